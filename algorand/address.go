@@ -1,10 +1,13 @@
 package algorand
 
 import (
+	"cmp"
+	"crypto/sha512"
 	_ "embed"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"filippo.io/edwards25519"
@@ -54,32 +57,100 @@ func isOnTheCurve(address []byte) bool {
 var ErrInvalidFalconPublicKey = errors.New(
 	"unsuitable Falcon public key for Algorand address")
 
+// DefaultTealVersion is the TEAL version of the PQlogicsig that
+// DerivePQLogicSig derives, and that every command sending from a PQ account
+// uses.
+//
+// Address stability: the address of a public key under a given TEAL version
+// never changes. The programs of the supported versions are generated from
+// teal/PQlogicsigTMPL.teal and pinned by TestPrecompileConsistency and the
+// KAT in testdata, so a released version is never regenerated differently.
+// A new TEAL version gives every key a new address; it is first offered for
+// previewing through DeriveOptions (falcon algorand address --teal-version),
+// and DefaultTealVersion only moves to it in a release that documents the
+// migration, since funds at the old addresses must be moved by signing with
+// the old version.
+const DefaultTealVersion = pqLogicSigVersion
+
+// ErrUnsupportedTealVersion is returned for a TEAL version without a
+// precompiled PQlogicsig.
+var ErrUnsupportedTealVersion = errors.New("unsupported TEAL version for the PQ logicsig")
+
+// DeriveOptions selects the program a PQ logicsig is derived from.
+type DeriveOptions struct {
+	// TealVersion is the TEAL version of the program; DefaultTealVersion if 0.
+	// See TealVersions.
+	TealVersion byte
+}
+
+// TealVersions returns the TEAL versions a PQ logicsig can be derived for,
+// in increasing order.
+func TealVersions() []byte {
+	versions := make([]byte, 0, len(pqLogicSigLayouts))
+	for v := range pqLogicSigLayouts {
+		versions = append(versions, v)
+	}
+	slices.Sort(versions)
+	return versions
+}
+
 // DerivePQLogicSig returns a LogicSig that verifies a Falcon signature.
 // The LogicSig embeds the Falcon public key and verifies the matching private key
 // was used to sign the transaction ID.
 // This is a deterministic derivation according to the specification in doc.go
 func DerivePQLogicSig(publicKey falcongo.PublicKey) (crypto.LogicSigAccount, error) {
-	maxIterations := 256
-	for counter := range maxIterations {
-		lsig := crypto.LogicSigAccount{
-			Lsig: types.LogicSig{
-				Logic: patchPrecompiledPQlogicsig(publicKey, byte(counter)),
-			},
-		}
-		lsa, err := lsig.Address()
-		if err != nil {
-			return crypto.LogicSigAccount{}, err
-		}
-		if !isOnTheCurve(lsa[:]) {
-			return lsig, nil
-		}
+	return DerivePQLogicSigWithOptions(publicKey, DeriveOptions{})
+}
+
+// DerivePQLogicSigWithOptions is like DerivePQLogicSig, for the program
+// selected by opt.
+func DerivePQLogicSigWithOptions(publicKey falcongo.PublicKey, opt DeriveOptions,
+) (crypto.LogicSigAccount, error) {
+
+	version := cmp.Or(opt.TealVersion, DefaultTealVersion)
+	layout, ok := pqLogicSigLayouts[version]
+	if !ok {
+		return crypto.LogicSigAccount{}, fmt.Errorf("%w: %d (supported: %v)", ErrUnsupportedTealVersion,
+			version, TealVersions())
 	}
-	return crypto.LogicSigAccount{}, ErrInvalidFalconPublicKey
+	program, _, err := layout.derive(publicKey)
+	if err != nil {
+		return crypto.LogicSigAccount{}, err
+	}
+	return crypto.LogicSigAccount{Lsig: types.LogicSig{Logic: program}}, nil
+}
+
+// DerivePQAddress returns the address of the LogicSig derived by
+// DerivePQLogicSig and the counter value the derivation settled on.
+func DerivePQAddress(publicKey falcongo.PublicKey) (types.Address, byte, error) {
+	return DerivePQAddressWithOptions(publicKey, DeriveOptions{})
+}
+
+// DerivePQAddressWithOptions is like DerivePQAddress, for the program
+// selected by opt.
+func DerivePQAddressWithOptions(publicKey falcongo.PublicKey, opt DeriveOptions,
+) (types.Address, byte, error) {
+
+	version := cmp.Or(opt.TealVersion, DefaultTealVersion)
+	layout, ok := pqLogicSigLayouts[version]
+	if !ok {
+		return types.Address{}, 0, fmt.Errorf("%w: %d (supported: %v)", ErrUnsupportedTealVersion,
+			version, TealVersions())
+	}
+	program, address, err := layout.derive(publicKey)
+	if err != nil {
+		return types.Address{}, 0, err
+	}
+	return address, program[layout.counterOffset], nil
 }
 
 //go:embed teal/PQlogicsig.teal.tok
 var PQlogicsigPrecompile []byte
 
+// pqLogicSigProgramSize is the length of the programs built by
+// patchPrecompiledPQlogicsig.
+const pqLogicSigProgramSize = len(pqLogicSigPrefix) + falcongo.PublicKeySize + len(pqLogicSigSuffix)
+
 // patchPrecompiledPQlogicsig returns the compiled PQlogicsig TEAL code
 // with the given Falcon public key and counter value
 //
@@ -94,18 +165,56 @@ var PQlogicsigPrecompile []byte
 //	      7	|	2d				| arg 0
 //	      8	|	80 81 0e 00... 	| pushbytes 0x00... (1793 public key bytes)
 //	   1804	|	85				| falcon_verify
+//
+// The bytes around the public key are generated from PQlogicsigTMPL by
+// go generate, in precompile_gen.go.
 func patchPrecompiledPQlogicsig(publicKey falcongo.PublicKey, counter byte) []byte {
-	precompiled := []byte{
-		0x0c,
-		0x26, 0x01, 0x01, 0x00,
-		0x31, 0x17,
-		0x2d,
-		0x80, 0x81, 0x0e,
+	return pqLogicSigLayouts[DefaultTealVersion].patch(publicKey, counter)
+}
+
+// pqLogicSigLayout is the precompiled PQlogicsig of a TEAL version: the
+// program bytes before and after the public key, and where the counter is.
+type pqLogicSigLayout struct {
+	counterOffset int
+	prefix        []byte
+	suffix        []byte
+}
+
+// patch returns the program with publicKey and counter.
+func (l pqLogicSigLayout) patch(publicKey falcongo.PublicKey, counter byte) []byte {
+	program := make([]byte, 0, len(l.prefix)+len(publicKey)+len(l.suffix))
+	program = append(program, l.prefix...)
+	program[l.counterOffset] = counter
+	program = append(program, publicKey[:]...)
+	return append(program, l.suffix...)
+}
+
+// programHashPrefix is the domain prefix of the hash of a logicsig program,
+// which is its address.
+const programHashPrefix = "Program"
+
+// derive returns the program with publicKey and the first counter whose
+// address does not decode to a curve point, and that address.
+//
+// The address is SHA-512/256("Program" || program). The counter sits in the
+// first SHA-512 block, so no hash state carries over from one counter to the
+// next; what does is the buffer, built once and hashed directly, of which
+// only the counter byte changes.
+func (l pqLogicSigLayout) derive(publicKey falcongo.PublicKey) ([]byte, types.Address, error) {
+	buf := make([]byte, 0, len(programHashPrefix)+len(l.prefix)+len(publicKey)+len(l.suffix))
+	buf = append(buf, programHashPrefix...)
+	buf = append(buf, l.prefix...)
+	buf = append(buf, publicKey[:]...)
+	buf = append(buf, l.suffix...)
+	program := buf[len(programHashPrefix):]
+	for counter := range 256 {
+		program[l.counterOffset] = byte(counter)
+		address := types.Address(sha512.Sum512_256(buf))
+		if !isOnTheCurve(address[:]) {
+			return program, address, nil
+		}
 	}
-	precompiled[4] = counter
-	precompiled = append(precompiled, publicKey[:]...)
-	precompiled = append(precompiled, 0x85)
-	return precompiled
+	return nil, types.Address{}, ErrInvalidFalconPublicKey
 }
 
 //go:embed teal/PQlogicsigTMPL.teal
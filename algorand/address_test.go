@@ -1,13 +1,16 @@
 package algorand
 
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"testing"
 
 	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
 	"github.com/algorandfoundation/falcon-signatures/falcongo"
 )
 
@@ -175,6 +178,15 @@ func TestDerivePQLogicSig_GoldenFixture(t *testing.T) {
 	if isOnTheCurve(address[:]) {
 		t.Fatalf("selected address decodes to an Edwards25519 point")
 	}
+
+	pqAddress, counter, err := DerivePQAddress(publicKey)
+	if err != nil {
+		t.Fatalf("DerivePQAddress failed: %v", err)
+	}
+	if pqAddress != address || int(counter) != derivation.SelectedCounter {
+		t.Fatalf("DerivePQAddress = %s, %d, want %s, %d",
+			pqAddress, counter, address, derivation.SelectedCounter)
+	}
 }
 
 func assertFixtureProgramShape(
@@ -222,3 +234,108 @@ func assertFixtureProgramShape(
 		t.Fatalf("program suffix = %s, want %s", got, derivation.ProgramSuffixHex)
 	}
 }
+
+// TestDerivePQAddressWithOptions checks that the default TEAL version keeps
+// the address of DerivePQAddress, that another version gives another
+// address, and that unknown versions are refused.
+func TestDerivePQAddressWithOptions(t *testing.T) {
+	kat := loadLSigAddressKAT(t)
+	publicKeyBytes, err := hex.DecodeString(kat.LSigDerivation.FalconPublicKeyHex)
+	if err != nil {
+		t.Fatalf("invalid Falcon public key hex fixture: %v", err)
+	}
+	var publicKey falcongo.PublicKey
+	copy(publicKey[:], publicKeyBytes)
+
+	want, _, err := DerivePQAddress(publicKey)
+	if err != nil {
+		t.Fatalf("DerivePQAddress failed: %v", err)
+	}
+	if want.String() != kat.LSigDerivation.SelectedAddress {
+		t.Fatalf("default address = %s, want the KAT's %s", want, kat.LSigDerivation.SelectedAddress)
+	}
+	got, _, err := DerivePQAddressWithOptions(publicKey, DeriveOptions{TealVersion: DefaultTealVersion})
+	if err != nil || got != want {
+		t.Fatalf("DerivePQAddressWithOptions(DefaultTealVersion) = %s, %v; want %s", got, err, want)
+	}
+	for _, v := range TealVersions() {
+		if v == DefaultTealVersion {
+			continue
+		}
+		lsig, err := DerivePQLogicSigWithOptions(publicKey, DeriveOptions{TealVersion: v})
+		if err != nil {
+			t.Fatalf("version %d: %v", v, err)
+		}
+		if lsig.Lsig.Logic[0] != v {
+			t.Fatalf("version %d: program starts with version %d", v, lsig.Lsig.Logic[0])
+		}
+		if other, _, _ := DerivePQAddressWithOptions(publicKey, DeriveOptions{TealVersion: v}); other == want {
+			t.Fatalf("version %d gives the address of version %d", v, DefaultTealVersion)
+		}
+	}
+	if _, _, err := DerivePQAddressWithOptions(publicKey, DeriveOptions{TealVersion: 11}); !errors.Is(err,
+		ErrUnsupportedTealVersion) {
+		t.Fatalf("version 11: got %v, want ErrUnsupportedTealVersion", err)
+	}
+}
+
+// TestDerivePQLogicSig_MatchesReference checks the derivation against the
+// specification, patching each counter and asking the SDK for the address,
+// over keys whose first counters are rejected.
+func TestDerivePQLogicSig_MatchesReference(t *testing.T) {
+	rejected := 0
+	for i := range 64 {
+		var publicKey falcongo.PublicKey
+		publicKey[0], publicKey[1] = 0x0a, byte(i)
+		var want crypto.LogicSigAccount
+		var counter int
+		for counter = range 256 {
+			want = crypto.LogicSigAccount{Lsig: types.LogicSig{Logic: patchPrecompiledPQlogicsig(publicKey, byte(counter))}}
+			if addr, _ := want.Address(); !isOnTheCurve(addr[:]) {
+				break
+			}
+		}
+		rejected += counter
+		got, err := DerivePQLogicSig(publicKey)
+		if err != nil {
+			t.Fatalf("key %d: DerivePQLogicSig failed: %v", i, err)
+		}
+		if !bytes.Equal(got.Lsig.Logic, want.Lsig.Logic) {
+			t.Fatalf("key %d: program differs from the reference (counter %d)", i, counter)
+		}
+		address, c, err := DerivePQAddress(publicKey)
+		wantAddress, _ := want.Address()
+		if err != nil || address != wantAddress || int(c) != counter {
+			t.Fatalf("key %d: DerivePQAddress = %s, %d, %v; want %s, %d", i, address, c, err, wantAddress, counter)
+		}
+	}
+	if rejected == 0 {
+		t.Fatalf("no key needed a second counter")
+	}
+}
+
+// BenchmarkDerivePQLogicSig measures the derivation of the PQ logicsig of
+// distinct keys.
+func BenchmarkDerivePQLogicSig(b *testing.B) {
+	var publicKey falcongo.PublicKey
+	for i := 0; b.Loop(); i++ {
+		publicKey[0], publicKey[1] = byte(i), byte(i>>8)
+		if _, err := DerivePQLogicSig(publicKey); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkDerivePQAddress_Parallel measures the throughput of bulk address
+// derivation on every CPU, as falcon algorand address --keys runs it.
+func BenchmarkDerivePQAddress_Parallel(b *testing.B) {
+	b.RunParallel(func(pb *testing.PB) {
+		var publicKey falcongo.PublicKey
+		for i := 0; pb.Next(); i++ {
+			publicKey[0], publicKey[1] = byte(i), byte(i>>8)
+			if _, _, err := DerivePQAddress(publicKey); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -3,10 +3,15 @@ package algorand
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"strings"
+	"sync/atomic"
 
 	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/indexer"
 	"github.com/algorand/go-algorand-sdk/v2/crypto"
 	"github.com/algorand/go-algorand-sdk/v2/types"
 )
@@ -16,6 +21,10 @@ const (
 	NodelyMainNetAlgodURL = "https://mainnet-api.4160.nodely.dev"
 	NodelyTestNetAlgodURL = "https://testnet-api.4160.nodely.dev"
 	NodelyBetaNetAlgodURL = "https://betanet-api.4160.nodely.dev"
+
+	NodelyMainNetIndexerURL = "https://mainnet-idx.4160.nodely.dev"
+	NodelyTestNetIndexerURL = "https://testnet-idx.4160.nodely.dev"
+	NodelyBetaNetIndexerURL = "https://betanet-idx.4160.nodely.dev"
 )
 
 // CompileLogicSig returns a LogicSigAccount compiled from the given TEAL code
@@ -40,18 +49,74 @@ func CompileLogicSig(teal string) (crypto.LogicSigAccount, error) {
 	return lsig, nil
 }
 
+// AlgodTransport, if not nil, carries the requests of the clients returned by
+// GetAlgodClient instead of http.DefaultTransport.
+var AlgodTransport http.RoundTripper
+
+// ErrBroadcastDisabled is returned instead of broadcasting a transaction once
+// DisableBroadcast was called.
+var ErrBroadcastDisabled = errors.New("broadcasting transactions is disabled (read-only mode)")
+
+var broadcastDisabled atomic.Bool
+
+// DisableBroadcast stops the process from broadcasting transactions: sending
+// fails with ErrBroadcastDisabled, CheckPending does not rebroadcast, and the
+// clients returned by GetAlgodClient refuse to post transactions. It cannot
+// be undone.
+func DisableBroadcast() {
+	broadcastDisabled.Store(true)
+}
+
+// BroadcastDisabled reports whether DisableBroadcast was called.
+func BroadcastDisabled() bool {
+	return broadcastDisabled.Load()
+}
+
+// noBroadcastTransport refuses the algod request that broadcasts
+// transactions, POST /v2/transactions.
+type noBroadcastTransport struct {
+	next http.RoundTripper
+}
+
+func (t noBroadcastTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/v2/transactions") {
+		return nil, ErrBroadcastDisabled
+	}
+	return t.next.RoundTrip(req)
+}
+
+// algodTransport returns the transport of the clients of GetAlgodClient.
+func algodTransport() http.RoundTripper {
+	if !BroadcastDisabled() {
+		return AlgodTransport
+	}
+	next := AlgodTransport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return noBroadcastTransport{next: next}
+}
+
 // GetAlgodClient returns an algod client for the specified network.
 // If the ALGOD_URL environment variable is set, it uses that URL and
 // the ALGOD_TOKEN environment variable for the token (which may be empty).
 // Otherwise, it uses the nodely.dev endpoints for MainNet, TestNet, and BetaNet.
 // For DevNet, the ALGOD_URL environment variable must be set.
 func GetAlgodClient(network Network) (*algod.Client, error) {
-	u := os.Getenv("ALGOD_URL")
-	if u != "" {
+	algodURL, token, err := algodEndpoint(network)
+	if err != nil {
+		return nil, err
+	}
+	return algod.MakeClientWithTransport(algodURL, token, nil, algodTransport())
+}
+
+// algodEndpoint returns the URL and token of the algod node of network, as
+// used by GetAlgodClient.
+func algodEndpoint(network Network) (algodURL, token string, err error) {
+	if u := os.Getenv("ALGOD_URL"); u != "" {
 		// Token may be empty depending on the endpoint setup.
-		return algod.MakeClient(u, os.Getenv("ALGOD_TOKEN"))
+		return u, os.Getenv("ALGOD_TOKEN"), nil
 	}
-	var algodURL string
 	switch network {
 	case MainNet:
 		algodURL = NodelyMainNetAlgodURL
@@ -60,7 +125,28 @@ func GetAlgodClient(network Network) (*algod.Client, error) {
 	case BetaNet:
 		algodURL = NodelyBetaNetAlgodURL
 	case DevNet:
-		return nil, fmt.Errorf("ALGOD_URL not set for DevNet")
+		return "", "", fmt.Errorf("ALGOD_URL not set for DevNet")
+	}
+	return algodURL, "", nil
+}
+
+// GetIndexerClient returns an indexer client for the specified network, like
+// GetAlgodClient but with the INDEXER_URL and INDEXER_TOKEN environment
+// variables.
+func GetIndexerClient(network Network) (*indexer.Client, error) {
+	if u := os.Getenv("INDEXER_URL"); u != "" {
+		return indexer.MakeClientWithTransport(u, os.Getenv("INDEXER_TOKEN"), nil, AlgodTransport)
+	}
+	var indexerURL string
+	switch network {
+	case MainNet:
+		indexerURL = NodelyMainNetIndexerURL
+	case TestNet:
+		indexerURL = NodelyTestNetIndexerURL
+	case BetaNet:
+		indexerURL = NodelyBetaNetIndexerURL
+	case DevNet:
+		return nil, fmt.Errorf("INDEXER_URL not set for DevNet")
 	}
-	return algod.MakeClient(algodURL, "")
+	return indexer.MakeClientWithTransport(indexerURL, "", nil, AlgodTransport)
 }
@@ -0,0 +1,38 @@
+package algorand
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestDisableBroadcast checks that algod clients refuse to post transactions
+// once broadcasting is disabled, and still read.
+func TestDisableBroadcast(t *testing.T) {
+	fake := &fakeAlgod{round: 15}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	t.Setenv("ALGOD_URL", srv.URL)
+	t.Setenv("ALGOD_TOKEN", "")
+	t.Cleanup(func() { broadcastDisabled.Store(false) })
+
+	DisableBroadcast()
+	c, err := GetAlgodClient(TestNet)
+	if err != nil {
+		t.Fatalf("GetAlgodClient failed: %v", err)
+	}
+	ctx := context.Background()
+	if _, err := c.SuggestedParams().Do(ctx); err != nil {
+		t.Fatalf("SuggestedParams failed: %v", err)
+	}
+	if _, err := c.SendRawTransaction([]byte{1}).Do(ctx); !errors.Is(err, ErrBroadcastDisabled) {
+		t.Fatalf("SendRawTransaction err = %v, want ErrBroadcastDisabled", err)
+	}
+	if fake.sent != 0 {
+		t.Fatalf("algod received %d transactions", fake.sent)
+	}
+	if _, _, err := sendSignedPQGroup(c, nil, nil, 0, 0, nil); !errors.Is(err, ErrBroadcastDisabled) {
+		t.Fatalf("sendSignedPQGroup err = %v, want ErrBroadcastDisabled", err)
+	}
+}
@@ -0,0 +1,257 @@
+package algorand
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/algorand/go-algorand-sdk/v2/abi"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// abiReturnPrefix starts the log in which an ARC-4 method returns its value.
+var abiReturnPrefix = []byte{0x15, 0x1f, 0x7c, 0x75}
+
+// abiMaxArgs is the number of method arguments an application call carries as
+// separate app args, after the selector; ARC-4 packs the arguments from the
+// 15th on in a tuple passed as the 15th.
+const abiMaxArgs = 15
+
+type AppCallOptions struct {
+	Network Network // default MainNet
+	// InnerTxns is the number of inner transactions the method issues; the
+	// call pays their fees.
+	InnerTxns int
+}
+
+// AppCallResult is the outcome of CallABIMethod.
+type AppCallResult struct {
+	TxID string
+	// Return is the value returned by the method in the ARC-4 JSON encoding
+	// (numbers, strings, addresses, base64 byte arrays and arrays for tuples);
+	// nil for void methods.
+	Return json.RawMessage
+}
+
+// abiCall is the app args and foreign references of an ABI method call.
+type abiCall struct {
+	appArgs  [][]byte
+	accounts []string
+	apps     []uint64
+	assets   []uint64
+}
+
+// CallABIMethod calls the ARC-4 method of appID with the given signature (e.g.
+// "transfer(address,uint64)void") from the PQ account of keyPair, and decodes
+// its return value from the logs of the confirmed call. Each of args is the
+// value of one method argument: its ARC-4 JSON encoding (e.g. 100, true,
+// "text", [1,2]), where strings and addresses may also be given unquoted, an
+// address for account references and a number for asset and application
+// references, which are added to the foreign arrays of the call. Transaction
+// arguments are not supported.
+func CallABIMethod(keyPair falcongo.KeyPair, appID uint64, signature string,
+	args []string, opt AppCallOptions,
+) (AppCallResult, error) {
+
+	method, err := abi.MethodFromSignature(signature)
+	if err != nil {
+		return AppCallResult{}, fmt.Errorf("invalid method signature: %w", err)
+	}
+	call, err := encodeABICall(method, args)
+	if err != nil {
+		return AppCallResult{}, err
+	}
+	if opt.InnerTxns < 0 {
+		return AppCallResult{}, fmt.Errorf("invalid number of inner transactions %d", opt.InnerTxns)
+	}
+
+	lsig, err := DerivePQLogicSig(keyPair.PublicKey)
+	if err != nil {
+		return AppCallResult{}, err
+	}
+	sender, err := lsig.Address()
+	if err != nil {
+		return AppCallResult{}, err
+	}
+	algodClient, err := GetAlgodClient(opt.Network)
+	if err != nil {
+		return AppCallResult{}, err
+	}
+	ctx := context.Background()
+	sp, err := algodClient.SuggestedParams().Do(ctx)
+	if err != nil {
+		return AppCallResult{}, err
+	}
+	sp.FlatFee = true
+	sp.Fee = types.MicroAlgos(uint64(1+opt.InnerTxns) * sp.MinFee)
+
+	txn, err := transaction.MakeApplicationNoOpTxWithBoxes(
+		appID,           // app ID
+		call.appArgs,    // app args
+		call.accounts,   // foreign accounts
+		call.apps,       // foreign apps
+		call.assets,     // foreign assets
+		nil,             // box references
+		sp,              // suggested params
+		sender,          // sender
+		nil,             // note
+		types.Digest{},  // group
+		[32]byte{},      // lease
+		types.Address{}, // rekey to
+	)
+	if err != nil {
+		return AppCallResult{}, err
+	}
+
+	txIDs, _, err := sendPQGroup(algodClient, keyPair, lsig, []types.Transaction{txn}, 0, 0, nil)
+	if err != nil {
+		return AppCallResult{}, err
+	}
+	result := AppCallResult{TxID: txIDs[0]}
+	if method.Returns.IsVoid() {
+		return result, nil
+	}
+	info, _, err := algodClient.PendingTransactionInformation(result.TxID).Do(ctx)
+	if err != nil {
+		return result, fmt.Errorf("call %s confirmed, but its logs could not be read: %w", result.TxID, err)
+	}
+	result.Return, err = decodeABIReturn(method, info.Logs)
+	if err != nil {
+		return result, fmt.Errorf("call %s confirmed, but %w", result.TxID, err)
+	}
+	return result, nil
+}
+
+// encodeABICall encodes the selector and the arguments of a call to method,
+// each of args given as described in CallABIMethod.
+func encodeABICall(method abi.Method, args []string) (abiCall, error) {
+	if len(args) != len(method.Args) {
+		return abiCall{}, fmt.Errorf("%s takes %d arguments, got %d",
+			method.GetSignature(), len(method.Args), len(args))
+	}
+	uint8Type, err := abi.TypeOf("uint8")
+	if err != nil {
+		return abiCall{}, err
+	}
+
+	var call abiCall
+	argTypes := make([]abi.Type, len(args))
+	values := make([]any, len(args))
+	for i, arg := range method.Args {
+		switch {
+		case arg.IsTransactionArg():
+			return abiCall{}, fmt.Errorf("argument %d: transaction arguments (%s) are not supported",
+				i+1, arg.Type)
+		case arg.IsReferenceArg():
+			index, err := call.addReference(arg.Type, args[i])
+			if err != nil {
+				return abiCall{}, fmt.Errorf("argument %d (%s): %w", i+1, arg.Type, err)
+			}
+			argTypes[i], values[i] = uint8Type, index
+		default:
+			argTypes[i], err = arg.GetTypeObject()
+			if err != nil {
+				return abiCall{}, err
+			}
+			values[i], err = parseABIValue(argTypes[i], args[i])
+			if err != nil {
+				return abiCall{}, fmt.Errorf("argument %d (%s): %w", i+1, arg.Type, err)
+			}
+		}
+	}
+
+	if len(args) > abiMaxArgs {
+		tupleType, err := abi.MakeTupleType(argTypes[abiMaxArgs-1:])
+		if err != nil {
+			return abiCall{}, err
+		}
+		// The tuple holds the tail of argTypes and values: append to copies.
+		argTypes = append(argTypes[:abiMaxArgs-1:abiMaxArgs-1], tupleType)
+		values = append(values[:abiMaxArgs-1:abiMaxArgs-1], values[abiMaxArgs-1:])
+	}
+	call.appArgs = [][]byte{method.GetSelector()}
+	for i, t := range argTypes {
+		encoded, err := t.Encode(values[i])
+		if err != nil {
+			return abiCall{}, fmt.Errorf("argument %d: %w", i+1, err)
+		}
+		call.appArgs = append(call.appArgs, encoded)
+	}
+	return call, nil
+}
+
+// parseABIValue parses s, the ARC-4 JSON encoding of a value of type t; a
+// string or an address may also be given unquoted.
+func parseABIValue(t abi.Type, s string) (any, error) {
+	v, err := t.UnmarshalFromJSON([]byte(s))
+	if err != nil && (t.String() == "string" || t.String() == "address") {
+		quoted, _ := json.Marshal(s)
+		if v, qerr := t.UnmarshalFromJSON(quoted); qerr == nil {
+			return v, nil
+		}
+	}
+	return v, err
+}
+
+// addReference adds the account, asset or application s to the foreign
+// arrays of the call, unless it is already there, and returns the index the
+// method receives for it. Index 0 of accounts is the sender, and index 0 of
+// applications the called one, so their indexes start at 1.
+func (c *abiCall) addReference(refType, s string) (uint8, error) {
+	switch refType {
+	case abi.AccountReferenceType:
+		addr, err := types.DecodeAddress(s)
+		if err != nil {
+			return 0, err
+		}
+		return uint8(addUnique(&c.accounts, addr.String()) + 1), nil
+	case abi.AssetReferenceType, abi.ApplicationReferenceType:
+		id, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ID %q", s)
+		}
+		if refType == abi.AssetReferenceType {
+			return uint8(addUnique(&c.assets, id)), nil
+		}
+		return uint8(addUnique(&c.apps, id) + 1), nil
+	}
+	return 0, fmt.Errorf("unknown reference type %s", refType)
+}
+
+// addUnique appends v to *s unless it is already there, and returns its
+// index.
+func addUnique[T comparable](s *[]T, v T) int {
+	for i, x := range *s {
+		if x == v {
+			return i
+		}
+	}
+	*s = append(*s, v)
+	return len(*s) - 1
+}
+
+// decodeABIReturn decodes the value returned by method from the logs of its
+// call: ARC-4 methods log it last, after abiReturnPrefix. It returns nil for
+// void methods.
+func decodeABIReturn(method abi.Method, logs [][]byte) (json.RawMessage, error) {
+	if method.Returns.IsVoid() {
+		return nil, nil
+	}
+	if len(logs) == 0 || !bytes.HasPrefix(logs[len(logs)-1], abiReturnPrefix) {
+		return nil, fmt.Errorf("the method logged no ARC-4 return value")
+	}
+	returnType, err := method.Returns.GetTypeObject()
+	if err != nil {
+		return nil, err
+	}
+	value, err := returnType.Decode(logs[len(logs)-1][len(abiReturnPrefix):])
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s return value: %w", method.Returns.Type, err)
+	}
+	return returnType.MarshalToJSON(value)
+}
@@ -0,0 +1,123 @@
+package algorand
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/abi"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// TestEncodeABICall checks the app args and foreign arrays of ABI calls.
+func TestEncodeABICall(t *testing.T) {
+	var to types.Address
+	to[0] = 9
+
+	method, err := abi.MethodFromSignature("transfer(address,uint64)void")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, addr := range []string{to.String(), strconv.Quote(to.String())} {
+		call, err := encodeABICall(method, []string{addr, "100"})
+		if err != nil {
+			t.Fatalf("encodeABICall(%s) failed: %v", addr, err)
+		}
+		if len(call.appArgs) != 3 || !bytes.Equal(call.appArgs[0], method.GetSelector()) ||
+			!bytes.Equal(call.appArgs[1], to[:]) ||
+			binary.BigEndian.Uint64(call.appArgs[2]) != 100 {
+			t.Fatalf("app args = %x", call.appArgs)
+		}
+	}
+
+	method, err = abi.MethodFromSignature("pay(account,asset,application,account,string,byte[])void")
+	if err != nil {
+		t.Fatal(err)
+	}
+	call, err := encodeABICall(method, []string{to.String(), "31", "42", to.String(), "hi", "[1,2]"})
+	if err != nil {
+		t.Fatalf("encodeABICall failed: %v", err)
+	}
+	wantArgs := [][]byte{method.GetSelector(), {1}, {0}, {1}, {1}, {0, 2, 'h', 'i'}, {0, 2, 1, 2}}
+	if len(call.appArgs) != len(wantArgs) {
+		t.Fatalf("got %d app args, want %d", len(call.appArgs), len(wantArgs))
+	}
+	for i, want := range wantArgs {
+		if !bytes.Equal(call.appArgs[i], want) {
+			t.Errorf("app arg %d = %x, want %x", i, call.appArgs[i], want)
+		}
+	}
+	if len(call.accounts) != 1 || call.accounts[0] != to.String() ||
+		len(call.assets) != 1 || call.assets[0] != 31 || len(call.apps) != 1 || call.apps[0] != 42 {
+		t.Fatalf("foreign arrays = %v %v %v", call.accounts, call.assets, call.apps)
+	}
+
+	// Arguments from the 15th on are packed in a tuple.
+	method, err = abi.MethodFromSignature("many(" + strings.Repeat("uint8,", 16) + "uint8)void")
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := make([]string, 17)
+	for i := range args {
+		args[i] = strconv.Itoa(i)
+	}
+	call, err = encodeABICall(method, args)
+	if err != nil {
+		t.Fatalf("encodeABICall failed: %v", err)
+	}
+	if len(call.appArgs) != 16 || !bytes.Equal(call.appArgs[15], []byte{14, 15, 16}) {
+		t.Fatalf("app args = %x", call.appArgs)
+	}
+
+	for sig, args := range map[string][]string{
+		"transfer(address,uint64)void": {to.String()},
+		"set(uint8)void":               {"256"},
+		"set(bool)void":                {"yes"},
+		"pay(pay,uint64)void":          {"0", "1"},
+		"opt(asset)void":               {"ASA"},
+		"to(account)void":              {"nope"},
+	} {
+		method, err := abi.MethodFromSignature(sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := encodeABICall(method, args); err == nil {
+			t.Errorf("encodeABICall(%s, %q) succeeded", sig, args)
+		}
+	}
+}
+
+// TestDecodeABIReturn decodes return values logged by ARC-4 methods.
+func TestDecodeABIReturn(t *testing.T) {
+	for sig, tc := range map[string]struct {
+		logs [][]byte
+		want string
+	}{
+		"get()uint64":          {[][]byte{[]byte("event"), append(abiReturnPrefix, 0, 0, 0, 0, 0, 0, 1, 0)}, "256"},
+		"name()string":         {[][]byte{append(abiReturnPrefix, 0, 2, 'o', 'k')}, `"ok"`},
+		"pair()(bool,byte[2])": {[][]byte{append(abiReturnPrefix, 0x80, 1, 2)}, `[true,"AQI="]`},
+		"noop()void":           {nil, ""},
+		"noop2()void":          {[][]byte{[]byte("event")}, ""},
+	} {
+		method, err := abi.MethodFromSignature(sig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := decodeABIReturn(method, tc.logs)
+		if err != nil || string(got) != tc.want {
+			t.Errorf("decodeABIReturn(%s) = %s, %v; want %s", sig, got, err, tc.want)
+		}
+	}
+
+	method, err := abi.MethodFromSignature("get()uint64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, logs := range [][][]byte{nil, {[]byte("event")}, {append(abiReturnPrefix, 1)}} {
+		if got, err := decodeABIReturn(method, logs); err == nil {
+			t.Errorf("decodeABIReturn(%x) = %s", logs, got)
+		}
+	}
+}
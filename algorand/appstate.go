@@ -0,0 +1,178 @@
+package algorand
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-codec/codec"
+)
+
+// StateEntry is a key of application global or local state and its value,
+// either bytes or a uint.
+type StateEntry struct {
+	Key    []byte
+	IsUint bool
+	Bytes  []byte
+	Uint   uint64
+}
+
+// TEAL value types of algod.
+const (
+	tealBytesType = 1
+	tealUintType  = 2
+)
+
+// ReadAppGlobal returns the global state of application appID, sorted by key.
+func ReadAppGlobal(network Network, appID uint64) ([]StateEntry, error) {
+	algodClient, err := GetAlgodClient(network)
+	if err != nil {
+		return nil, err
+	}
+	return readAppGlobal(algodClient, appID)
+}
+
+func readAppGlobal(algodClient *algod.Client, appID uint64) ([]StateEntry, error) {
+	app, err := algodClient.GetApplicationByID(appID).Do(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return stateEntries(app.Params.GlobalState)
+}
+
+// ReadAppLocal returns the local state of application appID for the account
+// address, sorted by key.
+func ReadAppLocal(network Network, appID uint64, address string) ([]StateEntry, error) {
+	algodClient, err := GetAlgodClient(network)
+	if err != nil {
+		return nil, err
+	}
+	return readAppLocal(algodClient, appID, address)
+}
+
+func readAppLocal(algodClient *algod.Client, appID uint64, address string,
+) ([]StateEntry, error) {
+
+	info, err := algodClient.AccountApplicationInformation(address, appID).Do(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return stateEntries(info.AppLocalState.KeyValue)
+}
+
+// ReadAppBox returns the value of the box name of application appID.
+func ReadAppBox(network Network, appID uint64, name []byte) ([]byte, error) {
+	algodClient, err := GetAlgodClient(network)
+	if err != nil {
+		return nil, err
+	}
+	return readAppBox(algodClient, appID, name)
+}
+
+func readAppBox(algodClient *algod.Client, appID uint64, name []byte) ([]byte, error) {
+	box, err := algodClient.GetApplicationBoxByName(appID, name).Do(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return box.Value, nil
+}
+
+// ListAppBoxes returns the names of the boxes of application appID, sorted.
+func ListAppBoxes(network Network, appID uint64) ([][]byte, error) {
+	algodClient, err := GetAlgodClient(network)
+	if err != nil {
+		return nil, err
+	}
+	return listAppBoxes(algodClient, appID)
+}
+
+func listAppBoxes(algodClient *algod.Client, appID uint64) ([][]byte, error) {
+	resp, err := algodClient.GetApplicationBoxes(appID).Do(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	names := make([][]byte, len(resp.Boxes))
+	for i, b := range resp.Boxes {
+		names[i] = b.Name
+	}
+	sort.Slice(names, func(i, j int) bool { return bytes.Compare(names[i], names[j]) < 0 })
+	return names, nil
+}
+
+// stateEntries decodes the base64 keys and values of algod.
+func stateEntries(kvs []models.TealKeyValue) ([]StateEntry, error) {
+	entries := make([]StateEntry, 0, len(kvs))
+	for _, kv := range kvs {
+		key, err := base64.StdEncoding.DecodeString(kv.Key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid state key %q: %w", kv.Key, err)
+		}
+		e := StateEntry{Key: key}
+		switch kv.Value.Type {
+		case tealBytesType:
+			if e.Bytes, err = base64.StdEncoding.DecodeString(kv.Value.Bytes); err != nil {
+				return nil, fmt.Errorf("invalid value of state key %q: %w", key, err)
+			}
+		case tealUintType:
+			e.IsUint, e.Uint = true, kv.Value.Uint
+		default:
+			return nil, fmt.Errorf("unknown type %d of state key %q", kv.Value.Type, key)
+		}
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return bytes.Compare(entries[i].Key, entries[j].Key) < 0 })
+	return entries, nil
+}
+
+// msgpackJSONHandle decodes msgpack strings to Go strings and binary data to
+// byte slices, so the two can be told apart in JSON.
+var msgpackJSONHandle = &codec.MsgpackHandle{RawToString: true}
+
+// DecodeMsgpack decodes b, which must hold exactly one msgpack value, into
+// values that encoding/json can marshal: maps with string keys, slices,
+// numbers, strings, booleans and nil. Binary data is base64-encoded.
+func DecodeMsgpack(b []byte) (any, error) {
+	r := bytes.NewReader(b)
+	var v any
+	if err := codec.NewDecoder(r, msgpackJSONHandle).Decode(&v); err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, errors.New("trailing bytes after msgpack value")
+	}
+	return jsonValue(v), nil
+}
+
+func jsonValue(v any) any {
+	switch v := v.(type) {
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, e := range v {
+			ks, ok := k.(string)
+			if !ok {
+				if kb, isBytes := k.([]byte); isBytes {
+					ks = string(kb)
+				} else {
+					ks = fmt.Sprint(k)
+				}
+			}
+			m[ks] = jsonValue(e)
+		}
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for i, e := range v {
+			s[i] = jsonValue(e)
+		}
+		return s
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	default:
+		return v
+	}
+}
@@ -0,0 +1,54 @@
+package algorand
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+)
+
+// TestReadAppGlobal checks keys and values are decoded and sorted by key.
+func TestReadAppGlobal(t *testing.T) {
+	b64 := base64.StdEncoding.EncodeToString
+	f := &fakeAlgod{app: models.Application{Id: 7, Params: models.ApplicationParams{
+		GlobalState: []models.TealKeyValue{
+			{Key: b64([]byte("owner")), Value: models.TealValue{Type: tealBytesType, Bytes: b64([]byte{1, 2})}},
+			{Key: b64([]byte("count")), Value: models.TealValue{Type: tealUintType, Uint: 42}},
+		},
+	}}}
+	entries, err := readAppGlobal(f.client(t), 7)
+	if err != nil {
+		t.Fatalf("readAppGlobal failed: %v", err)
+	}
+	if len(entries) != 2 ||
+		string(entries[0].Key) != "count" || !entries[0].IsUint || entries[0].Uint != 42 ||
+		string(entries[1].Key) != "owner" || entries[1].IsUint || string(entries[1].Bytes) != "\x01\x02" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+
+	bad := []models.TealKeyValue{{Key: b64([]byte("k")), Value: models.TealValue{Type: 3}}}
+	if _, err := stateEntries(bad); err == nil {
+		t.Fatalf("expected an error for an unknown value type")
+	}
+}
+
+// TestDecodeMsgpack checks msgpack values come out as JSON.
+func TestDecodeMsgpack(t *testing.T) {
+	enc := msgpack.Encode(map[string]any{"fee": 1000, "to": []byte{0xff}, "tags": []string{"a"}})
+	v, err := DecodeMsgpack(enc)
+	if err != nil {
+		t.Fatalf("DecodeMsgpack failed: %v", err)
+	}
+	got, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if want := `{"fee":1000,"tags":["a"],"to":"/w=="}`; string(got) != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+	if _, err := DecodeMsgpack(append(enc, 0)); err == nil {
+		t.Fatalf("expected an error for trailing bytes")
+	}
+}
@@ -0,0 +1,208 @@
+package algorand
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/v2/abi"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// ARC-59 router applications. Assets sent to an account that has not opted in
+// are held in a per-receiver inbox account controlled by the router; the
+// router keeps the inbox address in a box named after the receiver address.
+const (
+	ARC59MainNetRouterAppID uint64 = 2449590623
+	ARC59TestNetRouterAppID uint64 = 643020148
+)
+
+// ARC-59 router methods used to empty an inbox. Each one issues one inner
+// transaction from the inbox to the caller.
+const (
+	arc59ClaimMethod     = "arc59_claim(uint64)void"
+	arc59ClaimAlgoMethod = "arc59_claimAlgo()void"
+	arc59InnerTxns       = 1
+)
+
+type ClaimOptions struct {
+	Network Network // default MainNet
+	// RouterAppID overrides the ARC-59 router application. If zero, the
+	// router deployed on Network is used.
+	RouterAppID uint64
+}
+
+// ARC59RouterAppID returns the ARC-59 router application deployed on network.
+func ARC59RouterAppID(network Network) (uint64, error) {
+	switch network {
+	case MainNet:
+		return ARC59MainNetRouterAppID, nil
+	case TestNet:
+		return ARC59TestNetRouterAppID, nil
+	default:
+		return 0, fmt.Errorf("no known ARC-59 router on this network; set a router app ID")
+	}
+}
+
+// ClaimAsset claims all units of assetID held in the ARC-59 inbox of the PQ
+// account controlled by keyPair. In a single group it claims the Algos held by
+// the inbox (if any are above its minimum balance), opts the PQ account into
+// the asset (if needed) and claims the asset, so a PQ account holding no Algos
+// can still receive assets as long as the inbox funds it.
+// It returns the IDs of the PQ transactions in group order.
+func ClaimAsset(keyPair falcongo.KeyPair, assetID uint64, opt ClaimOptions,
+) (txIDs []string, err error) {
+
+	routerAppID := opt.RouterAppID
+	if routerAppID == 0 {
+		routerAppID, err = ARC59RouterAppID(opt.Network)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	lsig, err := DerivePQLogicSig(keyPair.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	receiver, err := lsig.Address()
+	if err != nil {
+		return nil, err
+	}
+
+	algodClient, err := GetAlgodClient(opt.Network)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+
+	box, err := algodClient.GetApplicationBoxByName(routerAppID, receiver[:]).Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("no ARC-59 inbox found for %s: %w", receiver, err)
+	}
+	if len(box.Value) != len(types.Address{}) {
+		return nil, fmt.Errorf("unexpected ARC-59 inbox box size %d", len(box.Value))
+	}
+	var inbox types.Address
+	copy(inbox[:], box.Value)
+
+	inboxInfo, err := algodClient.AccountInformation(inbox.String()).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !holdsAsset(inboxInfo, assetID) {
+		return nil, fmt.Errorf("ARC-59 inbox %s does not hold asset %d", inbox, assetID)
+	}
+	receiverInfo, err := algodClient.AccountInformation(receiver.String()).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sp, err := algodClient.SuggestedParams().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	txns, feePayer, err := makeClaimTxns(receiver, inbox, routerAppID, assetID,
+		inboxInfo.Amount > inboxInfo.MinBalance, !holdsAsset(receiverInfo, assetID), sp)
+	if err != nil {
+		return nil, err
+	}
+
+	txIDs, _, err = sendPQGroup(algodClient, keyPair, lsig, txns, feePayer, 0, nil)
+	return txIDs, err
+}
+
+// holdsAsset reports whether the account is opted into assetID.
+func holdsAsset(info models.Account, assetID uint64) bool {
+	for _, holding := range info.Assets {
+		if holding.AssetId == assetID {
+			return true
+		}
+	}
+	return false
+}
+
+// makeClaimTxns builds the PQ transactions of an ARC-59 claim, sent by
+// receiver: an optional arc59_claimAlgo call, an optional asset opt-in and the
+// arc59_claim call. The claim call comes last and pays the fees of the whole
+// group (including inner transactions), so the Algos claimed from the inbox
+// can cover them; its index is returned as feePayer.
+func makeClaimTxns(receiver, inbox types.Address, routerAppID, assetID uint64,
+	claimAlgo, optIn bool, sp types.SuggestedParams,
+) (txns []types.Transaction, feePayer int, err error) {
+
+	sp.FlatFee = true
+	sp.Fee = 0
+
+	boxes := []types.AppBoxReference{{AppID: routerAppID, Name: receiver[:]}}
+	innerTxns := 0
+
+	if claimAlgo {
+		txn, err := makeARC59Call(arc59ClaimAlgoMethod, nil, receiver, inbox,
+			routerAppID, nil, boxes, sp)
+		if err != nil {
+			return nil, 0, err
+		}
+		txns = append(txns, txn)
+		innerTxns += arc59InnerTxns
+	}
+
+	if optIn {
+		txn, err := transaction.MakeAssetAcceptanceTxn(receiver.String(), nil, sp, assetID)
+		if err != nil {
+			return nil, 0, err
+		}
+		txns = append(txns, txn)
+	}
+
+	uint64Type, err := abi.TypeOf("uint64")
+	if err != nil {
+		return nil, 0, err
+	}
+	encodedAssetID, err := uint64Type.Encode(assetID)
+	if err != nil {
+		return nil, 0, err
+	}
+	claimTxn, err := makeARC59Call(arc59ClaimMethod, [][]byte{encodedAssetID}, receiver,
+		inbox, routerAppID, []uint64{assetID}, boxes, sp)
+	if err != nil {
+		return nil, 0, err
+	}
+	txns = append(txns, claimTxn)
+	innerTxns += arc59InnerTxns
+
+	feePayer = len(txns) - 1
+	txns[feePayer].Fee = types.MicroAlgos(uint64(len(txns)+innerTxns) * sp.MinFee)
+	return txns, feePayer, nil
+}
+
+// makeARC59Call builds a zero-fee call to an ARC-59 router method with the
+// inbox as foreign account.
+func makeARC59Call(signature string, args [][]byte, receiver, inbox types.Address,
+	routerAppID uint64, foreignAssets []uint64, boxes []types.AppBoxReference,
+	sp types.SuggestedParams,
+) (types.Transaction, error) {
+
+	method, err := abi.MethodFromSignature(signature)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	appArgs := append([][]byte{method.GetSelector()}, args...)
+	return transaction.MakeApplicationNoOpTxWithBoxes(
+		routerAppID,              // app ID
+		appArgs,                  // app args
+		[]string{inbox.String()}, // foreign accounts
+		nil,                      // foreign apps
+		foreignAssets,            // foreign assets
+		boxes,                    // box references
+		sp,                       // suggested params
+		receiver,                 // sender
+		nil,                      // note
+		types.Digest{},           // group
+		[32]byte{},               // lease
+		types.ZeroAddress,        // rekey to
+	)
+}
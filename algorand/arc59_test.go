@@ -0,0 +1,103 @@
+package algorand
+
+import (
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/abi"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// TestARC59RouterAppID checks the known routers and the error for other networks.
+func TestARC59RouterAppID(t *testing.T) {
+	if id, err := ARC59RouterAppID(MainNet); err != nil || id != ARC59MainNetRouterAppID {
+		t.Fatalf("MainNet: got %d, %v", id, err)
+	}
+	if id, err := ARC59RouterAppID(TestNet); err != nil || id != ARC59TestNetRouterAppID {
+		t.Fatalf("TestNet: got %d, %v", id, err)
+	}
+	if _, err := ARC59RouterAppID(DevNet); err == nil {
+		t.Fatalf("expected error for DevNet")
+	}
+}
+
+// TestMakeClaimTxns checks the transactions of a full claim and their fees.
+func TestMakeClaimTxns(t *testing.T) {
+	sp := types.SuggestedParams{
+		FlatFee:         false,
+		Fee:             10,
+		MinFee:          1000,
+		FirstRoundValid: 1,
+		LastRoundValid:  1000,
+		GenesisID:       "test-v1",
+		GenesisHash:     make([]byte, 32),
+	}
+	receiver := types.Address{1}
+	inbox := types.Address{2}
+	const routerAppID, assetID = 77, 99
+
+	tests := []struct {
+		name      string
+		claimAlgo bool
+		optIn     bool
+		want      []string // method selector or "optin"
+		fee       uint64
+	}{
+		{"claim only", false, false, []string{arc59ClaimMethod}, 2000},
+		{"opt-in", false, true, []string{"optin", arc59ClaimMethod}, 3000},
+		{"full", true, true,
+			[]string{arc59ClaimAlgoMethod, "optin", arc59ClaimMethod}, 5000},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txns, feePayer, err := makeClaimTxns(receiver, inbox, routerAppID, assetID,
+				tt.claimAlgo, tt.optIn, sp)
+			if err != nil {
+				t.Fatalf("makeClaimTxns failed: %v", err)
+			}
+			if len(txns) != len(tt.want) {
+				t.Fatalf("expected %d transactions, got %d", len(tt.want), len(txns))
+			}
+			if feePayer != len(txns)-1 {
+				t.Fatalf("expected the claim call to pay fees, got index %d", feePayer)
+			}
+			for i, txn := range txns {
+				if txn.Sender != receiver {
+					t.Fatalf("transaction %d not sent by receiver", i)
+				}
+				wantFee := uint64(0)
+				if i == feePayer {
+					wantFee = tt.fee
+				}
+				if uint64(txn.Fee) != wantFee {
+					t.Fatalf("transaction %d: expected fee %d, got %d", i, wantFee, txn.Fee)
+				}
+				if tt.want[i] == "optin" {
+					if txn.Type != types.AssetTransferTx || txn.XferAsset != assetID ||
+						txn.AssetReceiver != receiver || txn.AssetAmount != 0 {
+						t.Fatalf("transaction %d is not an opt-in to %d", i, assetID)
+					}
+					continue
+				}
+				method, err := abi.MethodFromSignature(tt.want[i])
+				if err != nil {
+					t.Fatalf("MethodFromSignature failed: %v", err)
+				}
+				if txn.ApplicationID != routerAppID ||
+					string(txn.ApplicationArgs[0]) != string(method.GetSelector()) {
+					t.Fatalf("transaction %d is not a %s call", i, tt.want[i])
+				}
+				if len(txn.Accounts) != 1 || txn.Accounts[0] != inbox {
+					t.Fatalf("transaction %d does not reference the inbox", i)
+				}
+				if len(txn.BoxReferences) != 1 ||
+					string(txn.BoxReferences[0].Name) != string(receiver[:]) {
+					t.Fatalf("transaction %d does not reference the inbox box", i)
+				}
+			}
+			claim := txns[len(txns)-1]
+			if len(claim.ForeignAssets) != 1 || claim.ForeignAssets[0] != assetID {
+				t.Fatalf("claim call does not reference asset %d", assetID)
+			}
+		})
+	}
+}
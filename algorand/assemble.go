@@ -0,0 +1,255 @@
+package algorand
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// assembleTEAL assembles TEAL source into bytecode, as goal clerk compile
+// does, without an algod node. It knows only the opcodes and fields of the
+// programs of this package (see teal/), so that they can be generated and
+// checked offline; anything else is an error.
+func assembleTEAL(src string) ([]byte, error) {
+	a := assembler{labels: map[string]int{}}
+	for i, line := range strings.Split(src, "\n") {
+		if err := a.line(line); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+	}
+	if a.version == 0 {
+		return nil, fmt.Errorf("missing #pragma version")
+	}
+	for _, f := range a.fixups {
+		target, ok := a.labels[f.label]
+		if !ok {
+			return nil, fmt.Errorf("line %d: undefined label %q", f.line, f.label)
+		}
+		// Branch offsets are relative to the end of the branch instruction.
+		offset := target - (f.at + 2)
+		if offset < 0 && a.version < 4 || offset < -0x8000 || offset > 0x7fff {
+			return nil, fmt.Errorf("line %d: branch to %q out of range", f.line, f.label)
+		}
+		binary.BigEndian.PutUint16(a.program[f.at:], uint16(int16(offset)))
+	}
+	return a.program, nil
+}
+
+// maxTEALVersion is the latest TEAL version assembleTEAL accepts. The opcodes
+// it knows are assumed to be unchanged in versions after 12.
+const maxTEALVersion = 13
+
+// tealOp is an opcode known to assembleTEAL: its byte, the version it
+// appeared in, and how its immediates are assembled.
+type tealOp struct {
+	code    byte
+	version uint64
+	imm     func(a *assembler, args []string) error
+}
+
+var tealOps = map[string]tealOp{
+	"+":             {0x08, 1, nil},
+	"-":             {0x09, 1, nil},
+	"%":             {0x18, 1, nil},
+	"!":             {0x14, 1, nil},
+	"||":            {0x11, 1, nil},
+	"==":            {0x12, 1, nil},
+	">":             {0x0d, 1, nil},
+	"<=":            {0x0e, 1, nil},
+	"assert":        {0x44, 3, nil},
+	"ed25519verify": {0x04, 1, nil},
+	"return":        {0x43, 2, nil},
+	"falcon_verify": {0x85, 12, nil},
+	"bnz":           {0x40, 1, (*assembler).branch},
+	"bytecblock":    {0x26, 1, (*assembler).byteConstants},
+	"pushbytes":     {0x80, 3, (*assembler).pushBytes},
+	"pushint":       {0x81, 3, (*assembler).pushInt},
+	"txn":           {0x31, 1, fieldImmediate(txnFields)},
+	"global":        {0x32, 1, fieldImmediate(globalFields)},
+}
+
+// tealField is a txn or global field: its index and the version it appeared
+// in.
+type tealField struct {
+	index   byte
+	version uint64
+}
+
+var txnFields = map[string]tealField{
+	"Fee":              {1, 1},
+	"FirstValid":       {2, 1},
+	"LastValid":        {4, 1},
+	"Lease":            {6, 1},
+	"Receiver":         {7, 1},
+	"Amount":           {8, 1},
+	"CloseRemainderTo": {9, 1},
+	"TypeEnum":         {16, 1},
+	"TxID":             {23, 1},
+	"RekeyTo":          {32, 2},
+}
+
+var globalFields = map[string]tealField{
+	"ZeroAddress": {3, 1},
+}
+
+type assembler struct {
+	version uint64
+	program []byte
+	labels  map[string]int
+	fixups  []branchFixup
+	lineNo  int
+}
+
+// branchFixup is a branch whose offset is written once its label is known.
+type branchFixup struct {
+	at    int // offset of the 2-byte branch offset
+	label string
+	line  int
+}
+
+func (a *assembler) line(line string) error {
+	a.lineNo++
+	if i := strings.Index(line, "//"); i >= 0 {
+		line = line[:i]
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+	if fields[0] == "#pragma" {
+		if len(fields) != 3 || fields[1] != "version" {
+			return fmt.Errorf("unsupported pragma %q", strings.Join(fields, " "))
+		}
+		if a.version != 0 || len(a.program) != 0 {
+			return fmt.Errorf("#pragma version must come first")
+		}
+		v, err := strconv.ParseUint(fields[2], 10, 64)
+		if err != nil || v == 0 || v > maxTEALVersion {
+			return fmt.Errorf("unsupported version %q", fields[2])
+		}
+		a.version = v
+		a.program = binary.AppendUvarint(a.program, v)
+		return nil
+	}
+	if a.version == 0 {
+		return fmt.Errorf("missing #pragma version")
+	}
+	if label, ok := strings.CutSuffix(fields[0], ":"); ok && len(fields) == 1 {
+		if _, dup := a.labels[label]; dup {
+			return fmt.Errorf("duplicate label %q", label)
+		}
+		a.labels[label] = len(a.program)
+		return nil
+	}
+
+	name, args := fields[0], fields[1:]
+	if name == "arg" {
+		// arg 0 to arg 3 assemble to their one-byte forms.
+		if len(args) != 1 {
+			return fmt.Errorf("arg expects 1 immediate")
+		}
+		n, err := strconv.ParseUint(args[0], 0, 8)
+		if err != nil || n > 3 {
+			return fmt.Errorf("unsupported arg %q", args[0])
+		}
+		a.program = append(a.program, 0x2d+byte(n))
+		return nil
+	}
+	op, ok := tealOps[name]
+	if !ok {
+		return fmt.Errorf("unknown opcode %q", name)
+	}
+	if op.version > a.version {
+		return fmt.Errorf("%s requires version %d", name, op.version)
+	}
+	a.program = append(a.program, op.code)
+	if op.imm == nil {
+		if len(args) != 0 {
+			return fmt.Errorf("%s expects no immediates", name)
+		}
+		return nil
+	}
+	return op.imm(a, args)
+}
+
+func (a *assembler) branch(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("branch expects 1 label")
+	}
+	a.fixups = append(a.fixups, branchFixup{at: len(a.program), label: args[0], line: a.lineNo})
+	a.program = append(a.program, 0, 0)
+	return nil
+}
+
+func (a *assembler) byteConstants(args []string) error {
+	a.program = binary.AppendUvarint(a.program, uint64(len(args)))
+	for _, arg := range args {
+		b, err := parseTEALBytes(arg)
+		if err != nil {
+			return err
+		}
+		a.program = binary.AppendUvarint(a.program, uint64(len(b)))
+		a.program = append(a.program, b...)
+	}
+	return nil
+}
+
+func (a *assembler) pushBytes(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("pushbytes expects 1 immediate")
+	}
+	b, err := parseTEALBytes(args[0])
+	if err != nil {
+		return err
+	}
+	a.program = binary.AppendUvarint(a.program, uint64(len(b)))
+	a.program = append(a.program, b...)
+	return nil
+}
+
+func (a *assembler) pushInt(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("pushint expects 1 immediate")
+	}
+	n, err := strconv.ParseUint(args[0], 0, 64)
+	if err != nil {
+		return fmt.Errorf("invalid integer %q", args[0])
+	}
+	a.program = binary.AppendUvarint(a.program, n)
+	return nil
+}
+
+// fieldImmediate returns the immediate assembler of an opcode naming one of
+// fields.
+func fieldImmediate(fields map[string]tealField) func(a *assembler, args []string) error {
+	return func(a *assembler, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("expected 1 field")
+		}
+		f, ok := fields[args[0]]
+		if !ok {
+			return fmt.Errorf("unknown field %q", args[0])
+		}
+		if f.version > a.version {
+			return fmt.Errorf("field %s requires version %d", args[0], f.version)
+		}
+		a.program = append(a.program, f.index)
+		return nil
+	}
+}
+
+// parseTEALBytes parses a 0x-prefixed byte constant, the only form used by
+// the programs of this package.
+func parseTEALBytes(s string) ([]byte, error) {
+	h, ok := strings.CutPrefix(s, "0x")
+	if !ok {
+		return nil, fmt.Errorf("unsupported byte constant %q (want 0x...)", s)
+	}
+	b, err := hex.DecodeString(h)
+	if err != nil {
+		return nil, fmt.Errorf("invalid byte constant %q: %w", s, err)
+	}
+	return b, nil
+}
@@ -0,0 +1,273 @@
+package algorand
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// Errors of DestroyAsset, ReconfigureAsset and PlanAssetRoles, returned
+// wrapped with the details of the asset.
+var (
+	// ErrAssetNotFound is returned for an asset that does not exist, or no
+	// longer does.
+	ErrAssetNotFound = errors.New("asset not found")
+	// ErrNotAssetManager is returned when the PQ account is not the manager,
+	// the only account that can reconfigure or destroy an asset.
+	ErrNotAssetManager = errors.New("not the manager of the asset")
+	// ErrAssetSupplyOutstanding is returned by DestroyAsset while units of
+	// the asset are held by other accounts than its creator.
+	ErrAssetSupplyOutstanding = errors.New("the creator does not hold the whole supply")
+	// ErrAssetImmutable is returned for an asset without a manager, which can
+	// never be reconfigured or destroyed.
+	ErrAssetImmutable = errors.New("the asset has no manager")
+	// ErrAssetRoleCleared is returned for a role requested for an address
+	// after it was removed: a removed role can never be set again.
+	ErrAssetRoleCleared = errors.New("the role was removed and cannot be set again")
+	// ErrNoAssetRoleChange is returned when the requested roles are the
+	// current ones.
+	ErrNoAssetRoleChange = errors.New("the requested roles are the current roles")
+	// ErrRoleRemovalNotConfirmed is returned when roles would be removed
+	// without confirmation, as a removal is irreversible.
+	ErrRoleRemovalNotConfirmed = errors.New("role removal not confirmed")
+)
+
+// Asset roles, as named in AssetRoles and RoleChange.
+const (
+	RoleManager  = "manager"
+	RoleReserve  = "reserve"
+	RoleFreeze   = "freeze"
+	RoleClawback = "clawback"
+)
+
+// AssetRoles are the role addresses of an asset; "" is no one.
+type AssetRoles struct {
+	Manager, Reserve, Freeze, Clawback string
+}
+
+// get returns the address of role.
+func (r AssetRoles) get(role string) string {
+	switch role {
+	case RoleManager:
+		return r.Manager
+	case RoleReserve:
+		return r.Reserve
+	case RoleFreeze:
+		return r.Freeze
+	default:
+		return r.Clawback
+	}
+}
+
+// assetRoleNames are the roles in the order of an asset config transaction.
+var assetRoleNames = []string{RoleManager, RoleReserve, RoleFreeze, RoleClawback}
+
+// RoleChange is the change of a role of an asset from From to To.
+type RoleChange struct {
+	Role     string
+	From, To string
+}
+
+// Removed reports whether c removes the role, which is irreversible.
+func (c RoleChange) Removed() bool {
+	return c.To == ""
+}
+
+// AssetState is what DestroyAsset and ReconfigureAsset check before sending.
+type AssetState struct {
+	ID      uint64
+	Creator string
+	Total   uint64
+	// CreatorHolding is the number of units the creator holds.
+	CreatorHolding uint64
+	Roles          AssetRoles
+}
+
+// GetAssetState reads the parameters of assetID and the holding of its
+// creator.
+func GetAssetState(assetID uint64, network Network) (AssetState, error) {
+	algodClient, err := GetAlgodClient(network)
+	if err != nil {
+		return AssetState{}, err
+	}
+	return getAssetState(algodClient, assetID)
+}
+
+func getAssetState(algodClient *algod.Client, assetID uint64) (AssetState, error) {
+	ctx := context.Background()
+	asset, err := algodClient.GetAssetByID(assetID).Do(ctx)
+	if err != nil {
+		if httpStatusCode(err) == 404 {
+			return AssetState{}, fmt.Errorf("%w: %d", ErrAssetNotFound, assetID)
+		}
+		return AssetState{}, err
+	}
+	p := asset.Params
+	st := AssetState{
+		ID:      assetID,
+		Creator: p.Creator,
+		Total:   p.Total,
+		Roles:   AssetRoles{Manager: p.Manager, Reserve: p.Reserve, Freeze: p.Freeze, Clawback: p.Clawback},
+	}
+	holding, err := algodClient.AccountAssetInformation(p.Creator, assetID).Do(ctx)
+	if err != nil {
+		return AssetState{}, fmt.Errorf("reading the holding of creator %s: %w", p.Creator, err)
+	}
+	st.CreatorHolding = holding.AssetHolding.Amount
+	return st, nil
+}
+
+// CheckManager checks that account can reconfigure or destroy the asset.
+func (st AssetState) CheckManager(account string) error {
+	if st.Roles.Manager == "" {
+		return fmt.Errorf("%w: asset %d can never be reconfigured or destroyed", ErrAssetImmutable, st.ID)
+	}
+	if st.Roles.Manager != account {
+		return fmt.Errorf("%w: %s is not the manager of asset %d, %s", ErrNotAssetManager, account, st.ID,
+			st.Roles.Manager)
+	}
+	return nil
+}
+
+// CheckDestroy checks that account can destroy the asset now.
+func (st AssetState) CheckDestroy(account string) error {
+	if err := st.CheckManager(account); err != nil {
+		return err
+	}
+	if st.CreatorHolding != st.Total {
+		return fmt.Errorf("%w: creator %s holds %d of the %d units of asset %d; the other %d must be sent back first",
+			ErrAssetSupplyOutstanding, st.Creator, st.CreatorHolding, st.Total, st.ID, st.Total-st.CreatorHolding)
+	}
+	return nil
+}
+
+// PlanAssetRoles returns the changes from the current to the requested roles
+// of an asset, in the order manager, reserve, freeze, clawback. Every role
+// removed must be in confirmedRemovals; the error for the others wraps
+// ErrRoleRemovalNotConfirmed and names them.
+func PlanAssetRoles(current, requested AssetRoles, confirmedRemovals []string) ([]RoleChange, error) {
+	if current.Manager == "" {
+		return nil, fmt.Errorf("%w: it can never be reconfigured", ErrAssetImmutable)
+	}
+	var changes []RoleChange
+	var unconfirmed []string
+	for _, role := range assetRoleNames {
+		c := RoleChange{Role: role, From: current.get(role), To: requested.get(role)}
+		if c.From == c.To {
+			continue
+		}
+		if c.To != "" {
+			if _, err := types.DecodeAddress(c.To); err != nil {
+				return nil, fmt.Errorf("invalid %s address: %w", role, err)
+			}
+		}
+		if c.From == "" {
+			return nil, fmt.Errorf("%w: %s", ErrAssetRoleCleared, role)
+		}
+		if c.Removed() && !slices.Contains(confirmedRemovals, role) {
+			unconfirmed = append(unconfirmed, role)
+		}
+		changes = append(changes, c)
+	}
+	if len(changes) == 0 {
+		return nil, ErrNoAssetRoleChange
+	}
+	if len(unconfirmed) > 0 {
+		return changes, fmt.Errorf("%w: removing %s is irreversible", ErrRoleRemovalNotConfirmed,
+			strings.Join(unconfirmed, ", "))
+	}
+	return changes, nil
+}
+
+type AssetOptions struct {
+	Network Network // default MainNet
+	// ConfirmedRemovals are the roles whose removal ReconfigureAsset may
+	// send; see PlanAssetRoles.
+	ConfirmedRemovals []string
+	// OnBroadcast is as in SendOptions.
+	OnBroadcast func(PendingGroup) error
+}
+
+// DestroyAsset destroys assetID with the PQ account of keyPair, which must be
+// its manager, once the creator holds all of its units. It returns the ID of
+// the transaction once confirmed.
+func DestroyAsset(keyPair falcongo.KeyPair, assetID uint64, opt AssetOptions) (string, error) {
+	return configureAsset(keyPair, assetID, opt, AssetState.CheckDestroy, transaction.MakeAssetDestroyTxn)
+}
+
+// ReconfigureAsset sets the roles of assetID to requested with the PQ
+// account of keyPair, which must be its manager. Roles removed must be in
+// opt.ConfirmedRemovals. It returns the ID of the transaction once confirmed
+// and the changes it made.
+func ReconfigureAsset(keyPair falcongo.KeyPair, assetID uint64, requested AssetRoles, opt AssetOptions,
+) (txID string, changes []RoleChange, err error) {
+
+	txID, err = configureAsset(keyPair, assetID, opt, func(st AssetState, account string) error {
+		if err := st.CheckManager(account); err != nil {
+			return err
+		}
+		changes, err = PlanAssetRoles(st.Roles, requested, opt.ConfirmedRemovals)
+		return err
+	}, func(account string, note []byte, sp types.SuggestedParams, index uint64) (types.Transaction, error) {
+		// An asset config transaction sets all four roles; those left empty
+		// are removed.
+		return transaction.MakeAssetConfigTxn(account, note, sp, index,
+			requested.Manager, requested.Reserve, requested.Freeze, requested.Clawback, false)
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return txID, changes, nil
+}
+
+// configureAsset sends the asset config transaction made by makeTxn from
+// the PQ account of keyPair, once check accepts the state of the asset.
+func configureAsset(keyPair falcongo.KeyPair, assetID uint64, opt AssetOptions,
+	check func(st AssetState, account string) error,
+	makeTxn func(account string, note []byte, sp types.SuggestedParams, index uint64) (types.Transaction, error),
+) (string, error) {
+
+	lsig, err := DerivePQLogicSig(keyPair.PublicKey)
+	if err != nil {
+		return "", err
+	}
+	address, err := lsig.Address()
+	if err != nil {
+		return "", err
+	}
+	algodClient, err := GetAlgodClient(opt.Network)
+	if err != nil {
+		return "", err
+	}
+	st, err := getAssetState(algodClient, assetID)
+	if err != nil {
+		return "", err
+	}
+	if err := check(st, address.String()); err != nil {
+		return "", err
+	}
+
+	sp, err := algodClient.SuggestedParams().Do(context.Background())
+	if err != nil {
+		return "", err
+	}
+	sp.FlatFee = true
+	sp.Fee = types.MicroAlgos(sp.MinFee)
+	txn, err := makeTxn(address.String(), nil, sp, assetID)
+	if err != nil {
+		return "", err
+	}
+	txIDs, _, err := sendPQGroup(algodClient, keyPair, lsig, []types.Transaction{txn}, 0, 0, opt.OnBroadcast)
+	if err != nil {
+		return "", err
+	}
+	return txIDs[0], nil
+}
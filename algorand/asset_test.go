@@ -0,0 +1,109 @@
+package algorand
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// TestPlanAssetRoles covers the changes and refusals of reconfigurations.
+func TestPlanAssetRoles(t *testing.T) {
+	a, b, c := types.Address{1}.String(), types.Address{2}.String(), types.Address{3}.String()
+	current := AssetRoles{Manager: a, Reserve: a, Freeze: b, Clawback: ""}
+
+	changes, err := PlanAssetRoles(current, AssetRoles{Manager: c, Reserve: a, Freeze: b}, nil)
+	if err != nil || len(changes) != 1 || changes[0] != (RoleChange{Role: RoleManager, From: a, To: c}) {
+		t.Fatalf("manager change: got %+v, %v", changes, err)
+	}
+
+	requested := AssetRoles{Manager: a, Reserve: "", Freeze: ""}
+	changes, err = PlanAssetRoles(current, requested, []string{RoleFreeze})
+	if !errors.Is(err, ErrRoleRemovalNotConfirmed) || len(changes) != 2 ||
+		!changes[0].Removed() || changes[0].Role != RoleReserve {
+		t.Fatalf("unconfirmed removal: got %+v, %v", changes, err)
+	}
+	if _, err := PlanAssetRoles(current, requested, []string{RoleReserve, RoleFreeze}); err != nil {
+		t.Fatalf("confirmed removals: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name      string
+		current   AssetRoles
+		requested AssetRoles
+		want      error
+	}{
+		{"no change", current, current, ErrNoAssetRoleChange},
+		{"cleared role", current, AssetRoles{Manager: a, Reserve: a, Freeze: b, Clawback: c}, ErrAssetRoleCleared},
+		{"no manager", AssetRoles{Reserve: a}, AssetRoles{Manager: a, Reserve: a}, ErrAssetImmutable},
+	} {
+		if _, err := PlanAssetRoles(tc.current, tc.requested, nil); !errors.Is(err, tc.want) {
+			t.Errorf("%s: got %v, want %v", tc.name, err, tc.want)
+		}
+	}
+	if _, err := PlanAssetRoles(current, AssetRoles{Manager: "not an address"}, nil); err == nil {
+		t.Fatal("expected an invalid address to be refused")
+	}
+}
+
+// TestCheckDestroy covers the preflight checks of DestroyAsset.
+func TestCheckDestroy(t *testing.T) {
+	manager, other := types.Address{1}.String(), types.Address{2}.String()
+	st := AssetState{ID: 5, Creator: other, Total: 100, CreatorHolding: 100, Roles: AssetRoles{Manager: manager}}
+	if err := st.CheckDestroy(manager); err != nil {
+		t.Fatalf("CheckDestroy failed: %v", err)
+	}
+	if err := st.CheckDestroy(other); !errors.Is(err, ErrNotAssetManager) {
+		t.Fatalf("not the manager: got %v", err)
+	}
+	held := st
+	held.CreatorHolding = 60
+	if err := held.CheckDestroy(manager); !errors.Is(err, ErrAssetSupplyOutstanding) {
+		t.Fatalf("supply outstanding: got %v", err)
+	}
+	immutable := st
+	immutable.Roles.Manager = ""
+	if err := immutable.CheckDestroy(manager); !errors.Is(err, ErrAssetImmutable) {
+		t.Fatalf("no manager: got %v", err)
+	}
+}
+
+// TestGetAssetState reads an asset and the holding of its creator from algod.
+func TestGetAssetState(t *testing.T) {
+	creator, manager := types.Address{1}.String(), types.Address{2}.String()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/assets/5":
+			_ = json.NewEncoder(w).Encode(models.Asset{Index: 5, Params: models.AssetParams{
+				Creator: creator, Manager: manager, Freeze: creator, Total: 100}})
+		case "/v2/accounts/" + creator + "/assets/5":
+			_ = json.NewEncoder(w).Encode(models.AccountAssetResponse{
+				AssetHolding: models.AssetHolding{AssetId: 5, Amount: 40}})
+		default:
+			http.Error(w, `{"message":"asset does not exist"}`, http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	c, err := algod.MakeClient(srv.URL, "")
+	if err != nil {
+		t.Fatalf("MakeClient failed: %v", err)
+	}
+
+	st, err := getAssetState(c, 5)
+	if err != nil {
+		t.Fatalf("getAssetState failed: %v", err)
+	}
+	want := AssetState{ID: 5, Creator: creator, Total: 100, CreatorHolding: 40,
+		Roles: AssetRoles{Manager: manager, Freeze: creator}}
+	if st != want {
+		t.Fatalf("got %+v, want %+v", st, want)
+	}
+	if _, err := getAssetState(c, 6); !errors.Is(err, ErrAssetNotFound) {
+		t.Fatalf("missing asset: got %v", err)
+	}
+}
@@ -0,0 +1,228 @@
+package algorand
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// Kinds of the transactions reported by ScanBlock.
+const (
+	// PQKindLogicSig is the PQ logicsig derived by DerivePQLogicSig, of any
+	// supported TEAL version and counter.
+	PQKindLogicSig = "pq-logicsig"
+	// PQKindProgram is another program on the pattern of the PQ logicsig:
+	// a version with falcon_verify and FALCON public keys as byte constants,
+	// such as the recovery, hybrid and policy logicsigs.
+	PQKindProgram = "pq-program"
+)
+
+// Anomalies reported by ScanBlock.
+const (
+	// AnomalyNoSignature is a PQ transaction whose arg 0 is not a FALCON
+	// signature.
+	AnomalyNoSignature = "no-signature"
+	// AnomalyInvalidSignature is a PQ transaction whose arg 0 is not a
+	// FALCON signature of its TxID by any key of the program: the program
+	// accepted it without one, or the block data is not what was signed.
+	AnomalyInvalidSignature = "invalid-signature"
+	// AnomalySignerMismatch is a PQ transaction whose program is not that of
+	// the account it was authorized for.
+	AnomalySignerMismatch = "signer-mismatch"
+)
+
+// PQTxn is a transaction of a block authorized by a PQ program.
+type PQTxn struct {
+	Round  uint64 `json:"round"`
+	TxID   string `json:"txid"`
+	Type   string `json:"type"`
+	Sender string `json:"sender"`
+	// Account is the account the program authorized the transaction for:
+	// the sender or, if the sender is rekeyed, the account it is rekeyed to.
+	// With a delegated logicsig it is an Ed25519 account; otherwise it is the
+	// account of the program.
+	Account     string `json:"account"`
+	Kind        string `json:"kind"`
+	TealVersion uint64 `json:"teal_version"`
+	Delegated   bool   `json:"delegated,omitempty"`
+	// Keys are the fingerprints (hex) of the FALCON public keys of the
+	// program; SignedBy is the one whose signature of the TxID is arg 0.
+	Keys     []string `json:"keys"`
+	SignedBy string   `json:"signed_by,omitempty"`
+	Anomaly  string   `json:"anomaly,omitempty"`
+}
+
+// BlockScan is the result of ScanRounds.
+type BlockScan struct {
+	FirstRound uint64 `json:"first_round"`
+	LastRound  uint64 `json:"last_round"`
+	// Transactions is the number of top-level transactions scanned.
+	Transactions int     `json:"transactions"`
+	PQ           []PQTxn `json:"pq_transactions"`
+}
+
+// Anomalies returns the number of PQ transactions with an anomaly.
+func (s BlockScan) Anomalies() int {
+	n := 0
+	for _, t := range s.PQ {
+		if t.Anomaly != "" {
+			n++
+		}
+	}
+	return n
+}
+
+// ScanRounds scans the blocks of rounds first to last (inclusive) from the
+// algod of network, which must be archival for old rounds, with ScanBlock.
+// onBlock, if not nil, is called after each block.
+func ScanRounds(first, last uint64, network Network, onBlock func(round uint64)) (BlockScan, error) {
+	if last < first {
+		return BlockScan{}, fmt.Errorf("last round %d is before first round %d", last, first)
+	}
+	algodClient, err := GetAlgodClient(network)
+	if err != nil {
+		return BlockScan{}, err
+	}
+	scan := BlockScan{FirstRound: first, LastRound: last, PQ: []PQTxn{}}
+	for round := first; round <= last; round++ {
+		block, err := algodClient.Block(round).Do(context.Background())
+		if err != nil {
+			return scan, fmt.Errorf("round %d: %w", round, err)
+		}
+		scan.PQ = append(scan.PQ, ScanBlock(block)...)
+		scan.Transactions += len(block.Payset)
+		if onBlock != nil {
+			onBlock(round)
+		}
+	}
+	return scan, nil
+}
+
+// ScanBlock finds the top-level transactions of block authorized by PQ
+// programs and verifies their FALCON signatures offline: arg 0 must be a
+// signature of the TxID by one of the keys of the program. Inner
+// transactions are not signed, so they are not scanned.
+func ScanBlock(block types.Block) []PQTxn {
+	var found []PQTxn
+	for _, stib := range block.Payset {
+		logic := stib.Lsig.Logic
+		version, n := binary.Uvarint(logic)
+		if len(logic) == 0 || n <= 0 || version < falconVerifyVersion {
+			continue
+		}
+		keys := embeddedPublicKeys(logic)
+		if len(keys) == 0 {
+			continue
+		}
+
+		txn := stib.Txn
+		// Blocks strip the genesis ID and hash from transactions; restore
+		// them to compute the TxID that was signed.
+		if stib.HasGenesisID {
+			txn.GenesisID = block.GenesisID
+		}
+		if txn.GenesisHash == (types.Digest{}) {
+			txn.GenesisHash = block.GenesisHash
+		}
+		account := txn.Sender
+		if !stib.AuthAddr.IsZero() {
+			account = stib.AuthAddr
+		}
+
+		t := PQTxn{
+			Round:       uint64(block.Round),
+			TxID:        crypto.GetTxID(txn),
+			Type:        string(txn.Type),
+			Sender:      txn.Sender.String(),
+			Account:     account.String(),
+			Kind:        PQKindProgram,
+			TealVersion: version,
+			Delegated:   stib.Lsig.Sig != (types.Signature{}) || len(stib.Lsig.Msig.Subsigs) > 0,
+		}
+		if isDerivedPQLogicSig(logic, keys[0]) {
+			t.Kind = PQKindLogicSig
+		}
+		for _, pk := range keys {
+			fp := falcongo.Fingerprint(pk)
+			t.Keys = append(t.Keys, hex.EncodeToString(fp[:]))
+		}
+
+		if !t.Delegated && crypto.AddressFromProgram(logic) != account {
+			t.Anomaly = AnomalySignerMismatch
+		}
+		if len(stib.Lsig.Args) == 0 || len(stib.Lsig.Args[0]) == 0 ||
+			len(stib.Lsig.Args[0]) > falcongo.MaxCompressedSignatureSize {
+			t.Anomaly = AnomalyNoSignature
+		} else {
+			for i, pk := range keys {
+				if falcongo.VerifyTransactionID(txn, stib.Lsig.Args[0], pk) == nil {
+					t.SignedBy = t.Keys[i]
+					break
+				}
+			}
+			if t.SignedBy == "" {
+				t.Anomaly = AnomalyInvalidSignature
+			}
+		}
+		found = append(found, t)
+	}
+	return found
+}
+
+// embeddedPublicKeys returns the FALCON public keys of a program: the byte
+// constants of falcongo.PublicKeySize bytes with the header byte of
+// FALCON-1024 public keys.
+func embeddedPublicKeys(program []byte) []falcongo.PublicKey {
+	constant := binary.AppendUvarint(nil, falcongo.PublicKeySize)
+	var keys []falcongo.PublicKey
+	for i := 0; ; {
+		j := bytes.Index(program[i:], constant)
+		if j < 0 {
+			return keys
+		}
+		start := i + j + len(constant)
+		if start+falcongo.PublicKeySize > len(program) {
+			return keys
+		}
+		if program[start] != falconPublicKeyHeader {
+			i = i + j + 1
+			continue
+		}
+		var pk falcongo.PublicKey
+		copy(pk[:], program[start:])
+		if !containsKey(keys, pk) {
+			keys = append(keys, pk)
+		}
+		i = start + falcongo.PublicKeySize
+	}
+}
+
+// falconPublicKeyHeader is the first byte of FALCON-1024 public keys: 0 then
+// log2 of the degree, 10.
+const falconPublicKeyHeader = 0x0a
+
+func containsKey(keys []falcongo.PublicKey, pk falcongo.PublicKey) bool {
+	for _, k := range keys {
+		if k == pk {
+			return true
+		}
+	}
+	return false
+}
+
+// isDerivedPQLogicSig reports whether program is the PQ logicsig of pk for a
+// supported TEAL version and any counter.
+func isDerivedPQLogicSig(program []byte, pk falcongo.PublicKey) bool {
+	layout, ok := pqLogicSigLayouts[program[0]]
+	if !ok || len(program) != pqLogicSigProgramSize {
+		return false
+	}
+	return bytes.Equal(program, layout.patch(pk, program[layout.counterOffset]))
+}
@@ -0,0 +1,136 @@
+package algorand
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestScanBlock scans a block with PQ transactions, valid and not, and
+// others.
+func TestScanBlock(t *testing.T) {
+	kp, err := falcongo.GenerateKeyPair(bytes.Repeat([]byte{6}, 48))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	backup, err := falcongo.GenerateKeyPair(bytes.Repeat([]byte{7}, 48))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	if kp.PublicKey[0] != falconPublicKeyHeader {
+		t.Fatalf("public key header %#x, want %#x", kp.PublicKey[0], falconPublicKeyHeader)
+	}
+	lsig, err := DerivePQLogicSig(kp.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, err := lsig.Address()
+	if err != nil {
+		t.Fatal(err)
+	}
+	recovery, err := DeriveRecoveryLogicSig(kp.PublicKey, backup.PublicKey, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recoveryAddress, err := recovery.Address()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sk := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{4}, ed25519.SeedSize))
+	delegation, err := DelegatePQLogicSig(kp.PublicKey, sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := types.Block{BlockHeader: types.BlockHeader{
+		Round: 42, GenesisID: "testnet-v1.0", GenesisHash: types.Digest{1, 2, 3},
+	}}
+	// add signs a payment from sender with signer and adds it to the block
+	// as algod does, without the genesis ID and hash.
+	add := func(signer pqSigner, sender types.Address, edit func(*types.SignedTxn)) string {
+		t.Helper()
+		txn := types.Transaction{Type: types.PaymentTx, Header: types.Header{
+			Sender: sender, Fee: 1000, FirstValid: 40, LastValid: 50,
+			GenesisID: block.GenesisID, GenesisHash: block.GenesisHash, Note: []byte{byte(len(block.Payset))},
+		}}
+		txID, signed, err := signer.sign(txn)
+		if err != nil {
+			t.Fatalf("sign failed: %v", err)
+		}
+		var stib types.SignedTxnInBlock
+		if err := msgpack.Decode(signed, &stib.SignedTxn); err != nil {
+			t.Fatal(err)
+		}
+		stib.Txn.GenesisID, stib.Txn.GenesisHash = "", types.Digest{}
+		stib.HasGenesisID, stib.HasGenesisHash = true, true
+		if edit != nil {
+			edit(&stib.SignedTxn)
+		}
+		block.Payset = append(block.Payset, stib)
+		return txID
+	}
+	pq := pqSigner{keyPair: kp, lsig: lsig}
+	validID := add(pq, address, nil)
+	tamperedID := add(pq, address, func(st *types.SignedTxn) { st.Lsig.Args[0][10] ^= 1 })
+	add(pq, address, func(st *types.SignedTxn) { st.Lsig.Args = nil })
+	add(pq, address, func(st *types.SignedTxn) { st.AuthAddr = types.Address{9} })
+	delegatedID := add(pqSigner{keyPair: kp, lsig: crypto.LogicSigAccount{Lsig: delegation.LogicSig}},
+		delegation.Delegator, nil)
+	recoveryID := add(pqSigner{keyPair: backup, lsig: recovery}, recoveryAddress, nil)
+	// An Ed25519 payment is not a PQ transaction.
+	edTxn := types.Transaction{Type: types.PaymentTx, Header: types.Header{Sender: types.Address{5}}}
+	_, edSigned, err := crypto.SignTransaction(sk, edTxn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var edStib types.SignedTxnInBlock
+	if err := msgpack.Decode(edSigned, &edStib.SignedTxn); err != nil {
+		t.Fatal(err)
+	}
+	block.Payset = append(block.Payset, edStib)
+
+	found := ScanBlock(block)
+	if len(found) != 6 {
+		t.Fatalf("found %d PQ transactions, want 6: %+v", len(found), found)
+	}
+	fp := falcongo.Fingerprint(kp.PublicKey)
+	keyFP := hex.EncodeToString(fp[:])
+	fp = falcongo.Fingerprint(backup.PublicKey)
+	backupFP := hex.EncodeToString(fp[:])
+
+	want := []struct {
+		txID, kind, anomaly, signedBy string
+		keys                          int
+	}{
+		{validID, PQKindLogicSig, "", keyFP, 1},
+		{tamperedID, PQKindLogicSig, AnomalyInvalidSignature, "", 1},
+		{"", PQKindLogicSig, AnomalyNoSignature, "", 1},
+		{"", PQKindLogicSig, AnomalySignerMismatch, keyFP, 1},
+		{delegatedID, PQKindLogicSig, "", keyFP, 1},
+		{recoveryID, PQKindProgram, "", backupFP, 2},
+	}
+	for i, w := range want {
+		got := found[i]
+		if (w.txID != "" && got.TxID != w.txID) || got.Kind != w.kind || got.Anomaly != w.anomaly ||
+			got.SignedBy != w.signedBy || len(got.Keys) != w.keys || got.Round != 42 || got.TealVersion != 12 {
+			t.Errorf("transaction %d: got %+v, want %+v", i, got, w)
+		}
+	}
+	if !found[4].Delegated || found[4].Account != delegation.Delegator.String() || found[0].Delegated {
+		t.Errorf("delegation not reported: %+v", found[4])
+	}
+	if found[3].Account != (types.Address{9}).String() || found[3].Sender != address.String() {
+		t.Errorf("rekeyed account not reported: %+v", found[3])
+	}
+	scan := BlockScan{PQ: found}
+	if scan.Anomalies() != 3 {
+		t.Errorf("Anomalies() = %d, want 3", scan.Anomalies())
+	}
+}
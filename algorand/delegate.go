@@ -0,0 +1,79 @@
+package algorand
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// ErrInvalidDelegation is returned for a delegation that is not the PQ
+// logicsig of the expected FALCON key signed by its delegating account.
+var ErrInvalidDelegation = errors.New("invalid delegation")
+
+// Delegation is the PQ logicsig of a FALCON key, signed by an existing
+// Ed25519 account. A transaction sent by the delegating account is then
+// authorized by the logicsig and a FALCON signature of its TxID, without the
+// Ed25519 key. The account keeps its address and its Ed25519 key keeps
+// working; only rekeying the account revokes the delegation.
+type Delegation struct {
+	// Delegator is the account that signed LogicSig.
+	Delegator types.Address
+	// LogicSig is the PQ logicsig program with the Ed25519 signature of
+	// Delegator, and no arguments.
+	LogicSig types.LogicSig
+}
+
+// DelegatePQLogicSig signs the PQ logicsig of publicKey, as derived by
+// DerivePQLogicSig, with the Ed25519 key of the delegating account. It fails
+// with falcongo.ErrSigningDisabled in read-only mode.
+func DelegatePQLogicSig(publicKey falcongo.PublicKey, delegator ed25519.PrivateKey) (Delegation, error) {
+	if falcongo.SigningDisabled() {
+		return Delegation{}, falcongo.ErrSigningDisabled
+	}
+	if len(delegator) != ed25519.PrivateKeySize {
+		return Delegation{}, fmt.Errorf("invalid Ed25519 private key length %d", len(delegator))
+	}
+	lsig, err := DerivePQLogicSig(publicKey)
+	if err != nil {
+		return Delegation{}, err
+	}
+	lsa, err := crypto.MakeLogicSigAccountDelegated(lsig.Lsig.Logic, nil, delegator)
+	if err != nil {
+		return Delegation{}, err
+	}
+	var address types.Address
+	copy(address[:], delegator.Public().(ed25519.PublicKey))
+	return Delegation{Delegator: address, LogicSig: lsa.Lsig}, nil
+}
+
+// Verify checks that d delegates to publicKey: its program is the PQ logicsig
+// of publicKey for one of TealVersions, and its signature is the delegator's.
+func (d Delegation) Verify(publicKey falcongo.PublicKey) error {
+	matches := false
+	for _, version := range TealVersions() {
+		lsig, err := DerivePQLogicSigWithOptions(publicKey, DeriveOptions{TealVersion: version})
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(lsig.Lsig.Logic, d.LogicSig.Logic) {
+			matches = true
+			break
+		}
+	}
+	if !matches {
+		return fmt.Errorf("%w: the program is not the PQ logicsig of the key", ErrInvalidDelegation)
+	}
+	if d.LogicSig.Sig == (types.Signature{}) {
+		return fmt.Errorf("%w: the logicsig is not signed", ErrInvalidDelegation)
+	}
+	if !crypto.VerifyLogicSig(d.LogicSig, d.Delegator) {
+		return fmt.Errorf("%w: the logicsig is not signed by %s", ErrInvalidDelegation, d.Delegator)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package algorand
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestDelegatePQLogicSig checks that the delegation signs the PQ logicsig of
+// the key with the delegating account, and that Verify refuses another key,
+// an unsigned logicsig and another delegator.
+func TestDelegatePQLogicSig(t *testing.T) {
+	pk, other := testRecoveryKeys()
+	sk := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{3}, ed25519.SeedSize))
+
+	d, err := DelegatePQLogicSig(pk, sk)
+	if err != nil {
+		t.Fatalf("DelegatePQLogicSig failed: %v", err)
+	}
+	if !bytes.Equal(d.Delegator[:], sk.Public().(ed25519.PublicKey)) {
+		t.Fatalf("delegator = %s, want the address of the Ed25519 key", d.Delegator)
+	}
+	lsig, err := DerivePQLogicSig(pk)
+	if err != nil {
+		t.Fatalf("DerivePQLogicSig failed: %v", err)
+	}
+	if !bytes.Equal(d.LogicSig.Logic, lsig.Lsig.Logic) || d.LogicSig.Args != nil {
+		t.Fatal("the delegated program is not the PQ logicsig of the key")
+	}
+	if err := d.Verify(pk); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	if err := d.Verify(other); !errors.Is(err, ErrInvalidDelegation) {
+		t.Fatalf("other key: got %v", err)
+	}
+	unsigned := d
+	unsigned.LogicSig.Sig = types.Signature{}
+	if err := unsigned.Verify(pk); !errors.Is(err, ErrInvalidDelegation) {
+		t.Fatalf("unsigned: got %v", err)
+	}
+	forged := d
+	forged.Delegator = types.Address{1}
+	if err := forged.Verify(pk); !errors.Is(err, ErrInvalidDelegation) {
+		t.Fatalf("other delegator: got %v", err)
+	}
+	if _, err := DelegatePQLogicSig(pk, sk[:32]); err == nil {
+		t.Fatal("expected a short Ed25519 key to be refused")
+	}
+}
+
+// TestDelegatedSignature checks that a transaction of the delegating account
+// signed with the delegation carries the program, the delegator's signature
+// and the FALCON signature as arg 0, and no authorizing address.
+func TestDelegatedSignature(t *testing.T) {
+	kp, err := falcongo.GenerateKeyPair(bytes.Repeat([]byte{9}, 48))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	sk := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{4}, ed25519.SeedSize))
+	d, err := DelegatePQLogicSig(kp.PublicKey, sk)
+	if err != nil {
+		t.Fatalf("DelegatePQLogicSig failed: %v", err)
+	}
+	txn := types.Transaction{Type: types.PaymentTx, Header: types.Header{Sender: d.Delegator}}
+	signature, err := kp.SignTransactionID(txn)
+	if err != nil {
+		t.Fatalf("SignTransactionID failed: %v", err)
+	}
+	signer := d.LogicSig
+	signer.Args = [][]byte{signature}
+	_, signed, err := crypto.SignLogicSigTransaction(signer, txn)
+	if err != nil {
+		t.Fatalf("SignLogicSigTransaction failed: %v", err)
+	}
+	var stx types.SignedTxn
+	if err := msgpack.Decode(signed, &stx); err != nil {
+		t.Fatalf("decoding the signed transaction: %v", err)
+	}
+	if stx.AuthAddr != (types.Address{}) || stx.Lsig.Sig != d.LogicSig.Sig ||
+		!bytes.Equal(stx.Lsig.Args[0], signature) || !crypto.VerifyLogicSig(stx.Lsig, d.Delegator) {
+		t.Fatalf("unexpected signed transaction: %+v", stx)
+	}
+}
@@ -0,0 +1,151 @@
+package algorand
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+var (
+	// ErrInvalidAlgodToken is returned when algod rejects the API token.
+	ErrInvalidAlgodToken = errors.New("algod rejected the API token")
+	// ErrDeveloperAPIDisabled is returned when algod does not serve
+	// /v2/teal/compile, which nodes only serve with EnableDeveloperAPI.
+	ErrDeveloperAPIDisabled = errors.New("algod does not compile TEAL (developer API disabled)")
+)
+
+// AlgodDiagnosis is what DiagnoseAlgod found out about the algod node of a
+// network. The checks run in order and stop at the first of Health and
+// Status that fails; the later fields are then left zero.
+type AlgodDiagnosis struct {
+	URL string
+	// Health is nil if the node answered GET /health, which needs no token.
+	Health error
+	// Status is nil if the node reported its status, which needs the token;
+	// it wraps ErrInvalidAlgodToken if the token was rejected.
+	Status    error
+	LastRound uint64
+	// Clock is nil if the time of the last block was read. ClockSkew is then
+	// the local clock minus the time of the network, that is of the last
+	// block plus the time since it, to about a second.
+	Clock     error
+	ClockSkew time.Duration
+	// Compile is nil if the node compiled the source of the PQ logicsig to
+	// the precompiled program, which needs falcon_verify. It is
+	// ErrDeveloperAPIDisabled if the node does not compile TEAL.
+	Compile error
+}
+
+// DiagnoseAlgod checks the algod node of network, as GetAlgodClient would
+// reach it. The error is only for a network without a node.
+func DiagnoseAlgod(network Network) (AlgodDiagnosis, error) {
+	algodURL, token, err := algodEndpoint(network)
+	if err != nil {
+		return AlgodDiagnosis{}, err
+	}
+	algodClient, err := algod.MakeClientWithTransport(algodURL, token, nil, algodTransport())
+	if err != nil {
+		return AlgodDiagnosis{URL: algodURL}, err
+	}
+	d := diagnoseAlgod(algodClient, time.Now)
+	d.URL = algodURL
+	return d, nil
+}
+
+func diagnoseAlgod(algodClient *algod.Client, now func() time.Time) AlgodDiagnosis {
+	ctx := context.Background()
+	var d AlgodDiagnosis
+	if d.Health = algodClient.HealthCheck().Do(ctx); d.Health != nil {
+		return d
+	}
+	status, err := algodClient.Status().Do(ctx)
+	if err != nil {
+		if httpStatusCode(err) == 401 {
+			err = fmt.Errorf("%w: %v", ErrInvalidAlgodToken, err)
+		}
+		d.Status = err
+		return d
+	}
+	received := now()
+	d.LastRound = status.LastRound
+
+	block, err := algodClient.Block(status.LastRound).HeaderOnly(true).Do(ctx)
+	if err != nil {
+		d.Clock = err
+	} else {
+		networkTime := time.Unix(block.TimeStamp, 0).Add(time.Duration(status.TimeSinceLastRound))
+		d.ClockSkew = received.Sub(networkTime)
+	}
+
+	d.Compile = checkCompile(algodClient)
+	return d
+}
+
+// checkCompile has algod compile the PQ logicsig of DefaultTealVersion, with
+// counter 0 and an all-zero public key, and compares it to the precompiled
+// program.
+func checkCompile(algodClient *algod.Client) error {
+	var zero falcongo.PublicKey
+	src, err := PQLogicSigSource(DefaultTealVersion, 0, zero)
+	if err != nil {
+		return err
+	}
+	result, err := algodClient.TealCompile([]byte(src)).Do(context.Background())
+	if err != nil {
+		if httpStatusCode(err) == 404 {
+			return ErrDeveloperAPIDisabled
+		}
+		return err
+	}
+	program, err := base64.StdEncoding.DecodeString(result.Result)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(program, patchPrecompiledPQlogicsig(zero, 0)) {
+		return fmt.Errorf("algod compiles the PQ logicsig of TEAL version %d differently from the precompiled program",
+			DefaultTealVersion)
+	}
+	return nil
+}
+
+// httpStatusCode returns the HTTP status of an error response of the SDK
+// clients, which format them as "HTTP <code>: <body>", or 0.
+func httpStatusCode(err error) int {
+	rest, ok := strings.CutPrefix(err.Error(), "HTTP ")
+	if !ok {
+		return 0
+	}
+	code, _, _ := strings.Cut(rest, ":")
+	n, err := strconv.Atoi(code)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// CheckPrecompiles assembles, offline, the PQ logicsig template for each
+// supported TEAL version and checks that it matches the precompiled program
+// that addresses are derived from, to catch a damaged binary.
+func CheckPrecompiles() error {
+	for _, v := range TealVersions() {
+		p, err := GeneratePrecompile(v)
+		if err != nil {
+			return fmt.Errorf("TEAL version %d: %w", v, err)
+		}
+		layout := pqLogicSigLayouts[v]
+		if !bytes.Equal(p.Prefix(), layout.prefix) || !bytes.Equal(p.Suffix(), layout.suffix) ||
+			p.CounterOffset != layout.counterOffset {
+			return fmt.Errorf("TEAL version %d: the precompiled PQ logicsig differs from its template", v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package algorand
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// fakeDiagnosedAlgod serves the algod endpoints used by DiagnoseAlgod.
+type fakeDiagnosedAlgod struct {
+	token     string
+	blockTime time.Time
+	sinceLast time.Duration
+	program   []byte // nil: no developer API
+}
+
+func (f fakeDiagnosedAlgod) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/health" && r.Header.Get("X-Algo-API-Token") != f.token {
+		http.Error(w, `{"message":"Invalid API Token"}`, http.StatusUnauthorized)
+		return
+	}
+	switch r.URL.Path {
+	case "/health":
+		_, _ = w.Write([]byte("null"))
+	case "/v2/status":
+		_ = json.NewEncoder(w).Encode(models.NodeStatus{LastRound: 7, TimeSinceLastRound: uint64(f.sinceLast)})
+	case "/v2/blocks/7":
+		var b types.Block
+		b.TimeStamp = f.blockTime.Unix()
+		_, _ = w.Write(msgpack.Encode(models.BlockResponse{Block: b}))
+	case "/v2/teal/compile":
+		if f.program == nil {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(models.CompileResponse{Result: base64.StdEncoding.EncodeToString(f.program)})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// TestDiagnoseAlgod covers each check of a node.
+func TestDiagnoseAlgod(t *testing.T) {
+	var zero falcongo.PublicKey
+	now := time.Unix(1_700_000_100, 0)
+	healthy := fakeDiagnosedAlgod{
+		token:     "secret",
+		blockTime: time.Unix(1_700_000_040, 0),
+		sinceLast: 2 * time.Second,
+		program:   patchPrecompiledPQlogicsig(zero, 0),
+	}
+	diagnose := func(f fakeDiagnosedAlgod, token string) AlgodDiagnosis {
+		srv := httptest.NewServer(f)
+		t.Cleanup(srv.Close)
+		c, err := algod.MakeClient(srv.URL, token)
+		if err != nil {
+			t.Fatalf("MakeClient failed: %v", err)
+		}
+		return diagnoseAlgod(c, func() time.Time { return now })
+	}
+
+	d := diagnose(healthy, "secret")
+	if d.Health != nil || d.Status != nil || d.Clock != nil || d.Compile != nil {
+		t.Fatalf("healthy node: got %+v", d)
+	}
+	if d.LastRound != 7 || d.ClockSkew != 58*time.Second {
+		t.Fatalf("healthy node: got round %d, skew %v; want 7, 58s", d.LastRound, d.ClockSkew)
+	}
+
+	d = diagnose(healthy, "wrong")
+	if !errors.Is(d.Status, ErrInvalidAlgodToken) || d.Compile != nil {
+		t.Fatalf("wrong token: got %+v", d)
+	}
+
+	noDevAPI := healthy
+	noDevAPI.program = nil
+	if d = diagnose(noDevAPI, "secret"); !errors.Is(d.Compile, ErrDeveloperAPIDisabled) {
+		t.Fatalf("no developer API: got %v", d.Compile)
+	}
+
+	otherProgram := healthy
+	otherProgram.program = []byte{0x0c, 0x85}
+	if d = diagnose(otherProgram, "secret"); d.Compile == nil || errors.Is(d.Compile, ErrDeveloperAPIDisabled) {
+		t.Fatalf("other program: got %v", d.Compile)
+	}
+
+	srv := httptest.NewServer(healthy)
+	srv.Close()
+	c, err := algod.MakeClient(srv.URL, "secret")
+	if err != nil {
+		t.Fatalf("MakeClient failed: %v", err)
+	}
+	if d = diagnoseAlgod(c, time.Now); d.Health == nil {
+		t.Fatalf("unreachable node: got %+v", d)
+	}
+}
+
+func TestCheckPrecompiles(t *testing.T) {
+	if err := CheckPrecompiles(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -25,4 +25,23 @@
 // all rejected. On average, two iterations suffice. In the vanishingly unlikely
 // event that all 256 counters yield Edwards25519 points, the FALCON public key is
 // deemed unsuitable to derive an Algorand account.
+//
+// The TEAL version is part of the program, so each version gives a key another address.
+// DerivePQLogicSig uses DefaultTealVersion (12); DerivePQLogicSigWithOptions derives the
+// logicsig of another supported version (TealVersions) to preview addresses before a
+// migration. The address of a key under a given version never changes.
+//
+// DeriveRecoveryLogicSig derives escrow accounts in the same way from two FALCON public keys
+// and a round: the primary key can sign at any time, and the backup key only transactions
+// whose first valid round is after the given round (see teal/RecoverylogicsigTMPL.teal).
+//
+// DeriveHybridLogicSig derives accounts from a FALCON and an Ed25519 public key whose
+// transactions need a signature of the TxID by both keys (see teal/HybridlogicsigTMPL.teal).
+//
+// DeriveLimitedPQLogicSig, DeriveTimeLockedPQLogicSig and DeriveAllowListPQLogicSig add a policy
+// to the PQ logicsig of a key: a spending limit per window of rounds, a round before which
+// nothing can be spent, or a list of the only receivers of payments (see the templates in teal/).
+// Their programs can be sent from with SendOptions.Program. A logicsig keeps no state, so the
+// limit holds per window of at most MaxPolicyWindow rounds, enforced by the lease that
+// PrepareLimitedTxn (SendOptions.LimitWindow) sets on each payment.
 package algorand
@@ -0,0 +1,99 @@
+package algorand
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// Built-in block explorers accepted by ExplorerURLs.
+const (
+	ExplorerAllo = "allo"
+	ExplorerPera = "pera"
+	ExplorerNone = "none"
+)
+
+// ExplorerLinks holds block explorer URLs for a transaction and its group.
+// A field is empty when the explorer has no page for it on the network.
+type ExplorerLinks struct {
+	TxID  string `json:"txid,omitempty"`
+	Group string `json:"group,omitempty"`
+}
+
+// explorerTemplates are the transaction and group URL templates of the
+// built-in explorers, per network. Networks without an entry get no links.
+var explorerTemplates = map[string]map[Network][2]string{
+	ExplorerAllo: {
+		MainNet: {"https://allo.info/tx/{txid}", "https://allo.info/tx/group/{group}"},
+		TestNet: {"https://testnet.allo.info/tx/{txid}", "https://testnet.allo.info/tx/group/{group}"},
+	},
+	ExplorerPera: {
+		MainNet: {"https://explorer.perawallet.app/tx/{txid}/",
+			"https://explorer.perawallet.app/tx-group/{group}/"},
+		TestNet: {"https://testnet.explorer.perawallet.app/tx/{txid}/",
+			"https://testnet.explorer.perawallet.app/tx-group/{group}/"},
+	},
+}
+
+// ExplorerURLs returns links to txID and its group on a block explorer.
+// explorer is a built-in name (ExplorerAllo, ExplorerPera, ExplorerNone) or
+// a custom transaction URL template, optionally followed by whitespace and a
+// group URL template. Templates may use {txid}, {group} and {network}
+// (mainnet, testnet, betanet or devnet). A zero groupID yields no group link.
+func ExplorerURLs(explorer string, network Network, txID string, groupID types.Digest,
+) (ExplorerLinks, error) {
+
+	explorer = strings.TrimSpace(explorer)
+	var templates [2]string
+	switch strings.ToLower(explorer) {
+	case "", ExplorerNone:
+		return ExplorerLinks{}, nil
+	case ExplorerAllo, ExplorerPera:
+		templates = explorerTemplates[strings.ToLower(explorer)][network]
+	default:
+		fields := strings.Fields(explorer)
+		if len(fields) > 2 || !strings.Contains(fields[0], "{txid}") {
+			return ExplorerLinks{}, fmt.Errorf(
+				"explorer must be %s, %s, %s or a URL template with {txid}",
+				ExplorerAllo, ExplorerPera, ExplorerNone)
+		}
+		copy(templates[:], fields)
+	}
+
+	group := ""
+	if groupID != (types.Digest{}) {
+		group = url.PathEscape(base64.StdEncoding.EncodeToString(groupID[:]))
+	}
+	r := strings.NewReplacer(
+		"{txid}", txID,
+		"{group}", group,
+		"{network}", networkName(network),
+	)
+	var links ExplorerLinks
+	if templates[0] != "" && txID != "" {
+		links.TxID = r.Replace(templates[0])
+	}
+	if templates[1] != "" && group != "" {
+		links.Group = r.Replace(templates[1])
+	}
+	return links, nil
+}
+
+// networkName returns the lowercase name of network used in flags and URLs.
+func networkName(network Network) string {
+	switch network {
+	case MainNet:
+		return "mainnet"
+	case TestNet:
+		return "testnet"
+	case BetaNet:
+		return "betanet"
+	case DevNet:
+		return "devnet"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,58 @@
+package algorand
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// TestExplorerURLs covers built-in explorers, networks and custom templates.
+func TestExplorerURLs(t *testing.T) {
+	group := types.Digest{0xfb, 0xff} // base64 "+/8AAA..." needs escaping
+	const txID = "TXID123"
+
+	links, err := ExplorerURLs(ExplorerAllo, MainNet, txID, group)
+	if err != nil {
+		t.Fatalf("ExplorerURLs failed: %v", err)
+	}
+	if links.TxID != "https://allo.info/tx/TXID123" {
+		t.Fatalf("unexpected txid link %q", links.TxID)
+	}
+	if !strings.HasPrefix(links.Group, "https://allo.info/tx/group/+%2F8") {
+		t.Fatalf("unexpected group link %q", links.Group)
+	}
+
+	links, err = ExplorerURLs("Pera", TestNet, txID, types.Digest{})
+	if err != nil {
+		t.Fatalf("ExplorerURLs failed: %v", err)
+	}
+	if links.TxID != "https://testnet.explorer.perawallet.app/tx/TXID123/" || links.Group != "" {
+		t.Fatalf("unexpected links %+v", links)
+	}
+
+	for _, name := range []string{ExplorerNone, ""} {
+		if links, err := ExplorerURLs(name, MainNet, txID, group); err != nil || links != (ExplorerLinks{}) {
+			t.Fatalf("%q: expected no links, got %+v, %v", name, links, err)
+		}
+	}
+	if links, err := ExplorerURLs(ExplorerAllo, DevNet, txID, group); err != nil || links != (ExplorerLinks{}) {
+		t.Fatalf("devnet: expected no links, got %+v, %v", links, err)
+	}
+
+	links, err = ExplorerURLs("https://x.test/{network}/tx/{txid} https://x.test/{network}/g/{group}",
+		DevNet, txID, group)
+	if err != nil {
+		t.Fatalf("ExplorerURLs failed: %v", err)
+	}
+	if links.TxID != "https://x.test/devnet/tx/TXID123" ||
+		!strings.HasPrefix(links.Group, "https://x.test/devnet/g/+%2F8") {
+		t.Fatalf("unexpected custom links %+v", links)
+	}
+
+	for _, bad := range []string{"etherscan", "https://x.test/tx", "https://x/{txid} b c"} {
+		if _, err := ExplorerURLs(bad, MainNet, txID, group); err == nil {
+			t.Fatalf("%q: expected error", bad)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package algorand
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+)
+
+// Fee strategies of SuggestFees.
+const (
+	// FeeMin pays the minimum fee for each transaction of the group. It is
+	// enough unless the network is congested.
+	FeeMin = "min"
+	// FeeSuggested pays the fee per byte suggested by the node for the size
+	// of each transaction, and at least the minimum fee.
+	FeeSuggested = "suggested"
+	// FeePriority pays a multiple of FeeSuggested, larger when recent blocks
+	// are nearly full.
+	FeePriority = "priority"
+)
+
+// FeeStrategies lists the strategies accepted by SuggestFees.
+var FeeStrategies = []string{FeeMin, FeeSuggested, FeePriority}
+
+const (
+	// maxBlockBytes is the consensus limit on the transactions of a block.
+	maxBlockBytes = 5 << 20
+	// feeSampleBlocks is how many recent blocks SuggestFees looks at.
+	feeSampleBlocks = 5
+	// txnSizeOverhead bounds the encoded size of a signed payment without
+	// its logicsig program and arguments.
+	txnSizeOverhead = 300
+	// FeePriority multiplies the suggested fees by priorityFactor, and by
+	// congestedPriorityFactor once blocks are congestedFullness full.
+	priorityFactor          = 2
+	congestedPriorityFactor = 4
+	congestedFullness       = 0.75
+)
+
+// FeeBreakdown is the fee of a send group: one PQ payment and the dummy
+// transactions covering the size of its logicsig. Fees are pooled, so the
+// payment pays for the whole group and the dummies pay nothing.
+type FeeBreakdown struct {
+	Strategy   string
+	MinFee     uint64  // minimum fee per transaction, in microAlgos
+	FeePerByte uint64  // fee per byte suggested by the node; 0 unless congested
+	Fullness   float64 // average fullness of recent blocks, from 0 to 1
+	DummyTxns  int
+	PQTxnFee   uint64 // the payment's own share, in microAlgos
+	DummyFee   uint64 // the share of each dummy transaction, in microAlgos
+	Total      uint64 // paid by the payment: PQTxnFee + DummyTxns*DummyFee
+}
+
+// SuggestFees proposes the fee of a send group under strategy from the
+// suggested params and the fullness of recent blocks. Pass the result to
+// Send in SendOptions.Fees.
+func SuggestFees(strategy string, network Network) (FeeBreakdown, error) {
+	algodClient, err := GetAlgodClient(network)
+	if err != nil {
+		return FeeBreakdown{}, err
+	}
+	return suggestFees(algodClient, strategy)
+}
+
+func suggestFees(algodClient *algod.Client, strategy string) (FeeBreakdown, error) {
+	switch strategy {
+	case FeeMin, FeeSuggested, FeePriority:
+	default:
+		return FeeBreakdown{}, fmt.Errorf("unknown fee strategy %q", strategy)
+	}
+	ctx := context.Background()
+	sp, err := algodClient.SuggestedParams().Do(ctx)
+	if err != nil {
+		return FeeBreakdown{}, err
+	}
+	b := FeeBreakdown{
+		Strategy:   strategy,
+		MinFee:     sp.MinFee,
+		FeePerByte: uint64(sp.Fee),
+		DummyTxns:  dummyTxnsNeeded(1),
+	}
+	if b.Fullness, err = blockFullness(algodClient, uint64(sp.FirstRoundValid)); err != nil {
+		return FeeBreakdown{}, err
+	}
+
+	// bySize is the fee the node asks for a transaction of size bytes.
+	bySize := func(size int) uint64 {
+		return max(b.MinFee, b.FeePerByte*uint64(size))
+	}
+	switch strategy {
+	case FeeMin:
+		b.PQTxnFee, b.DummyFee = b.MinFee, b.MinFee
+	case FeeSuggested, FeePriority:
+		b.PQTxnFee = bySize(pqLogicSigMaxSize + txnSizeOverhead)
+		b.DummyFee = bySize(len(dummyLsigCompiled) + txnSizeOverhead)
+		if strategy == FeePriority {
+			factor := uint64(priorityFactor)
+			if b.Fullness >= congestedFullness {
+				factor = congestedPriorityFactor
+			}
+			b.PQTxnFee *= factor
+			b.DummyFee *= factor
+		}
+	}
+	b.Total = b.PQTxnFee + uint64(b.DummyTxns)*b.DummyFee
+	return b, nil
+}
+
+// blockFullness returns the average fullness of the feeSampleBlocks blocks up
+// to lastRound, measured by their encoded size.
+func blockFullness(algodClient *algod.Client, lastRound uint64) (float64, error) {
+	n := min(uint64(feeSampleBlocks), lastRound)
+	if n == 0 {
+		return 0, nil
+	}
+	var total int
+	for round := lastRound - n + 1; round <= lastRound; round++ {
+		raw, err := algodClient.BlockRaw(round).Do(context.Background())
+		if err != nil {
+			return 0, fmt.Errorf("cannot read block %d: %w", round, err)
+		}
+		total += len(raw)
+	}
+	return math.Min(1, float64(total)/float64(n)/maxBlockBytes), nil
+}
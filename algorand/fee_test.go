@@ -0,0 +1,51 @@
+package algorand
+
+import "testing"
+
+// TestSuggestFees checks each strategy in a quiet and a congested network.
+func TestSuggestFees(t *testing.T) {
+	dummies := uint64(dummyTxnsNeeded(1))
+	pqSize := uint64(pqLogicSigMaxSize + txnSizeOverhead)
+	dummySize := uint64(len(dummyLsigCompiled) + txnSizeOverhead)
+	quiet := func() *fakeAlgod {
+		f := &fakeAlgod{round: 100, blockSize: map[uint64]int{}}
+		f.params.MinFee = 1000
+		return f
+	}
+	congested := func() *fakeAlgod {
+		f := quiet()
+		f.params.Fee = 10
+		for r := uint64(96); r <= 100; r++ {
+			f.blockSize[r] = maxBlockBytes * 9 / 10
+		}
+		return f
+	}
+	for _, tc := range []struct {
+		name     string
+		fake     *fakeAlgod
+		strategy string
+		pq, dum  uint64
+	}{
+		{"min", congested(), FeeMin, 1000, 1000},
+		{"suggested quiet", quiet(), FeeSuggested, 1000, 1000},
+		{"suggested congested", congested(), FeeSuggested, 10 * pqSize, 10 * dummySize},
+		{"priority quiet", quiet(), FeePriority, 2000, 2000},
+		{"priority congested", congested(), FeePriority, 40 * pqSize, 40 * dummySize},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := suggestFees(tc.fake.client(t), tc.strategy)
+			if err != nil {
+				t.Fatalf("suggestFees failed: %v", err)
+			}
+			if b.PQTxnFee != tc.pq || b.DummyFee != tc.dum || b.Total != tc.pq+dummies*tc.dum {
+				t.Fatalf("got %+v, want payment fee %d and dummy fee %d", b, tc.pq, tc.dum)
+			}
+		})
+	}
+	if b, err := suggestFees(congested().client(t), FeeMin); err != nil || b.Fullness < 0.89 || b.Fullness > 0.91 {
+		t.Fatalf("fullness = %v (err %v), want 0.9", b.Fullness, err)
+	}
+	if _, err := suggestFees(quiet().client(t), "fast"); err == nil {
+		t.Fatalf("expected an error for an unknown strategy")
+	}
+}
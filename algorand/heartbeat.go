@@ -0,0 +1,181 @@
+package algorand
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// An online account stays incentive-eligible only while it is seen: each
+// proposal or heartbeat resets the clock, and the account is suspended when
+// it is not seen for absenteeismFactor times the rounds it is expected to
+// take to propose, total online stake / its stake.
+const absenteeismFactor = 20
+
+// RoundTime is the approximate duration of a round, for turning rounds into
+// times in guidance.
+const RoundTime = 2800 * time.Millisecond
+
+type HeartbeatOptions struct {
+	Network Network // default MainNet
+	// OnBroadcast is as in SendOptions.
+	OnBroadcast func(PendingGroup) error
+}
+
+// HeartbeatStatus is what the network tracks to keep an online account
+// incentive-eligible.
+type HeartbeatStatus struct {
+	Address           string
+	Round             uint64 // the current round
+	Online            bool
+	IncentiveEligible bool
+	LastHeartbeat     uint64 // 0 if never
+	LastProposed      uint64 // 0 if never
+	Stake             uint64 // microAlgos
+	OnlineStake       uint64 // microAlgos online in the network
+	// AbsenceWindow is the number of rounds without a proposal or heartbeat
+	// after which the account is suspended; 0 if the account cannot be
+	// suspended for absence (offline, no stake, or a window too long to
+	// matter).
+	AbsenceWindow uint64
+	// Deadline is the last round the account can go unseen, 0 if it is not
+	// tracked yet (never seen since incentives started) or AbsenceWindow is 0.
+	Deadline uint64
+}
+
+// GetHeartbeatStatus returns the heartbeat status of the account address.
+func GetHeartbeatStatus(address string, network Network) (HeartbeatStatus, error) {
+	algodClient, err := GetAlgodClient(network)
+	if err != nil {
+		return HeartbeatStatus{}, err
+	}
+	return getHeartbeatStatus(algodClient, address)
+}
+
+func getHeartbeatStatus(algodClient *algod.Client, address string) (HeartbeatStatus, error) {
+	ctx := context.Background()
+	info, err := algodClient.AccountInformation(address).Do(ctx)
+	if err != nil {
+		return HeartbeatStatus{}, err
+	}
+	supply, err := algodClient.Supply().Do(ctx)
+	if err != nil {
+		return HeartbeatStatus{}, err
+	}
+	return heartbeatStatus(info, supply), nil
+}
+
+// heartbeatStatus computes the heartbeat status of an account from its
+// information and the supply, following the absenteeism rule of the ledger.
+func heartbeatStatus(info models.Account, supply models.Supply) HeartbeatStatus {
+	st := HeartbeatStatus{
+		Address:           info.Address,
+		Round:             supply.Current_round,
+		Online:            info.Status == "Online",
+		IncentiveEligible: info.IncentiveEligible,
+		LastHeartbeat:     info.LastHeartbeat,
+		LastProposed:      info.LastProposed,
+		Stake:             info.Amount,
+		OnlineStake:       supply.OnlineMoney,
+	}
+	if !st.Online || st.Stake == 0 {
+		return st
+	}
+	window := absenteeismFactor * float64(st.OnlineStake) / float64(st.Stake)
+	if window > math.MaxUint32 {
+		return st
+	}
+	st.AbsenceWindow = uint64(window)
+	if lastSeen := max(st.LastHeartbeat, st.LastProposed); lastSeen != 0 {
+		st.Deadline = lastSeen + st.AbsenceWindow
+	}
+	return st
+}
+
+// DecodeHeartbeatTxn decodes a msgpack heartbeat transaction, bare or in a
+// (signed or unsigned) transaction envelope as written by goal.
+func DecodeHeartbeatTxn(b []byte) (types.Transaction, error) {
+	txn, err := DecodeTxn(b)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	if txn.Type != types.HeartbeatTx || txn.HeartbeatTxnFields == nil {
+		return types.Transaction{}, fmt.Errorf("not a heartbeat transaction (type %q)", txn.Type)
+	}
+	return txn, nil
+}
+
+// SendHeartbeat sends the heartbeat transaction hb from the PQ account of
+// keyPair, which signs it and pays its fees, and waits for confirmation.
+//
+// The heartbeat proof must be made with the participation key of the
+// heartbeat address, so hb comes from the participation node; its validity
+// range, bound to the proof's block seed, is kept, while its sender and fee
+// are replaced. It returns the ID of the heartbeat transaction.
+func SendHeartbeat(keyPair falcongo.KeyPair, hb types.Transaction, opt HeartbeatOptions,
+) (txID string, err error) {
+
+	lsig, err := DerivePQLogicSig(keyPair.PublicKey)
+	if err != nil {
+		return "", err
+	}
+	sender, err := lsig.Address()
+	if err != nil {
+		return "", err
+	}
+	algodClient, err := GetAlgodClient(opt.Network)
+	if err != nil {
+		return "", err
+	}
+	sp, err := algodClient.SuggestedParams().Do(context.Background())
+	if err != nil {
+		return "", err
+	}
+	txn, err := makeHeartbeatTxn(sender, hb, sp)
+	if err != nil {
+		return "", err
+	}
+	txIDs, _, err := sendPQGroup(algodClient, keyPair, lsig, []types.Transaction{txn}, 0, 0,
+		opt.OnBroadcast)
+	if err != nil {
+		return "", err
+	}
+	return txIDs[0], nil
+}
+
+// makeHeartbeatTxn returns hb sent by sender, paying the minimum fee, and
+// checks that it can be evaluated in the round after that of sp.
+func makeHeartbeatTxn(sender types.Address, hb types.Transaction,
+	sp types.SuggestedParams,
+) (types.Transaction, error) {
+
+	if hb.Type != types.HeartbeatTx || hb.HeartbeatTxnFields == nil {
+		return types.Transaction{}, fmt.Errorf("not a heartbeat transaction (type %q)", hb.Type)
+	}
+	if hb.GenesisHash != (types.Digest{}) && string(hb.GenesisHash[:]) != string(sp.GenesisHash) {
+		return types.Transaction{}, fmt.Errorf("the heartbeat is for another network (genesis %s)", hb.GenesisID)
+	}
+	// The round of sp is the last committed round: the group is evaluated in
+	// the next one.
+	next := uint64(sp.FirstRoundValid) + 1
+	if uint64(hb.LastValid) < next {
+		return types.Transaction{}, fmt.Errorf("the heartbeat expired at round %d (next round %d); "+
+			"prepare a new one", hb.LastValid, next)
+	}
+	if uint64(hb.FirstValid) > next {
+		return types.Transaction{}, fmt.Errorf("the heartbeat is not valid before round %d (next round %d)",
+			hb.FirstValid, next)
+	}
+	txn := hb
+	txn.Sender = sender
+	txn.Fee = types.MicroAlgos(sp.MinFee)
+	txn.Group = types.Digest{}
+	return txn, nil
+}
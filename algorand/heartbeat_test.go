@@ -0,0 +1,80 @@
+package algorand
+
+import (
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+func TestHeartbeatStatus(t *testing.T) {
+	supply := models.Supply{Current_round: 10_000, OnlineMoney: 1_000_000_000}
+	for _, tc := range []struct {
+		name             string
+		info             models.Account
+		window, deadline uint64
+	}{
+		{"offline", models.Account{Status: "Offline", Amount: 1_000_000, LastProposed: 9000}, 0, 0},
+		{"online", models.Account{Status: "Online", Amount: 10_000_000, LastHeartbeat: 9000, LastProposed: 8000},
+			2000, 11_000},
+		{"never seen", models.Account{Status: "Online", Amount: 10_000_000}, 2000, 0},
+		{"tiny stake", models.Account{Status: "Online", Amount: 1, LastProposed: 9000}, 0, 0},
+	} {
+		st := heartbeatStatus(tc.info, supply)
+		if st.AbsenceWindow != tc.window || st.Deadline != tc.deadline || st.Round != supply.Current_round {
+			t.Fatalf("%s: window %d, deadline %d; want %d, %d", tc.name, st.AbsenceWindow, st.Deadline,
+				tc.window, tc.deadline)
+		}
+	}
+}
+
+// TestMakeHeartbeatTxn checks that a heartbeat prepared by a participation
+// node is taken over by the PQ account, and rejected when it cannot be sent.
+func TestMakeHeartbeatTxn(t *testing.T) {
+	sp := types.SuggestedParams{MinFee: 1000, FirstRoundValid: 100, LastRoundValid: 1100,
+		GenesisHash: make([]byte, 32)}
+	hb := types.Transaction{
+		Type: types.HeartbeatTx,
+		Header: types.Header{Sender: types.Address{9}, FirstValid: 95, LastValid: 105,
+			Group: types.Digest{7}},
+		HeartbeatTxnFields: &types.HeartbeatTxnFields{HbAddress: types.Address{1}, HbKeyDilution: 10_000},
+	}
+	encoded := msgpack.Encode(types.SignedTxn{Txn: hb})
+	decoded, err := DecodeHeartbeatTxn(encoded)
+	if err != nil {
+		t.Fatalf("DecodeHeartbeatTxn failed: %v", err)
+	}
+	if bare, err := DecodeHeartbeatTxn(msgpack.Encode(hb)); err != nil || bare.HbAddress != hb.HbAddress {
+		t.Fatalf("DecodeHeartbeatTxn of a bare transaction = %+v, %v", bare, err)
+	}
+
+	sender := types.Address{2}
+	txn, err := makeHeartbeatTxn(sender, decoded, sp)
+	if err != nil {
+		t.Fatalf("makeHeartbeatTxn failed: %v", err)
+	}
+	if txn.Sender != sender || txn.Fee != 1000 || txn.FirstValid != 95 || txn.LastValid != 105 ||
+		txn.Group != (types.Digest{}) || txn.HbAddress != hb.HbAddress || txn.HbKeyDilution != 10_000 {
+		t.Fatalf("unexpected heartbeat: %+v", txn)
+	}
+
+	expired := decoded
+	expired.LastValid = 100
+	if _, err := makeHeartbeatTxn(sender, expired, sp); err == nil {
+		t.Fatalf("expected an error for an expired heartbeat")
+	}
+	early := decoded
+	early.FirstValid = 102
+	if _, err := makeHeartbeatTxn(sender, early, sp); err == nil {
+		t.Fatalf("expected an error for a heartbeat not valid yet")
+	}
+	other := decoded
+	other.GenesisHash = types.Digest{1}
+	if _, err := makeHeartbeatTxn(sender, other, sp); err == nil {
+		t.Fatalf("expected an error for a heartbeat of another network")
+	}
+	if _, err := DecodeHeartbeatTxn(msgpack.Encode(types.Transaction{Type: types.PaymentTx})); err == nil {
+		t.Fatalf("expected an error for a payment")
+	}
+}
@@ -0,0 +1,114 @@
+package algorand
+
+import (
+	"crypto/ed25519"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// hybridLogicSigCounterOffset is the offset of the counter byte in the
+// programs built by hybridLogicSigProgram.
+const hybridLogicSigCounterOffset = pqLogicSigCounterOffset
+
+// The programs built by hybridLogicSigProgram take hybridLogicSigProgramSize
+// bytes. With a maximum-size compressed FALCON signature and an Ed25519
+// signature, a hybrid logicsig takes at most hybridLogicSigMaxSize bytes,
+// which fits in the budget of a PQ transaction and its dummy transactions.
+const (
+	hybridLogicSigProgramSize = 19 + ed25519.PublicKeySize + falcongo.PublicKeySize
+	hybridLogicSigMaxSize     = hybridLogicSigProgramSize + falcongo.MaxCompressedSignatureSize +
+		ed25519.SignatureSize
+)
+
+// DeriveHybridLogicSig returns a LogicSig that approves a transaction only
+// if it carries both a FALCON signature of its TxID by falconKey, as arg 0,
+// and an Ed25519 signature of its TxID by ed25519Key, as arg 1. The Ed25519
+// signature is the one of crypto.TealSign for the address of the LogicSig,
+// so it cannot be replayed elsewhere. Funds stay safe as long as either
+// scheme holds.
+//
+// Like DerivePQLogicSig, the derivation is deterministic and tries counter
+// values until the address does not decode to any Edwards25519 point.
+func DeriveHybridLogicSig(falconKey falcongo.PublicKey, ed25519Key ed25519.PublicKey,
+) (crypto.LogicSigAccount, error) {
+
+	if len(ed25519Key) != ed25519.PublicKeySize {
+		return crypto.LogicSigAccount{}, fmt.Errorf("invalid Ed25519 public key length %d", len(ed25519Key))
+	}
+	for counter := range 256 {
+		lsig := crypto.LogicSigAccount{
+			Lsig: types.LogicSig{
+				Logic: hybridLogicSigProgram(falconKey, ed25519Key, byte(counter)),
+			},
+		}
+		lsa, err := lsig.Address()
+		if err != nil {
+			return crypto.LogicSigAccount{}, err
+		}
+		if !isOnTheCurve(lsa[:]) {
+			return lsig, nil
+		}
+	}
+	return crypto.LogicSigAccount{}, ErrInvalidFalconPublicKey
+}
+
+// DeriveHybridAddress returns the address of the LogicSig derived by
+// DeriveHybridLogicSig and the counter value the derivation settled on.
+func DeriveHybridAddress(falconKey falcongo.PublicKey, ed25519Key ed25519.PublicKey,
+) (types.Address, byte, error) {
+
+	lsig, err := DeriveHybridLogicSig(falconKey, ed25519Key)
+	if err != nil {
+		return types.Address{}, 0, err
+	}
+	address, err := lsig.Address()
+	if err != nil {
+		return types.Address{}, 0, err
+	}
+	return address, lsig.Lsig.Logic[hybridLogicSigCounterOffset], nil
+}
+
+// hybridLogicSigProgram returns the compiled HybridlogicsigTMPL TEAL code
+// with the given keys and counter value:
+//
+//	bytes				| teal
+//	_______________________________________________________________________
+//	0c					| #pragma version 12
+//	26 01 01 00			| bytecblock 0x00 (counter)
+//	31 17				| txn TxID
+//	2e					| arg 1
+//	80 20 00...			| pushbytes 0x00... (32 Ed25519 key bytes)
+//	04					| ed25519verify
+//	44					| assert
+//	31 17				| txn TxID
+//	2d					| arg 0
+//	80 81 0e 00...		| pushbytes 0x00... (1793 FALCON key bytes)
+//	85					| falcon_verify
+func hybridLogicSigProgram(falconKey falcongo.PublicKey, ed25519Key ed25519.PublicKey,
+	counter byte) []byte {
+
+	program := []byte{0x0c, 0x26, 0x01, 0x01, 0x00}
+	program[hybridLogicSigCounterOffset] = counter
+	program = append(program, 0x31, 0x17, 0x2e, 0x80, 0x20)
+	program = append(program, ed25519Key...)
+	program = append(program, 0x04, 0x44, 0x31, 0x17, 0x2d, 0x80, 0x81, 0x0e)
+	program = append(program, falconKey[:]...)
+	return append(program, 0x85)
+}
+
+// hybridSigner returns the signer of sendSignedPQGroup for the hybrid
+// account of keyPair and ed25519Key.
+func hybridSigner(keyPair falcongo.KeyPair, ed25519Key ed25519.PrivateKey) (pqSigner, error) {
+	if len(ed25519Key) != ed25519.PrivateKeySize {
+		return pqSigner{}, fmt.Errorf("invalid Ed25519 private key length %d", len(ed25519Key))
+	}
+	lsig, err := DeriveHybridLogicSig(keyPair.PublicKey, ed25519Key.Public().(ed25519.PublicKey))
+	if err != nil {
+		return pqSigner{}, err
+	}
+	return pqSigner{keyPair: keyPair, lsig: lsig, ed25519Key: ed25519Key}, nil
+}
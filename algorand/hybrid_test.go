@@ -0,0 +1,110 @@
+package algorand
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestHybridLogicSigProgram checks the layout and size of the program, and
+// that its logicsigs fit in the padding of a PQ transaction.
+func TestHybridLogicSigProgram(t *testing.T) {
+	falconKey, _ := testRecoveryKeys()
+	edKey := ed25519.PublicKey(bytes.Repeat([]byte{0xed}, ed25519.PublicKeySize))
+	program := hybridLogicSigProgram(falconKey, edKey, 7)
+	if len(program) != hybridLogicSigProgramSize {
+		t.Fatalf("program size %d, want %d", len(program), hybridLogicSigProgramSize)
+	}
+	if program[hybridLogicSigCounterOffset] != 7 {
+		t.Fatalf("counter = %d, want 7", program[hybridLogicSigCounterOffset])
+	}
+	if !bytes.Equal(program[10:42], edKey) {
+		t.Fatal("Ed25519 key not found at 10")
+	}
+	if !bytes.Equal(program[len(program)-1-falcongo.PublicKeySize:len(program)-1], falconKey[:]) {
+		t.Fatal("FALCON key not found before falcon_verify")
+	}
+	if budget := (1 + dummyTxnsNeeded(1)) * logicSigBytesPerTxn; hybridLogicSigMaxSize > budget {
+		t.Fatalf("a hybrid logicsig takes up to %d bytes, more than the %d of its padding",
+			hybridLogicSigMaxSize, budget)
+	}
+}
+
+func TestDeriveHybridAddress(t *testing.T) {
+	falconKey, _ := testRecoveryKeys()
+	edKey := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{1}, ed25519.SeedSize)).Public().(ed25519.PublicKey)
+	address, counter, err := DeriveHybridAddress(falconKey, edKey)
+	if err != nil {
+		t.Fatalf("DeriveHybridAddress failed: %v", err)
+	}
+	if isOnTheCurve(address[:]) {
+		t.Fatal("hybrid address decodes to an Edwards25519 point")
+	}
+	if want := crypto.AddressFromProgram(hybridLogicSigProgram(falconKey, edKey, counter)); address != want {
+		t.Fatalf("address %s does not match the program for counter %d", address, counter)
+	}
+
+	pqAddress, _, err := DerivePQAddress(falconKey)
+	if err != nil {
+		t.Fatalf("DerivePQAddress failed: %v", err)
+	}
+	otherEd := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{2}, ed25519.SeedSize)).Public().(ed25519.PublicKey)
+	other, _, err := DeriveHybridAddress(falconKey, otherEd)
+	if err != nil {
+		t.Fatalf("DeriveHybridAddress failed: %v", err)
+	}
+	if address == pqAddress || address == other {
+		t.Fatalf("hybrid address %s is not specific to both keys", address)
+	}
+	if _, _, err := DeriveHybridAddress(falconKey, edKey[:31]); err == nil {
+		t.Fatal("expected a short Ed25519 key to be refused")
+	}
+}
+
+// TestHybridSign checks that a transaction of a hybrid account carries a
+// FALCON signature of its TxID as arg 0 and an Ed25519 one, bound to the
+// address of the logicsig, as arg 1.
+func TestHybridSign(t *testing.T) {
+	kp, err := falcongo.GenerateKeyPair(bytes.Repeat([]byte{8}, 48))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	sk := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{6}, ed25519.SeedSize))
+	signer, err := hybridSigner(kp, sk)
+	if err != nil {
+		t.Fatalf("hybridSigner failed: %v", err)
+	}
+	address, err := signer.lsig.Address()
+	if err != nil {
+		t.Fatalf("Address failed: %v", err)
+	}
+	txn := types.Transaction{Type: types.PaymentTx, Header: types.Header{Sender: address}}
+	_, signed, err := signer.sign(txn)
+	if err != nil {
+		t.Fatalf("sign failed: %v", err)
+	}
+	var stx types.SignedTxn
+	if err := msgpack.Decode(signed, &stx); err != nil {
+		t.Fatalf("decoding the signed transaction: %v", err)
+	}
+	if len(stx.Lsig.Args) != 2 {
+		t.Fatalf("expected 2 args, got %d", len(stx.Lsig.Args))
+	}
+	if err := falcongo.VerifyTransactionID(txn, stx.Lsig.Args[0], kp.PublicKey); err != nil {
+		t.Fatalf("arg 0 is not a FALCON signature of the TxID: %v", err)
+	}
+	var edSignature types.Signature
+	copy(edSignature[:], stx.Lsig.Args[1])
+	if !crypto.TealVerify(sk.Public().(ed25519.PublicKey), crypto.TransactionID(txn), address, edSignature) {
+		t.Fatal("arg 1 is not an Ed25519 signature of the TxID for the logicsig")
+	}
+	if _, err := hybridSigner(kp, sk[:32]); err == nil {
+		t.Fatal("expected a short Ed25519 key to be refused")
+	}
+}
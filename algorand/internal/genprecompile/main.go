@@ -0,0 +1,119 @@
+// Command genprecompile regenerates the precompiled PQlogicsig from the TEAL
+// template teal/PQlogicsigTMPL.teal, with the assembler of package algorand:
+// teal/PQlogicsig.teal and teal/PQlogicsig.teal.tok for the default TEAL
+// version, and, in precompile_gen.go, the program bytes around the public key
+// that patchPrecompiledPQlogicsig uses for each supported version, and
+// with -manifest, teal/templates.sha256, the manifest of
+// VerifyTemplateIntegrity. The manifest hashes the files written without
+// -manifest, so it takes another run, with package algorand rebuilt. Run it
+// with go generate ./algorand.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+func main() {
+	defaultVersion := flag.Uint("default", 12, "default TEAL version, of teal/PQlogicsig.teal(.tok)")
+	versionList := flag.String("versions", "12,13", "comma-separated TEAL versions to support")
+	dir := flag.String("dir", ".", "directory of package algorand")
+	manifest := flag.Bool("manifest", false, "write only teal/templates.sha256")
+	flag.Parse()
+	if *manifest {
+		path := filepath.Join(*dir, "teal/templates.sha256")
+		if err := os.WriteFile(path, algorand.GenerateTemplateManifest(), 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "genprecompile: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	var versions []byte
+	for _, v := range strings.Split(*versionList, ",") {
+		n, err := strconv.ParseUint(strings.TrimSpace(v), 10, 8)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "genprecompile: invalid -versions: %v\n", err)
+			os.Exit(2)
+		}
+		versions = append(versions, byte(n))
+	}
+	if err := generate(byte(*defaultVersion), versions, *dir); err != nil {
+		fmt.Fprintf(os.Stderr, "genprecompile: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func generate(defaultVersion byte, versions []byte, dir string) error {
+	var def *algorand.Precompile
+	var layouts bytes.Buffer
+	for _, v := range versions {
+		p, err := algorand.GeneratePrecompile(v)
+		if err != nil {
+			return fmt.Errorf("version %d: %w", v, err)
+		}
+		fmt.Fprintf(&layouts, "\t%d: {\n\t\tcounterOffset: %d,\n\t\tprefix: []byte{%s},\n\t\tsuffix: []byte{%s},\n\t},\n",
+			v, p.CounterOffset, byteList(p.Prefix()), byteList(p.Suffix()))
+		if v == defaultVersion {
+			def = &p
+		}
+	}
+	if def == nil {
+		return fmt.Errorf("the default version %d is not among -versions", defaultVersion)
+	}
+	src, err := algorand.PQLogicSigSource(defaultVersion, 0, falcongo.PublicKey{})
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by go run ./internal/genprecompile; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package algorand\n\n")
+	fmt.Fprintf(&b, "// pqLogicSigVersion is the TEAL version of the precompiled PQlogicsig.\n")
+	fmt.Fprintf(&b, "const pqLogicSigVersion = %d\n\n", def.Version)
+	fmt.Fprintf(&b, "// pqLogicSigCounterOffset is the offset of the counter byte in the programs\n")
+	fmt.Fprintf(&b, "// built by patchPrecompiledPQlogicsig.\n")
+	fmt.Fprintf(&b, "const pqLogicSigCounterOffset = %d\n\n", def.CounterOffset)
+	fmt.Fprintf(&b, "// pqLogicSigPrefix and pqLogicSigSuffix are the precompiled PQlogicsig\n")
+	fmt.Fprintf(&b, "// before and after the public key.\n")
+	fmt.Fprintf(&b, "var (\n\tpqLogicSigPrefix = [...]byte{%s}\n\tpqLogicSigSuffix = [...]byte{%s}\n)\n\n",
+		byteList(def.Prefix()), byteList(def.Suffix()))
+	fmt.Fprintf(&b, "// pqLogicSigLayouts are the precompiled PQlogicsigs of the supported TEAL\n")
+	fmt.Fprintf(&b, "// versions.\n")
+	fmt.Fprintf(&b, "var pqLogicSigLayouts = map[byte]pqLogicSigLayout{\n%s}\n", layouts.String())
+	code, err := format.Source(b.Bytes())
+	if err != nil {
+		return err
+	}
+
+	program := def.Program
+	for name, data := range map[string][]byte{
+		"precompile_gen.go":        code,
+		"teal/PQlogicsig.teal":     []byte(src),
+		"teal/PQlogicsig.teal.tok": program,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func byteList(b []byte) string {
+	var s bytes.Buffer
+	for i, c := range b {
+		if i > 0 {
+			s.WriteString(", ")
+		}
+		fmt.Fprintf(&s, "0x%02x", c)
+	}
+	return s.String()
+}
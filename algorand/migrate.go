@@ -0,0 +1,532 @@
+package algorand
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/kmd"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// ErrMigrationRekeyed is returned by RekeyToPQ and Sweep for an account that
+// is already rekeyed: its own Ed25519 key no longer authorizes it.
+var ErrMigrationRekeyed = errors.New("the account is rekeyed")
+
+// MigrationSource is the Ed25519 account migrated from: its address and how
+// its transactions are signed, with a local key (Ed25519Source) or by KMD
+// (KMDSource).
+type MigrationSource struct {
+	Address types.Address
+	sign    func(txn types.Transaction) (txID string, stxn []byte, err error)
+}
+
+// Ed25519Source is the account of an Ed25519 private key held in memory. Its
+// transactions are not signed in read-only mode (falcongo.DisableSigning).
+func Ed25519Source(sk ed25519.PrivateKey) (MigrationSource, error) {
+	address, err := crypto.GenerateAddressFromSK(sk)
+	if err != nil {
+		return MigrationSource{}, err
+	}
+	return MigrationSource{Address: address, sign: func(txn types.Transaction) (string, []byte, error) {
+		if falcongo.SigningDisabled() {
+			return "", nil, falcongo.ErrSigningDisabled
+		}
+		return crypto.SignTransaction(sk, txn)
+	}}, nil
+}
+
+type KMDOptions struct {
+	// URL and Token of the KMD; the KMD_URL and KMD_TOKEN environment
+	// variables if URL is empty.
+	URL, Token string
+	// Wallet is the name of the wallet; it may be empty if KMD has one.
+	Wallet   string
+	Password string
+	// Address is the account; it may be empty if the wallet has one.
+	Address string
+}
+
+// KMDSource is an account of a KMD wallet, which signs its transactions
+// without the key leaving KMD, except in read-only mode. The returned function
+// releases the wallet handle.
+func KMDSource(opt KMDOptions) (MigrationSource, func(), error) {
+	if opt.URL == "" {
+		opt.URL, opt.Token = os.Getenv("KMD_URL"), os.Getenv("KMD_TOKEN")
+	}
+	if opt.URL == "" {
+		return MigrationSource{}, nil, errors.New("KMD_URL not set")
+	}
+	client, err := kmd.MakeClient(opt.URL, opt.Token)
+	if err != nil {
+		return MigrationSource{}, nil, err
+	}
+	wallets, err := client.ListWallets()
+	if err != nil {
+		return MigrationSource{}, nil, fmt.Errorf("listing the KMD wallets: %w", err)
+	}
+	names := make([]string, len(wallets.Wallets))
+	for i, w := range wallets.Wallets {
+		names[i] = w.Name
+	}
+	i, err := pickOne("wallet", names, opt.Wallet)
+	if err != nil {
+		return MigrationSource{}, nil, err
+	}
+	handle, err := client.InitWalletHandle(wallets.Wallets[i].ID, opt.Password)
+	if err != nil {
+		return MigrationSource{}, nil, fmt.Errorf("opening KMD wallet %s: %w", names[i], err)
+	}
+	release := func() { _, _ = client.ReleaseWalletHandle(handle.WalletHandleToken) }
+	keys, err := client.ListKeys(handle.WalletHandleToken)
+	if err != nil {
+		release()
+		return MigrationSource{}, nil, fmt.Errorf("listing the keys of KMD wallet %s: %w", names[i], err)
+	}
+	j, err := pickOne("account", keys.Addresses, opt.Address)
+	if err != nil {
+		release()
+		return MigrationSource{}, nil, err
+	}
+	address, err := types.DecodeAddress(keys.Addresses[j])
+	if err != nil {
+		release()
+		return MigrationSource{}, nil, err
+	}
+	return MigrationSource{Address: address, sign: func(txn types.Transaction) (string, []byte, error) {
+		if falcongo.SigningDisabled() {
+			return "", nil, falcongo.ErrSigningDisabled
+		}
+		// A migration outlasts the default lifetime of a handle.
+		if _, err := client.RenewWalletHandle(handle.WalletHandleToken); err != nil {
+			return "", nil, fmt.Errorf("renewing the KMD wallet handle: %w", err)
+		}
+		resp, err := client.SignTransaction(handle.WalletHandleToken, opt.Password, txn)
+		if err != nil {
+			return "", nil, fmt.Errorf("KMD signing: %w", err)
+		}
+		return crypto.TransactionIDString(txn), resp.SignedTransaction, nil
+	}}, release, nil
+}
+
+// pickOne returns the index of want in items, or of the only item if want is
+// empty.
+func pickOne(what string, items []string, want string) (int, error) {
+	if want == "" {
+		switch len(items) {
+		case 0:
+			return 0, fmt.Errorf("KMD has no %s", what)
+		case 1:
+			return 0, nil
+		}
+		return 0, fmt.Errorf("KMD has %d %ss: name one of %s", len(items), what, strings.Join(items, ", "))
+	}
+	for i, item := range items {
+		if item == want {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("KMD %s %s not found", what, want)
+}
+
+// MigrationAsset is an asset holding of the migrated account.
+type MigrationAsset struct {
+	ID     uint64 `json:"asset_id"`
+	Amount uint64 `json:"amount"`
+	// OptedIn reports whether the PQ account already holds the asset.
+	OptedIn bool `json:"pq_opted_in"`
+	// Stays, if not empty, is why Sweep leaves the holding where it is.
+	Stays string `json:"stays,omitempty"`
+}
+
+// MigrationPlan is the state of an account about to migrate to a PQ account,
+// and what Sweep would move.
+type MigrationPlan struct {
+	From, To   types.Address
+	Amount     uint64
+	MinBalance uint64
+	// AuthAddr is the address the account is rekeyed to, if any.
+	AuthAddr string
+	Assets   []MigrationAsset
+	// Blockers are why Sweep cannot close the account, which then keeps its
+	// minimum balance.
+	Blockers []string
+}
+
+// Closable reports whether Sweep closes the account into the PQ account.
+func (p MigrationPlan) Closable() bool {
+	return len(p.Blockers) == 0
+}
+
+// MigrationStep is a confirmed transaction group of a migration.
+type MigrationStep struct {
+	// Action is "rekey", "asset" (opt the PQ account in and move a
+	// holding), "pay" or "close" (the Algos).
+	Action  string   `json:"action"`
+	AssetID uint64   `json:"asset_id,omitempty"`
+	Amount  uint64   `json:"amount,omitempty"`
+	TxIDs   []string `json:"txids"`
+	Round   uint64   `json:"round"`
+}
+
+type MigrationOptions struct {
+	Network Network // default MainNet
+	// OnStep, if set, is called after each confirmed step.
+	OnStep func(MigrationStep)
+}
+
+// PlanMigration reads the account from and the PQ account of publicKey, and
+// plans what Sweep would move.
+func PlanMigration(from types.Address, publicKey falcongo.PublicKey, network Network) (MigrationPlan, error) {
+	lsig, err := DerivePQLogicSig(publicKey)
+	if err != nil {
+		return MigrationPlan{}, err
+	}
+	to, err := lsig.Address()
+	if err != nil {
+		return MigrationPlan{}, err
+	}
+	algodClient, err := GetAlgodClient(network)
+	if err != nil {
+		return MigrationPlan{}, err
+	}
+	return planMigration(algodClient, from, to)
+}
+
+func planMigration(algodClient *algod.Client, from, to types.Address) (MigrationPlan, error) {
+	ctx := context.Background()
+	info, err := algodClient.AccountInformation(from.String()).Do(ctx)
+	if err != nil {
+		return MigrationPlan{}, err
+	}
+	pqInfo, err := algodClient.AccountInformation(to.String()).Do(ctx)
+	if err != nil {
+		return MigrationPlan{}, err
+	}
+	params := map[uint64]*models.AssetParams{}
+	for _, h := range info.Assets {
+		asset, err := algodClient.GetAssetByID(h.AssetId).Do(ctx)
+		if err != nil {
+			if httpStatusCode(err) != 404 {
+				return MigrationPlan{}, err
+			}
+			continue
+		}
+		params[h.AssetId] = &asset.Params
+	}
+	return migrationPlan(from, to, info, pqInfo, params), nil
+}
+
+// migrationPlan plans the migration of the account info to the PQ account
+// pqInfo; params are the parameters of the assets it holds, nil for those
+// that no longer exist.
+func migrationPlan(from, to types.Address, info, pqInfo models.Account, params map[uint64]*models.AssetParams,
+) MigrationPlan {
+
+	p := MigrationPlan{From: from, To: to, Amount: info.Amount, MinBalance: info.MinBalance, AuthAddr: info.AuthAddr}
+	pqHoldings := map[uint64]models.AssetHolding{}
+	for _, h := range pqInfo.Assets {
+		pqHoldings[h.AssetId] = h
+	}
+	for _, h := range info.Assets {
+		a := MigrationAsset{ID: h.AssetId, Amount: h.Amount}
+		pq, optedIn := pqHoldings[h.AssetId]
+		a.OptedIn = optedIn
+		ap := params[h.AssetId]
+		switch {
+		case ap == nil:
+			a.Stays = "the asset no longer exists"
+		case ap.Creator == from.String():
+			a.Stays = "the account created the asset; change its roles with asset-config"
+		case h.IsFrozen:
+			a.Stays = "the holding is frozen"
+		case optedIn && pq.IsFrozen, !optedIn && ap.DefaultFrozen:
+			a.Stays = "the holding of the PQ account would be frozen"
+		}
+		if a.Stays != "" {
+			p.Blockers = append(p.Blockers, fmt.Sprintf("holds asset %d: %s", a.ID, a.Stays))
+		}
+		p.Assets = append(p.Assets, a)
+	}
+	for _, a := range info.CreatedAssets {
+		p.Blockers = append(p.Blockers, fmt.Sprintf("created asset %d", a.Index))
+	}
+	for _, a := range info.CreatedApps {
+		p.Blockers = append(p.Blockers, fmt.Sprintf("created application %d", a.Id))
+	}
+	for _, a := range info.AppsLocalState {
+		p.Blockers = append(p.Blockers, fmt.Sprintf("opted into application %d", a.Id))
+	}
+	if info.Status == "Online" {
+		p.Blockers = append(p.Blockers, "online for consensus; go offline first")
+	}
+	return p
+}
+
+// CheckSource returns an error wrapping ErrMigrationRekeyed if the key of
+// p.From no longer authorizes its transactions.
+func (p MigrationPlan) CheckSource() error {
+	if p.AuthAddr == "" {
+		return nil
+	}
+	if p.AuthAddr == p.To.String() {
+		return fmt.Errorf("%w to its PQ account %s already", ErrMigrationRekeyed, p.To)
+	}
+	return fmt.Errorf("%w to %s", ErrMigrationRekeyed, p.AuthAddr)
+}
+
+// RekeyToPQ rekeys the account of source to the PQ account of the plan, which
+// from then on authorizes its transactions (algorand send --from); the
+// Ed25519 key no longer can. The account keeps its address, assets and
+// applications.
+func RekeyToPQ(source MigrationSource, plan MigrationPlan, opt MigrationOptions) (MigrationStep, error) {
+	if err := plan.CheckSource(); err != nil {
+		return MigrationStep{}, err
+	}
+	algodClient, err := GetAlgodClient(opt.Network)
+	if err != nil {
+		return MigrationStep{}, err
+	}
+	sp, err := algodClient.SuggestedParams().Do(context.Background())
+	if err != nil {
+		return MigrationStep{}, err
+	}
+	txn, err := makeRekeyTxn(source.Address, plan.To, sp)
+	if err != nil {
+		return MigrationStep{}, err
+	}
+	step := MigrationStep{Action: "rekey"}
+	if err := sendSourceGroup(algodClient, source, nil, []types.Transaction{txn}, sp, &step); err != nil {
+		return MigrationStep{}, err
+	}
+	if opt.OnStep != nil {
+		opt.OnStep(step)
+	}
+	return step, nil
+}
+
+// makeRekeyTxn builds a 0-Algo payment of from to itself rekeying it to to.
+func makeRekeyTxn(from, to types.Address, sp types.SuggestedParams) (types.Transaction, error) {
+	txn, err := transaction.MakePaymentTxn(from.String(), from.String(), 0, nil, "", sp)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	txn.RekeyTo = to
+	return txn, nil
+}
+
+// Sweep moves the holdings of the plan to the PQ account of keyPair, one
+// atomic group per asset: the account pays what the PQ account lacks for its
+// minimum balance, the PQ account opts in, and the account closes its
+// holding into it, paying all fees. It then closes the account into the PQ
+// account if the plan is Closable, or sends what exceeds its minimum balance
+// otherwise. It returns the steps confirmed, also on error.
+func Sweep(source MigrationSource, keyPair falcongo.KeyPair, plan MigrationPlan, opt MigrationOptions,
+) ([]MigrationStep, error) {
+
+	if err := plan.CheckSource(); err != nil {
+		return nil, err
+	}
+	lsig, err := DerivePQLogicSig(keyPair.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if to, err := lsig.Address(); err != nil || to != plan.To {
+		return nil, fmt.Errorf("the plan is for another PQ account than that of the key")
+	}
+	pq := pqSigner{keyPair: keyPair, lsig: lsig}
+	algodClient, err := GetAlgodClient(opt.Network)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+
+	var steps []MigrationStep
+	done := func(step MigrationStep) {
+		steps = append(steps, step)
+		if opt.OnStep != nil {
+			opt.OnStep(step)
+		}
+	}
+	for _, a := range plan.Assets {
+		if a.Stays != "" {
+			continue
+		}
+		pqInfo, err := algodClient.AccountInformation(plan.To.String()).Do(ctx)
+		if err != nil {
+			return steps, err
+		}
+		sp, err := algodClient.SuggestedParams().Do(ctx)
+		if err != nil {
+			return steps, err
+		}
+		var funding uint64
+		if !a.OptedIn {
+			funding = optInFunding(pqInfo.Amount, pqInfo.MinBalance)
+		}
+		txns, err := makeMigrationAssetTxns(plan.From, plan.To, a, funding, sp)
+		if err != nil {
+			return steps, err
+		}
+		step := MigrationStep{Action: "asset", AssetID: a.ID, Amount: a.Amount}
+		if err := sendSourceGroup(algodClient, source, &pq, txns, sp, &step); err != nil {
+			return steps, fmt.Errorf("moving asset %d: %w", a.ID, err)
+		}
+		done(step)
+	}
+
+	info, err := algodClient.AccountInformation(plan.From.String()).Do(ctx)
+	if err != nil {
+		return steps, err
+	}
+	sp, err := algodClient.SuggestedParams().Do(ctx)
+	if err != nil {
+		return steps, err
+	}
+	txn, step, err := makeMigrationAlgoTxn(plan, info, sp)
+	if err != nil || step.Action == "" {
+		return steps, err
+	}
+	if err := sendSourceGroup(algodClient, source, nil, []types.Transaction{txn}, sp, &step); err != nil {
+		return steps, fmt.Errorf("moving the Algos: %w", err)
+	}
+	done(step)
+	return steps, nil
+}
+
+// makeMigrationAssetTxns builds the transactions moving the holding a of
+// from to the PQ account to: a payment of funding (if any), the opt-in of to
+// (unless it holds the asset already) and the closing of the holding into
+// to, which pays the minimum fee of all of them and of the dummy
+// transactions the opt-in needs.
+func makeMigrationAssetTxns(from, to types.Address, a MigrationAsset, funding uint64, sp types.SuggestedParams,
+) ([]types.Transaction, error) {
+
+	sp.FlatFee = true
+	sp.Fee = 0
+	var txns []types.Transaction
+	if funding > 0 {
+		txn, err := transaction.MakePaymentTxn(from.String(), to.String(), funding, nil, "", sp)
+		if err != nil {
+			return nil, err
+		}
+		txns = append(txns, txn)
+	}
+	pqTxns := 0
+	if !a.OptedIn {
+		txn, err := transaction.MakeAssetAcceptanceTxn(to.String(), nil, sp, a.ID)
+		if err != nil {
+			return nil, err
+		}
+		txns = append(txns, txn)
+		pqTxns++
+	}
+	txn, err := transaction.MakeAssetTransferTxn(from.String(), to.String(), a.Amount, nil, sp, to.String(), a.ID)
+	if err != nil {
+		return nil, err
+	}
+	n := len(txns) + 1 + migrationDummyTxns(pqTxns, len(txns)+1)
+	txn.Fee = types.MicroAlgos(uint64(n) * sp.MinFee)
+	return append(txns, txn), nil
+}
+
+// migrationDummyTxns returns how many dummy transactions a group of txns
+// transactions, pqTxns of them PQ ones, needs to cover their logicsigs: the
+// other transactions add to the budget too.
+func migrationDummyTxns(pqTxns, txns int) int {
+	if pqTxns == 0 {
+		return 0
+	}
+	return max(0, pqTxns+dummyTxnsNeeded(pqTxns)-txns)
+}
+
+// makeMigrationAlgoTxn builds the last transaction of Sweep for the account
+// info: closing it into the PQ account if the plan is Closable, or else a
+// payment of what exceeds its minimum balance and fee. The step has no
+// action if there is nothing to send.
+func makeMigrationAlgoTxn(plan MigrationPlan, info models.Account, sp types.SuggestedParams,
+) (types.Transaction, MigrationStep, error) {
+
+	sp.FlatFee = true
+	sp.Fee = types.MicroAlgos(sp.MinFee)
+	if info.Amount < sp.MinFee {
+		return types.Transaction{}, MigrationStep{}, nil
+	}
+	if plan.Closable() {
+		txn, err := transaction.MakePaymentTxn(plan.From.String(), plan.To.String(), 0, nil, plan.To.String(), sp)
+		return txn, MigrationStep{Action: "close", Amount: info.Amount - sp.MinFee}, err
+	}
+	keep := info.MinBalance + sp.MinFee
+	if info.Amount <= keep {
+		return types.Transaction{}, MigrationStep{}, nil
+	}
+	amount := info.Amount - keep
+	txn, err := transaction.MakePaymentTxn(plan.From.String(), plan.To.String(), amount, nil, "", sp)
+	return txn, MigrationStep{Action: "pay", Amount: amount}, err
+}
+
+// sendSourceGroup groups txns, sent by source or by the PQ account of pq,
+// with the dummy transactions the PQ ones need, signs them, broadcasts the
+// group and waits for its confirmation, recording its TxIDs and round in
+// step. The dummy fees must be in those of txns already.
+func sendSourceGroup(algodClient *algod.Client, source MigrationSource, pq *pqSigner,
+	txns []types.Transaction, sp types.SuggestedParams, step *MigrationStep,
+) error {
+
+	pqTxns := 0
+	for _, txn := range txns {
+		if txn.Sender != source.Address {
+			pqTxns++
+		}
+	}
+	group := txns
+	dummies := migrationDummyTxns(pqTxns, len(txns))
+	if len(txns) > 1 || dummies > 0 {
+		var err error
+		if group, err = makeSendGroup(txns, len(txns)-1, sp, dummies, 0); err != nil {
+			return err
+		}
+	}
+
+	var signed []byte
+	for i, txn := range group {
+		var txID string
+		var stxn []byte
+		var err error
+		switch {
+		case i >= len(txns):
+			stxn, err = signDummyTxn(txn)
+		case txn.Sender == source.Address:
+			txID, stxn, err = source.sign(txn)
+		case pq != nil:
+			txID, stxn, err = pq.sign(txn)
+		default:
+			err = fmt.Errorf("no signer for %s", txn.Sender)
+		}
+		if err != nil {
+			return err
+		}
+		if txID != "" {
+			step.TxIDs = append(step.TxIDs, txID)
+		}
+		signed = append(signed, stxn...)
+	}
+	round, err := broadcastPQGroup(algodClient, PendingGroup{
+		TxIDs:       step.TxIDs,
+		GroupID:     group[0].Group,
+		SignedGroup: signed,
+		FirstValid:  uint64(group[0].FirstValid),
+		LastValid:   uint64(group[0].LastValid),
+	}, nil)
+	step.Round = round
+	return err
+}
@@ -0,0 +1,296 @@
+package algorand
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestMigrationPlan checks which holdings stay and why the account cannot be
+// closed.
+func TestMigrationPlan(t *testing.T) {
+	from, to, other := types.Address{1}, types.Address{2}, types.Address{3}
+	info := models.Account{
+		Amount:     5_000_000,
+		MinBalance: 600_000,
+		Assets: []models.AssetHolding{
+			{AssetId: 10, Amount: 7},                 // moves, PQ account opts in
+			{AssetId: 11, Amount: 8},                 // moves, PQ account opted in
+			{AssetId: 12, Amount: 9, IsFrozen: true}, // frozen
+			{AssetId: 13, Amount: 1},                 // created by the account
+			{AssetId: 14, Amount: 2},                 // deleted
+			{AssetId: 15, Amount: 3},                 // default frozen
+		},
+	}
+	pqInfo := models.Account{Assets: []models.AssetHolding{{AssetId: 11}}}
+	params := map[uint64]*models.AssetParams{
+		10: {Creator: other.String()},
+		11: {Creator: other.String()},
+		12: {Creator: other.String()},
+		13: {Creator: from.String()},
+		15: {Creator: other.String(), DefaultFrozen: true},
+	}
+
+	p := migrationPlan(from, to, info, pqInfo, params)
+	if p.From != from || p.To != to || p.Amount != info.Amount || p.MinBalance != info.MinBalance {
+		t.Fatalf("unexpected plan: %+v", p)
+	}
+	want := []MigrationAsset{
+		{ID: 10, Amount: 7},
+		{ID: 11, Amount: 8, OptedIn: true},
+		{ID: 12, Amount: 9, Stays: "the holding is frozen"},
+		{ID: 13, Amount: 1, Stays: "the account created the asset; change its roles with asset-config"},
+		{ID: 14, Amount: 2, Stays: "the asset no longer exists"},
+		{ID: 15, Amount: 3, Stays: "the holding of the PQ account would be frozen"},
+	}
+	if len(p.Assets) != len(want) {
+		t.Fatalf("expected %d assets, got %+v", len(want), p.Assets)
+	}
+	for i := range want {
+		if p.Assets[i] != want[i] {
+			t.Errorf("asset %d = %+v, want %+v", i, p.Assets[i], want[i])
+		}
+	}
+	if p.Closable() || len(p.Blockers) != 4 {
+		t.Fatalf("expected 4 blockers, got %q", p.Blockers)
+	}
+
+	info = models.Account{
+		Amount:         1_000_000,
+		CreatedAssets:  []models.Asset{{Index: 20}},
+		CreatedApps:    []models.Application{{Id: 21}},
+		AppsLocalState: []models.ApplicationLocalState{{Id: 22}},
+		Status:         "Online",
+	}
+	p = migrationPlan(from, to, info, models.Account{}, nil)
+	blockers := strings.Join(p.Blockers, "; ")
+	for _, b := range []string{"created asset 20", "created application 21", "opted into application 22", "online"} {
+		if !strings.Contains(blockers, b) {
+			t.Errorf("blockers lack %q: %s", b, blockers)
+		}
+	}
+
+	p = migrationPlan(from, to, models.Account{Amount: 1}, models.Account{}, nil)
+	if !p.Closable() || p.CheckSource() != nil {
+		t.Fatalf("expected a closable plan, got %+v", p)
+	}
+	p.AuthAddr = to.String()
+	if err := p.CheckSource(); !errors.Is(err, ErrMigrationRekeyed) || !strings.Contains(err.Error(), "already") {
+		t.Fatalf("expected the account to be rekeyed to its PQ account already, got %v", err)
+	}
+	p.AuthAddr = other.String()
+	if err := p.CheckSource(); !errors.Is(err, ErrMigrationRekeyed) {
+		t.Fatalf("expected ErrMigrationRekeyed, got %v", err)
+	}
+}
+
+// TestMakeMigrationAssetTxns checks the group moving a holding, with and
+// without funding and opt-in, and that the account pays every fee.
+func TestMakeMigrationAssetTxns(t *testing.T) {
+	sp := types.SuggestedParams{
+		Fee:             10,
+		MinFee:          1000,
+		FirstRoundValid: 1,
+		LastRoundValid:  1000,
+		GenesisID:       "test-v1",
+		GenesisHash:     make([]byte, 32),
+	}
+	from, to := types.Address{1}, types.Address{2}
+	const assetID = 99
+
+	for _, tc := range []struct {
+		name    string
+		optedIn bool
+		funding uint64
+		senders []types.Address
+		fee     uint64
+	}{
+		{"funded opt-in", false, 200_000, []types.Address{from, to, from}, 4000},
+		{"opt-in", false, 0, []types.Address{to, from}, 4000},
+		{"opted in", true, 0, []types.Address{from}, 1000},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			a := MigrationAsset{ID: assetID, Amount: 5, OptedIn: tc.optedIn}
+			txns, err := makeMigrationAssetTxns(from, to, a, tc.funding, sp)
+			if err != nil {
+				t.Fatalf("makeMigrationAssetTxns failed: %v", err)
+			}
+			if len(txns) != len(tc.senders) {
+				t.Fatalf("expected %d transactions, got %d", len(tc.senders), len(txns))
+			}
+			for i, txn := range txns {
+				if txn.Sender != tc.senders[i] {
+					t.Errorf("transaction %d sent by %s", i, txn.Sender)
+				}
+			}
+			if tc.funding > 0 && (txns[0].Receiver != to || txns[0].Amount != types.MicroAlgos(tc.funding)) {
+				t.Errorf("unexpected funding: %+v", txns[0])
+			}
+			last := txns[len(txns)-1]
+			if last.Type != types.AssetTransferTx || last.XferAsset != assetID || last.AssetAmount != 5 ||
+				last.AssetReceiver != to || last.AssetCloseTo != to || uint64(last.Fee) != tc.fee {
+				t.Fatalf("unexpected transfer: %+v", last)
+			}
+			for _, txn := range txns[:len(txns)-1] {
+				if txn.Fee != 0 {
+					t.Errorf("unexpected fee %d of %+v", txn.Fee, txn)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrationDummyTxns(t *testing.T) {
+	for _, tc := range []struct{ pqTxns, txns, want int }{
+		{0, 1, 0},
+		{1, 1, 3},
+		{1, 2, 2},
+		{1, 3, 1},
+		{1, 5, 0},
+	} {
+		if got := migrationDummyTxns(tc.pqTxns, tc.txns); got != tc.want {
+			t.Errorf("migrationDummyTxns(%d, %d) = %d, want %d", tc.pqTxns, tc.txns, got, tc.want)
+		}
+	}
+}
+
+// TestMakeMigrationAlgoTxn checks the closing of a closable account and the
+// payment of what exceeds the minimum balance otherwise.
+func TestMakeMigrationAlgoTxn(t *testing.T) {
+	sp := types.SuggestedParams{MinFee: 1000, LastRoundValid: 1000, GenesisHash: make([]byte, 32)}
+	from, to := types.Address{1}, types.Address{2}
+	plan := MigrationPlan{From: from, To: to}
+
+	txn, step, err := makeMigrationAlgoTxn(plan, models.Account{Amount: 500_000}, sp)
+	if err != nil {
+		t.Fatalf("makeMigrationAlgoTxn failed: %v", err)
+	}
+	if step.Action != "close" || step.Amount != 499_000 || txn.CloseRemainderTo != to || txn.Fee != 1000 {
+		t.Fatalf("unexpected close: %+v %+v", step, txn)
+	}
+
+	plan.Blockers = []string{"created application 1"}
+	txn, step, err = makeMigrationAlgoTxn(plan, models.Account{Amount: 500_000, MinBalance: 200_000}, sp)
+	if err != nil {
+		t.Fatalf("makeMigrationAlgoTxn failed: %v", err)
+	}
+	if step.Action != "pay" || step.Amount != 299_000 || txn.Amount != 299_000 || !txn.CloseRemainderTo.IsZero() {
+		t.Fatalf("unexpected payment: %+v %+v", step, txn)
+	}
+
+	_, step, err = makeMigrationAlgoTxn(plan, models.Account{Amount: 200_500, MinBalance: 200_000}, sp)
+	if err != nil || step.Action != "" {
+		t.Fatalf("expected nothing to send, got %+v, %v", step, err)
+	}
+}
+
+func TestMakeRekeyTxn(t *testing.T) {
+	sp := types.SuggestedParams{MinFee: 1000, LastRoundValid: 1000, GenesisHash: make([]byte, 32)}
+	from, to := types.Address{1}, types.Address{2}
+	txn, err := makeRekeyTxn(from, to, sp)
+	if err != nil {
+		t.Fatalf("makeRekeyTxn failed: %v", err)
+	}
+	if txn.Sender != from || txn.Receiver != from || txn.Amount != 0 || txn.RekeyTo != to {
+		t.Fatalf("unexpected rekey: %+v", txn)
+	}
+}
+
+func TestPickOne(t *testing.T) {
+	if i, err := pickOne("wallet", []string{"main"}, ""); err != nil || i != 0 {
+		t.Fatalf("expected the only wallet, got %d, %v", i, err)
+	}
+	if i, err := pickOne("wallet", []string{"a", "b"}, "b"); err != nil || i != 1 {
+		t.Fatalf("expected wallet b, got %d, %v", i, err)
+	}
+	if _, err := pickOne("wallet", []string{"a", "b"}, ""); err == nil {
+		t.Fatal("expected an error for two wallets and none named")
+	}
+	if _, err := pickOne("account", []string{"a"}, "c"); err == nil {
+		t.Fatal("expected an error for an unknown account")
+	}
+}
+
+// TestSendSourceGroup sends the group moving a holding to a fake algod and
+// checks who signed each transaction, the dummy padding and the fees.
+func TestSendSourceGroup(t *testing.T) {
+	kp, err := falcongo.GenerateKeyPair(bytes.Repeat([]byte{12}, 48))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	lsig, err := DerivePQLogicSig(kp.PublicKey)
+	if err != nil {
+		t.Fatalf("DerivePQLogicSig failed: %v", err)
+	}
+	to, err := lsig.Address()
+	if err != nil {
+		t.Fatalf("Address failed: %v", err)
+	}
+	source, err := Ed25519Source(ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)))
+	if err != nil {
+		t.Fatalf("Ed25519Source failed: %v", err)
+	}
+	fake := &fakeAlgod{round: 10, confirmPosted: true,
+		params: models.TransactionParametersResponse{MinFee: 1000, Fee: 0, GenesisId: "test-v1"}}
+	algodClient := fake.client(t)
+	sp, err := algodClient.SuggestedParams().Do(context.Background())
+	if err != nil {
+		t.Fatalf("SuggestedParams failed: %v", err)
+	}
+	txns, err := makeMigrationAssetTxns(source.Address, to, MigrationAsset{ID: 99, Amount: 5}, 200_000, sp)
+	if err != nil {
+		t.Fatalf("makeMigrationAssetTxns failed: %v", err)
+	}
+
+	step := MigrationStep{Action: "asset"}
+	pq := pqSigner{keyPair: kp, lsig: lsig}
+	if err := sendSourceGroup(algodClient, source, &pq, txns, sp, &step); err != nil {
+		t.Fatalf("sendSourceGroup failed: %v", err)
+	}
+	if len(step.TxIDs) != 3 || step.Round != 10 || len(fake.posted) != 1 {
+		t.Fatalf("unexpected step %+v after %d posts", step, len(fake.posted))
+	}
+
+	dec := msgpack.NewDecoder(bytes.NewReader(fake.posted[0]))
+	var group []types.SignedTxn
+	for {
+		var stxn types.SignedTxn
+		if dec.Decode(&stxn) != nil {
+			break
+		}
+		group = append(group, stxn)
+	}
+	if len(group) != 4 {
+		t.Fatalf("expected 3 transactions and 1 dummy, got %d", len(group))
+	}
+	var fees types.MicroAlgos
+	for i, stxn := range group {
+		fees += stxn.Txn.Fee
+		if stxn.Txn.Group == (types.Digest{}) || stxn.Txn.Group != group[0].Txn.Group {
+			t.Errorf("transaction %d is not in the group", i)
+		}
+		signedBySource := stxn.Sig != (types.Signature{})
+		if signedBySource != (stxn.Txn.Sender == source.Address) {
+			t.Errorf("transaction %d of %s has an Ed25519 signature: %v", i, stxn.Txn.Sender, signedBySource)
+		}
+	}
+	if fees != 4000 {
+		t.Errorf("the group pays %d, want 4000", fees)
+	}
+	if group[1].Txn.Sender != to || len(group[1].Lsig.Args) != 1 {
+		t.Errorf("the opt-in is not signed by the PQ logicsig: %+v", group[1].Lsig)
+	}
+	if id := crypto.GetTxID(group[2].Txn); id != step.TxIDs[2] {
+		t.Errorf("TxID %s, want %s", step.TxIDs[2], id)
+	}
+}
@@ -0,0 +1,157 @@
+package algorand
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// ErrFeeTooLow is returned when the fee of a send group is below the minimum
+// the network accepts for it.
+var ErrFeeTooLow = errors.New("fee too low")
+
+// maxTxnGroupSize is the consensus limit on the transactions of a group.
+const maxTxnGroupSize = 16
+
+// NetworkParams are the parameters of a network that the fees of a send group
+// depend on. MinFee and ConsensusVersion come from the node; the others are
+// the consensus parameters of this version that algod does not report.
+type NetworkParams struct {
+	GenesisID        string
+	ConsensusVersion string
+	MinFee           uint64 // minimum fee per transaction, in microAlgos
+	// LogicSigBytesPerTxn is what each transaction of a group adds to the
+	// pooled logicsig size budget.
+	LogicSigBytesPerTxn int
+	MaxGroupSize        int
+}
+
+// networkParamsFrom returns the NetworkParams of suggested params.
+func networkParamsFrom(sp types.SuggestedParams) NetworkParams {
+	return NetworkParams{
+		GenesisID:           sp.GenesisID,
+		ConsensusVersion:    sp.ConsensusVersion,
+		MinFee:              sp.MinFee,
+		LogicSigBytesPerTxn: logicSigBytesPerTxn,
+		MaxGroupSize:        maxTxnGroupSize,
+	}
+}
+
+// SendGroupSize returns how many transactions a group of pqTxns PQ
+// transactions has once padded with dummy transactions.
+func (p NetworkParams) SendGroupSize(pqTxns int) int {
+	return pqTxns + dummyTxnsNeeded(pqTxns)
+}
+
+// CheckSendFee returns an error wrapping ErrFeeTooLow if Send would pay fee
+// for its payment, plus dummyFee for each dummy transaction, and the group
+// would be rejected for too low a fee. A zero dummyFee is the minimum fee.
+func (p NetworkParams) CheckSendFee(fee, dummyFee uint64) error {
+	if dummyFee == 0 {
+		dummyFee = p.MinFee
+	}
+	size := p.SendGroupSize(1)
+	dummies := uint64(size - 1)
+	need := uint64(size) * p.MinFee
+	if total := fee + dummies*dummyFee; total < need {
+		return fmt.Errorf("%w: the group of %d transactions (the payment and %d padding) needs %d microAlgos "+
+			"(minimum fee %d each) and pays %d, %d of it for the padding; the payment needs a fee of at least %d",
+			ErrFeeTooLow, size, dummies, need, p.MinFee, total, dummies*dummyFee, need-dummies*dummyFee)
+	}
+	return nil
+}
+
+// networkParamsCache holds the NetworkParams of GetNetworkParams by network
+// and ALGOD_URL.
+var networkParamsCache struct {
+	sync.Mutex
+	params map[string]NetworkParams
+}
+
+// GetNetworkParams returns the parameters of network from its algod node
+// (see GetAlgodClient). They are queried once per process and node, as they
+// change only with a consensus upgrade.
+func GetNetworkParams(network Network) (NetworkParams, error) {
+	key := fmt.Sprintf("%d %s", network, os.Getenv("ALGOD_URL"))
+	networkParamsCache.Lock()
+	defer networkParamsCache.Unlock()
+	if p, ok := networkParamsCache.params[key]; ok {
+		return p, nil
+	}
+	algodClient, err := GetAlgodClient(network)
+	if err != nil {
+		return NetworkParams{}, err
+	}
+	p, err := getNetworkParams(algodClient)
+	if err != nil {
+		return NetworkParams{}, err
+	}
+	if networkParamsCache.params == nil {
+		networkParamsCache.params = map[string]NetworkParams{}
+	}
+	networkParamsCache.params[key] = p
+	return p, nil
+}
+
+func getNetworkParams(algodClient *algod.Client) (NetworkParams, error) {
+	sp, err := algodClient.SuggestedParams().Do(context.Background())
+	if err != nil {
+		return NetworkParams{}, err
+	}
+	return networkParamsFrom(sp), nil
+}
+
+// minRoundTime is a lower bound on the time between rounds, used to tell how
+// long cached suggested params remain usable.
+const minRoundTime = time.Second
+
+// cachedSuggestedParams are suggested params with the time they were fetched.
+type cachedSuggestedParams struct {
+	sp      types.SuggestedParams
+	fetched time.Time
+}
+
+// suggestedParamsCache holds the suggested params of GetSuggestedParams by
+// network and ALGOD_URL.
+var suggestedParamsCache struct {
+	sync.Mutex
+	params map[string]cachedSuggestedParams
+}
+
+// GetSuggestedParams returns the suggested params of network from its algod
+// node (see GetAlgodClient). They are reused within a process while at least
+// half of their validity window remains, assuming rounds of minRoundTime, so
+// that transactions built from them stay valid long enough to be sent.
+func GetSuggestedParams(network Network) (types.SuggestedParams, error) {
+	key := fmt.Sprintf("%d %s", network, os.Getenv("ALGOD_URL"))
+	suggestedParamsCache.Lock()
+	defer suggestedParamsCache.Unlock()
+	if c, ok := suggestedParamsCache.params[key]; ok && suggestedParamsFresh(c, time.Now()) {
+		return c.sp, nil
+	}
+	algodClient, err := GetAlgodClient(network)
+	if err != nil {
+		return types.SuggestedParams{}, err
+	}
+	sp, err := algodClient.SuggestedParams().Do(context.Background())
+	if err != nil {
+		return types.SuggestedParams{}, err
+	}
+	if suggestedParamsCache.params == nil {
+		suggestedParamsCache.params = map[string]cachedSuggestedParams{}
+	}
+	suggestedParamsCache.params[key] = cachedSuggestedParams{sp: sp, fetched: time.Now()}
+	return sp, nil
+}
+
+// suggestedParamsFresh reports whether c can still be used at now.
+func suggestedParamsFresh(c cachedSuggestedParams, now time.Time) bool {
+	window := uint64(c.sp.LastRoundValid) - uint64(c.sp.FirstRoundValid)
+	return now.Sub(c.fetched) < time.Duration(window/2)*minRoundTime
+}
@@ -0,0 +1,65 @@
+package algorand
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestCheckSendFee checks the minimum fee of a send group, padding included.
+func TestCheckSendFee(t *testing.T) {
+	p := NetworkParams{MinFee: 1000}
+	if size := p.SendGroupSize(1); size != 4 {
+		t.Fatalf("SendGroupSize(1) = %d, want 4", size)
+	}
+	for _, tc := range []struct {
+		fee, dummyFee uint64
+		ok            bool
+	}{
+		{1000, 0, true},
+		{999, 0, false},
+		{0, 0, false},
+		{0, 1500, true},
+		{0, 1300, false},
+		{500, 1500, true},
+		{4000, 1, true},
+	} {
+		err := p.CheckSendFee(tc.fee, tc.dummyFee)
+		if tc.ok != (err == nil) || err != nil && !errors.Is(err, ErrFeeTooLow) {
+			t.Fatalf("CheckSendFee(%d, %d) = %v, want ok %v", tc.fee, tc.dummyFee, err, tc.ok)
+		}
+	}
+	err := p.CheckSendFee(500, 0)
+	if err == nil || !strings.Contains(err.Error(), "needs 4000 microAlgos") ||
+		!strings.Contains(err.Error(), "at least 1000") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// TestGetNetworkParams checks that the params are queried once per node.
+func TestGetNetworkParams(t *testing.T) {
+	f := &fakeAlgod{round: 10}
+	f.params.MinFee = 1000
+	f.params.ConsensusVersion = "future"
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	t.Setenv("ALGOD_URL", srv.URL)
+
+	p, err := GetNetworkParams(DevNet)
+	if err != nil {
+		t.Fatalf("GetNetworkParams failed: %v", err)
+	}
+	if p.MinFee != 1000 || p.ConsensusVersion != "future" || p.LogicSigBytesPerTxn != logicSigBytesPerTxn {
+		t.Fatalf("unexpected params %+v", p)
+	}
+	f.mu.Lock()
+	f.params.MinFee = 2000
+	f.mu.Unlock()
+	if p, err = GetNetworkParams(DevNet); err != nil || p.MinFee != 1000 {
+		t.Fatalf("expected the cached params, got %+v (err %v)", p, err)
+	}
+	if p, err = GetNetworkParams(TestNet); err != nil || p.MinFee != 2000 {
+		t.Fatalf("expected the params of another network, got %+v (err %v)", p, err)
+	}
+}
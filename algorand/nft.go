@@ -0,0 +1,346 @@
+package algorand
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// NFT metadata standards: ARC-3 metadata is a JSON file the asset URL points
+// to, committed to by the metadata hash of the asset; ARC-69 metadata is
+// JSON in the note of the asset config transaction.
+const (
+	NFTStandardARC3  = "arc3"
+	NFTStandardARC69 = "arc69"
+)
+
+// arc69MaxNote is the size limit of a transaction note, which holds ARC-69
+// metadata.
+const arc69MaxNote = 1024
+
+// ARC-3 metadata hash domains, used when the metadata has extra_metadata.
+const (
+	arc3MetadataDomain     = "arc0003/amj"
+	arc3MetadataHashDomain = "arc0003/am"
+)
+
+// ErrNFTMetadata reports metadata that does not follow its standard.
+var ErrNFTMetadata = errors.New("invalid NFT metadata")
+
+// metadataField is the JSON type of a metadata field: string, integer,
+// object or array.
+type metadataField string
+
+// arc3Fields are the fields of the ARC-3 metadata JSON schema.
+var arc3Fields = map[string]metadataField{
+	"name":                    "string",
+	"decimals":                "integer",
+	"description":             "string",
+	"image":                   "string",
+	"image_integrity":         "string",
+	"image_mimetype":          "string",
+	"background_color":        "string",
+	"external_url":            "string",
+	"external_url_integrity":  "string",
+	"external_url_mimetype":   "string",
+	"animation_url":           "string",
+	"animation_url_integrity": "string",
+	"animation_url_mimetype":  "string",
+	"properties":              "object",
+	"extra_metadata":          "string",
+	"localization":            "object",
+}
+
+// arc69Fields are the fields of the ARC-69 metadata JSON schema.
+var arc69Fields = map[string]metadataField{
+	"standard":     "string",
+	"description":  "string",
+	"external_url": "string",
+	"media_url":    "string",
+	"properties":   "object",
+	"mime_type":    "string",
+	"attributes":   "array",
+}
+
+// NFT describes an NFT to mint: an asset of 10^Decimals units, all held by
+// the creator, with metadata following Standard.
+type NFT struct {
+	Standard string // NFTStandardARC3 or NFTStandardARC69
+	// Metadata is the metadata JSON. ARC-3 metadata is hashed as given, so it
+	// must be the exact bytes the URL serves; ARC-69 metadata is compacted
+	// into the note.
+	Metadata  []byte
+	UnitName  string
+	AssetName string
+	// URL is the URL of the ARC-3 metadata, which must end with #arc3 unless
+	// the asset name is arc3 or ends with @arc3, or the URL of the ARC-69
+	// media.
+	URL      string
+	Decimals uint32 // 0 for a pure NFT; fractional NFTs have 10^Decimals units
+}
+
+type MintOptions struct {
+	Network Network // default MainNet
+	// OnBroadcast is as in SendOptions.
+	OnBroadcast func(PendingGroup) error
+}
+
+// Total returns the number of units of the NFT.
+func (n NFT) Total() uint64 {
+	total := uint64(1)
+	for range n.Decimals {
+		total *= 10
+	}
+	return total
+}
+
+// Validate checks the metadata of the NFT against its standard and the
+// asset parameters against the metadata and the protocol limits.
+func (n NFT) Validate() error {
+	if n.Decimals > types.AssetMaxNumberOfDecimals {
+		return fmt.Errorf("too many decimals: %d > %d", n.Decimals, types.AssetMaxNumberOfDecimals)
+	}
+	if len(n.UnitName) > types.AssetUnitNameMaxLen {
+		return fmt.Errorf("unit name too long: %d > %d bytes", len(n.UnitName), types.AssetUnitNameMaxLen)
+	}
+	if len(n.AssetName) > types.AssetNameMaxLen {
+		return fmt.Errorf("asset name too long: %d > %d bytes", len(n.AssetName), types.AssetNameMaxLen)
+	}
+	if len(n.URL) > types.AssetURLMaxLen {
+		return fmt.Errorf("URL too long: %d > %d bytes", len(n.URL), types.AssetURLMaxLen)
+	}
+
+	switch n.Standard {
+	case NFTStandardARC3:
+		md, err := checkMetadata(n.Metadata, arc3Fields)
+		if err != nil {
+			return err
+		}
+		if err := checkARC3Metadata(md); err != nil {
+			return err
+		}
+		if d, ok := md["decimals"].(json.Number); ok && d.String() != fmt.Sprint(n.Decimals) {
+			return fmt.Errorf("%w: decimals is %s, but the asset has %d", ErrNFTMetadata, d, n.Decimals)
+		}
+		if n.AssetName != "arc3" && !strings.HasSuffix(n.AssetName, "@arc3") &&
+			!strings.HasSuffix(n.URL, "#arc3") {
+			return fmt.Errorf("the URL of an ARC-3 NFT must end with #arc3 (or the asset name with @arc3)")
+		}
+		if n.URL == "" {
+			return fmt.Errorf("an ARC-3 NFT needs the URL of its metadata")
+		}
+	case NFTStandardARC69:
+		md, err := checkMetadata(n.Metadata, arc69Fields)
+		if err != nil {
+			return err
+		}
+		if md["standard"] != NFTStandardARC69 {
+			return fmt.Errorf("%w: standard must be %q", ErrNFTMetadata, NFTStandardARC69)
+		}
+		note, err := n.note()
+		if err != nil {
+			return err
+		}
+		if len(note) > arc69MaxNote {
+			return fmt.Errorf("%w: %d bytes, more than the %d of a note", ErrNFTMetadata, len(note), arc69MaxNote)
+		}
+	default:
+		return fmt.Errorf("unknown NFT standard %q (want %s or %s)", n.Standard, NFTStandardARC3, NFTStandardARC69)
+	}
+	return nil
+}
+
+// checkMetadata parses metadata as a JSON object and checks the types of the
+// given fields; other fields are allowed.
+func checkMetadata(metadata []byte, fields map[string]metadataField) (map[string]any, error) {
+	dec := json.NewDecoder(bytes.NewReader(metadata))
+	dec.UseNumber()
+	var md map[string]any
+	if err := dec.Decode(&md); err != nil {
+		return nil, fmt.Errorf("%w: not a JSON object: %v", ErrNFTMetadata, err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("%w: data after the JSON object", ErrNFTMetadata)
+	}
+	for name, want := range fields {
+		v, ok := md[name]
+		if !ok {
+			continue
+		}
+		var valid bool
+		switch want {
+		case "string":
+			_, valid = v.(string)
+		case "integer":
+			n, isNumber := v.(json.Number)
+			i, err := n.Int64()
+			valid = isNumber && err == nil && i >= 0
+		case "object":
+			_, valid = v.(map[string]any)
+		case "array":
+			_, valid = v.([]any)
+		}
+		if !valid {
+			return nil, fmt.Errorf("%w: %s must be a JSON %s", ErrNFTMetadata, name, want)
+		}
+	}
+	return md, nil
+}
+
+// checkARC3Metadata checks the formats the ARC-3 schema gives some fields.
+func checkARC3Metadata(md map[string]any) error {
+	for _, prefix := range []string{"image", "external_url", "animation_url"} {
+		if s, ok := md[prefix+"_integrity"].(string); ok {
+			digest, found := strings.CutPrefix(s, "sha256-")
+			if b, err := base64.StdEncoding.DecodeString(digest); !found || err != nil || len(b) != sha256.Size {
+				return fmt.Errorf("%w: %s_integrity must be sha256-<base64 digest>", ErrNFTMetadata, prefix)
+			}
+		}
+		if s, ok := md[prefix+"_mimetype"].(string); ok && !strings.Contains(s, "/") {
+			return fmt.Errorf("%w: %s_mimetype %q is not a MIME type", ErrNFTMetadata, prefix, s)
+		}
+	}
+	if s, ok := md["extra_metadata"].(string); ok {
+		if _, err := base64.StdEncoding.DecodeString(s); err != nil {
+			return fmt.Errorf("%w: extra_metadata must be base64", ErrNFTMetadata)
+		}
+	}
+	if l, ok := md["localization"].(map[string]any); ok {
+		uri, _ := l["uri"].(string)
+		def, _ := l["default"].(string)
+		locales, _ := l["locales"].([]any)
+		if !strings.Contains(uri, "{locale}") || def == "" || len(locales) == 0 {
+			return fmt.Errorf("%w: localization needs uri (with {locale}), default and locales", ErrNFTMetadata)
+		}
+	}
+	return nil
+}
+
+// ARC3MetadataHash returns the metadata hash of an ARC-3 asset: the SHA-256
+// of its metadata JSON or, if the metadata has extra_metadata,
+// SHA-512/256("arc0003/am" || SHA-512/256("arc0003/amj" || metadata) ||
+// extra_metadata).
+func ARC3MetadataHash(metadata []byte) ([32]byte, error) {
+	md, err := checkMetadata(metadata, arc3Fields)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	extra, ok := md["extra_metadata"].(string)
+	if !ok {
+		return sha256.Sum256(metadata), nil
+	}
+	extraBytes, err := base64.StdEncoding.DecodeString(extra)
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("%w: extra_metadata must be base64", ErrNFTMetadata)
+	}
+	am := sha512.Sum512_256(append([]byte(arc3MetadataDomain), metadata...))
+	h := sha512.New512_256()
+	h.Write([]byte(arc3MetadataHashDomain))
+	h.Write(am[:])
+	h.Write(extraBytes)
+	var hash [32]byte
+	copy(hash[:], h.Sum(nil))
+	return hash, nil
+}
+
+// note returns the note of the asset creation: the compacted ARC-69
+// metadata, or nothing for ARC-3.
+func (n NFT) note() ([]byte, error) {
+	if n.Standard != NFTStandardARC69 {
+		return nil, nil
+	}
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, n.Metadata); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNFTMetadata, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// MintNFT creates the NFT from the PQ account of keyPair, which holds all its
+// units and is its manager (so it can update ARC-69 metadata later); it has
+// no reserve, freeze or clawback address. It returns the ID of the
+// transaction and of the asset once confirmed.
+func MintNFT(keyPair falcongo.KeyPair, nft NFT, opt MintOptions,
+) (txID string, assetID uint64, err error) {
+
+	if err := nft.Validate(); err != nil {
+		return "", 0, err
+	}
+	lsig, err := DerivePQLogicSig(keyPair.PublicKey)
+	if err != nil {
+		return "", 0, err
+	}
+	creator, err := lsig.Address()
+	if err != nil {
+		return "", 0, err
+	}
+	algodClient, err := GetAlgodClient(opt.Network)
+	if err != nil {
+		return "", 0, err
+	}
+	ctx := context.Background()
+	sp, err := algodClient.SuggestedParams().Do(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+	txn, err := makeNFTCreateTxn(creator, nft, sp)
+	if err != nil {
+		return "", 0, err
+	}
+
+	txIDs, _, err := sendPQGroup(algodClient, keyPair, lsig, []types.Transaction{txn}, 0, 0, opt.OnBroadcast)
+	if err != nil {
+		return "", 0, err
+	}
+	info, _, err := algodClient.PendingTransactionInformation(txIDs[0]).Do(ctx)
+	if err != nil {
+		return txIDs[0], 0, fmt.Errorf("NFT created by %s, but its asset ID could not be read: %w", txIDs[0], err)
+	}
+	return txIDs[0], info.AssetIndex, nil
+}
+
+// makeNFTCreateTxn builds the asset creation transaction of nft, sent by
+// creator at the minimum fee.
+func makeNFTCreateTxn(creator types.Address, nft NFT, sp types.SuggestedParams) (types.Transaction, error) {
+	note, err := nft.note()
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	var metadataHash string
+	if nft.Standard == NFTStandardARC3 {
+		hash, err := ARC3MetadataHash(nft.Metadata)
+		if err != nil {
+			return types.Transaction{}, err
+		}
+		metadataHash = string(hash[:])
+	}
+	sp.FlatFee = true
+	sp.Fee = types.MicroAlgos(sp.MinFee)
+	return transaction.MakeAssetCreateTxn(
+		creator.String(), // creator
+		note,             // note
+		sp,               // suggested params
+		nft.Total(),      // total
+		nft.Decimals,     // decimals
+		false,            // default frozen
+		creator.String(), // manager
+		"",               // reserve
+		"",               // freeze
+		"",               // clawback
+		nft.UnitName,     // unit name
+		nft.AssetName,    // asset name
+		nft.URL,          // URL
+		metadataHash,     // metadata hash
+	)
+}
@@ -0,0 +1,113 @@
+package algorand
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// TestNFTValidate checks NFTs against the ARC-3 and ARC-69 rules.
+func TestNFTValidate(t *testing.T) {
+	arc3 := NFT{Standard: NFTStandardARC3, Metadata: []byte(`{"name":"Cat","decimals":0,"image":"ipfs://x"}`),
+		UnitName: "CAT", AssetName: "Cat", URL: "ipfs://meta#arc3"}
+	arc69 := NFT{Standard: NFTStandardARC69, Metadata: []byte(`{"standard":"arc69","attributes":[]}`),
+		UnitName: "CAT", AssetName: "Cat", URL: "ipfs://media#i"}
+	for _, n := range []NFT{arc3, arc69} {
+		if err := n.Validate(); err != nil {
+			t.Fatalf("Validate(%s) failed: %v", n.Standard, err)
+		}
+	}
+	named := arc3
+	named.AssetName, named.URL = "Cat@arc3", "ipfs://meta"
+	if err := named.Validate(); err != nil {
+		t.Fatalf("Validate(@arc3 name) failed: %v", err)
+	}
+
+	metadataErrors := map[string]NFT{
+		"not an object":     {Standard: NFTStandardARC3, Metadata: []byte(`[1]`), URL: "x#arc3"},
+		"trailing data":     {Standard: NFTStandardARC3, Metadata: []byte(`{} {}`), URL: "x#arc3"},
+		"name type":         {Standard: NFTStandardARC3, Metadata: []byte(`{"name":1}`), URL: "x#arc3"},
+		"negative decimals": {Standard: NFTStandardARC3, Metadata: []byte(`{"decimals":-1}`), URL: "x#arc3"},
+		"decimals mismatch": {Standard: NFTStandardARC3, Metadata: []byte(`{"decimals":2}`), URL: "x#arc3"},
+		"integrity":         {Standard: NFTStandardARC3, Metadata: []byte(`{"image_integrity":"md5-AA=="}`), URL: "x#arc3"},
+		"mimetype":          {Standard: NFTStandardARC3, Metadata: []byte(`{"image_mimetype":"png"}`), URL: "x#arc3"},
+		"localization":      {Standard: NFTStandardARC3, Metadata: []byte(`{"localization":{"uri":"x"}}`), URL: "x#arc3"},
+		"arc69 standard":    {Standard: NFTStandardARC69, Metadata: []byte(`{"standard":"arc3"}`)},
+		"arc69 attributes":  {Standard: NFTStandardARC69, Metadata: []byte(`{"standard":"arc69","attributes":{}}`)},
+		"arc69 too long": {Standard: NFTStandardARC69,
+			Metadata: []byte(`{"standard":"arc69","description":"` + string(bytes.Repeat([]byte("x"), 1024)) + `"}`)},
+	}
+	for name, n := range metadataErrors {
+		if err := n.Validate(); !errors.Is(err, ErrNFTMetadata) {
+			t.Errorf("%s: Validate() = %v, want ErrNFTMetadata", name, err)
+		}
+	}
+	for name, n := range map[string]NFT{
+		"no #arc3":   {Standard: NFTStandardARC3, Metadata: []byte(`{}`), URL: "ipfs://meta"},
+		"standard":   {Standard: "arc19", Metadata: []byte(`{}`)},
+		"unit name":  {Standard: NFTStandardARC69, Metadata: arc69.Metadata, UnitName: "TOOLONGNAME"},
+		"decimals":   {Standard: NFTStandardARC69, Metadata: arc69.Metadata, Decimals: 20},
+		"url length": {Standard: NFTStandardARC69, Metadata: arc69.Metadata, URL: string(bytes.Repeat([]byte("u"), 97))},
+	} {
+		if err := n.Validate(); err == nil {
+			t.Errorf("%s: Validate() succeeded", name)
+		}
+	}
+}
+
+// TestARC3MetadataHash checks both ARC-3 metadata hashes.
+func TestARC3MetadataHash(t *testing.T) {
+	metadata := []byte(`{"name":"Cat"}`)
+	got, err := ARC3MetadataHash(metadata)
+	if err != nil || got != sha256.Sum256(metadata) {
+		t.Fatalf("ARC3MetadataHash = %x, %v; want SHA-256 of the metadata", got, err)
+	}
+
+	metadata = []byte(`{"name":"Cat","extra_metadata":"AQI="}`)
+	am := sha512.Sum512_256(append([]byte("arc0003/amj"), metadata...))
+	want := sha512.Sum512_256(append(append([]byte("arc0003/am"), am[:]...), 1, 2))
+	got, err = ARC3MetadataHash(metadata)
+	if err != nil || got != want {
+		t.Fatalf("ARC3MetadataHash with extra_metadata = %x, %v; want %x", got, err, want)
+	}
+}
+
+// TestMakeNFTCreateTxn checks the asset parameters and note of minted NFTs.
+func TestMakeNFTCreateTxn(t *testing.T) {
+	sp := types.SuggestedParams{
+		Fee:             10,
+		MinFee:          1000,
+		FirstRoundValid: 1,
+		LastRoundValid:  1000,
+		GenesisID:       "test-v1",
+		GenesisHash:     make([]byte, 32),
+	}
+	creator := types.Address{1}
+
+	metadata := []byte(`{"name":"Cat", "decimals": 2}`)
+	txn, err := makeNFTCreateTxn(creator, NFT{Standard: NFTStandardARC3, Metadata: metadata,
+		UnitName: "CAT", AssetName: "Cat", URL: "ipfs://meta#arc3", Decimals: 2}, sp)
+	if err != nil {
+		t.Fatalf("makeNFTCreateTxn(arc3) failed: %v", err)
+	}
+	p := txn.AssetParams
+	if p.Total != 100 || p.Decimals != 2 || p.Manager != creator || p.MetadataHash != sha256.Sum256(metadata) ||
+		p.URL != "ipfs://meta#arc3" || p.UnitName != "CAT" || p.AssetName != "Cat" ||
+		(p.Reserve != types.Address{}) || txn.Fee != 1000 || txn.Note != nil {
+		t.Fatalf("unexpected ARC-3 transaction: %+v", txn)
+	}
+
+	txn, err = makeNFTCreateTxn(creator, NFT{Standard: NFTStandardARC69,
+		Metadata: []byte("{\n  \"standard\": \"arc69\"\n}\n"), AssetName: "Cat"}, sp)
+	if err != nil {
+		t.Fatalf("makeNFTCreateTxn(arc69) failed: %v", err)
+	}
+	if string(txn.Note) != `{"standard":"arc69"}` || txn.AssetParams.Total != 1 ||
+		(txn.AssetParams.MetadataHash != [32]byte{}) {
+		t.Fatalf("unexpected ARC-69 transaction: note %q, params %+v", txn.Note, txn.AssetParams)
+	}
+}
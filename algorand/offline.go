@@ -0,0 +1,68 @@
+package algorand
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// ErrStaleGroup is returned by SubmitGroup for a group whose validity window
+// has passed, e.g. one built from an old suggested params snapshot.
+var ErrStaleGroup = errors.New("transaction group is stale")
+
+// ErrWrongNetwork is returned by SubmitGroup for a group built for another
+// network than the one of the node.
+var ErrWrongNetwork = errors.New("transaction group is for another network")
+
+// SubmitGroup broadcasts a group signed earlier, e.g. by SignSend on an
+// offline machine, and waits for its confirmation. It first checks the group
+// against the node of network: it must be for that network and its validity
+// window must include the next round. onBroadcast is called as in SendOptions.
+func SubmitGroup(g PendingGroup, network Network, onBroadcast func(PendingGroup) error) error {
+	if BroadcastDisabled() {
+		return ErrBroadcastDisabled
+	}
+	algodClient, err := GetAlgodClient(network)
+	if err != nil {
+		return err
+	}
+	// Not GetSuggestedParams: the check needs the current round.
+	sp, err := algodClient.SuggestedParams().Do(context.Background())
+	if err != nil {
+		return err
+	}
+	if err := checkSubmittable(g, sp); err != nil {
+		return err
+	}
+	_, err = broadcastPQGroup(algodClient, g, onBroadcast)
+	return err
+}
+
+// checkSubmittable checks the first transaction of g against the suggested
+// params of the node it is to be sent to.
+func checkSubmittable(g PendingGroup, sp types.SuggestedParams) error {
+	var stxn types.SignedTxn
+	if err := msgpack.NewDecoder(bytes.NewReader(g.SignedGroup)).Decode(&stxn); err != nil {
+		return fmt.Errorf("invalid signed group: %w", err)
+	}
+	txn := stxn.Txn
+	if !bytes.Equal(txn.GenesisHash[:], sp.GenesisHash) {
+		return fmt.Errorf("%w: it is for %s and the node for %s", ErrWrongNetwork,
+			txn.GenesisID, sp.GenesisID)
+	}
+	// The next block is the first the group can be committed in.
+	next := uint64(sp.FirstRoundValid) + 1
+	if uint64(txn.LastValid) < next {
+		return fmt.Errorf("%w: it was valid through round %d and the network is at round %d",
+			ErrStaleGroup, txn.LastValid, sp.FirstRoundValid)
+	}
+	if uint64(txn.FirstValid) > next {
+		return fmt.Errorf("transaction group is not valid before round %d and the network is at round %d",
+			txn.FirstValid, sp.FirstRoundValid)
+	}
+	return nil
+}
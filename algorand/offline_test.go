@@ -0,0 +1,58 @@
+package algorand
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// TestCheckSubmittable covers the network and validity window checks of
+// SubmitGroup.
+func TestCheckSubmittable(t *testing.T) {
+	var txn types.Transaction
+	txn.GenesisHash = types.Digest{1}
+	txn.FirstValid, txn.LastValid = 100, 1100
+	g := PendingGroup{SignedGroup: msgpack.Encode(types.SignedTxn{Txn: txn})}
+
+	sp := types.SuggestedParams{GenesisHash: txn.GenesisHash[:]}
+	for _, tc := range []struct {
+		round uint64
+		want  error
+	}{{99, nil}, {500, nil}, {1099, nil}, {1100, ErrStaleGroup}} {
+		sp.FirstRoundValid = types.Round(tc.round)
+		if err := checkSubmittable(g, sp); !errors.Is(err, tc.want) {
+			t.Fatalf("round %d: got %v, want %v", tc.round, err, tc.want)
+		}
+	}
+	sp.FirstRoundValid = 98
+	if err := checkSubmittable(g, sp); err == nil {
+		t.Fatal("expected a group not yet valid to be refused")
+	}
+	sp.FirstRoundValid = 500
+	sp.GenesisHash = make([]byte, 32)
+	if err := checkSubmittable(g, sp); !errors.Is(err, ErrWrongNetwork) {
+		t.Fatalf("got %v, want ErrWrongNetwork", err)
+	}
+	if err := checkSubmittable(PendingGroup{SignedGroup: []byte{1}}, sp); err == nil {
+		t.Fatal("expected an invalid group to be refused")
+	}
+}
+
+// TestSuggestedParamsFresh checks that cached params are reused for half of
+// their validity window.
+func TestSuggestedParamsFresh(t *testing.T) {
+	fetched := time.Unix(1_700_000_000, 0)
+	c := cachedSuggestedParams{
+		sp:      types.SuggestedParams{FirstRoundValid: 10, LastRoundValid: 1010},
+		fetched: fetched,
+	}
+	if !suggestedParamsFresh(c, fetched.Add(499*time.Second)) {
+		t.Fatal("expected params to be fresh within half of their window")
+	}
+	if suggestedParamsFresh(c, fetched.Add(500*time.Second)) {
+		t.Fatal("expected params to be stale after half of their window")
+	}
+}
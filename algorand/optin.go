@@ -0,0 +1,137 @@
+package algorand
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// Minimum balance requirements of the Algorand protocol, in microAlgos: every
+// account must hold accountMinBalance, plus assetMinBalance per asset it is
+// opted into.
+const (
+	accountMinBalance uint64 = 100_000
+	assetMinBalance   uint64 = 100_000
+)
+
+type OptInOptions struct {
+	Network Network // default MainNet
+	// Sponsor, if set, is the FALCON keypair of a PQ account that pays the
+	// fees of the group and funds the minimum balance the opt-in requires, so
+	// an account holding no Algos can opt in.
+	Sponsor *falcongo.KeyPair
+}
+
+// OptInAsset opts the PQ account of keyPair into assetID. With a sponsor, the
+// group starts with a payment from the sponsor's PQ account of what the
+// account lacks to cover its minimum balance after the opt-in, which also pays
+// the fees of the whole group; both accounts sign in the same flow.
+// It returns the IDs of the PQ transactions in group order.
+func OptInAsset(keyPair falcongo.KeyPair, assetID uint64, opt OptInOptions,
+) (txIDs []string, err error) {
+
+	signers := []pqSigner{{keyPair: keyPair}}
+	if opt.Sponsor != nil {
+		if opt.Sponsor.PublicKey == keyPair.PublicKey {
+			return nil, fmt.Errorf("the sponsor must be a different key")
+		}
+		signers = append([]pqSigner{{keyPair: *opt.Sponsor}}, signers...)
+	}
+	for i := range signers {
+		signers[i].lsig, err = DerivePQLogicSig(signers[i].keyPair.PublicKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	account, err := signers[len(signers)-1].lsig.Address()
+	if err != nil {
+		return nil, err
+	}
+
+	algodClient, err := GetAlgodClient(opt.Network)
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+
+	info, err := algodClient.AccountInformation(account.String()).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if holdsAsset(info, assetID) {
+		return nil, fmt.Errorf("%s is already opted into asset %d", account, assetID)
+	}
+	if _, err := algodClient.GetAssetByID(assetID).Do(ctx); err != nil {
+		return nil, fmt.Errorf("asset %d not found: %w", assetID, err)
+	}
+
+	sp, err := algodClient.SuggestedParams().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var sponsor types.Address
+	if opt.Sponsor != nil {
+		if sponsor, err = signers[0].lsig.Address(); err != nil {
+			return nil, err
+		}
+	}
+	txns, err := makeOptInTxns(account, sponsor, assetID,
+		optInFunding(info.Amount, info.MinBalance), sp)
+	if err != nil {
+		return nil, err
+	}
+
+	txIDs, _, err = sendSignedPQGroup(algodClient, signers, txns, 0, 0, nil)
+	return txIDs, err
+}
+
+// optInFunding returns what an account holding amount, with minimum balance
+// minBalance, lacks to cover its minimum balance once opted into one more
+// asset. algod reports no minimum balance for accounts that do not exist yet.
+func optInFunding(amount, minBalance uint64) uint64 {
+	need := max(minBalance, accountMinBalance) + assetMinBalance
+	if amount >= need {
+		return 0
+	}
+	return need - amount
+}
+
+// makeOptInTxns builds the PQ transactions of an opt-in of account into
+// assetID. If sponsor is not the zero address, they start with a payment of
+// funding microAlgos from sponsor to account. The first transaction pays the
+// minimum fee of all of them.
+func makeOptInTxns(account, sponsor types.Address, assetID, funding uint64,
+	sp types.SuggestedParams,
+) ([]types.Transaction, error) {
+
+	sp.FlatFee = true
+	sp.Fee = 0
+
+	var txns []types.Transaction
+	if !sponsor.IsZero() {
+		txn, err := transaction.MakePaymentTxn(
+			sponsor.String(), // from
+			account.String(), // to
+			funding,          // amount
+			nil,              // note
+			"",               // closeRemainderTo
+			sp,               // suggested params
+		)
+		if err != nil {
+			return nil, err
+		}
+		txns = append(txns, txn)
+	}
+	txn, err := transaction.MakeAssetAcceptanceTxn(account.String(), nil, sp, assetID)
+	if err != nil {
+		return nil, err
+	}
+	txns = append(txns, txn)
+
+	txns[0].Fee = types.MicroAlgos(uint64(len(txns)) * sp.MinFee)
+	return txns, nil
+}
@@ -0,0 +1,61 @@
+package algorand
+
+import (
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+func TestOptInFunding(t *testing.T) {
+	for _, tc := range []struct{ amount, minBalance, want uint64 }{
+		{0, 0, 200_000},            // account does not exist yet
+		{150_000, 100_000, 50_000}, // funded, no assets
+		{500_000, 200_000, 0},      // enough for one more asset
+		{250_000, 300_000, 150_000},
+	} {
+		if got := optInFunding(tc.amount, tc.minBalance); got != tc.want {
+			t.Errorf("optInFunding(%d, %d) = %d, want %d", tc.amount, tc.minBalance, got, tc.want)
+		}
+	}
+}
+
+// TestMakeOptInTxns checks the transactions of an opt-in with and without
+// a sponsor, and who pays their fees.
+func TestMakeOptInTxns(t *testing.T) {
+	sp := types.SuggestedParams{
+		Fee:             10,
+		MinFee:          1000,
+		FirstRoundValid: 1,
+		LastRoundValid:  1000,
+		GenesisID:       "test-v1",
+		GenesisHash:     make([]byte, 32),
+	}
+	account := types.Address{1}
+	sponsor := types.Address{2}
+	const assetID = 99
+
+	txns, err := makeOptInTxns(account, types.Address{}, assetID, 200_000, sp)
+	if err != nil {
+		t.Fatalf("makeOptInTxns failed: %v", err)
+	}
+	if len(txns) != 1 || txns[0].Sender != account || txns[0].Fee != 1000 {
+		t.Fatalf("unexpected unsponsored opt-in: %+v", txns)
+	}
+
+	txns, err = makeOptInTxns(account, sponsor, assetID, 200_000, sp)
+	if err != nil {
+		t.Fatalf("makeOptInTxns failed: %v", err)
+	}
+	if len(txns) != 2 {
+		t.Fatalf("expected 2 transactions, got %d", len(txns))
+	}
+	pay, optIn := txns[0], txns[1]
+	if pay.Type != types.PaymentTx || pay.Sender != sponsor || pay.Receiver != account ||
+		pay.Amount != 200_000 || pay.Fee != 2000 {
+		t.Fatalf("unexpected sponsor payment: %+v", pay)
+	}
+	if optIn.Type != types.AssetTransferTx || optIn.Sender != account || optIn.AssetReceiver != account ||
+		optIn.XferAsset != assetID || optIn.AssetAmount != 0 || optIn.Fee != 0 {
+		t.Fatalf("unexpected opt-in: %+v", optIn)
+	}
+}
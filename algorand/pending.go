@@ -0,0 +1,180 @@
+package algorand
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// PendingGroup is a signed group that is broadcast without its confirmation
+// having been observed yet. It holds what is needed to check on the group
+// later and to broadcast it again, and no key material.
+type PendingGroup struct {
+	TxIDs       []string // IDs of the PQ transactions, in group order
+	GroupID     types.Digest
+	SignedGroup []byte // encoded signed transactions, as broadcast
+	FirstValid  uint64
+	LastValid   uint64
+}
+
+// Txns decodes the signed transactions of g, PQ and dummy, in group order.
+func (g PendingGroup) Txns() ([]types.SignedTxn, error) {
+	dec := msgpack.NewDecoder(bytes.NewReader(g.SignedGroup))
+	var txns []types.SignedTxn
+	for {
+		var stxn types.SignedTxn
+		err := dec.Decode(&stxn)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid signed group: %w", err)
+		}
+		txns = append(txns, stxn)
+	}
+	if len(txns) == 0 {
+		return nil, errors.New("invalid signed group: no transactions")
+	}
+	return txns, nil
+}
+
+// States reported by CheckPending. A group is committed atomically, so the
+// state of its last PQ transaction is the state of the group.
+const (
+	// StateConfirmed: the group was committed in ConfirmedRound.
+	StateConfirmed = "confirmed"
+	// StatePending: the group is in the node's transaction pool.
+	StatePending = "pending"
+	// StateRejected: the node removed the group from its pool (PoolError).
+	StateRejected = "rejected"
+	// StateExpired: the group was not committed by its last valid round and
+	// can no longer be; no funds moved.
+	StateExpired = "expired"
+	// StateUnknown: the node has not seen the group, which is still valid.
+	StateUnknown = "unknown"
+)
+
+// PendingStatus is the outcome of CheckPending.
+type PendingStatus struct {
+	State          string
+	ConfirmedRound uint64
+	PoolError      string
+	LastRound      uint64 // last round of the node when checked
+	Rebroadcast    bool   // whether the group was broadcast again
+}
+
+// Final reports whether the state can no longer change.
+func (s PendingStatus) Final() bool {
+	return s.State == StateConfirmed || s.State == StateRejected || s.State == StateExpired
+}
+
+// CheckOptions controls CheckPending.
+type CheckOptions struct {
+	Network Network // default MainNet
+	// WaitRounds is how many rounds to wait for the group to reach a final
+	// state. Zero checks once.
+	WaitRounds uint64
+	// Rebroadcast sends SignedGroup again if the node has not seen the group
+	// and it is still valid, e.g. because the process stopped right before
+	// or during the first broadcast. It is ignored after DisableBroadcast.
+	Rebroadcast bool
+}
+
+// CheckPending reports the state of a pending group, waiting up to
+// opt.WaitRounds rounds for it to become final. Groups with a validity range
+// that are not in the pool are looked up in the blocks of that range, so a
+// group committed after the node forgot about it is still found; a group
+// known only by its transaction IDs can only be found while the node
+// remembers it.
+func CheckPending(p PendingGroup, opt CheckOptions) (PendingStatus, error) {
+	if len(p.TxIDs) == 0 {
+		return PendingStatus{}, fmt.Errorf("no transaction IDs")
+	}
+	algodClient, err := GetAlgodClient(opt.Network)
+	if err != nil {
+		return PendingStatus{}, err
+	}
+	return checkPending(algodClient, p, opt)
+}
+
+func checkPending(algodClient *algod.Client, p PendingGroup, opt CheckOptions,
+) (PendingStatus, error) {
+
+	ctx := context.Background()
+	nodeStatus, err := algodClient.Status().Do(ctx)
+	if err != nil {
+		return PendingStatus{}, err
+	}
+	st := PendingStatus{LastRound: nodeStatus.LastRound}
+	txID := p.TxIDs[len(p.TxIDs)-1]
+	// Blocks before scanFrom have been searched for txID.
+	scanFrom := p.FirstValid
+
+	for waited := uint64(0); ; waited++ {
+		info, _, err := algodClient.PendingTransactionInformation(txID).Do(ctx)
+		switch {
+		case err == nil && info.PoolError != "":
+			st.State, st.PoolError = StateRejected, info.PoolError
+		case err == nil && info.ConfirmedRound > 0:
+			st.State, st.ConfirmedRound = StateConfirmed, info.ConfirmedRound
+		case err == nil:
+			st.State = StatePending
+		default:
+			// Not seen (or no longer remembered) by the node.
+			st.State = StateUnknown
+			if p.FirstValid == 0 || p.LastValid == 0 {
+				break
+			}
+			scanTo := min(p.LastValid, st.LastRound)
+			round, err := findTxInBlocks(algodClient, txID, scanFrom, scanTo)
+			if err != nil {
+				return st, err
+			}
+			scanFrom = max(scanFrom, scanTo+1)
+			switch {
+			case round != 0:
+				st.State, st.ConfirmedRound = StateConfirmed, round
+			case st.LastRound >= p.LastValid:
+				st.State = StateExpired
+			case opt.Rebroadcast && !st.Rebroadcast && len(p.SignedGroup) > 0 && !BroadcastDisabled():
+				if _, err := algodClient.SendRawTransaction(p.SignedGroup).Do(ctx); err != nil {
+					return st, fmt.Errorf("rebroadcast failed: %w", err)
+				}
+				st.Rebroadcast = true
+				st.State = StatePending
+			}
+		}
+		if st.Final() || waited >= opt.WaitRounds {
+			return st, nil
+		}
+		nodeStatus, err = algodClient.StatusAfterBlock(st.LastRound).Do(ctx)
+		if err != nil {
+			return st, err
+		}
+		st.LastRound = nodeStatus.LastRound
+	}
+}
+
+// findTxInBlocks returns the round in [from, to] whose block holds txID, or 0.
+func findTxInBlocks(algodClient *algod.Client, txID string, from, to uint64,
+) (uint64, error) {
+
+	for round := from; round <= to; round++ {
+		resp, err := algodClient.GetBlockTxids(round).Do(context.Background())
+		if err != nil {
+			return 0, fmt.Errorf("cannot read block %d: %w", round, err)
+		}
+		for _, id := range resp.Blocktxids {
+			if id == txID {
+				return round, nil
+			}
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,191 @@
+package algorand
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// fakeAlgod serves the algod endpoints used by CheckPending, SuggestFees and
+// ReadAppGlobal.
+type fakeAlgod struct {
+	mu     sync.Mutex
+	round  uint64
+	pool   map[string]models.PendingTransactionInfoResponse
+	blocks map[uint64][]string
+	// blockSize is the encoded size of each block.
+	blockSize map[uint64]int
+	params    models.TransactionParametersResponse
+	app       models.Application
+	sent      int
+	// posted are the groups sent; with confirmPosted, their transactions are
+	// confirmed in the current round.
+	posted        [][]byte
+	confirmPosted bool
+	// onRound, if set, is called with each new round.
+	onRound func(f *fakeAlgod)
+}
+
+func (f *fakeAlgod) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	path := r.URL.Path
+	switch {
+	case path == "/v2/status":
+		_ = json.NewEncoder(w).Encode(models.NodeStatus{LastRound: f.round})
+	case strings.HasPrefix(path, "/v2/status/wait-for-block-after/"):
+		f.round++
+		if f.onRound != nil {
+			f.onRound(f)
+		}
+		_ = json.NewEncoder(w).Encode(models.NodeStatus{LastRound: f.round})
+	case strings.HasPrefix(path, "/v2/transactions/pending/"):
+		info, ok := f.pool[strings.TrimPrefix(path, "/v2/transactions/pending/")]
+		if !ok {
+			http.Error(w, `{"message":"txn not found"}`, http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(msgpack.Encode(info))
+	case strings.HasPrefix(path, "/v2/blocks/"):
+		round, _ := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(path, "/v2/blocks/"), "/txids"), 10, 64)
+		if round > f.round {
+			http.Error(w, `{"message":"no block"}`, http.StatusNotFound)
+			return
+		}
+		if !strings.HasSuffix(path, "/txids") {
+			_, _ = w.Write(make([]byte, f.blockSize[round]))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(models.BlockTxidsResponse{Blocktxids: f.blocks[round]})
+	case strings.HasPrefix(path, "/v2/applications/"):
+		_ = json.NewEncoder(w).Encode(f.app)
+	case path == "/v2/transactions/params":
+		params := f.params
+		params.LastRound = f.round
+		params.GenesisHash = make([]byte, 32)
+		_ = json.NewEncoder(w).Encode(params)
+	case path == "/v2/transactions" && r.Method == http.MethodPost:
+		body, _ := io.ReadAll(r.Body)
+		f.sent++
+		f.posted = append(f.posted, body)
+		if f.confirmPosted {
+			f.confirm(body)
+		}
+		_ = json.NewEncoder(w).Encode(models.PostTransactionsResponse{Txid: "X"})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// confirm puts the transactions of the signed group body in the pool,
+// confirmed in the current round.
+func (f *fakeAlgod) confirm(body []byte) {
+	if f.pool == nil {
+		f.pool = map[string]models.PendingTransactionInfoResponse{}
+	}
+	dec := msgpack.NewDecoder(bytes.NewReader(body))
+	for {
+		var stxn types.SignedTxn
+		if dec.Decode(&stxn) != nil {
+			return
+		}
+		f.pool[crypto.GetTxID(stxn.Txn)] = models.PendingTransactionInfoResponse{ConfirmedRound: f.round}
+	}
+}
+
+func (f *fakeAlgod) client(t *testing.T) *algod.Client {
+	t.Helper()
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	c, err := algod.MakeClient(srv.URL, "")
+	if err != nil {
+		t.Fatalf("MakeClient failed: %v", err)
+	}
+	return c
+}
+
+// TestCheckPending covers each state of a recorded group.
+func TestCheckPending(t *testing.T) {
+	group := PendingGroup{TxIDs: []string{"A", "B"}, SignedGroup: []byte{1}, FirstValid: 10, LastValid: 20}
+	for _, tc := range []struct {
+		name  string
+		fake  *fakeAlgod
+		opt   CheckOptions
+		state string
+		round uint64
+		sent  int
+	}{{
+		name:  "confirmed in pool",
+		fake:  &fakeAlgod{round: 15, pool: map[string]models.PendingTransactionInfoResponse{"B": {ConfirmedRound: 14}}},
+		state: StateConfirmed, round: 14,
+	}, {
+		name:  "rejected",
+		fake:  &fakeAlgod{round: 15, pool: map[string]models.PendingTransactionInfoResponse{"B": {PoolError: "overspend"}}},
+		state: StateRejected,
+	}, {
+		name:  "confirmed in blocks",
+		fake:  &fakeAlgod{round: 25, blocks: map[uint64][]string{12: {"A", "B"}}},
+		state: StateConfirmed, round: 12,
+	}, {
+		name:  "expired",
+		fake:  &fakeAlgod{round: 20, blocks: map[uint64][]string{12: {"C"}}},
+		state: StateExpired,
+	}, {
+		name:  "unknown",
+		fake:  &fakeAlgod{round: 15},
+		state: StateUnknown,
+	}, {
+		name:  "rebroadcast once",
+		fake:  &fakeAlgod{round: 15},
+		opt:   CheckOptions{Rebroadcast: true, WaitRounds: 2},
+		state: StateUnknown, sent: 1,
+	}, {
+		name: "resumes waiting",
+		fake: &fakeAlgod{round: 15, pool: map[string]models.PendingTransactionInfoResponse{"B": {}},
+			onRound: func(f *fakeAlgod) {
+				if f.round == 17 {
+					f.pool["B"] = models.PendingTransactionInfoResponse{ConfirmedRound: 17}
+				}
+			}},
+		opt:   CheckOptions{WaitRounds: 5},
+		state: StateConfirmed, round: 17,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			st, err := checkPending(tc.fake.client(t), group, tc.opt)
+			if err != nil {
+				t.Fatalf("checkPending failed: %v", err)
+			}
+			if st.State != tc.state || st.ConfirmedRound != tc.round || tc.fake.sent != tc.sent {
+				t.Fatalf("got %+v after %d broadcasts, want %s in round %d after %d",
+					st, tc.fake.sent, tc.state, tc.round, tc.sent)
+			}
+		})
+	}
+}
+
+// TestPendingGroupTxns decodes a group of two signed transactions.
+func TestPendingGroupTxns(t *testing.T) {
+	var a, b types.Transaction
+	a.Fee, b.Fee = 3000, 1000
+	g := PendingGroup{SignedGroup: append(msgpack.Encode(types.SignedTxn{Txn: a}),
+		msgpack.Encode(types.SignedTxn{Txn: b})...)}
+	txns, err := g.Txns()
+	if err != nil || len(txns) != 2 || txns[0].Txn.Fee != 3000 || txns[1].Txn.Fee != 1000 {
+		t.Fatalf("Txns: %+v, %v", txns, err)
+	}
+	if _, err := (PendingGroup{SignedGroup: []byte{0xc1}}).Txns(); err == nil {
+		t.Fatal("expected an invalid group to fail")
+	}
+}
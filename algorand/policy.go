@@ -0,0 +1,234 @@
+package algorand
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// ErrInvalidPolicy is returned for parameters that a policy logicsig cannot
+// enforce.
+var ErrInvalidPolicy = errors.New("invalid logicsig policy")
+
+// MaxPolicyWindow is the largest window of DeriveLimitedPQLogicSig, in
+// rounds: a transaction is valid for at most 1000 rounds, and the window is
+// enforced by the lease of one transaction.
+const MaxPolicyWindow = 1000
+
+// MaxAllowListReceivers is the largest number of receivers of
+// DeriveAllowListPQLogicSig; with more, the program would not fit in the
+// padding of a PQ transaction.
+const MaxAllowListReceivers = 16
+
+// limitedLease is the lease that the programs of DeriveLimitedPQLogicSig
+// require, so that a payment locks out any other until its window ends.
+var limitedLease = sha512.Sum512_256([]byte("falcon-signatures limited PQ logicsig"))
+
+// The policy logicsigs keep the counter byte where the PQ logicsig does.
+const policyLogicSigCounterOffset = pqLogicSigCounterOffset
+
+// DeriveLimitedPQLogicSig returns a LogicSig that approves only payments
+// signed by publicKey, as the PQ logicsig does, that take at most limit
+// microAlgos from the account, fee included, per window of rounds: a
+// payment must carry the lease set by PrepareLimitedTxn and be valid from
+// the first round of a window (a multiple of window) to its last, so that
+// the lease locks out any other payment until the window ends. The account
+// can neither close nor be rekeyed.
+//
+// A logicsig keeps no state between transactions, so the window is at most
+// MaxPolicyWindow rounds; a daily limit needs an application.
+func DeriveLimitedPQLogicSig(publicKey falcongo.PublicKey, limit, window uint64,
+) (crypto.LogicSigAccount, error) {
+
+	if limit == 0 {
+		return crypto.LogicSigAccount{}, fmt.Errorf("%w: zero limit", ErrInvalidPolicy)
+	}
+	if window == 0 || window > MaxPolicyWindow {
+		return crypto.LogicSigAccount{}, fmt.Errorf("%w: window of %d rounds, want 1 to %d",
+			ErrInvalidPolicy, window, MaxPolicyWindow)
+	}
+	return derivePolicyLogicSig(func(counter byte) []byte {
+		return limitedLogicSigProgram(publicKey, limit, window, counter)
+	})
+}
+
+// PrepareLimitedTxn makes txn acceptable to a logicsig of
+// DeriveLimitedPQLogicSig with the given window: it sets the lease and
+// moves the validity of txn to the window holding its first valid round.
+// txn must not be signed or grouped yet.
+func PrepareLimitedTxn(txn *types.Transaction, window uint64) error {
+	if window == 0 || window > MaxPolicyWindow {
+		return fmt.Errorf("%w: window of %d rounds, want 1 to %d", ErrInvalidPolicy, window, MaxPolicyWindow)
+	}
+	first := uint64(txn.FirstValid) - uint64(txn.FirstValid)%window
+	txn.FirstValid = types.Round(first)
+	txn.LastValid = types.Round(first + window - 1)
+	txn.Lease = limitedLease
+	return nil
+}
+
+// DeriveTimeLockedPQLogicSig returns a LogicSig that approves a transaction
+// signed by publicKey, as the PQ logicsig does, only once its first valid
+// round is after afterRound: funds sent to it are locked until then.
+func DeriveTimeLockedPQLogicSig(publicKey falcongo.PublicKey, afterRound uint64,
+) (crypto.LogicSigAccount, error) {
+
+	return derivePolicyLogicSig(func(counter byte) []byte {
+		return timeLockedLogicSigProgram(publicKey, afterRound, counter)
+	})
+}
+
+// DeriveAllowListPQLogicSig returns a LogicSig that approves only payments
+// signed by publicKey, as the PQ logicsig does, to one of receivers, at most
+// MaxAllowListReceivers. The account can neither close nor be rekeyed.
+func DeriveAllowListPQLogicSig(publicKey falcongo.PublicKey, receivers []types.Address,
+) (crypto.LogicSigAccount, error) {
+
+	if len(receivers) == 0 || len(receivers) > MaxAllowListReceivers {
+		return crypto.LogicSigAccount{}, fmt.Errorf("%w: %d receivers, want 1 to %d",
+			ErrInvalidPolicy, len(receivers), MaxAllowListReceivers)
+	}
+	return derivePolicyLogicSig(func(counter byte) []byte {
+		return allowListLogicSigProgram(publicKey, receivers, counter)
+	})
+}
+
+// derivePolicyLogicSig returns the LogicSig of program for the first counter
+// value whose address does not decode to any Edwards25519 point, as
+// DerivePQLogicSig does.
+func derivePolicyLogicSig(program func(counter byte) []byte) (crypto.LogicSigAccount, error) {
+	for counter := range 256 {
+		lsig := crypto.LogicSigAccount{
+			Lsig: types.LogicSig{Logic: program(byte(counter))},
+		}
+		lsa, err := lsig.Address()
+		if err != nil {
+			return crypto.LogicSigAccount{}, err
+		}
+		if !isOnTheCurve(lsa[:]) {
+			return lsig, nil
+		}
+	}
+	return crypto.LogicSigAccount{}, ErrInvalidFalconPublicKey
+}
+
+// policyLogicSigHeader returns the version and counter constant that start
+// the policy programs:
+//
+//	0c					| #pragma version 12
+//	26 01 01 00			| bytecblock 0x00 (counter)
+func policyLogicSigHeader(counter byte) []byte {
+	program := []byte{0x0c, 0x26, 0x01, 0x01, 0x00}
+	program[policyLogicSigCounterOffset] = counter
+	return program
+}
+
+// appendPaymentChecks appends the checks that txn is a payment that neither
+// rekeys nor closes the account:
+//
+//	31 10				| txn TypeEnum
+//	81 01				| pushint 1 (pay)
+//	12					| ==
+//	44					| assert
+//	31 20				| txn RekeyTo
+//	32 03				| global ZeroAddress
+//	12					| ==
+//	44					| assert
+//	31 09				| txn CloseRemainderTo
+//	32 03				| global ZeroAddress
+//	12					| ==
+//	44					| assert
+func appendPaymentChecks(program []byte) []byte {
+	program = append(program, 0x31, 0x10, 0x81, 0x01, 0x12, 0x44)
+	program = append(program, 0x31, 0x20, 0x32, 0x03, 0x12, 0x44)
+	return append(program, 0x31, 0x09, 0x32, 0x03, 0x12, 0x44)
+}
+
+// appendFalconVerify appends the check of the PQ logicsig, whose result is
+// that of the program:
+//
+//	31 17				| txn TxID
+//	2d					| arg 0
+//	80 81 0e 00...		| pushbytes 0x00... (1793 FALCON key bytes)
+//	85					| falcon_verify
+func appendFalconVerify(program []byte, publicKey falcongo.PublicKey) []byte {
+	program = append(program, 0x31, 0x17, 0x2d, 0x80, 0x81, 0x0e)
+	program = append(program, publicKey[:]...)
+	return append(program, 0x85)
+}
+
+// limitedLogicSigProgram returns the compiled LimitedlogicsigTMPL TEAL code
+// with the given key, limit, window and counter value: the header, the
+// payment checks, then
+//
+//	31 08 31 01 08		| txn Amount; txn Fee; +
+//	81 xx... 0e 44		| pushint <limit>; <=; assert
+//	31 06 80 20 xx...	| txn Lease; pushbytes <limitedLease>
+//	12 44				| ==; assert
+//	31 02 81 xx...		| txn FirstValid; pushint <window>
+//	18 14 44			| %; !; assert
+//	31 04 31 02 09		| txn LastValid; txn FirstValid; -
+//	81 xx... 12 44		| pushint <window - 1>; ==; assert
+//
+// and the FALCON check.
+func limitedLogicSigProgram(publicKey falcongo.PublicKey, limit, window uint64, counter byte) []byte {
+	program := appendPaymentChecks(policyLogicSigHeader(counter))
+	program = append(program, 0x31, 0x08, 0x31, 0x01, 0x08, 0x81)
+	program = binary.AppendUvarint(program, limit)
+	program = append(program, 0x0e, 0x44, 0x31, 0x06, 0x80, 0x20)
+	program = append(program, limitedLease[:]...)
+	program = append(program, 0x12, 0x44, 0x31, 0x02, 0x81)
+	program = binary.AppendUvarint(program, window)
+	program = append(program, 0x18, 0x14, 0x44, 0x31, 0x04, 0x31, 0x02, 0x09, 0x81)
+	program = binary.AppendUvarint(program, window-1)
+	program = append(program, 0x12, 0x44)
+	return appendFalconVerify(program, publicKey)
+}
+
+// timeLockedLogicSigProgram returns the compiled TimelockedlogicsigTMPL TEAL
+// code with the given key, round and counter value: the header, then
+//
+//	31 02				| txn FirstValid
+//	81 xx...			| pushint <afterRound> (uvarint)
+//	0d					| >
+//	44					| assert
+//
+// and the FALCON check.
+func timeLockedLogicSigProgram(publicKey falcongo.PublicKey, afterRound uint64, counter byte) []byte {
+	program := append(policyLogicSigHeader(counter), 0x31, 0x02, 0x81)
+	program = binary.AppendUvarint(program, afterRound)
+	program = append(program, 0x0d, 0x44)
+	return appendFalconVerify(program, publicKey)
+}
+
+// allowListLogicSigProgram returns the compiled AllowlistlogicsigTMPL TEAL
+// code with the given key, receivers and counter value: the header, the
+// payment checks, then for each receiver
+//
+//	31 07				| txn Receiver
+//	80 20 xx...			| pushbytes <receiver>
+//	12					| ==
+//	11					| || (but for the first receiver)
+//
+// followed by assert (44) and the FALCON check.
+func allowListLogicSigProgram(publicKey falcongo.PublicKey, receivers []types.Address,
+	counter byte) []byte {
+
+	program := appendPaymentChecks(policyLogicSigHeader(counter))
+	for i, receiver := range receivers {
+		program = append(program, 0x31, 0x07, 0x80, 0x20)
+		program = append(program, receiver[:]...)
+		program = append(program, 0x12)
+		if i > 0 {
+			program = append(program, 0x11)
+		}
+	}
+	program = append(program, 0x44)
+	return appendFalconVerify(program, publicKey)
+}
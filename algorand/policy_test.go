@@ -0,0 +1,175 @@
+package algorand
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+func testAllowList(n int) []types.Address {
+	receivers := make([]types.Address, n)
+	for i := range receivers {
+		receivers[i][0], receivers[i][31] = byte(i+1), 0xaa
+	}
+	return receivers
+}
+
+// assemblePolicyTemplate assembles the template at path with the given
+// replacements. The lines between TMPL_EACH_OTHER_RECEIVER and TMPL_END
+// are repeated once per receiver after the first.
+func assemblePolicyTemplate(t *testing.T, path string, receivers []types.Address,
+	replacements ...string) []byte {
+
+	t.Helper()
+	src, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := string(src)
+	if before, rest, ok := strings.Cut(text, "// TMPL_EACH_OTHER_RECEIVER\n"); ok {
+		each, after, _ := strings.Cut(rest, "// TMPL_END\n")
+		text = strings.Replace(before, "TMPL_RECEIVER", "0x"+hex.EncodeToString(receivers[0][:]), 1)
+		for _, r := range receivers[1:] {
+			text += strings.Replace(each, "TMPL_RECEIVER", "0x"+hex.EncodeToString(r[:]), 1)
+		}
+		text += after
+	}
+	tok, err := assembleTEAL(strings.NewReplacer(replacements...).Replace(text))
+	if err != nil {
+		t.Fatalf("assembling %s failed: %v", path, err)
+	}
+	return tok
+}
+
+// TestPolicyTemplates checks the programs of the policy logicsigs against
+// their templates, and that they fit in the padding of a PQ transaction.
+func TestPolicyTemplates(t *testing.T) {
+	pk, _ := testRecoveryKeys()
+	key := "0x" + hex.EncodeToString(pk[:])
+
+	for _, tc := range []struct{ limit, window uint64 }{{1, 1}, {5_000_000, 300}, {math.MaxUint64, MaxPolicyWindow}} {
+		tok := assemblePolicyTemplate(t, "teal/LimitedlogicsigTMPL.teal", nil,
+			"TMPL_COUNTER", "0x07",
+			"TMPL_LIMIT", strconv.FormatUint(tc.limit, 10),
+			"TMPL_LEASE", "0x"+hex.EncodeToString(limitedLease[:]),
+			"TMPL_WINDOW_END", strconv.FormatUint(tc.window-1, 10),
+			"TMPL_WINDOW", strconv.FormatUint(tc.window, 10),
+			"TMPL_FALCON_PUBLIC_KEY", key)
+		program := limitedLogicSigProgram(pk, tc.limit, tc.window, 7)
+		if !bytes.Equal(tok, program) {
+			t.Fatalf("limit %d, window %d: LimitedlogicsigTMPL assembles differently from limitedLogicSigProgram",
+				tc.limit, tc.window)
+		}
+		if err := CheckPQProgram(program, pk); err != nil {
+			t.Fatalf("limited program: %v", err)
+		}
+	}
+
+	for _, afterRound := range []uint64{0, 127, 50_000_000} {
+		tok := assemblePolicyTemplate(t, "teal/TimelockedlogicsigTMPL.teal", nil,
+			"TMPL_COUNTER", "0x07",
+			"TMPL_AFTER_ROUND", strconv.FormatUint(afterRound, 10),
+			"TMPL_FALCON_PUBLIC_KEY", key)
+		program := timeLockedLogicSigProgram(pk, afterRound, 7)
+		if !bytes.Equal(tok, program) {
+			t.Fatalf("round %d: TimelockedlogicsigTMPL assembles differently from timeLockedLogicSigProgram",
+				afterRound)
+		}
+		if err := CheckPQProgram(program, pk); err != nil {
+			t.Fatalf("time-locked program: %v", err)
+		}
+	}
+
+	for _, n := range []int{1, 2, MaxAllowListReceivers} {
+		receivers := testAllowList(n)
+		tok := assemblePolicyTemplate(t, "teal/AllowlistlogicsigTMPL.teal", receivers,
+			"TMPL_COUNTER", "0x07",
+			"TMPL_FALCON_PUBLIC_KEY", key)
+		program := allowListLogicSigProgram(pk, receivers, 7)
+		if !bytes.Equal(tok, program) {
+			t.Fatalf("%d receivers: AllowlistlogicsigTMPL assembles differently from allowListLogicSigProgram", n)
+		}
+		if err := CheckPQProgram(program, pk); err != nil {
+			t.Fatalf("allow-list program of %d receivers: %v", n, err)
+		}
+	}
+}
+
+func TestDerivePolicyLogicSigs(t *testing.T) {
+	pk, _ := testRecoveryKeys()
+	pqAddress, _, err := DerivePQAddress(pk)
+	if err != nil {
+		t.Fatalf("DerivePQAddress failed: %v", err)
+	}
+	seen := map[types.Address]string{pqAddress: "PQ"}
+	for name, derive := range map[string]func() (crypto.LogicSigAccount, error){
+		"limited":      func() (crypto.LogicSigAccount, error) { return DeriveLimitedPQLogicSig(pk, 1_000_000, 100) },
+		"other limit":  func() (crypto.LogicSigAccount, error) { return DeriveLimitedPQLogicSig(pk, 2_000_000, 100) },
+		"time-locked":  func() (crypto.LogicSigAccount, error) { return DeriveTimeLockedPQLogicSig(pk, 1000) },
+		"allow-list":   func() (crypto.LogicSigAccount, error) { return DeriveAllowListPQLogicSig(pk, testAllowList(2)) },
+		"other recvrs": func() (crypto.LogicSigAccount, error) { return DeriveAllowListPQLogicSig(pk, testAllowList(3)) },
+	} {
+		lsig, err := derive()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		address, err := lsig.Address()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if isOnTheCurve(address[:]) {
+			t.Fatalf("%s: address decodes to an Edwards25519 point", name)
+		}
+		if other, dup := seen[address]; dup {
+			t.Fatalf("%s: same address as %s", name, other)
+		}
+		seen[address] = name
+	}
+
+	for name, derive := range map[string]func() (crypto.LogicSigAccount, error){
+		"zero limit":   func() (crypto.LogicSigAccount, error) { return DeriveLimitedPQLogicSig(pk, 0, 100) },
+		"zero window":  func() (crypto.LogicSigAccount, error) { return DeriveLimitedPQLogicSig(pk, 1, 0) },
+		"long window":  func() (crypto.LogicSigAccount, error) { return DeriveLimitedPQLogicSig(pk, 1, MaxPolicyWindow+1) },
+		"no receivers": func() (crypto.LogicSigAccount, error) { return DeriveAllowListPQLogicSig(pk, nil) },
+		"many receivers": func() (crypto.LogicSigAccount, error) {
+			return DeriveAllowListPQLogicSig(pk, testAllowList(MaxAllowListReceivers+1))
+		},
+	} {
+		if _, err := derive(); !errors.Is(err, ErrInvalidPolicy) {
+			t.Fatalf("%s: got %v, want ErrInvalidPolicy", name, err)
+		}
+	}
+}
+
+func TestPrepareLimitedTxn(t *testing.T) {
+	for _, tc := range []struct{ first, window, wantFirst uint64 }{
+		{1234, 100, 1200}, {1200, 100, 1200}, {1299, 100, 1200}, {5, 1, 5}, {999, MaxPolicyWindow, 0},
+	} {
+		txn := types.Transaction{}
+		txn.FirstValid, txn.LastValid = types.Round(tc.first), types.Round(tc.first+1000)
+		if err := PrepareLimitedTxn(&txn, tc.window); err != nil {
+			t.Fatalf("PrepareLimitedTxn failed: %v", err)
+		}
+		if uint64(txn.FirstValid) != tc.wantFirst || uint64(txn.LastValid) != tc.wantFirst+tc.window-1 {
+			t.Fatalf("first %d, window %d: validity %d-%d, want %d-%d", tc.first, tc.window,
+				txn.FirstValid, txn.LastValid, tc.wantFirst, tc.wantFirst+tc.window-1)
+		}
+		if uint64(txn.FirstValid) > tc.first || uint64(txn.LastValid) < tc.first {
+			t.Fatalf("first %d, window %d: the window does not hold the first valid round", tc.first, tc.window)
+		}
+		if txn.Lease != limitedLease {
+			t.Fatal("lease not set")
+		}
+	}
+	if err := PrepareLimitedTxn(&types.Transaction{}, 0); !errors.Is(err, ErrInvalidPolicy) {
+		t.Fatalf("window 0: got %v, want ErrInvalidPolicy", err)
+	}
+}
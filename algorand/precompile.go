@@ -0,0 +1,104 @@
+package algorand
+
+//go:generate go run ./internal/genprecompile
+//go:generate go run ./internal/genprecompile -manifest
+
+import (
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// Precompile is the PQlogicsig program assembled from PQlogicsigTMPL, with
+// counter 0 and an all-zero public key, and where patchPrecompiledPQlogicsig
+// patches them.
+type Precompile struct {
+	Version byte
+	// Program is teal/PQlogicsig.teal.tok for the version of the template.
+	Program         []byte
+	CounterOffset   int
+	PublicKeyOffset int
+}
+
+// Prefix returns the bytes of Program before the public key.
+func (p Precompile) Prefix() []byte {
+	return p.Program[:p.PublicKeyOffset]
+}
+
+// Suffix returns the bytes of Program after the public key.
+func (p Precompile) Suffix() []byte {
+	return p.Program[p.PublicKeyOffset+falcongo.PublicKeySize:]
+}
+
+// GeneratePrecompile assembles PQlogicsigTMPL for TEAL version, offline.
+// The offsets of the counter and the public key are found by assembling the
+// template again with other values and comparing.
+func GeneratePrecompile(version byte) (Precompile, error) {
+	var zero, ones falcongo.PublicKey
+	for i := range ones {
+		ones[i] = 0xff
+	}
+	program, err := assemblePQTemplate(version, 0, zero)
+	if err != nil {
+		return Precompile{}, err
+	}
+	p := Precompile{Version: version, Program: program, CounterOffset: -1, PublicKeyOffset: -1}
+
+	other, err := assemblePQTemplate(version, 1, zero)
+	if err != nil {
+		return Precompile{}, err
+	}
+	if diff := diffOffsets(program, other); len(diff) == 1 {
+		p.CounterOffset = diff[0]
+	}
+	other, err = assemblePQTemplate(version, 0, ones)
+	if err != nil {
+		return Precompile{}, err
+	}
+	if diff := diffOffsets(program, other); len(diff) == len(zero) && diff[len(diff)-1]-diff[0] == len(zero)-1 {
+		p.PublicKeyOffset = diff[0]
+	}
+	if p.CounterOffset < 0 || p.PublicKeyOffset < 0 {
+		return Precompile{}, fmt.Errorf("the counter and public key of PQlogicsigTMPL are not single patchable runs")
+	}
+	return p, nil
+}
+
+// PQLogicSigSource returns the PQlogicsigTMPL source for TEAL version, with
+// the counter and public key filled in.
+func PQLogicSigSource(version byte, counter byte, publicKey falcongo.PublicKey) (string, error) {
+	pragma := regexp.MustCompile(`(?m)^#pragma version \d+$`)
+	if !pragma.MatchString(PQlogicsigTMPL) {
+		return "", fmt.Errorf("PQlogicsigTMPL has no #pragma version")
+	}
+	src := pragma.ReplaceAllLiteralString(PQlogicsigTMPL, fmt.Sprintf("#pragma version %d", version))
+	src = strings.Replace(src, "TMPL_COUNTER", fmt.Sprintf("0x%02x", counter), 1)
+	src = strings.Replace(src, "TMPL_FALCON_PUBLIC_KEY", "0x"+hex.EncodeToString(publicKey[:]), 1)
+	return src, nil
+}
+
+func assemblePQTemplate(version byte, counter byte, publicKey falcongo.PublicKey) ([]byte, error) {
+	src, err := PQLogicSigSource(version, counter, publicKey)
+	if err != nil {
+		return nil, err
+	}
+	return assembleTEAL(src)
+}
+
+// diffOffsets returns the offsets where a and b differ, or nil if they differ
+// in length.
+func diffOffsets(a, b []byte) []int {
+	if len(a) != len(b) {
+		return nil
+	}
+	var diff []int
+	for i := range a {
+		if a[i] != b[i] {
+			diff = append(diff, i)
+		}
+	}
+	return diff
+}
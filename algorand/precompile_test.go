@@ -0,0 +1,132 @@
+package algorand
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestPrecompileConsistency checks that the precompiled PQlogicsig and the
+// generated bytes patchPrecompiledPQlogicsig uses are what the assembler
+// makes of PQlogicsigTMPL, so they cannot drift from the template; run go
+// generate ./algorand after changing it.
+func TestPrecompileConsistency(t *testing.T) {
+	p, err := GeneratePrecompile(pqLogicSigVersion)
+	if err != nil {
+		t.Fatalf("GeneratePrecompile failed: %v", err)
+	}
+	if !bytes.Equal(p.Program, PQlogicsigPrecompile) {
+		t.Fatalf("teal/PQlogicsig.teal.tok differs from the template; run go generate ./algorand")
+	}
+	if !bytes.Equal(p.Prefix(), pqLogicSigPrefix[:]) || !bytes.Equal(p.Suffix(), pqLogicSigSuffix[:]) ||
+		p.CounterOffset != pqLogicSigCounterOffset {
+		t.Fatalf("precompile_gen.go differs from the template; run go generate ./algorand")
+	}
+	src, err := os.ReadFile("teal/PQlogicsig.teal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok, err := assembleTEAL(string(src)); err != nil || !bytes.Equal(tok, PQlogicsigPrecompile) {
+		t.Fatalf("teal/PQlogicsig.teal does not assemble to teal/PQlogicsig.teal.tok: %v", err)
+	}
+
+	var pk falcongo.PublicKey
+	for i := range pk {
+		pk[i] = byte(i * 7)
+	}
+	for _, counter := range []byte{0, 1, 200} {
+		want, err := assemblePQTemplate(pqLogicSigVersion, counter, pk)
+		if err != nil {
+			t.Fatalf("assemblePQTemplate failed: %v", err)
+		}
+		if got := patchPrecompiledPQlogicsig(pk, counter); !bytes.Equal(got, want) {
+			t.Fatalf("counter %d: patched program differs from the assembled template", counter)
+		}
+	}
+
+	for _, v := range TealVersions() {
+		p, err := GeneratePrecompile(v)
+		if err != nil {
+			t.Fatalf("GeneratePrecompile(%d) failed: %v", v, err)
+		}
+		layout := pqLogicSigLayouts[v]
+		if !bytes.Equal(p.Prefix(), layout.prefix) || !bytes.Equal(p.Suffix(), layout.suffix) ||
+			p.CounterOffset != layout.counterOffset {
+			t.Fatalf("precompile_gen.go differs from the template for version %d; run go generate ./algorand", v)
+		}
+		// Fees are sized for pqLogicSigProgramSize whatever the version.
+		if n := len(layout.patch(pk, 0)); n != pqLogicSigProgramSize {
+			t.Fatalf("version %d: program of %d bytes, want %d", v, n, pqLogicSigProgramSize)
+		}
+	}
+
+	if _, err := GeneratePrecompile(11); err == nil || !strings.Contains(err.Error(), "falcon_verify requires version 12") {
+		t.Fatalf("GeneratePrecompile(11) = %v, want a falcon_verify version error", err)
+	}
+}
+
+// TestAssembleTEAL checks the assembler against the other programs of the
+// package.
+func TestAssembleTEAL(t *testing.T) {
+	src, err := os.ReadFile("teal/dummyLsig.teal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tok, err := assembleTEAL(string(src)); err != nil || !bytes.Equal(tok, dummyLsigCompiled) {
+		t.Fatalf("teal/dummyLsig.teal does not assemble to teal/dummyLsig.teal.tok: %x, %v", tok, err)
+	}
+
+	tmpl, err := os.ReadFile("teal/RecoverylogicsigTMPL.teal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var primary, backup falcongo.PublicKey
+	primary[0], backup[0] = 1, 2
+	const afterRound = 123_456_789
+	r := strings.NewReplacer(
+		"TMPL_COUNTER", "0x07",
+		"TMPL_PRIMARY_FALCON_PUBLIC_KEY", "0x"+hex.EncodeToString(primary[:]),
+		"TMPL_BACKUP_FALCON_PUBLIC_KEY", "0x"+hex.EncodeToString(backup[:]),
+		"TMPL_AFTER_ROUND", strconv.Itoa(afterRound))
+	tok, err := assembleTEAL(r.Replace(string(tmpl)))
+	if err != nil {
+		t.Fatalf("assembling RecoverylogicsigTMPL failed: %v", err)
+	}
+	if !bytes.Equal(tok, recoveryLogicSigProgram(primary, backup, afterRound, 7)) {
+		t.Fatalf("RecoverylogicsigTMPL assembles differently from recoveryLogicSigProgram")
+	}
+
+	tmpl, err = os.ReadFile("teal/HybridlogicsigTMPL.teal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	edKey := bytes.Repeat([]byte{3}, 32)
+	r = strings.NewReplacer(
+		"TMPL_COUNTER", "0x07",
+		"TMPL_ED25519_PUBLIC_KEY", "0x"+hex.EncodeToString(edKey),
+		"TMPL_FALCON_PUBLIC_KEY", "0x"+hex.EncodeToString(primary[:]))
+	tok, err = assembleTEAL(r.Replace(string(tmpl)))
+	if err != nil {
+		t.Fatalf("assembling HybridlogicsigTMPL failed: %v", err)
+	}
+	if !bytes.Equal(tok, hybridLogicSigProgram(primary, edKey, 7)) {
+		t.Fatalf("HybridlogicsigTMPL assembles differently from hybridLogicSigProgram")
+	}
+
+	for _, bad := range []string{
+		"txn TxID",                            // no version
+		"#pragma version 2\npushint 1",        // pushint is v3
+		"#pragma version 12\nbnz nowhere",     // undefined label
+		"#pragma version 12\nsha256",          // unknown to the assembler
+		"#pragma version 12\npushbytes \"a\"", // only 0x constants
+	} {
+		if _, err := assembleTEAL(bad); err == nil {
+			t.Fatalf("assembleTEAL(%q) succeeded", bad)
+		}
+	}
+}
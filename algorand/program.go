@@ -0,0 +1,71 @@
+package algorand
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// Errors of CheckPQProgram.
+var (
+	// ErrProgramKeyMismatch is returned for a program that does not embed the
+	// FALCON public key that is to sign for it.
+	ErrProgramKeyMismatch = errors.New("the program does not embed the FALCON public key")
+	// ErrProgramTooLarge is returned for a program that, with a FALCON
+	// signature, does not fit in the padding of a PQ transaction.
+	ErrProgramTooLarge = errors.New("the program is too large")
+	// ErrProgramVersion is returned for a program whose TEAL version has no
+	// falcon_verify.
+	ErrProgramVersion = errors.New("the program predates falcon_verify")
+)
+
+// MaxPQProgramSize is the largest program CheckPQProgram accepts: with a
+// maximum-size FALCON signature as arg 0, its logicsig fits in the budget of
+// a PQ transaction and its dummy transactions, as the derived PQ logicsig
+// does: 4 transactions' worth of bytes (see dummyTxnsNeeded).
+const MaxPQProgramSize = 4*logicSigBytesPerTxn - falcongo.MaxCompressedSignatureSize
+
+// falconVerifyVersion is the first TEAL version with falcon_verify.
+const falconVerifyVersion = 12
+
+// CheckPQProgram checks that program, a compiled logicsig built elsewhere on
+// the pattern of the PQ logicsig (a FALCON signature of the TxID as arg 0,
+// checked with falcon_verify), can be signed for with publicKey: it embeds
+// the key as a TEAL byte constant, its version has falcon_verify and it fits
+// in the padding of a PQ transaction. It cannot check what else the program
+// allows; that is up to whoever wrote it.
+func CheckPQProgram(program []byte, publicKey falcongo.PublicKey) error {
+	version, n := binary.Uvarint(program)
+	if n <= 0 {
+		return fmt.Errorf("invalid program: no version")
+	}
+	if version < falconVerifyVersion {
+		return fmt.Errorf("%w: version %d, want %d or later", ErrProgramVersion, version, falconVerifyVersion)
+	}
+	if len(program) > MaxPQProgramSize {
+		return fmt.Errorf("%w: %d bytes, at most %d", ErrProgramTooLarge, len(program), MaxPQProgramSize)
+	}
+	// A byte constant is its length as a uvarint followed by its bytes,
+	// whether pushed with pushbytes or declared with bytecblock.
+	constant := binary.AppendUvarint(nil, falcongo.PublicKeySize)
+	constant = append(constant, publicKey[:]...)
+	if !bytes.Contains(program, constant) {
+		return ErrProgramKeyMismatch
+	}
+	return nil
+}
+
+// ProgramAddress returns the address of the account of program, after
+// checking it with CheckPQProgram.
+func ProgramAddress(program []byte, publicKey falcongo.PublicKey) (types.Address, error) {
+	if err := CheckPQProgram(program, publicKey); err != nil {
+		return types.Address{}, err
+	}
+	return crypto.AddressFromProgram(program), nil
+}
@@ -0,0 +1,63 @@
+package algorand
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestCheckPQProgram accepts the PQ logicsig and a custom program embedding
+// the key with bytecblock, and refuses other keys, old versions and programs
+// too large for the padding.
+func TestCheckPQProgram(t *testing.T) {
+	pk, other := testRecoveryKeys()
+	if budget := (1 + dummyTxnsNeeded(1)) * logicSigBytesPerTxn; MaxPQProgramSize+falcongo.MaxCompressedSignatureSize != budget {
+		t.Fatalf("MaxPQProgramSize does not fill the %d-byte budget of a PQ transaction", budget)
+	}
+
+	lsig, err := DerivePQLogicSig(pk)
+	if err != nil {
+		t.Fatalf("DerivePQLogicSig failed: %v", err)
+	}
+	address, err := ProgramAddress(lsig.Lsig.Logic, pk)
+	if err != nil {
+		t.Fatalf("ProgramAddress failed: %v", err)
+	}
+	if want, _ := lsig.Address(); address != want {
+		t.Fatalf("address %s, want %s", address, want)
+	}
+	// A time lock: bytecblock <key>; txn FirstValid; pushint 1000; >; assert;
+	// txn TxID; arg 0; bytec_0; falcon_verify.
+	timeLock := append([]byte{0x0c, 0x26, 0x01, 0x81, 0x0e}, pk[:]...)
+	timeLock = append(timeLock, 0x31, 0x02, 0x81, 0xe8, 0x07, 0x0d, 0x44, 0x31, 0x17, 0x2d, 0x28, 0x85)
+	if address, err := ProgramAddress(timeLock, pk); err != nil || address != crypto.AddressFromProgram(timeLock) {
+		t.Fatalf("time lock: %s, %v", address, err)
+	}
+
+	oldVersion := bytes.Clone(lsig.Lsig.Logic)
+	oldVersion[0] = 11
+	large := append(bytes.Clone(lsig.Lsig.Logic), make([]byte, MaxPQProgramSize)...)
+	for name, tc := range map[string]struct {
+		program []byte
+		want    error
+	}{
+		"other key":   {lsig.Lsig.Logic, ErrProgramKeyMismatch},
+		"old version": {oldVersion, ErrProgramVersion},
+		"too large":   {large, ErrProgramTooLarge},
+	} {
+		key := pk
+		if name == "other key" {
+			key = other
+		}
+		if err := CheckPQProgram(tc.program, key); !errors.Is(err, tc.want) {
+			t.Errorf("%s: got %v, want %v", name, err, tc.want)
+		}
+	}
+	if err := CheckPQProgram(nil, pk); err == nil {
+		t.Fatal("expected an empty program to be refused")
+	}
+}
@@ -0,0 +1,204 @@
+package algorand
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// A published key is split into chunks, each in the note of a 0-Algo payment
+// of the PQ account to itself, all in one group. A note is
+//
+//	PublishedKeyNotePrefix | SHA-256 of the public key | chunk index | chunk count | chunk
+//
+// The hash commits to the whole key, and the key is checked against the
+// address it is fetched for, since a PQ address is derived from its key.
+const (
+	PublishedKeyNotePrefix = "falcon-pubkey/v1:"
+	publishedKeyChunkSize  = 900
+)
+
+// ErrNoPublishedKey is returned by FetchPublishedKey when no valid key was
+// published for the address.
+var ErrNoPublishedKey = errors.New("no public key published")
+
+type PublishKeyOptions struct {
+	Network Network // default MainNet
+	// OnBroadcast is as in SendOptions.
+	OnBroadcast func(PendingGroup) error
+}
+
+// PublishedKey is a public key fetched from the chain.
+type PublishedKey struct {
+	PublicKey falcongo.PublicKey
+	Hash      [sha256.Size]byte
+	Round     uint64   // round the key was published in
+	TxIDs     []string // of the publishing transactions, in chunk order
+}
+
+// publishedKeyNotes returns the notes publishing pk, in chunk order.
+func publishedKeyNotes(pk falcongo.PublicKey) [][]byte {
+	hash := falcongo.Fingerprint(pk)
+	count := (len(pk) + publishedKeyChunkSize - 1) / publishedKeyChunkSize
+	notes := make([][]byte, 0, count)
+	for i := range count {
+		chunk := pk[i*publishedKeyChunkSize : min((i+1)*publishedKeyChunkSize, len(pk))]
+		note := append([]byte(PublishedKeyNotePrefix), hash[:]...)
+		note = append(note, byte(i), byte(count))
+		notes = append(notes, append(note, chunk...))
+	}
+	return notes
+}
+
+// parsePublishedKeyNote splits a note of publishedKeyNotes.
+func parsePublishedKeyNote(note []byte) (hash [sha256.Size]byte, index, count int, chunk []byte, ok bool) {
+	rest, found := bytes.CutPrefix(note, []byte(PublishedKeyNotePrefix))
+	if !found || len(rest) < sha256.Size+2 {
+		return hash, 0, 0, nil, false
+	}
+	copy(hash[:], rest)
+	index, count = int(rest[sha256.Size]), int(rest[sha256.Size+1])
+	if count == 0 || index >= count {
+		return hash, 0, 0, nil, false
+	}
+	return hash, index, count, rest[sha256.Size+2:], true
+}
+
+// PublishKey publishes the public key of keyPair in the notes of a group of
+// payments of its PQ account to itself, and returns their IDs once
+// confirmed. The account pays the fees.
+func PublishKey(keyPair falcongo.KeyPair, opt PublishKeyOptions) (txIDs []string, err error) {
+	lsig, err := DerivePQLogicSig(keyPair.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	address, err := lsig.Address()
+	if err != nil {
+		return nil, err
+	}
+	algodClient, err := GetAlgodClient(opt.Network)
+	if err != nil {
+		return nil, err
+	}
+	sp, err := algodClient.SuggestedParams().Do(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	sp.FlatFee = true
+	sp.Fee = types.MicroAlgos(sp.MinFee)
+
+	var txns []types.Transaction
+	for _, note := range publishedKeyNotes(keyPair.PublicKey) {
+		txn, err := transaction.MakePaymentTxn(
+			address.String(), // from
+			address.String(), // to
+			0,                // amount
+			note,             // note
+			"",               // closeRemainderTo
+			sp,               // suggested params
+		)
+		if err != nil {
+			return nil, err
+		}
+		txns = append(txns, txn)
+	}
+	txIDs, _, err = sendPQGroup(algodClient, keyPair, lsig, txns, 0, 0, opt.OnBroadcast)
+	return txIDs, err
+}
+
+// FetchPublishedKey finds, with the indexer, a public key published for the
+// PQ address with PublishKey, and checks it against its hash and the address.
+// It returns the earliest such key.
+func FetchPublishedKey(address string, network Network) (PublishedKey, error) {
+	addr, err := types.DecodeAddress(address)
+	if err != nil {
+		return PublishedKey{}, err
+	}
+	indexerClient, err := GetIndexerClient(network)
+	if err != nil {
+		return PublishedKey{}, err
+	}
+	var txns []models.Transaction
+	next := ""
+	for {
+		req := indexerClient.LookupAccountTransactions(address).
+			NotePrefix([]byte(PublishedKeyNotePrefix)).TxType("pay")
+		if next != "" {
+			req = req.NextToken(next)
+		}
+		resp, err := req.Do(context.Background())
+		if err != nil {
+			return PublishedKey{}, err
+		}
+		txns = append(txns, resp.Transactions...)
+		if resp.NextToken == "" || len(resp.Transactions) == 0 {
+			break
+		}
+		next = resp.NextToken
+	}
+	key, ok := assemblePublishedKey(addr, txns)
+	if !ok {
+		return PublishedKey{}, fmt.Errorf("%w for %s", ErrNoPublishedKey, address)
+	}
+	return key, nil
+}
+
+// assemblePublishedKey returns the earliest key among txns, published for
+// addr in the notes of one group, whose chunks match their hash and which
+// derives addr.
+func assemblePublishedKey(addr types.Address, txns []models.Transaction) (PublishedKey, bool) {
+	type publication struct {
+		chunks [][]byte
+		txIDs  []string
+		round  uint64
+	}
+	pubs := map[string]*publication{}
+	var best *PublishedKey
+	for _, txn := range txns {
+		if txn.Sender != addr.String() || len(txn.Group) == 0 {
+			continue
+		}
+		hash, index, count, chunk, ok := parsePublishedKeyNote(txn.Note)
+		if !ok {
+			continue
+		}
+		id := fmt.Sprintf("%x/%x", txn.Group, hash)
+		p := pubs[id]
+		if p == nil {
+			p = &publication{chunks: make([][]byte, count), txIDs: make([]string, count), round: txn.ConfirmedRound}
+			pubs[id] = p
+		}
+		if len(p.chunks) != count || p.chunks[index] != nil {
+			continue
+		}
+		p.chunks[index], p.txIDs[index] = chunk, txn.Id
+		var pk falcongo.PublicKey
+		joined := bytes.Join(p.chunks, nil)
+		if len(joined) != len(pk) {
+			continue
+		}
+		copy(pk[:], joined)
+		if falcongo.Fingerprint(pk) != hash {
+			continue
+		}
+		derived, _, err := DerivePQAddress(pk)
+		if err != nil || derived != addr {
+			continue
+		}
+		if best == nil || p.round < best.Round {
+			best = &PublishedKey{PublicKey: pk, Hash: hash, Round: p.round, TxIDs: p.txIDs}
+		}
+	}
+	if best == nil {
+		return PublishedKey{}, false
+	}
+	return *best, true
+}
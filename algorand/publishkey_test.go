@@ -0,0 +1,73 @@
+package algorand
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestFetchPublishedKey checks that a key published in chunked notes is
+// reassembled from the indexer, and that notes which do not add up to the
+// key of the address are ignored.
+func TestFetchPublishedKey(t *testing.T) {
+	var pk, other falcongo.PublicKey
+	for i := range pk {
+		pk[i], other[i] = byte(i), byte(i+1)
+	}
+	addr, _, err := DerivePQAddress(pk)
+	if err != nil {
+		t.Fatalf("DerivePQAddress failed: %v", err)
+	}
+	notes := publishedKeyNotes(pk)
+	if len(notes) != 2 {
+		t.Fatalf("got %d notes, want 2", len(notes))
+	}
+	for _, note := range notes {
+		if len(note) > 1024 {
+			t.Fatalf("note of %d bytes is larger than 1024", len(note))
+		}
+	}
+
+	tx := func(id string, group byte, round uint64, note []byte) models.Transaction {
+		return models.Transaction{Id: id, Sender: addr.String(), Group: []byte{group}, ConfirmedRound: round,
+			Note: note}
+	}
+	otherNotes := publishedKeyNotes(other)
+	tampered := bytes.Clone(notes[1])
+	tampered[len(tampered)-1] ^= 1
+	// Newest first, as the indexer returns them, over two pages.
+	pages := [][]models.Transaction{
+		{tx("A1", 1, 30, notes[1]), tx("A0", 1, 30, notes[0]), tx("B0", 2, 20, otherNotes[0]),
+			tx("B1", 2, 20, otherNotes[1])},
+		{tx("C1", 3, 10, tampered), tx("C0", 3, 10, notes[0]), tx("D0", 4, 5, notes[0])},
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, next := pages[0], "p2"
+		if r.URL.Query().Get("next") == "p2" {
+			page, next = pages[1], ""
+		}
+		_ = json.NewEncoder(w).Encode(models.TransactionsResponse{Transactions: page, NextToken: next})
+	}))
+	defer srv.Close()
+	t.Setenv("INDEXER_URL", srv.URL)
+
+	key, err := FetchPublishedKey(addr.String(), DevNet)
+	if err != nil {
+		t.Fatalf("FetchPublishedKey failed: %v", err)
+	}
+	if key.PublicKey != pk || key.Round != 30 || len(key.TxIDs) != 2 || key.TxIDs[0] != "A0" ||
+		key.TxIDs[1] != "A1" {
+		t.Fatalf("unexpected key: round %d, txids %v", key.Round, key.TxIDs)
+	}
+
+	pages = [][]models.Transaction{pages[1], nil}
+	if _, err := FetchPublishedKey(addr.String(), DevNet); err == nil {
+		t.Fatalf("expected an error when no valid key is published")
+	}
+}
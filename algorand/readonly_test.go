@@ -0,0 +1,66 @@
+package algorand
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// Read-only mode cannot be left, so TestEd25519SigningDisabled runs
+// TestEd25519SigningDisabledChild in a child process.
+const envSigningDisabledChild = "FALCON_TEST_SIGNING_DISABLED_CHILD"
+
+// TestEd25519SigningDisabled checks that no Ed25519 key signs in read-only
+// mode: not a delegation, not a migration transaction, not the arg 1 of a
+// hybrid logicsig.
+func TestEd25519SigningDisabled(t *testing.T) {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestEd25519SigningDisabledChild$", "-test.v")
+	cmd.Env = append(os.Environ(), envSigningDisabledChild+"=1")
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("child failed: %v\n%s", err, out)
+	}
+	if !bytes.Contains(out, []byte("--- PASS: TestEd25519SigningDisabledChild")) {
+		t.Fatalf("child did not run:\n%s", out)
+	}
+}
+
+func TestEd25519SigningDisabledChild(t *testing.T) {
+	if os.Getenv(envSigningDisabledChild) == "" {
+		t.Skip("run by TestEd25519SigningDisabled")
+	}
+	kp, err := falcongo.GenerateKeyPair(bytes.Repeat([]byte{13}, 48))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	sk := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{7}, ed25519.SeedSize))
+	source, err := Ed25519Source(sk)
+	if err != nil {
+		t.Fatalf("Ed25519Source failed: %v", err)
+	}
+	hybrid, err := hybridSigner(kp, sk)
+	if err != nil {
+		t.Fatalf("hybridSigner failed: %v", err)
+	}
+	falcongo.DisableSigning()
+
+	if _, err := DelegatePQLogicSig(kp.PublicKey, sk); !errors.Is(err, falcongo.ErrSigningDisabled) {
+		t.Errorf("DelegatePQLogicSig: got %v", err)
+	}
+	txn := types.Transaction{Type: types.PaymentTx, Header: types.Header{Sender: source.Address}}
+	if _, _, err := source.sign(txn); !errors.Is(err, falcongo.ErrSigningDisabled) {
+		t.Errorf("Ed25519Source: got %v", err)
+	}
+	// The FALCON signature of the hybrid signer fails too; the Ed25519 one
+	// is refused on its own all the same.
+	if _, _, err := hybrid.sign(txn); !errors.Is(err, falcongo.ErrSigningDisabled) {
+		t.Errorf("hybrid signer: got %v", err)
+	}
+}
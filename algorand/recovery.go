@@ -0,0 +1,103 @@
+package algorand
+
+import (
+	"encoding/binary"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// recoveryLogicSigCounterOffset is the offset of the counter byte in the
+// programs built by recoveryLogicSigProgram.
+const recoveryLogicSigCounterOffset = pqLogicSigCounterOffset
+
+// DeriveRecoveryLogicSig returns a LogicSig that approves a transaction
+// signed by the primary Falcon key at any time, or by the backup Falcon key
+// once the transaction's first valid round is after afterRound. Either
+// signature is passed as arg 0.
+//
+// Like DerivePQLogicSig, the derivation is deterministic and tries counter
+// values until the address does not decode to any Edwards25519 point.
+func DeriveRecoveryLogicSig(primary, backup falcongo.PublicKey, afterRound uint64,
+) (crypto.LogicSigAccount, error) {
+
+	for counter := range 256 {
+		lsig := crypto.LogicSigAccount{
+			Lsig: types.LogicSig{
+				Logic: recoveryLogicSigProgram(primary, backup, afterRound, byte(counter)),
+			},
+		}
+		lsa, err := lsig.Address()
+		if err != nil {
+			return crypto.LogicSigAccount{}, err
+		}
+		if !isOnTheCurve(lsa[:]) {
+			return lsig, nil
+		}
+	}
+	return crypto.LogicSigAccount{}, ErrInvalidFalconPublicKey
+}
+
+// DeriveRecoveryAddress returns the address of the LogicSig derived by
+// DeriveRecoveryLogicSig and the counter value the derivation settled on.
+func DeriveRecoveryAddress(primary, backup falcongo.PublicKey, afterRound uint64,
+) (types.Address, byte, error) {
+
+	lsig, err := DeriveRecoveryLogicSig(primary, backup, afterRound)
+	if err != nil {
+		return types.Address{}, 0, err
+	}
+	address, err := lsig.Address()
+	if err != nil {
+		return types.Address{}, 0, err
+	}
+	return address, lsig.Lsig.Logic[recoveryLogicSigCounterOffset], nil
+}
+
+// recoveryLogicSigProgram returns the compiled RecoverylogicsigTMPL TEAL
+// code with the given keys, round and counter value:
+//
+//	bytes				| teal
+//	_______________________________________________________________________
+//	0c					| #pragma version 12
+//	26 01 01 00			| bytecblock 0x00 (counter)
+//	31 17				| txn TxID
+//	2d					| arg 0
+//	80 81 0e 00...		| pushbytes 0x00... (1793 primary key bytes)
+//	85					| falcon_verify
+//	40 xx xx			| bnz primary
+//	31 02				| txn FirstValid
+//	81 xx...			| pushint <afterRound> (uvarint)
+//	0d					| >
+//	44					| assert
+//	31 17				| txn TxID
+//	2d					| arg 0
+//	80 81 0e 00...		| pushbytes 0x00... (1793 backup key bytes)
+//	85					| falcon_verify
+//	43					| return
+//	81 01				| primary: pushint 1
+func recoveryLogicSigProgram(primary, backup falcongo.PublicKey, afterRound uint64,
+	counter byte) []byte {
+
+	verify := func(program []byte, publicKey falcongo.PublicKey) []byte {
+		program = append(program, 0x31, 0x17, 0x2d, 0x80, 0x81, 0x0e)
+		program = append(program, publicKey[:]...)
+		return append(program, 0x85)
+	}
+
+	var backupBranch []byte
+	backupBranch = append(backupBranch, 0x31, 0x02, 0x81)
+	backupBranch = binary.AppendUvarint(backupBranch, afterRound)
+	backupBranch = append(backupBranch, 0x0d, 0x44)
+	backupBranch = verify(backupBranch, backup)
+	backupBranch = append(backupBranch, 0x43)
+
+	program := []byte{0x0c, 0x26, 0x01, 0x01, 0x00}
+	program[recoveryLogicSigCounterOffset] = counter
+	program = verify(program, primary)
+	program = append(program, 0x40)
+	program = binary.BigEndian.AppendUint16(program, uint16(len(backupBranch)))
+	program = append(program, backupBranch...)
+	return append(program, 0x81, 0x01)
+}
@@ -0,0 +1,92 @@
+package algorand
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+func testRecoveryKeys() (primary, backup falcongo.PublicKey) {
+	for i := range primary {
+		primary[i] = byte(i)
+		backup[i] = byte(255 - i)
+	}
+	return primary, backup
+}
+
+// TestRecoveryLogicSigProgram checks the layout of the assembled program and
+// that the branch of the primary key lands on its final pushint 1.
+func TestRecoveryLogicSigProgram(t *testing.T) {
+	primary, backup := testRecoveryKeys()
+	verifySize := 6 + falcongo.PublicKeySize + 1
+	for _, afterRound := range []uint64{1, 127, 128, 50_000_000} {
+		program := recoveryLogicSigProgram(primary, backup, afterRound, 7)
+		if program[recoveryLogicSigCounterOffset] != 7 {
+			t.Fatalf("counter = %d, want 7", program[recoveryLogicSigCounterOffset])
+		}
+		pos := 5 + verifySize
+		if !bytes.Equal(program[5:pos], patchPrecompiledPQlogicsig(primary, 7)[5:]) {
+			t.Fatalf("round %d: primary check differs from the PQ logicsig", afterRound)
+		}
+		if program[pos] != 0x40 {
+			t.Fatalf("round %d: opcode at %d = %#x, want bnz", afterRound, pos, program[pos])
+		}
+		target := pos + 3 + int(binary.BigEndian.Uint16(program[pos+1:]))
+		if !bytes.Equal(program[target:], []byte{0x81, 0x01}) {
+			t.Fatalf("round %d: bnz lands on %x, want pushint 1", afterRound, program[target:])
+		}
+		round := binary.AppendUvarint(nil, afterRound)
+		check := append([]byte{0x31, 0x02, 0x81}, round...)
+		check = append(check, 0x0d, 0x44)
+		if !bytes.HasPrefix(program[pos+3:], check) {
+			t.Fatalf("round %d: time check = %x, want %x", afterRound, program[pos+3:], check)
+		}
+		backupAt := pos + 3 + len(check) + 6
+		if !bytes.Equal(program[backupAt:backupAt+falcongo.PublicKeySize], backup[:]) {
+			t.Fatalf("round %d: backup key not found at %d", afterRound, backupAt)
+		}
+		if want := backupAt + falcongo.PublicKeySize + 2; want != target {
+			t.Fatalf("round %d: backup branch ends at %d, want %d", afterRound, want, target)
+		}
+	}
+}
+
+func TestDeriveRecoveryAddress(t *testing.T) {
+	primary, backup := testRecoveryKeys()
+	address, counter, err := DeriveRecoveryAddress(primary, backup, 1000)
+	if err != nil {
+		t.Fatalf("DeriveRecoveryAddress failed: %v", err)
+	}
+	if isOnTheCurve(address[:]) {
+		t.Fatalf("recovery address decodes to an Edwards25519 point")
+	}
+	lsig, err := DeriveRecoveryLogicSig(primary, backup, 1000)
+	if err != nil {
+		t.Fatalf("DeriveRecoveryLogicSig failed: %v", err)
+	}
+	if !bytes.Equal(lsig.Lsig.Logic, recoveryLogicSigProgram(primary, backup, 1000, counter)) {
+		t.Fatalf("logicsig does not match the program for counter %d", counter)
+	}
+
+	pqAddress, _, err := DerivePQAddress(primary)
+	if err != nil {
+		t.Fatalf("DerivePQAddress failed: %v", err)
+	}
+	for name, derive := range map[string]func() (falcongo.PublicKey, falcongo.PublicKey, uint64){
+		"plain primary": nil,
+		"other round":   func() (falcongo.PublicKey, falcongo.PublicKey, uint64) { return primary, backup, 1001 },
+		"keys swapped":  func() (falcongo.PublicKey, falcongo.PublicKey, uint64) { return backup, primary, 1000 },
+	} {
+		other := pqAddress
+		if derive != nil {
+			if other, _, err = DeriveRecoveryAddress(derive()); err != nil {
+				t.Fatalf("%s: DeriveRecoveryAddress failed: %v", name, err)
+			}
+		}
+		if other == address {
+			t.Fatalf("%s: same address %s", name, address)
+		}
+	}
+}
@@ -2,8 +2,11 @@ package algorand
 
 import (
 	"context"
+	"crypto/ed25519"
 	_ "embed"
+	"fmt"
 
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/algod"
 	"github.com/algorand/go-algorand-sdk/v2/crypto"
 	"github.com/algorand/go-algorand-sdk/v2/transaction"
 	"github.com/algorand/go-algorand-sdk/v2/types"
@@ -18,90 +21,353 @@ type SendOptions struct {
 	// UseFlatFee controls whether to override suggested fee with Fee as a flat fee.
 	// If false, suggested params' fee behavior is used.
 	UseFlatFee bool
+	// Fees, if set, overrides Fee and UseFlatFee with the fees proposed by
+	// SuggestFees for the payment and its dummy transactions.
+	Fees *FeeBreakdown
+	// RekeyTo, if set, rekeys the PQ account to this address in the payment
+	// transaction itself: from then on only RekeyTo can authorize spending.
+	RekeyTo string
+	// OnBroadcast, if set, is called with the signed group right before it is
+	// broadcast, so the caller can record it and check on it with
+	// CheckPending should the wait for confirmation fail or the process stop.
+	// An error aborts before broadcasting.
+	OnBroadcast func(PendingGroup) error
+	// Delegation, if set, sends from the account that delegated to the PQ
+	// logicsig of keyPair instead of from the PQ account; it must verify
+	// with Delegation.Verify.
+	Delegation *Delegation
+	// From, if set, sends from this account, rekeyed to the account that
+	// signs (see RekeyToPQ), instead of from the signing account itself. It
+	// excludes Delegation.
+	From string
+	// HybridKey, if set, sends from the hybrid account of keyPair and this
+	// Ed25519 key (see DeriveHybridLogicSig) instead of from the PQ account,
+	// signing with both keys. It excludes Delegation.
+	HybridKey ed25519.PrivateKey
+	// Program, if set, sends from the account of this compiled logicsig
+	// instead of from the PQ account, passing it a FALCON signature of the
+	// TxID as arg 0; it must pass CheckPQProgram. It excludes Delegation and
+	// HybridKey.
+	Program []byte
+	// LimitWindow, if set, prepares the payment for a Program derived by
+	// DeriveLimitedPQLogicSig with this window (see PrepareLimitedTxn).
+	LimitWindow uint64
+	// OnConfirmed, if set, is called by Send with the group and the round it
+	// was committed in, e.g. to archive the bytes that were broadcast.
+	OnConfirmed func(g PendingGroup, round uint64)
+	// Params, if set, are used instead of the suggested params of the
+	// network, e.g. a snapshot taken on another machine to build the group
+	// offline with SignSend.
+	Params *types.SuggestedParams
 }
 
-// we need extra transactions to cover 3030 bytes of LogicSis since each txn has
-// a 1000 bytes limit
-const dummyTxnNeeded = 3
+// Each transaction in a group adds logicSigBytesPerTxn bytes to the pooled
+// logicsig size budget. A PQ logicsig takes at most pqLogicSigMaxSize bytes:
+// the program plus a maximum-size compressed FALCON signature, so one PQ
+// transaction needs 3 extra (dummy) transactions.
+const (
+	logicSigBytesPerTxn = 1000
+	pqLogicSigMaxSize   = pqLogicSigProgramSize + falcongo.MaxCompressedSignatureSize
+)
 
+// Send pays amount microAlgos from the PQ account of keyPair to the address to
+// and waits for confirmation. It returns the ID of the payment and of the
+// group it was sent in (with the dummy transactions covering its logicsig).
 func Send(keyPair falcongo.KeyPair, to string, amount uint64, opt SendOptions,
-) (txID string, err error) {
+) (txID string, groupID types.Digest, err error) {
 
-	lsig, err := DerivePQLogicSig(keyPair.PublicKey)
+	algodClient, err := GetAlgodClient(opt.Network)
 	if err != nil {
-		return "", err
+		return "", types.Digest{}, err
 	}
-	lsa, err := lsig.Address()
+	g, err := SignSend(keyPair, to, amount, opt)
 	if err != nil {
-		return "", err
+		return "", types.Digest{}, err
 	}
-	lsigAddress := lsa.String()
+	round, err := broadcastPQGroup(algodClient, g, opt.OnBroadcast)
+	if err != nil {
+		return "", types.Digest{}, err
+	}
+	if opt.OnConfirmed != nil {
+		opt.OnConfirmed(g, round)
+	}
+	return g.TxIDs[0], g.GroupID, nil
+}
 
-	algodClient, err := GetAlgodClient(opt.Network)
+// SignSend builds and signs the group of Send without broadcasting it, so that
+// SubmitGroup can broadcast it later, possibly from another machine. With
+// opt.Params set and opt.Fees unset, it needs no network access.
+func SignSend(keyPair falcongo.KeyPair, to string, amount uint64, opt SendOptions) (PendingGroup, error) {
+	signer, err := sendSigner(keyPair, opt)
 	if err != nil {
-		return "", err
+		return PendingGroup{}, err
 	}
-	sp, err := algodClient.SuggestedParams().Do(context.Background())
+	lsa, err := signer.lsig.Address()
 	if err != nil {
-		return "", err
+		return PendingGroup{}, err
+	}
+	lsigAddress := lsa.String()
+	if d := opt.Delegation; d != nil {
+		lsigAddress = d.Delegator.String()
+	}
+	if opt.From != "" {
+		if opt.Delegation != nil {
+			return PendingGroup{}, fmt.Errorf("a delegation cannot be combined with another sender")
+		}
+		if _, err := types.DecodeAddress(opt.From); err != nil {
+			return PendingGroup{}, fmt.Errorf("invalid sender address: %w", err)
+		}
+		lsigAddress = opt.From
+	}
+
+	var sp types.SuggestedParams
+	if opt.Params != nil {
+		sp = *opt.Params
+	} else if sp, err = GetSuggestedParams(opt.Network); err != nil {
+		return PendingGroup{}, err
 	}
-	if opt.UseFlatFee {
+	var dummyFee uint64
+	switch {
+	case opt.Fees != nil:
+		sp.FlatFee = true
+		sp.Fee = types.MicroAlgos(opt.Fees.PQTxnFee)
+		dummyFee = opt.Fees.DummyFee
+	case opt.UseFlatFee:
 		sp.FlatFee = true
 		sp.Fee = types.MicroAlgos(opt.Fee)
 	}
+	if sp.FlatFee {
+		// Catch a fee too low for the whole group here rather than have
+		// algod reject it.
+		if err := networkParamsFrom(sp).CheckSendFee(uint64(sp.Fee), dummyFee); err != nil {
+			return PendingGroup{}, err
+		}
+	}
 
-	var sendTxn types.Transaction
-	sendTxn, err = transaction.MakePaymentTxn(
-		lsigAddress, // from
-		to,          // to
-		amount,      // amount
-		opt.Note,    // note
-		"",          // closeRemainderTo
-		sp,          // suggested params
+	sendTxn, err := makePaymentTxn(lsigAddress, to, amount, opt, sp)
+	if err != nil {
+		return PendingGroup{}, err
+	}
+	if opt.LimitWindow != 0 {
+		if err := PrepareLimitedTxn(&sendTxn, opt.LimitWindow); err != nil {
+			return PendingGroup{}, err
+		}
+	}
+	return signPQGroup([]pqSigner{signer}, []types.Transaction{sendTxn}, 0, sp, dummyFee)
+}
+
+// sendSigner returns the signer of the payment of Send: the PQ logicsig of
+// keyPair, as delegated by opt.Delegation if set, the hybrid logicsig of
+// keyPair and opt.HybridKey, or opt.Program.
+func sendSigner(keyPair falcongo.KeyPair, opt SendOptions) (pqSigner, error) {
+	switch {
+	case opt.Delegation != nil && opt.HybridKey != nil:
+		return pqSigner{}, fmt.Errorf("a delegation and a hybrid key cannot be combined")
+	case opt.Program != nil && (opt.Delegation != nil || opt.HybridKey != nil):
+		return pqSigner{}, fmt.Errorf("a program cannot be combined with a delegation or a hybrid key")
+	case opt.Program != nil:
+		if err := CheckPQProgram(opt.Program, keyPair.PublicKey); err != nil {
+			return pqSigner{}, err
+		}
+		lsig := crypto.LogicSigAccount{Lsig: types.LogicSig{Logic: opt.Program}}
+		return pqSigner{keyPair: keyPair, lsig: lsig}, nil
+	case opt.HybridKey != nil:
+		return hybridSigner(keyPair, opt.HybridKey)
+	case opt.Delegation != nil:
+		if err := opt.Delegation.Verify(keyPair.PublicKey); err != nil {
+			return pqSigner{}, err
+		}
+		lsig := crypto.LogicSigAccount{Lsig: opt.Delegation.LogicSig}
+		lsig.Lsig.Args = nil
+		return pqSigner{keyPair: keyPair, lsig: lsig}, nil
+	}
+	lsig, err := DerivePQLogicSig(keyPair.PublicKey)
+	if err != nil {
+		return pqSigner{}, err
+	}
+	return pqSigner{keyPair: keyPair, lsig: lsig}, nil
+}
+
+// makePaymentTxn builds the payment of Send, rekeying the sender to
+// opt.RekeyTo if it is set.
+func makePaymentTxn(from, to string, amount uint64, opt SendOptions,
+	sp types.SuggestedParams,
+) (types.Transaction, error) {
+
+	txn, err := transaction.MakePaymentTxn(
+		from,     // from
+		to,       // to
+		amount,   // amount
+		opt.Note, // note
+		"",       // closeRemainderTo
+		sp,       // suggested params
 	)
 	if err != nil {
-		return "", err
+		return types.Transaction{}, err
 	}
+	if opt.RekeyTo != "" {
+		if err := txn.Rekey(opt.RekeyTo); err != nil {
+			return types.Transaction{}, fmt.Errorf("invalid rekey-to address: %w", err)
+		}
+	}
+	return txn, nil
+}
 
-	// add dummy transactions to cover the size of the SignLogicSigTransaction
-	sendGroup, err := makeSendGroup(&sendTxn, opt.Network, dummyTxnNeeded)
+// pqSigner is a PQ account: its logicsig and the FALCON keypair it verifies.
+type pqSigner struct {
+	keyPair falcongo.KeyPair
+	lsig    crypto.LogicSigAccount
+	// ed25519Key, if set, also signs the TxID, as arg 1 of a hybrid logicsig.
+	ed25519Key ed25519.PrivateKey
+}
+
+// sign signs txn with the logicsig of s, passing it a FALCON signature of
+// the TxID as arg 0 and, for a hybrid logicsig, an Ed25519 one as arg 1.
+func (s pqSigner) sign(txn types.Transaction) (string, []byte, error) {
+	signature, err := s.keyPair.SignTransactionID(txn)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
+	signer := s.lsig.Lsig
+	signer.Args = [][]byte{signature}
+	if s.ed25519Key != nil {
+		if falcongo.SigningDisabled() {
+			return "", nil, falcongo.ErrSigningDisabled
+		}
+		address, err := s.lsig.Address()
+		if err != nil {
+			return "", nil, err
+		}
+		edSignature, err := crypto.TealSign(s.ed25519Key, crypto.TransactionID(txn), address)
+		if err != nil {
+			return "", nil, err
+		}
+		signer.Args = append(signer.Args, edSignature[:])
+	}
+	return crypto.SignLogicSigTransaction(signer, txn)
+}
+
+// sendPQGroup groups txns, all sent by the PQ account of lsig, with the dummy
+// transactions needed to cover the size of their logicsigs. The dummy fees,
+// dummyFee each or the minimum fee if zero, are added to txns[feePayer]. Each PQ transaction is signed with a FALCON signature
+// of its TxID. The group is passed to onBroadcast (if not nil), broadcast, and
+// the IDs of txns and the group ID are returned once the last of them is
+// confirmed.
+func sendPQGroup(algodClient *algod.Client, keyPair falcongo.KeyPair,
+	lsig crypto.LogicSigAccount, txns []types.Transaction, feePayer int,
+	dummyFee uint64, onBroadcast func(PendingGroup) error,
+) ([]string, types.Digest, error) {
 
-	txnToSign := sendGroup[0]
-	signature, err := keyPair.Sign(crypto.TransactionID(txnToSign))
+	signers := make([]pqSigner, len(txns))
+	for i := range signers {
+		signers[i] = pqSigner{keyPair: keyPair, lsig: lsig}
+	}
+	return sendSignedPQGroup(algodClient, signers, txns, feePayer, dummyFee, onBroadcast)
+}
+
+// sendSignedPQGroup is sendPQGroup for transactions sent by several PQ
+// accounts: txns[i] is signed by signers[i].
+func sendSignedPQGroup(algodClient *algod.Client, signers []pqSigner,
+	txns []types.Transaction, feePayer int, dummyFee uint64,
+	onBroadcast func(PendingGroup) error,
+) ([]string, types.Digest, error) {
+
+	if BroadcastDisabled() {
+		return nil, types.Digest{}, ErrBroadcastDisabled
+	}
+	sp, err := algodClient.SuggestedParams().Do(context.Background())
+	if err != nil {
+		return nil, types.Digest{}, err
+	}
+	g, err := signPQGroup(signers, txns, feePayer, sp, dummyFee)
 	if err != nil {
-		return "", err
+		return nil, types.Digest{}, err
+	}
+	if _, err := broadcastPQGroup(algodClient, g, onBroadcast); err != nil {
+		return nil, types.Digest{}, err
+	}
+	return g.TxIDs, g.GroupID, nil
+}
+
+// signPQGroup groups txns with the dummy transactions covering their
+// logicsigs, as sendSignedPQGroup does, and signs the group without
+// broadcasting it.
+func signPQGroup(signers []pqSigner, txns []types.Transaction, feePayer int,
+	sp types.SuggestedParams, dummyFee uint64,
+) (PendingGroup, error) {
+
+	if dummyFee == 0 {
+		dummyFee = sp.MinFee
 	}
-	lsig.Lsig.Args = [][]byte{signature}
 
-	txID, signedTxn, err := crypto.SignLogicSigTransaction(lsig.Lsig, txnToSign)
+	// add dummy transactions to cover the size of the SignLogicSigTransaction
+	group, err := makeSendGroup(txns, feePayer, sp, dummyTxnsNeeded(len(txns)), dummyFee)
 	if err != nil {
-		return "", err
+		return PendingGroup{}, err
 	}
 
 	var sendBytes []byte
-	sendBytes = append(sendBytes, signedTxn...)
-	for i := 1; i < len(sendGroup); i++ {
-		signedDummyTxn, err := signDummyTxn(sendGroup[i])
+	txIDs := make([]string, len(txns))
+	for i := range txns {
+		txID, signedTxn, err := signers[i].sign(group[i])
 		if err != nil {
-			return "", err
+			return PendingGroup{}, err
+		}
+		txIDs[i] = txID
+		sendBytes = append(sendBytes, signedTxn...)
+	}
+	for i := len(txns); i < len(group); i++ {
+		signedDummyTxn, err := signDummyTxn(group[i])
+		if err != nil {
+			return PendingGroup{}, err
 		}
 		sendBytes = append(sendBytes, signedDummyTxn...)
 	}
+	return PendingGroup{
+		TxIDs:       txIDs,
+		GroupID:     group[0].Group,
+		SignedGroup: sendBytes,
+		FirstValid:  uint64(group[0].FirstValid),
+		LastValid:   uint64(group[0].LastValid),
+	}, nil
+}
 
-	_, err = algodClient.SendRawTransaction(sendBytes).Do(context.Background())
-	if err != nil {
-		return "", err
-	}
+// broadcastPQGroup passes g to onBroadcast (if not nil), broadcasts it and
+// waits for the confirmation of its last PQ transaction. It returns the round
+// the group was committed in.
+func broadcastPQGroup(algodClient *algod.Client, g PendingGroup, onBroadcast func(PendingGroup) error,
+) (uint64, error) {
 
-	_, err = transaction.WaitForConfirmation(algodClient, txID, 9, context.Background())
+	if BroadcastDisabled() {
+		return 0, ErrBroadcastDisabled
+	}
+	if onBroadcast != nil {
+		if err := onBroadcast(g); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := algodClient.SendRawTransaction(g.SignedGroup).Do(context.Background()); err != nil {
+		return 0, err
+	}
+	info, err := transaction.WaitForConfirmation(algodClient, g.TxIDs[len(g.TxIDs)-1], 9,
+		context.Background())
 	if err != nil {
-		return "", err
+		return 0, err
 	}
+	return info.ConfirmedRound, nil
+}
 
-	return txID, nil
+// dummyTxnsNeeded returns how many dummy transactions must accompany pqTxns PQ
+// transactions so the pooled logicsig size budget covers all their logicsigs.
+//
+// The padding cannot be avoided: the budget grows only with the number of
+// transactions in the group, whatever they are. Padding with transactions of
+// the sender would need a PQ signature each, and so more budget; the dummy
+// transactions carry a tiny logicsig, no signature and, with fee pooling, no
+// fee of their own (see BenchmarkSendGroupFootprint).
+func dummyTxnsNeeded(pqTxns int) int {
+	needed := (pqTxns*pqLogicSigMaxSize + logicSigBytesPerTxn - 1) / logicSigBytesPerTxn
+	return needed - pqTxns
 }
 
 //go:embed teal/dummyLsig.teal.tok
@@ -118,29 +384,20 @@ func signDummyTxn(txn types.Transaction) ([]byte, error) {
 	return signedDummyTxn, nil
 }
 
-// makeSendGroup inserts the given transaction in a group adding dummy transactions
-// and returns the group with the given transaction as first element
-// The given transaction will be modified to include the group ID and the extra fees
-func makeSendGroup(txn *types.Transaction, network Network, dummyNeeded int,
+// makeSendGroup appends dummyNeeded dummy transactions to txns and returns the
+// resulting group, with txns first and in order. The fees for the dummy
+// transactions, dummyFee each, are added to txns[feePayer], and all
+// transactions get the group ID.
+func makeSendGroup(txns []types.Transaction, feePayer int, sp types.SuggestedParams,
+	dummyNeeded int, dummyFee uint64,
 ) ([]types.Transaction, error) {
 
-	algodClient, err := GetAlgodClient(network)
-	if err != nil {
-		return nil, err
-	}
-
-	sp, err := algodClient.SuggestedParams().Do(context.Background())
-	if err != nil {
-		return nil, err
-	}
 	sp.FlatFee = true
 	sp.Fee = 0
 
+	group := append([]types.Transaction(nil), txns...)
 	// update fee to cover the extra transactions
-	txn.Fee += types.MicroAlgos(uint64(dummyNeeded) * sp.MinFee)
-
-	var txns []types.Transaction
-	txns = append(txns, *txn)
+	group[feePayer].Fee += types.MicroAlgos(uint64(dummyNeeded) * dummyFee)
 
 	for i := range dummyNeeded {
 		dummyLsig := crypto.LogicSigAccount{
@@ -164,15 +421,15 @@ func makeSendGroup(txn *types.Transaction, network Network, dummyNeeded int,
 			return nil, err
 		}
 
-		txns = append(txns, dummyTxn)
+		group = append(group, dummyTxn)
 	}
 
-	gid, err := crypto.ComputeGroupID(txns)
+	gid, err := crypto.ComputeGroupID(group)
 	if err != nil {
 		return nil, err
 	}
-	for i := range txns {
-		txns[i].Group = gid
+	for i := range group {
+		group[i].Group = gid
 	}
-	return txns, nil
+	return group, nil
 }
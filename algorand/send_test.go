@@ -0,0 +1,181 @@
+package algorand
+
+import (
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/transaction"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestDummyTxnsNeeded checks the padding covers the pooled logicsig budget.
+func TestDummyTxnsNeeded(t *testing.T) {
+	if got := dummyTxnsNeeded(1); got != 3 {
+		t.Fatalf("expected 3 dummy transactions for one PQ transaction, got %d", got)
+	}
+	for pq := 1; pq <= 4; pq++ {
+		total := pq + dummyTxnsNeeded(pq)
+		if total*logicSigBytesPerTxn < pq*pqLogicSigMaxSize {
+			t.Fatalf("%d PQ transactions: %d transactions do not cover %d bytes",
+				pq, total, pq*pqLogicSigMaxSize)
+		}
+		if (total-1)*logicSigBytesPerTxn >= pq*pqLogicSigMaxSize {
+			t.Fatalf("%d PQ transactions: %d transactions is more than needed", pq, total)
+		}
+	}
+}
+
+// TestPQLogicSigProgramSize ensures the size constant matches derived programs.
+func TestPQLogicSigProgramSize(t *testing.T) {
+	var pk falcongo.PublicKey
+	if got := len(patchPrecompiledPQlogicsig(pk, 0)); got != pqLogicSigProgramSize {
+		t.Fatalf("expected program size %d, got %d", pqLogicSigProgramSize, got)
+	}
+}
+
+// TestMakeSendGroup checks ordering, fee pooling and group IDs.
+func TestMakeSendGroup(t *testing.T) {
+	sp := types.SuggestedParams{
+		Fee:             0,
+		FlatFee:         true,
+		MinFee:          1000,
+		FirstRoundValid: 1,
+		LastRoundValid:  1000,
+		GenesisID:       "test-v1",
+		GenesisHash:     make([]byte, 32),
+	}
+	var kp falcongo.KeyPair
+	lsig, err := DerivePQLogicSig(kp.PublicKey)
+	if err != nil {
+		t.Fatalf("DerivePQLogicSig failed: %v", err)
+	}
+	from, err := lsig.Address()
+	if err != nil {
+		t.Fatalf("Address failed: %v", err)
+	}
+	var txns []types.Transaction
+	for i := range 2 {
+		txn, err := transaction.MakePaymentTxn(from.String(), from.String(), uint64(i),
+			nil, "", sp)
+		if err != nil {
+			t.Fatalf("MakePaymentTxn failed: %v", err)
+		}
+		txn.Fee = 1000
+		txns = append(txns, txn)
+	}
+
+	dummies := dummyTxnsNeeded(len(txns))
+	group, err := makeSendGroup(txns, 1, sp, dummies, sp.MinFee)
+	if err != nil {
+		t.Fatalf("makeSendGroup failed: %v", err)
+	}
+	if len(group) != len(txns)+dummies {
+		t.Fatalf("expected %d transactions, got %d", len(txns)+dummies, len(group))
+	}
+	if group[0].Fee != 1000 || group[1].Fee != types.MicroAlgos(1000+1000*dummies) {
+		t.Fatalf("unexpected fees: %d, %d", group[0].Fee, group[1].Fee)
+	}
+	if txns[1].Fee != 1000 {
+		t.Fatalf("makeSendGroup must not modify the input transactions")
+	}
+	dummyLsig := crypto.LogicSigAccount{Lsig: types.LogicSig{Logic: dummyLsigCompiled}}
+	dummyAddr, err := dummyLsig.Address()
+	if err != nil {
+		t.Fatalf("dummy Address failed: %v", err)
+	}
+	gid := group[0].Group
+	if gid == (types.Digest{}) {
+		t.Fatalf("group ID not set")
+	}
+	for i, txn := range group {
+		if txn.Group != gid {
+			t.Fatalf("transaction %d has a different group ID", i)
+		}
+		if i >= len(txns) && (txn.Sender != dummyAddr || txn.Fee != 0) {
+			t.Fatalf("transaction %d is not a zero-fee dummy transaction", i)
+		}
+	}
+}
+
+// TestMakePaymentTxn_RekeyTo checks the payment carries the rekey only when
+// requested and rejects malformed addresses.
+func TestMakePaymentTxn_RekeyTo(t *testing.T) {
+	sp := types.SuggestedParams{
+		MinFee:          1000,
+		FirstRoundValid: 1,
+		LastRoundValid:  1000,
+		GenesisID:       "test-v1",
+		GenesisHash:     make([]byte, 32),
+	}
+	from := crypto.GenerateAccount().Address.String()
+	to := crypto.GenerateAccount().Address
+	newAuth := crypto.GenerateAccount().Address
+
+	txn, err := makePaymentTxn(from, to.String(), 5, SendOptions{}, sp)
+	if err != nil {
+		t.Fatalf("makePaymentTxn failed: %v", err)
+	}
+	if !txn.RekeyTo.IsZero() {
+		t.Fatalf("unexpected rekey-to %s", txn.RekeyTo)
+	}
+
+	txn, err = makePaymentTxn(from, to.String(), 5, SendOptions{RekeyTo: newAuth.String()}, sp)
+	if err != nil {
+		t.Fatalf("makePaymentTxn failed: %v", err)
+	}
+	if txn.RekeyTo != newAuth || txn.Receiver != to || txn.Amount != 5 {
+		t.Fatalf("unexpected transaction: rekey-to %s, receiver %s, amount %d",
+			txn.RekeyTo, txn.Receiver, txn.Amount)
+	}
+
+	if _, err := makePaymentTxn(from, to.String(), 5, SendOptions{RekeyTo: "not-an-address"}, sp); err == nil {
+		t.Fatal("expected error for malformed rekey-to address")
+	}
+}
+
+// BenchmarkSendGroupFootprint reports the ledger footprint of the group of a
+// single PQ payment: the signed bytes of the payment, with a maximum-size
+// signature, and of the dummy transactions that pad the logicsig budget.
+func BenchmarkSendGroupFootprint(b *testing.B) {
+	sp := types.SuggestedParams{MinFee: 1000, FlatFee: true, FirstRoundValid: 1, LastRoundValid: 1000,
+		GenesisID: "test-v1", GenesisHash: make([]byte, 32)}
+	var kp falcongo.KeyPair
+	lsig, err := DerivePQLogicSig(kp.PublicKey)
+	if err != nil {
+		b.Fatalf("DerivePQLogicSig failed: %v", err)
+	}
+	from, err := lsig.Address()
+	if err != nil {
+		b.Fatalf("Address failed: %v", err)
+	}
+	txn, err := transaction.MakePaymentTxn(from.String(), from.String(), 1, nil, "", sp)
+	if err != nil {
+		b.Fatalf("MakePaymentTxn failed: %v", err)
+	}
+	signer := lsig.Lsig
+	signer.Args = [][]byte{make([]byte, falcongo.MaxCompressedSignatureSize)}
+
+	var pqBytes, paddingBytes, txns int
+	for b.Loop() {
+		group, err := makeSendGroup([]types.Transaction{txn}, 0, sp, dummyTxnsNeeded(1), sp.MinFee)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_, signed, err := crypto.SignLogicSigTransaction(signer, group[0])
+		if err != nil {
+			b.Fatal(err)
+		}
+		pqBytes, paddingBytes, txns = len(signed), 0, len(group)
+		for _, dummy := range group[1:] {
+			signed, err := signDummyTxn(dummy)
+			if err != nil {
+				b.Fatal(err)
+			}
+			paddingBytes += len(signed)
+		}
+	}
+	b.ReportMetric(float64(txns), "txns/group")
+	b.ReportMetric(float64(pqBytes), "pq-bytes/group")
+	b.ReportMetric(float64(paddingBytes), "padding-bytes/group")
+}
@@ -0,0 +1,41 @@
+package algorand
+
+import (
+	"fmt"
+
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// DecodeTxn decodes a msgpack transaction, bare or in a (signed or unsigned)
+// transaction envelope as written by goal.
+func DecodeTxn(b []byte) (types.Transaction, error) {
+	var stxn types.SignedTxn
+	if err := msgpack.Decode(b, &stxn); err == nil && stxn.Txn.Type != "" {
+		b = msgpack.Encode(stxn.Txn)
+	}
+	var txn types.Transaction
+	if err := msgpack.Decode(b, &txn); err != nil {
+		return types.Transaction{}, fmt.Errorf("invalid transaction msgpack: %w", err)
+	}
+	if txn.Type == "" {
+		return types.Transaction{}, fmt.Errorf("invalid transaction msgpack: no transaction type")
+	}
+	return txn, nil
+}
+
+// SignTransaction signs txn, built elsewhere, with the PQ logicsig of
+// keyPair, passing it a FALCON signature of the TxID as arg 0. The sender
+// must be the PQ account or an account rekeyed to it. Whoever built txn must
+// have grouped it with the dummy transactions covering its logicsig (see
+// dummyTxnsNeeded): a PQ transaction alone exceeds the logicsig size budget.
+// It returns the TxID and the signed transaction.
+func SignTransaction(keyPair falcongo.KeyPair, txn types.Transaction) (string, []byte, error) {
+	lsig, err := DerivePQLogicSig(keyPair.PublicKey)
+	if err != nil {
+		return "", nil, err
+	}
+	return pqSigner{keyPair: keyPair, lsig: lsig}.sign(txn)
+}
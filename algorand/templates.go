@@ -0,0 +1,143 @@
+package algorand
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// ErrTemplateMismatch is returned by VerifyTemplateIntegrity when a template
+// of the binary does not match the manifest.
+var ErrTemplateMismatch = errors.New("templates do not match the manifest")
+
+// templateManifest lists the SHA-256 of each template, in the format of
+// sha256sum. It is generated by go generate ./algorand.
+//
+//go:embed teal/templates.sha256
+var templateManifest []byte
+
+// TemplateCheck is the result of checking one template against the
+// manifest. Want is empty for a template missing from the manifest, and Got
+// for an entry of the manifest the binary has no template for.
+type TemplateCheck struct {
+	Name string
+	Want string // SHA-256, hex
+	Got  string // SHA-256, hex
+}
+
+// OK reports whether the template matches the manifest.
+func (c TemplateCheck) OK() bool {
+	return c.Want != "" && c.Want == c.Got
+}
+
+// TemplateManifestSHA256 returns the SHA-256 of the embedded manifest, hex,
+// to compare with the one published for a release.
+func TemplateManifestSHA256() string {
+	sum := sha256.Sum256(templateManifest)
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateTemplateManifest returns the manifest of the templates of this
+// package, as embedded by go generate.
+func GenerateTemplateManifest() []byte {
+	var b bytes.Buffer
+	for _, t := range templatePrograms() {
+		sum := sha256.Sum256(t.program)
+		fmt.Fprintf(&b, "%x  %s\n", sum, t.name)
+	}
+	return b.Bytes()
+}
+
+// VerifyTemplateIntegrity recomputes the SHA-256 of each template the binary
+// derives accounts with and compares it with the embedded manifest, offline.
+// The files of teal/ embedded as they are hash as such, so that sha256sum
+// can check them too; the programs assembled in Go are hashed with all-zero
+// keys and counter and the smallest valid parameters. It returns a check
+// per template and, if any fails, ErrTemplateMismatch.
+//
+// A binary that was tampered with can come with a matching manifest: compare
+// TemplateManifestSHA256 with the one published for the release.
+func VerifyTemplateIntegrity() ([]TemplateCheck, error) {
+	return verifyTemplates(templateManifest, templatePrograms())
+}
+
+func verifyTemplates(manifest []byte, programs []templateProgram) ([]TemplateCheck, error) {
+	want, err := parseTemplateManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+	var checks []TemplateCheck
+	for _, t := range programs {
+		sum := sha256.Sum256(t.program)
+		checks = append(checks, TemplateCheck{Name: t.name, Want: want[t.name], Got: hex.EncodeToString(sum[:])})
+		delete(want, t.name)
+	}
+	for _, name := range slices.Sorted(maps.Keys(want)) {
+		checks = append(checks, TemplateCheck{Name: name, Want: want[name]})
+	}
+	for _, c := range checks {
+		if !c.OK() {
+			return checks, ErrTemplateMismatch
+		}
+	}
+	return checks, nil
+}
+
+// parseTemplateManifest parses lines of a SHA-256, hex, two spaces and a
+// name.
+func parseTemplateManifest(manifest []byte) (map[string]string, error) {
+	sums := map[string]string{}
+	s := bufio.NewScanner(bytes.NewReader(manifest))
+	for n := 1; s.Scan(); n++ {
+		sum, name, ok := strings.Cut(s.Text(), "  ")
+		if b, err := hex.DecodeString(sum); !ok || err != nil || len(b) != sha256.Size || name == "" {
+			return nil, fmt.Errorf("invalid template manifest: line %d", n)
+		}
+		if _, dup := sums[name]; dup {
+			return nil, fmt.Errorf("invalid template manifest: %s listed twice", name)
+		}
+		sums[name] = sum
+	}
+	return sums, s.Err()
+}
+
+// templateProgram is a template as the manifest names it, and its bytes.
+type templateProgram struct {
+	name    string
+	program []byte
+}
+
+// templatePrograms returns the templates of the package, in the order of
+// the manifest.
+func templatePrograms() []templateProgram {
+	var pk falcongo.PublicKey
+	programs := []templateProgram{
+		{"teal/PQlogicsig.teal.tok", PQlogicsigPrecompile},
+		{"teal/PQlogicsigTMPL.teal", []byte(PQlogicsigTMPL)},
+		{"teal/dummyLsig.teal.tok", dummyLsigCompiled},
+	}
+	for _, v := range TealVersions() {
+		programs = append(programs, templateProgram{
+			fmt.Sprintf("PQlogicsig version %d", v), pqLogicSigLayouts[v].patch(pk, 0),
+		})
+	}
+	return append(programs,
+		templateProgram{"Recoverylogicsig", recoveryLogicSigProgram(pk, pk, 0, 0)},
+		templateProgram{"Hybridlogicsig", hybridLogicSigProgram(pk, make(ed25519.PublicKey, ed25519.PublicKeySize), 0)},
+		templateProgram{"Limitedlogicsig", limitedLogicSigProgram(pk, 1, 1, 0)},
+		templateProgram{"Timelockedlogicsig", timeLockedLogicSigProgram(pk, 0, 0)},
+		templateProgram{"Allowlistlogicsig", allowListLogicSigProgram(pk, []types.Address{{}}, 0)},
+	)
+}
@@ -0,0 +1,62 @@
+package algorand
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestTemplateManifest checks that the embedded manifest is that of the
+// templates of the package; run go generate ./algorand after changing one.
+func TestTemplateManifest(t *testing.T) {
+	if !bytes.Equal(templateManifest, GenerateTemplateManifest()) {
+		t.Fatalf("teal/templates.sha256 differs from the templates; run go generate ./algorand")
+	}
+	checks, err := VerifyTemplateIntegrity()
+	if err != nil {
+		t.Fatalf("VerifyTemplateIntegrity failed: %v", err)
+	}
+	if len(checks) != len(templatePrograms()) {
+		t.Fatalf("%d checks, want %d", len(checks), len(templatePrograms()))
+	}
+	if len(TemplateManifestSHA256()) != 64 {
+		t.Fatalf("TemplateManifestSHA256 = %q", TemplateManifestSHA256())
+	}
+}
+
+func TestVerifyTemplates(t *testing.T) {
+	programs := templatePrograms()
+	lines := strings.SplitAfter(string(GenerateTemplateManifest()), "\n")
+
+	tampered := append([]templateProgram(nil), programs...)
+	tampered[3].program = append([]byte(nil), tampered[3].program...)
+	tampered[3].program[len(tampered[3].program)-1] ^= 1
+	extra := strings.Repeat("0", 64) + "  Unknownlogicsig\n"
+
+	for name, tc := range map[string]struct {
+		manifest string
+		programs []templateProgram
+		failed   string
+	}{
+		"tampered program": {strings.Join(lines, ""), tampered, programs[3].name},
+		"missing entry":    {strings.Join(lines[1:], ""), programs, programs[0].name},
+		"extra entry":      {strings.Join(lines, "") + extra, programs, "Unknownlogicsig"},
+	} {
+		checks, err := verifyTemplates([]byte(tc.manifest), tc.programs)
+		if !errors.Is(err, ErrTemplateMismatch) {
+			t.Fatalf("%s: got %v, want ErrTemplateMismatch", name, err)
+		}
+		for _, c := range checks {
+			if c.OK() == (c.Name == tc.failed) {
+				t.Fatalf("%s: check of %s: OK = %v", name, c.Name, c.OK())
+			}
+		}
+	}
+
+	for _, bad := range []string{"not a manifest\n", "abcd  name\n", lines[0] + lines[0]} {
+		if _, err := verifyTemplates([]byte(bad), programs); err == nil || errors.Is(err, ErrTemplateMismatch) {
+			t.Fatalf("manifest %q: got %v, want a parse error", bad, err)
+		}
+	}
+}
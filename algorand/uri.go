@@ -0,0 +1,54 @@
+package algorand
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// AddressURIScheme is the scheme of ARC-26 URIs, which wallets such as Pera
+// and Defly read from QR codes: algorand://<address>?label=<label> names an
+// account, which they can add as a watch account. Other query parameters
+// (amount, asset, note, xnote) make the URI a payment request.
+const AddressURIScheme = "algorand"
+
+// addressURIPaymentParams are the ARC-26 parameters of payment requests.
+var addressURIPaymentParams = []string{"amount", "asset", "note", "xnote"}
+
+// AddressURI returns the ARC-26 URI naming address, with label (if not
+// empty) percent-encoded.
+func AddressURI(address types.Address, label string) string {
+	uri := AddressURIScheme + "://" + address.String()
+	if label != "" {
+		uri += "?label=" + strings.ReplaceAll(url.QueryEscape(label), "+", "%20")
+	}
+	return uri
+}
+
+// ParseAddressURI returns the address and label of an ARC-26 URI naming an
+// account. Payment requests are refused.
+func ParseAddressURI(uri string) (address types.Address, label string, err error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return types.Address{}, "", err
+	}
+	if u.Scheme != AddressURIScheme {
+		return types.Address{}, "", fmt.Errorf("not an %s:// URI", AddressURIScheme)
+	}
+	address, err = types.DecodeAddress(u.Host + strings.TrimPrefix(u.Path, "/"))
+	if err != nil {
+		return types.Address{}, "", fmt.Errorf("invalid address: %w", err)
+	}
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return types.Address{}, "", err
+	}
+	for _, param := range addressURIPaymentParams {
+		if query.Has(param) {
+			return types.Address{}, "", fmt.Errorf("a payment request (%s=), not an account URI", param)
+		}
+	}
+	return address, query.Get("label"), nil
+}
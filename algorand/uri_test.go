@@ -0,0 +1,39 @@
+package algorand
+
+import (
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// TestAddressURI checks ARC-26 account URIs both ways.
+func TestAddressURI(t *testing.T) {
+	var addr types.Address
+	addr[0] = 5
+
+	for label, want := range map[string]string{
+		"":            "algorand://" + addr.String(),
+		"PQ savings":  "algorand://" + addr.String() + "?label=PQ%20savings",
+		"a&b=c+d/été": "algorand://" + addr.String() + "?label=a%26b%3Dc%2Bd%2F%C3%A9t%C3%A9",
+	} {
+		uri := AddressURI(addr, label)
+		if uri != want {
+			t.Fatalf("AddressURI(%q) = %q, want %q", label, uri, want)
+		}
+		gotAddr, gotLabel, err := ParseAddressURI(uri)
+		if err != nil || gotAddr != addr || gotLabel != label {
+			t.Fatalf("ParseAddressURI(%q) = %s, %q, %v", uri, gotAddr, gotLabel, err)
+		}
+	}
+
+	for _, uri := range []string{
+		"https://" + addr.String(),
+		"algorand://NOTANADDRESS",
+		"algorand://" + addr.String() + "?amount=1000",
+		"algorand://" + addr.String() + "?label=x&asset=31",
+	} {
+		if _, _, err := ParseAddressURI(uri); err == nil {
+			t.Errorf("ParseAddressURI(%q) succeeded", uri)
+		}
+	}
+}
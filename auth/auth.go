@@ -0,0 +1,277 @@
+// Package auth implements a challenge-response login with FALCON keys.
+//
+// A service issues a Challenge naming itself and carrying a random nonce and
+// an expiry. The client signs the challenge, prefixed with a domain tag so the
+// signature cannot be replayed as anything else, and returns a Response with
+// its public key. The service checks the response against the challenge it
+// issued and identifies the client by its public key.
+//
+// Each nonce must be accepted once: Issuer keeps the outstanding challenges
+// and forgets each as soon as it is answered. Services that issue challenges
+// statelessly must track used nonces themselves until they expire.
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+const (
+	// Version is the protocol version of challenges and responses.
+	Version = 1
+	// NonceSize is the size of challenge nonces in bytes.
+	NonceSize = 32
+	// DefaultTTL is how long challenges stay valid unless told otherwise.
+	DefaultTTL = 5 * time.Minute
+
+	domain = "falcon-auth-v1"
+)
+
+var (
+	// ErrExpired is returned for challenges past their expiry.
+	ErrExpired = errors.New("auth: challenge expired")
+	// ErrMismatch is returned for responses to another challenge.
+	ErrMismatch = errors.New("auth: response does not answer the challenge")
+	// ErrUnknownChallenge is returned by Issuer for challenges it did not
+	// issue or that were already answered.
+	ErrUnknownChallenge = errors.New("auth: unknown or already used challenge")
+	// ErrSignature is returned for responses whose signature does not verify.
+	ErrSignature = errors.New("auth: invalid signature")
+)
+
+// Nonce is the random part of a challenge. It is encoded in hex in JSON.
+type Nonce [NonceSize]byte
+
+// MarshalText encodes n in hex.
+func (n Nonce) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(n[:])), nil
+}
+
+// UnmarshalText decodes a hex nonce.
+func (n *Nonce) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(string(text))
+	if err != nil || len(b) != NonceSize {
+		return fmt.Errorf("auth: nonce must be %d hex-encoded bytes", NonceSize)
+	}
+	copy(n[:], b)
+	return nil
+}
+
+// Challenge is issued by a service to a client that wants to log in.
+type Challenge struct {
+	Version int       `json:"version"`
+	Service string    `json:"service"` // who issued it, e.g. "login.example.com"
+	Nonce   Nonce     `json:"nonce"`
+	Expires time.Time `json:"expires"` // RFC 3339, whole seconds
+}
+
+// NewChallenge returns a fresh challenge of service valid for ttl
+// (DefaultTTL if zero).
+func NewChallenge(service string, ttl time.Duration) (Challenge, error) {
+	if service == "" {
+		return Challenge{}, errors.New("auth: service must not be empty")
+	}
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	if ttl < 0 {
+		return Challenge{}, errors.New("auth: ttl must be positive")
+	}
+	c := Challenge{
+		Version: Version,
+		Service: service,
+		Expires: time.Now().Add(ttl).UTC().Truncate(time.Second),
+	}
+	if _, err := rand.Read(c.Nonce[:]); err != nil {
+		return Challenge{}, err
+	}
+	return c, nil
+}
+
+// SigningBytes returns the bytes signed in response to c: the domain tag
+// followed by the service, the nonce and the expiry, each encoded as a 4-byte
+// big-endian length followed by its bytes.
+func (c Challenge) SigningBytes() []byte {
+	var out []byte
+	appendField := func(b []byte) {
+		out = binary.BigEndian.AppendUint32(out, uint32(len(b)))
+		out = append(out, b...)
+	}
+	appendField([]byte(domain))
+	appendField([]byte(c.Service))
+	appendField(c.Nonce[:])
+	appendField([]byte(c.Expires.UTC().Format(time.RFC3339)))
+	return out
+}
+
+// Expired reports whether c is expired at now.
+func (c Challenge) Expired(now time.Time) bool {
+	return !now.Before(c.Expires)
+}
+
+// equal reports whether c and o are the same challenge.
+func (c Challenge) equal(o Challenge) bool {
+	return c.Version == o.Version && c.Service == o.Service && c.Nonce == o.Nonce &&
+		c.Expires.Equal(o.Expires)
+}
+
+// Response answers a challenge with a signature by the client's key.
+type Response struct {
+	Challenge Challenge
+	PublicKey falcongo.PublicKey
+	Signature falcongo.CompressedSignature
+}
+
+// responseJSON is the encoding of Response, with the key and signature in hex.
+type responseJSON struct {
+	Version   int       `json:"version"`
+	Challenge Challenge `json:"challenge"`
+	PublicKey string    `json:"public_key"`
+	Signature string    `json:"signature"`
+}
+
+// MarshalJSON encodes r with its public key and signature in hex.
+func (r Response) MarshalJSON() ([]byte, error) {
+	return json.Marshal(responseJSON{
+		Version:   Version,
+		Challenge: r.Challenge,
+		PublicKey: hex.EncodeToString(r.PublicKey[:]),
+		Signature: hex.EncodeToString(r.Signature),
+	})
+}
+
+// UnmarshalJSON decodes a response encoded by MarshalJSON.
+func (r *Response) UnmarshalJSON(data []byte) error {
+	var j responseJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if j.Version != Version {
+		return fmt.Errorf("auth: unsupported response version %d", j.Version)
+	}
+	pub, err := hex.DecodeString(j.PublicKey)
+	if err != nil || len(pub) != falcongo.PublicKeySize {
+		return errors.New("auth: invalid public_key")
+	}
+	sig, err := hex.DecodeString(j.Signature)
+	if err != nil || !falcongo.IsValidSignatureLength(len(sig)) {
+		return errors.New("auth: invalid signature encoding")
+	}
+	r.Challenge = j.Challenge
+	copy(r.PublicKey[:], pub)
+	r.Signature = sig
+	return nil
+}
+
+// Respond signs c with kp. It refuses expired challenges and challenges of an
+// unsupported version. Clients should also check that c.Service is the service
+// they mean to log in to before responding.
+func Respond(kp falcongo.KeyPair, c Challenge) (Response, error) {
+	if c.Version != Version {
+		return Response{}, fmt.Errorf("auth: unsupported challenge version %d", c.Version)
+	}
+	if c.Expired(time.Now()) {
+		return Response{}, ErrExpired
+	}
+	sig, err := kp.Sign(c.SigningBytes())
+	if err != nil {
+		return Response{}, err
+	}
+	return Response{Challenge: c, PublicKey: kp.PublicKey, Signature: sig}, nil
+}
+
+// Verify checks that r answers c, that c has not expired at now and that the
+// signature is valid for r.PublicKey. The caller decides whether that key may
+// log in, and must not accept a nonce twice.
+func Verify(c Challenge, r Response, now time.Time) error {
+	if !r.Challenge.equal(c) {
+		return ErrMismatch
+	}
+	if c.Expired(now) {
+		return ErrExpired
+	}
+	if err := falcongo.Verify(c.SigningBytes(), r.Signature, r.PublicKey); err != nil {
+		return ErrSignature
+	}
+	return nil
+}
+
+// Issuer issues challenges for a service and accepts each answer once.
+// It is safe for concurrent use.
+type Issuer struct {
+	service string
+	ttl     time.Duration
+
+	mu     sync.Mutex
+	issued map[Nonce]Challenge
+}
+
+// NewIssuer returns an Issuer of challenges for service, valid for ttl
+// (DefaultTTL if zero).
+func NewIssuer(service string, ttl time.Duration) *Issuer {
+	return &Issuer{service: service, ttl: ttl, issued: make(map[Nonce]Challenge)}
+}
+
+// Challenge issues a new challenge and remembers it until it is answered or
+// expires.
+func (is *Issuer) Challenge() (Challenge, error) {
+	c, err := NewChallenge(is.service, is.ttl)
+	if err != nil {
+		return Challenge{}, err
+	}
+	is.mu.Lock()
+	defer is.mu.Unlock()
+	is.prune(time.Now())
+	is.issued[c.Nonce] = c
+	return c, nil
+}
+
+// Verify checks r against the challenge it answers, which must have been
+// issued by is and not answered yet, and returns the key that signed it. The
+// challenge is forgotten once it is answered by a valid response, or once it
+// expires; an invalid response leaves it outstanding, so that whoever sees a
+// nonce cannot cancel the login by answering it first.
+func (is *Issuer) Verify(r Response) (falcongo.PublicKey, error) {
+	nonce := r.Challenge.Nonce
+	is.mu.Lock()
+	c, ok := is.issued[nonce]
+	is.mu.Unlock()
+	if !ok {
+		return falcongo.PublicKey{}, ErrUnknownChallenge
+	}
+	// The signature is checked without the lock, so that verifications do
+	// not queue behind each other.
+	if err := Verify(c, r, time.Now()); err != nil {
+		if errors.Is(err, ErrExpired) {
+			is.mu.Lock()
+			delete(is.issued, nonce)
+			is.mu.Unlock()
+		}
+		return falcongo.PublicKey{}, err
+	}
+	is.mu.Lock()
+	defer is.mu.Unlock()
+	if _, ok := is.issued[nonce]; !ok {
+		// Another valid response was accepted meanwhile.
+		return falcongo.PublicKey{}, ErrUnknownChallenge
+	}
+	delete(is.issued, nonce)
+	return r.PublicKey, nil
+}
+
+// prune forgets expired challenges. is.mu must be held.
+func (is *Issuer) prune(now time.Time) {
+	for n, c := range is.issued {
+		if c.Expired(now) {
+			delete(is.issued, n)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+func testKeyPair(t *testing.T, seed byte) falcongo.KeyPair {
+	t.Helper()
+	s := make([]byte, falcongo.SeedSize)
+	s[0] = seed
+	kp, err := falcongo.GenerateKeyPair(s)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	return kp
+}
+
+// TestRespondVerify checks a response round-trips through JSON and verifies,
+// and that tampering with any part of it is caught.
+func TestRespondVerify(t *testing.T) {
+	kp := testKeyPair(t, 1)
+	c, err := NewChallenge("login.example.com", time.Minute)
+	if err != nil {
+		t.Fatalf("NewChallenge failed: %v", err)
+	}
+	r, err := Respond(kp, c)
+	if err != nil {
+		t.Fatalf("Respond failed: %v", err)
+	}
+	data, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var got Response
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	now := time.Now()
+	if err := Verify(c, got, now); err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+
+	other, _ := NewChallenge("login.example.com", time.Minute)
+	if err := Verify(other, got, now); !errors.Is(err, ErrMismatch) {
+		t.Fatalf("Verify of another challenge: got %v, want ErrMismatch", err)
+	}
+	if err := Verify(c, got, c.Expires); !errors.Is(err, ErrExpired) {
+		t.Fatalf("Verify after expiry: got %v, want ErrExpired", err)
+	}
+	forged := got
+	forged.PublicKey = testKeyPair(t, 2).PublicKey
+	if err := Verify(c, forged, now); !errors.Is(err, ErrSignature) {
+		t.Fatalf("Verify with another key: got %v, want ErrSignature", err)
+	}
+
+	// The signature covers the service: the same nonce for another service
+	// does not verify.
+	relayed := c
+	relayed.Service = "evil.example.com"
+	got.Challenge = relayed
+	if err := Verify(relayed, got, now); !errors.Is(err, ErrSignature) {
+		t.Fatalf("Verify for another service: got %v, want ErrSignature", err)
+	}
+
+	c.Expires = time.Now().Add(-time.Second)
+	if _, err := Respond(kp, c); !errors.Is(err, ErrExpired) {
+		t.Fatalf("Respond to an expired challenge: got %v, want ErrExpired", err)
+	}
+}
+
+// TestIssuer checks each issued challenge is accepted exactly once, and that
+// invalid responses do not use it up.
+func TestIssuer(t *testing.T) {
+	kp := testKeyPair(t, 1)
+	is := NewIssuer("login.example.com", 0)
+	c, err := is.Challenge()
+	if err != nil {
+		t.Fatalf("Challenge failed: %v", err)
+	}
+	if d := time.Until(c.Expires); d <= DefaultTTL-2*time.Second || d > DefaultTTL {
+		t.Fatalf("challenge expires in %v, want about %v", d, DefaultTTL)
+	}
+	forged, err := Respond(testKeyPair(t, 2), c)
+	if err != nil {
+		t.Fatalf("Respond failed: %v", err)
+	}
+	forged.PublicKey = kp.PublicKey
+	if _, err := is.Verify(forged); !errors.Is(err, ErrSignature) {
+		t.Fatalf("forged response: got %v, want ErrSignature", err)
+	}
+	r, err := Respond(kp, c)
+	if err != nil {
+		t.Fatalf("Respond failed: %v", err)
+	}
+	pk, err := is.Verify(r)
+	if err != nil || pk != kp.PublicKey {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if _, err := is.Verify(r); !errors.Is(err, ErrUnknownChallenge) {
+		t.Fatalf("replayed response: got %v, want ErrUnknownChallenge", err)
+	}
+	stray, _ := NewChallenge("login.example.com", 0)
+	if r, err = Respond(kp, stray); err != nil {
+		t.Fatalf("Respond failed: %v", err)
+	}
+	if _, err := is.Verify(r); !errors.Is(err, ErrUnknownChallenge) {
+		t.Fatalf("challenge not issued: got %v, want ErrUnknownChallenge", err)
+	}
+}
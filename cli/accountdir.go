@@ -0,0 +1,55 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+)
+
+// accountConfigDir returns the falcon directory in the user configuration
+// directory of the account running falcon, where os.UserConfigDir puts it by
+// default, but found from the home directory in the system user database.
+// No environment variable moves it, so what it holds can enforce signing
+// policy: second factor enrollments and the revocation store.
+func accountConfigDir() (string, error) {
+	home, err := accountHomeDir()
+	if err == nil && home == "" {
+		err = errors.New("no home directory")
+	}
+	if err != nil {
+		return "", fmt.Errorf("cannot find the home directory of the current user: %w", err)
+	}
+	var cfg string
+	switch runtime.GOOS {
+	case "windows":
+		cfg = filepath.Join(home, "AppData", "Roaming")
+	case "darwin", "ios":
+		cfg = filepath.Join(home, "Library", "Application Support")
+	default:
+		cfg = filepath.Join(home, ".config")
+	}
+	return filepath.Join(cfg, "falcon"), nil
+}
+
+// accountHomeDir returns the home directory of the current user as recorded
+// by the system (the passwd database, or the profile of the Windows account),
+// whatever $HOME says. Tests replace it.
+var accountHomeDir = func() (string, error) {
+	if runtime.GOOS == "windows" {
+		u, err := user.Current()
+		if err != nil {
+			return "", err
+		}
+		return u.HomeDir, nil
+	}
+	// user.Current falls back to $HOME without cgo; LookupId does not.
+	u, err := user.LookupId(strconv.Itoa(os.Getuid()))
+	if err != nil {
+		return "", err
+	}
+	return u.HomeDir, nil
+}
@@ -1,37 +1,49 @@
 package cli
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
 
 	"github.com/algorandfoundation/falcon-signatures/algorand"
 	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/mnemonic"
 )
 
-// ---- algorand dispatcher ----
-func runAlgorand(args []string) int {
-	if len(args) == 0 {
-		fmt.Fprintf(os.Stderr, "usage: falcon algorand <address|send> [flags]\n")
-		fmt.Fprintln(os.Stderr, "Run 'falcon help algorand' for details.")
-		return 2
+// readProgramFile reads a compiled TEAL program, raw or in base64 as
+// returned by algod's compile endpoint.
+func readProgramFile(path string) ([]byte, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
 	}
-	sub := args[0]
-	switch sub {
-	case "help", "-h", "--help":
-		fmt.Fprint(os.Stdout, helpAlgorand)
-		return 0
-	case "address":
-		return runAlgorandAddress(args[1:])
-	case "send":
-		return runAlgorandSend(args[1:])
-	default:
-		fmt.Fprintf(os.Stderr, "unknown algorand subcommand: %s\n", sub)
-		fmt.Fprintf(os.Stderr, "usage: falcon algorand <address|send> [flags]\n")
-		fmt.Fprintln(os.Stderr, "Run 'falcon help algorand' for details.")
-		return 2
+	if decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(b))); err == nil {
+		return decoded, nil
 	}
+	return b, nil
+}
+
+// ---- algorand dispatcher ----
+func runAlgorand(args []string) int {
+	return runSubcommand("algorand", args)
 }
 
 // ---- algorand address ----
@@ -41,22 +53,76 @@ func runAlgorandAddress(args []string) int {
 	keyPath := fs.String("key", "", "path to keypair/public key JSON file")
 	out := fs.String("out", "", "write derived address to file (stdout if empty)")
 	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
-	_ = fs.Parse(args)
+	keysPath := fs.String("keys", "", "key JSON file or directory; further key files may follow as arguments")
+	format := fs.String("format", "csv", "with --keys: table format, csv or json")
+	check := fs.String("check", "", "with --keys: compare against a previously exported table")
+	workers := fs.Int("workers", runtime.NumCPU(), "with --keys: number of parallel workers")
+	tealVersion := fs.Uint("teal-version", algorand.DefaultTealVersion, "TEAL version of the PQ logicsig, to preview addresses")
+	fromMnemonic := fs.String("from-mnemonic", "", "24-word BIP-39 mnemonic to derive the key from in memory, or - to read it from stdin")
+	parseFlags(fs, args)
 	passphraseProvided := false
+	formatSet := false
 	fs.Visit(func(f *flag.Flag) {
 		if f.Name == "mnemonic-passphrase" {
 			passphraseProvided = true
 		}
+		if f.Name == "format" {
+			formatSet = true
+		}
 	})
 
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	if *tealVersion > 255 || !slices.Contains(algorand.TealVersions(), byte(*tealVersion)) {
+		fmt.Fprintf(os.Stderr, "invalid --teal-version %d (supported: %v)\n", *tealVersion, algorand.TealVersions())
+		return 2
+	}
+	deriveOpt := algorand.DeriveOptions{TealVersion: byte(*tealVersion)}
+	if deriveOpt.TealVersion != algorand.DefaultTealVersion {
+		fmt.Fprintf(os.Stderr, "previewing TEAL version %d: these addresses differ from those of version %d, "+
+			"which the other commands use\n", deriveOpt.TealVersion, algorand.DefaultTealVersion)
+	}
+
+	if *fromMnemonic != "" {
+		if *keyPath != "" || *keysPath != "" || fs.NArg() > 0 || formatSet || *check != "" {
+			fmt.Fprintf(os.Stderr, "cannot combine --from-mnemonic with --key, --keys, --format or --check\n")
+			return 2
+		}
+		return addressFromMnemonic(*fromMnemonic, *mnemonicPassphrase, deriveOpt, *out)
+	}
+	if *keysPath != "" {
+		if *keyPath != "" {
+			fmt.Fprintf(os.Stderr, "cannot combine --key with --keys\n")
+			return 2
+		}
+		if *format != "csv" && *format != "json" {
+			fmt.Fprintf(os.Stderr, "invalid --format %q (want csv or json)\n", *format)
+			return 2
+		}
+		if *check != "" && (*out != "" || formatSet) {
+			fmt.Fprintf(os.Stderr, "cannot combine --check with --out or --format\n")
+			return 2
+		}
+		if *workers < 1 {
+			fmt.Fprintf(os.Stderr, "--workers must be at least 1\n")
+			return 2
+		}
+		paths, err := expandKeyPaths(append([]string{*keysPath}, fs.Args()...))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read --keys: %v\n", err)
+			return 2
+		}
+		return addressTable(paths, override, deriveOpt, *format, *out, *check, *workers)
+	}
 	if *keyPath == "" {
 		fmt.Fprintf(os.Stderr, "--key is required\n")
 		return 2
 	}
-
-	var override *string
-	if passphraseProvided {
-		override = mnemonicPassphrase
+	if fs.NArg() > 0 || formatSet || *check != "" {
+		fmt.Fprintf(os.Stderr, "extra key files, --format and --check require --keys\n")
+		return 2
 	}
 	pub, _, _, err := loadKeypairFile(*keyPath, override)
 	if err != nil {
@@ -71,11 +137,12 @@ func runAlgorandAddress(args []string) int {
 	var pk falcongo.PublicKey
 	copy(pk[:], pub)
 
-	address, err := algorand.GetAddressFromPublicKey(pk)
+	addr, _, err := algorand.DerivePQAddressWithOptions(pk, deriveOpt)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error deriving address: %v\n", err)
 		return 2
 	}
+	address := []byte(addr.String())
 
 	if *out == "" {
 		os.Stdout.Write(address)
@@ -90,6 +157,270 @@ func runAlgorandAddress(args []string) int {
 	return 0
 }
 
+// addressFromMnemonic derives the keypair of a 24-word mnemonic (read from
+// stdin if words is "-") in memory and prints its address and fingerprint,
+// or writes the address to out. No key file is written, and the seed and
+// private key are wiped once the public key is known.
+func addressFromMnemonic(words, passphrase string, opt algorand.DeriveOptions, out string) int {
+	if words == "-" {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Fprintf(os.Stderr, "failed to read the mnemonic from stdin: %v\n", err)
+			return 2
+		}
+		words = line
+	}
+	phrase := strings.Fields(words)
+	if len(phrase) != expectedMnemonicWords {
+		fmt.Fprintf(os.Stderr, "--from-mnemonic requires exactly %d words (got %d)\n",
+			expectedMnemonicWords, len(phrase))
+		return 2
+	}
+	pk, err := mnemonicPublicKey(phrase, passphrase)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --from-mnemonic: %v\n", err)
+		return 2
+	}
+
+	addr, _, err := algorand.DerivePQAddressWithOptions(pk, opt)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error deriving address: %v\n", err)
+		return 2
+	}
+	fp := falcongo.Fingerprint(pk)
+	if out == "" {
+		fmt.Printf("address: %s\nfingerprint: %s\npetname: %s\n", addr, hex.EncodeToString(fp[:]), mnemonic.Petname(fp))
+		return 0
+	}
+	if err := writeFileAtomic(out, []byte(addr.String()), 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", out, err)
+		return 2
+	}
+	fmt.Printf("fingerprint: %s\npetname: %s\n", hex.EncodeToString(fp[:]), mnemonic.Petname(fp))
+	return 0
+}
+
+// addressRow is one line of the table printed by algorand address --keys.
+type addressRow struct {
+	File        string `json:"file"`
+	Fingerprint string `json:"fingerprint"`
+	Address     string `json:"address"`
+	Counter     int    `json:"counter"`
+}
+
+// addressTableHeader is the CSV header of the address table.
+var addressTableHeader = []string{"file", "fingerprint", "address", "counter"}
+
+// expandKeyPaths replaces each directory in paths by the .json files it
+// contains, in name order.
+func expandKeyPaths(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.Type().IsRegular() && strings.HasSuffix(e.Name(), ".json") {
+				files = append(files, filepath.Join(path, e.Name()))
+			}
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no key files found")
+	}
+	return files, nil
+}
+
+// deriveAddressRow derives the address table row of one key file.
+func deriveAddressRow(path string, override *string, opt algorand.DeriveOptions) (addressRow, error) {
+	pub, _, _, err := loadKeypairFile(path, override)
+	if err != nil {
+		return addressRow{}, err
+	}
+	var pk falcongo.PublicKey
+	if len(pub) != len(pk) {
+		return addressRow{}, fmt.Errorf("valid public key not found")
+	}
+	copy(pk[:], pub)
+	address, counter, err := algorand.DerivePQAddressWithOptions(pk, opt)
+	if err != nil {
+		return addressRow{}, err
+	}
+	fp := falcongo.Fingerprint(pk)
+	return addressRow{
+		File:        path,
+		Fingerprint: hex.EncodeToString(fp[:]),
+		Address:     address.String(),
+		Counter:     int(counter),
+	}, nil
+}
+
+// addressTable derives the addresses of the key files in parallel, then either
+// writes them as a table or compares them against a previously exported one.
+func addressTable(paths []string, override *string, opt algorand.DeriveOptions, format, out, check string,
+	workers int) int {
+
+	rows := make([]addressRow, len(paths))
+	errs := make([]error, len(paths))
+	jobs := make(chan int)
+	bar := startProgress("deriving addresses", 0, uint64(len(paths)))
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				rows[i], errs[i] = deriveAddressRow(paths[i], override, opt)
+				bar.add(1)
+			}
+		}()
+	}
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	bar.finish()
+
+	failed := 0
+	var derived []addressRow
+	for i, err := range errs {
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "%s: %v\n", paths[i], err)
+			continue
+		}
+		derived = append(derived, rows[i])
+	}
+
+	code := 0
+	if check != "" {
+		code = checkAddressTable(derived, check)
+	} else if err := writeAddressTable(derived, format, out); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write address table: %v\n", err)
+		return 2
+	}
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d of %d keys failed\n", failed, len(paths))
+		return 2
+	}
+	return code
+}
+
+// writeAddressTable writes rows as CSV (with a header line) or as a JSON
+// array to out, or to stdout if out is empty.
+func writeAddressTable(rows []addressRow, format, out string) error {
+	var buf bytes.Buffer
+	if format == "json" {
+		if rows == nil {
+			rows = []addressRow{}
+		}
+		data, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return err
+		}
+		buf.Write(append(data, '\n'))
+	} else {
+		w := csv.NewWriter(&buf)
+		_ = w.Write(addressTableHeader)
+		for _, r := range rows {
+			_ = w.Write([]string{r.File, r.Fingerprint, r.Address, strconv.Itoa(r.Counter)})
+		}
+		w.Flush()
+		if err := w.Error(); err != nil {
+			return err
+		}
+	}
+	if out == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return writeFileAtomic(out, buf.Bytes(), 0o644)
+}
+
+// readAddressTable reads a table written by writeAddressTable in either
+// format.
+func readAddressTable(path string) ([]addressRow, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rows []addressRow
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("[")) {
+		if err := json.Unmarshal(b, &rows); err != nil {
+			return nil, fmt.Errorf("invalid JSON table: %w", err)
+		}
+		return rows, nil
+	}
+	records, err := csv.NewReader(bytes.NewReader(b)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid CSV table: %w", err)
+	}
+	if len(records) == 0 || !slices.Equal(records[0], addressTableHeader) {
+		return nil, fmt.Errorf("invalid CSV table: header must be %s",
+			strings.Join(addressTableHeader, ","))
+	}
+	for i, rec := range records[1:] {
+		counter, err := strconv.Atoi(rec[3])
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV table: line %d: counter: %w", i+2, err)
+		}
+		rows = append(rows, addressRow{File: rec[0], Fingerprint: rec[1],
+			Address: rec[2], Counter: counter})
+	}
+	return rows, nil
+}
+
+// checkAddressTable compares derived rows with the table at path by key
+// fingerprint and prints one line per difference. It returns 1 if the
+// derivation drifted or the key sets differ, 0 if they match and 2 if the
+// table cannot be read.
+func checkAddressTable(derived []addressRow, path string) int {
+	table, err := readAddressTable(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --check: %v\n", err)
+		return 2
+	}
+	want := make(map[string]addressRow, len(table))
+	for _, r := range table {
+		want[r.Fingerprint] = r
+	}
+	seen := make(map[string]bool, len(derived))
+	var diffs []string
+	for _, r := range derived {
+		seen[r.Fingerprint] = true
+		w, ok := want[r.Fingerprint]
+		switch {
+		case !ok:
+			diffs = append(diffs, fmt.Sprintf("%s: key %s not in %s", r.File, r.Fingerprint, path))
+		case w.Address != r.Address || w.Counter != r.Counter:
+			diffs = append(diffs, fmt.Sprintf("%s: derived %s (counter %d), table has %s (counter %d)",
+				r.File, r.Address, r.Counter, w.Address, w.Counter))
+		}
+	}
+	for _, w := range table {
+		if !seen[w.Fingerprint] {
+			diffs = append(diffs, fmt.Sprintf("%s: key %s only in %s", w.File, w.Fingerprint, path))
+		}
+	}
+	for _, d := range diffs {
+		fmt.Fprintln(os.Stdout, d)
+	}
+	if len(diffs) > 0 {
+		return 1
+	}
+	return 0
+}
+
 // ---- algorand send ----
 // Parse flags only; functionality is not implemented yet.
 func runAlgorandSend(args []string) int {
@@ -98,18 +429,74 @@ func runAlgorandSend(args []string) int {
 	to := fs.String("to", "", "Algorand destination address")
 	amount := fs.Uint64("amount", 0, "amount to send in microAlgos")
 	fee := fs.Uint64("fee", 0, "transaction fee in microAlgos (default: min network fee)")
+	feeStrategy := fs.String("fee-strategy", "", "propose the group fee: min, suggested or priority")
 	note := fs.String("note", "", "optional transaction note")
 	networkFlag := fs.String("network", "mainnet", "network: mainnet, testnet, betanet, devnet")
 	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
 	algodURL := fs.String("algod-url", "", "set algod API endpoint (optional)")
 	algodToken := fs.String("algod-token", "", "set algod API token (optional); requires --algod-url")
-	_ = fs.Parse(args)
+	preHook := fs.String("pre-hook", "", "program run before sending; non-zero exit aborts (env "+envPreHook+")")
+	postHook := fs.String("post-hook", "", "program run after confirmation (env "+envPostHook+")")
+	rekeyTo := fs.String("rekey-to", "", "also rekey the sending account to this address (DANGEROUS)")
+	confirmRekey := fs.String("confirm-rekey", "", "repeat the --rekey-to address to confirm without a prompt")
+	explorer := fs.String("explorer", "", "explorer links after sending: allo, pera, none or a URL template (env "+envExplorer+", default allo)")
+	jsonOut := fs.Bool("json", false, "print the result as JSON")
+	pendingDir := fs.String("pending-dir", "", "directory of pending transaction records (env "+envPendingDir+")")
+	receiptsDir := fs.String("receipts-dir", "", "archive the signed group and a receipt of the confirmed send here (env "+envReceiptsDir+")")
+	delegationPath := fs.String("delegation", "", "send from the account that delegated to the key (file written by algorand delegate)")
+	from := fs.String("from", "", "send from this account, rekeyed to the account of the key (algorand migrate --rekey)")
+	hybridMnemonic := fs.String("ed25519-mnemonic", "", "send from the hybrid account of the key and this 25-word Ed25519 mnemonic, or - to read it from stdin")
+	lsigFile := fs.String("from-lsig-file", "", "send from the account of this compiled logicsig (raw or base64) embedding the key")
+	template := fs.String("template", "", "name or file of a send template (algorand template-create); flags override its fields")
+	templateDir := fs.String("template-dir", "", "directory of send templates (env "+envTemplateDir+")")
+	offline := fs.Bool("offline", false, "build and sign from --suggested-params without network access; write the group to --out")
+	suggestedParams := fs.String("suggested-params", "", "with --offline: snapshot written by algorand suggested-params")
+	outPath := fs.String("out", "", "with --offline: write the signed group for algorand submit to this file")
+	parseFlags(fs, args)
 	// Track whether the user explicitly set --fee (even if zero)
 	feeSet := false
 	passphraseProvided := false
 	algodURLProvided := false
 	algodTokenProvided := false
+	preHookSet := false
+	postHookSet := false
+	explorerSet := false
+	pendingDirSet := false
+	receiptsDirSet := false
+	// With --template, the flags given override the fields of the template.
+	toSet, amountSet, noteSet, networkSet := false, false, false, false
+	templateDirSet := false
 	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "to" {
+			toSet = true
+		}
+		if f.Name == "amount" {
+			amountSet = true
+		}
+		if f.Name == "note" {
+			noteSet = true
+		}
+		if f.Name == "network" {
+			networkSet = true
+		}
+		if f.Name == "template-dir" {
+			templateDirSet = true
+		}
+		if f.Name == "pre-hook" {
+			preHookSet = true
+		}
+		if f.Name == "pending-dir" {
+			pendingDirSet = true
+		}
+		if f.Name == "receipts-dir" {
+			receiptsDirSet = true
+		}
+		if f.Name == "explorer" {
+			explorerSet = true
+		}
+		if f.Name == "post-hook" {
+			postHookSet = true
+		}
 		if f.Name == "fee" {
 			feeSet = true
 		}
@@ -124,6 +511,35 @@ func runAlgorandSend(args []string) int {
 		}
 	})
 
+	if *template != "" {
+		dir, err := resolveTemplateDir(*templateDir, templateDirSet)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		t, err := loadSendTemplate(*template, dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --template: %v\n", err)
+			return 2
+		}
+		if !toSet {
+			*to = t.To
+		}
+		if !amountSet {
+			*amount = t.Amount
+		}
+		if !noteSet {
+			if *note, err = expandNotePattern(t.Note, time.Now()); err != nil {
+				fmt.Fprintf(os.Stderr, "invalid --template: %v\n", err)
+				return 2
+			}
+		}
+		if !networkSet && t.Network != "" {
+			*networkFlag = t.Network
+			networkSet = true
+		}
+	}
+
 	// Validate required flags
 	if *keyPath == "" {
 		fmt.Fprintf(os.Stderr, "--key is required\n")
@@ -133,10 +549,85 @@ func runAlgorandSend(args []string) int {
 		fmt.Fprintf(os.Stderr, "--to is required\n")
 		return 2
 	}
-	if *amount == 0 {
+	if *amount == 0 && *rekeyTo == "" {
 		fmt.Fprintf(os.Stderr, "--amount is required and must be > 0\n")
 		return 2
 	}
+	if *rekeyTo != "" {
+		if _, err := types.DecodeAddress(*rekeyTo); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --rekey-to: %v\n", err)
+			return 2
+		}
+	}
+	if *confirmRekey != "" && *rekeyTo == "" {
+		fmt.Fprintf(os.Stderr, "--confirm-rekey requires --rekey-to\n")
+		return 2
+	}
+	sources := 0
+	for _, set := range []bool{*delegationPath != "", *hybridMnemonic != "", *lsigFile != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
+		fmt.Fprintf(os.Stderr, "--delegation, --ed25519-mnemonic and --from-lsig-file are mutually exclusive\n")
+		return 2
+	}
+	if *from != "" {
+		if *delegationPath != "" {
+			fmt.Fprintf(os.Stderr, "--from and --delegation are mutually exclusive\n")
+			return 2
+		}
+		if _, err := types.DecodeAddress(*from); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --from: %v\n", err)
+			return 2
+		}
+	}
+	if *offline {
+		if *suggestedParams == "" || *outPath == "" {
+			fmt.Fprintf(os.Stderr, "--offline requires --suggested-params and --out\n")
+			return 2
+		}
+		if *feeStrategy != "" || algodURLProvided || algodTokenProvided {
+			fmt.Fprintf(os.Stderr, "--offline cannot be combined with --fee-strategy, --algod-url or --algod-token\n")
+			return 2
+		}
+	} else if *suggestedParams != "" || *outPath != "" {
+		fmt.Fprintf(os.Stderr, "--suggested-params and --out require --offline\n")
+		return 2
+	}
+	var offlineParams types.SuggestedParams
+	if *offline {
+		network, sp, err := readSuggestedParams(*suggestedParams)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --suggested-params %s: %v\n", *suggestedParams, err)
+			return 2
+		}
+		// networkSet also covers the network of --template.
+		if networkSet && !strings.EqualFold(strings.TrimSpace(*networkFlag), network) {
+			fmt.Fprintf(os.Stderr, "network %s does not match the network of --suggested-params (%s)\n",
+				*networkFlag, network)
+			return 2
+		}
+		*networkFlag = network
+		offlineParams = sp
+	}
+	if *feeStrategy != "" {
+		if feeSet {
+			fmt.Fprintf(os.Stderr, "--fee and --fee-strategy are mutually exclusive\n")
+			return 2
+		}
+		if !slices.Contains(algorand.FeeStrategies, *feeStrategy) {
+			fmt.Fprintf(os.Stderr, "invalid --fee-strategy %q (use %s)\n", *feeStrategy,
+				strings.Join(algorand.FeeStrategies, ", "))
+			return 2
+		}
+	}
+	explorerValue := resolveExplorer(*explorer, explorerSet)
+	if _, err := algorand.ExplorerURLs(explorerValue, algorand.MainNet, "", types.Digest{}); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --explorer: %v\n", err)
+		return 2
+	}
 	if algodTokenProvided && !algodURLProvided {
 		fmt.Fprintf(os.Stderr, "--algod-token requires --algod-url\n")
 		return 2
@@ -154,36 +645,380 @@ func runAlgorandSend(args []string) int {
 		fmt.Fprintf(os.Stderr, "invalid --network: %v\n", err)
 		return 2
 	}
+	// Without a usable default directory, recordBeforeBroadcast warns instead.
+	recordDir, recordExplicit, _ := resolvePendingDir(*pendingDir, pendingDirSet)
 
 	// Load keypair (must include both public and private keys)
 	var override *string
 	if passphraseProvided {
 		override = mnemonicPassphrase
 	}
-	pub, priv, _, err := loadKeypairFile(*keyPath, override)
+	kp, code := loadSigningKeyPair("--key", *keyPath, override, "sending")
+	if code != 0 {
+		return code
+	}
+
+	opt := algorand.SendOptions{
+		Network:    netw,
+		Fee:        *fee,
+		Note:       []byte(*note),
+		UseFlatFee: feeSet,
+		RekeyTo:    *rekeyTo,
+		From:       *from,
+	}
+	if *delegationPath != "" {
+		d, err := readDelegation(*delegationPath, kp.PublicKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --delegation %s: %v\n", *delegationPath, err)
+			return 2
+		}
+		opt.Delegation = &d
+	}
+	if *hybridMnemonic != "" {
+		sk, err := readEd25519Mnemonic(*hybridMnemonic)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --ed25519-mnemonic: %v\n", err)
+			return 2
+		}
+		opt.HybridKey = sk
+	}
+	if *lsigFile != "" {
+		program, err := readProgramFile(*lsigFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read --from-lsig-file: %v\n", err)
+			return 2
+		}
+		if err := algorand.CheckPQProgram(program, kp.PublicKey); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --from-lsig-file %s: %v\n", *lsigFile, err)
+			return 2
+		}
+		opt.Program = program
+	}
+	if algodURLProvided {
+		if err := os.Setenv("ALGOD_URL", trimmedAlgodURL); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to set ALGOD_URL: %v\n", err)
+			return 2
+		}
+		if algodTokenProvided {
+			if err := os.Setenv("ALGOD_TOKEN", trimmedAlgodToken); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to set ALGOD_TOKEN: %v\n", err)
+				return 2
+			}
+		}
+	}
+
+	if *feeStrategy != "" {
+		fees, err := algorand.SuggestFees(*feeStrategy, netw)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to suggest fees: %v\n", err)
+			return 2
+		}
+		fmt.Fprint(os.Stderr, formatFeeBreakdown(fees))
+		opt.Fees = &fees
+		*fee = fees.Total
+	}
+	if *offline {
+		// SignSend checks the fee against the snapshot.
+		opt.Params = &offlineParams
+	} else if feeSet {
+		params, err := algorand.GetNetworkParams(netw)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to query network params: %v\n", err)
+			return 2
+		}
+		if err := params.CheckSendFee(*fee, 0); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --fee: %v\n", err)
+			fmt.Fprintf(os.Stderr, "use --fee %d or more, or omit --fee to pay the minimum\n",
+				params.MinFee)
+			return 2
+		}
+	}
+
+	preHookCmd := flagOrEnv(*preHook, preHookSet, envPreHook)
+	postHookCmd := flagOrEnv(*postHook, postHookSet, envPostHook)
+	event := hookEvent{
+		Operation: "algorand send",
+		KeyFile:   *keyPath,
+		Network:   strings.ToLower(strings.TrimSpace(*networkFlag)),
+		To:        *to,
+		Amount:    *amount,
+		Fee:       *fee,
+		Note:      *note,
+		RekeyTo:   *rekeyTo,
+	}
+	if preHookCmd != "" || postHookCmd != "" || *rekeyTo != "" {
+		from, err := algorand.GetAddressFromPublicKey(kp.PublicKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error deriving address: %v\n", err)
+			return 2
+		}
+		event.From = string(from)
+		if opt.Delegation != nil {
+			event.From = opt.Delegation.Delegator.String()
+		}
+		if opt.HybridKey != nil {
+			hybrid, _, err := algorand.DeriveHybridAddress(kp.PublicKey, opt.HybridKey.Public().(ed25519.PublicKey))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "error deriving address: %v\n", err)
+				return 2
+			}
+			event.From = hybrid.String()
+		}
+		if opt.Program != nil {
+			event.From = crypto.AddressFromProgram(opt.Program).String()
+		}
+		if opt.From != "" {
+			event.From = opt.From
+		}
+	}
+	if *rekeyTo != "" {
+		if code := confirmRekeyTo(event.From, *rekeyTo, *keyPath, kp.PublicKey, *confirmRekey); code != 0 {
+			return code
+		}
+	}
+	event.Stage = "pre"
+	if err := runHook(preHookCmd, event); err != nil {
+		fmt.Fprintf(os.Stderr, "send aborted: %v\n", err)
+		return 2
+	}
+
+	if *offline {
+		g, err := algorand.SignSend(kp, *to, *amount, opt)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "signing failed: %v\n", err)
+			return 2
+		}
+		if code := writeOfflineGroup(*outPath, event.Operation, event.Network, g); code != 0 {
+			return code
+		}
+		recordKeyUse(kp.PublicKey[:], *keyPath, "algorand send", event.Network, 1)
+		fmt.Fprintf(os.Stdout, "Signed transaction %s; submit it before round %d with:\n", g.TxIDs[0], g.LastValid+1)
+		fmt.Fprintf(os.Stdout, "  falcon algorand submit --in %s\n", *outPath)
+		return 0
+	}
+
+	var recordPath string
+	opt.OnBroadcast = recordBeforeBroadcast(recordDir, recordExplicit, event.Operation, event.Network, &recordPath)
+	var receiptPath string
+	var receiptErr error
+	if dir := resolveReceiptsDir(*receiptsDir, receiptsDirSet); dir != "" {
+		opt.OnConfirmed = func(g algorand.PendingGroup, round uint64) {
+			receiptPath, receiptErr = archiveReceipt(dir, event.Operation, event.Network, g, round)
+		}
+	}
+	txID, groupID, err := algorand.Send(kp, *to, *amount, opt)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
+		fmt.Fprintf(os.Stderr, "send failed: %v\n", err)
+		if recordPath != "" {
+			pendingTxID := strings.TrimSuffix(filepath.Base(recordPath), pendingRecordExt)
+			fmt.Fprintf(os.Stderr, "the transaction may have been broadcast; it is recorded in %s\n", recordPath)
+			fmt.Fprintf(os.Stderr, "run 'falcon algorand status --txid %s' to find out whether funds moved\n",
+				pendingTxID)
+		}
 		return 2
 	}
-	if pub == nil {
-		fmt.Fprintf(os.Stderr, "public key not found in %s (required for sending)\n", *keyPath)
+	if recordPath != "" {
+		if err := os.Remove(recordPath); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to remove %s: %v\n", recordPath, err)
+		}
+	}
+	recordKeyUse(kp.PublicKey[:], *keyPath, "algorand send", strings.ToLower(strings.TrimSpace(*networkFlag)), 1)
+
+	links, _ := algorand.ExplorerURLs(explorerValue, netw, txID, groupID)
+	if err := printSendResult(os.Stdout, txID, groupID, links, *jsonOut); err != nil {
+		fmt.Fprintf(os.Stderr, "transaction %s was sent, but printing the result failed: %v\n", txID, err)
 		return 2
 	}
-	if priv == nil {
-		fmt.Fprintf(os.Stderr, "private key not found in %s (required for sending)\n", *keyPath)
+	if receiptErr != nil {
+		fmt.Fprintf(os.Stderr, "transaction %s was sent, but archiving its receipt failed: %v\n", txID, receiptErr)
 		return 2
 	}
+	if receiptPath != "" {
+		fmt.Fprintf(os.Stderr, "receipt: %s\n", receiptPath)
+	}
 
-	var kp falcongo.KeyPair
-	copy(kp.PublicKey[:], pub)
-	copy(kp.PrivateKey[:], priv)
+	event.Stage = "post"
+	event.TxID = txID
+	if err := runHook(postHookCmd, event); err != nil {
+		fmt.Fprintf(os.Stderr, "transaction was sent, but %v\n", err)
+		return 2
+	}
+	return 0
+}
 
-	opt := algorand.SendOptions{
-		Network:    netw,
-		Fee:        *fee,
-		Note:       []byte(*note),
-		UseFlatFee: feeSet,
+// formatFeeBreakdown describes the fees proposed for --fee-strategy.
+func formatFeeBreakdown(b algorand.FeeBreakdown) string {
+	perByte := "no fee per byte"
+	if b.FeePerByte > 0 {
+		perByte = fmt.Sprintf("%d microAlgos per byte", b.FeePerByte)
+	}
+	return fmt.Sprintf("Fee (%s): %d microAlgos for %d transactions, all paid by the payment\n",
+		b.Strategy, b.Total, b.DummyTxns+1) +
+		fmt.Sprintf("  payment:  %d\n", b.PQTxnFee) +
+		fmt.Sprintf("  dummies:  %d x %d\n", b.DummyTxns, b.DummyFee) +
+		fmt.Sprintf("  network:  minimum fee %d, %s, recent blocks %.0f%% full\n",
+			b.MinFee, perByte, 100*b.Fullness)
+}
+
+// envExplorer selects the explorer links printed by send when --explorer is
+// not given.
+const envExplorer = "FALCON_EXPLORER"
+
+// resolveExplorer returns the --explorer value, falling back to $FALCON_EXPLORER
+// and then to allo.info.
+func resolveExplorer(flagValue string, flagSet bool) string {
+	if flagSet {
+		return flagValue
+	}
+	if v := strings.TrimSpace(os.Getenv(envExplorer)); v != "" {
+		return v
+	}
+	return algorand.ExplorerAllo
+}
+
+// sendResultJSON is the --json output of algorand send.
+type sendResultJSON struct {
+	TxID     string                  `json:"txid"`
+	Group    string                  `json:"group"` // base64, as shown by algod
+	Explorer *algorand.ExplorerLinks `json:"explorer,omitempty"`
+}
+
+// printSendResult prints the confirmed transaction and its explorer links.
+func printSendResult(w io.Writer, txID string, groupID types.Digest,
+	links algorand.ExplorerLinks, asJSON bool,
+) error {
+
+	if asJSON {
+		res := sendResultJSON{
+			TxID:  txID,
+			Group: base64.StdEncoding.EncodeToString(groupID[:]),
+		}
+		if links != (algorand.ExplorerLinks{}) {
+			res.Explorer = &links
+		}
+		data, err := json.MarshalIndent(res, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", data)
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Transaction confirmed with id: %s\n", txID); err != nil {
+		return err
+	}
+	if links.TxID != "" {
+		if _, err := fmt.Fprintf(w, "Transaction: %s\n", links.TxID); err != nil {
+			return err
+		}
+	}
+	if links.Group != "" {
+		if _, err := fmt.Fprintf(w, "Group: %s\n", links.Group); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// confirmRekeyTo warns that sending rekeys from to rekeyTo and requires the
+// rekeyTo address to be repeated, either by --confirm-rekey or typed on stdin.
+// The key pk of keyPath is named by its petname. It returns 0 if confirmed, or
+// the exit code to return.
+func confirmRekeyTo(from, rekeyTo, keyPath string, pk falcongo.PublicKey, confirmed string) int {
+	fp := falcongo.Fingerprint(pk)
+	fmt.Fprintf(os.Stderr, "WARNING: this transaction rekeys %s to %s.\n", from, rekeyTo)
+	fmt.Fprintf(os.Stderr, "WARNING: once confirmed, the FALCON key %s in %s can no longer authorize\n",
+		mnemonic.Petname(fp), keyPath)
+	fmt.Fprintf(os.Stderr, "WARNING: transactions from %s; only %s can, including to undo the rekey.\n",
+		from, rekeyTo)
+	if confirmed != "" {
+		if confirmed != rekeyTo {
+			fmt.Fprintln(os.Stderr, "--confirm-rekey does not match --rekey-to; nothing sent")
+			return 2
+		}
+		return 0
+	}
+	fmt.Fprint(os.Stderr, "type the --rekey-to address to confirm: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Fprintf(os.Stderr, "\nfailed to read confirmation: %v\n", err)
+		return 2
+	}
+	if strings.TrimSpace(line) != rekeyTo {
+		fmt.Fprintln(os.Stderr, "address does not match; nothing sent")
+		return 1
+	}
+	return 0
+}
+
+// ---- algorand claim ----
+func runAlgorandClaim(args []string) int {
+	fs := flag.NewFlagSet("algorand claim", flag.ExitOnError)
+	keyPath := fs.String("key", "", "path to FALCON keypair JSON file")
+	assetID := fs.Uint64("asset-id", 0, "ID of the asset to claim from the ARC-59 inbox")
+	routerAppID := fs.Uint64("router-app-id", 0, "ARC-59 router application ID (default: router of --network)")
+	networkFlag := fs.String("network", "mainnet", "network: mainnet, testnet, betanet, devnet")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	algodURL := fs.String("algod-url", "", "set algod API endpoint (optional)")
+	algodToken := fs.String("algod-token", "", "set algod API token (optional); requires --algod-url")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	algodURLProvided := false
+	algodTokenProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+		if f.Name == "algod-url" {
+			algodURLProvided = true
+		}
+		if f.Name == "algod-token" {
+			algodTokenProvided = true
+		}
+	})
+
+	// Validate required flags
+	if *keyPath == "" {
+		fmt.Fprintf(os.Stderr, "--key is required\n")
+		return 2
 	}
+	if *assetID == 0 {
+		fmt.Fprintf(os.Stderr, "--asset-id is required and must be > 0\n")
+		return 2
+	}
+	if algodTokenProvided && !algodURLProvided {
+		fmt.Fprintf(os.Stderr, "--algod-token requires --algod-url\n")
+		return 2
+	}
+	trimmedAlgodURL := strings.TrimSpace(*algodURL)
+	trimmedAlgodToken := strings.TrimSpace(*algodToken)
+	if algodURLProvided && trimmedAlgodURL == "" && algodTokenProvided && trimmedAlgodToken != "" {
+		fmt.Fprintf(os.Stderr, "--algod-token requires a non-empty --algod-url\n")
+		return 2
+	}
+
+	netw, err := parseAlgorandNetwork(*networkFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --network: %v\n", err)
+		return 2
+	}
+	if *routerAppID == 0 {
+		if _, err := algorand.ARC59RouterAppID(netw); err != nil {
+			fmt.Fprintf(os.Stderr, "--router-app-id is required for --network %s\n",
+				strings.ToLower(strings.TrimSpace(*networkFlag)))
+			return 2
+		}
+	}
+
+	// Load keypair (must include both public and private keys)
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	kp, code := loadSigningKeyPair("--key", *keyPath, override, "claiming")
+	if code != 0 {
+		return code
+	}
+
 	if algodURLProvided {
 		if err := os.Setenv("ALGOD_URL", trimmedAlgodURL); err != nil {
 			fmt.Fprintf(os.Stderr, "failed to set ALGOD_URL: %v\n", err)
@@ -197,13 +1032,124 @@ func runAlgorandSend(args []string) int {
 		}
 	}
 
-	txID, err := algorand.Send(kp, *to, *amount, opt)
+	txIDs, err := algorand.ClaimAsset(kp, *assetID, algorand.ClaimOptions{
+		Network:     netw,
+		RouterAppID: *routerAppID,
+	})
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "send failed: %v\n", err)
+		fmt.Fprintf(os.Stderr, "claim failed: %v\n", err)
 		return 2
 	}
+	recordKeyUse(kp.PublicKey[:], *keyPath, "algorand claim", strings.ToLower(strings.TrimSpace(*networkFlag)),
+		len(txIDs))
 
-	fmt.Fprintf(os.Stdout, "Transaction confirmed with id: %s\n", txID)
+	for _, txID := range txIDs {
+		fmt.Fprintf(os.Stdout, "Transaction confirmed with id: %s\n", txID)
+	}
+	return 0
+}
+
+// ---- algorand opt-in ----
+func runAlgorandOptIn(args []string) int {
+	fs := flag.NewFlagSet("algorand opt-in", flag.ExitOnError)
+	keyPath := fs.String("key", "", "path to FALCON keypair JSON file")
+	assetID := fs.Uint64("asset-id", 0, "ID of the asset to opt into")
+	sponsorPath := fs.String("sponsor", "", "FALCON keypair JSON of an account paying fees and minimum balance")
+	networkFlag := fs.String("network", "mainnet", "network: mainnet, testnet, betanet, devnet")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key files omit it)")
+	algodURL := fs.String("algod-url", "", "set algod API endpoint (optional)")
+	algodToken := fs.String("algod-token", "", "set algod API token (optional); requires --algod-url")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	algodURLProvided := false
+	algodTokenProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+		if f.Name == "algod-url" {
+			algodURLProvided = true
+		}
+		if f.Name == "algod-token" {
+			algodTokenProvided = true
+		}
+	})
+
+	// Validate required flags
+	if *keyPath == "" {
+		fmt.Fprintf(os.Stderr, "--key is required\n")
+		return 2
+	}
+	if *assetID == 0 {
+		fmt.Fprintf(os.Stderr, "--asset-id is required and must be > 0\n")
+		return 2
+	}
+	if algodTokenProvided && !algodURLProvided {
+		fmt.Fprintf(os.Stderr, "--algod-token requires --algod-url\n")
+		return 2
+	}
+	trimmedAlgodURL := strings.TrimSpace(*algodURL)
+	trimmedAlgodToken := strings.TrimSpace(*algodToken)
+	if algodURLProvided && trimmedAlgodURL == "" && algodTokenProvided && trimmedAlgodToken != "" {
+		fmt.Fprintf(os.Stderr, "--algod-token requires a non-empty --algod-url\n")
+		return 2
+	}
+
+	netw, err := parseAlgorandNetwork(*networkFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --network: %v\n", err)
+		return 2
+	}
+
+	// Load keypairs (must include both public and private keys)
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	kp, code := loadSigningKeyPair("--key", *keyPath, override, "opting in")
+	if code != 0 {
+		return code
+	}
+	opt := algorand.OptInOptions{Network: netw}
+	if *sponsorPath != "" {
+		sponsor, code := loadSigningKeyPair("--sponsor", *sponsorPath, override, "sponsoring")
+		if code != 0 {
+			return code
+		}
+		if sponsor.PublicKey == kp.PublicKey {
+			fmt.Fprintf(os.Stderr, "--sponsor must be a different key than --key\n")
+			return 2
+		}
+		opt.Sponsor = &sponsor
+	}
+
+	if algodURLProvided {
+		if err := os.Setenv("ALGOD_URL", trimmedAlgodURL); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to set ALGOD_URL: %v\n", err)
+			return 2
+		}
+		if algodTokenProvided {
+			if err := os.Setenv("ALGOD_TOKEN", trimmedAlgodToken); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to set ALGOD_TOKEN: %v\n", err)
+				return 2
+			}
+		}
+	}
+
+	txIDs, err := algorand.OptInAsset(kp, *assetID, opt)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "opt-in failed: %v\n", err)
+		return 2
+	}
+	network := strings.ToLower(strings.TrimSpace(*networkFlag))
+	if opt.Sponsor != nil {
+		recordKeyUse(opt.Sponsor.PublicKey[:], *sponsorPath, "algorand opt-in", network, 1)
+	}
+	recordKeyUse(kp.PublicKey[:], *keyPath, "algorand opt-in", network, 1)
+
+	for _, txID := range txIDs {
+		fmt.Fprintf(os.Stdout, "Transaction confirmed with id: %s\n", txID)
+	}
 	return 0
 }
 
@@ -228,26 +1174,413 @@ const helpAlgorand = `# falcon algorand
 Algorand utilities powered by FALCON signatures.
 
 Usage:
-  falcon algorand address --key <file> [--out <file>] [--mnemonic-passphrase <string>]
-  falcon algorand send --key <file> --to <address> --amount <number> [--fee <number>] [--note <string>] [--network <name>] [--algod-url <string>] [--algod-token <string>] [--mnemonic-passphrase <string>]
+  falcon algorand address --key <file> [--out <file>] [--teal-version <n>] [--mnemonic-passphrase <string>]
+  falcon algorand address --from-mnemonic <words|-> [--out <file>] [--teal-version <n>] [--mnemonic-passphrase <string>]
+  falcon algorand address --keys <file|dir> [<file>...] [--format csv|json] [--out <file> | --check <table>] [--workers <n>] [--teal-version <n>] [--mnemonic-passphrase <string>]
+  falcon algorand recovery-address --primary <file> --backup <file> --after-round <number> [--out <file>] [--mnemonic-passphrase <string>]
+  falcon algorand hybrid-address --key <file> --ed25519 <address> [--out <file>] [--mnemonic-passphrase <string>]
+  falcon algorand delegate --key <file> --from-ed25519-mnemonic <words|-> [--out <file>] [--mnemonic-passphrase <string>]
+  falcon algorand migrate --key <file> --from-ed25519 <words|-|kmd> [--kmd-wallet <name>] [--kmd-address <address>] [--kmd-password-file <file>] [--kmd-url <string>] [--kmd-token <string>] [--rekey [--confirm-rekey <address>] | --sweep [--confirm-sweep <address>]] [--report <file>] [--network <name>] [--algod-url <string>] [--algod-token <string>] [--mnemonic-passphrase <string>]
+  falcon algorand send --key <file> (--to <address> --amount <number> | --template <name|file>) [--delegation <file> | --ed25519-mnemonic <words|-> | --from-lsig-file <file>] [--from <address>] [--fee <number> | --fee-strategy <name>] [--note <string>] [--network <name>] [--algod-url <string>] [--algod-token <string>] [--pre-hook <program>] [--post-hook <program>] [--rekey-to <address> [--confirm-rekey <address>]] [--explorer <name|template>] [--json] [--pending-dir <dir>] [--receipts-dir <dir>] [--template-dir <dir>] [--mnemonic-passphrase <string>]
+  falcon algorand send --offline --suggested-params <file> --out <file> --key <file> (--to <address> --amount <number> | --template <name|file>) [send flags without --fee-strategy, --algod-url and --algod-token]
+  falcon algorand suggested-params [--out <file>] [--network <name>] [--algod-url <string>] [--algod-token <string>]
+  falcon algorand submit --in <file> [--explorer <name|template>] [--json] [--pending-dir <dir>] [--network <name>] [--algod-url <string>] [--algod-token <string>]
+  falcon algorand template-create --name <name> --to <address> --amount <number> [--note <pattern>] [--network <name>] [--template-dir <dir>] [--force]
+  falcon algorand template-list [--json] [--template-dir <dir>]
+  falcon algorand claim --key <file> --asset-id <number> [--router-app-id <number>] [--network <name>] [--algod-url <string>] [--algod-token <string>] [--mnemonic-passphrase <string>]
+  falcon algorand opt-in --key <file> --asset-id <number> [--sponsor <file>] [--network <name>] [--algod-url <string>] [--algod-token <string>] [--mnemonic-passphrase <string>]
+  falcon algorand asset-config --key <file> --asset-id <number> [--manager <address|none>] [--reserve <address|none>] [--freeze <address|none>] [--clawback <address|none>] [--confirm-remove <roles>] [--network <name>] [--algod-url <string>] [--algod-token <string>] [--mnemonic-passphrase <string>]
+  falcon algorand asset-destroy --key <file> --asset-id <number> [--confirm-destroy <number>] [--network <name>] [--algod-url <string>] [--algod-token <string>] [--mnemonic-passphrase <string>]
+  falcon algorand heartbeat (--key <file> | --address <address>) [--txn <file>] [--network <name>] [--algod-url <string>] [--algod-token <string>] [--mnemonic-passphrase <string>]
+  falcon algorand publish-key --key <file> [--network <name>] [--algod-url <string>] [--algod-token <string>] [--mnemonic-passphrase <string>]
+  falcon algorand fetch-key --address <address> [--out <file>] [--network <name>] [--indexer-url <string>] [--indexer-token <string>]
+  falcon algorand status (--txid <id> | --pending) [--wait <rounds>] [--no-rebroadcast] [--pending-dir <dir>] [--network <name>] [--algod-url <string>] [--algod-token <string>]
+  falcon algorand receipts list [--json] [--receipts-dir <dir>]
+  falcon algorand receipts show --txid <id> [--json] [--receipts-dir <dir>]
+  falcon algorand app-read --app-id <number> (--global | --local <address> | --key <file> | --box <name> | --boxes) [--msgpack] [--network <name>] [--algod-url <string>] [--algod-token <string>] [--mnemonic-passphrase <string>]
+  falcon algorand app-call --key <file> --app-id <number> --method <signature> [--args <value>]... [--inner-txns <number>] [--json] [--network <name>] [--algod-url <string>] [--algod-token <string>] [--mnemonic-passphrase <string>]
+  falcon algorand nft-mint --key <file> --standard <arc3|arc69> --metadata <file> [--unit-name <string>] [--asset-name <string>] [--url <string> | --ipfs-uploader <program>] [--decimals <n>] [--json] [--network <name>] [--algod-url <string>] [--algod-token <string>] [--mnemonic-passphrase <string>]
+  falcon algorand watch-export (--key <file> | --address <address>) [--label <string>] [--format text|uri|png|json] [--out <file>] [--invert] [--mnemonic-passphrase <string>]
+  falcon algorand scan-blocks (--round <n> | --first <n> --last <n>) [--anomalies-only] [--json] [--network <name>] [--algod-url <string>] [--algod-token <string>]
+  falcon algorand verify-templates [--expect <sha256>] [--json]
 
 Subcommands:
-  address   Derive an Algorand address from a FALCON public key
-  send      Send Algos from a FALCON-controlled address
+  address           Derive an Algorand address from a FALCON public key
+  recovery-address  Derive an address that a backup FALCON key can also spend from after a round
+  hybrid-address    Derive an address that needs both a FALCON and an Ed25519 signature
+  delegate          Delegate an existing Ed25519 account to a FALCON key
+  migrate           Move an existing Ed25519 account to a FALCON key, by rekeying or sweeping it
+  send              Send Algos from a FALCON-controlled address
+  suggested-params  Save a snapshot of the suggested params of a network for offline sends
+  submit            Broadcast a group signed by send --offline
+  template-create   Save the recipient, amount, note and network of a recurring send as a template
+  template-list     List the send templates
+  claim             Claim an asset from the ARC-59 inbox of a FALCON-controlled address
+  opt-in            Opt a FALCON-controlled address into an asset, optionally sponsored
+  asset-config      Change or remove the roles of an asset managed by a FALCON-controlled address
+  asset-destroy     Destroy an asset managed by a FALCON-controlled address
+  heartbeat         Show when an online account needs heartbeats, and send one
+  publish-key       Publish the FALCON public key of a PQ account on-chain
+  fetch-key         Fetch and verify the FALCON public key published for a PQ address
+  status            Check, and resume waiting for, a sent transaction
+  receipts          List and show the receipts archived by send --receipts-dir
+  app-read          Print the global, local or box storage of an application as JSON
+  app-call          Call an ARC-4 method of an application from a FALCON-controlled address
+  nft-mint          Mint an ARC-3 or ARC-69 NFT from a FALCON-controlled address
+  watch-export      Export the address of a PQ account as a URI and QR code for wallet watch accounts
+  scan-blocks       Re-verify the FALCON signatures of the PQ transactions of blocks
+  verify-templates  Check the logicsig templates of this binary against its manifest, offline
 
 Arguments (address):
-  --key <file>              keypair/public key JSON (required)
+  --key <file>              keypair/public key JSON (required unless --keys or --from-mnemonic is given)
+  --from-mnemonic <words|-> derive the key of a 24-word mnemonic in memory, without a key file, and
+                              print its address, fingerprint and petname (e.g. to check a backup phrase);
+                              - reads the words from stdin, which keeps them out of the shell history
+  --out <file>              write derived address, or the table with --keys (stdout if omitted)
+  --mnemonic-passphrase     optional mnemonic passphrase when the key file omits it
+  --keys <file|dir>         derive a table of file, fingerprint, address and counter for many keys:
+                              a key file followed by more key files, or a directory of *.json files
+  --format <csv|json>       with --keys: table format (default: csv)
+  --check <table>           with --keys: compare against a previously exported table (csv or json)
+                              and print each difference; exits 1 on drift
+  --workers <n>             with --keys: parallel workers (default: number of CPUs)
+  --teal-version <n>        TEAL version of the PQ logicsig (default: 12); another supported version
+                              (13) previews the addresses keys would get after a migration
+
+Arguments (recovery-address):
+  --primary <file>          key JSON of the primary key, which can sign at any time (required)
+  --backup <file>           key JSON of the backup key (required; public key sufficient for both)
+  --after-round <number>    the backup key can sign transactions whose first valid round is
+                              after this round (required)
+  --out <file>              write derived address (stdout if omitted)
+  --mnemonic-passphrase     optional mnemonic passphrase when the key files omit it
+
+Arguments (hybrid-address):
+  --key <file>              FALCON keypair JSON (required; public key sufficient)
+  --ed25519 <address>       Algorand address of the Ed25519 key that must also sign (required)
   --out <file>              write derived address (stdout if omitted)
   --mnemonic-passphrase     optional mnemonic passphrase when the key file omits it
+  Every transaction of the address needs a FALCON signature and an Ed25519 signature of
+  its TxID: funds stay safe if either scheme is broken, and need both keys to move.
+
+Arguments (delegate):
+  --key <file>              FALCON keypair JSON (required; public key sufficient)
+  --from-ed25519-mnemonic <words|->
+                            25-word Algorand mnemonic of the delegating account (required);
+                              - reads it from stdin, which keeps it out of the shell history
+  --out <file>              write the delegation JSON (stdout if omitted)
+  --mnemonic-passphrase     optional mnemonic passphrase when the key file omits it
+  The account signs the PQ logicsig of the key: from then on, the FALCON key alone can
+  authorize transactions of the account (send --delegation). The account keeps its address
+  and its Ed25519 key; only rekeying it revokes the delegation.
+
+Arguments (migrate):
+  --key <file>              FALCON keypair JSON of the PQ account to migrate to (required; public key
+                              sufficient without --sweep)
+  --from-ed25519 <words|-|kmd>
+                            the account to migrate (required): its 25-word Algorand mnemonic, - to read
+                              it from stdin, or kmd to have a KMD wallet sign for it
+  --kmd-wallet <name>       with kmd: wallet of the account (default: the only wallet)
+  --kmd-address <address>   with kmd: the account (default: the only account of the wallet)
+  --kmd-password-file <file> with kmd: read the wallet password from the first line of a file
+                              (default: empty password)
+  --kmd-url <string>        with kmd: KMD endpoint URL (default: $KMD_URL)
+  --kmd-token <string>      with kmd: KMD API token (default: $KMD_TOKEN; requires --kmd-url)
+  --rekey                   rekey the account to the PQ account: it keeps its address, assets and
+                              applications, and from then on only the FALCON key authorizes its
+                              transactions (send --from)
+  --confirm-rekey <address> repeat the PQ address to confirm --rekey non-interactively;
+                              otherwise it must be typed on stdin
+  --sweep                   move the assets and Algos to the PQ account: one atomic group per asset,
+                              in which the account pays what the PQ account lacks for its minimum
+                              balance, the PQ account opts in and the account sends its whole holding;
+                              then the account is closed into the PQ account, or, if it cannot be
+                              closed, sends all it has above its minimum balance
+  --confirm-sweep <address> repeat the PQ address to confirm --sweep non-interactively;
+                              otherwise it must be typed on stdin
+  --report <file>           write the plan, the confirmed transactions and the next steps as JSON
+  --network <name>          network: mainnet (default), testnet, betanet, devnet
+  --algod-url <string>      optional algod endpoint URL
+  --algod-token <string>    optional algod API token (requires --algod-url)
+  --mnemonic-passphrase     optional mnemonic passphrase when the key file omits it
+  Without --rekey or --sweep, prints the plan and sends nothing. Holdings that are frozen, of
+  assets the account created, or that the PQ account could not receive stay, as does the
+  minimum balance of an account that created assets or applications, is opted into
+  applications or is online. With --from-ed25519 -, confirm with the --confirm-* flag.
+  Exits 1, sending nothing, if the account is rekeyed already.
 
 Arguments (send):
   --key <file>              FALCON keypair JSON (required, must include private key)
-  --to <address>            destination Algorand address (required)
-  --amount <number>         amount to send in microAlgos (required)
-  --fee <number>            fee in microAlgos (default: minimum network transaction fee)
+  --to <address>            destination Algorand address (required without --template)
+  --amount <number>         amount to send in microAlgos (required without --template)
+  --template <name|file>    take --to, --amount, --note and --network from a send template
+                              (template-create): a file, or a name in --template-dir; those
+                              flags, when given, override the template for this send
+  --template-dir <dir>      directory of send templates (default: $FALCON_TEMPLATE_DIR, else
+                              falcon/templates in the user config dir)
+  --delegation <file>       send from the account that delegated to the key, as written by
+                              delegate, instead of from the PQ account of the key
+  --from <address>          send from this account, rekeyed to the account of the key (e.g. by
+                              migrate --rekey), instead of from that account; not with --delegation
+  --ed25519-mnemonic <words|->
+                            send from the hybrid account (hybrid-address) of the key and the Ed25519
+                              key of this 25-word mnemonic, signing with both; - reads it from stdin
+  --from-lsig-file <file>   send from the account of this compiled logicsig (raw bytes, as written by
+                              goal clerk compile, or base64) instead of the derived PQ logicsig; it must
+                              embed the key and take the FALCON signature of the TxID as arg 0
+  --fee <number>            fee in microAlgos (default: minimum network transaction fee);
+                              at least the network's minimum fee, checked before signing: the
+                              padding adds the minimum fee of its 3 dummy transactions
+  --fee-strategy <name>     propose the fee of the whole group and print the breakdown:
+                              min (minimum fee per transaction), suggested (node's fee per byte),
+                              priority (a multiple of suggested, larger when blocks are full)
   --note <string>           optional transaction note
   --network <name>          network: mainnet (default), testnet, betanet, devnet
   --algod-url <string>      optional algod endpoint URL
   --algod-token <string>    optional algod API token (requires --algod-url)
+  --pre-hook <program>      run before sending with the operation as JSON on stdin; non-zero exit aborts
+  --post-hook <program>     run after confirmation with the operation and txid as JSON on stdin
+  --rekey-to <address>      also rekey the sending account to this address in the same transaction
+                              (DANGEROUS: the FALCON key loses control of the account); --amount may be 0
+  --confirm-rekey <address> repeat the --rekey-to address to confirm non-interactively;
+                              otherwise the address must be typed on stdin
+  --explorer <value>        explorer links printed after sending: allo (default), pera, none, or a URL
+                              template with {txid} [and a second one with {group}]; may use {network}
+                              (default: $FALCON_EXPLORER)
+  --json                    print txid, group and explorer links as JSON
+  --pending-dir <dir>       where the signed transaction is recorded until it is confirmed
+                              (default: $FALCON_PENDING_DIR, else falcon/pending in the user config dir)
+  --receipts-dir <dir>      archive the signed group as broadcast (<txid>.msgpack), a receipt with its
+                              round and fees (<txid>.json) and a row of index.csv here once confirmed
+                              (default: $FALCON_RECEIPTS_DIR; not archived if neither is set)
+  --offline                 build and sign the group without network access, from --suggested-params,
+                              and write it to --out for submit instead of sending it; the pre-hook
+                              runs, the post-hook does not
+  --suggested-params <file> with --offline: snapshot written by suggested-params; sets the network
+  --out <file>              with --offline: where to write the signed group
+  --mnemonic-passphrase     optional mnemonic passphrase when the key file omits it
+
+Arguments (suggested-params):
+  --out <file>              write the snapshot JSON (stdout if omitted)
+  --network <name>          network: mainnet (default), testnet, betanet, devnet
+  --algod-url <string>      optional algod endpoint URL
+  --algod-token <string>    optional algod API token (requires --algod-url)
+  The snapshot holds the genesis, fees and validity window of transactions built now: a group
+  built from it with send --offline can be submitted until the network passes its last round.
+
+Arguments (submit):
+  --in <file>               signed group written by send --offline (required)
+  --network <name>          network (default: from the file; must match it)
+  --algod-url <string>      optional algod endpoint URL
+  --algod-token <string>    optional algod API token (requires --algod-url)
+  --explorer <value>        explorer links printed after sending, as for send
+  --json                    print txid, group and explorer links as JSON
+  --pending-dir <dir>       where the transaction is recorded until it is confirmed, as for send
+  Checks the group against the node first: exits 1, sending nothing, if it is for another
+  network or its validity window has passed.
+
+Arguments (template-create):
+  --name <name>             name of the template (letters, digits, '.', '_', '-'), saved as
+                              <name>.json in the template directory (required)
+  --to <address>            destination Algorand address (required)
+  --amount <number>         amount in microAlgos (required)
+  --note <pattern>          note; {date}, {month} and {year} are replaced by the UTC date of
+                              each send (e.g. "rent {month}")
+  --network <name>          network of the sends (default: that of send, mainnet)
+  --template-dir <dir>      directory of send templates (default: $FALCON_TEMPLATE_DIR, else
+                              falcon/templates in the user config dir)
+  --force                   replace an existing template of the same name
+
+Arguments (template-list):
+  --json                    print the templates as JSON
+  --template-dir <dir>      directory of send templates
+
+Arguments (claim):
+  --key <file>              FALCON keypair JSON (required, must include private key)
+  --asset-id <number>       asset to claim from the ARC-59 inbox (required)
+  --router-app-id <number>  ARC-59 router app (default: known router on mainnet/testnet)
+  --network <name>          network: mainnet (default), testnet, betanet, devnet
+  --algod-url <string>      optional algod endpoint URL
+  --algod-token <string>    optional algod API token (requires --algod-url)
+  --mnemonic-passphrase     optional mnemonic passphrase when the key file omits it
+
+Arguments (opt-in):
+  --key <file>              FALCON keypair JSON (required, must include private key)
+  --asset-id <number>       asset to opt into (required)
+  --sponsor <file>          FALCON keypair JSON of another PQ account that pays the fees and sends
+                              what --key's account lacks for its minimum balance after the opt-in
+                              (must include private key); without it, --key's account pays
+  --network <name>          network: mainnet (default), testnet, betanet, devnet
+  --algod-url <string>      optional algod endpoint URL
+  --algod-token <string>    optional algod API token (requires --algod-url)
+  --mnemonic-passphrase     optional mnemonic passphrase when the key files omit it
+
+Arguments (asset-config):
+  --key <file>              FALCON keypair JSON of the asset manager (required, must include private key)
+  --asset-id <number>       asset to reconfigure (required)
+  --manager <address|none>  new manager; none removes the role (at least one role flag is required)
+  --reserve <address|none>  new reserve address, or none
+  --freeze <address|none>   new freeze address, or none
+  --clawback <address|none> new clawback address, or none
+  --confirm-remove <roles>  comma-separated roles whose removal to confirm non-interactively;
+                              otherwise the removed roles must be typed on stdin
+  --network <name>          network: mainnet (default), testnet, betanet, devnet
+  --algod-url <string>      optional algod endpoint URL
+  --algod-token <string>    optional algod API token (requires --algod-url)
+  --mnemonic-passphrase     optional mnemonic passphrase when the key file omits it
+  Prints the roles changed, current -> requested; roles not given are kept. A removed role
+  can never be set again, and an asset without a manager can never be changed or destroyed.
+  Exits 1, sending nothing, if a preflight check refuses the change.
+
+Arguments (asset-destroy):
+  --key <file>              FALCON keypair JSON of the asset manager (required, must include private key)
+  --asset-id <number>       asset to destroy (required)
+  --confirm-destroy <number> repeat the --asset-id to confirm non-interactively;
+                              otherwise it must be typed on stdin
+  --network <name>          network: mainnet (default), testnet, betanet, devnet
+  --algod-url <string>      optional algod endpoint URL
+  --algod-token <string>    optional algod API token (requires --algod-url)
   --mnemonic-passphrase     optional mnemonic passphrase when the key file omits it
+  Checks first that the PQ account is the manager and that the creator holds the whole
+  supply; exits 1, sending nothing, otherwise.
+
+Arguments (heartbeat):
+  --key <file>              FALCON keypair JSON of the PQ account (public key sufficient without --txn)
+  --address <address>       account to report on instead of --key
+  --txn <file>              heartbeat transaction (msgpack) prepared by the participation node of the
+                              account; the PQ account of --key becomes its sender, signs it and pays
+                              its fee (--key must include private key)
+  --network <name>          network: mainnet (default), testnet, betanet, devnet
+  --algod-url <string>      optional algod endpoint URL
+  --algod-token <string>    optional algod API token (requires --algod-url)
+  --mnemonic-passphrase     optional mnemonic passphrase when the key file omits it
+  Prints whether the account is online and incentive-eligible, its last heartbeat and
+  proposal, the rounds it may go unseen before it is suspended, and when to send the
+  next heartbeat.
+
+Arguments (publish-key):
+  --key <file>              FALCON keypair JSON (required, must include private key)
+  --network <name>          network: mainnet (default), testnet, betanet, devnet
+  --algod-url <string>      optional algod endpoint URL
+  --algod-token <string>    optional algod API token (requires --algod-url)
+  --mnemonic-passphrase     optional mnemonic passphrase when the key file omits it
+  The key is split across the notes of a group of 0-Algo payments of the PQ account to
+  itself, each committing to the SHA-256 of the whole key; the account pays the fees.
+
+Arguments (fetch-key):
+  --address <address>       PQ address whose published key to fetch (required)
+  --out <file>              write the public key JSON (stdout if omitted)
+  --network <name>          network: mainnet (default), testnet, betanet, devnet
+  --indexer-url <string>    optional indexer endpoint URL
+  --indexer-token <string>  optional indexer API token (requires --indexer-url)
+  The key is accepted only if it matches its committed hash and derives the address.
+  Exits 1 if no such key was published.
+
+Arguments (status):
+  --txid <id>               transaction to check; resumes from its pending record, if any
+  --pending                 check every pending record left by send
+  --wait <rounds>           rounds to wait for a pending transaction (default: 9; 0 checks once)
+  --no-rebroadcast          do not broadcast a recorded transaction again when the node has not seen it
+  --pending-dir <dir>       directory of pending records (default: as for send)
+  --network <name>          network (default: from the record, else mainnet)
+  --algod-url <string>      algod endpoint URL (default: from the record)
+  --algod-token <string>    optional algod API token (requires --algod-url)
+  Prints one line per transaction; exits 0 if all are confirmed and 1 otherwise.
+
+Arguments (receipts list):
+  --receipts-dir <dir>      directory of receipts (default: $FALCON_RECEIPTS_DIR)
+  --json                    print the rows of index.csv as JSON
+
+Arguments (receipts show):
+  --txid <id>               transaction whose receipt to show (required)
+  --receipts-dir <dir>      directory of receipts (default: $FALCON_RECEIPTS_DIR)
+  --json                    print the receipt as JSON
+  Checks the archived signed group against the digest and txid of the receipt; exits 1 if
+  it does not match.
+
+Arguments (app-read):
+  --app-id <number>         application to read (required)
+  --global                  read its global state
+  --local <address>         read the local state of an account
+  --key <file>              read the local state of the PQ account of a key file (public key sufficient)
+  --box <name>              read a box; the name is str:<text>, b64:<base64>, int:<number> or addr:<address>
+  --boxes                   list the box names
+  --msgpack                 also decode byte values as msgpack where possible
+  --network <name>          network: mainnet (default), testnet, betanet, devnet
+  --algod-url <string>      optional algod endpoint URL
+  --algod-token <string>    optional algod API token (requires --algod-url)
+  --mnemonic-passphrase     optional mnemonic passphrase when the key file omits it
+  Keys, box names and byte values are printed in base64, and also as text, as an
+  address (32 bytes) or as decoded msgpack when they read as such.
+
+Arguments (app-call):
+  --key <file>              FALCON keypair JSON (required, must include private key)
+  --app-id <number>         application to call (required)
+  --method <signature>      ARC-4 method signature, e.g. "transfer(address,uint64)void" (required)
+  --args <value>            value of the next method argument, repeated once per argument in
+                              order: its ARC-4 JSON encoding (100, true, "text", [1,2], a base64
+                              string for byte arrays), with strings and addresses also unquoted;
+                              an address for account and a number for asset and application
+                              arguments, which are added to the foreign arrays of the call
+  --inner-txns <number>     inner transactions the method issues; the call pays their fees
+  --json                    print the transaction ID and the return value as JSON
+  --network <name>          network: mainnet (default), testnet, betanet, devnet
+  --algod-url <string>      optional algod endpoint URL
+  --algod-token <string>    optional algod API token (requires --algod-url)
+  --mnemonic-passphrase     optional mnemonic passphrase when the key file omits it
+  The return value of non-void methods is decoded from the log of the confirmed call and
+  printed in the ARC-4 JSON encoding. Transaction arguments are not supported.
+
+Arguments (nft-mint):
+  --key <file>              FALCON keypair JSON of the creator (required, must include private key)
+  --standard <arc3|arc69>   metadata standard (required)
+  --metadata <file>         metadata JSON (required), checked against the schema of the standard
+  --unit-name <string>      unit name of the asset (up to 8 bytes)
+  --asset-name <string>     asset name (up to 32 bytes; default for arc3: the name in the metadata)
+  --url <string>            arc3: URL serving the metadata file as is (#arc3 is appended if
+                              missing); arc69: URL of the media
+  --ipfs-uploader <program> arc3: pin the metadata with this program instead of giving --url; it
+                              receives the file on stdin and prints its CID (env FALCON_IPFS_UPLOADER)
+  --decimals <n>            fractional NFT of 10^n units (default: 0, a single unit)
+  --json                    print the transaction ID, asset ID, URL and metadata hash as JSON
+  --network <name>          network: mainnet (default), testnet, betanet, devnet
+  --algod-url <string>      optional algod endpoint URL
+  --algod-token <string>    optional algod API token (requires --algod-url)
+  --mnemonic-passphrase     optional mnemonic passphrase when the key file omits it
+  The creator holds all units and is the manager; there is no reserve, freeze or clawback
+  address. ARC-3 NFTs get the metadata hash; ARC-69 metadata goes in the note.
+
+Arguments (watch-export):
+  --key <file>              keypair/public key JSON of the PQ account (public key sufficient)
+  --address <address>       export this address instead of --key
+  --label <string>          name of the account in the wallet
+  --format <name>           text: the URI and a QR code drawn in the terminal (default);
+                              uri: the ARC-26 URI; png: a QR code image (requires --out, and is
+                              the default for a .png --out); json: address, label and URI
+  --out <file>              write the export to file (stdout if omitted)
+  --invert                  text: draw the QR code for a light terminal background
+  --mnemonic-passphrase     optional mnemonic passphrase when the key file omits it
+  The QR code encodes algorand://<address>?label=<label> (ARC-26), which mobile wallets such
+  as Pera and Defly scan to add the account as a watch account. Needs no network.
+
+Arguments (scan-blocks):
+  --round <n>               scan the block of this round
+  --first <n> --last <n>    scan the blocks of this range of rounds (inclusive)
+  --anomalies-only          list only the PQ transactions with an anomaly
+  --json                    print the rounds, number of transactions and PQ transactions as JSON
+  --network <name>          network: mainnet (default), testnet, betanet, devnet
+  --algod-url <string>      optional algod endpoint URL (archival for old rounds)
+  --algod-token <string>    optional algod API token (requires --algod-url)
+  Lists the transactions authorized by PQ logicsigs and other programs with embedded FALCON
+  keys, and checks offline that arg 0 is a FALCON signature of the TxID by one of the keys.
+  Exits 1 if a transaction has no signature, an invalid one, or a program that is not that
+  of its account.
+
+Arguments (verify-templates):
+  --expect <sha256>         SHA-256 of the manifest published for the release; exits 1 if the
+                              manifest of this binary differs
+  --json                    print the checks and the manifest SHA-256 as JSON
+  Recomputes the SHA-256 of every logicsig template and precompiled program the binary
+  derives accounts with and compares them with its embedded manifest; exits 1 on any
+  mismatch. Needs no network.
 `
@@ -1,12 +1,22 @@
 package cli
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
 	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/algorandfoundation/falcon-signatures/algorand"
 	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/mnemonic"
 )
 
 // Test that setting --algod-token without --algod-url results in an error.
@@ -129,3 +139,548 @@ func TestRunAlgorandSend_ClearAlgodURLResetsEnv(t *testing.T) {
 		t.Fatalf("expected ALGOD_TOKEN to be cleared, got %q", got)
 	}
 }
+
+// Test that claim requires --asset-id.
+func TestRunAlgorandClaim_AssetIDRequired(t *testing.T) {
+	var code int
+	_, stderr := captureStdoutStderr(t, func() {
+		code = runAlgorandClaim([]string{"--key", "dummy.json"})
+	})
+	if code != 2 {
+		t.Fatalf("expected exit code 2, got %d", code)
+	}
+	if !strings.Contains(stderr, "--asset-id is required") {
+		t.Fatalf("expected error about --asset-id, got %q", stderr)
+	}
+}
+
+// Test that claim requires --router-app-id on networks without a known router.
+func TestRunAlgorandClaim_RouterRequiredOnDevNet(t *testing.T) {
+	var code int
+	_, stderr := captureStdoutStderr(t, func() {
+		code = runAlgorandClaim([]string{
+			"--key", "dummy.json",
+			"--asset-id", "1",
+			"--network", "devnet",
+		})
+	})
+	if code != 2 {
+		t.Fatalf("expected exit code 2, got %d", code)
+	}
+	if !strings.Contains(stderr, "--router-app-id is required") {
+		t.Fatalf("expected error about --router-app-id, got %q", stderr)
+	}
+}
+
+// TestRunAlgorandSend_RekeyToConfirmation checks the rekey warning and that
+// nothing is sent unless the new address is repeated.
+func TestRunAlgorandSend_RekeyToConfirmation(t *testing.T) {
+	t.Setenv("ALGOD_URL", "")
+	t.Setenv("ALGOD_TOKEN", "")
+
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("rekey-to test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, t.TempDir(), "keys.json", kp, true)
+	var to types.Address
+	newAuth := types.Address{1}
+	args := []string{"--key", keyPath, "--to", to.String(), "--amount", "0",
+		"--network", "devnet", "--rekey-to", newAuth.String()}
+
+	// Wrong address typed: exit 1 before anything is built or sent.
+	var code int
+	var stderr string
+	withStdin(t, to.String()+"\n", func() {
+		_, stderr = captureStdoutStderr(t, func() { code = runAlgorandSend(args) })
+	})
+	if code != 1 {
+		t.Fatalf("expected exit 1, got %d (%s)", code, stderr)
+	}
+	if !strings.Contains(stderr, "WARNING: this transaction rekeys") ||
+		!strings.Contains(stderr, "nothing sent") || strings.Contains(stderr, "send failed") {
+		t.Fatalf("unexpected stderr: %q", stderr)
+	}
+
+	// Typed correctly: proceeds to sending (which fails for lack of a devnet node).
+	withStdin(t, newAuth.String()+"\n", func() {
+		_, stderr = captureStdoutStderr(t, func() { code = runAlgorandSend(args) })
+	})
+	if code != 2 || !strings.Contains(stderr, "send failed: ALGOD_URL not set for DevNet") {
+		t.Fatalf("expected send attempt, got code %d: %q", code, stderr)
+	}
+
+	// Non-interactive confirmation must match.
+	_, stderr = captureStdoutStderr(t, func() {
+		code = runAlgorandSend(append(args, "--confirm-rekey", to.String()))
+	})
+	if code != 2 || !strings.Contains(stderr, "--confirm-rekey does not match") {
+		t.Fatalf("expected mismatch error, got code %d: %q", code, stderr)
+	}
+	_, stderr = captureStdoutStderr(t, func() {
+		code = runAlgorandSend(append(args, "--confirm-rekey", newAuth.String()))
+	})
+	if code != 2 || !strings.Contains(stderr, "send failed: ALGOD_URL not set for DevNet") {
+		t.Fatalf("expected send attempt, got code %d: %q", code, stderr)
+	}
+}
+
+// TestRunAlgorandSend_RekeyToValidation checks rekey flag validation.
+func TestRunAlgorandSend_RekeyToValidation(t *testing.T) {
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--rekey-to", "BADADDRESS"}, "invalid --rekey-to"},
+		{[]string{"--amount", "1", "--confirm-rekey", "X"}, "--confirm-rekey requires --rekey-to"},
+		{[]string{"--amount", "0"}, "--amount is required"},
+		{[]string{"--amount", "1", "--fee", "1000", "--fee-strategy", "min"}, "mutually exclusive"},
+		{[]string{"--amount", "1", "--fee-strategy", "fast"}, "invalid --fee-strategy"},
+	}
+	for _, c := range cases {
+		args := append([]string{"--key", "dummy.json", "--to", "ALGOADDRESS"}, c.args...)
+		var code int
+		_, stderr := captureStdoutStderr(t, func() { code = runAlgorandSend(args) })
+		if code != 2 || !strings.Contains(stderr, c.want) {
+			t.Fatalf("%v: expected %q with exit 2, got code %d: %q", c.args, c.want, code, stderr)
+		}
+	}
+}
+
+// TestRunAlgorandSend_FeeBelowGroupMinimum checks that a --fee too low for the
+// padded group is refused before sending, with the fee to use.
+func TestRunAlgorandSend_FeeBelowGroupMinimum(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/transactions/params" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(models.TransactionParametersResponse{
+			MinFee: 1000, GenesisHash: make([]byte, 32), LastRound: 10})
+	}))
+	defer srv.Close()
+	t.Setenv("ALGOD_URL", "")
+
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("fee below minimum test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, t.TempDir(), "keys.json", kp, true)
+	var addr types.Address
+	var code int
+	_, stderr := captureStdoutStderr(t, func() {
+		code = runAlgorandSend([]string{"--key", keyPath, "--to", addr.String(), "--amount", "1",
+			"--fee", "500", "--network", "devnet", "--algod-url", srv.URL})
+	})
+	if code != 2 || !strings.Contains(stderr, "invalid --fee: fee too low: the group of 4 transactions") ||
+		!strings.Contains(stderr, "use --fee 1000 or more") {
+		t.Fatalf("expected a fee error with exit 2, got code %d: %q", code, stderr)
+	}
+}
+
+// TestPrintSendResult checks the text and JSON output of a confirmed send.
+func TestPrintSendResult(t *testing.T) {
+	group := types.Digest{1, 2, 3}
+	links, err := algorand.ExplorerURLs(algorand.ExplorerAllo, algorand.TestNet, "TXID", group)
+	if err != nil {
+		t.Fatalf("ExplorerURLs failed: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := printSendResult(&buf, "TXID", group, links, false); err != nil {
+		t.Fatalf("printSendResult failed: %v", err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "Transaction confirmed with id: TXID\n") ||
+		!strings.Contains(out, "Transaction: https://testnet.allo.info/tx/TXID\n") ||
+		!strings.Contains(out, "Group: https://testnet.allo.info/tx/group/") {
+		t.Fatalf("unexpected text output: %q", out)
+	}
+
+	buf.Reset()
+	if err := printSendResult(&buf, "TXID", group, links, true); err != nil {
+		t.Fatalf("printSendResult failed: %v", err)
+	}
+	var res sendResultJSON
+	if err := json.Unmarshal(buf.Bytes(), &res); err != nil {
+		t.Fatalf("invalid JSON output %q: %v", buf.String(), err)
+	}
+	if res.TxID != "TXID" || res.Group != base64.StdEncoding.EncodeToString(group[:]) ||
+		res.Explorer == nil || *res.Explorer != links {
+		t.Fatalf("unexpected JSON output: %+v", res)
+	}
+
+	buf.Reset()
+	if err := printSendResult(&buf, "TXID", group, algorand.ExplorerLinks{}, true); err != nil {
+		t.Fatalf("printSendResult failed: %v", err)
+	}
+	if strings.Contains(buf.String(), "explorer") {
+		t.Fatalf("expected no explorer links, got %q", buf.String())
+	}
+}
+
+// TestFormatFeeBreakdown checks the fee report of --fee-strategy.
+func TestFormatFeeBreakdown(t *testing.T) {
+	got := formatFeeBreakdown(algorand.FeeBreakdown{
+		Strategy: algorand.FeePriority, MinFee: 1000, FeePerByte: 10, Fullness: 0.9,
+		DummyTxns: 2, PQTxnFee: 80000, DummyFee: 20000, Total: 120000,
+	})
+	for _, want := range []string{
+		"Fee (priority): 120000 microAlgos for 3 transactions",
+		"payment:  80000", "dummies:  2 x 20000",
+		"minimum fee 1000, 10 microAlgos per byte, recent blocks 90% full",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("breakdown missing %q:\n%s", want, got)
+		}
+	}
+}
+
+// TestResolveExplorer checks flag, environment and default precedence, and
+// that invalid values are rejected before anything is sent.
+func TestResolveExplorer(t *testing.T) {
+	t.Setenv(envExplorer, "")
+	if got := resolveExplorer("", false); got != algorand.ExplorerAllo {
+		t.Fatalf("expected default %q, got %q", algorand.ExplorerAllo, got)
+	}
+	t.Setenv(envExplorer, "pera")
+	if got := resolveExplorer("", false); got != "pera" {
+		t.Fatalf("expected env value, got %q", got)
+	}
+	if got := resolveExplorer("none", true); got != "none" {
+		t.Fatalf("expected flag value, got %q", got)
+	}
+
+	var code int
+	_, stderr := captureStdoutStderr(t, func() {
+		code = runAlgorandSend([]string{"--key", "dummy.json", "--to", "ALGOADDRESS",
+			"--amount", "1", "--explorer", "etherscan"})
+	})
+	if code != 2 || !strings.Contains(stderr, "invalid --explorer") {
+		t.Fatalf("expected invalid --explorer error, got code %d: %q", code, stderr)
+	}
+}
+
+// TestRunAlgorandAddress_Keys exports a table for a directory of keys and
+// checks it, in both formats, and reports drift against an edited table.
+func TestRunAlgorandAddress_Keys(t *testing.T) {
+	dir := t.TempDir()
+	keysDir := filepath.Join(dir, "keys")
+	if err := os.Mkdir(keysDir, 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	var addresses []string
+	for _, name := range []string{"a.json", "b.json"} {
+		kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("address table " + name)))
+		if err != nil {
+			t.Fatalf("GenerateKeyPair failed: %v", err)
+		}
+		writeKeypairJSON(t, keysDir, name, kp, false)
+		address, err := algorand.GetAddressFromPublicKey(kp.PublicKey)
+		if err != nil {
+			t.Fatalf("GetAddressFromPublicKey failed: %v", err)
+		}
+		addresses = append(addresses, string(address))
+	}
+
+	var code int
+	out := captureStdout(t, func() { code = runAlgorandAddress([]string{"--keys", keysDir}) })
+	if code != 0 {
+		t.Fatalf("expected exit 0, got %d", code)
+	}
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 || lines[0] != "file,fingerprint,address,counter" ||
+		!strings.Contains(lines[1], addresses[0]) || !strings.Contains(lines[2], addresses[1]) {
+		t.Fatalf("unexpected CSV table: %q", out)
+	}
+
+	jsonTable := filepath.Join(dir, "table.json")
+	if code := runAlgorandAddress([]string{"--format", "json", "--out", jsonTable, "--keys",
+		filepath.Join(keysDir, "a.json"), filepath.Join(keysDir, "b.json")}); code != 0 {
+		t.Fatalf("expected exit 0 writing JSON table, got %d", code)
+	}
+	var rows []addressRow
+	readJSONFile(t, jsonTable, &rows)
+	if len(rows) != 2 || rows[0].Address != addresses[0] || rows[0].Fingerprint == "" {
+		t.Fatalf("unexpected JSON table: %+v", rows)
+	}
+
+	csvTable := filepath.Join(dir, "table.csv")
+	if err := os.WriteFile(csvTable, []byte(out), 0o644); err != nil {
+		t.Fatalf("write table: %v", err)
+	}
+	for _, table := range []string{csvTable, jsonTable} {
+		out := captureStdout(t, func() {
+			code = runAlgorandAddress([]string{"--check", table, "--keys", keysDir})
+		})
+		if code != 0 || out != "" {
+			t.Fatalf("check %s: expected exit 0 and no output, got %d %q", table, code, out)
+		}
+	}
+
+	rows[1].Counter++
+	rows = rows[:1:1]
+	rows[0].Address = addresses[1]
+	data, _ := json.Marshal(rows)
+	if err := os.WriteFile(jsonTable, data, 0o644); err != nil {
+		t.Fatalf("write table: %v", err)
+	}
+	out = captureStdout(t, func() {
+		code = runAlgorandAddress([]string{"--check", jsonTable, "--keys", keysDir})
+	})
+	if code != 1 || !strings.Contains(out, "a.json: derived "+addresses[0]) ||
+		!strings.Contains(out, "b.json: key ") {
+		t.Fatalf("expected drift report, got %d %q", code, out)
+	}
+}
+
+// TestRunAlgorandAddress_KeysErrors covers flag validation and per-key failures.
+func TestRunAlgorandAddress_KeysErrors(t *testing.T) {
+	dir := t.TempDir()
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("address table errors")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	good := writeKeypairJSON(t, dir, "good.json", kp, false)
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`{"public_key":"00"}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"--key", good, "--keys", good},
+		{"--keys", good, "--format", "xml"},
+		{"--check", "t.csv", "--out", "x", "--keys", good},
+		{"--key", good, "--check", "t.csv"},
+		{"--keys", t.TempDir()},
+	} {
+		var code int
+		stderr := captureStderr(t, func() { code = runAlgorandAddress(args) })
+		if code != 2 || stderr == "" {
+			t.Fatalf("%v: expected exit 2 with stderr, got %d %q", args, code, stderr)
+		}
+	}
+
+	var code int
+	out, stderr := captureStdoutStderr(t, func() {
+		code = runAlgorandAddress([]string{"--keys", dir})
+	})
+	if code != 2 || !strings.Contains(stderr, "bad.json: valid public key not found") ||
+		!strings.Contains(stderr, "1 of 2 keys failed") || !strings.Contains(out, "good.json") {
+		t.Fatalf("expected partial table and failure, got %d %q %q", code, out, stderr)
+	}
+}
+
+// TestRunAlgorandOptIn_Validation checks the flags of opt-in before anything
+// is sent.
+func TestRunAlgorandOptIn_Validation(t *testing.T) {
+	dir := t.TempDir()
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("opt-in test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+	pubPath := writeKeypairJSON(t, dir, "pub.json", kp, false)
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--asset-id", "1"}, "--key is required"},
+		{[]string{"--key", keyPath}, "--asset-id is required"},
+		{[]string{"--key", keyPath, "--asset-id", "1", "--sponsor", pubPath}, "private key not found"},
+		{[]string{"--key", keyPath, "--asset-id", "1", "--sponsor", keyPath}, "--sponsor must be a different key"},
+	} {
+		var code int
+		_, stderr := captureStdoutStderr(t, func() { code = runAlgorandOptIn(tc.args) })
+		if code != 2 || !strings.Contains(stderr, tc.want) {
+			t.Fatalf("%q: got exit %d, stderr %q; want %q", tc.args, code, stderr, tc.want)
+		}
+	}
+}
+
+// TestRunAlgorandAddress_TealVersion previews the address of a key under the
+// next TEAL version, and rejects unsupported versions.
+func TestRunAlgorandAddress_TealVersion(t *testing.T) {
+	dir := t.TempDir()
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("teal version")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, dir, "key.json", kp, false)
+	current, err := algorand.GetAddressFromPublicKey(kp.PublicKey)
+	if err != nil {
+		t.Fatalf("GetAddressFromPublicKey failed: %v", err)
+	}
+	next, _, err := algorand.DerivePQAddressWithOptions(kp.PublicKey, algorand.DeriveOptions{TealVersion: 13})
+	if err != nil {
+		t.Fatalf("DerivePQAddressWithOptions failed: %v", err)
+	}
+
+	var code int
+	out, stderr := captureStdoutStderr(t, func() {
+		code = runAlgorandAddress([]string{"--key", keyPath, "--teal-version", "12"})
+	})
+	if code != 0 || strings.TrimSpace(out) != string(current) || stderr != "" {
+		t.Fatalf("--teal-version 12: exit %d, %q, %q", code, out, stderr)
+	}
+	out, stderr = captureStdoutStderr(t, func() {
+		code = runAlgorandAddress([]string{"--key", keyPath, "--teal-version", "13"})
+	})
+	if code != 0 || strings.TrimSpace(out) != next.String() || !strings.Contains(stderr, "previewing TEAL version 13") {
+		t.Fatalf("--teal-version 13: exit %d, %q, %q", code, out, stderr)
+	}
+	out = captureStdout(t, func() {
+		code = runAlgorandAddress([]string{"--keys", keyPath, "--teal-version", "13"})
+	})
+	if code != 0 || !strings.Contains(out, next.String()) {
+		t.Fatalf("--keys --teal-version 13: exit %d, %q", code, out)
+	}
+	for _, v := range []string{"11", "300"} {
+		_, stderr = captureStdoutStderr(t, func() {
+			code = runAlgorandAddress([]string{"--key", keyPath, "--teal-version", v})
+		})
+		if code != 2 || !strings.Contains(stderr, "invalid --teal-version") {
+			t.Fatalf("--teal-version %s: exit %d, %q", v, code, stderr)
+		}
+	}
+}
+
+// TestRunAlgorandAddress_FromMnemonic derives the address and fingerprint of a
+// mnemonic without a key file, from the flag or stdin.
+func TestRunAlgorandAddress_FromMnemonic(t *testing.T) {
+	wordStr := "legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth title"
+	seed, err := mnemonic.SeedFromMnemonic(strings.Fields(wordStr), "TREZOR")
+	if err != nil {
+		t.Fatalf("SeedFromMnemonic failed: %v", err)
+	}
+	kp, err := falcongo.GenerateKeyPair(seed[:])
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	addr, err := algorand.GetAddressFromPublicKey(kp.PublicKey)
+	if err != nil {
+		t.Fatalf("GetAddressFromPublicKey failed: %v", err)
+	}
+	fp := falcongo.Fingerprint(kp.PublicKey)
+	want := "address: " + string(addr) + "\nfingerprint: " + hex.EncodeToString(fp[:]) +
+		"\npetname: " + mnemonic.Petname(fp) + "\n"
+
+	var code int
+	out, stderr := captureStdoutStderr(t, func() {
+		code = runAlgorandAddress([]string{"--from-mnemonic", wordStr, "--mnemonic-passphrase", "TREZOR"})
+	})
+	if code != 0 || out != want || stderr != "" {
+		t.Fatalf("--from-mnemonic: exit %d, %q, %q", code, out, stderr)
+	}
+	withStdin(t, wordStr+"\n", func() {
+		out = captureStdout(t, func() {
+			code = runAlgorandAddress([]string{"--from-mnemonic", "-", "--mnemonic-passphrase", "TREZOR"})
+		})
+	})
+	if code != 0 || out != want {
+		t.Fatalf("--from-mnemonic -: exit %d, %q", code, out)
+	}
+	out = captureStdout(t, func() {
+		code = runAlgorandAddress([]string{"--from-mnemonic", wordStr})
+	})
+	if code != 0 || strings.Contains(out, string(addr)) {
+		t.Fatalf("--from-mnemonic without the passphrase: exit %d, %q", code, out)
+	}
+
+	outPath := filepath.Join(t.TempDir(), "address.txt")
+	out = captureStdout(t, func() {
+		code = runAlgorandAddress([]string{"--from-mnemonic", wordStr, "--mnemonic-passphrase", "TREZOR", "--out", outPath})
+	})
+	got, err := os.ReadFile(outPath)
+	if code != 0 || err != nil || string(got) != string(addr) || out != strings.TrimPrefix(want, "address: "+string(addr)+"\n") {
+		t.Fatalf("--from-mnemonic --out: exit %d, %q, %q, %v", code, out, got, err)
+	}
+
+	for _, args := range [][]string{
+		{"--from-mnemonic", "legal winner thank"},
+		{"--from-mnemonic", strings.Replace(wordStr, "title", "zoo", 1)},
+		{"--from-mnemonic", wordStr, "--key", outPath},
+		{"--from-mnemonic", wordStr, "--format", "json"},
+	} {
+		_, stderr = captureStdoutStderr(t, func() { code = runAlgorandAddress(args) })
+		if code != 2 || stderr == "" {
+			t.Fatalf("%q: exit %d, %q", args, code, stderr)
+		}
+	}
+}
+
+// TestRunAlgorandSend_FromLsigFile refuses a program that does not embed the
+// signing key, raw or in base64, before contacting algod.
+func TestRunAlgorandSend_FromLsigFile(t *testing.T) {
+	dir := t.TempDir()
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("lsig file test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	other, err := falcongo.GenerateKeyPair(deriveSeed([]byte("another lsig file test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+	lsig, err := algorand.DerivePQLogicSig(other.PublicKey)
+	if err != nil {
+		t.Fatalf("DerivePQLogicSig failed: %v", err)
+	}
+	raw := filepath.Join(dir, "other.tok")
+	b64 := filepath.Join(dir, "other.b64")
+	if err := os.WriteFile(raw, lsig.Lsig.Logic, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(b64, []byte(base64.StdEncoding.EncodeToString(lsig.Lsig.Logic)+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{raw, b64} {
+		var code int
+		_, stderr := captureStdoutStderr(t, func() {
+			code = runAlgorandSend([]string{"--key", keyPath, "--to", types.ZeroAddress.String(), "--amount", "1",
+				"--network", "devnet", "--algod-url", "http://127.0.0.1:1", "--from-lsig-file", path})
+		})
+		if code != 2 || !strings.Contains(stderr, "does not embed the FALCON public key") {
+			t.Fatalf("%s: expected a key mismatch, got %d: %s", path, code, stderr)
+		}
+	}
+	program, err := readProgramFile(b64)
+	if err != nil || !bytes.Equal(program, lsig.Lsig.Logic) {
+		t.Fatalf("readProgramFile of base64: %v", err)
+	}
+}
+
+func TestRunAlgorandVerifyTemplates(t *testing.T) {
+	var code int
+	stdout, stderr := captureStdoutStderr(t, func() { code = runAlgorandVerifyTemplates(nil) })
+	if code != 0 || strings.Contains(stdout, "MISMATCH") || !strings.Contains(stdout, "ok ") {
+		t.Fatalf("verify-templates: exit %d\n%s%s", code, stdout, stderr)
+	}
+	manifest := algorand.TemplateManifestSHA256()
+	if !strings.Contains(stdout, "manifest sha256: "+manifest) {
+		t.Fatalf("manifest SHA-256 not printed:\n%s", stdout)
+	}
+
+	stdout, _ = captureStdoutStderr(t, func() {
+		code = runAlgorandVerifyTemplates([]string{"--expect", strings.ToUpper(manifest), "--json"})
+	})
+	var out verifyTemplatesJSON
+	if err := json.Unmarshal([]byte(stdout), &out); err != nil || code != 0 {
+		t.Fatalf("--json: exit %d, %v\n%s", code, err, stdout)
+	}
+	if out.ExpectedOK == nil || !*out.ExpectedOK || len(out.Templates) == 0 || !out.Templates[0].OK {
+		t.Fatalf("--json: unexpected output %s", stdout)
+	}
+
+	_, stderr = captureStdoutStderr(t, func() {
+		code = runAlgorandVerifyTemplates([]string{"--expect", strings.Repeat("0", 64)})
+	})
+	if code != 1 || !strings.Contains(stderr, "not the expected one") {
+		t.Fatalf("--expect of another manifest: exit %d, %s", code, stderr)
+	}
+	_, _ = captureStdoutStderr(t, func() { code = runAlgorandVerifyTemplates([]string{"--expect", "abc"}) })
+	if code != 2 {
+		t.Fatalf("invalid --expect: exit %d, want 2", code)
+	}
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+)
+
+// appCallResultJSON is the --json output of algorand app-call.
+type appCallResultJSON struct {
+	TxID   string          `json:"txid"`
+	Return json.RawMessage `json:"return,omitempty"` // absent for void methods
+}
+
+// ---- algorand app-call ----
+func runAlgorandAppCall(args []string) int {
+	fs := flag.NewFlagSet("algorand app-call", flag.ExitOnError)
+	keyPath := fs.String("key", "", "path to FALCON keypair JSON file")
+	appID := fs.Uint64("app-id", 0, "ID of the application to call")
+	method := fs.String("method", "", `ARC-4 method signature, e.g. "transfer(address,uint64)void"`)
+	var methodArgs []string
+	fs.Func("args", "value of the next method argument (repeatable, in order)", func(s string) error {
+		methodArgs = append(methodArgs, s)
+		return nil
+	})
+	innerTxns := fs.Int("inner-txns", 0, "number of inner transactions the method issues, whose fees the call pays")
+	networkFlag := fs.String("network", "mainnet", "network: mainnet, testnet, betanet, devnet")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	algodURL := fs.String("algod-url", "", "set algod API endpoint (optional)")
+	algodToken := fs.String("algod-token", "", "set algod API token (optional); requires --algod-url")
+	jsonOut := fs.Bool("json", false, "print the result as JSON")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	algodURLProvided := false
+	algodTokenProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+		if f.Name == "algod-url" {
+			algodURLProvided = true
+		}
+		if f.Name == "algod-token" {
+			algodTokenProvided = true
+		}
+	})
+
+	// Validate required flags
+	if *keyPath == "" {
+		fmt.Fprintf(os.Stderr, "--key is required\n")
+		return 2
+	}
+	if *appID == 0 {
+		fmt.Fprintf(os.Stderr, "--app-id is required and must be > 0\n")
+		return 2
+	}
+	if *method == "" {
+		fmt.Fprintf(os.Stderr, "--method is required\n")
+		return 2
+	}
+	if *innerTxns < 0 {
+		fmt.Fprintf(os.Stderr, "--inner-txns must be >= 0\n")
+		return 2
+	}
+	if algodTokenProvided && !algodURLProvided {
+		fmt.Fprintf(os.Stderr, "--algod-token requires --algod-url\n")
+		return 2
+	}
+	trimmedAlgodURL := strings.TrimSpace(*algodURL)
+	trimmedAlgodToken := strings.TrimSpace(*algodToken)
+	if algodURLProvided && trimmedAlgodURL == "" && algodTokenProvided && trimmedAlgodToken != "" {
+		fmt.Fprintf(os.Stderr, "--algod-token requires a non-empty --algod-url\n")
+		return 2
+	}
+
+	netw, err := parseAlgorandNetwork(*networkFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --network: %v\n", err)
+		return 2
+	}
+
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	kp, code := loadSigningKeyPair("--key", *keyPath, override, "calling an application")
+	if code != 0 {
+		return code
+	}
+
+	if algodURLProvided {
+		if err := os.Setenv("ALGOD_URL", trimmedAlgodURL); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to set ALGOD_URL: %v\n", err)
+			return 2
+		}
+		if algodTokenProvided {
+			if err := os.Setenv("ALGOD_TOKEN", trimmedAlgodToken); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to set ALGOD_TOKEN: %v\n", err)
+				return 2
+			}
+		}
+	}
+
+	res, err := algorand.CallABIMethod(kp, *appID, *method, methodArgs,
+		algorand.AppCallOptions{Network: netw, InnerTxns: *innerTxns})
+	if res.TxID != "" {
+		recordKeyUse(kp.PublicKey[:], *keyPath, "algorand app-call",
+			strings.ToLower(strings.TrimSpace(*networkFlag)), 1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "app call failed: %v\n", err)
+		return 2
+	}
+
+	if *jsonOut {
+		data, err := json.MarshalIndent(appCallResultJSON{TxID: res.TxID, Return: res.Return}, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to encode result: %v\n", err)
+			return 2
+		}
+		fmt.Fprintf(os.Stdout, "%s\n", data)
+		return 0
+	}
+	fmt.Fprintf(os.Stdout, "Transaction confirmed with id: %s\n", res.TxID)
+	if res.Return != nil {
+		fmt.Fprintf(os.Stdout, "Return value: %s\n", res.Return)
+	}
+	return 0
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestRunAlgorandAppCall_Validation checks flag validation, and that method
+// arguments are checked before connecting to algod.
+func TestRunAlgorandAppCall_Validation(t *testing.T) {
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("app call test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, t.TempDir(), "keys.json", kp, true)
+	t.Setenv("ALGOD_URL", "http://127.0.0.1:1")
+
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--app-id", "7", "--method", "get()uint64"}, "--key is required"},
+		{[]string{"--key", keyPath, "--method", "get()uint64"}, "--app-id is required"},
+		{[]string{"--key", keyPath, "--app-id", "7"}, "--method is required"},
+		{[]string{"--key", keyPath, "--app-id", "7", "--method", "get()uint64", "--inner-txns", "-1"},
+			"--inner-txns must be >= 0"},
+		{[]string{"--key", keyPath, "--app-id", "7", "--method", "get(uint64"}, "invalid method signature"},
+		{[]string{"--key", keyPath, "--app-id", "7", "--method", "transfer(address,uint64)void",
+			"--args", "NOTANADDRESS", "--args", "1"}, "argument 1 (address)"},
+		{[]string{"--key", keyPath, "--app-id", "7", "--method", "transfer(address,uint64)void",
+			"--args", "1"}, "takes 2 arguments, got 1"},
+	}
+	for _, c := range cases {
+		var code int
+		_, stderr := captureStdoutStderr(t, func() { code = runAlgorandAppCall(c.args) })
+		if code != 2 || !strings.Contains(stderr, c.want) {
+			t.Fatalf("%v: expected %q with exit 2, got code %d: %q", c.args, c.want, code, stderr)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package cli
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// appReadJSON is the output of algorand app-read.
+type appReadJSON struct {
+	AppID   uint64         `json:"app_id"`
+	Scope   string         `json:"scope"` // global, local, box or boxes
+	Address string         `json:"address,omitempty"`
+	Entries []appEntryJSON `json:"entries"`
+}
+
+// appEntryJSON is a state key or box name and its value, decoded in the
+// ways that apply.
+type appEntryJSON struct {
+	Key       string          `json:"key,omitempty"` // when printable UTF-8
+	KeyBase64 string          `json:"key_base64"`
+	Type      string          `json:"type,omitempty"` // bytes or uint; empty when listing boxes
+	Uint      *uint64         `json:"uint,omitempty"`
+	Base64    *string         `json:"base64,omitempty"`
+	UTF8      string          `json:"utf8,omitempty"`    // when printable UTF-8
+	Address   string          `json:"address,omitempty"` // when 32 bytes long
+	Msgpack   json.RawMessage `json:"msgpack,omitempty"` // with --msgpack, when it decodes
+}
+
+// printableUTF8 returns b as a string if it is non-empty, valid UTF-8 without
+// control characters.
+func printableUTF8(b []byte) string {
+	if len(b) == 0 || !utf8.Valid(b) {
+		return ""
+	}
+	for _, r := range string(b) {
+		if unicode.IsControl(r) {
+			return ""
+		}
+	}
+	return string(b)
+}
+
+// newAppEntry describes key and, unless value is nil, its bytes value.
+func newAppEntry(key, value []byte, decodeMsgpack bool) appEntryJSON {
+	e := appEntryJSON{Key: printableUTF8(key), KeyBase64: base64.StdEncoding.EncodeToString(key)}
+	if value == nil {
+		return e
+	}
+	b64 := base64.StdEncoding.EncodeToString(value)
+	e.Type, e.Base64, e.UTF8 = "bytes", &b64, printableUTF8(value)
+	if len(value) == len(types.Address{}) {
+		var addr types.Address
+		copy(addr[:], value)
+		e.Address = addr.String()
+	}
+	if decodeMsgpack {
+		if v, err := algorand.DecodeMsgpack(value); err == nil {
+			e.Msgpack, _ = json.Marshal(v)
+		}
+	}
+	return e
+}
+
+// parseBoxName parses a box name in the notation of goal: str:<text>,
+// b64:<base64>, int:<uint64> (8 bytes big-endian) or addr:<address>.
+func parseBoxName(s string) ([]byte, error) {
+	enc, val, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, errors.New("box name must be str:, b64:, int: or addr: followed by the name")
+	}
+	switch enc {
+	case "str", "string":
+		return []byte(val), nil
+	case "b64", "base64":
+		return base64.StdEncoding.DecodeString(val)
+	case "int", "integer":
+		n, err := strconv.ParseUint(val, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return binary.BigEndian.AppendUint64(nil, n), nil
+	case "addr", "address":
+		addr, err := types.DecodeAddress(val)
+		if err != nil {
+			return nil, err
+		}
+		return addr[:], nil
+	default:
+		return nil, fmt.Errorf("unknown box name encoding %q", enc)
+	}
+}
+
+// ---- algorand app-read ----
+func runAlgorandAppRead(args []string) int {
+	fs := flag.NewFlagSet("algorand app-read", flag.ExitOnError)
+	appID := fs.Uint64("app-id", 0, "application ID")
+	global := fs.Bool("global", false, "read the global state")
+	local := fs.String("local", "", "read the local state of this account")
+	keyPath := fs.String("key", "", "read the local state of the PQ account of this key file")
+	box := fs.String("box", "", "read a box: str:<text>, b64:<base64>, int:<n> or addr:<address>")
+	boxes := fs.Bool("boxes", false, "list the box names")
+	decodeMsgpack := fs.Bool("msgpack", false, "also decode byte values as msgpack where possible")
+	networkFlag := fs.String("network", "mainnet", "network: mainnet, testnet, betanet, devnet")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	algodURL := fs.String("algod-url", "", "set algod API endpoint (optional)")
+	algodToken := fs.String("algod-token", "", "set algod API token (optional); requires --algod-url")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	algodURLProvided := false
+	algodTokenProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+		if f.Name == "algod-url" {
+			algodURLProvided = true
+		}
+		if f.Name == "algod-token" {
+			algodTokenProvided = true
+		}
+	})
+
+	if *appID == 0 {
+		fmt.Fprintf(os.Stderr, "--app-id is required and must be > 0\n")
+		return 2
+	}
+	scopes := 0
+	for _, set := range []bool{*global, *local != "", *keyPath != "", *box != "", *boxes} {
+		if set {
+			scopes++
+		}
+	}
+	if scopes != 1 {
+		fmt.Fprintf(os.Stderr, "provide exactly one of --global, --local, --key, --box or --boxes\n")
+		return 2
+	}
+	if algodTokenProvided && !algodURLProvided {
+		fmt.Fprintf(os.Stderr, "--algod-token requires --algod-url\n")
+		return 2
+	}
+	netw, err := parseAlgorandNetwork(*networkFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --network: %v\n", err)
+		return 2
+	}
+
+	res := appReadJSON{AppID: *appID, Entries: []appEntryJSON{}}
+	var boxName []byte
+	switch {
+	case *global:
+		res.Scope = "global"
+	case *local != "":
+		if _, err := types.DecodeAddress(*local); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --local: %v\n", err)
+			return 2
+		}
+		res.Scope, res.Address = "local", *local
+	case *keyPath != "":
+		var override *string
+		if passphraseProvided {
+			override = mnemonicPassphrase
+		}
+		pub, _, _, err := loadKeypairFile(*keyPath, override)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
+			return 2
+		}
+		if pub == nil {
+			fmt.Fprintf(os.Stderr, "public key not found in %s\n", *keyPath)
+			return 2
+		}
+		var pk falcongo.PublicKey
+		copy(pk[:], pub)
+		addr, err := algorand.GetAddressFromPublicKey(pk)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error deriving address: %v\n", err)
+			return 2
+		}
+		res.Scope, res.Address = "local", string(addr)
+	case *box != "":
+		if boxName, err = parseBoxName(*box); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --box: %v\n", err)
+			return 2
+		}
+		res.Scope = "box"
+	default:
+		res.Scope = "boxes"
+	}
+
+	if algodURLProvided {
+		if err := setAlgodEnv(strings.TrimSpace(*algodURL), strings.TrimSpace(*algodToken)); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to set ALGOD_URL: %v\n", err)
+			return 2
+		}
+	}
+
+	switch res.Scope {
+	case "global", "local":
+		var entries []algorand.StateEntry
+		if res.Scope == "global" {
+			entries, err = algorand.ReadAppGlobal(netw, *appID)
+		} else {
+			entries, err = algorand.ReadAppLocal(netw, *appID, res.Address)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read %s state: %v\n", res.Scope, err)
+			return 2
+		}
+		for _, e := range entries {
+			if e.IsUint {
+				entry := newAppEntry(e.Key, nil, false)
+				entry.Type, entry.Uint = "uint", &e.Uint
+				res.Entries = append(res.Entries, entry)
+				continue
+			}
+			res.Entries = append(res.Entries, newAppEntry(e.Key, e.Bytes, *decodeMsgpack))
+		}
+	case "box":
+		value, err := algorand.ReadAppBox(netw, *appID, boxName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read box: %v\n", err)
+			return 2
+		}
+		if value == nil {
+			value = []byte{}
+		}
+		res.Entries = append(res.Entries, newAppEntry(boxName, value, *decodeMsgpack))
+	case "boxes":
+		names, err := algorand.ListAppBoxes(netw, *appID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to list boxes: %v\n", err)
+			return 2
+		}
+		for _, name := range names {
+			res.Entries = append(res.Entries, newAppEntry(name, nil, false))
+		}
+	}
+
+	data, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode JSON: %v\n", err)
+		return 2
+	}
+	fmt.Fprintf(os.Stdout, "%s\n", data)
+	return 0
+}
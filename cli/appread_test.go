@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// TestParseBoxName covers the goal box name notations.
+func TestParseBoxName(t *testing.T) {
+	var addr types.Address
+	addr[0] = 7
+	for in, want := range map[string][]byte{
+		"str:config":            []byte("config"),
+		"b64:AQI=":              {1, 2},
+		"int:258":               {0, 0, 0, 0, 0, 0, 1, 2},
+		"addr:" + addr.String(): addr[:],
+	} {
+		got, err := parseBoxName(in)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Fatalf("parseBoxName(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"config", "hex:00", "int:-1", "addr:NOPE"} {
+		if _, err := parseBoxName(in); err == nil {
+			t.Fatalf("parseBoxName(%q) succeeded", in)
+		}
+	}
+}
+
+// TestRunAlgorandAppRead reads global state and a box from a fake algod.
+func TestRunAlgorandAppRead(t *testing.T) {
+	b64 := base64.StdEncoding.EncodeToString
+	var owner types.Address
+	owner[31] = 1
+	config := msgpack.Encode(map[string]any{"fee": 1000})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/applications/7":
+			_ = json.NewEncoder(w).Encode(models.Application{Id: 7, Params: models.ApplicationParams{
+				GlobalState: []models.TealKeyValue{
+					{Key: b64([]byte("owner")), Value: models.TealValue{Type: 1, Bytes: b64(owner[:])}},
+					{Key: b64([]byte("count")), Value: models.TealValue{Type: 2, Uint: 3}},
+				},
+			}})
+		case "/v2/applications/7/box":
+			_ = json.NewEncoder(w).Encode(models.Box{Name: []byte("config"), Value: config})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("ALGOD_URL", "")
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runAlgorandAppRead([]string{"--app-id", "7", "--global", "--algod-url", srv.URL})
+	})
+	var res appReadJSON
+	if err := json.Unmarshal([]byte(out), &res); code != 0 || err != nil {
+		t.Fatalf("unexpected result %d: %q", code, out)
+	}
+	if res.Scope != "global" || len(res.Entries) != 2 ||
+		res.Entries[0].Key != "count" || res.Entries[0].Uint == nil || *res.Entries[0].Uint != 3 ||
+		res.Entries[1].Key != "owner" || res.Entries[1].Address != owner.String() {
+		t.Fatalf("unexpected global state: %s", out)
+	}
+
+	out = captureStdout(t, func() {
+		code = runAlgorandAppRead([]string{"--app-id", "7", "--box", "str:config", "--msgpack",
+			"--algod-url", srv.URL})
+	})
+	if err := json.Unmarshal([]byte(out), &res); code != 0 || err != nil {
+		t.Fatalf("unexpected result %d: %q", code, out)
+	}
+	var compact bytes.Buffer
+	if res.Scope != "box" || len(res.Entries) != 1 || res.Entries[0].Key != "config" ||
+		json.Compact(&compact, res.Entries[0].Msgpack) != nil || compact.String() != `{"fee":1000}` {
+		t.Fatalf("unexpected box: %s", out)
+	}
+
+	_, stderr := captureStdoutStderr(t, func() {
+		code = runAlgorandAppRead([]string{"--app-id", "7", "--global", "--boxes"})
+	})
+	if code != 2 || !bytes.Contains([]byte(stderr), []byte("exactly one of")) {
+		t.Fatalf("expected a usage error, got %d: %q", code, stderr)
+	}
+}
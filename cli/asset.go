@@ -0,0 +1,351 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+)
+
+// assetRefusals are the errors of the preflight checks of asset-destroy and
+// asset-config: nothing is sent and the command exits 1.
+var assetRefusals = []error{
+	algorand.ErrAssetNotFound,
+	algorand.ErrNotAssetManager,
+	algorand.ErrAssetSupplyOutstanding,
+	algorand.ErrAssetImmutable,
+	algorand.ErrAssetRoleCleared,
+	algorand.ErrNoAssetRoleChange,
+	algorand.ErrRoleRemovalNotConfirmed,
+}
+
+// assetErrorCode reports err of operation and returns the exit code: 1 for a
+// refusal of the preflight checks, 2 otherwise.
+func assetErrorCode(operation string, err error) int {
+	for _, refusal := range assetRefusals {
+		if errors.Is(err, refusal) {
+			fmt.Fprintf(os.Stderr, "%s refused: %v; nothing sent\n", operation, err)
+			return 1
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%s failed: %v\n", operation, err)
+	return 2
+}
+
+// assetRoleNames are the roles of an asset, in the order they are printed.
+var assetRoleNames = []string{algorand.RoleManager, algorand.RoleReserve, algorand.RoleFreeze, algorand.RoleClawback}
+
+// ---- algorand asset-destroy ----
+func runAlgorandAssetDestroy(args []string) int {
+	fs := flag.NewFlagSet("algorand asset-destroy", flag.ExitOnError)
+	keyPath := fs.String("key", "", "path to FALCON keypair JSON file of the asset manager")
+	assetID := fs.Uint64("asset-id", 0, "ID of the asset to destroy")
+	confirmDestroy := fs.String("confirm-destroy", "", "repeat the --asset-id to confirm without a prompt")
+	networkFlag := fs.String("network", "mainnet", "network: mainnet, testnet, betanet, devnet")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	algodURL := fs.String("algod-url", "", "set algod API endpoint (optional)")
+	algodToken := fs.String("algod-token", "", "set algod API token (optional); requires --algod-url")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	algodURLProvided := false
+	algodTokenProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+		if f.Name == "algod-url" {
+			algodURLProvided = true
+		}
+		if f.Name == "algod-token" {
+			algodTokenProvided = true
+		}
+	})
+
+	if *keyPath == "" {
+		fmt.Fprintf(os.Stderr, "--key is required\n")
+		return 2
+	}
+	if *assetID == 0 {
+		fmt.Fprintf(os.Stderr, "--asset-id is required and must be > 0\n")
+		return 2
+	}
+	if algodTokenProvided && !algodURLProvided {
+		fmt.Fprintf(os.Stderr, "--algod-token requires --algod-url\n")
+		return 2
+	}
+	netw, err := parseAlgorandNetwork(*networkFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --network: %v\n", err)
+		return 2
+	}
+
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	kp, code := loadSigningKeyPair("--key", *keyPath, override, "destroying the asset")
+	if code != 0 {
+		return code
+	}
+	from, err := algorand.GetAddressFromPublicKey(kp.PublicKey)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error deriving address: %v\n", err)
+		return 2
+	}
+
+	if algodURLProvided {
+		if err := setAlgodEnv(strings.TrimSpace(*algodURL), strings.TrimSpace(*algodToken)); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to set ALGOD_URL: %v\n", err)
+			return 2
+		}
+	}
+
+	// Check before asking for confirmation; DestroyAsset checks again.
+	st, err := algorand.GetAssetState(*assetID, netw)
+	if err == nil {
+		err = st.CheckDestroy(string(from))
+	}
+	if err != nil {
+		return assetErrorCode("asset-destroy", err)
+	}
+	fmt.Fprintf(os.Stderr, "WARNING: this transaction destroys asset %d (total %d units, all held by its creator %s).\n",
+		st.ID, st.Total, st.Creator)
+	fmt.Fprintln(os.Stderr, "WARNING: this is irreversible: the asset ID can never be used again.")
+	if code := confirmTyped("--confirm-destroy", "asset ID", strconv.FormatUint(*assetID, 10), *confirmDestroy,
+		"type the --asset-id to confirm: "); code != 0 {
+		return code
+	}
+
+	txID, err := algorand.DestroyAsset(kp, *assetID, algorand.AssetOptions{Network: netw})
+	if err != nil {
+		return assetErrorCode("asset-destroy", err)
+	}
+	recordKeyUse(kp.PublicKey[:], *keyPath, "algorand asset-destroy",
+		strings.ToLower(strings.TrimSpace(*networkFlag)), 1)
+	fmt.Fprintf(os.Stdout, "Transaction confirmed with id: %s\n", txID)
+	return 0
+}
+
+// ---- algorand asset-config ----
+func runAlgorandAssetConfig(args []string) int {
+	fs := flag.NewFlagSet("algorand asset-config", flag.ExitOnError)
+	keyPath := fs.String("key", "", "path to FALCON keypair JSON file of the asset manager")
+	assetID := fs.Uint64("asset-id", 0, "ID of the asset to reconfigure")
+	roleFlags := map[string]*string{}
+	for _, role := range assetRoleNames {
+		roleFlags[role] = fs.String(role, "", "new "+role+" address, or none to remove the role (IRREVERSIBLE)")
+	}
+	confirmRemove := fs.String("confirm-remove", "", "comma-separated roles whose removal to confirm without a prompt")
+	networkFlag := fs.String("network", "mainnet", "network: mainnet, testnet, betanet, devnet")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	algodURL := fs.String("algod-url", "", "set algod API endpoint (optional)")
+	algodToken := fs.String("algod-token", "", "set algod API token (optional); requires --algod-url")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	algodURLProvided := false
+	algodTokenProvided := false
+	rolesSet := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		if _, ok := roleFlags[f.Name]; ok {
+			rolesSet[f.Name] = true
+		}
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+		if f.Name == "algod-url" {
+			algodURLProvided = true
+		}
+		if f.Name == "algod-token" {
+			algodTokenProvided = true
+		}
+	})
+
+	if *keyPath == "" {
+		fmt.Fprintf(os.Stderr, "--key is required\n")
+		return 2
+	}
+	if *assetID == 0 {
+		fmt.Fprintf(os.Stderr, "--asset-id is required and must be > 0\n")
+		return 2
+	}
+	if len(rolesSet) == 0 {
+		fmt.Fprintf(os.Stderr, "at least one of --manager, --reserve, --freeze or --clawback is required\n")
+		return 2
+	}
+	for _, role := range assetRoleNames {
+		if v := *roleFlags[role]; rolesSet[role] && v != "none" {
+			if _, err := types.DecodeAddress(v); err != nil {
+				fmt.Fprintf(os.Stderr, "invalid --%s: %v (use none to remove the role)\n", role, err)
+				return 2
+			}
+		}
+	}
+	var confirmed []string
+	if *confirmRemove != "" {
+		for _, role := range strings.Split(*confirmRemove, ",") {
+			role = strings.TrimSpace(role)
+			if _, ok := roleFlags[role]; !ok {
+				fmt.Fprintf(os.Stderr, "invalid --confirm-remove: unknown role %q (valid: manager, reserve, freeze, clawback)\n", role)
+				return 2
+			}
+			confirmed = append(confirmed, role)
+		}
+	}
+	if algodTokenProvided && !algodURLProvided {
+		fmt.Fprintf(os.Stderr, "--algod-token requires --algod-url\n")
+		return 2
+	}
+	netw, err := parseAlgorandNetwork(*networkFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --network: %v\n", err)
+		return 2
+	}
+
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	kp, code := loadSigningKeyPair("--key", *keyPath, override, "reconfiguring the asset")
+	if code != 0 {
+		return code
+	}
+
+	if algodURLProvided {
+		if err := setAlgodEnv(strings.TrimSpace(*algodURL), strings.TrimSpace(*algodToken)); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to set ALGOD_URL: %v\n", err)
+			return 2
+		}
+	}
+
+	from, err := algorand.GetAddressFromPublicKey(kp.PublicKey)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error deriving address: %v\n", err)
+		return 2
+	}
+	// Check before asking for confirmation; ReconfigureAsset checks again.
+	st, err := algorand.GetAssetState(*assetID, netw)
+	if err == nil {
+		err = st.CheckManager(string(from))
+	}
+	if err != nil {
+		return assetErrorCode("asset-config", err)
+	}
+	requested := requestedAssetRoles(st.Roles, roleFlags, rolesSet)
+	changes, err := algorand.PlanAssetRoles(st.Roles, requested, confirmed)
+	if err != nil && !errors.Is(err, algorand.ErrRoleRemovalNotConfirmed) {
+		return assetErrorCode("asset-config", err)
+	}
+	printRoleChanges(os.Stderr, *assetID, changes)
+
+	var removed []string
+	for _, c := range changes {
+		if c.Removed() {
+			removed = append(removed, c.Role)
+		}
+	}
+	for _, role := range confirmed {
+		if !slices.Contains(removed, role) {
+			fmt.Fprintf(os.Stderr, "--confirm-remove names %s, which is not removed; nothing sent\n", role)
+			return 2
+		}
+	}
+	if len(removed) > 0 {
+		fmt.Fprintf(os.Stderr, "WARNING: removing a role is irreversible: %s can never be set again.\n",
+			strings.Join(removed, ", "))
+		if code := confirmTyped("--confirm-remove", "removed roles", strings.Join(removed, ","),
+			strings.Join(confirmed, ","), "type the removed roles, comma-separated, to confirm: "); code != 0 {
+			return code
+		}
+	}
+
+	txID, _, err := algorand.ReconfigureAsset(kp, *assetID, requested, algorand.AssetOptions{
+		Network:           netw,
+		ConfirmedRemovals: removed,
+	})
+	if err != nil {
+		return assetErrorCode("asset-config", err)
+	}
+	recordKeyUse(kp.PublicKey[:], *keyPath, "algorand asset-config",
+		strings.ToLower(strings.TrimSpace(*networkFlag)), 1)
+	fmt.Fprintf(os.Stdout, "Transaction confirmed with id: %s\n", txID)
+	return 0
+}
+
+// requestedAssetRoles returns current with the roles given as flags
+// replaced, none standing for no one.
+func requestedAssetRoles(current algorand.AssetRoles, roleFlags map[string]*string, rolesSet map[string]bool,
+) algorand.AssetRoles {
+
+	role := func(name, currentValue string) string {
+		if !rolesSet[name] {
+			return currentValue
+		}
+		if v := *roleFlags[name]; v != "none" {
+			return v
+		}
+		return ""
+	}
+	return algorand.AssetRoles{
+		Manager:  role(algorand.RoleManager, current.Manager),
+		Reserve:  role(algorand.RoleReserve, current.Reserve),
+		Freeze:   role(algorand.RoleFreeze, current.Freeze),
+		Clawback: role(algorand.RoleClawback, current.Clawback),
+	}
+}
+
+// printRoleChanges prints the role changes of an asset config, current
+// versus requested.
+func printRoleChanges(w io.Writer, assetID uint64, changes []algorand.RoleChange) {
+	fmt.Fprintf(w, "Role changes of asset %d:\n", assetID)
+	for _, c := range changes {
+		to, note := c.To, ""
+		if c.Removed() {
+			to, note = "(none)", "  IRREVERSIBLE"
+		}
+		fmt.Fprintf(w, "  %-9s %s -> %s%s\n", c.Role, c.From, to, note)
+	}
+}
+
+// confirmTyped requires want to be repeated, either by confirmFlag (given
+// as confirmed) or typed on stdin after prompt. It returns 0 if confirmed,
+// or the exit code to return.
+func confirmTyped(confirmFlag, what, want, confirmed, prompt string) int {
+	if confirmed != "" {
+		if normalizeConfirmation(confirmed) != normalizeConfirmation(want) {
+			fmt.Fprintf(os.Stderr, "%s does not match the %s; nothing sent\n", confirmFlag, what)
+			return 2
+		}
+		return 0
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Fprintf(os.Stderr, "\nfailed to read confirmation: %v\n", err)
+		return 2
+	}
+	if normalizeConfirmation(line) != normalizeConfirmation(want) {
+		fmt.Fprintf(os.Stderr, "confirmation does not match the %s; nothing sent\n", what)
+		return 1
+	}
+	return 0
+}
+
+// normalizeConfirmation sorts the comma-separated items of s and trims them,
+// so roles can be confirmed in any order.
+func normalizeConfirmation(s string) string {
+	items := strings.Split(s, ",")
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+	slices.Sort(items)
+	return strings.Join(items, ",")
+}
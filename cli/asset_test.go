@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// assetAlgod serves asset 5 with params and the holding of its creator.
+func assetAlgod(t *testing.T, params models.AssetParams, creatorHolding uint64) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/assets/5":
+			_ = json.NewEncoder(w).Encode(models.Asset{Index: 5, Params: params})
+		case "/v2/accounts/" + params.Creator + "/assets/5":
+			_ = json.NewEncoder(w).Encode(models.AccountAssetResponse{
+				AssetHolding: models.AssetHolding{AssetId: 5, Amount: creatorHolding}})
+		default:
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// TestRunAlgorandAssetGuardrails covers the refusals of asset-destroy and
+// asset-config, none of which sends anything.
+func TestRunAlgorandAssetGuardrails(t *testing.T) {
+	t.Setenv("ALGOD_URL", "")
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("asset guardrails test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, t.TempDir(), "keys.json", kp, true)
+	addr, err := algorand.GetAddressFromPublicKey(kp.PublicKey)
+	if err != nil {
+		t.Fatalf("GetAddressFromPublicKey failed: %v", err)
+	}
+	manager := string(addr)
+	other := types.Address{7}.String()
+	managed := models.AssetParams{Creator: other, Manager: manager, Freeze: other, Clawback: other, Total: 100}
+
+	for _, tc := range []struct {
+		name    string
+		run     func([]string) int
+		params  models.AssetParams
+		holding uint64
+		args    []string
+		code    int
+		stderr  []string
+	}{{
+		name: "destroy: not the manager", run: runAlgorandAssetDestroy,
+		params: models.AssetParams{Creator: other, Manager: other, Total: 100}, holding: 100,
+		code: 1, stderr: []string{"asset-destroy refused: not the manager of the asset", "nothing sent"},
+	}, {
+		name: "destroy: supply outstanding", run: runAlgorandAssetDestroy,
+		params: managed, holding: 60,
+		code: 1, stderr: []string{"holds 60 of the 100 units of asset 5; the other 40 must be sent back first"},
+	}, {
+		name: "destroy: confirmation mismatch", run: runAlgorandAssetDestroy,
+		params: managed, holding: 100, args: []string{"--confirm-destroy", "6"},
+		code: 2, stderr: []string{"WARNING: this transaction destroys asset 5", "--confirm-destroy does not match"},
+	}, {
+		name: "config: no manager", run: runAlgorandAssetConfig,
+		params: models.AssetParams{Creator: other, Total: 100}, args: []string{"--freeze", "none"},
+		code: 1, stderr: []string{"the asset has no manager"},
+	}, {
+		name: "config: cleared role", run: runAlgorandAssetConfig,
+		params: models.AssetParams{Creator: other, Manager: manager, Total: 100}, args: []string{"--reserve", other},
+		code: 1, stderr: []string{"the role was removed and cannot be set again: reserve"},
+	}, {
+		name: "config: no change", run: runAlgorandAssetConfig,
+		params: managed, args: []string{"--freeze", other},
+		code: 1, stderr: []string{"the requested roles are the current roles"},
+	}, {
+		name: "config: partial confirmation", run: runAlgorandAssetConfig,
+		params: managed, args: []string{"--freeze", "none", "--clawback", "none", "--confirm-remove", "freeze"},
+		code: 2, stderr: []string{
+			"freeze    " + other + " -> (none)  IRREVERSIBLE",
+			"clawback  " + other + " -> (none)  IRREVERSIBLE",
+			"--confirm-remove does not match the removed roles",
+		},
+	}, {
+		name: "config: confirmation of a kept role", run: runAlgorandAssetConfig,
+		params: managed, args: []string{"--manager", other, "--confirm-remove", "freeze"},
+		code: 2, stderr: []string{"manager   " + manager + " -> " + other, "names freeze, which is not removed"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			url := assetAlgod(t, tc.params, tc.holding)
+			args := append([]string{"--key", keyPath, "--asset-id", "5", "--network", "devnet", "--algod-url", url},
+				tc.args...)
+			var code int
+			_, stderr := captureStdoutStderr(t, func() { code = tc.run(args) })
+			if code != tc.code {
+				t.Fatalf("expected exit %d, got %d: %s", tc.code, code, stderr)
+			}
+			for _, want := range tc.stderr {
+				if !strings.Contains(stderr, want) {
+					t.Errorf("stderr lacks %q:\n%s", want, stderr)
+				}
+			}
+		})
+	}
+}
+
+// TestRunAlgorandAssetConfig_Usage covers the usage errors of asset-config.
+func TestRunAlgorandAssetConfig_Usage(t *testing.T) {
+	for _, args := range [][]string{
+		{"--key", "k.json", "--asset-id", "5"},
+		{"--key", "k.json", "--asset-id", "5", "--freeze", "nobody"},
+		{"--key", "k.json", "--asset-id", "5", "--freeze", "none", "--confirm-remove", "owner"},
+		{"--asset-id", "5", "--freeze", "none"},
+	} {
+		var code int
+		_, _ = captureStdoutStderr(t, func() { code = runAlgorandAssetConfig(args) })
+		if code != 2 {
+			t.Errorf("%v: expected exit 2, got %d", args, code)
+		}
+	}
+}
@@ -0,0 +1,430 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/hashing"
+)
+
+// attestBundleJSON carries one message digest and the signatures of several
+// keys over it, so a single artifact can prove a K-of-N quorum.
+type attestBundleJSON struct {
+	Version   int    `json:"version"`
+	Algorithm string `json:"algorithm"`
+	// Hash is the algorithm of Digest; version 1 bundles omit it and use
+	// SHA-256.
+	Hash    string            `json:"hash,omitempty"`
+	Digest  string            `json:"digest"` // hex digest of the message
+	Entries []attestEntryJSON `json:"entries"`
+}
+
+type attestEntryJSON struct {
+	Fingerprint string `json:"fingerprint"` // hex SHA-256 of the public key
+	PublicKey   string `json:"public_key"`
+	// Signature is the text of a falcongo.Signature. It is decoded entry by
+	// entry, so that a malformed entry is an invalid signature rather than
+	// an unreadable bundle.
+	Signature string `json:"signature"`
+}
+
+const (
+	attestVersion   = 2
+	attestAlgorithm = "falcon-1024"
+	// Version 1 bundles sign attestDomainV1 || SHA-256 digest; version 2
+	// bundles sign attestDomain || hash name || 0x00 || digest, so the
+	// signatures cover the algorithm too.
+	attestVersionV1 = 1
+	attestDomainV1  = "falcon-attest-v1"
+	attestDomain    = "falcon-attest-v2"
+)
+
+// attestHash returns the algorithm of the bundle digest.
+func (b *attestBundleJSON) attestHash() (hashing.Algorithm, error) {
+	switch b.Version {
+	case attestVersionV1:
+		if b.Hash != "" {
+			return "", fmt.Errorf("version 1 bundles have no hash")
+		}
+		return hashing.SHA256, nil
+	case attestVersion:
+		return hashing.Parse(b.Hash)
+	}
+	return "", fmt.Errorf("unsupported bundle version %d", b.Version)
+}
+
+// attestSigningBytes returns the bytes each signer signs: the domain tag
+// followed by the message digest, and by the hash name in version 2.
+func attestSigningBytes(version int, hash hashing.Algorithm, digest []byte) []byte {
+	if version == attestVersionV1 {
+		return append([]byte(attestDomainV1), digest...)
+	}
+	out := append([]byte(attestDomain), hash...)
+	out = append(out, 0)
+	return append(out, digest...)
+}
+
+// ---- attest dispatcher ----
+func runAttest(args []string) int {
+	return runSubcommand("attest", args)
+}
+
+// ---- attest add ----
+func runAttestAdd(args []string) int {
+	fs := flag.NewFlagSet("attest add", flag.ExitOnError)
+	bundlePath := fs.String("bundle", "", "attestation bundle JSON file (created if missing)")
+	keyPath := fs.String("key", "", "path to keypair JSON file")
+	inFile := fs.String("in", "", "file containing message (alternative to --msg)")
+	msg := fs.String("msg", "", "inline message text (alternative to --in)")
+	hexIn := fs.Bool("hex", false, "treat message as hex-encoded bytes")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	hashName := fs.String("hash", string(hashing.Default), "digest algorithm of a new bundle")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	hashSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+		if f.Name == "hash" {
+			hashSet = true
+		}
+	})
+
+	if *bundlePath == "" {
+		fmt.Fprintf(os.Stderr, "--bundle is required\n")
+		return 2
+	}
+	if *keyPath == "" {
+		fmt.Fprintf(os.Stderr, "--key is required\n")
+		return 2
+	}
+	if *inFile != "" && *msg != "" {
+		fmt.Fprintf(os.Stderr, "provide at most one of --in or --msg\n")
+		return 2
+	}
+
+	newHash, err := hashing.ParseSelectable(*hashName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --hash: %v\n", err)
+		return 2
+	}
+	bundle, err := readAttestBundle(*bundlePath)
+	if errors.Is(err, os.ErrNotExist) {
+		bundle = &attestBundleJSON{Version: attestVersion, Algorithm: attestAlgorithm, Hash: string(newHash)}
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --bundle: %v\n", err)
+		return 2
+	}
+	hash, err := bundle.attestHash()
+	if err != nil || bundle.Algorithm != attestAlgorithm {
+		fmt.Fprintf(os.Stderr, "unsupported bundle version %d / algorithm %q / hash %q\n",
+			bundle.Version, bundle.Algorithm, bundle.Hash)
+		return 2
+	}
+	if hashSet && hash != newHash {
+		fmt.Fprintf(os.Stderr, "--hash %s does not match the bundle's %s\n", newHash, hash)
+		return 2
+	}
+
+	var digest []byte
+	if *inFile != "" || *msg != "" {
+		msgBytes, err := readAttestMessage(*inFile, *msg, *hexIn)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 2
+		}
+		digest = hash.Sum(msgBytes)
+	}
+	switch {
+	case bundle.Digest == "" && digest == nil:
+		fmt.Fprintf(os.Stderr, "provide --in or --msg to create a new bundle\n")
+		return 2
+	case bundle.Digest == "":
+		bundle.Digest = hex.EncodeToString(digest)
+	case digest != nil && bundle.Digest != hex.EncodeToString(digest):
+		fmt.Fprintf(os.Stderr, "message does not match the bundle digest\n")
+		return 2
+	}
+	bundleDigest, err := parseHex(bundle.Digest)
+	if err != nil || len(bundleDigest) != hash.Size() {
+		fmt.Fprintf(os.Stderr, "invalid bundle digest\n")
+		return 2
+	}
+
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	kp, code := loadSigningKeyPair("--key", *keyPath, override, "attesting")
+	if code != 0 {
+		return code
+	}
+	pub := kp.PublicKey[:]
+
+	fp := falcongo.Fingerprint(kp.PublicKey)
+	fingerprint := hex.EncodeToString(fp[:])
+	for _, e := range bundle.Entries {
+		if strings.EqualFold(e.Fingerprint, fingerprint) {
+			fmt.Fprintf(os.Stderr, "bundle already has a signature from %s\n", fingerprint)
+			return 2
+		}
+	}
+
+	sig, err := kp.Sign(attestSigningBytes(bundle.Version, hash, bundleDigest))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "signing failed: %v\n", err)
+		return 2
+	}
+	typed, err := falcongo.NewSignature(sig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "signing failed: %v\n", err)
+		return 2
+	}
+	sigText, err := typed.MarshalText()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "signing failed: %v\n", err)
+		return 2
+	}
+	bundle.Entries = append(bundle.Entries, attestEntryJSON{
+		Fingerprint: fingerprint,
+		PublicKey:   strings.ToLower(hex.EncodeToString(pub)),
+		Signature:   string(sigText),
+	})
+
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode bundle JSON: %v\n", err)
+		return 2
+	}
+	if err := writeFileAtomic(*bundlePath, data, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *bundlePath, err)
+		return 2
+	}
+	recordKeyUse(pub, *keyPath, "attest add", "", 1)
+	fmt.Fprintf(os.Stdout, "added signature from %s (%d total)\n", fingerprint, len(bundle.Entries))
+	return 0
+}
+
+// ---- attest verify ----
+func runAttestVerify(args []string) int {
+	fs := flag.NewFlagSet("attest verify", flag.ExitOnError)
+	bundlePath := fs.String("bundle", "", "attestation bundle JSON file")
+	threshold := fs.Int("threshold", 1, "number of distinct valid signatures required")
+	inFile := fs.String("in", "", "file containing message (alternative to --msg)")
+	msg := fs.String("msg", "", "inline message text (alternative to --in)")
+	hexIn := fs.Bool("hex", false, "treat message as hex-encoded bytes")
+	var signerPaths []string
+	fs.Func("signer", "public key JSON of an accepted signer (repeatable; default: any key)", func(s string) error {
+		signerPaths = append(signerPaths, s)
+		return nil
+	})
+	parseFlags(fs, args)
+
+	if *bundlePath == "" {
+		fmt.Fprintf(os.Stderr, "--bundle is required\n")
+		return 2
+	}
+	if *threshold < 1 {
+		fmt.Fprintf(os.Stderr, "--threshold must be >= 1\n")
+		return 2
+	}
+	if *inFile != "" && *msg != "" {
+		fmt.Fprintf(os.Stderr, "provide at most one of --in or --msg\n")
+		return 2
+	}
+
+	// Fingerprints of accepted signers; nil accepts any key.
+	var accepted map[string]bool
+	if len(signerPaths) > 0 {
+		accepted = map[string]bool{}
+		for _, path := range signerPaths {
+			fp, ok, err := keyFileFingerprint(path, nil)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to read --signer: %v\n", err)
+				return 2
+			}
+			if !ok {
+				fmt.Fprintf(os.Stderr, "public key not found in %s\n", path)
+				return 2
+			}
+			accepted[hex.EncodeToString(fp[:])] = true
+		}
+		if *threshold > len(accepted) {
+			fmt.Fprintf(os.Stderr, "--threshold %d exceeds the %d distinct --signer keys\n",
+				*threshold, len(accepted))
+			return 2
+		}
+	}
+
+	bundle, err := readAttestBundle(*bundlePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --bundle: %v\n", err)
+		return 2
+	}
+	hash, err := bundle.attestHash()
+	if err != nil || bundle.Algorithm != attestAlgorithm {
+		fmt.Fprintf(os.Stderr, "unsupported bundle version %d / algorithm %q / hash %q\n",
+			bundle.Version, bundle.Algorithm, bundle.Hash)
+		return 2
+	}
+	digest, err := parseHex(bundle.Digest)
+	if err != nil || len(digest) != hash.Size() {
+		fmt.Fprintf(os.Stderr, "invalid bundle digest\n")
+		return 2
+	}
+	if *inFile != "" || *msg != "" {
+		msgBytes, err := readAttestMessage(*inFile, *msg, *hexIn)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 2
+		}
+		if !bytes.Equal(hash.Sum(msgBytes), digest) {
+			fmt.Fprintln(os.Stdout, "INVALID")
+			fmt.Fprintln(os.Stderr, "message does not match the bundle digest")
+			return 1
+		}
+	}
+
+	signed := attestSigningBytes(bundle.Version, hash, digest)
+	valid := map[string]bool{}
+	for i, e := range bundle.Entries {
+		fingerprint, ok := verifyAttestEntry(e, signed)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "entry %d: invalid signature\n", i)
+			continue
+		}
+		if accepted != nil && !accepted[fingerprint] {
+			fmt.Fprintf(os.Stderr, "entry %d: %s is not an accepted signer\n", i, fingerprint)
+			continue
+		}
+		valid[fingerprint] = true
+	}
+
+	if len(valid) < *threshold {
+		fmt.Fprintf(os.Stdout, "INVALID (%d of %d required signatures)\n", len(valid), *threshold)
+		return 1
+	}
+	fmt.Fprintf(os.Stdout, "VALID (%d of %d required signatures)\n", len(valid), *threshold)
+	return 0
+}
+
+// verifyAttestEntry checks that the entry's fingerprint matches its public key
+// and that its signature over signed is valid. It returns the lowercase
+// fingerprint.
+func verifyAttestEntry(e attestEntryJSON, signed []byte) (string, bool) {
+	pub, err := parseHex(e.PublicKey)
+	if err != nil || len(pub) != falcongo.PublicKeySize {
+		return "", false
+	}
+	var sig falcongo.Signature
+	if err := sig.UnmarshalText([]byte(e.Signature)); err != nil {
+		return "", false
+	}
+	var pk falcongo.PublicKey
+	copy(pk[:], pub)
+	fp := falcongo.Fingerprint(pk)
+	fingerprint := hex.EncodeToString(fp[:])
+	if !strings.EqualFold(e.Fingerprint, fingerprint) {
+		return "", false
+	}
+	if err := sig.Verify(signed, pk); err != nil {
+		return "", false
+	}
+	return fingerprint, true
+}
+
+// readAttestBundle loads a bundle file; a missing file returns an error
+// wrapping os.ErrNotExist.
+func readAttestBundle(path string) (*attestBundleJSON, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var bundle attestBundleJSON
+	if err := json.Unmarshal(b, &bundle); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return &bundle, nil
+}
+
+// readAttestMessage reads the message from inFile or msg, hex-decoding it when
+// hexIn is set.
+func readAttestMessage(inFile, msg string, hexIn bool) ([]byte, error) {
+	if inFile != "" {
+		b, err := os.ReadFile(inFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read --in: %v", err)
+		}
+		if !hexIn {
+			return b, nil
+		}
+		out, err := parseHex(strings.TrimSpace(string(b)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex in --in file: %v", err)
+		}
+		return out, nil
+	}
+	if !hexIn {
+		return []byte(msg), nil
+	}
+	out, err := parseHex(msg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --msg hex: %v", err)
+	}
+	return out, nil
+}
+
+const helpAttest = `# falcon attest
+
+Collect and check K-of-N FALCON-1024 signatures over one message.
+
+An attestation bundle holds the digest of a message, the name of its hash
+algorithm, and one entry per signer with its public key fingerprint (SHA-256 of
+the public key), public key, and signature. Signers add their entries one at a
+time; verification enforces a quorum of distinct signers and recomputes the
+digest with the algorithm recorded in the bundle. Version 1 bundles, which
+record no algorithm, use SHA-256 and still verify.
+
+Usage:
+  falcon attest add --bundle <file> --key <file> [--in <file> | --msg <string>] [--hex] [--hash <name>] [--mnemonic-passphrase <string>]
+  falcon attest verify --bundle <file> [--threshold <k>] [--signer <file> ...] [--in <file> | --msg <string>] [--hex]
+
+Subcommands:
+  add       Sign the bundle digest and append the signature (creates the bundle if missing)
+  verify    Check that at least k distinct signers signed the bundle
+
+Arguments (add):
+  --bundle <file>           attestation bundle JSON (required; created if missing)
+  --key <file>              keypair JSON (required, must include private key)
+  --in <file> | --msg <string>
+                            message to attest (required for a new bundle; checked
+                            against the digest of an existing bundle)
+  --hex                     treat message as hex-encoded bytes
+  --hash <name>             digest algorithm of a new bundle: sha512-256 (default),
+                            sha3-256 or blake2b-256; must match an existing bundle
+  --mnemonic-passphrase     optional mnemonic passphrase when the key file omits it
+
+Arguments (verify):
+  --bundle <file>           attestation bundle JSON (required)
+  --threshold <k>           number of distinct valid signers required (default: 1)
+  --signer <file>           public key JSON of an accepted signer (repeatable;
+                            when omitted any valid signature counts)
+  --in <file> | --msg <string>
+                            message to check against the bundle digest (optional)
+  --hex                     treat message as hex-encoded bytes
+
+Exit codes (verify): 0 VALID, 1 INVALID, 2 usage or parse errors.
+
+Examples:
+  falcon attest add --bundle release.attest.json --key alice.json --in release.tar.gz
+  falcon attest add --bundle release.attest.json --key bob.json
+  falcon attest verify --bundle release.attest.json --threshold 2 --signer alice.pub.json --signer bob.pub.json --signer carol.pub.json --in release.tar.gz
+`
@@ -0,0 +1,206 @@
+package cli
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/hashing"
+)
+
+// attestKeys writes n keypair files to dir and returns their paths.
+func attestKeys(t *testing.T, dir string, n int) []string {
+	t.Helper()
+	var paths []string
+	for i := range n {
+		kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte{'a', byte(i)}))
+		if err != nil {
+			t.Fatalf("GenerateKeyPair failed: %v", err)
+		}
+		paths = append(paths, writeKeypairJSON(t, dir, "key"+string(rune('0'+i))+".json", kp, true))
+	}
+	return paths
+}
+
+// TestRunAttest_Threshold checks that a quorum is enforced.
+func TestRunAttest_Threshold(t *testing.T) {
+	dir := t.TempDir()
+	keys := attestKeys(t, dir, 3)
+	bundle := filepath.Join(dir, "bundle.json")
+
+	if code := runAttestAdd([]string{"--bundle", bundle, "--key", keys[0], "--msg", "release"}); code != 0 {
+		t.Fatalf("expected exit 0 from first add, got %d", code)
+	}
+	if code := runAttestAdd([]string{"--bundle", bundle, "--key", keys[1]}); code != 0 {
+		t.Fatalf("expected exit 0 from second add, got %d", code)
+	}
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runAttestVerify([]string{"--bundle", bundle, "--threshold", "2", "--msg", "release"})
+	})
+	if code != 0 || !strings.Contains(out, "VALID") {
+		t.Fatalf("expected VALID with 2 signers, got %d %q", code, out)
+	}
+	out = captureStdout(t, func() {
+		code = runAttestVerify([]string{"--bundle", bundle, "--threshold", "3"})
+	})
+	if code != 1 || !strings.Contains(out, "INVALID") {
+		t.Fatalf("expected INVALID with threshold 3, got %d %q", code, out)
+	}
+	out = captureStdout(t, func() {
+		code = runAttestVerify([]string{"--bundle", bundle, "--msg", "other"})
+	})
+	if code != 1 {
+		t.Fatalf("expected exit 1 for a different message, got %d %q", code, out)
+	}
+}
+
+// TestRunAttest_SignerRestriction ensures only accepted signers count.
+func TestRunAttest_SignerRestriction(t *testing.T) {
+	dir := t.TempDir()
+	keys := attestKeys(t, dir, 3)
+	bundle := filepath.Join(dir, "bundle.json")
+	for i, key := range keys[:2] {
+		if code := runAttestAdd([]string{"--bundle", bundle, "--key", key, "--msg", "m"}); code != 0 {
+			t.Fatalf("add %d: expected exit 0, got %d", i, code)
+		}
+	}
+
+	var code int
+	captureStdoutStderr(t, func() {
+		code = runAttestVerify([]string{"--bundle", bundle, "--threshold", "2",
+			"--signer", keys[0], "--signer", keys[2]})
+	})
+	if code != 1 {
+		t.Fatalf("expected exit 1 when only one accepted signer signed, got %d", code)
+	}
+	captureStdout(t, func() {
+		code = runAttestVerify([]string{"--bundle", bundle, "--threshold", "2",
+			"--signer", keys[0], "--signer", keys[1]})
+	})
+	if code != 0 {
+		t.Fatalf("expected exit 0 with both accepted signers, got %d", code)
+	}
+}
+
+// TestRunAttest_DuplicateAndTamper checks duplicate signers and tampered
+// entries do not count toward the threshold.
+func TestRunAttest_DuplicateAndTamper(t *testing.T) {
+	dir := t.TempDir()
+	keys := attestKeys(t, dir, 2)
+	bundle := filepath.Join(dir, "bundle.json")
+	for _, key := range keys {
+		if code := runAttestAdd([]string{"--bundle", bundle, "--key", key, "--msg", "m"}); code != 0 {
+			t.Fatalf("expected exit 0 from add, got %d", code)
+		}
+	}
+	var code int
+	_, stderr := captureStdoutStderr(t, func() {
+		code = runAttestAdd([]string{"--bundle", bundle, "--key", keys[0]})
+	})
+	if code != 2 || !strings.Contains(stderr, "already has a signature") {
+		t.Fatalf("expected duplicate signer rejection, got %d %q", code, stderr)
+	}
+
+	b, err := os.ReadFile(bundle)
+	if err != nil {
+		t.Fatalf("read bundle: %v", err)
+	}
+	var doc attestBundleJSON
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatalf("decode bundle: %v", err)
+	}
+	// Duplicate the first entry and corrupt the second.
+	doc.Entries[1].Signature = doc.Entries[0].Signature
+	doc.Entries = append(doc.Entries, doc.Entries[0])
+	b, _ = json.Marshal(doc)
+	if err := os.WriteFile(bundle, b, 0o644); err != nil {
+		t.Fatalf("write bundle: %v", err)
+	}
+	_, stderr = captureStdoutStderr(t, func() {
+		code = runAttestVerify([]string{"--bundle", bundle, "--threshold", "2"})
+	})
+	if code != 1 || !strings.Contains(stderr, "entry 1: invalid signature") {
+		t.Fatalf("expected INVALID with one distinct valid signer, got %d %q", code, stderr)
+	}
+}
+
+// TestRunAttest_Hash checks that the bundle records its digest algorithm, that
+// verification uses it, and that version 1 (SHA-256) bundles still verify.
+func TestRunAttest_Hash(t *testing.T) {
+	dir := t.TempDir()
+	keys := attestKeys(t, dir, 1)
+	bundle := filepath.Join(dir, "bundle.json")
+	if code := runAttestAdd([]string{"--bundle", bundle, "--key", keys[0], "--msg", "m",
+		"--hash", "sha3-256"}); code != 0 {
+		t.Fatalf("expected exit 0 from add, got %d", code)
+	}
+	var doc attestBundleJSON
+	readJSONFile(t, bundle, &doc)
+	if doc.Version != attestVersion || doc.Hash != "sha3-256" ||
+		doc.Digest != hex.EncodeToString(hashing.SHA3_256.Sum([]byte("m"))) {
+		t.Fatalf("unexpected bundle %+v", doc)
+	}
+	var code int
+	_, stderr := captureStdoutStderr(t, func() {
+		code = runAttestAdd([]string{"--bundle", bundle, "--key", keys[0], "--hash", "blake2b-256"})
+	})
+	if code != 2 || !strings.Contains(stderr, "does not match the bundle's sha3-256") {
+		t.Fatalf("expected a hash mismatch, got %d %q", code, stderr)
+	}
+	verify := func() (int, string) {
+		var code int
+		out, _ := captureStdoutStderr(t, func() {
+			code = runAttestVerify([]string{"--bundle", bundle, "--msg", "m"})
+		})
+		return code, out
+	}
+	if code, out := verify(); code != 0 {
+		t.Fatalf("expected VALID, got %d %q", code, out)
+	}
+
+	// The signatures cover the algorithm: relabeling it invalidates them.
+	doc.Hash = "blake2b-256"
+	doc.Digest = hex.EncodeToString(hashing.BLAKE2b256.Sum([]byte("m")))
+	writeAttestBundle(t, bundle, doc)
+	if code, out := verify(); code != 1 {
+		t.Fatalf("expected INVALID for a relabeled hash, got %d %q", code, out)
+	}
+
+	// A version 1 bundle: SHA-256 digest, no hash recorded.
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte{'a', 0}))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	digest := hashing.SHA256.Sum([]byte("m"))
+	sig, err := kp.Sign(append([]byte("falcon-attest-v1"), digest...))
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	fp := falcongo.Fingerprint(kp.PublicKey)
+	writeAttestBundle(t, bundle, attestBundleJSON{Version: 1, Algorithm: attestAlgorithm,
+		Digest: hex.EncodeToString(digest), Entries: []attestEntryJSON{{
+			Fingerprint: hex.EncodeToString(fp[:]),
+			PublicKey:   hex.EncodeToString(kp.PublicKey[:]),
+			Signature:   hex.EncodeToString(sig),
+		}}})
+	if code, out := verify(); code != 0 {
+		t.Fatalf("expected a version 1 bundle to verify, got %d %q", code, out)
+	}
+}
+
+func writeAttestBundle(t *testing.T, path string, doc attestBundleJSON) {
+	t.Helper()
+	b, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("encode bundle: %v", err)
+	}
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		t.Fatalf("write bundle: %v", err)
+	}
+}
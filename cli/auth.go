@@ -0,0 +1,248 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/algorandfoundation/falcon-signatures/auth"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/mnemonic"
+)
+
+// ---- auth dispatcher ----
+func runAuth(args []string) int {
+	return runSubcommand("auth", args)
+}
+
+// ---- auth challenge ----
+func runAuthChallenge(args []string) int {
+	fs := flag.NewFlagSet("auth challenge", flag.ExitOnError)
+	service := fs.String("service", "", "name of the service issuing the challenge")
+	ttl := fs.Duration("ttl", auth.DefaultTTL, "how long the challenge stays valid")
+	out := fs.String("out", "", "write challenge JSON to file (stdout if omitted)")
+	parseFlags(fs, args)
+
+	if strings.TrimSpace(*service) == "" {
+		fmt.Fprintf(os.Stderr, "--service is required\n")
+		return 2
+	}
+	if *ttl <= 0 {
+		fmt.Fprintf(os.Stderr, "--ttl must be positive\n")
+		return 2
+	}
+	c, err := auth.NewChallenge(strings.TrimSpace(*service), *ttl)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create challenge: %v\n", err)
+		return 2
+	}
+	return writeAuthJSON(c, *out, "challenge")
+}
+
+// ---- auth respond ----
+func runAuthRespond(args []string) int {
+	fs := flag.NewFlagSet("auth respond", flag.ExitOnError)
+	keyPath := fs.String("key", "", "path to keypair JSON file")
+	challengePath := fs.String("challenge", "", "challenge JSON file")
+	service := fs.String("service", "", "service you are logging in to; must match the challenge")
+	out := fs.String("out", "", "write response JSON to file (stdout if omitted)")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+	})
+
+	if *keyPath == "" {
+		fmt.Fprintf(os.Stderr, "--key is required\n")
+		return 2
+	}
+	if *challengePath == "" {
+		fmt.Fprintf(os.Stderr, "--challenge is required\n")
+		return 2
+	}
+	var c auth.Challenge
+	if err := readAuthJSON(*challengePath, &c); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --challenge: %v\n", err)
+		return 2
+	}
+	if *service != "" && c.Service != strings.TrimSpace(*service) {
+		fmt.Fprintf(os.Stderr, "challenge is for service %q, not %q; nothing signed\n", c.Service, *service)
+		return 2
+	}
+	if *service == "" {
+		fmt.Fprintf(os.Stderr, "signing a login challenge for service %q\n", c.Service)
+	}
+
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	kp, code := loadSigningKeyPair("--key", *keyPath, override, "answering the challenge")
+	if code != 0 {
+		return code
+	}
+
+	r, err := auth.Respond(kp, c)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to respond: %v\n", err)
+		return 2
+	}
+	if code := writeAuthJSON(r, *out, "response"); code != 0 {
+		return code
+	}
+	recordKeyUse(kp.PublicKey[:], *keyPath, "auth respond", "", 1)
+	return 0
+}
+
+// ---- auth verify ----
+func runAuthVerify(args []string) int {
+	fs := flag.NewFlagSet("auth verify", flag.ExitOnError)
+	challengePath := fs.String("challenge", "", "challenge JSON file, as issued")
+	responsePath := fs.String("response", "", "response JSON file")
+	keyPath := fs.String("key", "", "only accept this public key (keypair or public key JSON)")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+	})
+
+	if *challengePath == "" || *responsePath == "" {
+		fmt.Fprintf(os.Stderr, "--challenge and --response are required\n")
+		return 2
+	}
+	var c auth.Challenge
+	if err := readAuthJSON(*challengePath, &c); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --challenge: %v\n", err)
+		return 2
+	}
+	var r auth.Response
+	if err := readAuthJSON(*responsePath, &r); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --response: %v\n", err)
+		return 2
+	}
+	var want []byte
+	if *keyPath != "" {
+		var override *string
+		if passphraseProvided {
+			override = mnemonicPassphrase
+		}
+		pub, _, _, err := loadKeypairFile(*keyPath, override)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
+			return 2
+		}
+		if pub == nil {
+			fmt.Fprintf(os.Stderr, "public key not found in %s\n", *keyPath)
+			return 2
+		}
+		want = pub
+	}
+
+	if err := auth.Verify(c, r, time.Now()); err != nil {
+		fmt.Fprintf(os.Stdout, "INVALID: %s\n", strings.TrimPrefix(err.Error(), "auth: "))
+		return 1
+	}
+	if want != nil && !bytes.Equal(want, r.PublicKey[:]) {
+		fmt.Fprintln(os.Stdout, "INVALID: signed by a different key than --key")
+		return 1
+	}
+	fp := falcongo.Fingerprint(r.PublicKey)
+	fmt.Fprintln(os.Stdout, "VALID")
+	fmt.Fprintf(os.Stdout, "service: %s\n", c.Service)
+	fmt.Fprintf(os.Stdout, "fingerprint: %s\n", hex.EncodeToString(fp[:]))
+	fmt.Fprintf(os.Stdout, "petname: %s\n", mnemonic.Petname(fp))
+	return 0
+}
+
+// readAuthJSON decodes the JSON file at path into v.
+func readAuthJSON(path string, v any) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return nil
+}
+
+// writeAuthJSON writes v as indented JSON to out, or to stdout if out is
+// empty.
+func writeAuthJSON(v any, out, what string) int {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode %s JSON: %v\n", what, err)
+		return 2
+	}
+	data = append(data, '\n')
+	if out == "" {
+		if _, err := os.Stdout.Write(data); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write %s JSON: %v\n", what, err)
+			return 2
+		}
+		return 0
+	}
+	if err := writeFileAtomic(out, data, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", out, err)
+		return 2
+	}
+	return 0
+}
+
+const helpAuth = `# falcon auth
+
+Log in to a service with a FALCON key by challenge-response.
+
+The service issues a challenge with its name, a random nonce and an expiry.
+The client signs it, behind the domain tag falcon-auth-v1, and returns the
+signature with its public key. The service checks the response against the
+challenge it issued and identifies the client by the key's fingerprint.
+
+Usage:
+  falcon auth challenge --service <name> [--ttl <duration>] [--out <file>]
+  falcon auth respond --key <file> --challenge <file> [--service <name>] [--out <file>] [--mnemonic-passphrase <string>]
+  falcon auth verify --challenge <file> --response <file> [--key <file>] [--mnemonic-passphrase <string>]
+
+Subcommands:
+  challenge  Issue a challenge (service side)
+  respond    Sign a challenge (client side)
+  verify     Check a response against the issued challenge (service side)
+
+Arguments (challenge):
+  --service <name>          name of the issuing service, e.g. login.example.com (required)
+  --ttl <duration>          validity of the challenge (default: 5m)
+  --out <file>              write challenge JSON (stdout if omitted)
+
+Arguments (respond):
+  --key <file>              keypair JSON (required, must include private key)
+  --challenge <file>        challenge JSON (required)
+  --service <name>          refuse to sign unless the challenge names this service
+  --out <file>              write response JSON (stdout if omitted)
+  --mnemonic-passphrase     optional mnemonic passphrase when the key file omits it
+
+Arguments (verify):
+  --challenge <file>        the challenge as issued (required)
+  --response <file>         response JSON (required)
+  --key <file>              only accept responses signed by this key
+  --mnemonic-passphrase     optional mnemonic passphrase when the key file omits it
+
+Exit codes (verify): 0 VALID, 1 INVALID, 2 usage or parse errors.
+
+verify is stateless: the service must accept each challenge only once.
+
+Examples:
+  falcon auth challenge --service login.example.com --out challenge.json
+  falcon auth respond --key mykeys.json --challenge challenge.json --service login.example.com --out response.json
+  falcon auth verify --challenge challenge.json --response response.json
+`
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"encoding/hex"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestRunAuth_ChallengeRespondVerify runs a login end to end and checks the
+// service check of respond and the key pinning of verify.
+func TestRunAuth_ChallengeRespondVerify(t *testing.T) {
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("auth test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	other, err := falcongo.GenerateKeyPair(deriveSeed([]byte("auth other seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	dir := t.TempDir()
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+	otherPath := writeKeypairJSON(t, dir, "other.json", other, false)
+	challengePath := filepath.Join(dir, "challenge.json")
+	responsePath := filepath.Join(dir, "response.json")
+
+	if code := runAuthChallenge([]string{"--service", "login.example.com", "--out", challengePath}); code != 0 {
+		t.Fatalf("expected exit 0 from challenge, got %d", code)
+	}
+	var code int
+	_, stderr := captureStdoutStderr(t, func() {
+		code = runAuthRespond([]string{"--key", keyPath, "--challenge", challengePath,
+			"--service", "evil.example.com", "--out", responsePath})
+	})
+	if code != 2 || !strings.Contains(stderr, "nothing signed") {
+		t.Fatalf("expected respond to refuse another service, got %d: %q", code, stderr)
+	}
+	code = runAuthRespond([]string{"--key", keyPath, "--challenge", challengePath,
+		"--service", "login.example.com", "--out", responsePath})
+	if code != 0 {
+		t.Fatalf("expected exit 0 from respond, got %d", code)
+	}
+
+	fp := falcongo.Fingerprint(kp.PublicKey)
+	out := captureStdout(t, func() {
+		code = runAuthVerify([]string{"--challenge", challengePath, "--response", responsePath,
+			"--key", keyPath})
+	})
+	if code != 0 || !strings.Contains(out, "VALID") || !strings.Contains(out, "service: login.example.com") ||
+		!strings.Contains(out, "fingerprint: "+hex.EncodeToString(fp[:])) {
+		t.Fatalf("unexpected verify result %d: %q", code, out)
+	}
+	out = captureStdout(t, func() {
+		code = runAuthVerify([]string{"--challenge", challengePath, "--response", responsePath,
+			"--key", otherPath})
+	})
+	if code != 1 || !strings.Contains(out, "different key") {
+		t.Fatalf("expected verify to reject another key, got %d: %q", code, out)
+	}
+
+	// A response to another challenge does not log in.
+	otherChallenge := filepath.Join(dir, "other-challenge.json")
+	if code := runAuthChallenge([]string{"--service", "login.example.com", "--out", otherChallenge}); code != 0 {
+		t.Fatalf("expected exit 0 from challenge, got %d", code)
+	}
+	out = captureStdout(t, func() {
+		code = runAuthVerify([]string{"--challenge", otherChallenge, "--response", responsePath})
+	})
+	if code != 1 || !strings.Contains(out, "INVALID") {
+		t.Fatalf("expected verify to reject a response to another challenge, got %d: %q", code, out)
+	}
+}
@@ -0,0 +1,339 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/mnemonic"
+	"golang.org/x/crypto/argon2"
+)
+
+// Backup files (.fbk) hold only what is needed to re-derive a keypair: the
+// mnemonic entropy and the mnemonic passphrase, encrypted under a backup
+// passphrase. Layout:
+//
+//	magic "FBK" | version (1) | argon2id passes (1) | memory KiB (4, BE) |
+//	threads (1) | salt (16) | nonce (12) | AES-256-GCM ciphertext
+//
+// The header up to the nonce is authenticated as additional data. The
+// plaintext is the entropy length (1), the entropy, then the mnemonic
+// passphrase in UTF-8.
+const (
+	backupMagic      = "FBK"
+	backupVersion    = 1
+	backupSaltSize   = 16
+	backupKeySize    = 32
+	backupHeaderSize = len(backupMagic) + 1 + 1 + 4 + 1 + backupSaltSize
+)
+
+// Upper bounds on the argon2id parameters accepted from a backup header, which
+// is read before it is authenticated: four times what export-backup writes.
+const (
+	backupMaxPasses    = 4 * argon2DefaultIterations
+	backupMaxMemoryKiB = 4 * argon2DefaultMemoryKiB
+	backupMaxThreads   = 4 * argon2DefaultThreads
+)
+
+// backupKDF holds the argon2id parameters of a backup file.
+type backupKDF struct {
+	passes    uint8
+	memoryKiB uint32
+	threads   uint8
+	salt      [backupSaltSize]byte
+}
+
+func (k backupKDF) key(passphrase []byte) []byte {
+	return argon2.IDKey(passphrase, k.salt[:], uint32(k.passes), k.memoryKiB, k.threads,
+		backupKeySize)
+}
+
+func (k backupKDF) header() []byte {
+	h := append([]byte(backupMagic), backupVersion, k.passes)
+	h = binary.BigEndian.AppendUint32(h, k.memoryKiB)
+	h = append(h, k.threads)
+	return append(h, k.salt[:]...)
+}
+
+// sealBackup encrypts the mnemonic entropy and passphrase under the backup
+// passphrase with fresh salt and nonce.
+func sealBackup(entropy []byte, mnemonicPassphrase string, passphrase []byte) ([]byte, error) {
+	kdf := backupKDF{
+		passes:    argon2DefaultIterations,
+		memoryKiB: argon2DefaultMemoryKiB,
+		threads:   argon2DefaultThreads,
+	}
+	if _, err := rand.Read(kdf.salt[:]); err != nil {
+		return nil, err
+	}
+	aead, err := newBackupAEAD(kdf.key(passphrase))
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	plain := append([]byte{byte(len(entropy))}, entropy...)
+	plain = append(plain, mnemonicPassphrase...)
+	defer wipeBytes(plain)
+
+	header := kdf.header()
+	out := append(bytes.Clone(header), nonce...)
+	return aead.Seal(out, nonce, plain, header), nil
+}
+
+// openBackup decrypts a backup file and returns the mnemonic entropy and
+// passphrase.
+func openBackup(b, passphrase []byte) (entropy []byte, mnemonicPassphrase string, err error) {
+	if len(b) < backupHeaderSize || string(b[:len(backupMagic)]) != backupMagic {
+		return nil, "", errors.New("not a falcon backup file")
+	}
+	if v := b[len(backupMagic)]; v != backupVersion {
+		return nil, "", fmt.Errorf("unsupported backup version %d", v)
+	}
+	var kdf backupKDF
+	p := b[len(backupMagic)+1:]
+	kdf.passes = p[0]
+	kdf.memoryKiB = binary.BigEndian.Uint32(p[1:])
+	kdf.threads = p[5]
+	copy(kdf.salt[:], p[6:])
+	if kdf.passes == 0 || kdf.threads == 0 || kdf.memoryKiB < 8*uint32(kdf.threads) {
+		return nil, "", errors.New("invalid backup KDF parameters")
+	}
+	if kdf.passes > backupMaxPasses || kdf.memoryKiB > backupMaxMemoryKiB || kdf.threads > backupMaxThreads {
+		return nil, "", fmt.Errorf("backup KDF parameters exceed the limits (%d passes, %d MiB, %d threads)",
+			backupMaxPasses, backupMaxMemoryKiB/1024, backupMaxThreads)
+	}
+
+	aead, err := newBackupAEAD(kdf.key(passphrase))
+	if err != nil {
+		return nil, "", err
+	}
+	rest := b[backupHeaderSize:]
+	if len(rest) < aead.NonceSize()+aead.Overhead() {
+		return nil, "", errors.New("truncated backup file")
+	}
+	nonce, sealed := rest[:aead.NonceSize()], rest[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, sealed, b[:backupHeaderSize])
+	if err != nil {
+		return nil, "", errors.New("wrong passphrase or corrupted backup")
+	}
+	defer wipeBytes(plain)
+	if len(plain) < 1 || int(plain[0]) > len(plain)-1 {
+		return nil, "", errors.New("invalid backup contents")
+	}
+	n := int(plain[0])
+	entropy = bytes.Clone(plain[1 : 1+n])
+	return entropy, string(plain[1+n:]), nil
+}
+
+func newBackupAEAD(key []byte) (cipher.AEAD, error) {
+	defer wipeBytes(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// wipeBytes zeroes b (best effort: copies made by the runtime are not
+// reached).
+func wipeBytes(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// readBackupPassphrase returns the backup passphrase from --passphrase or the
+// first line of --passphrase-file.
+func readBackupPassphrase(flagVal, file string) ([]byte, error) {
+	if (flagVal == "") == (file == "") {
+		return nil, errors.New("provide exactly one of --passphrase or --passphrase-file")
+	}
+	if flagVal != "" {
+		return []byte(flagVal), nil
+	}
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read --passphrase-file: %w", err)
+	}
+	line, _, _ := strings.Cut(string(b), "\n")
+	line = strings.TrimSuffix(line, "\r")
+	if line == "" {
+		return nil, errors.New("--passphrase-file is empty")
+	}
+	return []byte(line), nil
+}
+
+// ---- export-backup ----
+func runExportBackup(args []string) int {
+	fs := flag.NewFlagSet("export-backup", flag.ExitOnError)
+	keyPath := fs.String("key", "", "keypair JSON file holding a mnemonic")
+	out := fs.String("out", "", "write the encrypted backup to file")
+	passphrase := fs.String("passphrase", "", "passphrase protecting the backup")
+	passphraseFile := fs.String("passphrase-file", "", "read the backup passphrase from the first line of a file")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase, if not stored in the key file")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+	})
+
+	if *keyPath == "" || *out == "" {
+		fmt.Fprintln(os.Stderr, "--key and --out are required")
+		return 2
+	}
+	pass, err := readBackupPassphrase(*passphrase, *passphraseFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	// Loading checks that the mnemonic matches any keys stored next to it, so
+	// the backup restores the same keypair.
+	_, _, meta, err := loadKeypairFile(*keyPath, override)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
+		return 2
+	}
+	words := strings.Fields(meta.Mnemonic)
+	if len(words) == 0 {
+		fmt.Fprintf(os.Stderr, "no mnemonic in %s; back up the key file itself instead\n", *keyPath)
+		return 2
+	}
+	entropy, err := mnemonic.MnemonicToEntropy(words)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid mnemonic in %s: %v\n", *keyPath, err)
+		return 2
+	}
+	defer wipeBytes(entropy)
+	mnemonicPass := meta.MnemonicPassphrase
+	if override != nil {
+		mnemonicPass = *override
+	}
+
+	data, err := sealBackup(entropy, mnemonicPass, pass)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encrypt backup: %v\n", err)
+		return 2
+	}
+	if err := writeFileAtomic(*out, data, 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *out, err)
+		return 2
+	}
+	return 0
+}
+
+// ---- restore-backup ----
+func runRestoreBackup(args []string) int {
+	fs := flag.NewFlagSet("restore-backup", flag.ExitOnError)
+	in := fs.String("in", "", "encrypted backup file")
+	out := fs.String("out", "", "write keypair JSON to file (stdout if empty)")
+	passphrase := fs.String("passphrase", "", "passphrase protecting the backup")
+	passphraseFile := fs.String("passphrase-file", "", "read the backup passphrase from the first line of a file")
+	parseFlags(fs, args)
+
+	if *in == "" {
+		fmt.Fprintln(os.Stderr, "--in is required")
+		return 2
+	}
+	pass, err := readBackupPassphrase(*passphrase, *passphraseFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	b, err := os.ReadFile(*in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --in: %v\n", err)
+		return 2
+	}
+	entropy, mnemonicPass, err := openBackup(b, pass)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to restore %s: %v\n", *in, err)
+		return 2
+	}
+	defer wipeBytes(entropy)
+	words, err := mnemonic.EntropyToMnemonic(entropy)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to restore %s: %v\n", *in, err)
+		return 2
+	}
+	seed, err := mnemonic.SeedFromMnemonic(words, mnemonicPass)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to derive Falcon seed from mnemonic: %v\n", err)
+		return 2
+	}
+	kp, err := falcongo.GenerateKeyPair(seed[:])
+	wipeBytes(seed[:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to generate keypair: %v\n", err)
+		return 2
+	}
+	obj := keyPairJSON{
+		PublicKey:          strings.ToLower(hex.EncodeToString(kp.PublicKey[:])),
+		PrivateKey:         strings.ToLower(hex.EncodeToString(kp.PrivateKey[:])),
+		Mnemonic:           strings.Join(words, " "),
+		MnemonicPassphrase: mnemonicPass,
+	}
+	attestKeyFile(&obj, kp)
+	return writeKeypairOutput(obj, *out)
+}
+
+const helpExportBackup = `# falcon export-backup
+
+Write a compact encrypted backup of a keypair created from a mnemonic. The
+backup holds only the mnemonic and its passphrase (no raw keys), encrypted
+with AES-256-GCM under a key derived from the backup passphrase with
+argon2id. Restore it with 'falcon restore-backup'.
+
+Usage:
+  falcon export-backup --key <file> --out <file> (--passphrase <string> | --passphrase-file <file>)
+
+Options:
+  --key <file>                keypair JSON file holding a mnemonic (required)
+  --out <file>                write the backup (0600) to file (required)
+  --passphrase <string>       passphrase protecting the backup
+  --passphrase-file <file>    read the backup passphrase from the first line of a file
+  --mnemonic-passphrase <string>
+                              mnemonic passphrase, if the key file does not store it
+
+Key files without a mnemonic (--no-mnemonic, --seed, subkeys) cannot be
+exported this way; back up the key file itself.
+
+Examples:
+  falcon export-backup --key mykeys.json --out mykeys.fbk --passphrase-file pass.txt
+`
+
+const helpRestoreBackup = `# falcon restore-backup
+
+Recreate the full keypair JSON from a backup made with 'falcon export-backup'.
+
+Usage:
+  falcon restore-backup --in <file> (--passphrase <string> | --passphrase-file <file>) [--out <file>]
+
+Options:
+  --in <file>                 backup file (required)
+  --out <file>                write keypair JSON (0600) to file (stdout if omitted)
+  --passphrase <string>       passphrase protecting the backup
+  --passphrase-file <file>    read the backup passphrase from the first line of a file
+
+A wrong passphrase or a modified backup is reported as an error (exit 2).
+
+Examples:
+  falcon restore-backup --in mykeys.fbk --passphrase-file pass.txt --out mykeys.json
+`
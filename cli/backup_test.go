@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestBackup_RoundTrip exports a mnemonic key file and restores the same
+// keypair from the backup.
+func TestBackup_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "keys.json")
+	if code := runCreate([]string{"--mnemonic-passphrase", "TREZOR", "--out", keyPath}); code != 0 {
+		t.Fatalf("create failed with %d", code)
+	}
+	backup := filepath.Join(dir, "keys.fbk")
+	passFile := filepath.Join(dir, "pass.txt")
+	if err := os.WriteFile(passFile, []byte("correct horse battery staple\n"), 0o600); err != nil {
+		t.Fatalf("write passphrase: %v", err)
+	}
+	if code := runExportBackup([]string{"--key", keyPath, "--out", backup,
+		"--passphrase-file", passFile}); code != 0 {
+		t.Fatalf("export-backup failed with %d", code)
+	}
+	b, err := os.ReadFile(backup)
+	if err != nil {
+		t.Fatalf("read backup: %v", err)
+	}
+	if len(b) > 128 || !strings.HasPrefix(string(b), backupMagic) {
+		t.Fatalf("unexpected backup of %d bytes", len(b))
+	}
+
+	restored := filepath.Join(dir, "restored.json")
+	if code := runRestoreBackup([]string{"--in", backup, "--passphrase",
+		"correct horse battery staple", "--out", restored}); code != 0 {
+		t.Fatalf("restore-backup failed with %d", code)
+	}
+	var want, got keyPairJSON
+	readJSONFile(t, keyPath, &want)
+	readJSONFile(t, restored, &got)
+	if got.PublicKey != want.PublicKey || got.PrivateKey != want.PrivateKey ||
+		got.Mnemonic != want.Mnemonic || got.MnemonicPassphrase != "TREZOR" {
+		t.Fatalf("restored key file differs: %+v", got)
+	}
+	if info, err := os.Stat(restored); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected restored file with mode 0600, got %v (%v)", info, err)
+	}
+}
+
+// TestBackup_Errors covers wrong passphrases, tampering and key files that
+// cannot be backed up.
+func TestBackup_Errors(t *testing.T) {
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "keys.json")
+	if code := runCreate([]string{"--out", keyPath}); code != 0 {
+		t.Fatalf("create failed with %d", code)
+	}
+	backup := filepath.Join(dir, "keys.fbk")
+	if code := runExportBackup([]string{"--key", keyPath, "--out", backup, "--passphrase", "pw"}); code != 0 {
+		t.Fatalf("export-backup failed with %d", code)
+	}
+	b, err := os.ReadFile(backup)
+	if err != nil {
+		t.Fatalf("read backup: %v", err)
+	}
+	tampered := filepath.Join(dir, "tampered.fbk")
+	b[len(b)-1] ^= 1
+	if err := os.WriteFile(tampered, b, 0o600); err != nil {
+		t.Fatalf("write backup: %v", err)
+	}
+
+	for name, args := range map[string][]string{
+		"wrong passphrase": {"--in", backup, "--passphrase", "wrong"},
+		"tampered":         {"--in", tampered, "--passphrase", "pw"},
+		"not a backup":     {"--in", keyPath, "--passphrase", "pw"},
+		"no passphrase":    {"--in", backup},
+	} {
+		var code int
+		stderr := captureStderr(t, func() { code = runRestoreBackup(args) })
+		if code != 2 || stderr == "" {
+			t.Fatalf("%s: expected error, got %d %q", name, code, stderr)
+		}
+	}
+
+	noMnemonic := filepath.Join(dir, "plain.json")
+	if code := runCreate([]string{"--no-mnemonic", "--out", noMnemonic}); code != 0 {
+		t.Fatalf("create failed with %d", code)
+	}
+	var code int
+	stderr := captureStderr(t, func() {
+		code = runExportBackup([]string{"--key", noMnemonic, "--out", filepath.Join(dir, "x.fbk"),
+			"--passphrase", "pw"})
+	})
+	if code != 2 || !strings.Contains(stderr, "no mnemonic") {
+		t.Fatalf("expected no mnemonic error, got %d %q", code, stderr)
+	}
+}
+
+// TestOpenBackup_KDFLimits checks that KDF parameters above the limits are
+// rejected before any key derivation runs.
+func TestOpenBackup_KDFLimits(t *testing.T) {
+	b, err := sealBackup(make([]byte, 32), "", []byte("pw"))
+	if err != nil {
+		t.Fatalf("sealBackup: %v", err)
+	}
+	p := len(backupMagic) + 1
+	for name, mutate := range map[string]func(h []byte){
+		"passes":  func(h []byte) { h[p] = backupMaxPasses + 1 },
+		"memory":  func(h []byte) { binary.BigEndian.PutUint32(h[p+1:], math.MaxUint32) },
+		"threads": func(h []byte) { h[p+5] = backupMaxThreads + 1 },
+	} {
+		crafted := bytes.Clone(b)
+		mutate(crafted)
+		if _, _, err := openBackup(crafted, []byte("pw")); err == nil || !strings.Contains(err.Error(), "exceed the limits") {
+			t.Errorf("%s: expected a limits error, got %v", name, err)
+		}
+	}
+	if _, _, err := openBackup(b, []byte("pw")); err != nil {
+		t.Fatalf("openBackup of the original failed: %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+)
+
+// ---- algorand scan-blocks ----
+func runAlgorandScanBlocks(args []string) int {
+	fs := flag.NewFlagSet("algorand scan-blocks", flag.ExitOnError)
+	round := fs.Uint64("round", 0, "round of the block to scan")
+	first := fs.Uint64("first", 0, "first round of a range to scan")
+	last := fs.Uint64("last", 0, "last round of a range to scan (inclusive)")
+	anomaliesOnly := fs.Bool("anomalies-only", false, "list only the PQ transactions with an anomaly")
+	jsonOut := fs.Bool("json", false, "print the result as JSON")
+	networkFlag := fs.String("network", "mainnet", "network: mainnet, testnet, betanet, devnet")
+	algodURL := fs.String("algod-url", "", "set algod API endpoint (optional)")
+	algodToken := fs.String("algod-token", "", "set algod API token (optional); requires --algod-url")
+	parseFlags(fs, args)
+	roundSet, firstSet, lastSet := false, false, false
+	algodURLProvided := false
+	algodTokenProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "round":
+			roundSet = true
+		case "first":
+			firstSet = true
+		case "last":
+			lastSet = true
+		case "algod-url":
+			algodURLProvided = true
+		case "algod-token":
+			algodTokenProvided = true
+		}
+	})
+
+	if roundSet == (firstSet || lastSet) || firstSet != lastSet {
+		fmt.Fprintf(os.Stderr, "either --round or both --first and --last are required\n")
+		return 2
+	}
+	if roundSet {
+		*first, *last = *round, *round
+	}
+	if *last < *first {
+		fmt.Fprintf(os.Stderr, "--last must not be before --first\n")
+		return 2
+	}
+	if algodTokenProvided && !algodURLProvided {
+		fmt.Fprintf(os.Stderr, "--algod-token requires --algod-url\n")
+		return 2
+	}
+	trimmedAlgodURL := strings.TrimSpace(*algodURL)
+	trimmedAlgodToken := strings.TrimSpace(*algodToken)
+	if algodURLProvided && trimmedAlgodURL == "" && algodTokenProvided && trimmedAlgodToken != "" {
+		fmt.Fprintf(os.Stderr, "--algod-token requires a non-empty --algod-url\n")
+		return 2
+	}
+
+	netw, err := parseAlgorandNetwork(*networkFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --network: %v\n", err)
+		return 2
+	}
+	if algodURLProvided {
+		if err := os.Setenv("ALGOD_URL", trimmedAlgodURL); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to set ALGOD_URL: %v\n", err)
+			return 2
+		}
+		if algodTokenProvided {
+			if err := os.Setenv("ALGOD_TOKEN", trimmedAlgodToken); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to set ALGOD_TOKEN: %v\n", err)
+				return 2
+			}
+		}
+	}
+
+	bar := startProgress("scanning blocks", 0, *last-*first+1)
+	scan, err := algorand.ScanRounds(*first, *last, netw, func(uint64) { bar.add(1) })
+	bar.finish()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scan failed: %v\n", err)
+		return 2
+	}
+	anomalies, pq := scan.Anomalies(), len(scan.PQ)
+	if *anomaliesOnly {
+		listed := scan.PQ[:0:0]
+		for _, t := range scan.PQ {
+			if t.Anomaly != "" {
+				listed = append(listed, t)
+			}
+		}
+		scan.PQ = listed
+	}
+
+	if *jsonOut {
+		data, err := json.MarshalIndent(scan, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to encode result: %v\n", err)
+			return 2
+		}
+		fmt.Fprintf(os.Stdout, "%s\n", data)
+	} else {
+		for _, t := range scan.PQ {
+			status := "VALID"
+			if t.Anomaly != "" {
+				status = strings.ToUpper(t.Anomaly)
+			}
+			fmt.Fprintf(os.Stdout, "%d %s %s %s %s", t.Round, t.TxID, t.Kind, t.Account, status)
+			if t.SignedBy != "" {
+				fmt.Fprintf(os.Stdout, " signed-by=%s", t.SignedBy[:16])
+			}
+			if t.Delegated {
+				fmt.Fprint(os.Stdout, " delegated")
+			}
+			fmt.Fprintln(os.Stdout)
+		}
+		fmt.Fprintf(os.Stdout, "Scanned %d transactions in rounds %d-%d: %d authorized by PQ programs, %d anomalies\n",
+			scan.Transactions, scan.FirstRound, scan.LastRound, pq, anomalies)
+	}
+	if anomalies > 0 {
+		return 1
+	}
+	return 0
+}
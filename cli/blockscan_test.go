@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestRunAlgorandScanBlocks scans blocks of a fake algod: round 10 has a
+// valid PQ payment, round 11 one whose signature was tampered with.
+func TestRunAlgorandScanBlocks(t *testing.T) {
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("scan blocks test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	lsig, err := algorand.DerivePQLogicSig(kp.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	address, err := lsig.Address()
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := func(round uint64, tamper bool) types.Block {
+		txn := types.Transaction{Type: types.PaymentTx, Header: types.Header{
+			Sender: address, Fee: 1000, FirstValid: 5, LastValid: 20, GenesisID: "testnet-v1.0",
+		}}
+		sig, err := kp.SignTransactionID(txn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tamper {
+			sig[10] ^= 1
+		}
+		signer := lsig.Lsig
+		signer.Args = [][]byte{sig}
+		_, signed, err := crypto.SignLogicSigTransaction(signer, txn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var stib types.SignedTxnInBlock
+		if err := msgpack.Decode(signed, &stib.SignedTxn); err != nil {
+			t.Fatal(err)
+		}
+		stib.Txn.GenesisID, stib.HasGenesisID = "", true
+		return types.Block{
+			BlockHeader: types.BlockHeader{Round: types.Round(round), GenesisID: "testnet-v1.0"},
+			Payset:      types.Payset{stib},
+		}
+	}
+	blocks := map[string]types.Block{"/v2/blocks/10": block(10, false), "/v2/blocks/11": block(11, true)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, ok := blocks[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(msgpack.Encode(models.BlockResponse{Block: b}))
+	}))
+	defer srv.Close()
+	t.Setenv("ALGOD_URL", "")
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runAlgorandScanBlocks([]string{"--round", "10", "--network", "testnet", "--algod-url", srv.URL})
+	})
+	if code != 0 || !strings.Contains(out, address.String()+" VALID") ||
+		!strings.Contains(out, "1 authorized by PQ programs, 0 anomalies") {
+		t.Fatalf("round 10: exit %d, %q", code, out)
+	}
+
+	out = captureStdout(t, func() {
+		code = runAlgorandScanBlocks([]string{"--first", "10", "--last", "11", "--anomalies-only", "--json",
+			"--network", "testnet", "--algod-url", srv.URL})
+	})
+	var scan algorand.BlockScan
+	if err := json.Unmarshal([]byte(out), &scan); code != 1 || err != nil {
+		t.Fatalf("rounds 10-11: exit %d, %q", code, out)
+	}
+	if scan.Transactions != 2 || len(scan.PQ) != 1 || scan.PQ[0].Round != 11 ||
+		scan.PQ[0].Anomaly != algorand.AnomalyInvalidSignature {
+		t.Fatalf("unexpected scan: %s", out)
+	}
+
+	for name, args := range map[string][]string{
+		"no round":       {},
+		"round and last": {"--round", "10", "--last", "11"},
+		"no last":        {"--first", "10"},
+		"reversed range": {"--first", "11", "--last", "10"},
+		"token only":     {"--round", "10", "--algod-token", "x"},
+		"missing block":  {"--round", "12", "--network", "testnet", "--algod-url", srv.URL},
+	} {
+		stderr := captureStderr(t, func() { code = runAlgorandScanBlocks(args) })
+		if code != 2 || stderr == "" {
+			t.Fatalf("%s: exit %d, %q", name, code, stderr)
+		}
+	}
+}
@@ -10,6 +10,21 @@ type keyPairJSON struct {
 	PrivateKey         string `json:"private_key,omitempty"`
 	Mnemonic           string `json:"mnemonic,omitempty"`
 	MnemonicPassphrase string `json:"mnemonic_passphrase,omitempty"`
+	// KDF records the parameters used to derive a --seed keypair.
+	KDF *kdfParamsJSON `json:"kdf,omitempty"`
+	// CreatedBy records the falcon version that wrote the file.
+	CreatedBy string `json:"created_by,omitempty"`
+	// MinReaderVersion is the lowest key file format a reader must support
+	// to use the file; see keyFileFormatVersion.
+	MinReaderVersion int `json:"min_reader_version,omitempty"`
+	// KeyAttestation is the self-signed consistency check written by create.
+	KeyAttestation *keyAttestationJSON `json:"key_attestation,omitempty"`
+	// PassphraseAccounts lists the accounts other passphrases open from the
+	// mnemonic, under user hints; see passphraseAccountJSON.
+	PassphraseAccounts []passphraseAccountJSON `json:"passphrase_accounts,omitempty"`
+	// MessagePolicy restricts the messages falcon sign signs with the key;
+	// see messagePolicyJSON.
+	MessagePolicy *messagePolicyJSON `json:"message_policy,omitempty"`
 }
 
 // Main is the CLI entrypoint used by the falcon binary.
@@ -18,32 +33,35 @@ func Main() {
 }
 
 // Run executes the CLI with the provided arguments and returns the exit code.
+// With --debug-bundle, or if the command panics, it also writes a debug bundle.
+// With --read-only or $FALCON_READ_ONLY, signing and broadcasting are disabled
+// for the rest of the process. --no-progress turns off progress bars, and
+// --insecure-permissions reads key files other users can read.
 func Run(args []string) int {
+	return runWithDebugBundle(args, run)
+}
+
+func run(args []string) int {
+	args, err := enterReadOnlyMode(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	args, noProgress := extractGlobalBoolFlag(args, noProgressFlag)
+	progressDisabled.Store(noProgress)
+	args, insecure := extractGlobalBoolFlag(args, insecurePermissionsFlag)
+	insecurePermissions.Store(insecure)
 	if len(args) < 1 {
-		fmt.Fprint(os.Stdout, topHelp)
+		fmt.Fprint(os.Stdout, topHelp())
 		return 0
 	}
 
 	cmd := args[0]
-	remain := args[1:]
-	switch cmd {
-	case "create":
-		return runCreate(remain)
-	case "sign":
-		return runSign(remain)
-	case "verify":
-		return runVerify(remain)
-	case "info":
-		return runInfo(remain)
-	case "algorand":
-		return runAlgorand(remain)
-	case "version":
-		return runVersion(remain)
-	case "help", "-h", "--help":
-		return runHelp(remain)
-	default:
+	c, ok := findCommand(commandRegistry(), cmd)
+	if !ok {
 		fmt.Fprintf(os.Stderr, "unknown command: %s\n\n", cmd)
-		fmt.Fprint(os.Stderr, topHelp)
+		fmt.Fprint(os.Stderr, topHelp())
 		return 2
 	}
+	return c.run(args[1:])
 }
@@ -0,0 +1,283 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// command is an entry of the command registry. The dispatchers, the command
+// list of 'falcon help', the help topics and 'falcon help --json' are all
+// generated from it.
+type command struct {
+	name    string
+	aliases []string
+	summary string
+	// help is the text of 'falcon help <name>'; subcommands are described
+	// in the help of their group.
+	help string
+	// args describes the positional arguments, if the command takes any.
+	args string
+	run  func(args []string) int
+	// subcommands, if any, are dispatched on the first argument by run.
+	subcommands []command
+	// exit1 says what exit code 1 reports, for commands that use it for a
+	// negative result rather than an error.
+	exit1 string
+}
+
+// commandRegistry returns the commands of the CLI, in the order of 'falcon
+// help'.
+func commandRegistry() []command {
+	return []command{
+		{name: "create", summary: "Create a new keypair", help: helpCreate, run: runCreate},
+		{name: "sign", summary: "Sign a message", help: helpSign, run: runSign},
+		{name: "sign-request", summary: "Review, approve and execute signing requests", help: helpSignRequest,
+			run: runSignRequest, subcommands: []command{
+				{name: "create", summary: "Describe a message or transaction to sign", run: runSignRequestCreate},
+				{name: "approve", summary: "Show a signing request and record its approval", run: runSignRequestApprove,
+					exit1: "the review code was not confirmed, or the request expired"},
+				{name: "execute", summary: "Sign an approved signing request", run: runSignRequestExecute,
+					exit1: "the request is not approved, changed since approval, expired, or not approved by --approver; nothing signed"},
+			}},
+		{name: "verify", summary: "Verify a signature for a message", help: helpVerify, run: runVerify,
+			exit1: "the signature is INVALID, REVOKED or REPLAYED (with --stream: a record is not valid)"},
+		{name: "info", summary: "Display information about a keypair file or signature", help: helpInfo, run: runInfo,
+			exit1: "the key_attestation does not verify, or --mnemonic-passphrase opens none of the passphrase accounts"},
+		{name: "inspect-key", summary: "Show the polynomial coefficients of a public key", help: helpInspectKey,
+			run: runInspectKey},
+		{name: "algorand", summary: "Algorand utilities", help: helpAlgorand, run: runAlgorand, subcommands: []command{
+			{name: "address", summary: "Derive an Algorand address from a FALCON public key", args: "[<file>...]",
+				run:   runAlgorandAddress,
+				exit1: "--check: the derived addresses differ from the table"},
+			{name: "recovery-address", summary: "Derive an address that a backup FALCON key can also spend from after a round",
+				run: runAlgorandRecoveryAddress},
+			{name: "hybrid-address", summary: "Derive an address that needs both a FALCON and an Ed25519 signature",
+				run: runAlgorandHybridAddress},
+			{name: "delegate", summary: "Delegate an existing Ed25519 account to a FALCON key", run: runAlgorandDelegate},
+			{name: "migrate", summary: "Move an existing Ed25519 account to a FALCON key, by rekeying or sweeping it",
+				run: runAlgorandMigrate, exit1: "the account is rekeyed already or the migration was not confirmed; nothing sent"},
+			{name: "send", summary: "Send Algos from a FALCON-controlled address", run: runAlgorandSend,
+				exit1: "--confirm-rekey does not match --rekey-to; nothing sent"},
+			{name: "suggested-params", summary: "Save a snapshot of the suggested params of a network for offline sends",
+				run: runAlgorandSuggestedParams},
+			{name: "submit", summary: "Broadcast a group signed by send --offline", run: runAlgorandSubmit,
+				exit1: "the group is stale or for another network; nothing sent"},
+			{name: "template-create", summary: "Save the recipient, amount, note and network of a recurring send as a template",
+				run: runAlgorandTemplateCreate},
+			{name: "template-list", summary: "List the send templates", run: runAlgorandTemplateList},
+			{name: "claim", summary: "Claim an asset from the ARC-59 inbox of a FALCON-controlled address",
+				run: runAlgorandClaim},
+			{name: "opt-in", summary: "Opt a FALCON-controlled address into an asset, optionally sponsored",
+				run: runAlgorandOptIn},
+			{name: "asset-config", summary: "Change or remove the roles of an asset managed by a FALCON-controlled address",
+				run: runAlgorandAssetConfig, exit1: "the change was refused by the preflight checks or not confirmed; nothing sent"},
+			{name: "asset-destroy", summary: "Destroy an asset managed by a FALCON-controlled address",
+				run: runAlgorandAssetDestroy, exit1: "the destruction was refused by the preflight checks or not confirmed; nothing sent"},
+			{name: "heartbeat", summary: "Show when an online account needs heartbeats, and send one", run: runAlgorandHeartbeat},
+			{name: "publish-key", summary: "Publish the FALCON public key of a PQ account on-chain", run: runAlgorandPublishKey},
+			{name: "fetch-key", summary: "Fetch and verify the FALCON public key published for a PQ address",
+				run: runAlgorandFetchKey, exit1: "no key is published for the address"},
+			{name: "status", summary: "Check, and resume waiting for, a sent transaction", run: runAlgorandStatus},
+			{name: "receipts", summary: "List and show the receipts archived by send --receipts-dir", run: runAlgorandReceipts,
+				subcommands: []command{
+					{name: "list", summary: "List the archived receipts", run: runAlgorandReceiptsList},
+					{name: "show", summary: "Show a receipt and check its archived signed group", run: runAlgorandReceiptsShow,
+						exit1: "the archived signed group does not match its receipt"},
+				}},
+			{name: "app-read", summary: "Print the global, local or box storage of an application as JSON", run: runAlgorandAppRead},
+			{name: "app-call", summary: "Call an ARC-4 method of an application from a FALCON-controlled address",
+				run: runAlgorandAppCall},
+			{name: "nft-mint", summary: "Mint an ARC-3 or ARC-69 NFT from a FALCON-controlled address",
+				run: runAlgorandNFTMint},
+			{name: "watch-export", summary: "Export the address of a PQ account as a URI and QR code for wallet watch accounts",
+				run: runAlgorandWatchExport},
+			{name: "scan-blocks", summary: "Re-verify the FALCON signatures of the PQ transactions of blocks",
+				run: runAlgorandScanBlocks, exit1: "a PQ transaction has an anomaly (e.g. an invalid signature)"},
+			{name: "verify-templates", summary: "Check the logicsig templates of this binary against its manifest, offline",
+				run: runAlgorandVerifyTemplates, exit1: "the templates or the manifest are not those of the release"},
+		}},
+		{name: "mnemonic", summary: "Mnemonic utilities", help: helpMnemonic, run: runMnemonic, subcommands: []command{
+			{name: "recover", summary: "Recover missing words of a damaged 24-word mnemonic", run: runMnemonicRecover,
+				exit1: "NOT FOUND: no candidate mnemonic matches"},
+		}},
+		{name: "csr", summary: "Create and verify certification requests", help: helpCSR, run: runCSR, subcommands: []command{
+			{name: "create", summary: "Create a self-signed certification request", run: runCSRCreate},
+			{name: "verify", summary: "Check the self-signature of a certification request", run: runCSRVerify,
+				exit1: "the self-signature is INVALID"},
+		}},
+		{name: "tree", summary: "Sign directory trees and verify single files with Merkle proofs", help: helpTree,
+			run: runTree, subcommands: []command{
+				{name: "sign", summary: "Sign the Merkle root of the files of a directory", run: runTreeSign},
+				{name: "verify", summary: "Check one file against its proof", run: runTreeVerify,
+					exit1: "the file, its proof or the root signature is INVALID"},
+			}},
+		{name: "attest", summary: "Collect and verify K-of-N attestation signatures", help: helpAttest, run: runAttest,
+			subcommands: []command{
+				{name: "add", summary: "Sign the bundle digest and append the signature", run: runAttestAdd},
+				{name: "verify", summary: "Check that at least k distinct signers signed the bundle", run: runAttestVerify,
+					exit1: "the bundle is INVALID: too few valid signatures, or the message does not match"},
+			}},
+		{name: "auth", summary: "Challenge-response login with FALCON keys", help: helpAuth, run: runAuth,
+			subcommands: []command{
+				{name: "challenge", summary: "Issue a challenge (service side)", run: runAuthChallenge},
+				{name: "respond", summary: "Sign a challenge (client side)", run: runAuthRespond},
+				{name: "verify", summary: "Check a response against the issued challenge (service side)", run: runAuthVerify,
+					exit1: "the response is INVALID"},
+			}},
+		{name: "vote", summary: "Sign and tally off-chain votes", help: helpVote, run: runVote,
+			subcommands: []command{
+				{name: "sign", summary: "Sign a vote and write the ballot", run: runVoteSign},
+				{name: "tally", summary: "Verify a directory of ballots and count the votes", run: runVoteTally,
+					exit1: "ballots were rejected or duplicated"},
+			}},
+		{name: "keys", summary: "Key file utilities", help: helpKeys, run: runKeys, subcommands: []command{
+			{name: "list", summary: "List the keys used on this machine, with their usage statistics", run: runKeysList},
+			{name: "canonicalize", summary: "Rewrite a key file in a byte-stable canonical JSON encoding",
+				run: runKeysCanonicalize, exit1: "--check: the file is not canonical"},
+			{name: "diff", summary: "Compare the material and metadata of two key files", args: "<a.json> <b.json>",
+				run:   runKeysDiff,
+				exit1: "the key files differ"},
+			{name: "check", summary: "Check that the public key (and mnemonic) of a key file match its private key",
+				run: runKeysCheck, exit1: "the key file is inconsistent"},
+			{name: "destroy", summary: "Overwrite a key file with zeros and delete it", run: runKeysDestroy,
+				exit1: "the confirmed fingerprint does not match; the key is not destroyed"},
+			{name: "add-passphrase-account", summary: "Record the account a passphrase opens from the mnemonic of a key file",
+				run: runKeysAddPassphraseAccount},
+			{name: "set-policy", summary: "Restrict the messages falcon sign signs with a key (regex, JSON Schema, size)",
+				run: runKeysSetPolicy},
+			{name: "totp-enroll", summary: "Require a TOTP code from an authenticator before a key signs on this machine",
+				run: runKeysTOTPEnroll, exit1: "the confirmation code is invalid; the key is not enrolled"},
+			{name: "totp-remove", summary: "Remove the TOTP second factor of a key, given a current code",
+				run: runKeysTOTPRemove, exit1: "the code is invalid; the second factor is kept"},
+		}},
+		{name: "revoke", summary: "Declare a key compromised with a self-signed revocation statement", help: helpRevoke,
+			run: runRevoke},
+		{name: "export-backup", summary: "Write an encrypted mnemonic-only backup of a keypair", help: helpExportBackup,
+			run: runExportBackup},
+		{name: "restore-backup", summary: "Recreate a keypair file from a backup", help: helpRestoreBackup,
+			run: runRestoreBackup},
+		{name: "export", summary: "Encrypt a key file to another custodian's wrap key", help: helpExport, run: runExport},
+		{name: "import", summary: "Decrypt a wrapped key file, or create wrap keys to receive one", help: helpImport,
+			run: runImport},
+		{name: "doctor", summary: "Check the environment and algod connectivity", help: helpDoctor, run: runDoctor,
+			exit1: "a check failed"},
+		{name: "version", summary: "Show the CLI build version", help: helpVersion, run: runVersion},
+		{name: "help", aliases: []string{"-h", "--help"}, summary: "Show help (general or for a command)", help: helpHelp,
+			args: "[<command> [<subcommand>]]", run: runHelp},
+	}
+}
+
+// helpTopics returns the help pages that are not commands.
+func helpTopics() []command {
+	return []command{
+		{name: "verify-stream", help: helpVerifyStream},
+		{name: "debug-bundle", help: helpDebugBundle},
+		{name: "read-only", help: helpReadOnly},
+	}
+}
+
+// globalFlag is a flag every command accepts, handled before dispatch.
+type globalFlag struct {
+	name  string
+	value string // placeholder of the value; empty for boolean flags
+	usage string
+}
+
+var globalFlags = []globalFlag{
+	{name: "debug-bundle", value: "file", usage: "Write a zip of diagnostics to attach to an issue"},
+	{name: "read-only", usage: "Disable signing and broadcasting (also $FALCON_READ_ONLY)"},
+	{name: "no-progress", usage: "Do not draw progress bars for long operations"},
+	{name: "insecure-permissions", usage: "Read private key files that other users can read"},
+}
+
+// findCommand returns the command called name (or one of its aliases) in
+// cmds.
+func findCommand(cmds []command, name string) (command, bool) {
+	for _, c := range cmds {
+		if c.name == name {
+			return c, true
+		}
+		for _, a := range c.aliases {
+			if a == name {
+				return c, true
+			}
+		}
+	}
+	return command{}, false
+}
+
+// runSubcommand dispatches the arguments of group to its subcommands. A
+// group nested in another, such as "algorand receipts", is given by its path
+// and shows the help of its top-level group.
+func runSubcommand(group string, args []string) int {
+	var c command
+	help := ""
+	cmds := commandRegistry()
+	for _, name := range strings.Fields(group) {
+		c, _ = findCommand(cmds, name)
+		cmds = c.subcommands
+		if c.help != "" {
+			help = c.help
+		}
+	}
+	names := make([]string, len(c.subcommands))
+	for i, sub := range c.subcommands {
+		names[i] = sub.name
+	}
+	usage := fmt.Sprintf("usage: falcon %s <%s> [flags]\n", group, strings.Join(names, "|"))
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		fmt.Fprintf(os.Stderr, "Run 'falcon help %s' for details.\n", group)
+		return 2
+	}
+	switch args[0] {
+	case "help", "-h", "--help":
+		fmt.Fprint(os.Stdout, help)
+		return 0
+	}
+	sub, ok := findCommand(c.subcommands, args[0])
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown %s subcommand: %s\n", group, args[0])
+		fmt.Fprint(os.Stderr, usage)
+		fmt.Fprintf(os.Stderr, "Run 'falcon help %s' for details.\n", group)
+		return 2
+	}
+	return sub.run(args[1:])
+}
+
+// describingFlags makes parseFlags stop the command it is called from by
+// panicking with the command's flag set, so that commandFlags can read the
+// flags of a command without running it.
+var describingFlags bool
+
+// describedFlags is the panic value of parseFlags while describingFlags.
+type describedFlags struct{ fs *flag.FlagSet }
+
+// parseFlags parses the flags of a command. Every command parses its flags
+// with it, before doing anything else, for 'falcon help --json'.
+func parseFlags(fs *flag.FlagSet, args []string) {
+	if describingFlags {
+		panic(describedFlags{fs})
+	}
+	_ = fs.Parse(args)
+}
+
+// commandFlags returns the flag set of a command that has no subcommands,
+// stopping it at parseFlags.
+func commandFlags(c command) (fs *flag.FlagSet) {
+	describingFlags = true
+	defer func() {
+		describingFlags = false
+		if r := recover(); r != nil {
+			d, ok := r.(describedFlags)
+			if !ok {
+				panic(r)
+			}
+			fs = d.fs
+		}
+	}()
+	c.run(nil)
+	return nil
+}
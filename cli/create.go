@@ -1,17 +1,24 @@
 package cli
 
 import (
+	"bufio"
+	"bytes"
 	"crypto/rand"
 	"crypto/sha512"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"strings"
 
+	"github.com/algorandfoundation/falcon-signatures/algorand"
 	"github.com/algorandfoundation/falcon-signatures/falcongo"
 	"github.com/algorandfoundation/falcon-signatures/mnemonic"
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/pbkdf2"
 )
 
@@ -21,9 +28,84 @@ func runCreate(args []string) int {
 	seedText := fs.String("seed", "", "generate deterministic keypair from seed/passphrase without mnemonic")
 	out := fs.String("out", "", "write keypair JSON to file (stdout if empty)")
 	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "optional mnemonic passphrase used for BIP-39 seed derivation")
+	promptPassphrase := fs.Bool("mnemonic-passphrase-prompt", false, "read the mnemonic passphrase from stdin, with strength check and confirmation")
 	noMnemonic := fs.Bool("no-mnemonic", false, "generate a random keypair without mnemonic (384-bit entropy)")
 	fromMnemonic := fs.String("from-mnemonic", "", "recover keypair from a 24-word BIP-39 mnemonic")
-	_ = fs.Parse(args)
+	deriveFrom := fs.String("derive-from", "", "derive a subkey from the master keypair JSON file (requires --label)")
+	label := fs.String("label", "", "purpose label of the subkey, e.g. payments, ci (with --derive-from)")
+	kdfName := fs.String("kdf", kdfPBKDF2, "KDF for --seed: "+kdfPBKDF2+" or "+kdfArgon2id)
+	kdfSalt := fs.String("kdf-salt", kdfSaltStr, "KDF salt for --seed")
+	kdfIters := fs.Uint("kdf-iterations", 0, "KDF iterations (argon2id: passes) for --seed (default: per KDF)")
+	kdfMemory := fs.Uint("kdf-memory", argon2DefaultMemoryKiB, "argon2id memory in KiB for --seed")
+	kdfThreads := fs.Uint("kdf-threads", argon2DefaultThreads, "argon2id parallelism for --seed")
+	kdfFrom := fs.String("kdf-from", "", "reuse the KDF parameters recorded in a key file for --seed")
+	allowWeakSeed := fs.Bool("allow-weak-seed", false, "accept a --seed below the minimum length/entropy estimate")
+	finalWord := fs.String("final-word", "", "retry new mnemonics until the last word is this BIP-39 word")
+	addressSuffix := fs.String("address-suffix", "", "retry new mnemonics until the Algorand address ends with these characters")
+	maxAttempts := fs.Uint64("max-attempts", defaultVanityAttempts, "give up --final-word/--address-suffix after this many mnemonics")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	kdfFlagSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+		if strings.HasPrefix(f.Name, "kdf") && f.Name != "kdf-from" {
+			kdfFlagSet = true
+		}
+	})
+	if *promptPassphrase && (passphraseProvided || *seedText != "" || *noMnemonic || *deriveFrom != "") {
+		fmt.Fprintln(os.Stderr,
+			"--mnemonic-passphrase-prompt cannot be combined with --mnemonic-passphrase, --seed, --no-mnemonic or --derive-from")
+		return 2
+	}
+	if *allowWeakSeed && *seedText == "" {
+		fmt.Fprintln(os.Stderr, "--allow-weak-seed requires --seed")
+		return 2
+	}
+	if (kdfFlagSet || *kdfFrom != "") && *seedText == "" {
+		fmt.Fprintln(os.Stderr, "--kdf flags require --seed")
+		return 2
+	}
+	vanity := *finalWord != "" || *addressSuffix != ""
+	if vanity && (*seedText != "" || *fromMnemonic != "" || *noMnemonic || *deriveFrom != "") {
+		fmt.Fprintln(os.Stderr,
+			"--final-word and --address-suffix cannot be combined with --seed, --from-mnemonic, --no-mnemonic or --derive-from")
+		return 2
+	}
+	if vanity && *maxAttempts == 0 {
+		fmt.Fprintln(os.Stderr, "--max-attempts must be > 0")
+		return 2
+	}
+	if kdfFlagSet && *kdfFrom != "" {
+		fmt.Fprintln(os.Stderr, "cannot combine --kdf-from with other --kdf flags")
+		return 2
+	}
+	if *kdfIters > math.MaxUint32 || *kdfMemory > math.MaxUint32 {
+		fmt.Fprintln(os.Stderr, "--kdf-iterations and --kdf-memory must fit in 32 bits")
+		return 2
+	}
+	if *kdfThreads < 1 || *kdfThreads > math.MaxUint8 {
+		fmt.Fprintln(os.Stderr, "--kdf-threads must be between 1 and 255")
+		return 2
+	}
+
+	if *deriveFrom != "" || *label != "" {
+		if *deriveFrom == "" || *label == "" {
+			fmt.Fprintln(os.Stderr, "--derive-from and --label must be used together")
+			return 2
+		}
+		if *seedText != "" || *fromMnemonic != "" || *noMnemonic {
+			fmt.Fprintln(os.Stderr,
+				"cannot combine --derive-from with --seed, --from-mnemonic or --no-mnemonic")
+			return 2
+		}
+		var override *string
+		if passphraseProvided {
+			override = mnemonicPassphrase
+		}
+		return createSubkey(*deriveFrom, override, *label, *out)
+	}
 
 	recoveryInput := strings.TrimSpace(*fromMnemonic)
 	if *seedText != "" && recoveryInput != "" {
@@ -48,10 +130,29 @@ func runCreate(args []string) int {
 	}
 
 	useMnemonic := !*noMnemonic && *seedText == "" && recoveryInput == ""
+	if *promptPassphrase {
+		pass, err := promptMnemonicPassphrase(useMnemonic)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		*mnemonicPassphrase = pass
+	} else if useMnemonic && *mnemonicPassphrase != "" {
+		if st := mnemonic.EstimatePassphraseStrength(*mnemonicPassphrase); st.Score < passphraseMinScore {
+			fmt.Fprintf(os.Stderr, "warning: weak mnemonic passphrase: %s\n", describeStrength(st))
+		}
+	}
+	if *seedText == "" && recoveryInput == "" {
+		if err := checkRNG(rand.Reader); err != nil {
+			fmt.Fprintf(os.Stderr, "refusing to generate keys: %v\n", err)
+			return 2
+		}
+	}
 
 	var kp falcongo.KeyPair
 	var err error
 	var words []string
+	var kdf *kdfParamsJSON
 	includeMnemonic := false
 
 	switch {
@@ -75,13 +176,57 @@ func runCreate(args []string) int {
 		}
 		includeMnemonic = !*noMnemonic
 	case *seedText != "":
-		if kp, err = falcongo.GenerateKeyPair(deriveSeed([]byte(*seedText))); err != nil {
+		bits := estimateSeedEntropy(*seedText)
+		fmt.Fprintf(os.Stderr, "seed entropy estimate: ~%.0f bits\n", bits)
+		if len([]rune(*seedText)) < minSeedLength || bits < minSeedEntropyBits {
+			if !*allowWeakSeed {
+				fmt.Fprintf(os.Stderr,
+					"refusing weak --seed (need at least %d characters and ~%d bits); "+
+						"use a longer passphrase, a mnemonic, or --allow-weak-seed\n",
+					minSeedLength, minSeedEntropyBits)
+				return 2
+			}
+			fmt.Fprintln(os.Stderr, "warning: weak --seed accepted because of --allow-weak-seed")
+		}
+		if *kdfFrom != "" {
+			kdf, err = kdfParamsFromFile(*kdfFrom)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to read --kdf-from: %v\n", err)
+				return 2
+			}
+		} else {
+			kdf = &kdfParamsJSON{
+				Version:    kdfEnvelopeVersion,
+				Name:       *kdfName,
+				Salt:       *kdfSalt,
+				Iterations: uint32(*kdfIters),
+			}
+			if *kdfName == kdfArgon2id {
+				kdf.MemoryKiB = uint32(*kdfMemory)
+				kdf.Threads = uint8(*kdfThreads)
+			}
+			if kdf.Iterations == 0 {
+				kdf.Iterations = defaultKDFIterations(*kdfName)
+			}
+		}
+		seed, err := deriveSeedKDF([]byte(*seedText), *kdf)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to derive seed: %v\n", err)
+			return 2
+		}
+		if kp, err = falcongo.GenerateKeyPair(seed); err != nil {
 			fmt.Fprintf(os.Stderr, "failed to generate keypair: %v\n", err)
 			return 2
 		}
 	case useMnemonic:
 		entropy := make([]byte, 32)
-		if _, err := rand.Read(entropy); err != nil {
+		if vanity {
+			if entropy, err = searchVanityEntropy(*finalWord, *addressSuffix, *mnemonicPassphrase,
+				*maxAttempts); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 2
+			}
+		} else if _, err := rand.Read(entropy); err != nil {
 			fmt.Fprintf(os.Stderr, "failed to read entropy: %v\n", err)
 			return 2
 		}
@@ -112,6 +257,7 @@ func runCreate(args []string) int {
 	obj := keyPairJSON{
 		PublicKey:  strings.ToLower(hex.EncodeToString(kp.PublicKey[:])),
 		PrivateKey: strings.ToLower(hex.EncodeToString(kp.PrivateKey[:])),
+		KDF:        kdf,
 	}
 	if includeMnemonic && len(words) > 0 {
 		obj.Mnemonic = strings.Join(words, " ")
@@ -119,30 +265,198 @@ func runCreate(args []string) int {
 			obj.MnemonicPassphrase = *mnemonicPassphrase
 		}
 	}
+	attestKeyFile(&obj, kp)
+	if code := writeKeypairOutput(obj, *out); code != 0 {
+		return code
+	}
+	recordKeyCreated(kp.PublicKey[:], *out)
+	return 0
+}
+
+// createSubkey derives the subkey for label from the master keypair file and
+// writes it like a newly created keypair. The subkey has no mnemonic of its
+// own: it is recovered from the master and the label.
+func createSubkey(masterPath string, override *string, label, out string) int {
+	pub, priv, _, err := loadKeypairFile(masterPath, override)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --derive-from: %v\n", err)
+		return 2
+	}
+	if priv == nil {
+		fmt.Fprintf(os.Stderr, "private key not found in %s (required for derivation)\n", masterPath)
+		return 2
+	}
+	var master falcongo.KeyPair
+	copy(master.PublicKey[:], pub)
+	copy(master.PrivateKey[:], priv)
+	kp, err := falcongo.DeriveSubkey(master, label)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to derive subkey: %v\n", err)
+		return 2
+	}
+	obj := keyPairJSON{
+		PublicKey:  strings.ToLower(hex.EncodeToString(kp.PublicKey[:])),
+		PrivateKey: strings.ToLower(hex.EncodeToString(kp.PrivateKey[:])),
+	}
+	attestKeyFile(&obj, kp)
+	code := writeKeypairOutput(obj, out)
+	if code == 0 {
+		recordKeyCreated(kp.PublicKey[:], out)
+	}
+	return code
+}
+
+// writeKeypairOutput writes the keypair JSON to out (0600) or to stdout.
+func writeKeypairOutput(obj keyPairJSON, out string) int {
+	stampKeyFile(&obj)
 	data, err := json.MarshalIndent(obj, "", "  ")
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to encode keypair JSON: %v\n", err)
 		return 2
 	}
 
-	if *out == "" {
+	if out == "" {
 		if _, err := os.Stdout.Write(append(data, '\n')); err != nil {
 			fmt.Fprintf(os.Stderr, "failed to write keypair JSON: %v\n", err)
 			return 2
 		}
 	} else {
-		if err := writeFileAtomic(*out, data, 0o600); err != nil {
-			fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *out, err)
+		if err := writeFileAtomic(out, data, 0o600); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", out, err)
 			return 2
 		}
 	}
 	return 0
 }
 
+// defaultVanityAttempts bounds the mnemonics tried for --final-word and
+// --address-suffix: about 50 times the 2048 expected for a final word, and
+// enough for a 3-character address suffix.
+const defaultVanityAttempts = 100000
+
+// base32Alphabet is the alphabet of Algorand addresses.
+const base32Alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+
+// searchVanityEntropy draws random mnemonics until one ends with finalWord
+// and, with passphrase, gives an Algorand address ending with addressSuffix
+// (either may be empty). It reports the attempts and the entropy given up on
+// stderr.
+func searchVanityEntropy(finalWord, addressSuffix, passphrase string, maxAttempts uint64) ([]byte, error) {
+	finalWord = strings.ToLower(strings.TrimSpace(finalWord))
+	addressSuffix = strings.ToUpper(strings.TrimSpace(addressSuffix))
+	matchWord := func([]string) bool { return true }
+	lostBits := 0
+	if finalWord != "" {
+		m, err := mnemonic.FinalWordMatcher(finalWord)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --final-word: %w", err)
+		}
+		matchWord, lostBits = m, 11
+	}
+	for _, c := range addressSuffix {
+		if !strings.ContainsRune(base32Alphabet, c) {
+			return nil, fmt.Errorf("invalid --address-suffix: %q is not in the address alphabet (A-Z, 2-7)", c)
+		}
+	}
+	lostBits += 5 * len(addressSuffix)
+
+	var searchErr error
+	bar := startProgress("searching mnemonics", 0, maxAttempts)
+	entropy, attempts, err := mnemonic.SearchEntropy(rand.Reader, maxAttempts, func(phrase []string) bool {
+		bar.add(1)
+		if !matchWord(phrase) {
+			return false
+		}
+		if addressSuffix == "" {
+			return true
+		}
+		seed, err := mnemonic.SeedFromMnemonic(phrase, passphrase)
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		kp, err := falcongo.GenerateKeyPair(seed[:])
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		address, err := algorand.GetAddressFromPublicKey(kp.PublicKey)
+		// Keys without an address never match.
+		return err == nil && strings.HasSuffix(string(address), addressSuffix)
+	})
+	bar.finish()
+	if err == nil {
+		err = searchErr
+	}
+	if errors.Is(err, mnemonic.ErrSearchExhausted) {
+		return nil, fmt.Errorf("no matching mnemonic in %d attempts; raise --max-attempts or relax the pattern",
+			attempts)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to search mnemonics: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "found a matching mnemonic after %d attempts (~%d bits of entropy given up)\n",
+		attempts, lostBits)
+	return entropy, nil
+}
+
+// passphraseMinScore is the mnemonic.EstimatePassphraseStrength score below
+// which a new mnemonic passphrase is reported as weak.
+const passphraseMinScore = 2
+
+// describeStrength formats a passphrase strength estimate for stderr.
+func describeStrength(st mnemonic.PassphraseStrength) string {
+	s := fmt.Sprintf("strength %d/4 (~%.0f bits)", st.Score, st.Bits)
+	if st.Warning != "" {
+		s += ", " + st.Warning
+	}
+	return s
+}
+
+// promptMnemonicPassphrase reads a mnemonic passphrase and its confirmation
+// from stdin. When creating a new mnemonic, it also reports the estimated
+// strength and warns that the passphrase is needed for recovery.
+func promptMnemonicPassphrase(creating bool) (string, error) {
+	r := bufio.NewReader(os.Stdin)
+	readLine := func(prompt string) (string, error) {
+		fmt.Fprint(os.Stderr, prompt)
+		line, err := r.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return "", fmt.Errorf("\nfailed to read mnemonic passphrase: %w", err)
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+	pass, err := readLine("mnemonic passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if pass == "" {
+		return "", fmt.Errorf("empty mnemonic passphrase; omit --mnemonic-passphrase-prompt to use none")
+	}
+	if creating {
+		st := mnemonic.EstimatePassphraseStrength(pass)
+		label := "passphrase"
+		if st.Score < passphraseMinScore {
+			label = "WEAK passphrase"
+		}
+		fmt.Fprintf(os.Stderr, "%s: %s\n", label, describeStrength(st))
+		fmt.Fprintln(os.Stderr, "WARNING: the mnemonic alone cannot recover these keys; losing the passphrase")
+		fmt.Fprintln(os.Stderr, "makes the keys and any funds they control unrecoverable.")
+	}
+	confirm, err := readLine("repeat the mnemonic passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if !falcongo.SecretsEqual([]byte(pass), []byte(confirm)) {
+		return "", fmt.Errorf("mnemonic passphrases do not match")
+	}
+	return pass, nil
+}
+
 // Seed derivation parameters from user seedphrase
 const (
 	kdfIterations         = 100000
-	kdfKeyLen             = 48
+	kdfKeyLen             = falcongo.SeedSize
 	kdfSaltStr            = "falcon-cli-seed-v1"
 	expectedMnemonicWords = 24
 )
@@ -152,6 +466,155 @@ func deriveSeed(b []byte) []byte {
 	return pbkdf2.Key(b, []byte(kdfSaltStr), kdfIterations, kdfKeyLen, sha512.New)
 }
 
+// Versioned --seed derivation. The parameters are recorded in the key file so
+// the keypair can be re-derived after the defaults change.
+const (
+	kdfEnvelopeVersion = 1
+	kdfPBKDF2          = "pbkdf2-sha512"
+	kdfArgon2id        = "argon2id"
+
+	argon2DefaultIterations = 3
+	argon2DefaultMemoryKiB  = 64 * 1024
+	argon2DefaultThreads    = 4
+)
+
+// kdfParamsJSON is the derivation envelope stored under "kdf" in key files.
+// Fields are in sorted order to keep the canonical encoding sorted.
+type kdfParamsJSON struct {
+	Iterations uint32 `json:"iterations"`
+	MemoryKiB  uint32 `json:"memory_kib,omitempty"`
+	Name       string `json:"name"`
+	Salt       string `json:"salt"`
+	Threads    uint8  `json:"threads,omitempty"`
+	Version    int    `json:"version"`
+}
+
+// defaultKDFIterations returns the default iteration count for a KDF.
+func defaultKDFIterations(name string) uint32 {
+	if name == kdfArgon2id {
+		return argon2DefaultIterations
+	}
+	return kdfIterations
+}
+
+// deriveSeedKDF maps a text seed to a 48-byte keygen seed using the KDF and
+// parameters of p.
+func deriveSeedKDF(b []byte, p kdfParamsJSON) ([]byte, error) {
+	if p.Version != kdfEnvelopeVersion {
+		return nil, fmt.Errorf("unsupported kdf version %d", p.Version)
+	}
+	if p.Iterations == 0 {
+		return nil, fmt.Errorf("kdf iterations must be > 0")
+	}
+	switch p.Name {
+	case kdfPBKDF2:
+		return pbkdf2.Key(b, []byte(p.Salt), int(p.Iterations), kdfKeyLen, sha512.New), nil
+	case kdfArgon2id:
+		if p.Threads == 0 {
+			return nil, fmt.Errorf("argon2id threads must be between 1 and 255")
+		}
+		if p.MemoryKiB < 8*uint32(p.Threads) {
+			return nil, fmt.Errorf("argon2id memory must be at least 8 KiB per thread")
+		}
+		return argon2.IDKey(b, []byte(p.Salt), p.Iterations, p.MemoryKiB, p.Threads, kdfKeyLen), nil
+	default:
+		return nil, fmt.Errorf("unknown kdf %q (valid: %s, %s)", p.Name, kdfPBKDF2, kdfArgon2id)
+	}
+}
+
+// kdfParamsFromFile returns the KDF parameters recorded in a key file.
+func kdfParamsFromFile(path string) (*kdfParamsJSON, error) {
+	b, err := readKeySource(path)
+	if err != nil {
+		return nil, err
+	}
+	var meta keyPairJSON
+	if err := json.Unmarshal(b, &meta); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if meta.KDF == nil {
+		return nil, fmt.Errorf("no kdf parameters in %s", path)
+	}
+	return meta.KDF, nil
+}
+
+// Minimum --seed quality accepted without --allow-weak-seed.
+const (
+	minSeedLength      = 12
+	minSeedEntropyBits = 50
+)
+
+// estimateSeedEntropy returns a rough estimate, in bits, of the entropy of a
+// text seed: the smaller of the character-pool estimate (length times log2 of
+// the size of the character classes used) and the Shannon estimate (length
+// times the empirical per-character entropy), so both short seeds and seeds
+// made of repeated characters score low. It is an upper bound for seeds a
+// human picked, not a guarantee.
+func estimateSeedEntropy(seed string) float64 {
+	runes := []rune(seed)
+	if len(runes) == 0 {
+		return 0
+	}
+	var lower, upper, digit, space, symbol, other bool
+	counts := make(map[rune]int)
+	for _, r := range runes {
+		counts[r]++
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r == ' ':
+			space = true
+		case r < 0x80:
+			symbol = true
+		default:
+			other = true
+		}
+	}
+	pool := 0
+	for _, c := range []struct {
+		used bool
+		size int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {space, 1}, {symbol, 32}, {other, 100}} {
+		if c.used {
+			pool += c.size
+		}
+	}
+	n := float64(len(runes))
+	poolBits := n * math.Log2(float64(pool))
+	shannon := 0.0
+	for _, c := range counts {
+		p := float64(c) / n
+		shannon -= p * math.Log2(p)
+	}
+	return math.Min(poolBits, n*shannon)
+}
+
+// checkRNG is a basic health probe of the random source used for key
+// generation: two consecutive reads must succeed, differ, and not be a single
+// repeated byte. It catches a broken or stubbed RNG, not a subtly biased one.
+func checkRNG(r io.Reader) error {
+	var a, b [32]byte
+	if _, err := io.ReadFull(r, a[:]); err != nil {
+		return fmt.Errorf("random source failed: %w", err)
+	}
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return fmt.Errorf("random source failed: %w", err)
+	}
+	if a == b {
+		return fmt.Errorf("random source failed health check: repeated output")
+	}
+	for _, buf := range [][32]byte{a, b} {
+		if bytes.Count(buf[:], buf[:1]) == len(buf) {
+			return fmt.Errorf("random source failed health check: constant output")
+		}
+	}
+	return nil
+}
+
 const helpCreate = `# falcon create
 
 Generate a new FALCON-1024 keypair.
@@ -161,19 +624,48 @@ Modes:
   --no-mnemonic               generate a random keypair without a mnemonic (384-bit entropy)
   --seed <text>               deterministically derive the keypair from a text seed
                                 (entropy depends on text seed; USE WITH CAUTION)
+                                the estimated entropy is printed to stderr and weak seeds are refused
+                                the KDF parameters are recorded under "kdf" in the key JSON
   --from-mnemonic <24 words>  recover the keypair from a 24-word BIP-39 mnemonic
+  --derive-from <file> --label <label>
+                              derive a per-purpose subkey (e.g. payments, ci) from a master keypair;
+                                the same master and label always give the same subkey
 
 Options:
   --out <file>                write keypair JSON (stdout if omitted)
+  --kdf <name>                KDF for --seed: pbkdf2-sha512 (default) or argon2id
+  --kdf-salt <string>         KDF salt for --seed (default: falcon-cli-seed-v1)
+  --kdf-iterations <n>        iterations (argon2id: passes); default 100000 (pbkdf2-sha512) or 3 (argon2id)
+  --kdf-memory <KiB>          argon2id memory (default: 65536)
+  --kdf-threads <n>           argon2id parallelism (default: 4)
+  --kdf-from <file>           reuse the KDF parameters recorded in a key file
+  --final-word <word>         in default mode, draw new mnemonics until the last word is this BIP-39 word
+                                (about 2048 tries; the phrase keeps ~245 of its 256 bits of entropy)
+  --address-suffix <chars>    in default mode, draw new mnemonics until the Algorand address of the key
+                                ends with these characters (A-Z, 2-7; ~32^n tries of a full key
+                                generation each, and ~5 bits of entropy given up per character)
+  --max-attempts <n>          give up --final-word/--address-suffix after n mnemonics (default: 100000)
+  --allow-weak-seed           accept a --seed shorter than 12 characters or estimated below ~50 bits
   --mnemonic-passphrase <string>
                               optional BIP-39 passphrase mixed into seed derivation (stored in JSON when provided);
-                                use with default mode or --from-mnemonic
+                                use with default mode or --from-mnemonic; with --derive-from it unlocks a
+                                mnemonic-only master file instead; a weak new passphrase is reported on stderr
+  --mnemonic-passphrase-prompt
+                              read the mnemonic passphrase from stdin instead, then ask for it again to confirm;
+                                in default mode its estimated strength is shown with a warning that it is
+                                required for recovery
 
 Examples:
   falcon create
   falcon create --out mykeys.json
   falcon create --mnemonic-passphrase "TREZOR" --out mykeys.json
+  falcon create --mnemonic-passphrase-prompt --out mykeys.json
   falcon create --no-mnemonic --out mykeys.json
+  falcon create --final-word zoo --out mykeys.json
+  falcon create --address-suffix PQ --out mykeys.json
   falcon create --seed "my 12 word seed phrase ..."
+  falcon create --seed "my 12 word seed phrase ..." --kdf argon2id --out mykeys.json
+  falcon create --seed "my 12 word seed phrase ..." --kdf-from mykeys.json
   falcon create --from-mnemonic "abandon abandon ... art" --mnemonic-passphrase "TREZOR"
+  falcon create --derive-from master.json --label ci --out ci.json
 `
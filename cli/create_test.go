@@ -2,6 +2,7 @@ package cli
 
 import (
 	"bytes"
+	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
 	"io"
@@ -10,10 +11,29 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/algorandfoundation/falcon-signatures/algorand"
 	"github.com/algorandfoundation/falcon-signatures/falcongo"
 	"github.com/algorandfoundation/falcon-signatures/mnemonic"
 )
 
+// withStdin runs fn with os.Stdin reading input.
+func withStdin(t *testing.T, input string, fn func()) {
+	t.Helper()
+	in := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(in, []byte(input), 0o600); err != nil {
+		t.Fatalf("write stdin: %v", err)
+	}
+	f, err := os.Open(in)
+	if err != nil {
+		t.Fatalf("open stdin: %v", err)
+	}
+	defer f.Close()
+	orig := os.Stdin
+	os.Stdin = f
+	defer func() { os.Stdin = orig }()
+	fn()
+}
+
 // captureStdout captures os.Stdout output produced by fn and returns it as a string.
 func captureStdout(t *testing.T, fn func()) string {
 	t.Helper()
@@ -354,3 +374,293 @@ func TestRunCreate_OutDirMissing_Returns2AndStderr(t *testing.T) {
 		t.Fatalf("expected error about failed to write, got: %q", errOut)
 	}
 }
+
+// TestRunCreate_DeriveFrom checks subkeys are deterministic per label.
+func TestRunCreate_DeriveFrom(t *testing.T) {
+	dir := t.TempDir()
+	master, err := falcongo.GenerateKeyPair(deriveSeed([]byte("master")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	masterPath := writeKeypairJSON(t, dir, "master.json", master, true)
+
+	derive := func(label string) string {
+		t.Helper()
+		var code int
+		out := captureStdout(t, func() {
+			code = runCreate([]string{"--derive-from", masterPath, "--label", label})
+		})
+		if code != 0 {
+			t.Fatalf("expected exit 0, got %d", code)
+		}
+		return out
+	}
+	// The outputs differ in their key_attestation creation time only.
+	ci := derive("ci")
+	obj1, obj2 := decodeKeyJSON(t, ci), decodeKeyJSON(t, derive("ci"))
+	if obj1.PublicKey != obj2.PublicKey || obj1.PrivateKey != obj2.PrivateKey {
+		t.Fatalf("subkey derivation is not deterministic")
+	}
+	if decodeKeyJSON(t, derive("payments")).PublicKey == obj1.PublicKey {
+		t.Fatalf("different labels must give different subkeys")
+	}
+	want, err := falcongo.DeriveSubkey(master, "ci")
+	if err != nil {
+		t.Fatalf("DeriveSubkey failed: %v", err)
+	}
+	if !strings.Contains(ci, hex.EncodeToString(want.PublicKey[:])) {
+		t.Fatalf("CLI output does not match DeriveSubkey")
+	}
+}
+
+// TestRunCreate_DeriveFromRequiresLabel ensures --label is mandatory.
+func TestRunCreate_DeriveFromRequiresLabel(t *testing.T) {
+	var code int
+	_, stderr := captureStdoutStderr(t, func() {
+		code = runCreate([]string{"--derive-from", "master.json"})
+	})
+	if code != 2 || !strings.Contains(stderr, "--derive-from and --label must be used together") {
+		t.Fatalf("expected usage error, got %d %q", code, stderr)
+	}
+}
+
+// TestRunCreate_SeedKDFEnvelope checks the default --seed derivation is
+// unchanged and recorded, and that recorded argon2id parameters re-derive the
+// same keypair with --kdf-from.
+func TestRunCreate_SeedKDFEnvelope(t *testing.T) {
+	dir := t.TempDir()
+	seed := "kdf envelope seed"
+
+	defaultPath := filepath.Join(dir, "default.json")
+	if code := runCreate([]string{"--seed", seed, "--out", defaultPath}); code != 0 {
+		t.Fatalf("expected exit 0, got %d", code)
+	}
+	var def keyPairJSON
+	readJSONFile(t, defaultPath, &def)
+	want, err := falcongo.GenerateKeyPair(deriveSeed([]byte(seed)))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	if def.PublicKey != hex.EncodeToString(want.PublicKey[:]) {
+		t.Fatalf("default --seed derivation changed")
+	}
+	if def.KDF == nil || *def.KDF != (kdfParamsJSON{Version: 1, Name: kdfPBKDF2,
+		Salt: kdfSaltStr, Iterations: kdfIterations}) {
+		t.Fatalf("unexpected kdf envelope: %+v", def.KDF)
+	}
+
+	argonPath := filepath.Join(dir, "argon.json")
+	if code := runCreate([]string{"--seed", seed, "--kdf", kdfArgon2id, "--kdf-memory", "1024",
+		"--kdf-threads", "1", "--kdf-salt", "custom salt", "--out", argonPath}); code != 0 {
+		t.Fatalf("expected exit 0, got %d", code)
+	}
+	var argon keyPairJSON
+	readJSONFile(t, argonPath, &argon)
+	if argon.PublicKey == def.PublicKey {
+		t.Fatalf("argon2id must derive a different keypair")
+	}
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runCreate([]string{"--seed", seed, "--kdf-from", argonPath})
+	})
+	if code != 0 {
+		t.Fatalf("expected exit 0, got %d", code)
+	}
+	var again keyPairJSON
+	if err := json.Unmarshal([]byte(out), &again); err != nil {
+		t.Fatalf("decode output: %v", err)
+	}
+	if again.PublicKey != argon.PublicKey || *again.KDF != *argon.KDF {
+		t.Fatalf("--kdf-from did not re-derive the same keypair")
+	}
+}
+
+// TestRunCreate_KDFFlagsRequireSeed rejects KDF flags outside --seed mode.
+func TestRunCreate_KDFFlagsRequireSeed(t *testing.T) {
+	var code int
+	errOut := captureStderr(t, func() { code = runCreate([]string{"--kdf", kdfArgon2id}) })
+	if code != 2 || !strings.Contains(errOut, "--kdf flags require --seed") {
+		t.Fatalf("expected usage error, got %d %q", code, errOut)
+	}
+}
+
+func readJSONFile(t *testing.T, path string, v any) {
+	t.Helper()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		t.Fatalf("decode %s: %v", path, err)
+	}
+}
+
+// TestRunCreate_WeakSeedRefused checks that short or low-entropy seeds are
+// refused unless --allow-weak-seed is given, and that the estimate is shown.
+func TestRunCreate_WeakSeedRefused(t *testing.T) {
+	for _, seed := range []string{"hello", "password1234", "aaaaaaaaaaaaaaaaaaaaaaaa"} {
+		var code int
+		errOut := captureStderr(t, func() {
+			_ = captureStdout(t, func() { code = runCreate([]string{"--seed", seed}) })
+		})
+		if code != 2 {
+			t.Fatalf("%q: expected exit 2, got %d", seed, code)
+		}
+		if !strings.Contains(errOut, "seed entropy estimate") || !strings.Contains(errOut, "refusing weak --seed") {
+			t.Fatalf("%q: unexpected stderr: %q", seed, errOut)
+		}
+	}
+
+	var code int
+	var out string
+	errOut := captureStderr(t, func() {
+		out = captureStdout(t, func() { code = runCreate([]string{"--seed", "hello", "--allow-weak-seed"}) })
+	})
+	if code != 0 {
+		t.Fatalf("expected exit 0 with --allow-weak-seed, got %d (%s)", code, errOut)
+	}
+	if !strings.Contains(errOut, "warning: weak --seed") || !strings.Contains(out, "public_key") {
+		t.Fatalf("unexpected output: stdout=%q stderr=%q", out, errOut)
+	}
+}
+
+// TestRunCreate_AllowWeakSeedRequiresSeed ensures the override is only
+// accepted together with --seed.
+func TestRunCreate_AllowWeakSeedRequiresSeed(t *testing.T) {
+	var code int
+	errOut := captureStderr(t, func() { code = runCreate([]string{"--allow-weak-seed"}) })
+	if code != 2 || !strings.Contains(errOut, "--allow-weak-seed requires --seed") {
+		t.Fatalf("unexpected result: code=%d stderr=%q", code, errOut)
+	}
+}
+
+// TestEstimateSeedEntropy checks the estimate orders seeds sensibly.
+func TestEstimateSeedEntropy(t *testing.T) {
+	if got := estimateSeedEntropy(""); got != 0 {
+		t.Fatalf("empty seed: expected 0, got %v", got)
+	}
+	if got := estimateSeedEntropy("zzzzzzzzzzzzzzzzzzzz"); got != 0 {
+		t.Fatalf("repeated character: expected 0, got %v", got)
+	}
+	weak := estimateSeedEntropy("password")
+	strong := estimateSeedEntropy("correct horse battery staple")
+	if weak >= minSeedEntropyBits || strong < minSeedEntropyBits {
+		t.Fatalf("unexpected estimates: weak=%v strong=%v", weak, strong)
+	}
+}
+
+// TestCheckRNG checks the RNG health probe rejects broken sources.
+func TestCheckRNG(t *testing.T) {
+	if err := checkRNG(rand.Reader); err != nil {
+		t.Fatalf("crypto/rand failed health check: %v", err)
+	}
+	if err := checkRNG(bytes.NewReader(make([]byte, 64))); err == nil {
+		t.Fatal("expected constant source to fail")
+	}
+	pattern := bytes.Repeat([]byte("0123456789abcdef0123456789abcdef"), 2)
+	if err := checkRNG(bytes.NewReader(pattern)); err == nil {
+		t.Fatal("expected repeating source to fail")
+	}
+	if err := checkRNG(bytes.NewReader(make([]byte, 10))); err == nil {
+		t.Fatal("expected short source to fail")
+	}
+}
+
+// TestRunCreate_MnemonicPassphrasePrompt reads the passphrase from stdin,
+// reports its strength and requires a matching confirmation.
+func TestRunCreate_MnemonicPassphrasePrompt(t *testing.T) {
+	dir := t.TempDir()
+	outPath := filepath.Join(dir, "keys.json")
+	var code int
+	withStdin(t, "x\nx\n", func() {
+		stderr := captureStderr(t, func() {
+			code = runCreate([]string{"--mnemonic-passphrase-prompt", "--out", outPath})
+		})
+		if !strings.Contains(stderr, "WEAK passphrase: strength 0/4") ||
+			!strings.Contains(stderr, "unrecoverable") {
+			t.Fatalf("expected weak passphrase warning, got %q", stderr)
+		}
+	})
+	if code != 0 {
+		t.Fatalf("create failed with %d", code)
+	}
+	var obj keyPairJSON
+	readJSONFile(t, outPath, &obj)
+	if obj.MnemonicPassphrase != "x" {
+		t.Fatalf("expected passphrase to be stored, got %q", obj.MnemonicPassphrase)
+	}
+
+	withStdin(t, "kX9#mQ2$vL7!pR4@\nkX9#mQ2$vL7!pR4\n", func() {
+		stderr := captureStderr(t, func() {
+			code = runCreate([]string{"--mnemonic-passphrase-prompt"})
+		})
+		if code != 2 || !strings.Contains(stderr, "do not match") {
+			t.Fatalf("expected mismatch error, got %d %q", code, stderr)
+		}
+	})
+
+	stderr := captureStderr(t, func() {
+		code = runCreate([]string{"--mnemonic-passphrase-prompt", "--mnemonic-passphrase", "x"})
+	})
+	if code != 2 || !strings.Contains(stderr, "cannot be combined") {
+		t.Fatalf("expected usage error, got %d %q", code, stderr)
+	}
+
+	_ = captureStdout(t, func() {
+		stderr = captureStderr(t, func() {
+			code = runCreate([]string{"--mnemonic-passphrase", "1"})
+		})
+	})
+	if code != 0 || !strings.Contains(stderr, "warning: weak mnemonic passphrase") {
+		t.Fatalf("expected weak passphrase warning, got %d %q", code, stderr)
+	}
+}
+
+// TestRunCreate_Vanity checks --final-word and --address-suffix constrain the
+// new mnemonic and address.
+func TestRunCreate_Vanity(t *testing.T) {
+	var code int
+	stdout, stderr := captureStdoutStderr(t, func() {
+		code = runCreate([]string{"--final-word", "Zoo", "--address-suffix", "q"})
+	})
+	if code != 0 {
+		t.Fatalf("expected exit 0, got %d (stderr %q)", code, stderr)
+	}
+	if !strings.Contains(stderr, "found a matching mnemonic after") || !strings.Contains(stderr, "~16 bits") {
+		t.Fatalf("unexpected stderr: %q", stderr)
+	}
+	obj := decodeKeyJSON(t, stdout)
+	words := strings.Fields(obj.Mnemonic)
+	if len(words) != 24 || words[23] != "zoo" {
+		t.Fatalf("expected a mnemonic ending with zoo, got %q", obj.Mnemonic)
+	}
+	var pk falcongo.PublicKey
+	pub, _ := parseHex(obj.PublicKey)
+	copy(pk[:], pub)
+	address, err := algorand.GetAddressFromPublicKey(pk)
+	if err != nil || !strings.HasSuffix(string(address), "Q") {
+		t.Fatalf("expected an address ending with Q, got %s (%v)", address, err)
+	}
+}
+
+// TestRunCreate_VanityErrors covers invalid vanity flags and exhaustion.
+func TestRunCreate_VanityErrors(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--final-word", "zoo", "--no-mnemonic"}, "cannot be combined"},
+		{[]string{"--address-suffix", "A", "--seed", "correct horse battery staple"}, "cannot be combined"},
+		{[]string{"--final-word", "notaword"}, "invalid --final-word"},
+		{[]string{"--address-suffix", "a1"}, "invalid --address-suffix"},
+		{[]string{"--final-word", "zoo", "--max-attempts", "0"}, "--max-attempts must be > 0"},
+		{[]string{"--address-suffix", "AAAAAAAA", "--max-attempts", "2"}, "no matching mnemonic in 2 attempts"},
+	} {
+		var code int
+		stdout, stderr := captureStdoutStderr(t, func() { code = runCreate(tc.args) })
+		if code != 2 || stdout != "" || !strings.Contains(stderr, tc.want) {
+			t.Fatalf("%q: got exit %d, stderr %q; want %q", tc.args, code, stderr, tc.want)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// csrJSON is a PKCS#10-like certification request binding a FALCON public key
+// to subject information, self-signed by the matching private key.
+type csrJSON struct {
+	Version    int                `json:"version"`
+	Algorithm  string             `json:"algorithm"`
+	Subject    string             `json:"subject"`
+	Attributes map[string]string  `json:"attributes,omitempty"`
+	PublicKey  string             `json:"public_key"`
+	Signature  falcongo.Signature `json:"signature"`
+}
+
+const (
+	csrVersion   = 1
+	csrAlgorithm = "falcon-1024"
+	csrDomain    = "falcon-csr-v1"
+)
+
+// csrSigningBytes returns the byte string covered by the CSR signature: the
+// domain tag followed by length-prefixed subject, public key, and attributes
+// sorted by name.
+func csrSigningBytes(c csrJSON, pub []byte) []byte {
+	var out []byte
+	appendField := func(b []byte) {
+		out = binary.BigEndian.AppendUint32(out, uint32(len(b)))
+		out = append(out, b...)
+	}
+	appendField([]byte(csrDomain))
+	appendField([]byte(c.Algorithm))
+	appendField([]byte(c.Subject))
+	appendField(pub)
+	names := make([]string, 0, len(c.Attributes))
+	for name := range c.Attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out = binary.BigEndian.AppendUint32(out, uint32(len(names)))
+	for _, name := range names {
+		appendField([]byte(name))
+		appendField([]byte(c.Attributes[name]))
+	}
+	return out
+}
+
+// ---- csr dispatcher ----
+func runCSR(args []string) int {
+	return runSubcommand("csr", args)
+}
+
+// ---- csr create ----
+func runCSRCreate(args []string) int {
+	fs := flag.NewFlagSet("csr create", flag.ExitOnError)
+	keyPath := fs.String("key", "", "path to keypair JSON file")
+	subject := fs.String("subject", "", "subject distinguished name, e.g. \"CN=alice,O=Example\"")
+	out := fs.String("out", "", "write CSR JSON to file (stdout if empty)")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	attrs := map[string]string{}
+	fs.Func("attr", "extra attribute as name=value (repeatable)", func(s string) error {
+		name, value, ok := strings.Cut(s, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return fmt.Errorf("expected name=value, got %q", s)
+		}
+		if _, dup := attrs[name]; dup {
+			return fmt.Errorf("duplicate attribute %q", name)
+		}
+		attrs[name] = value
+		return nil
+	})
+	parseFlags(fs, args)
+	passphraseProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+	})
+
+	if *keyPath == "" {
+		fmt.Fprintf(os.Stderr, "--key is required\n")
+		return 2
+	}
+	if strings.TrimSpace(*subject) == "" {
+		fmt.Fprintf(os.Stderr, "--subject is required\n")
+		return 2
+	}
+
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	kp, code := loadSigningKeyPair("--key", *keyPath, override, "signing the request")
+	if code != 0 {
+		return code
+	}
+	pub := kp.PublicKey[:]
+
+	req := csrJSON{
+		Version:   csrVersion,
+		Algorithm: csrAlgorithm,
+		Subject:   strings.TrimSpace(*subject),
+		PublicKey: strings.ToLower(hex.EncodeToString(pub)),
+	}
+	if len(attrs) > 0 {
+		req.Attributes = attrs
+	}
+
+	sig, err := kp.Sign(csrSigningBytes(req, pub))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "signing failed: %v\n", err)
+		return 2
+	}
+	if req.Signature, err = falcongo.NewSignature(sig); err != nil {
+		fmt.Fprintf(os.Stderr, "signing failed: %v\n", err)
+		return 2
+	}
+
+	data, err := json.MarshalIndent(req, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode CSR JSON: %v\n", err)
+		return 2
+	}
+	if *out == "" {
+		if _, err := os.Stdout.Write(append(data, '\n')); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write CSR JSON: %v\n", err)
+			return 2
+		}
+	} else if err := writeFileAtomic(*out, data, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *out, err)
+		return 2
+	}
+	recordKeyUse(pub, *keyPath, "csr create", "", 1)
+	return 0
+}
+
+// ---- csr verify ----
+func runCSRVerify(args []string) int {
+	fs := flag.NewFlagSet("csr verify", flag.ExitOnError)
+	inFile := fs.String("in", "", "path to CSR JSON file")
+	parseFlags(fs, args)
+
+	if *inFile == "" {
+		fmt.Fprintf(os.Stderr, "--in is required\n")
+		return 2
+	}
+	b, err := os.ReadFile(*inFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --in: %v\n", err)
+		return 2
+	}
+	var req csrJSON
+	if err := json.Unmarshal(b, &req); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid CSR JSON: %v\n", err)
+		return 2
+	}
+	if req.Version != csrVersion || req.Algorithm != csrAlgorithm {
+		fmt.Fprintf(os.Stderr, "unsupported CSR version %d / algorithm %q\n",
+			req.Version, req.Algorithm)
+		return 2
+	}
+	pub, err := parseHex(req.PublicKey)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid public_key hex: %v\n", err)
+		return 2
+	}
+	if len(pub) != falcongo.PublicKeySize {
+		fmt.Fprintf(os.Stderr, "invalid public_key length: %d\n", len(pub))
+		return 2
+	}
+	var pk falcongo.PublicKey
+	copy(pk[:], pub)
+	if err := req.Signature.Verify(csrSigningBytes(req, pub), pk); err != nil {
+		fmt.Fprintln(os.Stdout, "INVALID")
+		return 1
+	}
+	fmt.Fprintln(os.Stdout, "VALID")
+	fmt.Fprintf(os.Stdout, "subject: %s\n", req.Subject)
+	return 0
+}
+
+const helpCSR = `# falcon csr
+
+Create and verify certification requests for FALCON-1024 keys.
+
+A CSR binds a public key to subject information and is self-signed by the
+matching private key, proving possession of the key to the issuing PKI.
+
+Usage:
+  falcon csr create --key <file> --subject <dn> [--attr name=value ...] [--out <file>] [--mnemonic-passphrase <string>]
+  falcon csr verify --in <file>
+
+Subcommands:
+  create    Create a self-signed certification request
+  verify    Check the self-signature of a certification request
+
+Arguments (create):
+  --key <file>              keypair JSON (required, must include private key)
+  --subject <dn>            subject distinguished name (required)
+  --attr <name=value>       extra attribute (repeatable), e.g. email=alice@example.com
+  --out <file>              write CSR JSON (stdout if omitted)
+  --mnemonic-passphrase     optional mnemonic passphrase when the key file omits it
+
+Arguments (verify):
+  --in <file>               CSR JSON file (required)
+
+Exit codes (verify): 0 VALID, 1 INVALID, 2 usage or parse errors.
+
+Examples:
+  falcon csr create --key mykeys.json --subject "CN=alice,O=Example" --out alice.csr.json
+  falcon csr verify --in alice.csr.json
+`
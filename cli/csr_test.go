@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestRunCSR_CreateAndVerify creates a CSR and checks it verifies.
+func TestRunCSR_CreateAndVerify(t *testing.T) {
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("csr test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	dir := t.TempDir()
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+	csrPath := filepath.Join(dir, "req.json")
+
+	code := runCSRCreate([]string{"--key", keyPath, "--subject", "CN=alice,O=Example",
+		"--attr", "email=alice@example.com", "--out", csrPath})
+	if code != 0 {
+		t.Fatalf("expected exit 0 from create, got %d", code)
+	}
+
+	var verifyCode int
+	out := captureStdout(t, func() { verifyCode = runCSRVerify([]string{"--in", csrPath}) })
+	if verifyCode != 0 {
+		t.Fatalf("expected exit 0 from verify, got %d", verifyCode)
+	}
+	if !strings.Contains(out, "VALID") || !strings.Contains(out, "subject: CN=alice,O=Example") {
+		t.Fatalf("unexpected verify output: %q", out)
+	}
+}
+
+// TestRunCSR_TamperedSubject_Exits1 ensures edits to signed fields are detected.
+func TestRunCSR_TamperedSubject_Exits1(t *testing.T) {
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("csr tamper seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	dir := t.TempDir()
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runCSRCreate([]string{"--key", keyPath, "--subject", "CN=alice", "--attr", "role=dev"})
+	})
+	if code != 0 {
+		t.Fatalf("expected exit 0 from create, got %d", code)
+	}
+	var req csrJSON
+	if err := json.Unmarshal([]byte(out), &req); err != nil {
+		t.Fatalf("invalid CSR JSON: %v", err)
+	}
+
+	testCases := []struct {
+		name   string
+		mutate func(*csrJSON)
+	}{
+		{"subject", func(c *csrJSON) { c.Subject = "CN=mallory" }},
+		{"attribute", func(c *csrJSON) { c.Attributes["role"] = "admin" }},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tampered := req
+			tampered.Attributes = map[string]string{"role": "dev"}
+			tc.mutate(&tampered)
+			data, _ := json.Marshal(tampered)
+			path := filepath.Join(dir, tc.name+".json")
+			if err := os.WriteFile(path, data, 0o644); err != nil {
+				t.Fatalf("write tampered CSR: %v", err)
+			}
+			var verifyCode int
+			verifyOut := captureStdout(t, func() { verifyCode = runCSRVerify([]string{"--in", path}) })
+			if verifyCode != 1 || strings.TrimSpace(verifyOut) != "INVALID" {
+				t.Fatalf("expected INVALID/1, got %q/%d", verifyOut, verifyCode)
+			}
+		})
+	}
+}
+
+// TestRunCSRCreate_MissingSubject_Returns2 validates required flags.
+func TestRunCSRCreate_MissingSubject_Returns2(t *testing.T) {
+	var code int
+	errOut := captureStderr(t, func() { code = runCSRCreate([]string{"--key", "keys.json"}) })
+	if code != 2 {
+		t.Fatalf("expected exit 2, got %d", code)
+	}
+	if !strings.Contains(errOut, "--subject is required") {
+		t.Fatalf("unexpected stderr: %q", errOut)
+	}
+}
@@ -0,0 +1,498 @@
+package cli
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// debugBundleFlag asks Run to write a zip of diagnostics once the command
+// returns. It is accepted anywhere before a "--" argument.
+const debugBundleFlag = "--debug-bundle"
+
+// publicFlags are the flags whose values go into a debug bundle as given:
+// file names, networks, amounts and identifiers. The values of every other
+// flag are redacted, so a flag added later is secret until listed here.
+var publicFlags = map[string]bool{
+	"address":           true,
+	"address-suffix":    true,
+	"after-round":       true,
+	"amount":            true,
+	"app-id":            true,
+	"asset-id":          true,
+	"at":                true,
+	"choice":            true,
+	"decimals":          true,
+	"dir":               true,
+	"explorer":          true,
+	"fee":               true,
+	"fee-strategy":      true,
+	"file":              true,
+	"first":             true,
+	"format":            true,
+	"hash":              true,
+	"in":                true,
+	"in-dir":            true,
+	"kdf":               true,
+	"kdf-iterations":    true,
+	"kdf-memory":        true,
+	"kdf-threads":       true,
+	"key":               true,
+	"key-registry":      true,
+	"kmd-password-file": true,
+	"last":              true,
+	"max-attempts":      true,
+	"max-size":          true,
+	"network":           true,
+	"out":               true,
+	"out-dir":           true,
+	"passphrase-file":   true,
+	"path":              true,
+	"pending-dir":       true,
+	"proof":             true,
+	"proof-dir":         true,
+	"proposal-id":       true,
+	"receipts-dir":      true,
+	"registry":          true,
+	"revocations":       true,
+	"round":             true,
+	"router-app-id":     true,
+	"sig-encoding":      true,
+	"teal-version":      true,
+	"template-dir":      true,
+	"threshold":         true,
+	"to":                true,
+	"txid":              true,
+	"wait":              true,
+	"workers":           true,
+}
+
+// boolFlags are the flags that take no value, so the argument after them is
+// not theirs. Names used as a boolean by one command and with a value by
+// another (--check, --confirm) are left out and treated as taking a value.
+var boolFlags = map[string]bool{
+	"allow-weak-seed":            true,
+	"anomalies-only":             true,
+	"attest-env":                 true,
+	"boxes":                      true,
+	"clear":                      true,
+	"coefficients":               true,
+	"commit":                     true,
+	"force":                      true,
+	"global":                     true,
+	"hex":                        true,
+	"invert":                     true,
+	"json":                       true,
+	"json-canonicalize":          true,
+	"list-passphrase-accounts":   true,
+	"mnemonic-passphrase-prompt": true,
+	"msgpack":                    true,
+	"no-mnemonic":                true,
+	"no-rebroadcast":             true,
+	"offline":                    true,
+	"pending":                    true,
+	"rekey":                      true,
+	"require-ct":                 true,
+	"stats":                      true,
+	"stream":                     true,
+	"sweep":                      true,
+	"verbose":                    true,
+}
+
+const (
+	redacted = "[REDACTED]"
+	// maxBundledStderr and maxBundledBody bound what a bundle keeps of the
+	// command's stderr and of each algod response body.
+	maxBundledStderr = 1 << 20
+	maxBundledBody   = 64 << 10
+)
+
+// debugSummary is summary.json in a debug bundle.
+type debugSummary struct {
+	Version   string                     `json:"version"`
+	GoVersion string                     `json:"go_version"`
+	Platform  string                     `json:"platform"`
+	Crypto    falcongo.BuildCapabilities `json:"crypto"`
+	Modules   map[string]string          `json:"modules,omitempty"`
+	Args      []string                   `json:"args"`
+	Env       map[string]string          `json:"env,omitempty"`
+	Started   time.Time                  `json:"started"`
+	Duration  string                     `json:"duration"`
+	ExitCode  int                        `json:"exit_code"`
+	Panic     string                     `json:"panic,omitempty"`
+}
+
+// algodExchange is one request to algod and its response, in algod.json.
+type algodExchange struct {
+	Method     string `json:"method"`
+	URL        string `json:"url"`
+	Status     int    `json:"status,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Duration   string `json:"duration"`
+	BodyBytes  int    `json:"body_bytes"`
+	Body       string `json:"body,omitempty"`        // when UTF-8
+	BodyBase64 string `json:"body_base64,omitempty"` // otherwise
+	Truncated  bool   `json:"truncated,omitempty"`
+}
+
+// algodRecorder records the exchanges of the algod clients while a debug
+// bundle is being collected.
+type algodRecorder struct {
+	mu        sync.Mutex
+	next      http.RoundTripper
+	exchanges []algodExchange
+}
+
+func (r *algodRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := r.next.RoundTrip(req)
+	ex := algodExchange{Method: req.Method, URL: sanitizeURL(req.URL)}
+	if err != nil {
+		ex.Error = err.Error()
+	} else {
+		ex.Status = resp.StatusCode
+		body, readErr := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		if readErr != nil {
+			ex.Error = readErr.Error()
+		}
+		ex.BodyBytes = len(body)
+		if len(body) > maxBundledBody {
+			body, ex.Truncated = body[:maxBundledBody], true
+		}
+		if utf8.Valid(body) {
+			ex.Body = string(body)
+		} else {
+			ex.BodyBase64 = base64.StdEncoding.EncodeToString(body)
+		}
+	}
+	ex.Duration = time.Since(start).String()
+	r.mu.Lock()
+	r.exchanges = append(r.exchanges, ex)
+	r.mu.Unlock()
+	return resp, err
+}
+
+// sanitizeURL drops credentials and the query from u.
+func sanitizeURL(u *url.URL) string {
+	c := *u
+	c.User, c.RawQuery, c.Fragment = nil, "", ""
+	return c.String()
+}
+
+// redactArgs returns args with the values of all flags but publicFlags and
+// boolFlags replaced, and the values it replaced. As with package flag, a
+// flag without "=" takes the next argument as its value unless it is a
+// boolean flag. Bare arguments after the first flag are redacted as well:
+// they are positionals or follow a boolean flag missing from boolFlags.
+// Arguments after "--" are kept.
+func redactArgs(args []string) (out []string, secrets []string) {
+	out = slices.Clone(args)
+	secret := func(i int) {
+		out[i] = redacted
+		secrets = append(secrets, args[i])
+	}
+	inFlags := false
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			break
+		}
+		if len(a) < 2 || a[0] != '-' {
+			// Command and subcommand names come before the flags.
+			if inFlags {
+				secret(i)
+			}
+			continue
+		}
+		inFlags = true
+		name, value, hasValue := strings.Cut(strings.TrimLeft(a, "-"), "=")
+		switch {
+		case boolFlags[name]:
+		case hasValue:
+			if !publicFlags[name] {
+				out[i] = a[:len(a)-len(value)] + redacted
+				secrets = append(secrets, value)
+			}
+		case i+1 < len(args):
+			i++
+			if !publicFlags[name] {
+				secret(i)
+			}
+		}
+	}
+	return out, secrets
+}
+
+// extractDebugBundleFlag removes --debug-bundle and its value from args.
+func extractDebugBundleFlag(args []string) (rest []string, path string, err error) {
+	seen := false
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		name, value, hasValue := strings.Cut(a, "=")
+		if name != debugBundleFlag && name != debugBundleFlag[1:] {
+			rest = append(rest, a)
+			continue
+		}
+		seen = true
+		if !hasValue && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		path = value
+	}
+	if seen && path == "" {
+		return nil, "", errors.New("--debug-bundle requires a file name")
+	}
+	return rest, path, nil
+}
+
+// debugSession collects the diagnostics of one Run.
+type debugSession struct {
+	summary debugSummary
+	secrets []string
+	stack   []byte
+
+	// Set while capturing.
+	stderr    *os.File
+	pipe      *os.File
+	copied    chan struct{}
+	stderrBuf bytes.Buffer
+	recorder  *algodRecorder
+	transport http.RoundTripper
+}
+
+func newDebugSession(args []string) *debugSession {
+	d := &debugSession{summary: debugSummary{
+		Version:   buildVersion(),
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		Crypto:    falcongo.Capabilities(),
+		Env:       map[string]string{},
+		Started:   time.Now().UTC(),
+	}}
+	d.summary.Args, d.secrets = redactArgs(args)
+	if info, ok := debug.ReadBuildInfo(); ok {
+		d.summary.Modules = map[string]string{}
+		for _, m := range info.Deps {
+			d.summary.Modules[m.Path] = m.Version
+		}
+	}
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		switch {
+		case name == "ALGOD_URL":
+			if u, err := url.Parse(value); err == nil {
+				value = sanitizeURL(u)
+			} else {
+				value = redacted
+			}
+		case name == "ALGOD_TOKEN", strings.HasPrefix(name, "FALCON_") && value != "":
+			value = "set"
+		default:
+			continue
+		}
+		d.summary.Env[name] = value
+	}
+	return d
+}
+
+// capture starts copying stderr into the session and recording algod
+// exchanges.
+func (d *debugSession) capture() error {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	d.stderr, d.pipe, d.copied = os.Stderr, w, make(chan struct{})
+	go func() {
+		defer close(d.copied)
+		buf := make([]byte, 4096)
+		for {
+			n, err := r.Read(buf)
+			if n > 0 {
+				_, _ = d.stderr.Write(buf[:n])
+				if room := maxBundledStderr - d.stderrBuf.Len(); room > 0 {
+					d.stderrBuf.Write(buf[:min(n, room)])
+				}
+			}
+			if err != nil {
+				r.Close()
+				return
+			}
+		}
+	}()
+	os.Stderr = w
+
+	d.transport = algorand.AlgodTransport
+	next := d.transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	d.recorder = &algodRecorder{next: next}
+	algorand.AlgodTransport = d.recorder
+	return nil
+}
+
+// stop ends the capture and records how the command ended.
+func (d *debugSession) stop(code int, panicValue any) {
+	if d.pipe != nil {
+		os.Stderr = d.stderr
+		d.pipe.Close()
+		<-d.copied
+		algorand.AlgodTransport = d.transport
+		d.pipe = nil
+	}
+	d.summary.Duration = time.Since(d.summary.Started).String()
+	d.summary.ExitCode = code
+	if panicValue != nil {
+		d.summary.Panic = fmt.Sprint(panicValue)
+	}
+}
+
+// write writes the bundle to path as a zip archive.
+func (d *debugSession) write(path string) error {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	add := func(name string, data []byte) error {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(d.redact(data))
+		return err
+	}
+	summary, err := json.MarshalIndent(d.summary, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := add("summary.json", append(summary, '\n')); err != nil {
+		return err
+	}
+	if d.recorder != nil {
+		if err := add("stderr.txt", d.stderrBuf.Bytes()); err != nil {
+			return err
+		}
+		exchanges, err := json.MarshalIndent(d.recorder.exchanges, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := add("algod.json", append(exchanges, '\n')); err != nil {
+			return err
+		}
+	}
+	if d.stack != nil {
+		if err := add("stack.txt", d.stack); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes(), 0o600)
+}
+
+// redact replaces the redacted flag values wherever they appear in data.
+func (d *debugSession) redact(data []byte) []byte {
+	for _, s := range d.secrets {
+		if s != "" {
+			data = bytes.ReplaceAll(data, []byte(s), []byte(redacted))
+		}
+	}
+	return data
+}
+
+// crashBundlePath is where a bundle goes after a panic when --debug-bundle
+// was not given.
+func crashBundlePath() string {
+	name := "falcon-crash-" + time.Now().UTC().Format("20060102T150405Z") + ".zip"
+	return filepath.Join(os.TempDir(), name)
+}
+
+// runWithDebugBundle runs fn on args, less --debug-bundle. It writes a debug
+// bundle if --debug-bundle was given, or if fn panics.
+func runWithDebugBundle(args []string, fn func([]string) int) (code int) {
+	args, path, err := extractDebugBundleFlag(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	d := newDebugSession(args)
+	if path != "" {
+		if err := d.capture(); err != nil {
+			fmt.Fprintf(os.Stderr, "cannot collect debug bundle: %v\n", err)
+			return 2
+		}
+	}
+	defer func() {
+		p := recover()
+		if p == nil && path == "" {
+			return
+		}
+		if p != nil {
+			d.stack, code = debug.Stack(), 2
+			if path == "" {
+				path = crashBundlePath()
+			}
+		}
+		d.stop(code, p)
+		if p != nil {
+			fmt.Fprintf(os.Stderr, "falcon crashed: %v\n", p)
+		}
+		if err := d.write(path); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write debug bundle %s: %v\n", path, err)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "debug bundle written to %s; review it, then attach it to an issue\n", path)
+	}()
+	return fn(args)
+}
+
+const helpDebugBundle = `# falcon --debug-bundle
+
+Collect diagnostics of a failing command into a zip archive that can be
+attached to an issue. Nothing is sent anywhere.
+
+Usage:
+  falcon <command> [flags] --debug-bundle <file>
+
+The flag is accepted anywhere on the command line. The archive holds:
+  summary.json   falcon, Go and module versions, platform, crypto backend (as
+                 falcon version --verbose reports it), the arguments with
+                 secret values redacted, ALGOD_URL without credentials, which
+                 of ALGOD_TOKEN and FALCON_* are set, exit code and duration
+  stderr.txt     what the command printed on stderr
+  algod.json     each algod request (without its token) and the response
+  stack.txt      the stack trace, if falcon crashed
+
+The values of --algod-token, --from-mnemonic, --known, --mnemonic-passphrase,
+--passphrase and --seed are replaced by [REDACTED] wherever they appear.
+Key files and standard output are never included. Review the archive before
+sharing it.
+
+If falcon crashes, it writes a bundle without stderr.txt and algod.json even
+without the flag, to falcon-crash-<time>.zip in the temporary directory.
+`
@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+)
+
+// readBundle returns the files of the zip archive at path.
+func readBundle(t *testing.T, path string) map[string]string {
+	t.Helper()
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("open bundle: %v", err)
+	}
+	defer zr.Close()
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", f.Name, err)
+		}
+		b, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", f.Name, err)
+		}
+		files[f.Name] = string(b)
+	}
+	return files
+}
+
+func TestRedactArgs(t *testing.T) {
+	got, secrets := redactArgs([]string{
+		"create", "--seed", "s3cret", "--mnemonic-passphrase=pw", "--out", "k.json", "--", "--seed", "x"})
+	want := []string{
+		"create", "--seed", redacted, "--mnemonic-passphrase=" + redacted, "--out", "k.json", "--", "--seed", "x"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("redactArgs = %q, want %q", got, want)
+	}
+	if !reflect.DeepEqual(secrets, []string{"s3cret", "pw"}) {
+		t.Fatalf("secrets = %q", secrets)
+	}
+
+	for _, tc := range []struct {
+		args, want []string
+	}{
+		// Flags not listed as public are redacted, including later additions.
+		{[]string{"auth", "enroll", "--secret", "JBSWY3DP", "--code=123456", "--key", "k.json"},
+			[]string{"auth", "enroll", "--secret", redacted, "--code=" + redacted, "--key", "k.json"}},
+		{[]string{"algorand", "watch", "--indexer-token", "tok", "--network", "testnet"},
+			[]string{"algorand", "watch", "--indexer-token", redacted, "--network", "testnet"}},
+		// A value may start with "-", as for package flag.
+		{[]string{"sign", "--passphrase", "-pw-", "--json"},
+			[]string{"sign", "--passphrase", redacted, "--json"}},
+		// Boolean flags take no value; bare arguments after a flag are redacted.
+		{[]string{"sign", "--hex", "--key", "k.json", "stray"},
+			[]string{"sign", "--hex", "--key", "k.json", redacted}},
+		{[]string{"sign", "--unknown-bool", "--passphrase", "pw"},
+			[]string{"sign", "--unknown-bool", redacted, redacted}},
+	} {
+		if got, _ := redactArgs(tc.args); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("redactArgs(%q) = %q, want %q", tc.args, got, tc.want)
+		}
+	}
+}
+
+func TestExtractDebugBundleFlag(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		rest []string
+		path string
+	}{
+		{[]string{"verify", "--debug-bundle", "a.zip", "--key", "k"}, []string{"verify", "--key", "k"}, "a.zip"},
+		{[]string{"--debug-bundle=b.zip", "version"}, []string{"version"}, "b.zip"},
+		{[]string{"sign", "--", "--debug-bundle", "c.zip"}, []string{"sign", "--", "--debug-bundle", "c.zip"}, ""},
+	} {
+		rest, path, err := extractDebugBundleFlag(tc.args)
+		if err != nil || path != tc.path || !reflect.DeepEqual(rest, tc.rest) {
+			t.Fatalf("extractDebugBundleFlag(%q) = %q, %q, %v", tc.args, rest, path, err)
+		}
+	}
+	for _, args := range [][]string{{"verify", "--debug-bundle"}, {"--debug-bundle="}} {
+		if _, _, err := extractDebugBundleFlag(args); err == nil {
+			t.Fatalf("extractDebugBundleFlag(%q) succeeded", args)
+		}
+	}
+}
+
+// TestRunWithDebugBundle checks that a failing command leaves a bundle with
+// its stderr and algod exchanges, and no secrets.
+func TestRunWithDebugBundle(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message":"node is catching up"}`, http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	t.Setenv("ALGOD_URL", srv.URL)
+	t.Setenv("ALGOD_TOKEN", "tok3n")
+	path := filepath.Join(t.TempDir(), "debug.zip")
+
+	var code int
+	_, stderr := captureStdoutStderr(t, func() {
+		code = runWithDebugBundle([]string{"cmd", "--passphrase", "hunter2", "--debug-bundle", path},
+			func(args []string) int {
+				if !reflect.DeepEqual(args, []string{"cmd", "--passphrase", "hunter2"}) {
+					t.Errorf("command got %q", args)
+				}
+				client, err := algorand.GetAlgodClient(algorand.DevNet)
+				if err != nil {
+					t.Errorf("GetAlgodClient failed: %v", err)
+					return 2
+				}
+				_, err = client.Status().Do(t.Context())
+				fmt.Fprintf(os.Stderr, "status failed with passphrase hunter2: %v\n", err)
+				return 2
+			})
+	})
+	if code != 2 {
+		t.Fatalf("expected exit 2, got %d", code)
+	}
+	if !strings.Contains(stderr, "status failed") || !strings.Contains(stderr, "debug bundle written to") {
+		t.Fatalf("unexpected stderr: %q", stderr)
+	}
+	if algorand.AlgodTransport != nil {
+		t.Fatalf("AlgodTransport not restored")
+	}
+
+	files := readBundle(t, path)
+	for _, name := range []string{"summary.json", "stderr.txt", "algod.json"} {
+		if strings.Contains(files[name], "hunter2") || strings.Contains(files[name], "tok3n") {
+			t.Fatalf("%s leaks a secret: %s", name, files[name])
+		}
+	}
+	var summary debugSummary
+	if err := json.Unmarshal([]byte(files["summary.json"]), &summary); err != nil {
+		t.Fatalf("summary.json: %v", err)
+	}
+	if summary.ExitCode != 2 || summary.Args[2] != redacted || summary.Env["ALGOD_TOKEN"] != "set" {
+		t.Fatalf("unexpected summary: %+v", summary)
+	}
+	if !strings.Contains(files["stderr.txt"], "status failed with passphrase "+redacted) {
+		t.Fatalf("unexpected stderr.txt: %q", files["stderr.txt"])
+	}
+	var exchanges []algodExchange
+	if err := json.Unmarshal([]byte(files["algod.json"]), &exchanges); err != nil {
+		t.Fatalf("algod.json: %v", err)
+	}
+	if len(exchanges) != 1 || exchanges[0].Status != http.StatusServiceUnavailable ||
+		!strings.HasSuffix(exchanges[0].URL, "/v2/status") ||
+		!strings.Contains(exchanges[0].Body, "catching up") {
+		t.Fatalf("unexpected algod.json: %+v", exchanges)
+	}
+}
+
+// TestRunWithDebugBundle_Panic checks that a crash writes a bundle with the
+// stack trace to the temporary directory.
+func TestRunWithDebugBundle_Panic(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+
+	var code int
+	_, stderr := captureStdoutStderr(t, func() {
+		code = runWithDebugBundle([]string{"cmd"}, func([]string) int { panic("boom") })
+	})
+	if code != 2 || !strings.Contains(stderr, "falcon crashed: boom") {
+		t.Fatalf("got exit %d, stderr %q", code, stderr)
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, "falcon-crash-*.zip"))
+	if len(matches) != 1 {
+		t.Fatalf("expected one crash bundle, found %v", matches)
+	}
+	files := readBundle(t, matches[0])
+	if !strings.Contains(files["stack.txt"], "TestRunWithDebugBundle_Panic") ||
+		!strings.Contains(files["summary.json"], `"panic": "boom"`) {
+		t.Fatalf("unexpected crash bundle: %v", files)
+	}
+	if _, ok := files["algod.json"]; ok {
+		t.Fatalf("crash bundle without --debug-bundle has algod.json")
+	}
+}
@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	sdkmnemonic "github.com/algorand/go-algorand-sdk/v2/mnemonic"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// delegationJSON is the file written by algorand delegate and read by
+// algorand send --delegation.
+type delegationJSON struct {
+	Delegator string `json:"delegator"`
+	PublicKey string `json:"public_key"`
+	// LogicSig is the msgpack of the signed logicsig, in base64.
+	LogicSig string `json:"logicsig"`
+}
+
+// ---- algorand delegate ----
+func runAlgorandDelegate(args []string) int {
+	fs := flag.NewFlagSet("algorand delegate", flag.ExitOnError)
+	keyPath := fs.String("key", "", "path to FALCON keypair JSON file (public key sufficient)")
+	fromMnemonic := fs.String("from-ed25519-mnemonic", "", "25-word Algorand mnemonic of the delegating account, or - to read it from stdin")
+	out := fs.String("out", "", "write the delegation to file (stdout if empty)")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+	})
+
+	if *keyPath == "" {
+		fmt.Fprintf(os.Stderr, "--key is required\n")
+		return 2
+	}
+	if *fromMnemonic == "" {
+		fmt.Fprintf(os.Stderr, "--from-ed25519-mnemonic is required\n")
+		return 2
+	}
+	sk, err := readEd25519Mnemonic(*fromMnemonic)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --from-ed25519-mnemonic: %v\n", err)
+		return 2
+	}
+
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	pub, _, _, err := loadKeypairFile(*keyPath, override)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", *keyPath, err)
+		return 2
+	}
+	if pub == nil {
+		fmt.Fprintf(os.Stderr, "public key not found in %s\n", *keyPath)
+		return 2
+	}
+	var pk falcongo.PublicKey
+	copy(pk[:], pub)
+
+	d, err := algorand.DelegatePQLogicSig(pk, sk)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "delegation failed: %v\n", err)
+		return 2
+	}
+	data, err := json.MarshalIndent(delegationJSON{
+		Delegator: d.Delegator.String(),
+		PublicKey: hex.EncodeToString(pk[:]),
+		LogicSig:  base64.StdEncoding.EncodeToString(msgpack.Encode(d.LogicSig)),
+	}, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode the delegation: %v\n", err)
+		return 2
+	}
+	data = append(data, '\n')
+
+	fmt.Fprintf(os.Stderr, "delegating account: %s\n", d.Delegator)
+	fmt.Fprintf(os.Stderr, "WARNING: whoever holds the FALCON key and this delegation can now spend from %s;\n",
+		d.Delegator)
+	fmt.Fprintf(os.Stderr, "only rekeying the account revokes the delegation.\n")
+	if *out == "" {
+		_, _ = os.Stdout.Write(data)
+		return 0
+	}
+	if err := writeFileAtomic(*out, data, 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *out, err)
+		return 2
+	}
+	return 0
+}
+
+// readEd25519Mnemonic returns the Ed25519 key of a 25-word Algorand
+// mnemonic, read from stdin if words is "-".
+func readEd25519Mnemonic(words string) (ed25519.PrivateKey, error) {
+	if words == "-" {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return nil, fmt.Errorf("reading the mnemonic from stdin: %w", err)
+		}
+		words = line
+	}
+	return sdkmnemonic.ToPrivateKey(strings.Join(strings.Fields(words), " "))
+}
+
+// readDelegation reads a delegation written by algorand delegate and checks
+// that it delegates to the FALCON key pub.
+func readDelegation(path string, pub falcongo.PublicKey) (algorand.Delegation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return algorand.Delegation{}, err
+	}
+	var dj delegationJSON
+	if err := json.Unmarshal(data, &dj); err != nil {
+		return algorand.Delegation{}, fmt.Errorf("invalid delegation file: %w", err)
+	}
+	var d algorand.Delegation
+	if d.Delegator, err = types.DecodeAddress(dj.Delegator); err != nil {
+		return algorand.Delegation{}, fmt.Errorf("invalid delegator: %w", err)
+	}
+	if dj.PublicKey != "" {
+		pk, err := hex.DecodeString(dj.PublicKey)
+		if err != nil || !bytes.Equal(pk, pub[:]) {
+			return algorand.Delegation{}, fmt.Errorf("the delegation is to another FALCON key")
+		}
+	}
+	raw, err := base64.StdEncoding.DecodeString(dj.LogicSig)
+	if err != nil {
+		return algorand.Delegation{}, fmt.Errorf("invalid logicsig: %w", err)
+	}
+	if err := msgpack.Decode(raw, &d.LogicSig); err != nil {
+		return algorand.Delegation{}, fmt.Errorf("invalid logicsig: %w", err)
+	}
+	if err := d.Verify(pub); err != nil {
+		return algorand.Delegation{}, err
+	}
+	return d, nil
+}
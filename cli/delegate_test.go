@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"path/filepath"
+	"testing"
+
+	sdkmnemonic "github.com/algorand/go-algorand-sdk/v2/mnemonic"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestRunAlgorandDelegate writes a delegation and reads it back for the key,
+// and refuses it for another key.
+func TestRunAlgorandDelegate(t *testing.T) {
+	dir := t.TempDir()
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("delegate test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, false)
+	sk := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{5}, ed25519.SeedSize))
+	words, err := sdkmnemonic.FromPrivateKey(sk)
+	if err != nil {
+		t.Fatalf("FromPrivateKey failed: %v", err)
+	}
+	out := filepath.Join(dir, "delegation.json")
+
+	var code int
+	_, stderr := captureStdoutStderr(t, func() {
+		code = runAlgorandDelegate([]string{"--key", keyPath, "--from-ed25519-mnemonic", words, "--out", out})
+	})
+	if code != 0 {
+		t.Fatalf("expected exit 0, got %d: %s", code, stderr)
+	}
+	d, err := readDelegation(out, kp.PublicKey)
+	if err != nil {
+		t.Fatalf("readDelegation failed: %v", err)
+	}
+	if !bytes.Equal(d.Delegator[:], sk.Public().(ed25519.PublicKey)) {
+		t.Fatalf("delegator = %s, want the account of the mnemonic", d.Delegator)
+	}
+
+	other, err := falcongo.GenerateKeyPair(deriveSeed([]byte("another delegate test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	if _, err := readDelegation(out, other.PublicKey); err == nil {
+		t.Fatal("expected the delegation to be refused for another key")
+	}
+
+	for _, args := range [][]string{
+		{"--from-ed25519-mnemonic", words},
+		{"--key", keyPath},
+		{"--key", keyPath, "--from-ed25519-mnemonic", "abandon abandon abandon"},
+	} {
+		_, _ = captureStdoutStderr(t, func() { code = runAlgorandDelegate(args) })
+		if code != 2 {
+			t.Errorf("%v: expected exit 2, got %d", args, code)
+		}
+	}
+}
@@ -0,0 +1,412 @@
+package cli
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/hashing"
+)
+
+// Results of the checks of falcon doctor. Only doctorFail makes it exit 1.
+const (
+	doctorOK   = "ok"
+	doctorWarn = "warn"
+	doctorFail = "FAIL"
+	doctorSkip = "skip"
+)
+
+// maxClockSkew is the largest difference between the local clock and the
+// network that doctor accepts: auth challenges, revocation times and key
+// statistics rely on the local clock.
+const maxClockSkew = time.Minute
+
+// doctorCheck is one line of the report of falcon doctor.
+type doctorCheck struct {
+	Status string
+	Name   string
+	Detail string
+}
+
+// doctorNetworks are the networks checked when --network is not given and
+// ALGOD_URL is not set; DevNet has no default node.
+var doctorNetworks = []string{"mainnet", "testnet", "betanet"}
+
+// runDoctor implements `falcon doctor`: it checks the binary, the key store
+// and the algod nodes, and prints a report.
+func runDoctor(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	networkFlag := fs.String("network", "", "check only this network: mainnet, testnet, betanet, devnet")
+	keyPath := fs.String("key", "", "also check the permissions of this key file")
+	offline := fs.Bool("offline", false, "skip the checks of algod nodes")
+	algodURL := fs.String("algod-url", "", "set algod API endpoint (optional)")
+	algodToken := fs.String("algod-token", "", "set algod API token (optional); requires --algod-url")
+	parseFlags(fs, args)
+	algodURLProvided := false
+	algodTokenProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "algod-url" {
+			algodURLProvided = true
+		}
+		if f.Name == "algod-token" {
+			algodTokenProvided = true
+		}
+	})
+
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "unexpected argument: %s\n", fs.Arg(0))
+		return 2
+	}
+	if algodTokenProvided && !algodURLProvided {
+		fmt.Fprintf(os.Stderr, "--algod-token requires --algod-url\n")
+		return 2
+	}
+	networks := doctorNetworks
+	if *networkFlag != "" {
+		if _, err := parseAlgorandNetwork(*networkFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --network: %v\n", err)
+			return 2
+		}
+		networks = []string{strings.ToLower(strings.TrimSpace(*networkFlag))}
+	}
+	if algodURLProvided {
+		if err := os.Setenv("ALGOD_URL", strings.TrimSpace(*algodURL)); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to set ALGOD_URL: %v\n", err)
+			return 2
+		}
+		if algodTokenProvided {
+			if err := os.Setenv("ALGOD_TOKEN", strings.TrimSpace(*algodToken)); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to set ALGOD_TOKEN: %v\n", err)
+				return 2
+			}
+		}
+	}
+
+	var checks []doctorCheck
+	checks = append(checks, binaryChecks()...)
+	checks = append(checks, keyStoreChecks(*keyPath)...)
+	if *offline {
+		checks = append(checks, doctorCheck{doctorSkip, "algod", "--offline"})
+	} else {
+		if os.Getenv("ALGOD_URL") != "" && *networkFlag == "" {
+			// Every network uses ALGOD_URL: check it once.
+			networks = []string{"devnet"}
+		}
+		for _, name := range networks {
+			netw, _ := parseAlgorandNetwork(name)
+			label := "algod " + name
+			if os.Getenv("ALGOD_URL") != "" {
+				label = "algod (ALGOD_URL)"
+			}
+			d, err := algorand.DiagnoseAlgod(netw)
+			checks = append(checks, algodChecks(label, d, err)...)
+		}
+	}
+
+	if err := printDoctorReport(os.Stdout, checks); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write report: %v\n", err)
+		return 2
+	}
+	for _, c := range checks {
+		if c.Status == doctorFail {
+			return 1
+		}
+	}
+	return 0
+}
+
+// printDoctorReport writes checks, one per line, and a summary.
+func printDoctorReport(w io.Writer, checks []doctorCheck) error {
+	failed := 0
+	for _, c := range checks {
+		if c.Status == doctorFail {
+			failed++
+		}
+		if _, err := fmt.Fprintf(w, "%-4s  %s: %s\n", c.Status, c.Name, c.Detail); err != nil {
+			return err
+		}
+	}
+	summary := "all checks passed"
+	if failed > 0 {
+		summary = fmt.Sprintf("%d of %d checks failed", failed, len(checks))
+	}
+	_, err := fmt.Fprintf(w, "\n%s\n", summary)
+	return err
+}
+
+// binaryChecks reports the build of the running binary and the hash of its
+// executable, to compare with the published checksums, and runs a self-test
+// of the FALCON implementation and of the precompiled PQ logicsig.
+func binaryChecks() []doctorCheck {
+	build := fmt.Sprintf("falcon %s (%s, %s/%s)", buildVersion(), runtime.Version(), runtime.GOOS, runtime.GOARCH)
+	binary := doctorCheck{doctorOK, "binary", build}
+	if exe, err := os.Executable(); err != nil {
+		binary = doctorCheck{doctorFail, "binary", fmt.Sprintf("%s; cannot locate the executable: %v", build, err)}
+	} else if sum, err := fileDigest(exe, hashing.SHA256); err != nil {
+		binary = doctorCheck{doctorFail, "binary", fmt.Sprintf("%s; cannot read %s: %v", build, exe, err)}
+	} else {
+		binary.Detail = fmt.Sprintf("%s; %s sha256 %s", build, exe, hex.EncodeToString(sum))
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && binary.Status == doctorOK {
+		for _, s := range info.Settings {
+			if s.Key == "vcs.modified" && s.Value == "true" {
+				binary.Status = doctorWarn
+				binary.Detail += "; built from a modified source tree"
+			}
+		}
+	}
+	return []doctorCheck{binary, selfTest()}
+}
+
+// fileDigest returns the digest of the file at path under a.
+func fileDigest(path string, a hashing.Algorithm) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := a.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// selfTest signs and verifies with a fixed key, and checks the precompiled
+// PQ logicsig against its template.
+func selfTest() doctorCheck {
+	fail := func(format string, a ...any) doctorCheck {
+		return doctorCheck{doctorFail, "self-test", fmt.Sprintf(format, a...)}
+	}
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("falcon doctor self-test")))
+	if err != nil {
+		return fail("key generation failed: %v", err)
+	}
+	detail := "FALCON sign and verify, precompiled PQ logicsig"
+	if falcongo.SigningDisabled() {
+		detail = "precompiled PQ logicsig; signing not tested (read-only mode)"
+	} else {
+		msg := []byte("falcon doctor")
+		sig, err := kp.Sign(msg)
+		if err != nil {
+			return fail("signing failed: %v", err)
+		}
+		if err := falcongo.Verify(msg, sig, kp.PublicKey); err != nil {
+			return fail("a fresh signature does not verify: %v", err)
+		}
+		if falcongo.Verify([]byte("falcon doctoR"), sig, kp.PublicKey) == nil {
+			return fail("a signature verifies for another message")
+		}
+	}
+	if err := algorand.CheckPrecompiles(); err != nil {
+		return fail("%v", err)
+	}
+	if _, err := algorand.DerivePQLogicSig(kp.PublicKey); err != nil {
+		return fail("address derivation failed: %v", err)
+	}
+	return doctorCheck{doctorOK, "self-test", detail}
+}
+
+// keyStoreChecks checks the permissions of the key statistics file, of the
+// pending transaction records, and of the key files recorded in the
+// statistics and extraKey: a file holding a private key must not be readable
+// by other users, and the others must not be writable by them.
+func keyStoreChecks(extraKey string) []doctorCheck {
+	if runtime.GOOS == "windows" {
+		return []doctorCheck{{doctorSkip, "key store", "permissions are not checked on Windows"}}
+	}
+	var checks []doctorCheck
+	var keyFiles []string
+
+	statsPath, err := keyStatsPath()
+	switch {
+	case err != nil:
+		checks = append(checks, doctorCheck{doctorWarn, "key statistics", err.Error()})
+	case statsPath == "":
+		checks = append(checks, doctorCheck{doctorSkip, "key statistics", "recording disabled ($" + envKeyStats + ")"})
+	default:
+		c := checkNotWritableByOthers("key statistics", statsPath)
+		if c.Status == doctorOK {
+			stats, err := readKeyStats(statsPath)
+			if err != nil {
+				c = doctorCheck{doctorFail, "key statistics", err.Error()}
+			}
+			for _, u := range stats.Keys {
+				if _, _, isSource := keySourceRef(u.KeyFile); u.KeyFile != "" && !isSource {
+					keyFiles = append(keyFiles, u.KeyFile)
+				}
+			}
+		}
+		checks = append(checks, c)
+	}
+
+	if dir, _, err := resolvePendingDir("", false); err != nil {
+		checks = append(checks, doctorCheck{doctorWarn, "pending records", err.Error()})
+	} else {
+		checks = append(checks, checkNotWritableByOthers("pending records", dir))
+	}
+
+	if extraKey != "" {
+		keyFiles = append(keyFiles, extraKey)
+	}
+	seen := map[string]bool{}
+	for _, path := range keyFiles {
+		if !seen[absPath(path)] {
+			seen[absPath(path)] = true
+			checks = append(checks, checkKeyFileMode(path, path == extraKey))
+		}
+	}
+	return checks
+}
+
+// checkNotWritableByOthers checks that the file or directory at path, if it
+// exists, is not writable by group or others.
+func checkNotWritableByOthers(name, path string) doctorCheck {
+	fi, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return doctorCheck{doctorOK, name, path + " (not created yet)"}
+	}
+	if err != nil {
+		return doctorCheck{doctorFail, name, err.Error()}
+	}
+	if mode := fi.Mode().Perm(); mode&0o022 != 0 {
+		return doctorCheck{doctorFail, name, fmt.Sprintf("%s is writable by other users (mode %04o); run chmod go-w %s",
+			path, mode, path)}
+	}
+	return doctorCheck{doctorOK, name, path}
+}
+
+// checkKeyFileMode checks the permissions of a key file. A key file recorded
+// in the statistics may since have moved: that is not a failure, unlike a
+// missing --key.
+func checkKeyFileMode(path string, required bool) doctorCheck {
+	name := "key file " + path
+	fi, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) && !required {
+		return doctorCheck{doctorSkip, name, "no longer exists"}
+	}
+	if err != nil {
+		return doctorCheck{doctorFail, name, err.Error()}
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return doctorCheck{doctorFail, name, err.Error()}
+	}
+	var kp keyPairJSON
+	if err := json.Unmarshal(b, &kp); err != nil {
+		return doctorCheck{doctorFail, name, fmt.Sprintf("not a key file: %v", err)}
+	}
+	mode := fi.Mode().Perm()
+	secret := kp.PrivateKey != "" || kp.Mnemonic != ""
+	switch {
+	case secret && mode&0o077 != 0:
+		return doctorCheck{doctorFail, name, fmt.Sprintf("holds a private key but is accessible by other users (mode %04o); run chmod 600 %s",
+			mode, path)}
+	case mode&0o022 != 0:
+		return doctorCheck{doctorFail, name, fmt.Sprintf("writable by other users (mode %04o); run chmod go-w %s",
+			mode, path)}
+	case secret:
+		return doctorCheck{doctorOK, name, fmt.Sprintf("private key, mode %04o", mode)}
+	default:
+		return doctorCheck{doctorOK, name, fmt.Sprintf("public key only, mode %04o", mode)}
+	}
+}
+
+// algodChecks turns the diagnosis of an algod node into report lines.
+func algodChecks(label string, d algorand.AlgodDiagnosis, err error) []doctorCheck {
+	if err != nil {
+		return []doctorCheck{{doctorSkip, label, err.Error()}}
+	}
+	if d.Health != nil {
+		return []doctorCheck{{doctorFail, label, fmt.Sprintf("%s is unreachable: %v", d.URL, d.Health)}}
+	}
+	checks := []doctorCheck{{doctorOK, label, d.URL + " is reachable"}}
+	if d.Status != nil {
+		detail := d.Status.Error()
+		if errors.Is(d.Status, algorand.ErrInvalidAlgodToken) {
+			detail += "; check ALGOD_TOKEN or --algod-token"
+		}
+		return append(checks, doctorCheck{doctorFail, label + " token", detail})
+	}
+	checks = append(checks, doctorCheck{doctorOK, label + " token", fmt.Sprintf("accepted; last round %d", d.LastRound)})
+
+	switch skew := d.ClockSkew.Round(time.Second); {
+	case d.Clock != nil:
+		checks = append(checks, doctorCheck{doctorWarn, label + " clock", fmt.Sprintf("cannot read the last block: %v", d.Clock)})
+	case skew.Abs() > maxClockSkew:
+		checks = append(checks, doctorCheck{doctorFail, label + " clock",
+			fmt.Sprintf("the local clock is %s; synchronize it (e.g. with NTP)", describeSkew(skew))})
+	default:
+		checks = append(checks, doctorCheck{doctorOK, label + " clock", "the local clock is " + describeSkew(skew)})
+	}
+
+	switch {
+	case d.Compile == nil:
+		checks = append(checks, doctorCheck{doctorOK, label + " compile",
+			"falcon_verify is available and the PQ logicsig compiles to the precompiled program"})
+	case errors.Is(d.Compile, algorand.ErrDeveloperAPIDisabled):
+		checks = append(checks, doctorCheck{doctorSkip, label + " compile", d.Compile.Error()})
+	default:
+		checks = append(checks, doctorCheck{doctorFail, label + " compile", d.Compile.Error()})
+	}
+	return checks
+}
+
+// describeSkew describes a clock skew rounded to the second; the time of the
+// network is only known to about a second.
+func describeSkew(skew time.Duration) string {
+	switch {
+	case skew.Abs() <= time.Second:
+		return "in sync with the network"
+	case skew > 0:
+		return skew.String() + " ahead of the network"
+	default:
+		return (-skew).String() + " behind the network"
+	}
+}
+
+const helpDoctor = `# falcon doctor
+
+Check the environment and the connectivity of falcon, and print a report with
+one line per check: ok, warn, FAIL or skip. Attach it to support requests.
+
+Usage:
+  falcon doctor [--network <name>] [--key <file>] [--offline] [--algod-url <string>] [--algod-token <string>]
+
+Checks:
+  binary            The build, and the SHA-256 of the executable to compare with the published checksums
+  self-test         FALCON signing and verification, and the precompiled PQ logicsig against its template
+  key statistics    The key statistics file is not writable by other users
+  pending records   The pending transaction records directory is not writable by other users
+  key file <path>   Key files recorded in the key statistics, and --key: no private key readable by other users
+  algod <network>   The node is reachable, accepts the API token, agrees with the local clock to within
+                    a minute, and compiles the PQ logicsig (falcon_verify) as the precompiled program;
+                    the compile check is skipped on nodes without the developer API
+
+Arguments:
+  --network <name>       check only this network: mainnet, testnet, betanet, devnet
+                         (default: mainnet, testnet and betanet, or only ALGOD_URL when it is set)
+  --key <file>           also check the permissions of this key file
+  --offline              skip the checks of algod nodes
+  --algod-url <string>   set algod API endpoint (optional; otherwise uses ALGOD_URL env or defaults)
+  --algod-token <string> set algod API token (optional; requires --algod-url)
+
+Permissions are not checked on Windows.
+
+Exit status: 0 if no check failed, 1 if one did, 2 on usage errors.
+
+Examples:
+  falcon doctor
+  falcon doctor --network testnet --key mykeys.json
+  falcon doctor --algod-url http://localhost:4001 --algod-token "$(cat algod.token)"
+`
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// doctorAlgod serves the algod endpoints checked by falcon doctor, with a
+// last block at blockTime and no developer API.
+func doctorAlgod(t *testing.T, blockTime time.Time) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" && r.Header.Get("X-Algo-API-Token") != "secret" {
+			http.Error(w, `{"message":"Invalid API Token"}`, http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/health":
+			_, _ = w.Write([]byte("null"))
+		case "/v2/status":
+			_ = json.NewEncoder(w).Encode(models.NodeStatus{LastRound: 42})
+		case "/v2/blocks/42":
+			var b types.Block
+			b.TimeStamp = blockTime.Unix()
+			_, _ = w.Write(msgpack.Encode(models.BlockResponse{Block: b}))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// TestRunDoctor covers the report and exit status of falcon doctor.
+func TestRunDoctor(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permissions are not checked on Windows")
+	}
+	t.Setenv("ALGOD_URL", "")
+	t.Setenv("ALGOD_TOKEN", "")
+	t.Setenv(envPendingDir, t.TempDir())
+
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("doctor test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, t.TempDir(), "keys.json", kp, true)
+	url := doctorAlgod(t, time.Now())
+
+	var code int
+	stdout := captureStdout(t, func() {
+		code = runDoctor([]string{"--key", keyPath, "--algod-url", url, "--algod-token", "secret"})
+	})
+	for _, want := range []string{
+		"ok    self-test: ",
+		"ok    key file " + keyPath + ": private key, mode 0600",
+		"ok    algod (ALGOD_URL): " + url + " is reachable",
+		"ok    algod (ALGOD_URL) token: accepted; last round 42",
+		"ok    algod (ALGOD_URL) clock: the local clock is in sync with the network",
+		"skip  algod (ALGOD_URL) compile: ",
+		"all checks passed",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("report lacks %q:\n%s", want, stdout)
+		}
+	}
+	if code != 0 {
+		t.Fatalf("expected exit 0, got %d", code)
+	}
+
+	if err := os.Chmod(keyPath, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stdout = captureStdout(t, func() {
+		code = runDoctor([]string{"--key", keyPath, "--algod-url", doctorAlgod(t, time.Now().Add(-5*time.Minute)),
+			"--algod-token", "wrong"})
+	})
+	for _, want := range []string{
+		"FAIL  key file " + keyPath + ": holds a private key but is accessible by other users (mode 0644)",
+		"FAIL  algod (ALGOD_URL) token: algod rejected the API token",
+		"2 of ",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("report lacks %q:\n%s", want, stdout)
+		}
+	}
+	if code != 1 {
+		t.Fatalf("expected exit 1, got %d", code)
+	}
+
+	stdout = captureStdout(t, func() {
+		code = runDoctor([]string{"--offline", "--algod-url", doctorAlgod(t, time.Now().Add(-5*time.Minute)),
+			"--algod-token", "secret"})
+	})
+	if code != 0 || !strings.Contains(stdout, "skip  algod: --offline") {
+		t.Fatalf("--offline: exit %d:\n%s", code, stdout)
+	}
+	stdout = captureStdout(t, func() {
+		code = runDoctor([]string{"--algod-url", doctorAlgod(t, time.Now().Add(-5*time.Minute)), "--algod-token", "secret"})
+	})
+	if code != 1 || !strings.Contains(stdout, "FAIL  algod (ALGOD_URL) clock: the local clock is 5m") {
+		t.Fatalf("clock skew: exit %d:\n%s", code, stdout)
+	}
+}
+
+// TestRunDoctor_Usage covers the usage errors of falcon doctor.
+func TestRunDoctor_Usage(t *testing.T) {
+	for _, args := range [][]string{
+		{"--network", "nowhere"},
+		{"--algod-token", "secret"},
+		{"extra"},
+	} {
+		var code int
+		_, _ = captureStdoutStderr(t, func() { code = runDoctor(args) })
+		if code != 2 {
+			t.Errorf("%v: expected exit 2, got %d", args, code)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/algorandfoundation/falcon-signatures/hashing"
+)
+
+// Environment attestation: with sign --attest-env, a statement describing the
+// signing environment is signed together with the message, so a verifier can
+// check where a signature was made against a policy. The signed payload is
+// envDomain || H(statement) || message, and the output container is the
+// 4-byte big-endian statement length, the statement JSON, then the usual
+// output (commitment, if any, and signature). H is the hash the statement
+// records, SHA-256 for version 1 statements, which record none.
+const (
+	envDomain             = "falcon-env-v1"
+	envStatementVersion   = 2
+	envStatementVersionV1 = 1
+	envLengthSize         = 4
+	// envStatementMaxSize bounds the statement, which holds at most a TEE
+	// quote of a few KiB besides small fields.
+	envStatementMaxSize = 1 << 20
+	envAttester         = "FALCON_ATTESTER"
+)
+
+// envStatementJSON describes the environment a signature was made in.
+type envStatementJSON struct {
+	Version        int    `json:"version"`
+	Tool           string `json:"tool"`            // "falcon <version>"
+	HostnameSHA256 string `json:"hostname_sha256"` // hex SHA-256 of the hostname
+	OS             string `json:"os"`
+	Arch           string `json:"arch"`
+	// Hash is the algorithm of the statement digest and of envReportData.
+	Hash string `json:"hash,omitempty"`
+	// Attester names the source of Quote, if any.
+	Attester string `json:"attester,omitempty"`
+	// Quote is attester evidence (e.g. a TEE quote) over envReportData,
+	// base64-encoded.
+	Quote string `json:"quote,omitempty"`
+}
+
+// environmentAttester produces evidence of the signing environment bound to
+// reportData, such as a TEE quote. Implementations for specific TEEs can be
+// added next to programAttester.
+type environmentAttester interface {
+	Name() string
+	Quote(reportData []byte) ([]byte, error)
+}
+
+// programAttester obtains the quote from an external program, which receives
+// {"report_data": "<hex>"} on stdin and writes the raw quote to stdout.
+type programAttester struct {
+	command string // program path optionally followed by arguments
+}
+
+// Name is the base name of the program, e.g. "tdx-quote".
+func (a programAttester) Name() string {
+	return filepath.Base(strings.Fields(a.command)[0])
+}
+
+func (a programAttester) Quote(reportData []byte) ([]byte, error) {
+	argv := strings.Fields(a.command)
+	payload, err := json.Marshal(map[string]string{"report_data": hex.EncodeToString(reportData)})
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("attester %q failed: %w", argv[0], err)
+	}
+	if out.Len() == 0 {
+		return nil, fmt.Errorf("attester %q returned an empty quote", argv[0])
+	}
+	return out.Bytes(), nil
+}
+
+// envReportData binds attester evidence to one signature: the digest under
+// hash of the public key followed by the digest of the message.
+func envReportData(hash hashing.Algorithm, pub, msg []byte) []byte {
+	h := hash.New()
+	h.Write(pub)
+	h.Write(hash.Sum(msg))
+	return h.Sum(nil)
+}
+
+// newEnvStatement describes the current environment, with a quote from
+// attester (if not nil) over the report data of pub and msg under hash.
+func newEnvStatement(attester environmentAttester, hash hashing.Algorithm, pub, msg []byte) ([]byte, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hostname: %w", err)
+	}
+	// The policy field is named after SHA-256, so the hostname keeps it.
+	hostDigest := hashing.SHA256.Sum([]byte(hostname))
+	st := envStatementJSON{
+		Version:        envStatementVersion,
+		Tool:           "falcon " + buildVersion(),
+		HostnameSHA256: hex.EncodeToString(hostDigest),
+		OS:             runtime.GOOS,
+		Arch:           runtime.GOARCH,
+		Hash:           string(hash),
+	}
+	if attester != nil {
+		quote, err := attester.Quote(envReportData(hash, pub, msg))
+		if err != nil {
+			return nil, err
+		}
+		st.Attester = attester.Name()
+		st.Quote = base64.StdEncoding.EncodeToString(quote)
+	}
+	return json.Marshal(st)
+}
+
+// attestedMessage returns the payload signed with an environment statement
+// whose hash is hash.
+func attestedMessage(hash hashing.Algorithm, statement, msg []byte) []byte {
+	digest := hash.Sum(statement)
+	out := make([]byte, 0, len(envDomain)+len(digest)+len(msg))
+	out = append(out, envDomain...)
+	out = append(out, digest...)
+	return append(out, msg...)
+}
+
+// envContainer prefixes rest with the length-prefixed statement.
+func envContainer(statement, rest []byte) []byte {
+	out := binary.BigEndian.AppendUint32(nil, uint32(len(statement)))
+	out = append(out, statement...)
+	return append(out, rest...)
+}
+
+// splitEnvContainer splits an output container made with --attest-env into
+// the statement bytes, the decoded statement and the remaining output.
+func splitEnvContainer(b []byte) (raw []byte, st envStatementJSON, rest []byte, err error) {
+	if len(b) < envLengthSize {
+		return nil, st, nil, errors.New("no environment statement")
+	}
+	n := binary.BigEndian.Uint32(b)
+	if n > envStatementMaxSize || uint64(n) > uint64(len(b)-envLengthSize) {
+		return nil, st, nil, errors.New("truncated environment statement")
+	}
+	raw, rest = b[envLengthSize:envLengthSize+n], b[envLengthSize+n:]
+	if err := json.Unmarshal(raw, &st); err != nil {
+		return nil, st, nil, fmt.Errorf("invalid environment statement: %w", err)
+	}
+	if _, err := st.hash(); err != nil {
+		return nil, st, nil, err
+	}
+	return raw, st, rest, nil
+}
+
+// hash returns the algorithm the statement records.
+func (st envStatementJSON) hash() (hashing.Algorithm, error) {
+	switch st.Version {
+	case envStatementVersionV1:
+		if st.Hash != "" {
+			return "", errors.New("version 1 environment statements have no hash")
+		}
+		return hashing.SHA256, nil
+	case envStatementVersion:
+		hash, err := hashing.Parse(st.Hash)
+		if err != nil {
+			return "", fmt.Errorf("invalid environment statement: %w", err)
+		}
+		return hash, nil
+	}
+	return "", fmt.Errorf("unsupported environment statement version %d", st.Version)
+}
+
+// envPolicyJSON is the policy given to verify --require-attestation. Empty
+// lists allow any value.
+type envPolicyJSON struct {
+	Tools          []string `json:"tools,omitempty"` // e.g. "falcon v1.2.0"
+	HostnameSHA256 []string `json:"hostname_sha256,omitempty"`
+	OS             []string `json:"os,omitempty"`
+	Arch           []string `json:"arch,omitempty"`
+	Attesters      []string `json:"attesters,omitempty"`
+	RequireQuote   bool     `json:"require_quote,omitempty"`
+	// QuoteVerifier is a program that checks quotes: it receives
+	// {"attester", "quote" (base64), "report_data" (hex)} on stdin and
+	// must exit 0. Without it quotes are not checked, only required.
+	QuoteVerifier string `json:"quote_verifier,omitempty"`
+}
+
+// readEnvPolicy reads a policy file, rejecting unknown fields so that a
+// misspelled constraint is not silently ignored.
+func readEnvPolicy(path string) (envPolicyJSON, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return envPolicyJSON{}, err
+	}
+	var p envPolicyJSON
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&p); err != nil {
+		return envPolicyJSON{}, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return p, nil
+}
+
+// check reports the first way st fails the policy, for the signature of msg
+// under pub.
+func (p envPolicyJSON) check(st envStatementJSON, pub, msg []byte) error {
+	allowed := func(field, value string, list []string) error {
+		if len(list) > 0 && !slices.Contains(list, value) {
+			return fmt.Errorf("%s %q not allowed", field, value)
+		}
+		return nil
+	}
+	for _, err := range []error{
+		allowed("tool", st.Tool, p.Tools),
+		allowed("hostname_sha256", st.HostnameSHA256, p.HostnameSHA256),
+		allowed("os", st.OS, p.OS),
+		allowed("arch", st.Arch, p.Arch),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	if st.Quote == "" {
+		if p.RequireQuote || len(p.Attesters) > 0 {
+			return errors.New("no attester quote")
+		}
+		return nil
+	}
+	if err := allowed("attester", st.Attester, p.Attesters); err != nil {
+		return err
+	}
+	if p.QuoteVerifier == "" {
+		return nil
+	}
+	hash, err := st.hash()
+	if err != nil {
+		return err
+	}
+	argv := strings.Fields(p.QuoteVerifier)
+	payload, err := json.Marshal(map[string]string{
+		"attester":    st.Attester,
+		"quote":       st.Quote,
+		"report_data": hex.EncodeToString(envReportData(hash, pub, msg)),
+	})
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("quote rejected by %q: %w", argv[0], err)
+	}
+	return nil
+}
+
+// describe summarizes st on one line for verify output.
+func (st envStatementJSON) describe() string {
+	s := fmt.Sprintf("%s, %s/%s, host %s", st.Tool, st.OS, st.Arch, st.HostnameSHA256)
+	if st.Attester != "" {
+		s += ", attester " + st.Attester
+	}
+	return s
+}
@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/hashing"
+)
+
+// TestRunSign_AttestEnv signs with an environment statement and checks it
+// against policies with verify --require-attestation.
+func TestRunSign_AttestEnv(t *testing.T) {
+	t.Setenv(envAttester, "")
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("attest env seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	dir := t.TempDir()
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+	sigPath := filepath.Join(dir, "msg.sig")
+	if code := runSign([]string{"--key", keyPath, "--msg", "hi", "--attest-env", "--commit",
+		"--out", sigPath}); code != 0 {
+		t.Fatalf("sign failed with %d", code)
+	}
+
+	writePolicy := func(name, body string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatalf("write policy: %v", err)
+		}
+		return path
+	}
+	verify := func(policy string, sig string) (int, string, string) {
+		var code int
+		out, stderr := captureStdoutStderr(t, func() {
+			code = runVerify([]string{"--key", keyPath, "--msg", "hi", "--sig", sig, "--commit",
+				"--require-attestation", policy})
+		})
+		return code, out, stderr
+	}
+
+	allowed := writePolicy("allowed.json", `{"os": ["`+runtime.GOOS+`"], "tools": ["falcon `+buildVersion()+`"]}`)
+	code, out, _ := verify(allowed, sigPath)
+	if code != 0 || !strings.HasPrefix(out, "VALID\ncommitment: ") ||
+		!strings.Contains(out, "environment: falcon "+buildVersion()+", "+runtime.GOOS+"/"+runtime.GOARCH) {
+		t.Fatalf("expected VALID with environment, got %d %q", code, out)
+	}
+
+	for name, body := range map[string]string{
+		"os.json":    `{"os": ["plan9"]}`,
+		"quote.json": `{"require_quote": true}`,
+	} {
+		code, out, stderr := verify(writePolicy(name, body), sigPath)
+		if code != 1 || strings.TrimSpace(out) != "INVALID" || !strings.Contains(stderr, "attestation policy") {
+			t.Fatalf("%s: expected policy failure, got %d %q %q", name, code, out, stderr)
+		}
+	}
+
+	// The statement is signed: editing it invalidates the signature.
+	sig, err := os.ReadFile(sigPath)
+	if err != nil {
+		t.Fatalf("read sig: %v", err)
+	}
+	tampered := filepath.Join(dir, "tampered.sig")
+	edited := strings.Replace(string(sig), `"os":"`+runtime.GOOS+`"`, `"os":"plan9"`, 1)
+	if edited == string(sig) {
+		t.Fatalf("statement not found in container")
+	}
+	if err := os.WriteFile(tampered, []byte(edited), 0o644); err != nil {
+		t.Fatalf("write sig: %v", err)
+	}
+	if code, out, _ := verify(allowed, tampered); code != 1 || strings.TrimSpace(out) != "INVALID" {
+		t.Fatalf("expected INVALID for a tampered statement, got %d %q", code, out)
+	}
+
+	var code2 int
+	out = captureStdout(t, func() {
+		code2 = runVerify([]string{"--key", keyPath, "--msg", "hi", "--sig", sigPath, "--commit"})
+	})
+	if code2 != 1 || strings.TrimSpace(out) != "INVALID" {
+		t.Fatalf("expected INVALID without --require-attestation, got %d %q", code2, out)
+	}
+}
+
+// TestRunSign_AttestEnvQuote passes a quote from an attester program to the
+// policy's quote verifier.
+func TestRunSign_AttestEnvQuote(t *testing.T) {
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("attest env quote seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	dir := t.TempDir()
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+	attester := writeHookScript(t, dir, "attester.sh", "cat > "+filepath.Join(dir, "request.json")+"; printf quote")
+	verifierIn := filepath.Join(dir, "verifier.json")
+	verifier := writeHookScript(t, dir, "verifier.sh", "cat > "+verifierIn+"; exit \"$1\"")
+	t.Setenv(envAttester, attester)
+
+	var code int
+	sigHex := strings.TrimSpace(captureStdout(t, func() {
+		code = runSign([]string{"--key", keyPath, "--msg", "hi", "--attest-env"})
+	}))
+	if code != 0 {
+		t.Fatalf("sign failed with %d", code)
+	}
+	request, err := os.ReadFile(filepath.Join(dir, "request.json"))
+	if err != nil {
+		t.Fatalf("attester did not run: %v", err)
+	}
+	reportData := hex.EncodeToString(envReportData(hashing.Default, kp.PublicKey[:], []byte("hi")))
+	if !strings.Contains(string(request), reportData) {
+		t.Fatalf("attester request %q lacks report data %s", request, reportData)
+	}
+
+	for exit, want := range map[string]int{"0": 0, "3": 1} {
+		policy := filepath.Join(dir, "policy"+exit+".json")
+		body := `{"attesters": ["attester.sh"], "quote_verifier": "` + verifier + ` ` + exit + `"}`
+		if err := os.WriteFile(policy, []byte(body), 0o644); err != nil {
+			t.Fatalf("write policy: %v", err)
+		}
+		_, _ = captureStdoutStderr(t, func() {
+			code = runVerify([]string{"--key", keyPath, "--msg", "hi", "--signature", sigHex,
+				"--require-attestation", policy})
+		})
+		if code != want {
+			t.Fatalf("quote verifier exit %s: expected %d, got %d", exit, want, code)
+		}
+		in, err := os.ReadFile(verifierIn)
+		if err != nil || !strings.Contains(string(in), `"quote":"cXVvdGU="`) ||
+			!strings.Contains(string(in), reportData) {
+			t.Fatalf("unexpected quote verifier input %q (%v)", in, err)
+		}
+	}
+}
+
+// TestRunVerify_RequireAttestationErrors covers policy and container errors.
+func TestRunVerify_RequireAttestationErrors(t *testing.T) {
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("attest env errors seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	dir := t.TempDir()
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+	policy := filepath.Join(dir, "policy.json")
+	if err := os.WriteFile(policy, []byte(`{"hostnames": ["x"]}`), 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	var code int
+	stderr := captureStderr(t, func() {
+		code = runVerify([]string{"--key", keyPath, "--msg", "hi", "--signature", "00",
+			"--require-attestation", policy})
+	})
+	if code != 2 || !strings.Contains(stderr, "hostnames") {
+		t.Fatalf("expected unknown policy field error, got %d %q", code, stderr)
+	}
+
+	if err := os.WriteFile(policy, []byte(`{}`), 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	sig, err := kp.Sign([]byte("hi"))
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	out, _ := captureStdoutStderr(t, func() {
+		code = runVerify([]string{"--key", keyPath, "--msg", "hi", "--signature",
+			hex.EncodeToString(sig), "--require-attestation", policy})
+	})
+	if code != 1 || strings.TrimSpace(out) != "INVALID" {
+		t.Fatalf("expected INVALID for a plain signature, got %d %q", code, out)
+	}
+
+	stderr = captureStderr(t, func() {
+		code = runSign([]string{"--key", keyPath, "--msg", "hi", "--attester", "x"})
+	})
+	if code != 2 || !strings.Contains(stderr, "--attester requires --attest-env") {
+		t.Fatalf("expected --attester usage error, got %d %q", code, stderr)
+	}
+}
+
+// TestRunSign_AttestEnvHash checks that the statement records its hash and
+// that verify honors it, including the implied SHA-256 of version 1.
+func TestRunSign_AttestEnvHash(t *testing.T) {
+	t.Setenv(envAttester, "")
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("attest env hash seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	dir := t.TempDir()
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+	policy := filepath.Join(dir, "policy.json")
+	if err := os.WriteFile(policy, []byte(`{}`), 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	verify := func(sig string) int {
+		var code int
+		_, _ = captureStdoutStderr(t, func() {
+			code = runVerify([]string{"--key", keyPath, "--msg", "hi", "--signature", sig,
+				"--require-attestation", policy})
+		})
+		return code
+	}
+
+	var code int
+	sigHex := strings.TrimSpace(captureStdout(t, func() {
+		code = runSign([]string{"--key", keyPath, "--msg", "hi", "--attest-env", "--hash", "blake2b-256"})
+	}))
+	if code != 0 {
+		t.Fatalf("sign failed with %d", code)
+	}
+	container, _ := hex.DecodeString(sigHex)
+	_, st, _, err := splitEnvContainer(container)
+	if err != nil || st.Version != envStatementVersion || st.Hash != "blake2b-256" {
+		t.Fatalf("unexpected statement %+v (err %v)", st, err)
+	}
+	if code := verify(sigHex); code != 0 {
+		t.Fatalf("expected VALID, got %d", code)
+	}
+
+	// A version 1 statement, hashed with SHA-256.
+	statement := []byte(`{"version":1,"tool":"falcon old","hostname_sha256":"00","os":"linux","arch":"amd64"}`)
+	sig, err := kp.Sign(attestedMessage(hashing.SHA256, statement, []byte("hi")))
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if code := verify(hex.EncodeToString(envContainer(statement, sig))); code != 0 {
+		t.Fatalf("expected a version 1 statement to verify, got %d", code)
+	}
+	relabeled := []byte(`{"version":2,"tool":"falcon old","hostname_sha256":"00","os":"linux","arch":"amd64","hash":"sha256"}`)
+	if code := verify(hex.EncodeToString(envContainer(relabeled, sig))); code != 1 {
+		t.Fatalf("expected INVALID for a relabeled statement, got %d", code)
+	}
+
+	_, stderr := captureStdoutStderr(t, func() {
+		code = runSign([]string{"--key", keyPath, "--msg", "hi", "--hash", "sha3-256"})
+	})
+	if code != 2 || !strings.Contains(stderr, "--hash requires --attest-env") {
+		t.Fatalf("expected --hash to require --attest-env, got %d %q", code, stderr)
+	}
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// File locks: the files that falcon reads, changes and writes back (key
+// statistics, second factor enrollments, the receipts index, key files
+// updated in place, pending records) are changed under an advisory lock on
+// <file>.lock, so that concurrent invocations, e.g. parallel CI jobs, do not
+// lose each other's updates. Lock files are left in place: removing one
+// while another process waits on it would let a third take a second lock.
+const lockFileExt = ".lock"
+
+// Locks are polled rather than waited on, so that a stuck process makes the
+// others fail after lockTimeout instead of hanging.
+var (
+	lockTimeout  = 30 * time.Second
+	lockInterval = 20 * time.Millisecond
+)
+
+// lockFile takes an exclusive advisory lock for path, waiting up to
+// lockTimeout for other falcon processes that hold it, and returns the
+// function that releases it.
+func lockFile(path string) (unlock func(), err error) {
+	lockPath := path + lockFileExt
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0o700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(lockPath, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		ok, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", lockPath, err)
+		}
+		if ok {
+			return func() {
+				_ = unlockFile(f)
+				f.Close()
+			}, nil
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("timed out after %v waiting for %s, held by another falcon process",
+				lockTimeout, lockPath)
+		}
+		time.Sleep(lockInterval)
+	}
+}
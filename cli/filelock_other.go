@@ -0,0 +1,15 @@
+//go:build !unix && !windows
+
+package cli
+
+import "os"
+
+// tryLockFile does not lock on platforms without file locks (js/wasm), where
+// a single process runs.
+func tryLockFile(f *os.File) (bool, error) {
+	return true, nil
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/totp"
+)
+
+// TestLockFile checks that a held lock makes others wait and time out, and
+// that it can be taken again once released.
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "state.json")
+	unlock, err := lockFile(path)
+	if err != nil {
+		t.Fatalf("lockFile failed: %v", err)
+	}
+	defer func(d time.Duration) { lockTimeout = d }(lockTimeout)
+	lockTimeout = 100 * time.Millisecond
+	if _, err := lockFile(path); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected a timeout while the lock is held, got %v", err)
+	}
+	unlock()
+	unlock, err = lockFile(path)
+	if err != nil {
+		t.Fatalf("lockFile after unlock failed: %v", err)
+	}
+	unlock()
+	if _, err := os.Stat(path + lockFileExt); err != nil {
+		t.Fatalf("lock file: %v", err)
+	}
+}
+
+// Locks are between processes, so TestLockFile_Stress also signs in child
+// processes that run TestLockChild. TestMain turns key statistics off, so the
+// child is given its file separately.
+const (
+	envLockChildArgs  = "FALCON_TEST_LOCK_ARGS"
+	envLockChildStats = "FALCON_TEST_LOCK_STATS"
+)
+
+func TestLockChild(t *testing.T) {
+	args := os.Getenv(envLockChildArgs)
+	if args == "" {
+		t.Skip("run by TestLockFile_Stress")
+	}
+	os.Setenv(envKeyStats, os.Getenv(envLockChildStats))
+	os.Exit(Run(strings.Split(args, "\n")))
+}
+
+// TestLockFile_Stress has many invocations update key statistics at once;
+// every signature must be counted.
+func TestLockFile_Stress(t *testing.T) {
+	const goroutines, processes = 16, 8
+	dir := t.TempDir()
+	statsPath := filepath.Join(dir, keyStatsFileName)
+	t.Setenv(envKeyStats, statsPath)
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("file lock stress")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, dir, "key.json", kp, true)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines+processes)
+	for i := 0; i < processes; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cmd := exec.Command(os.Args[0], "-test.run=^TestLockChild$")
+			cmd.Env = append(os.Environ(), envLockChildStats+"="+statsPath, envLockChildArgs+"="+strings.Join(
+				[]string{"sign", "--key", keyPath, "--msg", fmt.Sprint("process ", i)}, "\n"))
+			if out, err := cmd.CombinedOutput(); err != nil {
+				errs <- fmt.Errorf("child %d: %v\n%s", i, err, out)
+			}
+		}(i)
+	}
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			recordKeyUse(kp.PublicKey[:], keyPath, "sign", "", 1)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	stats, err := readKeyStats(statsPath)
+	if err != nil {
+		t.Fatalf("readKeyStats failed: %v", err)
+	}
+	fp := falcongo.Fingerprint(kp.PublicKey)
+	u := stats.Keys[hex.EncodeToString(fp[:])]
+	if u == nil || u.Signatures != goroutines+processes || u.Operations["sign"] != goroutines+processes {
+		t.Fatalf("expected %d signatures, got %+v", goroutines+processes, u)
+	}
+}
+
+// TestApproveSecondFactor_Concurrent checks that a code is accepted once when
+// concurrent invocations give it.
+func TestApproveSecondFactor_Concurrent(t *testing.T) {
+	const n = 8
+	useTempUserConfigDir(t)
+	path, err := secondFactorPath()
+	if err != nil {
+		t.Fatalf("secondFactorPath: %v", err)
+	}
+	k := totp.Key{Secret: []byte("12345678901234567890"), Digits: totp.DefaultDigits, Period: totp.DefaultPeriod}
+	const id = "00"
+	sf := secondFactorJSON{Version: secondFactorVersion, Keys: map[string]*totpEnrollmentJSON{
+		id: {Method: secondFactorTOTP, Secret: totp.EncodeSecret(k.Secret), Digits: k.Digits, Period: int(k.Period / time.Second)},
+	}}
+	if err := writeSecondFactors(path, sf); err != nil {
+		t.Fatalf("writeSecondFactors failed: %v", err)
+	}
+	t.Setenv(envTOTPCode, k.Code(k.Counter(time.Now())))
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	approved := 0
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if approveSecondFactor(id, "test") == nil {
+				mu.Lock()
+				approved++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if approved != 1 {
+		t.Fatalf("code accepted %d times", approved)
+	}
+}
+
+// TestAppendReceiptIndex_Concurrent checks that concurrent appends write one
+// header and every row.
+func TestAppendReceiptIndex_Concurrent(t *testing.T) {
+	const n = 32
+	dir := t.TempDir()
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := appendReceiptIndex(filepath.Join(dir, receiptsIndexName), receiptJSON{TxID: fmt.Sprint("TX", i)}); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	rows, err := readReceiptsIndex(dir)
+	if err != nil {
+		t.Fatalf("readReceiptsIndex failed: %v", err)
+	}
+	if len(rows) != n {
+		t.Fatalf("expected %d rows, got %d", n, len(rows))
+	}
+	for _, row := range rows {
+		if !strings.HasPrefix(row["txid"], "TX") {
+			t.Fatalf("unexpected row %v", row)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// ---- algorand heartbeat ----
+func runAlgorandHeartbeat(args []string) int {
+	fs := flag.NewFlagSet("algorand heartbeat", flag.ExitOnError)
+	keyPath := fs.String("key", "", "FALCON keypair JSON of the PQ account")
+	address := fs.String("address", "", "account to report on, instead of --key")
+	txnPath := fs.String("txn", "", "heartbeat transaction (msgpack) prepared by the participation node, to send")
+	networkFlag := fs.String("network", "mainnet", "network: mainnet, testnet, betanet, devnet")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	algodURL := fs.String("algod-url", "", "set algod API endpoint (optional)")
+	algodToken := fs.String("algod-token", "", "set algod API token (optional); requires --algod-url")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	algodURLProvided := false
+	algodTokenProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+		if f.Name == "algod-url" {
+			algodURLProvided = true
+		}
+		if f.Name == "algod-token" {
+			algodTokenProvided = true
+		}
+	})
+
+	if (*keyPath == "") == (*address == "") {
+		fmt.Fprintf(os.Stderr, "provide exactly one of --key or --address\n")
+		return 2
+	}
+	if *txnPath != "" && *keyPath == "" {
+		fmt.Fprintf(os.Stderr, "--txn requires --key: the PQ account signs and pays for the heartbeat\n")
+		return 2
+	}
+	if algodTokenProvided && !algodURLProvided {
+		fmt.Fprintf(os.Stderr, "--algod-token requires --algod-url\n")
+		return 2
+	}
+	netw, err := parseAlgorandNetwork(*networkFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --network: %v\n", err)
+		return 2
+	}
+	if *address != "" {
+		if _, err := types.DecodeAddress(*address); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --address: %v\n", err)
+			return 2
+		}
+	}
+
+	var hb types.Transaction
+	if *txnPath != "" {
+		b, err := os.ReadFile(*txnPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read --txn: %v\n", err)
+			return 2
+		}
+		if hb, err = algorand.DecodeHeartbeatTxn(b); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --txn: %v\n", err)
+			return 2
+		}
+	}
+
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	var kp falcongo.KeyPair
+	if *txnPath != "" {
+		var code int
+		if kp, code = loadSigningKeyPair("--key", *keyPath, override, "sending a heartbeat"); code != 0 {
+			return code
+		}
+	} else if *keyPath != "" {
+		pub, _, _, err := loadKeypairFile(*keyPath, override)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
+			return 2
+		}
+		if len(pub) != len(kp.PublicKey) {
+			fmt.Fprintf(os.Stderr, "public key not found in %s\n", *keyPath)
+			return 2
+		}
+		copy(kp.PublicKey[:], pub)
+	}
+	account := *address
+	if *keyPath != "" {
+		addr, err := algorand.GetAddressFromPublicKey(kp.PublicKey)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error deriving address: %v\n", err)
+			return 2
+		}
+		account = string(addr)
+	}
+
+	if algodURLProvided {
+		if err := setAlgodEnv(strings.TrimSpace(*algodURL), strings.TrimSpace(*algodToken)); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to set ALGOD_URL: %v\n", err)
+			return 2
+		}
+	}
+
+	if *txnPath != "" {
+		txID, err := algorand.SendHeartbeat(kp, hb, algorand.HeartbeatOptions{Network: netw})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "heartbeat failed: %v\n", err)
+			return 2
+		}
+		recordKeyUse(kp.PublicKey[:], *keyPath, "algorand heartbeat", strings.ToLower(strings.TrimSpace(*networkFlag)), 1)
+		fmt.Fprintf(os.Stdout, "Transaction confirmed with id: %s\n", txID)
+		// Report on the account the heartbeat was for.
+		account = hb.HbAddress.String()
+	}
+
+	st, err := algorand.GetHeartbeatStatus(account, netw)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read the account status: %v\n", err)
+		return 2
+	}
+	printHeartbeatStatus(st)
+	return 0
+}
+
+// printHeartbeatStatus prints the heartbeat status of an account followed by
+// scheduling guidance.
+func printHeartbeatStatus(st algorand.HeartbeatStatus) {
+	status := "offline"
+	if st.Online {
+		status = "online"
+	}
+	if st.IncentiveEligible {
+		status += ", incentive eligible"
+	} else {
+		status += ", not incentive eligible"
+	}
+	fmt.Fprintf(os.Stdout, "account: %s\n", st.Address)
+	fmt.Fprintf(os.Stdout, "status: %s\n", status)
+	fmt.Fprintf(os.Stdout, "round: %d\n", st.Round)
+	fmt.Fprintf(os.Stdout, "last heartbeat: %s\n", roundOrNever(st.LastHeartbeat))
+	fmt.Fprintf(os.Stdout, "last proposal: %s\n", roundOrNever(st.LastProposed))
+	if st.AbsenceWindow > 0 {
+		fmt.Fprintf(os.Stdout, "absence window: %d rounds (%s)\n", st.AbsenceWindow, roundsDuration(st.AbsenceWindow))
+	}
+	if st.Deadline > 0 {
+		fmt.Fprintf(os.Stdout, "deadline: round %d\n", st.Deadline)
+	}
+	fmt.Fprintf(os.Stdout, "guidance: %s\n", heartbeatGuidance(st))
+}
+
+// heartbeatGuidance tells when the account of st should send heartbeats.
+func heartbeatGuidance(st algorand.HeartbeatStatus) string {
+	every := max(st.AbsenceWindow/2, 1)
+	schedule := fmt.Sprintf("schedule one every %d rounds (%s) to keep a margin", every, roundsDuration(every))
+	switch {
+	case !st.Online:
+		return "the account is offline; heartbeats only keep online accounts from being suspended"
+	case st.AbsenceWindow == 0:
+		return "the stake is too small for the account to be suspended for absence; no heartbeat is needed"
+	case st.Deadline == 0:
+		return "the account is not tracked yet; send a heartbeat to start, then " + schedule
+	case st.Deadline < st.Round:
+		return fmt.Sprintf("the account has been absent since round %d and may be suspended; send a heartbeat now",
+			st.Deadline)
+	}
+	left := st.Deadline - st.Round
+	return fmt.Sprintf("send a heartbeat (unless the account proposes) within %d rounds (%s); %s",
+		left, roundsDuration(left), schedule)
+}
+
+func roundOrNever(round uint64) string {
+	if round == 0 {
+		return "never"
+	}
+	return fmt.Sprintf("round %d", round)
+}
+
+// roundsDuration formats the approximate duration of rounds.
+func roundsDuration(rounds uint64) string {
+	d := time.Duration(rounds) * algorand.RoundTime
+	switch {
+	case d >= time.Hour:
+		d = d.Round(time.Minute)
+		return fmt.Sprintf("~%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+	case d >= time.Minute:
+		return fmt.Sprintf("~%dm", int(d.Round(time.Minute).Minutes()))
+	default:
+		return fmt.Sprintf("~%ds", int(d.Round(time.Second).Seconds()))
+	}
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+func TestHeartbeatGuidance(t *testing.T) {
+	online := algorand.HeartbeatStatus{Online: true, Round: 10_000, AbsenceWindow: 2000, Deadline: 11_000}
+	for _, tc := range []struct {
+		name string
+		st   algorand.HeartbeatStatus
+		want string
+	}{
+		{"offline", algorand.HeartbeatStatus{}, "the account is offline"},
+		{"tiny stake", algorand.HeartbeatStatus{Online: true}, "no heartbeat is needed"},
+		{"untracked", algorand.HeartbeatStatus{Online: true, AbsenceWindow: 2000},
+			"send a heartbeat to start, then schedule one every 1000 rounds (~47m)"},
+		{"due", online, "within 1000 rounds (~47m); schedule one every 1000 rounds"},
+		{"absent", algorand.HeartbeatStatus{Online: true, Round: 12_000, AbsenceWindow: 2000, Deadline: 11_000},
+			"absent since round 11000"},
+	} {
+		if got := heartbeatGuidance(tc.st); !strings.Contains(got, tc.want) {
+			t.Fatalf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+// TestRunAlgorandHeartbeat reports the status of a PQ account from a fake
+// algod.
+func TestRunAlgorandHeartbeat(t *testing.T) {
+	t.Setenv("ALGOD_URL", "")
+	t.Setenv("ALGOD_TOKEN", "")
+	dir := t.TempDir()
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("heartbeat test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	pubPath := writeKeypairJSON(t, dir, "pub.json", kp, false)
+	addr, err := algorand.GetAddressFromPublicKey(kp.PublicKey)
+	if err != nil {
+		t.Fatalf("GetAddressFromPublicKey failed: %v", err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/accounts/" + string(addr):
+			_ = json.NewEncoder(w).Encode(models.Account{Address: string(addr), Status: "Online",
+				Amount: 10_000_000, IncentiveEligible: true, LastHeartbeat: 9000})
+		case "/v2/ledger/supply":
+			_ = json.NewEncoder(w).Encode(models.Supply{Current_round: 10_000, OnlineMoney: 1_000_000_000})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runAlgorandHeartbeat([]string{"--key", pubPath, "--algod-url", srv.URL})
+	})
+	for _, want := range []string{
+		"account: " + string(addr),
+		"status: online, incentive eligible",
+		"last heartbeat: round 9000",
+		"last proposal: never",
+		"absence window: 2000 rounds",
+		"deadline: round 11000",
+		"guidance: send a heartbeat",
+	} {
+		if code != 0 || !strings.Contains(out, want) {
+			t.Fatalf("got exit %d, output %q; want %q", code, out, want)
+		}
+	}
+}
+
+func TestRunAlgorandHeartbeat_Validation(t *testing.T) {
+	dir := t.TempDir()
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("heartbeat test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+	addr := types.Address{1}.String()
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{nil, "exactly one of --key or --address"},
+		{[]string{"--key", keyPath, "--address", addr}, "exactly one of --key or --address"},
+		{[]string{"--address", addr, "--txn", "hb.txn"}, "--txn requires --key"},
+		{[]string{"--address", "NOPE"}, "invalid --address"},
+		{[]string{"--key", keyPath, "--txn", keyPath}, "invalid --txn"},
+	} {
+		var code int
+		_, stderr := captureStdoutStderr(t, func() { code = runAlgorandHeartbeat(tc.args) })
+		if code != 2 || !strings.Contains(stderr, tc.want) {
+			t.Fatalf("%q: got exit %d, stderr %q; want %q", tc.args, code, stderr, tc.want)
+		}
+	}
+}
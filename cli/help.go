@@ -1,38 +1,57 @@
 package cli
 
 import (
+	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"strings"
+	"time"
 )
 
-// Help text for top-level usage (kept in sync with docs).
-const topHelp = `falcon – FALCON-1024 CLI
-
-Usage:
-  falcon <command> [flags]
-
-Commands:
-  create   Create a new keypair
-  sign     Sign a message
-  verify   Verify a signature for a message
-  info     Display information about a keypair file
-  algorand Algorand utilities (address, send)
-  version  Show the CLI build version
-  help     Show help (general or for a command)
-
-Run 'falcon help <command>' for details.
-`
+// topHelp returns the top-level usage, generated from the command registry.
+func topHelp() string {
+	var b strings.Builder
+	b.WriteString("falcon – FALCON-1024 CLI\n\nUsage:\n  falcon <command> [flags]\n\nCommands:\n")
+	for _, c := range commandRegistry() {
+		summary := c.summary
+		if len(c.subcommands) > 0 {
+			names := make([]string, len(c.subcommands))
+			for i, sub := range c.subcommands {
+				names[i] = sub.name
+			}
+			summary += " (" + strings.Join(names, ", ") + ")"
+		}
+		fmt.Fprintf(&b, "  %-16s%s\n", c.name, summary)
+	}
+	b.WriteString("\nFlags for every command:\n")
+	for _, g := range globalFlags {
+		name := "--" + g.name
+		if g.value != "" {
+			name += " <" + g.value + ">"
+		}
+		fmt.Fprintf(&b, "  %-23s%s\n", name, g.usage)
+	}
+	b.WriteString("\nRun 'falcon help <command>' for details.\n")
+	return b.String()
+}
 
 // ---- help ----
 func runHelp(args []string) int {
-	if len(args) == 0 {
-		fmt.Fprint(os.Stdout, topHelp)
+	fs := flag.NewFlagSet("help", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print the commands, flags and exit codes as JSON")
+	parseFlags(fs, args)
+
+	if *asJSON {
+		return printCommandSchema(fs.Args())
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprint(os.Stdout, topHelp())
 		return 0
 	}
 
-	topic := args[0]
+	topic := fs.Arg(0)
 	// Try built-in help topics.
 	if s, ok := lookupDoc(topic); ok {
 		if _, err := io.Copy(os.Stdout, strings.NewReader(s)); err != nil {
@@ -45,30 +64,167 @@ func runHelp(args []string) int {
 		return 0
 	}
 	// Fallback to simple usage
-	fmt.Fprint(os.Stdout, topHelp)
+	fmt.Fprint(os.Stdout, topHelp())
 	return 0
 }
 
-// lookupDoc returns built-in help text for a command if present.
+// lookupDoc returns built-in help text for a command or help topic if
+// present.
 func lookupDoc(topic string) (string, bool) {
-	switch topic {
-	case "create":
-		return helpCreate, true
-	case "sign":
-		return helpSign, true
-	case "verify":
-		return helpVerify, true
-	case "info":
-		return helpInfo, true
-	case "algorand":
-		return helpAlgorand, true
-	case "version":
-		return helpVersion, true
-	case "help":
-		return helpHelp, true
-	default:
-		return "", false
+	if c, ok := findCommand(append(commandRegistry(), helpTopics()...), topic); ok {
+		return c.help, true
+	}
+	return "", false
+}
+
+// cliSchemaJSON is the output of 'falcon help --json'.
+type cliSchemaJSON struct {
+	Name        string              `json:"name"`
+	Version     string              `json:"version"`
+	GlobalFlags []flagSchemaJSON    `json:"global_flags"`
+	ExitCodes   []exitCodeJSON      `json:"exit_codes"`
+	Commands    []commandSchemaJSON `json:"commands"`
+}
+
+// commandSchemaJSON describes a command: its flags if it has no
+// subcommands, its subcommands otherwise.
+type commandSchemaJSON struct {
+	Name        string              `json:"name"`
+	Path        string              `json:"path"`
+	Aliases     []string            `json:"aliases,omitempty"`
+	Summary     string              `json:"summary"`
+	Args        string              `json:"args,omitempty"`
+	Flags       []flagSchemaJSON    `json:"flags,omitempty"`
+	Subcommands []commandSchemaJSON `json:"subcommands,omitempty"`
+	ExitCodes   []exitCodeJSON      `json:"exit_codes"`
+}
+
+// flagSchemaJSON describes a flag. Type is bool, string, int, int64, uint,
+// uint64, float64 or duration; flags parsed by a function (repeatable ones)
+// are strings.
+type flagSchemaJSON struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Default string `json:"default,omitempty"`
+	Usage   string `json:"usage"`
+}
+
+type exitCodeJSON struct {
+	Code        int    `json:"code"`
+	Description string `json:"description"`
+}
+
+// Exit codes shared by every command; see command.exit1 for code 1.
+const (
+	exitSuccessDescription = "success"
+	exitUsageDescription   = "usage error, invalid input or failure"
+)
+
+// printCommandSchema prints the schema of the CLI, or of the command at path
+// (e.g. "algorand address").
+func printCommandSchema(path []string) int {
+	var v any = cliSchema()
+	if len(path) > 0 {
+		var c command
+		cmds, parent := commandRegistry(), ""
+		for i, name := range path {
+			if i > 0 {
+				parent = strings.TrimSpace(parent + " " + c.name)
+			}
+			var ok bool
+			if c, ok = findCommand(cmds, name); !ok {
+				fmt.Fprintf(os.Stderr, "unknown command: %s\n", strings.TrimSpace(parent+" "+name))
+				return 2
+			}
+			cmds = c.subcommands
+		}
+		v = commandSchema(c, parent)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write help: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+// cliSchema describes the CLI: its global flags and all its commands.
+func cliSchema() cliSchemaJSON {
+	s := cliSchemaJSON{
+		Name:    "falcon",
+		Version: buildVersion(),
+		ExitCodes: []exitCodeJSON{
+			{Code: 0, Description: exitSuccessDescription},
+			{Code: 1, Description: "negative result of a check, as described by the command"},
+			{Code: 2, Description: exitUsageDescription},
+		},
+	}
+	for _, g := range globalFlags {
+		typ := "bool"
+		if g.value != "" {
+			typ = "string"
+		}
+		s.GlobalFlags = append(s.GlobalFlags, flagSchemaJSON{Name: g.name, Type: typ, Usage: g.usage})
+	}
+	for _, c := range commandRegistry() {
+		s.Commands = append(s.Commands, commandSchema(c, ""))
+	}
+	return s
+}
+
+// commandSchema describes c, a subcommand of the group at parent ("" for
+// top-level commands).
+func commandSchema(c command, parent string) commandSchemaJSON {
+	s := commandSchemaJSON{
+		Name:      c.name,
+		Path:      strings.TrimSpace(parent + " " + c.name),
+		Aliases:   c.aliases,
+		Summary:   c.summary,
+		Args:      c.args,
+		ExitCodes: []exitCodeJSON{{Code: 0, Description: exitSuccessDescription}},
+	}
+	if c.exit1 != "" {
+		s.ExitCodes = append(s.ExitCodes, exitCodeJSON{Code: 1, Description: c.exit1})
+	}
+	s.ExitCodes = append(s.ExitCodes, exitCodeJSON{Code: 2, Description: exitUsageDescription})
+	for _, sub := range c.subcommands {
+		s.Subcommands = append(s.Subcommands, commandSchema(sub, s.Path))
+	}
+	if len(c.subcommands) > 0 {
+		return s
+	}
+	if fs := commandFlags(c); fs != nil {
+		fs.VisitAll(func(f *flag.Flag) {
+			s.Flags = append(s.Flags, flagSchema(f))
+		})
 	}
+	return s
+}
+
+// flagSchema describes f, taking its type from the value it holds.
+func flagSchema(f *flag.Flag) flagSchemaJSON {
+	s := flagSchemaJSON{Name: f.Name, Type: "string", Default: f.DefValue, Usage: f.Usage}
+	if g, ok := f.Value.(flag.Getter); ok {
+		switch g.Get().(type) {
+		case bool:
+			s.Type = "bool"
+		case int:
+			s.Type = "int"
+		case int64:
+			s.Type = "int64"
+		case uint:
+			s.Type = "uint"
+		case uint64:
+			s.Type = "uint64"
+		case float64:
+			s.Type = "float64"
+		case time.Duration:
+			s.Type = "duration"
+		}
+	}
+	return s
 }
 
 const helpHelp = `# falcon help
@@ -78,4 +234,13 @@ Show general help or per-command help.
 Usage:
   falcon help
   falcon help <command>
+  falcon help --json [<command> [<subcommand>]]
+
+With --json, print a description of the commands for wrapper UIs and
+automation: for each command, its summary, its positional arguments, its
+subcommands or flags (name, type, default and usage) and what its exit codes
+mean. Types are bool, string, int, int64, uint, uint64, float64 and duration.
+Exit code 0 is success and 2 a usage error, invalid input or failure;
+commands that report a negative result (e.g. an invalid signature) with exit
+code 1 say so.
 `
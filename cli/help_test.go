@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestHelpJSON checks that 'falcon help --json' describes every command of
+// the registry, with the flags its run function parses.
+func TestHelpJSON(t *testing.T) {
+	// The full schema is larger than a pipe buffer, so it is built
+	// directly; runHelp is checked with a single command below.
+	schema := cliSchema()
+	if len(schema.Commands) != len(commandRegistry()) || len(schema.GlobalFlags) != len(globalFlags) {
+		t.Fatalf("help --json lists %d commands and %d global flags", len(schema.Commands), len(schema.GlobalFlags))
+	}
+
+	// Every command without subcommands must reach parseFlags, or its
+	// flags could not be described (and describing it would run it).
+	var leaves []commandSchemaJSON
+	var walk func(c command, parent string)
+	walk = func(c command, parent string) {
+		path := strings.TrimSpace(parent + " " + c.name)
+		if len(c.subcommands) == 0 {
+			if commandFlags(c) == nil {
+				t.Errorf("%s: no flag set reaches parseFlags", path)
+			}
+			return
+		}
+		for _, sub := range c.subcommands {
+			walk(sub, path)
+		}
+	}
+	for _, c := range commandRegistry() {
+		walk(c, "")
+	}
+	var collect func(c commandSchemaJSON)
+	collect = func(c commandSchemaJSON) {
+		leaves = append(leaves, c)
+		for _, sub := range c.Subcommands {
+			collect(sub)
+		}
+	}
+	for _, c := range schema.Commands {
+		collect(c)
+	}
+
+	flagType := func(path, name string) string {
+		for _, c := range leaves {
+			if c.Path != path {
+				continue
+			}
+			for _, f := range c.Flags {
+				if f.Name == name {
+					return f.Type
+				}
+			}
+		}
+		return ""
+	}
+	for _, tc := range []struct{ path, flag, typ string }{
+		{"sign", "sig-encoding", "string"},
+		{"verify", "stream", "bool"},
+		{"algorand address", "workers", "int"},
+		{"algorand address", "teal-version", "uint"},
+		{"auth challenge", "ttl", "duration"},
+		{"csr create", "attr", "string"},
+		{"help", "json", "bool"},
+	} {
+		if got := flagType(tc.path, tc.flag); got != tc.typ {
+			t.Errorf("%s --%s: type %q, want %q", tc.path, tc.flag, got, tc.typ)
+		}
+	}
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runHelp([]string{"--json", "verify"})
+	})
+	var verify commandSchemaJSON
+	if err := json.Unmarshal([]byte(out), &verify); err != nil || code != 0 {
+		t.Fatalf("help --json verify: exit %d, %v", code, err)
+	}
+	if len(verify.ExitCodes) != 3 || verify.ExitCodes[1].Code != 1 {
+		t.Fatalf("help --json verify exit codes: %+v", verify.ExitCodes)
+	}
+	stderr := captureStderr(t, func() {
+		code = runHelp([]string{"--json", "keys", "nope"})
+	})
+	if code != 2 || !strings.Contains(stderr, "keys nope") {
+		t.Fatalf("help --json keys nope: exit %d, %q", code, stderr)
+	}
+}
+
+// TestTopHelp checks that the generated usage lists every command and that
+// every command and help topic has a help page.
+func TestTopHelp(t *testing.T) {
+	usage := topHelp()
+	for _, c := range commandRegistry() {
+		if !strings.Contains(usage, "\n  "+c.name+" ") {
+			t.Errorf("top-level help does not list %s", c.name)
+		}
+	}
+	for _, c := range append(commandRegistry(), helpTopics()...) {
+		if s, ok := lookupDoc(c.name); !ok || !strings.HasPrefix(s, "# falcon") {
+			t.Errorf("no help page for %s", c.name)
+		}
+	}
+	var code int
+	stderr := captureStderr(t, func() {
+		code = runKeys([]string{"nope"})
+	})
+	if code != 2 || !strings.Contains(stderr, "usage: falcon keys <list|canonicalize|diff|check|destroy|add-passphrase-account|set-policy|totp-enroll|totp-remove>") {
+		t.Fatalf("keys nope: exit %d, %q", code, stderr)
+	}
+}
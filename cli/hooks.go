@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Environment variables consulted when --pre-hook/--post-hook are not given.
+const (
+	envPreHook  = "FALCON_PRE_HOOK"
+	envPostHook = "FALCON_POST_HOOK"
+)
+
+// hookEvent is the JSON document written to a hook's stdin.
+type hookEvent struct {
+	Stage     string `json:"stage"`     // "pre" or "post"
+	Operation string `json:"operation"` // "sign" or "algorand send"
+	KeyFile   string `json:"key_file"`
+	PublicKey string `json:"public_key,omitempty"`
+
+	// sign
+	Message    string `json:"message,omitempty"` // hex
+	Commitment string `json:"commitment,omitempty"`
+	Signature  string `json:"signature,omitempty"`
+
+	// algorand send
+	Network string `json:"network,omitempty"`
+	From    string `json:"from,omitempty"`
+	To      string `json:"to,omitempty"`
+	Amount  uint64 `json:"amount,omitempty"`
+	Fee     uint64 `json:"fee,omitempty"`
+	Note    string `json:"note,omitempty"`
+	RekeyTo string `json:"rekey_to,omitempty"`
+	TxID    string `json:"txid,omitempty"`
+}
+
+// flagOrEnv returns the trimmed flag value when the flag was set, and the
+// environment variable envName otherwise.
+func flagOrEnv(flagValue string, flagSet bool, envName string) string {
+	if flagSet {
+		return strings.TrimSpace(flagValue)
+	}
+	return strings.TrimSpace(os.Getenv(envName))
+}
+
+// runHook executes command (split on whitespace, no shell) with ev as JSON on
+// stdin. The hook's own output goes to stderr so it never mixes with command
+// output. A non-zero exit status is returned as an error.
+func runHook(command string, ev hookEvent) error {
+	if command == "" {
+		return nil
+	}
+	argv := strings.Fields(command)
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("encode hook event: %w", err)
+	}
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		var ee *exec.ExitError
+		if errors.As(err, &ee) {
+			return fmt.Errorf("%s hook %q exited with code %d", ev.Stage, argv[0], ee.ExitCode())
+		}
+		return fmt.Errorf("%s hook %q failed: %w", ev.Stage, argv[0], err)
+	}
+	return nil
+}
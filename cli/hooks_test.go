@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// writeHookScript writes an executable shell script for hook tests.
+func writeHookScript(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("hook scripts require a POSIX shell")
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+		t.Fatalf("write hook script: %v", err)
+	}
+	return path
+}
+
+// TestRunSign_HooksReceiveEvents checks both hooks run with the expected JSON.
+func TestRunSign_HooksReceiveEvents(t *testing.T) {
+	t.Setenv(envPreHook, "")
+	t.Setenv(envPostHook, "")
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("hook events seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	dir := t.TempDir()
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+	preOut := filepath.Join(dir, "pre.json")
+	postOut := filepath.Join(dir, "post.json")
+	pre := writeHookScript(t, dir, "pre.sh", "cat > "+preOut)
+	post := writeHookScript(t, dir, "post.sh", "cat > \"$1\"")
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runSign([]string{"--key", keyPath, "--msg", "hi", "--pre-hook", pre,
+			"--post-hook", post + " " + postOut})
+	})
+	if code != 0 {
+		t.Fatalf("expected exit 0, got %d", code)
+	}
+
+	var preEv, postEv hookEvent
+	for path, ev := range map[string]*hookEvent{preOut: &preEv, postOut: &postEv} {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("hook did not run: %v", err)
+		}
+		if err := json.Unmarshal(b, ev); err != nil {
+			t.Fatalf("invalid hook JSON: %v", err)
+		}
+	}
+	if preEv.Stage != "pre" || preEv.Operation != "sign" || preEv.Message != "6869" || preEv.Signature != "" {
+		t.Fatalf("unexpected pre event: %+v", preEv)
+	}
+	if postEv.Stage != "post" || postEv.Signature != strings.TrimSpace(out) {
+		t.Fatalf("unexpected post event: %+v", postEv)
+	}
+}
+
+// TestRunSign_HookAborts ensures a failing hook prevents signature output.
+func TestRunSign_HookAborts(t *testing.T) {
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("hook abort seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	dir := t.TempDir()
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+	deny := writeHookScript(t, dir, "deny.sh", "exit 3")
+
+	for _, stage := range []string{"pre", "post"} {
+		t.Run(stage, func(t *testing.T) {
+			t.Setenv(envPreHook, "")
+			t.Setenv(envPostHook, "")
+			if stage == "pre" {
+				t.Setenv(envPreHook, deny)
+			} else {
+				t.Setenv(envPostHook, deny)
+			}
+			var code int
+			stdout, stderr := captureStdoutStderr(t, func() {
+				code = runSign([]string{"--key", keyPath, "--msg", "hi"})
+			})
+			if code != 2 {
+				t.Fatalf("expected exit 2, got %d", code)
+			}
+			if stdout != "" {
+				t.Fatalf("expected no signature output, got %q", stdout)
+			}
+			if !strings.Contains(stderr, stage+" hook") || !strings.Contains(stderr, "exited with code 3") {
+				t.Fatalf("unexpected stderr: %q", stderr)
+			}
+		})
+	}
+}
+
+// TestRunAlgorandSend_PreHookAborts ensures a failing pre-hook stops the send
+// before any network access.
+func TestRunAlgorandSend_PreHookAborts(t *testing.T) {
+	t.Setenv("ALGOD_URL", "")
+	t.Setenv("ALGOD_TOKEN", "")
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("send hook seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	dir := t.TempDir()
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+	eventPath := filepath.Join(dir, "event.json")
+	deny := writeHookScript(t, dir, "deny.sh", "cat > "+eventPath+"; exit 1")
+
+	var addr types.Address
+	var code int
+	_, stderr := captureStdoutStderr(t, func() {
+		code = runAlgorandSend([]string{"--key", keyPath, "--to", addr.String(),
+			"--amount", "5", "--network", "devnet", "--pre-hook", deny})
+	})
+	if code != 2 {
+		t.Fatalf("expected exit 2, got %d", code)
+	}
+	if !strings.Contains(stderr, "send aborted: pre hook") {
+		t.Fatalf("unexpected stderr: %q", stderr)
+	}
+	b, err := os.ReadFile(eventPath)
+	if err != nil {
+		t.Fatalf("hook did not run: %v", err)
+	}
+	var ev hookEvent
+	if err := json.Unmarshal(b, &ev); err != nil {
+		t.Fatalf("invalid hook JSON: %v", err)
+	}
+	if ev.Operation != "algorand send" || ev.Amount != 5 || ev.To != addr.String() || ev.From == "" {
+		t.Fatalf("unexpected event: %+v", ev)
+	}
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"crypto/ed25519"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// ---- algorand hybrid-address ----
+func runAlgorandHybridAddress(args []string) int {
+	fs := flag.NewFlagSet("algorand hybrid-address", flag.ExitOnError)
+	keyPath := fs.String("key", "", "path to FALCON keypair JSON file (public key sufficient)")
+	edAddress := fs.String("ed25519", "", "Algorand address of the Ed25519 key that must also sign")
+	out := fs.String("out", "", "write derived address to file (stdout if empty)")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+	})
+
+	if *keyPath == "" || *edAddress == "" {
+		fmt.Fprintf(os.Stderr, "--key and --ed25519 are required\n")
+		return 2
+	}
+	edKey, err := types.DecodeAddress(*edAddress)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --ed25519: %v\n", err)
+		return 2
+	}
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	pub, _, _, err := loadKeypairFile(*keyPath, override)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", *keyPath, err)
+		return 2
+	}
+	if pub == nil {
+		fmt.Fprintf(os.Stderr, "public key not found in %s\n", *keyPath)
+		return 2
+	}
+	var pk falcongo.PublicKey
+	copy(pk[:], pub)
+
+	address, _, err := algorand.DeriveHybridAddress(pk, ed25519.PublicKey(edKey[:]))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error deriving address: %v\n", err)
+		return 2
+	}
+
+	if *out == "" {
+		fmt.Fprintln(os.Stdout, address.String())
+		return 0
+	}
+	if err := writeFileAtomic(*out, []byte(address.String()), 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *out, err)
+		return 2
+	}
+	return 0
+}
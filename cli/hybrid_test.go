@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestRunAlgorandHybridAddress checks the address printed for a FALCON key
+// and an Ed25519 address, and the usage errors.
+func TestRunAlgorandHybridAddress(t *testing.T) {
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("hybrid address test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, t.TempDir(), "keys.json", kp, false)
+	edKey := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{7}, ed25519.SeedSize)).Public().(ed25519.PublicKey)
+	var edAddress types.Address
+	copy(edAddress[:], edKey)
+
+	var code int
+	stdout, stderr := captureStdoutStderr(t, func() {
+		code = runAlgorandHybridAddress([]string{"--key", keyPath, "--ed25519", edAddress.String()})
+	})
+	if code != 0 {
+		t.Fatalf("expected exit 0, got %d: %s", code, stderr)
+	}
+	want, _, err := algorand.DeriveHybridAddress(kp.PublicKey, edKey)
+	if err != nil {
+		t.Fatalf("DeriveHybridAddress failed: %v", err)
+	}
+	if got := strings.TrimSpace(stdout); got != want.String() {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+
+	for _, args := range [][]string{
+		{"--key", keyPath},
+		{"--ed25519", edAddress.String()},
+		{"--key", keyPath, "--ed25519", "NOTANADDRESS"},
+	} {
+		_, _ = captureStdoutStderr(t, func() { code = runAlgorandHybridAddress(args) })
+		if code != 2 {
+			t.Errorf("%v: expected exit 2, got %d", args, code)
+		}
+	}
+	_, stderr = captureStdoutStderr(t, func() {
+		code = runAlgorandSend([]string{"--key", keyPath, "--to", edAddress.String(), "--amount", "1",
+			"--delegation", "d.json", "--ed25519-mnemonic", "-"})
+	})
+	if code != 2 || !strings.Contains(stderr, "mutually exclusive") {
+		t.Fatalf("send with --delegation and --ed25519-mnemonic: exit %d: %s", code, stderr)
+	}
+}
@@ -1,11 +1,14 @@
 package cli
 
 import (
+	"bytes"
 	"encoding/hex"
 	"flag"
 	"fmt"
 	"os"
 	"strings"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
 )
 
 // ---- info ----
@@ -13,7 +16,10 @@ func runInfo(args []string) int {
 	fs := flag.NewFlagSet("info", flag.ExitOnError)
 	keyPath := fs.String("key", "", "path to keypair JSON file")
 	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
-	_ = fs.Parse(args)
+	sigFile := fs.String("sig", "", "inspect the signature in this file instead of a key")
+	sigHex := fs.String("signature", "", "inspect this hex or base64 signature instead of a key")
+	listAccounts := fs.Bool("list-passphrase-accounts", false, "list the passphrase accounts recorded in the key file")
+	parseFlags(fs, args)
 	passphraseProvided := false
 	fs.Visit(func(f *flag.Flag) {
 		if f.Name == "mnemonic-passphrase" {
@@ -21,6 +27,13 @@ func runInfo(args []string) int {
 		}
 	})
 
+	if *sigFile != "" || *sigHex != "" {
+		if *keyPath != "" || (*sigFile != "" && *sigHex != "") {
+			fmt.Fprintf(os.Stderr, "provide exactly one of --key, --sig or --signature\n")
+			return 2
+		}
+		return infoSignature(*sigFile, *sigHex)
+	}
 	if *keyPath == "" {
 		fmt.Fprintf(os.Stderr, "--key is required\n")
 		return 2
@@ -30,6 +43,9 @@ func runInfo(args []string) int {
 	if passphraseProvided {
 		override = mnemonicPassphrase
 	}
+	if *listAccounts {
+		return listPassphraseAccounts(*keyPath, override)
+	}
 	pub, priv, meta, err := loadKeypairFile(*keyPath, override)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
@@ -41,8 +57,27 @@ func runInfo(args []string) int {
 		return 2
 	}
 
+	if priv != nil {
+		derived, err := publicKeyFromPrivate(priv)
+		switch {
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "warning: cannot derive public key: %v\n", err)
+		case pub == nil:
+			// Private-only file: show the public key recomputed from it.
+			pub = derived
+		case !bytes.Equal(pub, derived):
+			fmt.Fprintf(os.Stderr, "warning: public_key does not match private_key (see 'falcon keys check')\n")
+		}
+	}
+	var attestationErr error
+	if meta.KeyAttestation != nil && pub != nil {
+		attestationErr = checkKeyAttestation(meta.KeyAttestation, pub, priv)
+	}
 	if pub != nil {
 		fmt.Printf("public_key: %s\n", strings.ToLower(hex.EncodeToString(pub)))
+		fingerprint := publicKeyFingerprint(hex.EncodeToString(pub))
+		fmt.Printf("fingerprint: %s\n", fingerprint)
+		fmt.Printf("petname: %s\n", fingerprintPetname(fingerprint))
 	}
 	if priv != nil {
 		fmt.Printf("private_key: %s\n", strings.ToLower(hex.EncodeToString(priv)))
@@ -57,18 +92,103 @@ func runInfo(args []string) int {
 			fmt.Printf("mnemonic_passphrase: %s\n", pass)
 		}
 	}
+	if meta.MessagePolicy != nil {
+		fmt.Printf("message_policy: %s\n", messagePolicyString(meta.MessagePolicy))
+	}
+	if meta.KeyAttestation != nil {
+		if attestationErr != nil {
+			fmt.Printf("key_attestation: invalid\n")
+			fmt.Fprintf(os.Stderr, "%v\n", attestationErr)
+			return 1
+		}
+		fmt.Printf("key_attestation: valid (created %s)\n", meta.KeyAttestation.CreatedAt)
+	}
+	return 0
+}
+
+// infoSignature prints the header fields of a signature read from sigFile or
+// given as hex, and the reasons Verify would reject its encoding, if any.
+func infoSignature(sigFile, sigHex string) int {
+	var sig []byte
+	var err error
+	if sigFile != "" {
+		if sig, err = os.ReadFile(sigFile); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read --sig: %v\n", err)
+			return 2
+		}
+		// Detected like falcon verify does; raw otherwise.
+		sig, _ = decodeSignature(sig, sigEncodingAuto, true)
+	} else if sig, err = decodeSignature([]byte(sigHex), sigEncodingAuto, false); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --signature %s: %v\n", signatureTextEncodings(sigEncodingAuto), err)
+		return 2
+	}
+	// A signature with its version and form bytes (falcongo.Signature) is
+	// inspected without them.
+	var typed falcongo.Signature
+	wrapped := len(sig) > 0 && sig[0] == falcongo.SignatureEncodingVersion
+	if wrapped {
+		if err := typed.UnmarshalBinary(sig); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 2
+		}
+		sig = typed.Bytes()
+	}
+	info, err := falcongo.InspectSignature(sig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 2
+	}
+	if wrapped {
+		fmt.Printf("encoding_version: %d\n", falcongo.SignatureEncodingVersion)
+	}
+
+	fmt.Printf("length: %d\n", info.Length)
+	fmt.Printf("header: 0x%02x\n", info.Header)
+	fmt.Printf("encoding: %s\n", info.Encoding)
+	fmt.Printf("logn: %d\n", info.LogN)
+	if info.Deterministic {
+		fmt.Printf("variant: deterministic\n")
+		fmt.Printf("salt_version: %d\n", info.SaltVersion)
+	} else {
+		fmt.Printf("variant: salted\n")
+		if info.Nonce != nil {
+			fmt.Printf("nonce: %s\n", hex.EncodeToString(info.Nonce))
+		}
+	}
+	if len(info.Issues) == 0 {
+		fmt.Printf("verifiable: yes\n")
+	} else {
+		fmt.Printf("verifiable: no\n")
+		for _, issue := range info.Issues {
+			fmt.Printf("issue: %s\n", issue)
+		}
+	}
 	return 0
 }
 
 const helpInfo = `# falcon info
 
-Display info about a keypair JSON file.
+Display info about a keypair JSON file, or inspect a signature.
+
+For a key, prints the public key with its fingerprint (SHA-256 of the public
+key) and petname (three words derived from the fingerprint, to recognize the
+key at a glance), the private key and the mnemonic.
 
 Arguments:
   --key <file>   path to keypair JSON
   --mnemonic-passphrase <string>
                  mnemonic passphrase if needed and the key file omits it
+  --sig <file> | --signature <hex|base64>
+                 instead of --key: print the signature's length, header, encoding,
+                 variant (deterministic salt version or salted nonce) and whether
+                 'falcon verify' can accept its encoding, with the reasons if not
+  --list-passphrase-accounts
+                 list the hint, address and fingerprint of the passphrase accounts
+                 recorded in --key (see 'falcon keys add-passphrase-account'); with
+                 --mnemonic-passphrase, mark the account it opens, or exit 1 if none
 
-Example:
+Examples:
   falcon info --key mykeys.json
+  falcon info --key mykeys.json --list-passphrase-accounts
+  falcon info --sig payload.sig
 `
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/mnemonic"
 )
 
 // captureStderr captures os.Stderr output produced by fn and returns it as a string.
@@ -49,6 +50,10 @@ func TestRunInfo_PrintsBothKeys(t *testing.T) {
 	if !strings.Contains(out, "public_key:") || !strings.Contains(out, "private_key:") {
 		t.Fatalf("expected both keys in output, got: %q", out)
 	}
+	fp := falcongo.Fingerprint(kp.PublicKey)
+	if !strings.Contains(out, "fingerprint: "+hex.EncodeToString(fp[:])+"\npetname: "+mnemonic.Petname(fp)+"\n") {
+		t.Fatalf("expected fingerprint and petname in output, got: %q", out)
+	}
 }
 
 // TestRunInfo_PublicOnly checks output when only the public key exists.
@@ -176,3 +181,64 @@ func TestRunInfo_InvalidPrivateHex_Returns2(t *testing.T) {
 		t.Fatalf("unexpected error: %q", errOut)
 	}
 }
+
+// TestRunInfo_Signature inspects a signature file and a hex signature.
+func TestRunInfo_Signature(t *testing.T) {
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("info signature seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	sig, err := kp.Sign([]byte("info signature"))
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	sigPath := filepath.Join(t.TempDir(), "msg.sig")
+	if err := os.WriteFile(sigPath, sig, 0o644); err != nil {
+		t.Fatalf("write sig: %v", err)
+	}
+
+	var code int
+	out := captureStdout(t, func() { code = runInfo([]string{"--sig", sigPath}) })
+	if code != 0 {
+		t.Fatalf("expected exit 0, got %d", code)
+	}
+	for _, want := range []string{"header: 0xba", "encoding: compressed", "logn: 10",
+		"variant: deterministic", "salt_version: 0", "verifiable: yes"} {
+		if !strings.Contains(out, want+"\n") {
+			t.Fatalf("missing %q in output: %q", want, out)
+		}
+	}
+
+	ct, err := falcongo.GetFixedLengthSignature(sig)
+	if err != nil {
+		t.Fatalf("GetFixedLengthSignature failed: %v", err)
+	}
+	out = captureStdout(t, func() {
+		code = runInfo([]string{"--signature", hex.EncodeToString(ct)})
+	})
+	if code != 0 {
+		t.Fatalf("expected exit 0, got %d", code)
+	}
+	if !strings.Contains(out, "encoding: ct\n") || !strings.Contains(out, "verifiable: no\n") ||
+		!strings.Contains(out, "issue: ") {
+		t.Fatalf("unexpected output for CT signature: %q", out)
+	}
+}
+
+// TestRunInfo_Signature_Errors checks usage and input errors return 2.
+func TestRunInfo_Signature_Errors(t *testing.T) {
+	cases := [][]string{
+		{"--sig", "a.sig", "--signature", "00"},
+		{"--key", "k.json", "--signature", "00"},
+		{"--signature", "zz"},
+		{"--signature", ""},
+		{"--sig", filepath.Join(t.TempDir(), "missing.sig")},
+	}
+	for _, args := range cases {
+		var code int
+		errOut := captureStderr(t, func() { code = runInfo(args) })
+		if code != 2 || errOut == "" {
+			t.Fatalf("%v: expected exit 2 with stderr, got %d (%q)", args, code, errOut)
+		}
+	}
+}
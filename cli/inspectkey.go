@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo/lattice"
+)
+
+// inspectKeyJSON is the output of inspect-key --json.
+type inspectKeyJSON struct {
+	Fingerprint  string          `json:"fingerprint"`
+	LogN         int             `json:"logn"`
+	N            int             `json:"n"`
+	Q            int             `json:"q"`
+	Summary      lattice.Summary `json:"summary"`
+	Coefficients []uint16        `json:"coefficients,omitempty"`
+}
+
+// ---- inspect-key ----
+func runInspectKey(args []string) int {
+	fs := flag.NewFlagSet("inspect-key", flag.ExitOnError)
+	keyPath := fs.String("key", "", "path to keypair or public key JSON file")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	coefficients := fs.Bool("coefficients", false, "also print the coefficients of the public key polynomial")
+	jsonOut := fs.Bool("json", false, "print the result as JSON")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+	})
+
+	if *keyPath == "" {
+		fmt.Fprintf(os.Stderr, "--key is required\n")
+		return 2
+	}
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	pub, priv, _, err := loadKeypairFile(*keyPath, override)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
+		return 2
+	}
+	if pub == nil && priv != nil {
+		// Private-only file: inspect the public key recomputed from it.
+		if pub, err = publicKeyFromPrivate(priv); err != nil {
+			fmt.Fprintf(os.Stderr, "cannot derive public key: %v\n", err)
+			return 2
+		}
+	}
+	if pub == nil {
+		fmt.Fprintf(os.Stderr, "public key not found in %s\n", *keyPath)
+		return 2
+	}
+	h, err := lattice.ParsePublicKeyCoefficients(pub)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", *keyPath, err)
+		return 2
+	}
+
+	result := inspectKeyJSON{
+		Fingerprint: publicKeyFingerprint(hex.EncodeToString(pub)),
+		LogN:        lattice.LogN,
+		N:           lattice.N,
+		Q:           lattice.Q,
+		Summary:     lattice.Summarize(h),
+	}
+	if *coefficients {
+		result.Coefficients = h
+	}
+	if *jsonOut {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to encode result: %v\n", err)
+			return 2
+		}
+		fmt.Fprintf(os.Stdout, "%s\n", data)
+		return 0
+	}
+	s := result.Summary
+	fmt.Printf("fingerprint: %s\n", result.Fingerprint)
+	fmt.Printf("logn: %d\nn: %d\nq: %d\n", result.LogN, result.N, result.Q)
+	fmt.Printf("min: %d\nmax: %d\n", s.Min, s.Max)
+	fmt.Printf("mean: %.2f (uniform: %.2f)\n", s.Mean, (lattice.Q-1)/2.0)
+	fmt.Printf("variance: %.0f (uniform: %.0f)\n", s.Variance, (lattice.Q*lattice.Q-1)/12.0)
+	fmt.Printf("chi_square: %.2f (%d bins, %d degrees of freedom)\n",
+		s.ChiSquare, lattice.ChiSquareBins, lattice.ChiSquareBins-1)
+	if *coefficients {
+		words := make([]string, len(h))
+		for i, c := range h {
+			words[i] = strconv.Itoa(int(c))
+		}
+		fmt.Printf("coefficients: %s\n", strings.Join(words, " "))
+	}
+	return 0
+}
+
+const helpInspectKey = `# falcon inspect-key
+
+Show the polynomial structure of a FALCON-1024 public key, for research tooling.
+
+The public key is the polynomial h = g/f mod (x^1024 + 1, q), q = 12289. The
+command decodes its 1024 coefficients and prints their minimum, maximum, mean,
+variance and a chi-squared statistic against the uniform distribution on
+[0, q), which generated keys should be close to.
+
+Arguments:
+  --key <file>     keypair or public key JSON (required); the public key of a
+                   private-only file is recomputed
+  --coefficients   also print the coefficients h[0] ... h[1023], in order
+  --json           print the result as JSON
+  --mnemonic-passphrase <string>
+                   mnemonic passphrase if needed and the key file omits it
+
+Examples:
+  falcon inspect-key --key mykeys.json
+  falcon inspect-key --key mykeys.json --coefficients --json
+`
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/falcongo/lattice"
+)
+
+// TestRunInspectKey checks the coefficients printed for a generated key.
+func TestRunInspectKey(t *testing.T) {
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("inspect key seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	dir := t.TempDir()
+	pubPath := writeKeypairJSON(t, dir, "pub.json", kp, false)
+	want, err := lattice.ParsePublicKeyCoefficients(kp.PublicKey[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runInspectKey([]string{"--key", pubPath, "--coefficients", "--json"})
+	})
+	var result inspectKeyJSON
+	if err := json.Unmarshal([]byte(out), &result); err != nil || code != 0 {
+		t.Fatalf("exit %d, %v: %q", code, err, out)
+	}
+	if result.N != 1024 || result.Q != 12289 || len(result.Coefficients) != len(want) ||
+		result.Coefficients[7] != want[7] || result.Summary != lattice.Summarize(want) {
+		t.Fatalf("unexpected result %+v", result.Summary)
+	}
+
+	out = captureStdout(t, func() { code = runInspectKey([]string{"--key", pubPath}) })
+	if code != 0 || !strings.Contains(out, "fingerprint: "+result.Fingerprint) ||
+		!strings.Contains(out, "chi_square: ") || strings.Contains(out, "coefficients:") {
+		t.Fatalf("exit %d, %q", code, out)
+	}
+
+	stderr := captureStderr(t, func() { code = runInspectKey(nil) })
+	if code != 2 || !strings.Contains(stderr, "--key is required") {
+		t.Fatalf("without --key: exit %d, %q", code, stderr)
+	}
+}
@@ -0,0 +1,341 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// jsonSchema is a compiled schema of the JSON Schema subset that message
+// policies support: type, enum, const, properties, required,
+// additionalProperties, items, minItems/maxItems, minLength/maxLength,
+// pattern and minimum/maximum. Annotations ($schema, $id, $comment, title,
+// description) are ignored; any other keyword is refused when the schema is
+// compiled, so that no constraint is silently skipped.
+type jsonSchema struct {
+	never      bool // the false schema
+	types      []string
+	enum       []string // canonical JSON of the allowed values
+	properties map[string]*jsonSchema
+	required   []string
+	additional *jsonSchema // nil: any additional property
+	items      *jsonSchema
+	minItems   int
+	maxItems   int // -1: no limit
+	minLength  int
+	maxLength  int // -1: no limit
+	pattern    *regexp.Regexp
+	minimum    *float64
+	maximum    *float64
+}
+
+var jsonSchemaTypes = []string{"object", "array", "string", "number", "integer", "boolean", "null"}
+
+var jsonSchemaAnnotations = []string{"$schema", "$id", "$comment", "title", "description"}
+
+// compileJSONSchema parses a schema document.
+func compileJSONSchema(doc []byte) (*jsonSchema, error) {
+	v, err := decodeJSONValue(doc)
+	if err != nil {
+		return nil, err
+	}
+	return compileSchemaValue(v, "#")
+}
+
+// decodeJSONValue decodes exactly one JSON value, keeping numbers exact.
+func decodeJSONValue(b []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if dec.More() {
+		return nil, errors.New("invalid JSON: trailing data")
+	}
+	return v, nil
+}
+
+func compileSchemaValue(v any, at string) (*jsonSchema, error) {
+	s := &jsonSchema{maxItems: -1, maxLength: -1}
+	switch v := v.(type) {
+	case bool:
+		s.never = !v
+		return s, nil
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := s.compileKeyword(k, v[k], at+"/"+k); err != nil {
+				return nil, err
+			}
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("%s: a schema must be an object or a boolean", at)
+	}
+}
+
+func (s *jsonSchema) compileKeyword(k string, v any, at string) error {
+	var err error
+	switch k {
+	case "type":
+		switch t := v.(type) {
+		case string:
+			s.types = []string{t}
+		case []any:
+			for _, e := range t {
+				name, ok := e.(string)
+				if !ok {
+					return fmt.Errorf("%s: types must be strings", at)
+				}
+				s.types = append(s.types, name)
+			}
+		default:
+			return fmt.Errorf("%s: must be a string or an array of strings", at)
+		}
+		for _, t := range s.types {
+			if !slices.Contains(jsonSchemaTypes, t) {
+				return fmt.Errorf("%s: unknown type %q", at, t)
+			}
+		}
+	case "enum", "const":
+		values := []any{v}
+		if k == "enum" {
+			var ok bool
+			if values, ok = v.([]any); !ok {
+				return fmt.Errorf("%s: must be an array", at)
+			}
+		}
+		if s.enum != nil {
+			return fmt.Errorf("%s: cannot combine enum and const", at)
+		}
+		s.enum = []string{}
+		for _, e := range values {
+			c, err := canonicalJSONValue(e)
+			if err != nil {
+				return fmt.Errorf("%s: %w", at, err)
+			}
+			s.enum = append(s.enum, c)
+		}
+	case "properties":
+		props, ok := v.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: must be an object", at)
+		}
+		s.properties = make(map[string]*jsonSchema, len(props))
+		for name, p := range props {
+			if s.properties[name], err = compileSchemaValue(p, at+"/"+name); err != nil {
+				return err
+			}
+		}
+	case "required":
+		names, ok := v.([]any)
+		if !ok {
+			return fmt.Errorf("%s: must be an array of strings", at)
+		}
+		for _, e := range names {
+			name, ok := e.(string)
+			if !ok {
+				return fmt.Errorf("%s: must be an array of strings", at)
+			}
+			s.required = append(s.required, name)
+		}
+	case "additionalProperties":
+		s.additional, err = compileSchemaValue(v, at)
+	case "items":
+		s.items, err = compileSchemaValue(v, at)
+	case "minItems":
+		s.minItems, err = schemaCount(v, at)
+	case "maxItems":
+		s.maxItems, err = schemaCount(v, at)
+	case "minLength":
+		s.minLength, err = schemaCount(v, at)
+	case "maxLength":
+		s.maxLength, err = schemaCount(v, at)
+	case "pattern":
+		p, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%s: must be a string", at)
+		}
+		if s.pattern, err = regexp.Compile(p); err != nil {
+			return fmt.Errorf("%s: %w", at, err)
+		}
+	case "minimum", "maximum":
+		n, ok := v.(json.Number)
+		if !ok {
+			return fmt.Errorf("%s: must be a number", at)
+		}
+		f, err := n.Float64()
+		if err != nil {
+			return fmt.Errorf("%s: %w", at, err)
+		}
+		if k == "minimum" {
+			s.minimum = &f
+		} else {
+			s.maximum = &f
+		}
+	default:
+		if !slices.Contains(jsonSchemaAnnotations, k) {
+			return fmt.Errorf("%s: unsupported keyword %q", at, k)
+		}
+	}
+	return err
+}
+
+func schemaCount(v any, at string) (int, error) {
+	n, ok := v.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("%s: must be a non-negative integer", at)
+	}
+	i, err := strconv.Atoi(n.String())
+	if err != nil || i < 0 {
+		return 0, fmt.Errorf("%s: must be a non-negative integer", at)
+	}
+	return i, nil
+}
+
+// canonicalJSONValue returns the RFC 8785 encoding of a decoded value, so
+// that enum and const compare values rather than their spelling.
+func canonicalJSONValue(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	c, err := falcongo.CanonicalizeJSON(b)
+	if err != nil {
+		return "", err
+	}
+	return string(c), nil
+}
+
+// validate checks a JSON document against s.
+func (s *jsonSchema) validate(doc []byte) error {
+	v, err := decodeJSONValue(doc)
+	if err != nil {
+		return err
+	}
+	return s.check(v, "$")
+}
+
+func (s *jsonSchema) check(v any, at string) error {
+	if s.never {
+		return fmt.Errorf("%s: not allowed", at)
+	}
+	if len(s.types) > 0 && !slices.ContainsFunc(s.types, func(t string) bool { return jsonHasType(v, t) }) {
+		return fmt.Errorf("%s: %s is not of type %v", at, jsonTypeName(v), s.types)
+	}
+	if s.enum != nil {
+		c, err := canonicalJSONValue(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", at, err)
+		}
+		if !slices.Contains(s.enum, c) {
+			return fmt.Errorf("%s: value not allowed", at)
+		}
+	}
+	switch v := v.(type) {
+	case map[string]any:
+		for _, name := range s.required {
+			if _, ok := v[name]; !ok {
+				return fmt.Errorf("%s: missing property %q", at, name)
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			p := s.properties[name]
+			if p == nil {
+				p = s.additional
+			}
+			if p == nil {
+				continue
+			}
+			if err := p.check(v[name], at+"."+name); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if len(v) < s.minItems || (s.maxItems >= 0 && len(v) > s.maxItems) {
+			return fmt.Errorf("%s: %d items, outside the allowed range", at, len(v))
+		}
+		if s.items != nil {
+			for i, e := range v {
+				if err := s.items.check(e, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case string:
+		n := utf8.RuneCountInString(v)
+		if n < s.minLength || (s.maxLength >= 0 && n > s.maxLength) {
+			return fmt.Errorf("%s: %d characters, outside the allowed range", at, n)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(v) {
+			return fmt.Errorf("%s: does not match pattern %q", at, s.pattern)
+		}
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return fmt.Errorf("%s: %w", at, err)
+		}
+		if (s.minimum != nil && f < *s.minimum) || (s.maximum != nil && f > *s.maximum) {
+			return fmt.Errorf("%s: %s is outside the allowed range", at, v)
+		}
+	}
+	return nil
+}
+
+// jsonHasType reports whether a decoded value is of a JSON Schema type;
+// integers are numbers without a fractional part.
+func jsonHasType(v any, t string) bool {
+	switch v := v.(type) {
+	case map[string]any:
+		return t == "object"
+	case []any:
+		return t == "array"
+	case string:
+		return t == "string"
+	case bool:
+		return t == "boolean"
+	case nil:
+		return t == "null"
+	case json.Number:
+		if t == "number" {
+			return true
+		}
+		if t != "integer" {
+			return false
+		}
+		if _, err := v.Int64(); err == nil {
+			return true
+		}
+		f, err := v.Float64()
+		return err == nil && f == math.Trunc(f) && !math.IsInf(f, 0)
+	}
+	return false
+}
+
+func jsonTypeName(v any) string {
+	for _, t := range jsonSchemaTypes {
+		if t != "integer" && jsonHasType(v, t) {
+			return t
+		}
+	}
+	return "value"
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJSONSchema(t *testing.T) {
+	for _, tc := range []struct {
+		schema string
+		doc    string
+		err    string // "" if doc is valid
+	}{
+		{`true`, `[1, "a"]`, ""},
+		{`false`, `null`, "$: not allowed"},
+		{`{"type": "integer"}`, `12`, ""},
+		{`{"type": "integer"}`, `12.0`, ""},
+		{`{"type": "integer"}`, `12.5`, "is not of type [integer]"},
+		{`{"type": ["string", "null"]}`, `null`, ""},
+		{`{"type": "string"}`, `{}`, "$: object is not of type [string]"},
+		{`{"enum": [1, "a", {"b": [true]}]}`, `1.0`, ""},
+		{`{"enum": [1, "a", {"b": [true]}]}`, `{ "b" : [ true ] }`, ""},
+		{`{"enum": [1, "a"]}`, `"b"`, "$: value not allowed"},
+		{`{"const": "v1"}`, `"v1"`, ""},
+		{`{"type": "string", "minLength": 2, "maxLength": 3}`, `"éé"`, ""},
+		{`{"type": "string", "maxLength": 3}`, `"abcd"`, "4 characters"},
+		{`{"minimum": 1, "maximum": 10}`, `0`, "0 is outside"},
+		{`{"items": {"type": "number"}, "minItems": 1, "maxItems": 2}`, `[1, 2]`, ""},
+		{`{"items": {"type": "number"}}`, `[1, "2"]`, "$[1]: string is not of type [number]"},
+		{`{"maxItems": 2}`, `[1, 2, 3]`, "3 items"},
+		{`{"properties": {"a": {"properties": {"b": {"const": 1}}}}}`, `{"a": {"b": 2}}`, "$.a.b: value not allowed"},
+		{`{"additionalProperties": {"type": "boolean"}, "properties": {"n": {"type": "number"}}}`, `{"n": 1, "f": true}`, ""},
+		{`{"type": "object"}`, `{} {}`, "trailing data"},
+	} {
+		s, err := compileJSONSchema([]byte(tc.schema))
+		if err != nil {
+			t.Fatalf("compile %s: %v", tc.schema, err)
+		}
+		err = s.validate([]byte(tc.doc))
+		if tc.err == "" && err != nil {
+			t.Errorf("%s against %s: %v", tc.doc, tc.schema, err)
+		}
+		if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+			t.Errorf("%s against %s: got %v, want %q", tc.doc, tc.schema, err, tc.err)
+		}
+	}
+}
+
+func TestJSONSchema_Compile(t *testing.T) {
+	for schema, want := range map[string]string{
+		`[]`:                                   "a schema must be an object or a boolean",
+		`{"$ref": "#/defs/a"}`:                 `unsupported keyword "$ref"`,
+		`{"properties": {"a": {"anyOf": []}}}`: `#/properties/a/anyOf: unsupported keyword "anyOf"`,
+		`{"minLength": -1}`:                    "non-negative integer",
+		`{"pattern": "("}`:                     "#/pattern",
+		`{"enum": 1}`:                          "must be an array",
+	} {
+		if _, err := compileJSONSchema([]byte(schema)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("compile %s: got %v, want %q", schema, err, want)
+		}
+	}
+	if _, err := compileJSONSchema([]byte(`{"$schema": "https://json-schema.org/draft/2020-12/schema", "title": "t", "type": "object"}`)); err != nil {
+		t.Errorf("annotations: %v", err)
+	}
+}
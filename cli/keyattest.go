@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// keyAttestationJSON is the key_attestation of a key file: a signature by the
+// key of its own public key and creation time, written by create. Re-checking
+// it detects corruption of either half of the key file, which would otherwise
+// surface only when a later signature does not verify: the public key must
+// verify it, and, signatures being deterministic, the private key must sign
+// it again byte for byte.
+type keyAttestationJSON struct {
+	CreatedAt string `json:"created_at"` // RFC 3339, UTC
+	Signature string `json:"signature"`  // compressed, hex
+}
+
+// keyAttestationContext starts the message of a key attestation, which is
+// signed with SignBytes so it can pass for nothing else.
+const keyAttestationContext = "falcon key attestation v1\n"
+
+// Errors of checkKeyAttestation.
+var (
+	errKeyAttestationPublic  = errors.New("key_attestation does not verify with public_key: the public key or the attestation is corrupted")
+	errKeyAttestationPrivate = errors.New("private_key does not reproduce key_attestation: the private key is corrupted")
+)
+
+// keyAttestationMessage returns the message a key attestation signs.
+func keyAttestationMessage(pub []byte, createdAt string) []byte {
+	msg := append([]byte(keyAttestationContext), pub...)
+	return append(msg, createdAt...)
+}
+
+// newKeyAttestation signs the attestation of kp, created at now.
+func newKeyAttestation(kp falcongo.KeyPair, now time.Time) (*keyAttestationJSON, error) {
+	createdAt := now.UTC().Format(time.RFC3339)
+	sig, err := kp.SignBytes(keyAttestationMessage(kp.PublicKey[:], createdAt))
+	if err != nil {
+		return nil, err
+	}
+	return &keyAttestationJSON{CreatedAt: createdAt, Signature: hex.EncodeToString(sig)}, nil
+}
+
+// attestKeyFile adds the attestation of kp to obj, created now. Should
+// signing fail (e.g. in read-only mode), it warns and leaves obj without one:
+// the attestation is a check, not part of the key.
+func attestKeyFile(obj *keyPairJSON, kp falcongo.KeyPair) {
+	att, err := newKeyAttestation(kp, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: writing the key file without key_attestation: %v\n", err)
+		return
+	}
+	obj.KeyAttestation = att
+}
+
+// checkKeyAttestation checks att against pub and, unless nil, priv; see
+// keyAttestationJSON. It returns errKeyAttestationPublic or
+// errKeyAttestationPrivate for a corrupted key file.
+func checkKeyAttestation(att *keyAttestationJSON, pub, priv []byte) error {
+	if _, err := time.Parse(time.RFC3339, att.CreatedAt); err != nil {
+		return fmt.Errorf("invalid key_attestation created_at: %w", err)
+	}
+	sig, err := parseHex(strings.TrimSpace(att.Signature))
+	if err != nil {
+		return fmt.Errorf("invalid key_attestation signature hex: %w", err)
+	}
+	var pk falcongo.PublicKey
+	if len(pub) != len(pk) {
+		return errKeyAttestationPublic
+	}
+	copy(pk[:], pub)
+	msg := keyAttestationMessage(pub, att.CreatedAt)
+	if falcongo.VerifyBytes(msg, sig, pk) != nil {
+		return errKeyAttestationPublic
+	}
+	if priv == nil {
+		return nil
+	}
+	if c := falcongo.Capabilities(); !c.Signing || c.SigningDisabled {
+		// Without signing (purego build or read-only mode), the public half
+		// is all that can be checked.
+		return nil
+	}
+	kp := falcongo.KeyPair{PublicKey: pk}
+	if len(priv) != len(kp.PrivateKey) {
+		return errKeyAttestationPrivate
+	}
+	copy(kp.PrivateKey[:], priv)
+	again, err := kp.SignBytes(msg)
+	if err != nil || !bytes.Equal(again, sig) {
+		return errKeyAttestationPrivate
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// createAttestedKey runs create into a file of dir and returns its path and
+// its contents.
+func createAttestedKey(t *testing.T, dir string) (string, keyPairJSON) {
+	t.Helper()
+	path := filepath.Join(dir, "key.json")
+	if code := runCreate([]string{"--seed", "key attestation test seed", "--out", path}); code != 0 {
+		t.Fatalf("create failed with %d", code)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var k keyPairJSON
+	if err := json.Unmarshal(b, &k); err != nil {
+		t.Fatal(err)
+	}
+	if k.KeyAttestation == nil || k.KeyAttestation.CreatedAt == "" || k.KeyAttestation.Signature == "" {
+		t.Fatalf("create wrote no key_attestation: %s", b)
+	}
+	return path, k
+}
+
+// writeKeyJSON writes k to a file of dir.
+func writeKeyJSON(t *testing.T, dir, name string, k keyPairJSON) string {
+	t.Helper()
+	b, err := json.Marshal(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// flipHexByte returns h with the byte at i (negative: from the end) flipped.
+func flipHexByte(t *testing.T, h string, i int) string {
+	t.Helper()
+	b, err := hex.DecodeString(h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i < 0 {
+		i += len(b)
+	}
+	b[i] ^= 0x01
+	return hex.EncodeToString(b)
+}
+
+func TestKeyAttestation(t *testing.T) {
+	dir := t.TempDir()
+	path, k := createAttestedKey(t, dir)
+
+	var code int
+	out, stderr := captureStdoutStderr(t, func() { code = runInfo([]string{"--key", path}) })
+	if code != 0 || !strings.Contains(out, "key_attestation: valid (created "+k.KeyAttestation.CreatedAt+")") {
+		t.Fatalf("info of a fresh key: exit %d\n%s%s", code, out, stderr)
+	}
+	out, _ = captureStdoutStderr(t, func() { code = runKeysCheck([]string{"--key", path}) })
+	if code != 0 || !strings.Contains(out, "key_attestation: valid") {
+		t.Fatalf("keys check of a fresh key: exit %d\n%s", code, out)
+	}
+
+	// The last bytes of the private key encode F, which the public key does
+	// not depend on: only the attestation catches their corruption.
+	badPriv := k
+	badPriv.PrivateKey = flipHexByte(t, k.PrivateKey, -1)
+	badPrivPath := writeKeyJSON(t, dir, "bad-priv.json", badPriv)
+	out, stderr = captureStdoutStderr(t, func() { code = runInfo([]string{"--key", badPrivPath}) })
+	if code != 1 || !strings.Contains(out, "key_attestation: invalid") ||
+		!strings.Contains(stderr, "private key is corrupted") {
+		t.Fatalf("info of a corrupted private key: exit %d\n%s%s", code, out, stderr)
+	}
+	out, _ = captureStdoutStderr(t, func() { code = runKeysCheck([]string{"--key", badPrivPath}) })
+	if code != 1 || !strings.Contains(out, "private key is corrupted") {
+		t.Fatalf("keys check of a corrupted private key: exit %d\n%s", code, out)
+	}
+
+	// A public-only file keeps the check of its public key.
+	badPub := keyPairJSON{PublicKey: flipHexByte(t, k.PublicKey, 100), KeyAttestation: k.KeyAttestation}
+	badPubPath := writeKeyJSON(t, dir, "bad-pub.json", badPub)
+	_, stderr = captureStdoutStderr(t, func() {
+		code = runVerify([]string{"--key", badPubPath, "--msg", "hello", "--signature", "00"})
+	})
+	if code != 2 || !strings.Contains(stderr, "public key or the attestation is corrupted") {
+		t.Fatalf("verify with a corrupted public key: exit %d\n%s", code, stderr)
+	}
+	goodPub := writeKeyJSON(t, dir, "pub.json", keyPairJSON{PublicKey: k.PublicKey, KeyAttestation: k.KeyAttestation})
+	out, stderr = captureStdoutStderr(t, func() { code = runInfo([]string{"--key", goodPub}) })
+	if code != 0 || !strings.Contains(out, "key_attestation: valid") {
+		t.Fatalf("info of a public-only file: exit %d\n%s%s", code, out, stderr)
+	}
+
+	// A key file without attestation, as written before, is unaffected.
+	legacy := writeKeyJSON(t, dir, "legacy.json", keyPairJSON{PublicKey: k.PublicKey, PrivateKey: k.PrivateKey})
+	out, _ = captureStdoutStderr(t, func() { code = runInfo([]string{"--key", legacy}) })
+	if code != 0 || strings.Contains(out, "key_attestation") {
+		t.Fatalf("info of a key file without attestation: exit %d\n%s", code, out)
+	}
+}
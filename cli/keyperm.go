@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync/atomic"
+)
+
+// Like OpenSSH with private keys, falcon refuses to read a key file holding a
+// private key or mnemonic that other users can read: the key is as good as
+// leaked. --insecure-permissions, accepted anywhere on the command line,
+// reads such files anyway. Files holding only a public key, and key sources
+// (env:, fd:), are not checked.
+const insecurePermissionsFlag = "--insecure-permissions"
+
+// insecurePermissions is set by --insecure-permissions.
+var insecurePermissions atomic.Bool
+
+// readKeyFile reads the key file at path, checking its permissions with
+// checkKeyFilePermissions.
+func readKeyFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkKeyFilePermissions(f, b); err != nil {
+		wipeBytes(b)
+		return nil, err
+	}
+	return b, nil
+}
+
+// checkKeyFilePermissions refuses the open key file f, whose contents are
+// data, if it holds secrets that other users can read.
+func checkKeyFilePermissions(f *os.File, data []byte) error {
+	if insecurePermissions.Load() {
+		return nil
+	}
+	var secrets struct {
+		PrivateKey string `json:"private_key"`
+		Mnemonic   string `json:"mnemonic"`
+	}
+	if json.Unmarshal(data, &secrets) != nil || (secrets.PrivateKey == "" && secrets.Mnemonic == "") {
+		return nil
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+	return checkKeyFileAccess(f, info)
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestKeyFilePermissions refuses a private key file other users can read,
+// unless --insecure-permissions is given.
+func TestKeyFilePermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not permissions on Windows")
+	}
+	t.Cleanup(func() { insecurePermissions.Store(false) })
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("key permissions test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	dir := t.TempDir()
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+	pubPath := writeKeypairJSON(t, dir, "pub.json", kp, false)
+	for _, path := range []string{keyPath, pubPath} {
+		if err := os.Chmod(path, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var code int
+	stderr := captureStderr(t, func() { code = runSign([]string{"--key", keyPath, "--msg", "hi"}) })
+	if code != 2 || !strings.Contains(stderr, "accessible by other users (mode 0644)") ||
+		!strings.Contains(stderr, "chmod 600 "+keyPath) || !strings.Contains(stderr, insecurePermissionsFlag) {
+		t.Fatalf("sign with a 0644 key: exit %d, %q", code, stderr)
+	}
+	// A public key file may be readable by anyone.
+	if _, _, _, err := loadKeypairFile(pubPath, nil); err != nil {
+		t.Fatalf("public key file refused: %v", err)
+	}
+
+	out := captureStdout(t, func() {
+		code = run([]string{"sign", "--key", keyPath, "--msg", "hi", "--insecure-permissions"})
+	})
+	if code != 0 || out == "" {
+		t.Fatalf("sign --insecure-permissions: exit %d, %q", code, out)
+	}
+
+	insecurePermissions.Store(false)
+	if err := os.Chmod(keyPath, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := loadKeypairFile(keyPath, nil); err != nil {
+		t.Fatalf("0600 key file refused: %v", err)
+	}
+}
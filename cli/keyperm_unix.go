@@ -0,0 +1,17 @@
+//go:build !windows
+
+package cli
+
+import (
+	"fmt"
+	"os"
+)
+
+// checkKeyFileAccess refuses a key file that group or others may access.
+func checkKeyFileAccess(f *os.File, info os.FileInfo) error {
+	if mode := info.Mode().Perm(); mode&0o077 != 0 {
+		return fmt.Errorf("%s holds a private key but is accessible by other users (mode %04o); "+
+			"run chmod 600 %s, or pass %s to use it anyway", f.Name(), mode, f.Name(), insecurePermissionsFlag)
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+//go:build windows
+
+package cli
+
+import (
+	"fmt"
+	"os"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// broadSIDs are the groups a key file must not grant read access to: any
+// member of them is another user.
+var broadSIDs = []windows.WELL_KNOWN_SID_TYPE{
+	windows.WinWorldSid,             // Everyone
+	windows.WinAuthenticatedUserSid, // Authenticated Users
+	windows.WinBuiltinUsersSid,      // Users
+	windows.WinAnonymousSid,
+	windows.WinBuiltinGuestsSid,
+}
+
+// keyFileReadAccess are the rights that let a trustee read a file.
+const keyFileReadAccess = windows.FILE_READ_DATA | windows.GENERIC_READ | windows.GENERIC_ALL
+
+// checkKeyFileAccess refuses a key file whose DACL lets broad groups, such as
+// Everyone or Users, read it. A file without a DACL grants everyone access.
+// Grants to other named users are not detected.
+func checkKeyFileAccess(f *os.File, info os.FileInfo) error {
+	sd, err := windows.GetSecurityInfo(windows.Handle(f.Fd()), windows.SE_FILE_OBJECT,
+		windows.DACL_SECURITY_INFORMATION)
+	if err != nil {
+		return fmt.Errorf("%s: cannot read its access control list: %w", f.Name(), err)
+	}
+	dacl, _, err := sd.DACL()
+	if err != nil || dacl == nil {
+		return insecureKeyFileError(f.Name(), "Everyone (no access control list)")
+	}
+	for i := range uint32(dacl.AceCount) {
+		var ace *windows.ACCESS_ALLOWED_ACE
+		if err := windows.GetAce(dacl, i, &ace); err != nil {
+			return fmt.Errorf("%s: cannot read its access control list: %w", f.Name(), err)
+		}
+		if ace.Header.AceType != windows.ACCESS_ALLOWED_ACE_TYPE || ace.Mask&keyFileReadAccess == 0 {
+			continue
+		}
+		sid := (*windows.SID)(unsafe.Pointer(&ace.SidStart))
+		for _, broad := range broadSIDs {
+			if sid.IsWellKnown(broad) {
+				account, domain, _, err := sid.LookupAccount("")
+				name := domain + `\` + account
+				if err != nil {
+					name = sid.String()
+				}
+				return insecureKeyFileError(f.Name(), name)
+			}
+		}
+	}
+	return nil
+}
+
+func insecureKeyFileError(path, trustee string) error {
+	return fmt.Errorf("%s holds a private key but %s can read it; "+
+		"run icacls \"%s\" /inheritance:r /grant:r \"%%USERNAME%%:F\", or pass %s to use it anyway",
+		path, trustee, path, insecurePermissionsFlag)
+}
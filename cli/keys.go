@@ -0,0 +1,573 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/mnemonic"
+)
+
+// canonicalKeyJSON lists the key file fields in sorted order so that
+// encoding/json emits them sorted.
+type canonicalKeyJSON struct {
+	CreatedBy          string                  `json:"created_by,omitempty"`
+	KDF                *kdfParamsJSON          `json:"kdf,omitempty"`
+	KeyAttestation     *keyAttestationJSON     `json:"key_attestation,omitempty"`
+	MessagePolicy      *messagePolicyJSON      `json:"message_policy,omitempty"`
+	MinReaderVersion   int                     `json:"min_reader_version,omitempty"`
+	Mnemonic           string                  `json:"mnemonic,omitempty"`
+	MnemonicPassphrase string                  `json:"mnemonic_passphrase,omitempty"`
+	PassphraseAccounts []passphraseAccountJSON `json:"passphrase_accounts,omitempty"`
+	PrivateKey         string                  `json:"private_key,omitempty"`
+	PublicKey          string                  `json:"public_key,omitempty"`
+}
+
+// ---- keys dispatcher ----
+func runKeys(args []string) int {
+	return runSubcommand("keys", args)
+}
+
+// readKeyFileStrict decodes a key file, rejecting fields this tool does not
+// know so they are never silently dropped.
+func readKeyFileStrict(path string) (keyPairJSON, error) {
+	b, err := readKeySource(path)
+	if err != nil {
+		return keyPairJSON{}, err
+	}
+	// Check the format version first: a newer file is expected to carry
+	// fields this build does not know.
+	if err := checkKeyFileVersion(b); err != nil {
+		return keyPairJSON{}, err
+	}
+	var k keyPairJSON
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&k); err != nil {
+		return keyPairJSON{}, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if dec.More() {
+		return keyPairJSON{}, fmt.Errorf("invalid JSON: trailing data")
+	}
+	return k, nil
+}
+
+// canonicalKey normalizes a key file: hex fields are lowercase without 0x
+// prefix or odd nibble, and mnemonic words are lowercase and single-spaced.
+func canonicalKey(k keyPairJSON) (canonicalKeyJSON, error) {
+	c := canonicalKeyJSON{
+		Mnemonic:           strings.ToLower(strings.Join(strings.Fields(k.Mnemonic), " ")),
+		MnemonicPassphrase: k.MnemonicPassphrase,
+		KDF:                k.KDF,
+		CreatedBy:          k.CreatedBy,
+		MinReaderVersion:   k.MinReaderVersion,
+		PassphraseAccounts: k.PassphraseAccounts,
+	}
+	if p := k.MessagePolicy; p != nil {
+		c.MessagePolicy = &messagePolicyJSON{Pattern: p.Pattern, MaxSize: p.MaxSize}
+		if len(p.Schema) > 0 {
+			schema, err := falcongo.CanonicalizeJSON(p.Schema)
+			if err != nil {
+				return canonicalKeyJSON{}, fmt.Errorf("invalid message_policy schema: %w", err)
+			}
+			c.MessagePolicy.Schema = schema
+		}
+	}
+	if a := k.KeyAttestation; a != nil {
+		sig, err := parseHex(strings.TrimSpace(a.Signature))
+		if err != nil {
+			return canonicalKeyJSON{}, fmt.Errorf("invalid key_attestation signature hex: %w", err)
+		}
+		c.KeyAttestation = &keyAttestationJSON{CreatedAt: a.CreatedAt, Signature: hex.EncodeToString(sig)}
+	}
+	if k.PublicKey != "" {
+		b, err := parseHex(k.PublicKey)
+		if err != nil {
+			return canonicalKeyJSON{}, fmt.Errorf("invalid public_key hex: %w", err)
+		}
+		c.PublicKey = hex.EncodeToString(b)
+	}
+	if k.PrivateKey != "" {
+		b, err := parseHex(k.PrivateKey)
+		if err != nil {
+			return canonicalKeyJSON{}, fmt.Errorf("invalid private_key hex: %w", err)
+		}
+		c.PrivateKey = hex.EncodeToString(b)
+	}
+	return c, nil
+}
+
+// encodeCanonicalKey returns the byte-stable encoding of c: compact JSON with
+// sorted keys, no HTML escaping, and a trailing newline.
+func encodeCanonicalKey(c canonicalKeyJSON) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(c); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ---- keys canonicalize ----
+func runKeysCanonicalize(args []string) int {
+	fs := flag.NewFlagSet("keys canonicalize", flag.ExitOnError)
+	inFile := fs.String("in", "", "key JSON file to canonicalize")
+	out := fs.String("out", "", "write canonical JSON to file (stdout if empty)")
+	check := fs.Bool("check", false, "exit 1 if --in is not already canonical instead of writing")
+	parseFlags(fs, args)
+
+	if *inFile == "" {
+		fmt.Fprintf(os.Stderr, "--in is required\n")
+		return 2
+	}
+	if *check && *out != "" {
+		fmt.Fprintf(os.Stderr, "cannot combine --check with --out\n")
+		return 2
+	}
+	k, err := readKeyFileStrict(*inFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --in: %v\n", err)
+		return 2
+	}
+	c, err := canonicalKey(k)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --in: %v\n", err)
+		return 2
+	}
+	data, err := encodeCanonicalKey(c)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode key JSON: %v\n", err)
+		return 2
+	}
+
+	if *check {
+		orig, err := os.ReadFile(*inFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read --in: %v\n", err)
+			return 2
+		}
+		if !bytes.Equal(orig, data) {
+			fmt.Fprintf(os.Stdout, "%s: not canonical\n", *inFile)
+			return 1
+		}
+		return 0
+	}
+	if *out == "" {
+		if _, err := os.Stdout.Write(data); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write key JSON: %v\n", err)
+			return 2
+		}
+		return 0
+	}
+	mode := os.FileMode(0o644)
+	if c.PrivateKey != "" || c.Mnemonic != "" {
+		mode = 0o600
+	}
+	if err := writeFileAtomic(*out, data, mode); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *out, err)
+		return 2
+	}
+	return 0
+}
+
+// ---- keys diff ----
+func runKeysDiff(args []string) int {
+	fs := flag.NewFlagSet("keys diff", flag.ExitOnError)
+	parseFlags(fs, args)
+	if fs.NArg() != 2 {
+		fmt.Fprintf(os.Stderr, "usage: falcon keys diff <a.json> <b.json>\n")
+		return 2
+	}
+	pathA, pathB := fs.Arg(0), fs.Arg(1)
+
+	var keys [2]canonicalKeyJSON
+	for i, path := range []string{pathA, pathB} {
+		k, err := readKeyFileStrict(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", path, err)
+			return 2
+		}
+		if keys[i], err = canonicalKey(k); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", path, err)
+			return 2
+		}
+	}
+	a, b := keys[0], keys[1]
+
+	var diffs []string
+	// The created_by and min_reader_version stamps, the key_attestation and
+	// the passphrase_accounts are not key material and are not compared; the
+	// message_policy is, as it changes what the key signs.
+	// Public keys are shown by fingerprint; secret fields are never printed.
+	if d := diffKeyField("public_key", pathA, pathB, a.PublicKey, b.PublicKey, false); d != "" {
+		if a.PublicKey != "" && b.PublicKey != "" {
+			d += fmt.Sprintf(" (%s vs %s)", publicKeyFingerprint(a.PublicKey),
+				publicKeyFingerprint(b.PublicKey))
+		}
+		diffs = append(diffs, d)
+	}
+	if d := diffKeyField("private_key", pathA, pathB, a.PrivateKey, b.PrivateKey, true); d != "" {
+		diffs = append(diffs, d)
+	}
+	if d := diffKeyField("mnemonic", pathA, pathB, a.Mnemonic, b.Mnemonic, true); d != "" {
+		diffs = append(diffs, d)
+	}
+	if d := diffKeyField("mnemonic_passphrase", pathA, pathB,
+		a.MnemonicPassphrase, b.MnemonicPassphrase, true); d != "" {
+		diffs = append(diffs, d)
+	}
+	if d := diffKeyField("kdf", pathA, pathB, kdfString(a.KDF), kdfString(b.KDF), false); d != "" {
+		diffs = append(diffs, d)
+	}
+	if d := diffKeyField("message_policy", pathA, pathB, messagePolicyString(a.MessagePolicy),
+		messagePolicyString(b.MessagePolicy), false); d != "" {
+		diffs = append(diffs, d)
+	}
+
+	for _, d := range diffs {
+		fmt.Fprintln(os.Stdout, d)
+	}
+	if len(diffs) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// diffKeyField describes how field differs between two canonical key files,
+// or returns "" when the values are equal. Secret values are compared in
+// constant time.
+func diffKeyField(field, pathA, pathB, a, b string, secret bool) string {
+	switch {
+	case a == "" && b == "":
+		return ""
+	case b == "":
+		return fmt.Sprintf("%s: only in %s", field, pathA)
+	case a == "":
+		return fmt.Sprintf("%s: only in %s", field, pathB)
+	}
+	if secret {
+		if falcongo.SecretsEqual([]byte(a), []byte(b)) {
+			return ""
+		}
+	} else if a == b {
+		return ""
+	}
+	return fmt.Sprintf("%s: differs", field)
+}
+
+// kdfString returns the canonical encoding of KDF parameters, or "" if absent.
+func kdfString(p *kdfParamsJSON) string {
+	if p == nil {
+		return ""
+	}
+	b, _ := json.Marshal(p)
+	return string(b)
+}
+
+// messagePolicyString returns the canonical encoding of a message policy, or
+// "" if absent.
+func messagePolicyString(p *messagePolicyJSON) string {
+	if p == nil {
+		return ""
+	}
+	b, _ := json.Marshal(p)
+	return string(b)
+}
+
+// publicKeyFingerprint returns the hex fingerprint of a canonical public key,
+// or "invalid" when it is not a FALCON public key.
+func publicKeyFingerprint(pubHex string) string {
+	fp, err := falcongo.FingerprintFromHex(pubHex)
+	if err != nil {
+		return "invalid"
+	}
+	return hex.EncodeToString(fp[:])
+}
+
+// fingerprintPetname returns the petname (see mnemonic.Petname) of a hex
+// fingerprint, or "invalid" when it is not one.
+func fingerprintPetname(fingerprint string) string {
+	b, err := hex.DecodeString(fingerprint)
+	if err != nil || len(b) != 32 {
+		return "invalid"
+	}
+	return mnemonic.Petname([32]byte(b))
+}
+
+// ---- keys check ----
+func runKeysCheck(args []string) int {
+	fs := flag.NewFlagSet("keys check", flag.ExitOnError)
+	keyPath := fs.String("key", "", "key JSON file to check")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+	})
+
+	if *keyPath == "" {
+		fmt.Fprintf(os.Stderr, "--key is required\n")
+		return 2
+	}
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	// loadKeypairFile already rejects a mnemonic that does not match the keys.
+	pub, priv, meta, err := loadKeypairFile(*keyPath, override)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
+		return 2
+	}
+	if priv == nil {
+		fmt.Fprintf(os.Stderr, "private key not found in %s (required for check)\n", *keyPath)
+		return 2
+	}
+	derived, err := publicKeyFromPrivate(priv)
+	if err != nil {
+		fmt.Fprintf(os.Stdout, "private_key: %v\n", err)
+		return 1
+	}
+	fingerprint := publicKeyFingerprint(hex.EncodeToString(derived))
+	switch {
+	case pub == nil:
+		fmt.Fprintf(os.Stdout, "public_key: missing (derived fingerprint %s, petname %s)\n", fingerprint,
+			fingerprintPetname(fingerprint))
+	case !bytes.Equal(pub, derived):
+		fmt.Fprintf(os.Stdout, "public_key: does not match private_key (%s vs derived %s)\n",
+			publicKeyFingerprint(hex.EncodeToString(pub)), fingerprint)
+		return 1
+	default:
+		fmt.Fprintf(os.Stdout, "public_key: matches private_key (%s, petname %s)\n", fingerprint,
+			fingerprintPetname(fingerprint))
+	}
+	if meta.Mnemonic != "" {
+		fmt.Fprintln(os.Stdout, "mnemonic: matches keys")
+	}
+	if meta.KeyAttestation != nil {
+		if err := checkKeyAttestation(meta.KeyAttestation, derived, priv); err != nil {
+			fmt.Fprintf(os.Stdout, "key_attestation: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(os.Stdout, "key_attestation: valid (created %s)\n", meta.KeyAttestation.CreatedAt)
+	}
+	return 0
+}
+
+// ---- keys destroy ----
+func runKeysDestroy(args []string) int {
+	fs := flag.NewFlagSet("keys destroy", flag.ExitOnError)
+	keyPath := fs.String("key", "", "key JSON file to overwrite and delete")
+	confirm := fs.Bool("confirm", false, "require typing the key fingerprint before deleting")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+	})
+
+	if *keyPath == "" {
+		fmt.Fprintf(os.Stderr, "--key is required\n")
+		return 2
+	}
+	if _, _, ok := keySourceRef(*keyPath); ok {
+		fmt.Fprintf(os.Stderr, "--key %s is not a file; there is nothing to wipe\n", *keyPath)
+		return 2
+	}
+	// Refuse to wipe files that are not key files, e.g. a mistyped path.
+	var meta keyPairJSON
+	b, err := os.ReadFile(*keyPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
+		return 2
+	}
+	if err := json.Unmarshal(b, &meta); err != nil ||
+		(meta.PublicKey == "" && meta.PrivateKey == "" && meta.Mnemonic == "") {
+		fmt.Fprintf(os.Stderr, "%s is not a key JSON file\n", *keyPath)
+		return 2
+	}
+
+	if *confirm {
+		var override *string
+		if passphraseProvided {
+			override = mnemonicPassphrase
+		}
+		fp, ok, err := keyFileFingerprint(*keyPath, override)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
+			return 2
+		}
+		if !ok {
+			fmt.Fprintf(os.Stderr, "valid public key not found in %s (required for --confirm)\n", *keyPath)
+			return 2
+		}
+		fingerprint := hex.EncodeToString(fp[:])
+		fmt.Fprintf(os.Stderr, "key fingerprint: %s (petname %s)\n", fingerprint, fingerprintPetname(fingerprint))
+		fmt.Fprintf(os.Stderr, "type the fingerprint to destroy %s: ", *keyPath)
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Fprintf(os.Stderr, "\nfailed to read confirmation: %v\n", err)
+			return 2
+		}
+		if !strings.EqualFold(strings.TrimSpace(line), fingerprint) {
+			fmt.Fprintln(os.Stderr, "fingerprint does not match; key not destroyed")
+			return 1
+		}
+	}
+
+	if err := wipeFile(*keyPath); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to destroy %s: %v\n", *keyPath, err)
+		return 2
+	}
+	forgetKeyStats(meta.PublicKey)
+	fmt.Fprintf(os.Stdout, "destroyed %s\n", *keyPath)
+	return 0
+}
+
+const helpKeys = `# falcon keys
+
+Key file maintenance utilities.
+
+Usage:
+  falcon keys list [--stats] [--json]
+  falcon keys canonicalize --in <file> [--out <file> | --check]
+  falcon keys diff <a.json> <b.json>
+  falcon keys check --key <file> [--mnemonic-passphrase <string>]
+  falcon keys destroy --key <file> [--confirm] [--mnemonic-passphrase <string>]
+  falcon keys add-passphrase-account --key <file> --hint <text> (--mnemonic-passphrase <string> | --mnemonic-passphrase-prompt)
+  falcon keys set-policy --key <file> ([--pattern <regex>] [--schema <file>] [--max-size <n>] | --clear)
+  falcon keys totp-enroll --key <file> [--secret <base32>] [--code <digits>] [--issuer <name>] [--mnemonic-passphrase <string>]
+  falcon keys totp-remove --key <file> [--mnemonic-passphrase <string>]
+
+Subcommands:
+  list          List the keys used on this machine, with their usage statistics
+  canonicalize  Rewrite a key file in a byte-stable canonical JSON encoding
+  diff          Compare the material and metadata of two key files
+  check         Check that the public key (and mnemonic) of a key file match its private key
+  destroy       Overwrite a key file with zeros and delete it
+  add-passphrase-account
+                Record the account a passphrase opens from the mnemonic of a key file
+  set-policy    Restrict the messages falcon sign signs with a key (regex, JSON Schema, size)
+  totp-enroll   Require a TOTP code from an authenticator before a key signs on this machine
+  totp-remove   Remove the TOTP second factor of a key, given a current code
+
+Arguments (list):
+  --stats          also show age, last use, signature count, networks and revocation
+  --json           print every key and all its statistics as JSON
+
+create, sign, csr create, attest add, auth respond, vote sign and algorand
+send/claim record per key fingerprint: when the key was created and first and
+last used, the signatures made by each command, the networks used, and the key
+file. The statistics live in $FALCON_KEY_STATS (default: falcon/keystats.json
+in the user config dir); FALCON_KEY_STATS=off disables them. keys destroy drops
+the entry of the destroyed key; falcon revoke marks the key revoked. Keys are
+listed with their petname: three BIP-39 words derived from the fingerprint,
+the same on every machine, to recognize a key at a glance (not a substitute for
+comparing fingerprints).
+
+Arguments (canonicalize):
+  --in <file>      key JSON file (required)
+  --out <file>     write canonical JSON (stdout if omitted)
+  --check          do not write; exit 1 if --in is not already canonical
+
+The canonical form is compact JSON with keys sorted, lowercase hex without 0x
+prefix, lowercase single-spaced mnemonic words, and a trailing newline. Unknown
+fields are rejected.
+
+Arguments (check):
+  --key <file>     key JSON file with a private key (required)
+  --mnemonic-passphrase
+                   optional mnemonic passphrase when the key file omits it
+
+Check recomputes the public key from the private key and compares it with
+public_key; a file without public_key passes and the derived fingerprint is
+shown.
+
+Arguments (destroy):
+  --key <file>     key JSON file to destroy (required; symlinks are refused)
+  --confirm        show the key fingerprint and petname and require typing the
+                   fingerprint before deleting
+  --mnemonic-passphrase
+                   optional mnemonic passphrase when the key file omits it (with --confirm)
+
+Arguments (add-passphrase-account):
+  --key <file>     key JSON file with a mnemonic, updated in place (required)
+  --hint <text>    hint recorded for the account, e.g. decoy or real (required)
+  --mnemonic-passphrase <string> | --mnemonic-passphrase-prompt
+                   passphrase of the account (required; may be empty); it is never stored
+
+Like the hidden wallets of hardware wallets, each passphrase opens a separate
+account from the same mnemonic. add-passphrase-account records the address and
+fingerprint of one under a hint; falcon info --list-passphrase-accounts lists
+them. The hints are stored in clear text.
+
+Arguments (set-policy):
+  --key <file>     key JSON file, updated in place (required)
+  --pattern <regex>
+                   RE2 expression every message must match in full, as UTF-8
+                   text (a trailing newline is part of the message)
+  --schema <file>  JSON Schema every message must satisfy as a JSON document
+                   (type, enum, const, properties, required,
+                   additionalProperties, items, min/maxItems, min/maxLength,
+                   pattern, minimum/maximum; other keywords are refused)
+  --max-size <n>   largest message in bytes
+  --clear          remove the policy
+
+The policy is stored in the key file as message_policy and replaces any
+previous one; every constraint given applies. falcon sign refuses messages
+that break it (after --hex decoding and --json-canonicalize); the other signing
+commands refuse the key. Files with a policy need min_reader_version 2, so
+older falcon builds refuse them instead of ignoring it. Anyone who can write
+the key file can remove the policy.
+
+Arguments (totp-enroll):
+  --key <file>     key JSON file (required; public key sufficient)
+  --secret <base32>
+                   enroll this secret, e.g. that of a programmable hardware
+                   token (at least 16 bytes), instead of printing a new one
+  --code <digits>  current code, to confirm without a prompt
+  --issuer <name>  issuer shown by the authenticator app (default: falcon)
+  --mnemonic-passphrase
+                   optional mnemonic passphrase when the key file omits it
+
+Arguments (totp-remove):
+  --key <file>     key JSON file (required; public key sufficient)
+  --mnemonic-passphrase
+                   optional mnemonic passphrase when the key file omits it
+
+Once a key is enrolled, every command that signs with it on this machine asks
+for a code from the authenticator (or reads $FALCON_TOTP_CODE), and refuses
+to sign without a valid one; each code is accepted once. Removing the second
+factor takes a code too. The enrollments live, with the TOTP secrets, in
+falcon/second-factor.json in the default user config dir of the home directory
+the system records for the user (e.g. ~/.config on Linux), whatever $HOME or
+$XDG_CONFIG_HOME say; if it cannot be found, every key is refused. They guard
+the use of a key on this machine, not a copy of the key file taken elsewhere.
+
+Diff prints one line per differing field (public keys are shown by fingerprint,
+secret fields are never printed). Formatting differences are ignored.
+
+Exit codes: 0 canonical / identical / consistent / destroyed, 1 not canonical /
+different / inconsistent / confirmation mismatch, 2 usage or I/O errors.
+
+Examples:
+  falcon keys list --stats
+  falcon keys canonicalize --in pubkey.json --out pubkey.json
+  falcon keys canonicalize --in pubkey.json --check
+  falcon keys diff old.json new.json
+  falcon keys check --key mykeys.json
+  falcon keys destroy --key old.json --confirm
+  falcon keys set-policy --key ci.json --pattern 'sha256:[0-9a-f]{64}\n?'
+  falcon keys totp-enroll --key treasury.json
+`
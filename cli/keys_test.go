@@ -0,0 +1,215 @@
+package cli
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestRunKeysCanonicalize_Stable checks equivalent files canonicalize to the
+// same bytes and that the result passes --check.
+func TestRunKeysCanonicalize_Stable(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.json")
+	b := filepath.Join(dir, "b.json")
+	if err := os.WriteFile(a, []byte(`{"public_key": "0xABC", "mnemonic": " Legal  Winner "}`), 0o600); err != nil {
+		t.Fatalf("write a: %v", err)
+	}
+	if err := os.WriteFile(b, []byte("{\n  \"mnemonic\": \"legal winner\",\n  \"public_key\": \"0abc\"\n}\n"), 0o600); err != nil {
+		t.Fatalf("write b: %v", err)
+	}
+
+	var outs []string
+	for _, path := range []string{a, b} {
+		var code int
+		out := captureStdout(t, func() { code = runKeysCanonicalize([]string{"--in", path}) })
+		if code != 0 {
+			t.Fatalf("expected exit 0 for %s, got %d", path, code)
+		}
+		outs = append(outs, out)
+	}
+	want := `{"mnemonic":"legal winner","public_key":"0abc"}` + "\n"
+	if outs[0] != want || outs[1] != want {
+		t.Fatalf("expected %q for both files, got %q and %q", want, outs[0], outs[1])
+	}
+
+	var code int
+	captureStdout(t, func() { code = runKeysCanonicalize([]string{"--in", a, "--check"}) })
+	if code != 1 {
+		t.Fatalf("expected --check to fail on non-canonical file, got %d", code)
+	}
+	if code := runKeysCanonicalize([]string{"--in", a, "--out", a}); code != 0 {
+		t.Fatalf("expected in-place canonicalization to succeed, got %d", code)
+	}
+	if code := runKeysCanonicalize([]string{"--in", a, "--check"}); code != 0 {
+		t.Fatalf("expected --check to pass after canonicalization, got %d", code)
+	}
+}
+
+// TestRunKeysCanonicalize_UnknownField ensures unknown fields are rejected.
+func TestRunKeysCanonicalize_UnknownField(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "k.json")
+	if err := os.WriteFile(path, []byte(`{"public_key":"00","comment":"x"}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	var code int
+	_, stderr := captureStdoutStderr(t, func() { code = runKeysCanonicalize([]string{"--in", path}) })
+	if code != 2 || !strings.Contains(stderr, "comment") {
+		t.Fatalf("expected unknown field error, got %d %q", code, stderr)
+	}
+}
+
+// TestRunKeysDiff reports differing fields without printing secrets.
+func TestRunKeysDiff(t *testing.T) {
+	dir := t.TempDir()
+	kp1, err := falcongo.GenerateKeyPair(deriveSeed([]byte("diff 1")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	kp2, err := falcongo.GenerateKeyPair(deriveSeed([]byte("diff 2")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	full := writeKeypairJSON(t, dir, "full.json", kp1, true)
+	pubOnly := writeKeypairJSON(t, dir, "pub.json", kp1, false)
+	other := writeKeypairJSON(t, dir, "other.json", kp2, true)
+
+	var code int
+	out := captureStdout(t, func() { code = runKeysDiff([]string{full, full}) })
+	if code != 0 || out != "" {
+		t.Fatalf("expected identical files, got %d %q", code, out)
+	}
+
+	out = captureStdout(t, func() { code = runKeysDiff([]string{full, pubOnly}) })
+	if code != 1 || strings.Contains(out, "public_key") ||
+		!strings.Contains(out, "private_key: only in "+full) {
+		t.Fatalf("unexpected diff output: %d %q", code, out)
+	}
+
+	out = captureStdout(t, func() { code = runKeysDiff([]string{full, other}) })
+	fp1 := falcongo.Fingerprint(kp1.PublicKey)
+	fp2 := falcongo.Fingerprint(kp2.PublicKey)
+	wantPub := "public_key: differs (" + hex.EncodeToString(fp1[:]) + " vs " +
+		hex.EncodeToString(fp2[:]) + ")"
+	if code != 1 || !strings.Contains(out, wantPub) ||
+		!strings.Contains(out, "private_key: differs") {
+		t.Fatalf("unexpected diff output: %d %q", code, out)
+	}
+	if strings.Contains(out, hex.EncodeToString(kp1.PrivateKey[:8])) {
+		t.Fatalf("diff output must not contain private key material")
+	}
+}
+
+// TestRunKeysDestroy_Confirm checks the fingerprint confirmation and deletion.
+func TestRunKeysDestroy_Confirm(t *testing.T) {
+	dir := t.TempDir()
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("destroy")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+	fp := falcongo.Fingerprint(kp.PublicKey)
+
+	var code int
+	withStdin(t, "wrong\n", func() {
+		captureStdoutStderr(t, func() {
+			code = runKeysDestroy([]string{"--key", keyPath, "--confirm"})
+		})
+	})
+	if code != 1 {
+		t.Fatalf("expected exit 1 on mismatch, got %d", code)
+	}
+	if _, err := os.Stat(keyPath); err != nil {
+		t.Fatalf("key must survive a failed confirmation: %v", err)
+	}
+
+	withStdin(t, strings.ToUpper(hex.EncodeToString(fp[:]))+"\n", func() {
+		captureStdoutStderr(t, func() {
+			code = runKeysDestroy([]string{"--key", keyPath, "--confirm"})
+		})
+	})
+	if code != 0 {
+		t.Fatalf("expected exit 0, got %d", code)
+	}
+	if _, err := os.Stat(keyPath); !os.IsNotExist(err) {
+		t.Fatalf("expected key file to be deleted, stat err=%v", err)
+	}
+}
+
+// TestRunKeysDestroy_RefusesNonKeyFiles guards against wiping arbitrary files.
+func TestRunKeysDestroy_RefusesNonKeyFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("hello"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	var code int
+	captureStdoutStderr(t, func() { code = runKeysDestroy([]string{"--key", path}) })
+	if code != 2 {
+		t.Fatalf("expected exit 2, got %d", code)
+	}
+	if b, err := os.ReadFile(path); err != nil || string(b) != "hello" {
+		t.Fatalf("non-key file must be left untouched: %q %v", b, err)
+	}
+}
+
+// TestRunKeysCheck covers consistent, private-only and mismatched key files.
+func TestRunKeysCheck(t *testing.T) {
+	dir := t.TempDir()
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("keys check seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	other, err := falcongo.GenerateKeyPair(deriveSeed([]byte("keys check other")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	fp := falcongo.Fingerprint(kp.PublicKey)
+	fpHex := hex.EncodeToString(fp[:])
+
+	good := writeKeypairJSON(t, dir, "good.json", kp, true)
+	privOnly := filepath.Join(dir, "priv.json")
+	if err := os.WriteFile(privOnly, []byte(`{"private_key":"`+hex.EncodeToString(kp.PrivateKey[:])+`"}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	mismatched := filepath.Join(dir, "mismatched.json")
+	if err := os.WriteFile(mismatched, []byte(`{"public_key":"`+hex.EncodeToString(other.PublicKey[:])+
+		`","private_key":"`+hex.EncodeToString(kp.PrivateKey[:])+`"}`), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	for path, want := range map[string]struct {
+		code int
+		out  string
+	}{
+		good:       {0, "public_key: matches private_key (" + fpHex + ", petname " + fingerprintPetname(fpHex) + ")"},
+		privOnly:   {0, "public_key: missing (derived fingerprint " + fpHex + ", petname "},
+		mismatched: {1, "public_key: does not match private_key"},
+	} {
+		var code int
+		out := captureStdout(t, func() { code = runKeysCheck([]string{"--key", path}) })
+		if code != want.code || !strings.Contains(out, want.out) {
+			t.Fatalf("%s: expected %d %q, got %d %q", filepath.Base(path), want.code, want.out, code, out)
+		}
+	}
+
+	pubOnly := writeKeypairJSON(t, dir, "pub.json", kp, false)
+	var code int
+	stderr := captureStderr(t, func() { code = runKeysCheck([]string{"--key", pubOnly}) })
+	if code != 2 || !strings.Contains(stderr, "private key not found") {
+		t.Fatalf("expected exit 2 for a public-only file, got %d %q", code, stderr)
+	}
+
+	// sign refuses the mismatched file and fills in the missing public key.
+	stderr = captureStderr(t, func() { code = runSign([]string{"--key", mismatched, "--msg", "x"}) })
+	if code != 2 || !strings.Contains(stderr, "public_key does not match private_key") {
+		t.Fatalf("expected sign to refuse a mismatched key file, got %d %q", code, stderr)
+	}
+	out := captureStdout(t, func() { code = runInfo([]string{"--key", privOnly}) })
+	if code != 0 || !strings.Contains(out, "public_key: "+hex.EncodeToString(kp.PublicKey[:])) {
+		t.Fatalf("expected info to show the derived public key, got %d", code)
+	}
+}
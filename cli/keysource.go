@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Key sources: the key flags (--key and the like) name a key JSON file, or,
+// with a scheme prefix, another source of the same JSON, so that CI systems
+// can pass secrets without writing them to disk:
+//
+//	env:NAME  the environment variable NAME
+//	fd:N      file descriptor N, e.g. a pipe set up by the caller
+//
+// Each source is read once per process, as a command may load its key more
+// than once and a pipe can be read only once. An environment variable is
+// unset once read, so hooks and other child processes do not inherit it.
+// Other sources can be added to keySources.
+var keySources = map[string]func(ref string) ([]byte, error){
+	"env": readEnvKeySource,
+	"fd":  readFDKeySource,
+}
+
+var keySourceCache struct {
+	sync.Mutex
+	data map[string][]byte
+}
+
+// keySourceRef splits a key flag into its scheme and reference, if it names
+// a key source rather than a file.
+func keySourceRef(path string) (scheme, ref string, ok bool) {
+	scheme, ref, found := strings.Cut(path, ":")
+	if !found {
+		return "", "", false
+	}
+	if _, known := keySources[scheme]; !known {
+		return "", "", false
+	}
+	return scheme, ref, true
+}
+
+// readKeySource returns the contents of the key file or key source path. Key
+// files are read with readKeyFile.
+func readKeySource(path string) ([]byte, error) {
+	scheme, ref, ok := keySourceRef(path)
+	if !ok {
+		return readKeyFile(path)
+	}
+	keySourceCache.Lock()
+	defer keySourceCache.Unlock()
+	if b, ok := keySourceCache.data[path]; ok {
+		return b, nil
+	}
+	b, err := keySources[scheme](ref)
+	if err != nil {
+		return nil, fmt.Errorf("key source %s: %w", path, err)
+	}
+	if keySourceCache.data == nil {
+		keySourceCache.data = map[string][]byte{}
+	}
+	keySourceCache.data[path] = b
+	return b, nil
+}
+
+func readEnvKeySource(name string) ([]byte, error) {
+	if name == "" {
+		return nil, fmt.Errorf("missing variable name (want env:NAME)")
+	}
+	v, ok := os.LookupEnv(name)
+	if !ok || v == "" {
+		return nil, fmt.Errorf("$%s is not set", name)
+	}
+	if err := os.Unsetenv(name); err != nil {
+		return nil, err
+	}
+	return []byte(v), nil
+}
+
+func readFDKeySource(ref string) ([]byte, error) {
+	fd, err := strconv.ParseUint(ref, 10, 31)
+	if err != nil {
+		return nil, fmt.Errorf("invalid file descriptor %q (want fd:N)", ref)
+	}
+	if fd <= 2 {
+		return nil, fmt.Errorf("file descriptor %d is a standard stream", fd)
+	}
+	f := os.NewFile(uintptr(fd), "fd:"+ref)
+	if f == nil {
+		return nil, fmt.Errorf("invalid file descriptor %d", fd)
+	}
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("file descriptor %d is empty", fd)
+	}
+	return b, nil
+}
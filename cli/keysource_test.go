@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestKeySources signs with a key passed in an environment variable and
+// through a file descriptor, and checks that neither is left behind.
+func TestKeySources(t *testing.T) {
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("key source seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	dir := t.TempDir()
+	keyJSON, err := os.ReadFile(writeKeypairJSON(t, dir, "keys.json", kp, true))
+	if err != nil {
+		t.Fatalf("read key: %v", err)
+	}
+	pubPath := writeKeypairJSON(t, dir, "pub.json", kp, false)
+	verify := func(sig string) int {
+		var code int
+		_, _ = captureStdoutStderr(t, func() {
+			code = runVerify([]string{"--key", pubPath, "--msg", "ci", "--signature", sig})
+		})
+		return code
+	}
+
+	t.Setenv("FALCON_TEST_KEY_JSON", string(keyJSON))
+	var code int
+	sig := strings.TrimSpace(captureStdout(t, func() {
+		code = runSign([]string{"--key", "env:FALCON_TEST_KEY_JSON", "--msg", "ci"})
+	}))
+	if code != 0 || verify(sig) != 0 {
+		t.Fatalf("sign with env:FALCON_TEST_KEY_JSON failed: %d", code)
+	}
+	if _, ok := os.LookupEnv("FALCON_TEST_KEY_JSON"); ok {
+		t.Fatalf("the key variable was not unset")
+	}
+	// Read again from the cache, after the variable is gone.
+	if _, _, _, err := loadKeypairFile("env:FALCON_TEST_KEY_JSON", nil); err != nil {
+		t.Fatalf("second load failed: %v", err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe failed: %v", err)
+	}
+	go func() {
+		_, _ = w.Write(keyJSON)
+		w.Close()
+	}()
+	fd := "fd:" + strconv.Itoa(int(r.Fd()))
+	sig = strings.TrimSpace(captureStdout(t, func() {
+		code = runSign([]string{"--key", fd, "--msg", "ci"})
+	}))
+	// The key source closed the descriptor; mark r closed too, so that its
+	// finalizer does not close a reused descriptor.
+	_ = r.Close()
+	if code != 0 || verify(sig) != 0 {
+		t.Fatalf("sign with %s failed: %d", fd, code)
+	}
+
+	for ref, want := range map[string]string{
+		"env:FALCON_TEST_UNSET_KEY": "$FALCON_TEST_UNSET_KEY is not set",
+		"env:":                      "missing variable name",
+		"fd:x":                      "invalid file descriptor",
+		"fd:1":                      "standard stream",
+	} {
+		if _, err := readKeySource(ref); err == nil || !strings.Contains(err.Error(), want) {
+			t.Fatalf("%s: expected %q, got %v", ref, want, err)
+		}
+	}
+	// Unknown schemes are file paths.
+	if _, _, ok := keySourceRef("c:/keys.json"); ok {
+		t.Fatalf("c: taken for a key source")
+	}
+}
@@ -0,0 +1,343 @@
+package cli
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// Key usage statistics: create and the signing commands record, per key
+// fingerprint, when the key was created and used, how many signatures it made
+// and on which networks, so rotation policies can be checked with keys list.
+// Recording never makes a command fail.
+const (
+	envKeyStats = "FALCON_KEY_STATS"
+	// keyStatsOff as $FALCON_KEY_STATS disables recording.
+	keyStatsOff      = "off"
+	keyStatsVersion  = 1
+	keyStatsFileName = "keystats.json"
+)
+
+// keyStatsJSON is the statistics file.
+type keyStatsJSON struct {
+	Version int                      `json:"version"`
+	Keys    map[string]*keyUsageJSON `json:"keys"` // by hex fingerprint
+}
+
+// keyUsageJSON is the statistics of one key. Times are RFC 3339.
+type keyUsageJSON struct {
+	KeyFile    string            `json:"key_file,omitempty"` // last path the key was used from
+	Created    string            `json:"created,omitempty"`  // when falcon create wrote it
+	FirstUsed  string            `json:"first_used,omitempty"`
+	LastUsed   string            `json:"last_used,omitempty"`
+	Signatures uint64            `json:"signatures"`
+	Operations map[string]uint64 `json:"operations,omitempty"` // signatures by command
+	Networks   []string          `json:"networks,omitempty"`
+	// Revoked is when falcon revoke declared the key compromised as of.
+	// Signing commands check the revocation store instead (see
+	// refuseRevokedKey), which cannot be turned off.
+	Revoked          string `json:"revoked,omitempty"`
+	RevocationReason string `json:"revocation_reason,omitempty"`
+}
+
+// keyStatsPath returns the statistics file: $FALCON_KEY_STATS, else
+// falcon/keystats.json in the user configuration directory. It returns ""
+// when recording is disabled.
+func keyStatsPath() (string, error) {
+	if p := strings.TrimSpace(os.Getenv(envKeyStats)); p != "" {
+		if p == keyStatsOff {
+			return "", nil
+		}
+		return p, nil
+	}
+	cfg, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("no directory for key statistics (set $%s): %w", envKeyStats, err)
+	}
+	return filepath.Join(cfg, "falcon", keyStatsFileName), nil
+}
+
+// readKeyStats reads the statistics file at path; a missing file has no
+// statistics.
+func readKeyStats(path string) (keyStatsJSON, error) {
+	stats := keyStatsJSON{Version: keyStatsVersion, Keys: map[string]*keyUsageJSON{}}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return stats, nil
+	}
+	if err != nil {
+		return stats, err
+	}
+	if err := json.Unmarshal(b, &stats); err != nil {
+		return stats, fmt.Errorf("invalid JSON in %s: %w", path, err)
+	}
+	if stats.Version > keyStatsVersion {
+		return stats, fmt.Errorf("%s has version %d; this falcon supports up to %d",
+			path, stats.Version, keyStatsVersion)
+	}
+	if stats.Keys == nil {
+		stats.Keys = map[string]*keyUsageJSON{}
+	}
+	return stats, nil
+}
+
+// updateKeyStats applies update to the statistics of pub and writes them
+// back. Failures are reported on stderr as warnings.
+func updateKeyStats(pub []byte, update func(u *keyUsageJSON, now string)) {
+	var pk falcongo.PublicKey
+	if len(pub) != len(pk) {
+		return
+	}
+	copy(pk[:], pub)
+	err := func() error {
+		path, err := keyStatsPath()
+		if err != nil || path == "" {
+			return err
+		}
+		unlock, err := lockFile(path)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		stats, err := readKeyStats(path)
+		if err != nil {
+			return err
+		}
+		fp := falcongo.Fingerprint(pk)
+		id := hex.EncodeToString(fp[:])
+		u := stats.Keys[id]
+		if u == nil {
+			u = &keyUsageJSON{}
+			stats.Keys[id] = u
+		}
+		update(u, time.Now().UTC().Format(time.RFC3339))
+		stats.Version = keyStatsVersion
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return err
+		}
+		return writeFileAtomic(path, append(data, '\n'), 0o600)
+	}()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: cannot update key statistics: %v\n", err)
+	}
+}
+
+// recordKeyCreated records that falcon create wrote the key pub to keyFile
+// ("" for stdout).
+func recordKeyCreated(pub []byte, keyFile string) {
+	updateKeyStats(pub, func(u *keyUsageJSON, now string) {
+		if u.Created == "" {
+			u.Created = now
+		}
+		if keyFile != "" {
+			u.KeyFile = absPath(keyFile)
+		}
+	})
+}
+
+// recordKeyUse records that operation made signatures with the key pub
+// loaded from keyFile, on network if it is not empty.
+func recordKeyUse(pub []byte, keyFile, operation, network string, signatures int) {
+	if signatures <= 0 {
+		return
+	}
+	updateKeyStats(pub, func(u *keyUsageJSON, now string) {
+		if u.FirstUsed == "" {
+			u.FirstUsed = now
+		}
+		u.LastUsed = now
+		u.KeyFile = absPath(keyFile)
+		u.Signatures += uint64(signatures)
+		if u.Operations == nil {
+			u.Operations = map[string]uint64{}
+		}
+		u.Operations[operation] += uint64(signatures)
+		if network != "" && !slices.Contains(u.Networks, network) {
+			u.Networks = append(u.Networks, network)
+			sort.Strings(u.Networks)
+		}
+	})
+}
+
+// recordKeyRevoked records, for keys list, that falcon revoke declared the
+// key pub, loaded from keyFile, compromised as of revokedAt.
+func recordKeyRevoked(pub []byte, keyFile, revokedAt, reason string) {
+	updateKeyStats(pub, func(u *keyUsageJSON, now string) {
+		u.KeyFile = absPath(keyFile)
+		u.Revoked = revokedAt
+		u.RevocationReason = reason
+	})
+}
+
+// keyStatsOf returns the statistics of the key pub, or nil if there are none
+// or they cannot be read.
+func keyStatsOf(pub []byte) *keyUsageJSON {
+	var pk falcongo.PublicKey
+	if len(pub) != len(pk) {
+		return nil
+	}
+	copy(pk[:], pub)
+	path, err := keyStatsPath()
+	if err != nil || path == "" {
+		return nil
+	}
+	stats, err := readKeyStats(path)
+	if err != nil {
+		return nil
+	}
+	fp := falcongo.Fingerprint(pk)
+	return stats.Keys[hex.EncodeToString(fp[:])]
+}
+
+// forgetKeyStats drops the statistics of the key whose public key is pubHex.
+func forgetKeyStats(pubHex string) {
+	fp, err := falcongo.FingerprintFromHex(pubHex)
+	if err != nil {
+		return
+	}
+	path, err := keyStatsPath()
+	if err != nil || path == "" {
+		return
+	}
+	unlock, err := lockFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: cannot update key statistics: %v\n", err)
+		return
+	}
+	defer unlock()
+	stats, err := readKeyStats(path)
+	if err != nil || stats.Keys[hex.EncodeToString(fp[:])] == nil {
+		return
+	}
+	delete(stats.Keys, hex.EncodeToString(fp[:]))
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err == nil {
+		err = writeFileAtomic(path, append(data, '\n'), 0o600)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: cannot update key statistics: %v\n", err)
+	}
+}
+
+// absPath returns path made absolute; key sources (env:, fd:) are kept as
+// given.
+func absPath(path string) string {
+	if _, _, ok := keySourceRef(path); ok {
+		return path
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// keyListEntry is one key of keys list --json.
+type keyListEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	Petname     string `json:"petname"`
+	keyUsageJSON
+	AgeDays *int `json:"age_days,omitempty"` // since created, else first used
+}
+
+// ---- keys list ----
+func runKeysList(args []string) int {
+	fs := flag.NewFlagSet("keys list", flag.ExitOnError)
+	stats := fs.Bool("stats", false, "show usage statistics")
+	jsonOut := fs.Bool("json", false, "print the keys and their statistics as JSON")
+	parseFlags(fs, args)
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "keys list does not accept arguments\n")
+		return 2
+	}
+
+	path, err := keyStatsPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 2
+	}
+	if path == "" {
+		fmt.Fprintf(os.Stderr, "key statistics are disabled ($%s=%s)\n", envKeyStats, keyStatsOff)
+		return 2
+	}
+	all, err := readKeyStats(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read key statistics: %v\n", err)
+		return 2
+	}
+
+	now := time.Now()
+	entries := make([]keyListEntry, 0, len(all.Keys))
+	for fp, u := range all.Keys {
+		e := keyListEntry{Fingerprint: fp, Petname: fingerprintPetname(fp), keyUsageJSON: *u}
+		since := u.Created
+		if since == "" {
+			since = u.FirstUsed
+		}
+		if t, err := time.Parse(time.RFC3339, since); err == nil {
+			days := int(now.Sub(t).Hours() / 24)
+			e.AgeDays = &days
+		}
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].KeyFile != entries[j].KeyFile {
+			return entries[i].KeyFile < entries[j].KeyFile
+		}
+		return entries[i].Fingerprint < entries[j].Fingerprint
+	})
+
+	if *jsonOut {
+		data, err := json.MarshalIndent(entries, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to encode JSON: %v\n", err)
+			return 2
+		}
+		fmt.Fprintf(os.Stdout, "%s\n", data)
+		return 0
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if *stats {
+		fmt.Fprintln(tw, "FINGERPRINT\tPETNAME\tAGE\tLAST USED\tSIGNATURES\tNETWORKS\tREVOKED\tKEY FILE")
+	} else {
+		fmt.Fprintln(tw, "FINGERPRINT\tPETNAME\tKEY FILE")
+	}
+	for _, e := range entries {
+		if !*stats {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", e.Fingerprint, e.Petname, orDash(e.KeyFile))
+			continue
+		}
+		age := "-"
+		if e.AgeDays != nil {
+			age = fmt.Sprintf("%dd", *e.AgeDays)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n", e.Fingerprint, e.Petname, age, orDash(e.LastUsed),
+			e.Signatures, orDash(strings.Join(e.Networks, ",")), orDash(e.Revoked), orDash(e.KeyFile))
+	}
+	if err := tw.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write list: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestKeysList_Stats checks that create and sign are recorded and listed, and
+// that destroy forgets the key.
+func TestKeysList_Stats(t *testing.T) {
+	dir := t.TempDir()
+	statsPath := filepath.Join(dir, "stats", keyStatsFileName)
+	t.Setenv(envKeyStats, statsPath)
+	keyPath := filepath.Join(dir, "key.json")
+
+	if code := runCreate([]string{"--out", keyPath}); code != 0 {
+		t.Fatalf("create failed: %d", code)
+	}
+	for _, msg := range []string{"a", "b"} {
+		var code int
+		captureStdout(t, func() { code = runSign([]string{"--key", keyPath, "--msg", msg}) })
+		if code != 0 {
+			t.Fatalf("sign failed: %d", code)
+		}
+	}
+
+	var code int
+	out := captureStdout(t, func() { code = runKeysList([]string{"--json"}) })
+	if code != 0 {
+		t.Fatalf("keys list --json failed: %d", code)
+	}
+	var entries []keyListEntry
+	if err := json.Unmarshal([]byte(out), &entries); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected one key, got %+v", entries)
+	}
+	e := entries[0]
+	if e.KeyFile != keyPath || e.Created == "" || e.LastUsed == "" || e.Signatures != 2 ||
+		e.Operations["sign"] != 2 || e.AgeDays == nil || *e.AgeDays != 0 ||
+		e.Petname != fingerprintPetname(e.Fingerprint) || strings.Count(e.Petname, "-") != 2 {
+		t.Fatalf("unexpected entry: %+v", e)
+	}
+	if info, err := os.Stat(statsPath); err != nil || info.Mode().Perm() != 0o600 {
+		t.Fatalf("statistics file: %v, %v", info, err)
+	}
+
+	out = captureStdout(t, func() { code = runKeysList([]string{"--stats"}) })
+	if code != 0 || !strings.Contains(out, "SIGNATURES") || !strings.Contains(out, e.Fingerprint+"  "+e.Petname+"  0d") {
+		t.Fatalf("unexpected --stats output (exit %d):\n%s", code, out)
+	}
+
+	if code := runKeysDestroy([]string{"--key", keyPath}); code != 0 {
+		t.Fatalf("destroy failed: %d", code)
+	}
+	out = captureStdout(t, func() { code = runKeysList([]string{"--json"}) })
+	if code != 0 || strings.TrimSpace(out) != "[]" {
+		t.Fatalf("expected no keys after destroy, got %q", out)
+	}
+}
+
+// TestKeysList_Disabled checks that recording can be turned off.
+func TestKeysList_Disabled(t *testing.T) {
+	t.Setenv(envKeyStats, keyStatsOff)
+	var code int
+	_, stderr := captureStdoutStderr(t, func() { code = runKeysList(nil) })
+	if code != 2 || !strings.Contains(stderr, "disabled") {
+		t.Fatalf("got exit %d, stderr %q", code, stderr)
+	}
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"os"
+	"testing"
+)
+
+// TestMain keeps the tests from recording key statistics, and makes the user
+// configuration directory and the home directory of accountConfigDir, home of
+// the revocation store and the second factor enrollments, a temporary
+// directory.
+func TestMain(m *testing.M) {
+	os.Setenv(envKeyStats, keyStatsOff)
+	dir, err := os.MkdirTemp("", "falcon-cli-test")
+	if err != nil {
+		panic(err)
+	}
+	for _, name := range userConfigEnv {
+		os.Setenv(name, dir)
+	}
+	accountHomeDir = func() (string, error) { return dir, nil }
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// userConfigEnv are the variables os.UserConfigDir is derived from on Unix,
+// macOS and Windows.
+var userConfigEnv = []string{"XDG_CONFIG_HOME", "HOME", "AppData"}
+
+// useTempUserConfigDir moves os.UserConfigDir and accountConfigDir into a new
+// temporary directory for the duration of the test.
+func useTempUserConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range userConfigEnv {
+		t.Setenv(name, dir)
+	}
+	useAccountHomeDir(t, dir, nil)
+}
+
+// useAccountHomeDir makes accountHomeDir return dir and err for the duration
+// of the test.
+func useAccountHomeDir(t *testing.T, dir string, err error) {
+	prev := accountHomeDir
+	accountHomeDir = func() (string, error) { return dir, err }
+	t.Cleanup(func() { accountHomeDir = prev })
+}
@@ -0,0 +1,378 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/mnemonic"
+)
+
+// migrationReportVersion is the version of the --report of algorand migrate.
+const migrationReportVersion = 1
+
+// migrationReportJSON is the --report of algorand migrate.
+type migrationReportJSON struct {
+	Version     int                       `json:"version"`
+	Network     string                    `json:"network"`
+	Mode        string                    `json:"mode"` // plan, rekey or sweep
+	From        string                    `json:"from"`
+	To          string                    `json:"to"`
+	Fingerprint string                    `json:"key_fingerprint"`
+	Petname     string                    `json:"key_petname"`
+	Amount      uint64                    `json:"amount"`
+	MinBalance  uint64                    `json:"min_balance"`
+	AuthAddr    string                    `json:"auth_addr,omitempty"`
+	Assets      []algorand.MigrationAsset `json:"assets"`
+	Blockers    []string                  `json:"close_blockers"`
+	Steps       []algorand.MigrationStep  `json:"steps"`
+	Error       string                    `json:"error,omitempty"`
+	NextSteps   []string                  `json:"next_steps"`
+}
+
+// ---- algorand migrate ----
+func runAlgorandMigrate(args []string) int {
+	fs := flag.NewFlagSet("algorand migrate", flag.ExitOnError)
+	keyPath := fs.String("key", "", "path to FALCON keypair JSON file of the PQ account to migrate to")
+	fromEd25519 := fs.String("from-ed25519", "", "25-word Algorand mnemonic of the account to migrate, - to read it from stdin, or kmd")
+	kmdWallet := fs.String("kmd-wallet", "", "with --from-ed25519 kmd: name of the wallet (default: the only one)")
+	kmdAddress := fs.String("kmd-address", "", "with --from-ed25519 kmd: account of the wallet (default: the only one)")
+	kmdPasswordFile := fs.String("kmd-password-file", "", "with --from-ed25519 kmd: read the wallet password from the first line of a file")
+	kmdURL := fs.String("kmd-url", "", "with --from-ed25519 kmd: KMD endpoint (default: $KMD_URL)")
+	kmdToken := fs.String("kmd-token", "", "with --from-ed25519 kmd: KMD API token (requires --kmd-url)")
+	rekey := fs.Bool("rekey", false, "rekey the account to the PQ account, keeping its address")
+	confirmRekey := fs.String("confirm-rekey", "", "repeat the PQ address to confirm --rekey without a prompt")
+	sweep := fs.Bool("sweep", false, "move the assets and Algos of the account to the PQ account")
+	confirmSweep := fs.String("confirm-sweep", "", "repeat the PQ address to confirm --sweep without a prompt")
+	reportPath := fs.String("report", "", "write the migration report JSON to this file")
+	networkFlag := fs.String("network", "mainnet", "network: mainnet, testnet, betanet, devnet")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	algodURL := fs.String("algod-url", "", "set algod API endpoint (optional)")
+	algodToken := fs.String("algod-token", "", "set algod API token (optional); requires --algod-url")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	algodURLProvided := false
+	algodTokenProvided := false
+	kmdFlagSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if strings.HasPrefix(f.Name, "kmd-") {
+			kmdFlagSet = true
+		}
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+		if f.Name == "algod-url" {
+			algodURLProvided = true
+		}
+		if f.Name == "algod-token" {
+			algodTokenProvided = true
+		}
+	})
+
+	if *keyPath == "" {
+		fmt.Fprintf(os.Stderr, "--key is required\n")
+		return 2
+	}
+	if *fromEd25519 == "" {
+		fmt.Fprintf(os.Stderr, "--from-ed25519 is required\n")
+		return 2
+	}
+	useKMD := *fromEd25519 == "kmd"
+	if kmdFlagSet && !useKMD {
+		fmt.Fprintf(os.Stderr, "--kmd-* flags require --from-ed25519 kmd\n")
+		return 2
+	}
+	if *kmdToken != "" && *kmdURL == "" {
+		fmt.Fprintf(os.Stderr, "--kmd-token requires --kmd-url\n")
+		return 2
+	}
+	if *rekey && *sweep {
+		fmt.Fprintf(os.Stderr, "--rekey and --sweep are mutually exclusive\n")
+		return 2
+	}
+	if *confirmRekey != "" && !*rekey {
+		fmt.Fprintf(os.Stderr, "--confirm-rekey requires --rekey\n")
+		return 2
+	}
+	if *confirmSweep != "" && !*sweep {
+		fmt.Fprintf(os.Stderr, "--confirm-sweep requires --sweep\n")
+		return 2
+	}
+	// stdin holds the mnemonic, so it cannot also take the confirmation.
+	if *fromEd25519 == "-" && ((*rekey && *confirmRekey == "") || (*sweep && *confirmSweep == "")) {
+		fmt.Fprintf(os.Stderr, "with --from-ed25519 -, confirm with --confirm-rekey or --confirm-sweep\n")
+		return 2
+	}
+	if algodTokenProvided && !algodURLProvided {
+		fmt.Fprintf(os.Stderr, "--algod-token requires --algod-url\n")
+		return 2
+	}
+	netw, err := parseAlgorandNetwork(*networkFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --network: %v\n", err)
+		return 2
+	}
+	network := strings.ToLower(strings.TrimSpace(*networkFlag))
+
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	var kp falcongo.KeyPair
+	if *sweep {
+		var code int
+		if kp, code = loadSigningKeyPair("--key", *keyPath, override, "opting the PQ account into assets"); code != 0 {
+			return code
+		}
+	} else {
+		pub, _, _, err := loadKeypairFile(*keyPath, override)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", *keyPath, err)
+			return 2
+		}
+		if pub == nil {
+			fmt.Fprintf(os.Stderr, "public key not found in %s\n", *keyPath)
+			return 2
+		}
+		copy(kp.PublicKey[:], pub)
+	}
+
+	var source algorand.MigrationSource
+	if useKMD {
+		var password string
+		if *kmdPasswordFile != "" {
+			b, err := os.ReadFile(*kmdPasswordFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to read --kmd-password-file: %v\n", err)
+				return 2
+			}
+			password, _, _ = strings.Cut(string(b), "\n")
+			password = strings.TrimSuffix(password, "\r")
+		}
+		var release func()
+		source, release, err = algorand.KMDSource(algorand.KMDOptions{
+			URL:      strings.TrimSpace(*kmdURL),
+			Token:    strings.TrimSpace(*kmdToken),
+			Wallet:   *kmdWallet,
+			Password: password,
+			Address:  *kmdAddress,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --from-ed25519 kmd: %v\n", err)
+			return 2
+		}
+		defer release()
+	} else {
+		sk, err := readEd25519Mnemonic(*fromEd25519)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --from-ed25519: %v\n", err)
+			return 2
+		}
+		if source, err = algorand.Ed25519Source(sk); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --from-ed25519: %v\n", err)
+			return 2
+		}
+	}
+
+	if algodURLProvided {
+		if err := setAlgodEnv(strings.TrimSpace(*algodURL), strings.TrimSpace(*algodToken)); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to set ALGOD_URL: %v\n", err)
+			return 2
+		}
+	}
+
+	plan, err := algorand.PlanMigration(source.Address, kp.PublicKey, netw)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read the accounts: %v\n", err)
+		return 2
+	}
+	fp := falcongo.Fingerprint(kp.PublicKey)
+	report := migrationReportJSON{
+		Version:     migrationReportVersion,
+		Network:     network,
+		Mode:        "plan",
+		From:        plan.From.String(),
+		To:          plan.To.String(),
+		Fingerprint: fmt.Sprintf("%x", fp[:]),
+		Petname:     mnemonic.Petname(fp),
+		Amount:      plan.Amount,
+		MinBalance:  plan.MinBalance,
+		AuthAddr:    plan.AuthAddr,
+		Assets:      plan.Assets,
+		Blockers:    plan.Blockers,
+		Steps:       []algorand.MigrationStep{},
+	}
+	if report.Assets == nil {
+		report.Assets = []algorand.MigrationAsset{}
+	}
+	if report.Blockers == nil {
+		report.Blockers = []string{}
+	}
+	printMigrationPlan(os.Stdout, plan, report.Petname)
+
+	code := 0
+	var runErr error
+	opt := algorand.MigrationOptions{Network: netw, OnStep: func(step algorand.MigrationStep) {
+		report.Steps = append(report.Steps, step)
+		printMigrationStep(os.Stdout, step)
+	}}
+	if *rekey || *sweep {
+		// Refuse before asking for confirmation; RekeyToPQ and Sweep check again.
+		runErr = plan.CheckSource()
+	}
+	switch {
+	case runErr != nil:
+	case *rekey:
+		report.Mode = "rekey"
+		fmt.Fprintf(os.Stderr, "WARNING: this transaction rekeys %s to the PQ account %s.\n", plan.From, plan.To)
+		fmt.Fprintf(os.Stderr, "WARNING: once confirmed, its Ed25519 key can no longer authorize its transactions;\n")
+		fmt.Fprintf(os.Stderr, "WARNING: only the FALCON key %s in %s can, including to undo the rekey.\n",
+			report.Petname, *keyPath)
+		if code = confirmTyped("--confirm-rekey", "PQ address", plan.To.String(), *confirmRekey,
+			"type the PQ address to confirm: "); code != 0 {
+			break
+		}
+		_, runErr = algorand.RekeyToPQ(source, plan, opt)
+	case *sweep:
+		report.Mode = "sweep"
+		fmt.Fprintf(os.Stderr, "WARNING: this moves the assets and Algos listed above from %s to the PQ account %s.\n",
+			plan.From, plan.To)
+		if plan.Closable() {
+			fmt.Fprintf(os.Stderr, "WARNING: the account is closed: its address is emptied, and only its Ed25519 key can use it again.\n")
+		}
+		if code = confirmTyped("--confirm-sweep", "PQ address", plan.To.String(), *confirmSweep,
+			"type the PQ address to confirm: "); code != 0 {
+			break
+		}
+		var steps []algorand.MigrationStep
+		steps, runErr = algorand.Sweep(source, kp, plan, opt)
+		optIns := 0
+		for _, step := range steps {
+			if step.Action == "asset" && !assetOptedIn(plan, step.AssetID) {
+				optIns++
+			}
+		}
+		if optIns > 0 {
+			recordKeyUse(kp.PublicKey[:], *keyPath, "algorand migrate", network, optIns)
+		}
+	}
+	if runErr != nil {
+		report.Error = runErr.Error()
+		if errors.Is(runErr, algorand.ErrMigrationRekeyed) {
+			fmt.Fprintf(os.Stderr, "migrate refused: %v; nothing sent\n", runErr)
+			code = 1
+		} else {
+			fmt.Fprintf(os.Stderr, "migrate failed: %v\n", runErr)
+			code = 2
+		}
+	}
+	if code == 0 {
+		report.NextSteps = migrationNextSteps(report.Mode, plan, *keyPath)
+	}
+	if report.NextSteps == nil {
+		report.NextSteps = []string{}
+	}
+	for _, s := range report.NextSteps {
+		fmt.Fprintf(os.Stdout, "Next: %s\n", s)
+	}
+
+	if *reportPath != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to encode the report: %v\n", err)
+			return 2
+		}
+		if err := writeFileAtomic(*reportPath, append(data, '\n'), 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *reportPath, err)
+			return 2
+		}
+	}
+	return code
+}
+
+// printMigrationPlan prints the accounts, holdings and blockers of plan.
+func printMigrationPlan(w io.Writer, plan algorand.MigrationPlan, petname string) {
+	fmt.Fprintf(w, "Account:    %s\n", plan.From)
+	fmt.Fprintf(w, "PQ account: %s (key %s)\n", plan.To, petname)
+	fmt.Fprintf(w, "Balance:    %d microAlgos (minimum %d)\n", plan.Amount, plan.MinBalance)
+	if plan.AuthAddr != "" {
+		fmt.Fprintf(w, "Rekeyed to: %s\n", plan.AuthAddr)
+	}
+	fmt.Fprintf(w, "Assets:     %d\n", len(plan.Assets))
+	for _, a := range plan.Assets {
+		status := "PQ account opted in"
+		switch {
+		case a.Stays != "":
+			status = "stays: " + a.Stays
+		case !a.OptedIn:
+			status = "PQ account opts in"
+		}
+		fmt.Fprintf(w, "  %-12d %-20d %s\n", a.ID, a.Amount, status)
+	}
+	if plan.Closable() {
+		fmt.Fprintf(w, "Closable:   yes\n")
+		return
+	}
+	fmt.Fprintf(w, "Closable:   no, the account keeps its minimum balance:\n")
+	for _, b := range plan.Blockers {
+		fmt.Fprintf(w, "  - %s\n", b)
+	}
+}
+
+func printMigrationStep(w io.Writer, step algorand.MigrationStep) {
+	what := step.Action
+	switch step.Action {
+	case "asset":
+		what = fmt.Sprintf("moved %d of asset %d", step.Amount, step.AssetID)
+	case "pay":
+		what = fmt.Sprintf("sent %d microAlgos", step.Amount)
+	case "close":
+		what = fmt.Sprintf("closed the account, sending %d microAlgos", step.Amount)
+	case "rekey":
+		what = "rekeyed the account"
+	}
+	fmt.Fprintf(w, "Round %d: %s (%s)\n", step.Round, what, strings.Join(step.TxIDs, ", "))
+}
+
+// assetOptedIn reports whether the PQ account of plan held assetID already.
+func assetOptedIn(plan algorand.MigrationPlan, assetID uint64) bool {
+	for _, a := range plan.Assets {
+		if a.ID == assetID {
+			return a.OptedIn
+		}
+	}
+	return false
+}
+
+// migrationNextSteps returns what to do after a successful migrate in mode.
+func migrationNextSteps(mode string, plan algorand.MigrationPlan, keyPath string) []string {
+	switch mode {
+	case "rekey":
+		return []string{
+			fmt.Sprintf("spend from %s with: falcon algorand send --key %s --from %s", plan.From, keyPath, plan.From),
+			"keep the Ed25519 mnemonic: it no longer authorizes the account, but identifies it",
+		}
+	case "sweep":
+		if plan.Closable() {
+			return []string{fmt.Sprintf("use the PQ account %s from now on; %s is closed", plan.To, plan.From)}
+		}
+		return []string{
+			fmt.Sprintf("%s keeps its minimum balance until these are resolved: %s", plan.From,
+				strings.Join(plan.Blockers, "; ")),
+			"then run migrate --sweep again, or rekey the account with migrate --rekey",
+		}
+	}
+	if err := plan.CheckSource(); err != nil {
+		return []string{fmt.Sprintf("nothing to migrate: %v", err)}
+	}
+	return []string{
+		"rekey the account to the PQ account, keeping its address, assets and applications: migrate --rekey",
+		"or move its assets and Algos to the PQ account: migrate --sweep",
+	}
+}
@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	sdkmnemonic "github.com/algorand/go-algorand-sdk/v2/mnemonic"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// migrateAlgod serves the accounts of accounts and asset 5, created by
+// creator.
+func migrateAlgod(t *testing.T, accounts map[string]models.Account, creator string) string {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/assets/5" {
+			_ = json.NewEncoder(w).Encode(models.Asset{Index: 5, Params: models.AssetParams{Creator: creator}})
+			return
+		}
+		if info, ok := accounts[strings.TrimPrefix(r.URL.Path, "/v2/accounts/")]; ok {
+			_ = json.NewEncoder(w).Encode(info)
+			return
+		}
+		http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+// TestRunAlgorandMigrate prints and reports the plan of an account, and
+// refuses to rekey an account rekeyed already.
+func TestRunAlgorandMigrate(t *testing.T) {
+	t.Setenv("ALGOD_URL", "")
+	dir := t.TempDir()
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("migrate test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, false)
+	pq, err := algorand.GetAddressFromPublicKey(kp.PublicKey)
+	if err != nil {
+		t.Fatalf("GetAddressFromPublicKey failed: %v", err)
+	}
+	sk := ed25519.NewKeyFromSeed(bytes.Repeat([]byte{9}, ed25519.SeedSize))
+	words, err := sdkmnemonic.FromPrivateKey(sk)
+	if err != nil {
+		t.Fatalf("FromPrivateKey failed: %v", err)
+	}
+	from, err := crypto.GenerateAddressFromSK(sk)
+	if err != nil {
+		t.Fatalf("GenerateAddressFromSK failed: %v", err)
+	}
+	info := models.Account{
+		Address:     from.String(),
+		Amount:      3_000_000,
+		MinBalance:  200_000,
+		Assets:      []models.AssetHolding{{AssetId: 5, Amount: 42}},
+		CreatedApps: []models.Application{{Id: 8}},
+	}
+	accounts := map[string]models.Account{from.String(): info, string(pq): {Address: string(pq)}}
+	url := migrateAlgod(t, accounts, types.Address{3}.String())
+
+	reportPath := filepath.Join(dir, "report.json")
+	var code int
+	stdout, stderr := captureStdoutStderr(t, func() {
+		code = runAlgorandMigrate([]string{"--key", keyPath, "--from-ed25519", words, "--network", "devnet",
+			"--algod-url", url, "--report", reportPath})
+	})
+	if code != 0 {
+		t.Fatalf("expected exit 0, got %d: %s", code, stderr)
+	}
+	for _, want := range []string{
+		"Account:    " + from.String(),
+		"PQ account: " + string(pq),
+		"Balance:    3000000 microAlgos (minimum 200000)",
+		"PQ account opts in",
+		"- created application 8",
+		"Next: rekey the account",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("stdout lacks %q:\n%s", want, stdout)
+		}
+	}
+	data, err := os.ReadFile(reportPath)
+	if err != nil {
+		t.Fatalf("failed to read the report: %v", err)
+	}
+	var report migrationReportJSON
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("invalid report: %v", err)
+	}
+	if report.Version != migrationReportVersion || report.Mode != "plan" || report.Network != "devnet" ||
+		report.From != from.String() || report.To != string(pq) || len(report.Assets) != 1 ||
+		report.Assets[0].Amount != 42 || len(report.Blockers) != 1 || len(report.Steps) != 0 ||
+		len(report.NextSteps) != 2 {
+		t.Fatalf("unexpected report: %s", data)
+	}
+
+	info.AuthAddr = string(pq)
+	accounts[from.String()] = info
+	_, stderr = captureStdoutStderr(t, func() {
+		code = runAlgorandMigrate([]string{"--key", keyPath, "--from-ed25519", words, "--network", "devnet",
+			"--algod-url", url, "--rekey", "--confirm-rekey", string(pq), "--report", reportPath})
+	})
+	if code != 1 || !strings.Contains(stderr, "migrate refused: the account is rekeyed to its PQ account") {
+		t.Fatalf("expected a refusal, got exit %d: %s", code, stderr)
+	}
+	if strings.Contains(stderr, "WARNING") {
+		t.Errorf("refused before the confirmation, yet warned:\n%s", stderr)
+	}
+	data, err = os.ReadFile(reportPath)
+	if err != nil || !strings.Contains(string(data), `"error": "the account is rekeyed`) {
+		t.Fatalf("expected the refusal in the report, got %s (%v)", data, err)
+	}
+}
+
+// TestRunAlgorandMigrate_Usage covers the usage errors of migrate, and of
+// send --from.
+func TestRunAlgorandMigrate_Usage(t *testing.T) {
+	for _, args := range [][]string{
+		{"--from-ed25519", "-"},
+		{"--key", "k.json"},
+		{"--key", "k.json", "--from-ed25519", "-", "--kmd-wallet", "main"},
+		{"--key", "k.json", "--from-ed25519", "kmd", "--kmd-token", "t"},
+		{"--key", "k.json", "--from-ed25519", "kmd", "--rekey", "--sweep"},
+		{"--key", "k.json", "--from-ed25519", "kmd", "--confirm-sweep", "A"},
+		{"--key", "k.json", "--from-ed25519", "-", "--rekey"},
+		{"--key", "k.json", "--from-ed25519", "abandon abandon", "--network", "nowhere"},
+	} {
+		var code int
+		_, _ = captureStdoutStderr(t, func() { code = runAlgorandMigrate(args) })
+		if code != 2 {
+			t.Errorf("%v: expected exit 2, got %d", args, code)
+		}
+	}
+	for _, args := range [][]string{
+		{"--key", "k.json", "--to", types.Address{1}.String(), "--amount", "1", "--from", "nowhere"},
+		{"--key", "k.json", "--to", types.Address{1}.String(), "--amount", "1", "--from", types.Address{2}.String(),
+			"--delegation", "d.json"},
+	} {
+		var code int
+		_, stderr := captureStdoutStderr(t, func() { code = runAlgorandSend(args) })
+		if code != 2 || !strings.Contains(stderr, "--from") {
+			t.Errorf("send %v: expected exit 2 on --from, got %d: %s", args, code, stderr)
+		}
+	}
+}
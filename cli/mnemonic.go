@@ -0,0 +1,300 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/mnemonic"
+)
+
+// ---- mnemonic dispatcher ----
+func runMnemonic(args []string) int {
+	return runSubcommand("mnemonic", args)
+}
+
+// recoverCheckpoint records search progress so an interrupted recovery can resume.
+type recoverCheckpoint struct {
+	Known string `json:"known"`
+	Next  uint64 `json:"next"`
+}
+
+// ---- mnemonic recover ----
+func runMnemonicRecover(args []string) int {
+	fs := flag.NewFlagSet("mnemonic recover", flag.ExitOnError)
+	known := fs.String("known", "", "24-word mnemonic with unknown words replaced by '?'")
+	address := fs.String("address", "", "Algorand address derived from the lost key")
+	keyPath := fs.String("key", "", "keypair/public key JSON file holding the lost public key")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase used when the key was created")
+	passphraseFile := fs.String("passphrase-file", "", "file with candidate mnemonic passphrases, one per line")
+	workers := fs.Int("workers", runtime.NumCPU(), "number of parallel workers")
+	checkpoint := fs.String("checkpoint", "", "file used to save and resume search progress")
+	out := fs.String("out", "", "write recovered keypair JSON to file (stdout summary if empty)")
+	parseFlags(fs, args)
+
+	pattern := strings.Fields(*known)
+	if len(pattern) == 0 {
+		fmt.Fprintln(os.Stderr, "--known is required")
+		return 2
+	}
+	if (*address == "") == (*keyPath == "") {
+		fmt.Fprintln(os.Stderr, "provide exactly one of --address or --key")
+		return 2
+	}
+	if *passphraseFile != "" && *mnemonicPassphrase != "" {
+		fmt.Fprintln(os.Stderr, "cannot combine --mnemonic-passphrase with --passphrase-file")
+		return 2
+	}
+	if *workers < 1 {
+		fmt.Fprintln(os.Stderr, "--workers must be >= 1")
+		return 2
+	}
+	total, err := mnemonic.PartialCandidateCount(pattern)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --known: %v\n", err)
+		return 2
+	}
+
+	var targetPub []byte
+	if *keyPath != "" {
+		pub, _, _, err := loadKeypairFile(*keyPath, nil)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
+			return 2
+		}
+		if pub == nil {
+			fmt.Fprintf(os.Stderr, "public key not found in %s\n", *keyPath)
+			return 2
+		}
+		targetPub = pub
+	}
+	targetAddress := strings.TrimSpace(*address)
+
+	passphrases := []string{*mnemonicPassphrase}
+	if *passphraseFile != "" {
+		passphrases, err = readPassphraseList(*passphraseFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read --passphrase-file: %v\n", err)
+			return 2
+		}
+	}
+
+	start := uint64(0)
+	if *checkpoint != "" {
+		cp, err := readRecoverCheckpoint(*checkpoint)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read --checkpoint: %v\n", err)
+			return 2
+		}
+		if cp != nil {
+			if cp.Known != strings.Join(pattern, " ") {
+				fmt.Fprintln(os.Stderr, "--checkpoint was written for a different --known phrase")
+				return 2
+			}
+			start = cp.Next
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "searching %d combinations (starting at %d) with %d passphrase(s)\n",
+		total, start, len(passphrases))
+	n := uint64(len(passphrases))
+	bar := startProgress("recovering", start*n, total*n)
+
+	type candidate struct {
+		words      []string
+		passphrase string
+	}
+	var (
+		mu    sync.Mutex
+		found *candidate
+		kp    falcongo.KeyPair
+	)
+	matches := func(c candidate) (falcongo.KeyPair, bool) {
+		seed, err := mnemonic.SeedFromMnemonic(c.words, c.passphrase)
+		if err != nil {
+			return falcongo.KeyPair{}, false
+		}
+		k, err := falcongo.GenerateKeyPair(seed[:])
+		if err != nil {
+			return falcongo.KeyPair{}, false
+		}
+		if targetPub != nil {
+			return k, bytes.Equal(k.PublicKey[:], targetPub)
+		}
+		addr, err := algorand.GetAddressFromPublicKey(k.PublicKey)
+		return k, err == nil && string(addr) == targetAddress
+	}
+
+	batchSize := *workers * 4
+	var batch []candidate
+	next := start
+	flush := func() bool {
+		jobs := make(chan candidate)
+		var wg sync.WaitGroup
+		for range *workers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for c := range jobs {
+					if k, ok := matches(c); ok {
+						mu.Lock()
+						found, kp = &c, k
+						mu.Unlock()
+					}
+					bar.add(1)
+				}
+			}()
+		}
+		for _, c := range batch {
+			jobs <- c
+		}
+		close(jobs)
+		wg.Wait()
+		batch = batch[:0]
+		if *checkpoint != "" {
+			if err := writeRecoverCheckpoint(*checkpoint, pattern, next); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to write --checkpoint: %v\n", err)
+			}
+		}
+		return found == nil
+	}
+
+	err = mnemonic.EnumeratePartial(pattern, start, func(index uint64, phrase []string) bool {
+		words := append([]string(nil), phrase...)
+		for _, pass := range passphrases {
+			batch = append(batch, candidate{words: words, passphrase: pass})
+		}
+		next = index + 1
+		if len(batch) < batchSize {
+			return true
+		}
+		return flush()
+	})
+	if err == nil && found == nil && len(batch) > 0 {
+		next = total
+		flush()
+	}
+	bar.finish()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --known: %v\n", err)
+		return 2
+	}
+
+	if found == nil {
+		if *checkpoint != "" {
+			_ = writeRecoverCheckpoint(*checkpoint, pattern, total)
+		}
+		fmt.Fprintln(os.Stdout, "NOT FOUND")
+		return 1
+	}
+
+	if *out == "" {
+		fmt.Fprintf(os.Stdout, "mnemonic: %s\n", strings.Join(found.words, " "))
+		if found.passphrase != "" {
+			fmt.Fprintf(os.Stdout, "mnemonic_passphrase: %s\n", found.passphrase)
+		}
+		return 0
+	}
+	obj := keyPairJSON{
+		PublicKey:          strings.ToLower(hex.EncodeToString(kp.PublicKey[:])),
+		PrivateKey:         strings.ToLower(hex.EncodeToString(kp.PrivateKey[:])),
+		Mnemonic:           strings.Join(found.words, " "),
+		MnemonicPassphrase: found.passphrase,
+	}
+	attestKeyFile(&obj, kp)
+	stampKeyFile(&obj)
+	data, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode keypair JSON: %v\n", err)
+		return 2
+	}
+	if err := writeFileAtomic(*out, data, 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *out, err)
+		return 2
+	}
+	fmt.Fprintf(os.Stdout, "recovered keypair written to %s\n", *out)
+	return 0
+}
+
+// readPassphraseList returns each line of path as a candidate passphrase; an
+// empty line stands for the empty passphrase.
+func readPassphraseList(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var out []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		out = append(out, strings.TrimRight(sc.Text(), "\r"))
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no passphrases in %s", path)
+	}
+	return out, nil
+}
+
+// readRecoverCheckpoint loads a checkpoint file; a missing file returns nil.
+func readRecoverCheckpoint(path string) (*recoverCheckpoint, error) {
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cp recoverCheckpoint
+	if err := json.Unmarshal(b, &cp); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return &cp, nil
+}
+
+func writeRecoverCheckpoint(path string, pattern []string, next uint64) error {
+	data, err := json.Marshal(recoverCheckpoint{Known: strings.Join(pattern, " "), Next: next})
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0o600)
+}
+
+const helpMnemonic = `# falcon mnemonic
+
+Mnemonic utilities.
+
+Usage:
+  falcon mnemonic recover --known "<24 words with ?>" (--address <address> | --key <file>) [flags]
+
+Subcommands:
+  recover   Recover missing words of a damaged 24-word mnemonic
+
+Arguments (recover):
+  --known <words>             24-word mnemonic with up to 3 unknown words written as '?' (required)
+  --address <address>         Algorand address derived from the lost key
+  --key <file>                keypair/public key JSON holding the lost public key
+  --mnemonic-passphrase <string>
+                              mnemonic passphrase used when the key was created
+  --passphrase-file <file>    try each line of the file as the mnemonic passphrase
+  --workers <n>               number of parallel workers (default: number of CPUs)
+  --checkpoint <file>         save progress to file and resume from it when present
+  --out <file>                write the recovered keypair JSON (prints mnemonic if omitted)
+
+Exit codes: 0 when found, 1 when no candidate matches, 2 on usage or I/O errors.
+
+Examples:
+  falcon mnemonic recover --known "abandon ? abandon ... art" --address ALGOADDRESS...
+  falcon mnemonic recover --known "legal ? ... title" --key pubkey.json --checkpoint progress.json
+`
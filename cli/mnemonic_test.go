@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const recoverTestMnemonic = "legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth title"
+
+// TestRunMnemonicRecover_ByPublicKey recovers a missing word using the public key.
+func TestRunMnemonicRecover_ByPublicKey(t *testing.T) {
+	words := strings.Fields(recoverTestMnemonic)
+	kp := deriveKeyPair(t, words, "")
+	dir := t.TempDir()
+	keyPath := writeKeypairJSON(t, dir, "pub.json", kp, false)
+
+	known := append([]string(nil), words...)
+	known[5] = "?"
+	checkpoint := filepath.Join(dir, "progress.json")
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runMnemonicRecover([]string{
+			"--known", strings.Join(known, " "),
+			"--key", keyPath,
+			"--workers", "2",
+			"--checkpoint", checkpoint,
+		})
+	})
+	if code != 0 {
+		t.Fatalf("expected exit 0, got %d", code)
+	}
+	if strings.TrimSpace(out) != "mnemonic: "+recoverTestMnemonic {
+		t.Fatalf("unexpected output: %q", out)
+	}
+
+	b, err := os.ReadFile(checkpoint)
+	if err != nil {
+		t.Fatalf("checkpoint not written: %v", err)
+	}
+	var cp recoverCheckpoint
+	if err := json.Unmarshal(b, &cp); err != nil {
+		t.Fatalf("invalid checkpoint JSON: %v", err)
+	}
+	if cp.Known != strings.Join(known, " ") || cp.Next == 0 {
+		t.Fatalf("unexpected checkpoint contents: %+v", cp)
+	}
+}
+
+// TestRunMnemonicRecover_PassphraseFileToOut finds the passphrase from a list and
+// writes the recovered keypair.
+func TestRunMnemonicRecover_PassphraseFileToOut(t *testing.T) {
+	words := strings.Fields(recoverTestMnemonic)
+	kp := deriveKeyPair(t, words, "beta")
+	dir := t.TempDir()
+	keyPath := writeKeypairJSON(t, dir, "pub.json", kp, false)
+	listPath := writeTempFile(t, dir, "pass.txt", []byte("alpha\nbeta\n"))
+	outPath := filepath.Join(dir, "recovered.json")
+
+	known := append([]string(nil), words...)
+	known[23] = "?"
+
+	var code int
+	captureStdout(t, func() {
+		code = runMnemonicRecover([]string{
+			"--known", strings.Join(known, " "),
+			"--key", keyPath,
+			"--passphrase-file", listPath,
+			"--out", outPath,
+		})
+	})
+	if code != 0 {
+		t.Fatalf("expected exit 0, got %d", code)
+	}
+	pub, priv, meta, err := loadKeypairFile(outPath, nil)
+	if err != nil {
+		t.Fatalf("failed to load recovered keypair: %v", err)
+	}
+	if meta.MnemonicPassphrase != "beta" || meta.Mnemonic != recoverTestMnemonic {
+		t.Fatalf("unexpected recovered metadata: %+v", meta)
+	}
+	if string(pub) != string(kp.PublicKey[:]) || string(priv) != string(kp.PrivateKey[:]) {
+		t.Fatalf("recovered keys do not match original")
+	}
+}
+
+// TestRunMnemonicRecover_NotFound_Returns1 reports a miss when no candidate matches.
+func TestRunMnemonicRecover_NotFound_Returns1(t *testing.T) {
+	words := strings.Fields(recoverTestMnemonic)
+	kp := deriveKeyPair(t, words, "other")
+	dir := t.TempDir()
+	keyPath := writeKeypairJSON(t, dir, "pub.json", kp, false)
+
+	known := append([]string(nil), words...)
+	known[23] = "?"
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runMnemonicRecover([]string{"--known", strings.Join(known, " "), "--key", keyPath})
+	})
+	if code != 1 {
+		t.Fatalf("expected exit 1, got %d", code)
+	}
+	if strings.TrimSpace(out) != "NOT FOUND" {
+		t.Fatalf("unexpected output: %q", out)
+	}
+}
+
+// TestRunMnemonicRecover_FlagValidation covers usage errors.
+func TestRunMnemonicRecover_FlagValidation(t *testing.T) {
+	known := strings.Replace(recoverTestMnemonic, "legal", "?", 1)
+	testCases := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"missing known", []string{"--address", "X"}, "--known is required"},
+		{"missing target", []string{"--known", known}, "exactly one of --address or --key"},
+		{"both targets", []string{"--known", known, "--address", "X", "--key", "k.json"}, "exactly one of --address or --key"},
+		{"no unknown words", []string{"--known", recoverTestMnemonic, "--address", "X"}, "invalid --known"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var code int
+			errOut := captureStderr(t, func() { code = runMnemonicRecover(tc.args) })
+			if code != 2 {
+				t.Fatalf("expected exit 2, got %d", code)
+			}
+			if !strings.Contains(errOut, tc.want) {
+				t.Fatalf("expected %q in stderr, got %q", tc.want, errOut)
+			}
+		})
+	}
+}
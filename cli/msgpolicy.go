@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/mnemonic"
+)
+
+// messagePolicyJSON is the message_policy of a key file: constraints on the
+// messages falcon sign signs with the key, e.g. for a CI key that must sign
+// only digest lines. Every constraint given applies. Key files carrying one
+// need format version keyFileFormatPolicy, so that older builds refuse them
+// instead of signing without the constraints. Other signing commands refuse
+// such keys, as what they sign is not a message the policy describes.
+// Fields are in sorted order, for keys canonicalize.
+type messagePolicyJSON struct {
+	// MaxSize is the largest message, in bytes.
+	MaxSize int `json:"max_size,omitempty"`
+	// Pattern is an RE2 expression the whole message, as UTF-8 text, must
+	// match.
+	Pattern string `json:"pattern,omitempty"`
+	// Schema is a JSON Schema (see jsonSchema for the supported subset) the
+	// message, as a JSON document, must satisfy.
+	Schema json.RawMessage `json:"schema,omitempty"`
+}
+
+// messagePolicy is a compiled message_policy.
+type messagePolicy struct {
+	pattern *regexp.Regexp
+	schema  *jsonSchema
+	maxSize int
+}
+
+// compileMessagePolicy compiles p; a nil p is no policy.
+func compileMessagePolicy(p *messagePolicyJSON) (*messagePolicy, error) {
+	if p == nil {
+		return nil, nil
+	}
+	if p.Pattern == "" && len(p.Schema) == 0 && p.MaxSize == 0 {
+		return nil, errors.New("message_policy has no constraint")
+	}
+	if p.MaxSize < 0 {
+		return nil, errors.New("message_policy max_size is negative")
+	}
+	c := &messagePolicy{maxSize: p.MaxSize}
+	var err error
+	if p.Pattern != "" {
+		if c.pattern, err = regexp.Compile(`^(?:` + p.Pattern + `)$`); err != nil {
+			return nil, fmt.Errorf("invalid message_policy pattern: %w", err)
+		}
+	}
+	if len(p.Schema) > 0 {
+		if c.schema, err = compileJSONSchema(p.Schema); err != nil {
+			return nil, fmt.Errorf("invalid message_policy schema: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// check returns why msg breaks the policy, or nil; a nil policy allows
+// everything.
+func (p *messagePolicy) check(msg []byte) error {
+	if p == nil {
+		return nil
+	}
+	if p.maxSize > 0 && len(msg) > p.maxSize {
+		return fmt.Errorf("message of %d bytes exceeds the max_size of %d of the key's message_policy",
+			len(msg), p.maxSize)
+	}
+	if p.pattern != nil && (!utf8.Valid(msg) || !p.pattern.Match(msg)) {
+		return errors.New("message does not match the pattern of the key's message_policy")
+	}
+	if p.schema != nil {
+		if err := p.schema.validate(msg); err != nil {
+			return fmt.Errorf("message does not satisfy the schema of the key's message_policy: %w", err)
+		}
+	}
+	return nil
+}
+
+// refuseRestrictedKey reports on stderr, and returns true, if the key file
+// meta carries a message_policy, which only falcon sign enforces.
+func refuseRestrictedKey(meta keyPairJSON) bool {
+	if meta.MessagePolicy == nil {
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "refusing to sign: the key has a message_policy and signs only with falcon sign\n")
+	return true
+}
+
+// ---- keys set-policy ----
+func runKeysSetPolicy(args []string) int {
+	fs := flag.NewFlagSet("keys set-policy", flag.ExitOnError)
+	keyPath := fs.String("key", "", "key JSON file, updated in place")
+	pattern := fs.String("pattern", "", "RE2 expression every message must match in full")
+	schemaPath := fs.String("schema", "", "JSON Schema file every message must satisfy")
+	maxSize := fs.Int("max-size", 0, "largest message in bytes")
+	clearPolicy := fs.Bool("clear", false, "remove the message policy")
+	parseFlags(fs, args)
+
+	if *keyPath == "" {
+		fmt.Fprintf(os.Stderr, "--key is required\n")
+		return 2
+	}
+	constrained := *pattern != "" || *schemaPath != "" || *maxSize != 0
+	if constrained == *clearPolicy {
+		fmt.Fprintf(os.Stderr, "provide --pattern, --schema or --max-size, or --clear\n")
+		return 2
+	}
+	if _, _, ok := keySourceRef(*keyPath); ok {
+		fmt.Fprintf(os.Stderr, "--key must be a file: %s cannot be updated\n", *keyPath)
+		return 2
+	}
+	var policy *messagePolicyJSON
+	if constrained {
+		policy = &messagePolicyJSON{Pattern: *pattern, MaxSize: *maxSize}
+		if *schemaPath != "" {
+			b, err := os.ReadFile(*schemaPath)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "failed to read --schema: %v\n", err)
+				return 2
+			}
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, b); err != nil {
+				fmt.Fprintf(os.Stderr, "invalid --schema: %v\n", err)
+				return 2
+			}
+			policy.Schema = compact.Bytes()
+		}
+		if _, err := compileMessagePolicy(policy); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 2
+		}
+	}
+
+	unlock, err := lockFile(*keyPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	defer unlock()
+	k, err := readKeyFileStrict(*keyPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
+		return 2
+	}
+	if *clearPolicy && k.MessagePolicy == nil {
+		fmt.Fprintf(os.Stderr, "%s has no message policy\n", *keyPath)
+		return 2
+	}
+	k.MessagePolicy = policy
+	k.MinReaderVersion = keyFileFormat(k)
+	data, err := json.MarshalIndent(k, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode key JSON: %v\n", err)
+		return 2
+	}
+	if err := writeFileAtomic(*keyPath, append(data, '\n'), 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *keyPath, err)
+		return 2
+	}
+	name := *keyPath
+	if pub, err := parseHex(k.PublicKey); err == nil && len(pub) == falcongo.PublicKeySize {
+		name = "key " + mnemonic.Petname(falcongo.Fingerprint(falcongo.PublicKey(pub)))
+	}
+	if *clearPolicy {
+		fmt.Fprintf(os.Stdout, "%s signs any message again\n", name)
+	} else {
+		fmt.Fprintf(os.Stdout, "%s now signs only messages allowed by its message policy\n", name)
+	}
+	return 0
+}
@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestKeysSetPolicy restricts a key to digest lines, then to a JSON schema,
+// and checks what sign and the other signing commands accept.
+func TestKeysSetPolicy(t *testing.T) {
+	dir := t.TempDir()
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("message policy test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, dir, "ci.json", kp, true)
+	sign := func(args ...string) (int, string) {
+		t.Helper()
+		var code int
+		_, stderr := captureStdoutStderr(t, func() {
+			code = runSign(append([]string{"--key", keyPath}, args...))
+		})
+		return code, stderr
+	}
+	digest := "sha256:" + strings.Repeat("ab", 32)
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runKeysSetPolicy([]string{"--key", keyPath, "--pattern", `sha256:[0-9a-f]{64}\n?`, "--max-size", "80"})
+	})
+	if code != 0 || !strings.Contains(out, "now signs only") {
+		t.Fatalf("set-policy: exit %d, %q", code, out)
+	}
+	k, err := readKeyFileStrict(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.MessagePolicy == nil || k.MinReaderVersion != keyFileFormatPolicy {
+		t.Fatalf("key file after set-policy: %+v", k)
+	}
+
+	if code, stderr := sign("--msg", digest); code != 0 {
+		t.Fatalf("sign a digest line: exit %d, %q", code, stderr)
+	}
+	if code, stderr := sign("--msg", digest+"\n"); code != 0 {
+		t.Fatalf("sign a digest line with newline: exit %d, %q", code, stderr)
+	}
+	for _, msg := range []string{"hello", digest + "\n\n", "x" + digest, strings.ToUpper(digest)} {
+		if code, stderr := sign("--msg", msg); code != 2 || !strings.Contains(stderr, "does not match the pattern") {
+			t.Fatalf("sign %q: exit %d, %q", msg, code, stderr)
+		}
+	}
+	if code, stderr := sign("--msg", "ff", "--hex"); code != 2 || !strings.Contains(stderr, "refusing to sign") {
+		t.Fatalf("sign binary: exit %d, %q", code, stderr)
+	}
+	inDir := filepath.Join(dir, "in")
+	if err := os.MkdirAll(inDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTempFile(t, inDir, "good.txt", []byte(digest+"\n"))
+	writeTempFile(t, inDir, "bad.txt", []byte(strings.Repeat("x", 100)))
+	if code, stderr := sign("--in-dir", inDir, "--out-dir", filepath.Join(dir, "sigs")); code != 2 ||
+		!strings.Contains(stderr, "bad.txt: refusing to sign: message of 100 bytes exceeds the max_size") ||
+		strings.Contains(stderr, "good.txt") {
+		t.Fatalf("sign --in-dir: exit %d, %q", code, stderr)
+	}
+
+	_, stderr := captureStdoutStderr(t, func() {
+		code = runCSRCreate([]string{"--key", keyPath, "--subject", "CN=ci"})
+	})
+	if code != 2 || !strings.Contains(stderr, "signs only with falcon sign") {
+		t.Fatalf("csr create with a restricted key: exit %d, %q", code, stderr)
+	}
+
+	schemaPath := writeTempFile(t, dir, "schema.json", []byte(`{
+		"type": "object",
+		"required": ["artifact", "digest"],
+		"additionalProperties": false,
+		"properties": {
+			"artifact": {"type": "string", "maxLength": 64},
+			"digest": {"type": "string", "pattern": "^sha256:[0-9a-f]{64}$"}
+		}
+	}`))
+	captureStdout(t, func() { code = runKeysSetPolicy([]string{"--key", keyPath, "--schema", schemaPath}) })
+	if code != 0 {
+		t.Fatalf("set-policy --schema: exit %d", code)
+	}
+	if code, stderr := sign("--msg", `{"digest": "`+digest+`", "artifact": "app.tar.gz"}`, "--json-canonicalize"); code != 0 {
+		t.Fatalf("sign a release statement: exit %d, %q", code, stderr)
+	}
+	for msg, want := range map[string]string{
+		`{"digest": "` + digest + `"}`:                              `missing property "artifact"`,
+		`{"digest": "sha1:00", "artifact": "a"}`:                    "$.digest: does not match pattern",
+		`{"digest": "` + digest + `", "artifact": "a", "extra": 1}`: "$.extra: not allowed",
+		digest: "invalid JSON",
+	} {
+		if code, stderr := sign("--msg", msg); code != 2 || !strings.Contains(stderr, want) {
+			t.Fatalf("sign %s: exit %d, %q; want %q", msg, code, stderr, want)
+		}
+	}
+
+	captureStdout(t, func() { code = runKeysSetPolicy([]string{"--key", keyPath, "--clear"}) })
+	if code != 0 {
+		t.Fatalf("set-policy --clear: exit %d", code)
+	}
+	if k, err = readKeyFileStrict(keyPath); err != nil || k.MessagePolicy != nil || k.MinReaderVersion != keyFileFormatBase {
+		t.Fatalf("key file after --clear: %+v, %v", k, err)
+	}
+	if code, stderr := sign("--msg", "hello"); code != 0 {
+		t.Fatalf("sign after --clear: exit %d, %q", code, stderr)
+	}
+}
+
+// TestKeysSetPolicy_Errors checks that invalid policies are refused before
+// the key file is touched.
+func TestKeysSetPolicy_Errors(t *testing.T) {
+	dir := t.TempDir()
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("message policy error seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, dir, "ci.json", kp, true)
+	before, err := os.ReadFile(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{nil, "provide --pattern"},
+		{[]string{"--pattern", "a", "--clear"}, "provide --pattern"},
+		{[]string{"--pattern", "("}, "invalid message_policy pattern"},
+		{[]string{"--max-size", "-1"}, "max_size is negative"},
+		{[]string{"--schema", writeTempFile(t, dir, "s1.json", []byte(`{"type": "object", "oneOf": []}`))}, `unsupported keyword "oneOf"`},
+		{[]string{"--schema", writeTempFile(t, dir, "s2.json", []byte(`{"type": "text"}`))}, `unknown type "text"`},
+		{[]string{"--clear"}, "has no message policy"},
+	} {
+		var code int
+		_, stderr := captureStdoutStderr(t, func() {
+			code = runKeysSetPolicy(append([]string{"--key", keyPath}, tc.args...))
+		})
+		if code != 2 || !strings.Contains(stderr, tc.want) {
+			t.Fatalf("set-policy %q: exit %d, %q; want %q", tc.args, code, stderr, tc.want)
+		}
+	}
+	after, err := os.ReadFile(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(before) {
+		t.Fatalf("key file changed: %s", after)
+	}
+}
@@ -0,0 +1,260 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+)
+
+// envIPFSUploader names the default --ipfs-uploader program.
+const envIPFSUploader = "FALCON_IPFS_UPLOADER"
+
+// ipfsUploader pins a file to IPFS and returns its CID. Implementations for
+// specific pinning services can be added next to programUploader.
+type ipfsUploader interface {
+	Name() string
+	Upload(data []byte) (cid string, err error)
+}
+
+// programUploader pins files with an external program, which receives the
+// file on stdin and writes its CID (or ipfs://<cid>) to stdout.
+type programUploader struct {
+	command string // program path optionally followed by arguments
+}
+
+// Name is the base name of the program, e.g. "pin-to-ipfs".
+func (u programUploader) Name() string {
+	return filepath.Base(strings.Fields(u.command)[0])
+}
+
+func (u programUploader) Upload(data []byte) (string, error) {
+	argv := strings.Fields(u.command)
+	var out bytes.Buffer
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("uploader %q failed: %w", argv[0], err)
+	}
+	cid := strings.TrimPrefix(strings.TrimSpace(out.String()), "ipfs://")
+	if cid == "" || strings.ContainsAny(cid, " \t\r\n/#") {
+		return "", fmt.Errorf("uploader %q returned no valid CID: %q", argv[0], out.String())
+	}
+	return cid, nil
+}
+
+// nftMintResultJSON is the --json output of algorand nft-mint.
+type nftMintResultJSON struct {
+	TxID         string `json:"txid"`
+	AssetID      uint64 `json:"asset_id"`
+	Standard     string `json:"standard"`
+	URL          string `json:"url,omitempty"`
+	MetadataHash string `json:"metadata_hash,omitempty"` // hex, ARC-3 only
+}
+
+// ---- algorand nft-mint ----
+func runAlgorandNFTMint(args []string) int {
+	fs := flag.NewFlagSet("algorand nft-mint", flag.ExitOnError)
+	keyPath := fs.String("key", "", "path to FALCON keypair JSON file")
+	standard := fs.String("standard", "", "metadata standard: arc3 or arc69")
+	metadataPath := fs.String("metadata", "", "path to the metadata JSON file")
+	unitName := fs.String("unit-name", "", "unit name of the asset (up to 8 bytes)")
+	assetName := fs.String("asset-name", "", "asset name (up to 32 bytes; default for arc3: the name in the metadata)")
+	url := fs.String("url", "", "arc3: URL of the metadata; arc69: URL of the media")
+	decimals := fs.Uint("decimals", 0, "decimals of a fractional NFT of 10^decimals units (default 0: a single unit)")
+	uploaderCmd := fs.String("ipfs-uploader", "", "arc3: program pinning the metadata to IPFS, instead of --url (env "+envIPFSUploader+")")
+	networkFlag := fs.String("network", "mainnet", "network: mainnet, testnet, betanet, devnet")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	algodURL := fs.String("algod-url", "", "set algod API endpoint (optional)")
+	algodToken := fs.String("algod-token", "", "set algod API token (optional); requires --algod-url")
+	jsonOut := fs.Bool("json", false, "print the result as JSON")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	algodURLProvided := false
+	algodTokenProvided := false
+	uploaderSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+		if f.Name == "algod-url" {
+			algodURLProvided = true
+		}
+		if f.Name == "algod-token" {
+			algodTokenProvided = true
+		}
+		if f.Name == "ipfs-uploader" {
+			uploaderSet = true
+		}
+	})
+
+	// Validate required flags
+	if *keyPath == "" {
+		fmt.Fprintf(os.Stderr, "--key is required\n")
+		return 2
+	}
+	if *standard != algorand.NFTStandardARC3 && *standard != algorand.NFTStandardARC69 {
+		fmt.Fprintf(os.Stderr, "--standard is required and must be arc3 or arc69\n")
+		return 2
+	}
+	if *metadataPath == "" {
+		fmt.Fprintf(os.Stderr, "--metadata is required\n")
+		return 2
+	}
+	if *decimals > 19 {
+		fmt.Fprintf(os.Stderr, "--decimals must be <= 19\n")
+		return 2
+	}
+	var uploader ipfsUploader
+	if *standard == algorand.NFTStandardARC3 {
+		if cmd := flagOrEnv(*uploaderCmd, uploaderSet, envIPFSUploader); cmd != "" {
+			uploader = programUploader{command: cmd}
+		}
+		if uploader != nil && strings.TrimSpace(*url) != "" {
+			fmt.Fprintf(os.Stderr, "--url and --ipfs-uploader are mutually exclusive\n")
+			return 2
+		}
+		if uploader == nil && strings.TrimSpace(*url) == "" {
+			fmt.Fprintf(os.Stderr, "arc3 needs --url or --ipfs-uploader\n")
+			return 2
+		}
+	} else if uploaderSet {
+		fmt.Fprintf(os.Stderr, "--ipfs-uploader applies to arc3 only: arc69 metadata is stored on-chain\n")
+		return 2
+	}
+	if algodTokenProvided && !algodURLProvided {
+		fmt.Fprintf(os.Stderr, "--algod-token requires --algod-url\n")
+		return 2
+	}
+	trimmedAlgodURL := strings.TrimSpace(*algodURL)
+	trimmedAlgodToken := strings.TrimSpace(*algodToken)
+	if algodURLProvided && trimmedAlgodURL == "" && algodTokenProvided && trimmedAlgodToken != "" {
+		fmt.Fprintf(os.Stderr, "--algod-token requires a non-empty --algod-url\n")
+		return 2
+	}
+
+	netw, err := parseAlgorandNetwork(*networkFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --network: %v\n", err)
+		return 2
+	}
+
+	metadata, err := os.ReadFile(*metadataPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --metadata: %v\n", err)
+		return 2
+	}
+	nft := algorand.NFT{
+		Standard:  *standard,
+		Metadata:  metadata,
+		UnitName:  *unitName,
+		AssetName: *assetName,
+		URL:       strings.TrimSpace(*url),
+		Decimals:  uint32(*decimals),
+	}
+	if nft.Standard == algorand.NFTStandardARC3 {
+		if nft.AssetName == "" {
+			var md struct {
+				Name string `json:"name"`
+			}
+			_ = json.Unmarshal(metadata, &md)
+			nft.AssetName = md.Name
+		}
+		// ARC-3 URLs are marked with #arc3, unless the asset name is.
+		if nft.URL != "" && nft.AssetName != "arc3" && !strings.HasSuffix(nft.AssetName, "@arc3") &&
+			!strings.HasSuffix(nft.URL, "#arc3") {
+			nft.URL += "#arc3"
+		}
+		if uploader != nil {
+			nft.URL = "ipfs://#arc3" // checked again with the CID
+		}
+	}
+	if err := nft.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid NFT: %v\n", err)
+		return 2
+	}
+
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	kp, code := loadSigningKeyPair("--key", *keyPath, override, "minting")
+	if code != 0 {
+		return code
+	}
+	if algorand.BroadcastDisabled() {
+		fmt.Fprintf(os.Stderr, "mint failed: %v\n", algorand.ErrBroadcastDisabled)
+		return 2
+	}
+
+	if uploader != nil {
+		cid, err := uploader.Upload(metadata)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to pin the metadata: %v\n", err)
+			return 2
+		}
+		nft.URL = "ipfs://" + cid + "#arc3"
+		if err := nft.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid NFT: %v\n", err)
+			return 2
+		}
+		fmt.Fprintf(os.Stderr, "Pinned the metadata with %s: %s\n", uploader.Name(), nft.URL)
+	}
+
+	if algodURLProvided {
+		if err := os.Setenv("ALGOD_URL", trimmedAlgodURL); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to set ALGOD_URL: %v\n", err)
+			return 2
+		}
+		if algodTokenProvided {
+			if err := os.Setenv("ALGOD_TOKEN", trimmedAlgodToken); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to set ALGOD_TOKEN: %v\n", err)
+				return 2
+			}
+		}
+	}
+
+	txID, assetID, err := algorand.MintNFT(kp, nft, algorand.MintOptions{Network: netw})
+	if txID != "" {
+		recordKeyUse(kp.PublicKey[:], *keyPath, "algorand nft-mint",
+			strings.ToLower(strings.TrimSpace(*networkFlag)), 1)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mint failed: %v\n", err)
+		return 2
+	}
+
+	res := nftMintResultJSON{TxID: txID, AssetID: assetID, Standard: nft.Standard, URL: nft.URL}
+	if nft.Standard == algorand.NFTStandardARC3 {
+		hash, err := algorand.ARC3MetadataHash(metadata)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to hash the metadata: %v\n", err)
+			return 2
+		}
+		res.MetadataHash = hex.EncodeToString(hash[:])
+	}
+	if *jsonOut {
+		data, err := json.MarshalIndent(res, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to encode result: %v\n", err)
+			return 2
+		}
+		fmt.Fprintf(os.Stdout, "%s\n", data)
+		return 0
+	}
+	fmt.Fprintf(os.Stdout, "Transaction confirmed with id: %s\n", res.TxID)
+	fmt.Fprintf(os.Stdout, "Asset ID: %d\n", res.AssetID)
+	if res.MetadataHash != "" {
+		fmt.Fprintf(os.Stdout, "Metadata hash: %s\n", res.MetadataHash)
+	}
+	return 0
+}
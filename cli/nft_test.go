@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestRunAlgorandNFTMint_Validation checks flag and metadata validation, done
+// before connecting to algod.
+func TestRunAlgorandNFTMint_Validation(t *testing.T) {
+	t.Setenv(envIPFSUploader, "")
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("nft mint test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	dir := t.TempDir()
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+	arc3 := filepath.Join(dir, "arc3.json")
+	if err := os.WriteFile(arc3, []byte(`{"name":"Cat","decimals":1}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	arc69 := filepath.Join(dir, "arc69.json")
+	if err := os.WriteFile(arc69, []byte(`{"standard":"arc69"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--standard", "arc3", "--metadata", arc3}, "--key is required"},
+		{[]string{"--key", keyPath, "--metadata", arc3}, "--standard is required"},
+		{[]string{"--key", keyPath, "--standard", "arc69"}, "--metadata is required"},
+		{[]string{"--key", keyPath, "--standard", "arc3", "--metadata", arc3}, "arc3 needs --url or --ipfs-uploader"},
+		{[]string{"--key", keyPath, "--standard", "arc3", "--metadata", arc3, "--url", "ipfs://x",
+			"--ipfs-uploader", "true"}, "mutually exclusive"},
+		{[]string{"--key", keyPath, "--standard", "arc69", "--metadata", arc69, "--ipfs-uploader", "true"},
+			"applies to arc3 only"},
+		{[]string{"--key", keyPath, "--standard", "arc3", "--metadata", arc3, "--url", "ipfs://x"},
+			"decimals is 1, but the asset has 0"},
+		{[]string{"--key", keyPath, "--standard", "arc69", "--metadata", arc3}, "standard must be \"arc69\""},
+		{[]string{"--key", keyPath, "--standard", "arc69", "--metadata", arc69, "--unit-name", "TOOLONGNAME"},
+			"unit name too long"},
+	}
+	for _, c := range cases {
+		var code int
+		_, stderr := captureStdoutStderr(t, func() { code = runAlgorandNFTMint(c.args) })
+		if code != 2 || !strings.Contains(stderr, c.want) {
+			t.Fatalf("%v: expected %q with exit 2, got code %d: %q", c.args, c.want, code, stderr)
+		}
+	}
+}
+
+// TestProgramUploader checks the CID returned by an uploader program.
+func TestProgramUploader(t *testing.T) {
+	dir := t.TempDir()
+	ok := writeHookScript(t, dir, "pin.sh", `cat > "$(dirname "$0")/pinned"; echo ipfs://bafytest`)
+	cid, err := programUploader{command: ok}.Upload([]byte(`{"name":"Cat"}`))
+	if err != nil || cid != "bafytest" {
+		t.Fatalf("Upload = %q, %v; want bafytest", cid, err)
+	}
+	pinned, err := os.ReadFile(filepath.Join(dir, "pinned"))
+	if err != nil || string(pinned) != `{"name":"Cat"}` {
+		t.Fatalf("uploader received %q, %v", pinned, err)
+	}
+
+	for name, body := range map[string]string{
+		"empty.sh":  "true",
+		"fail.sh":   "exit 1",
+		"spaces.sh": "echo not a cid",
+	} {
+		path := writeHookScript(t, dir, name, body)
+		if cid, err := (programUploader{command: path}).Upload(nil); err == nil {
+			t.Errorf("%s: Upload = %q", name, cid)
+		}
+	}
+}
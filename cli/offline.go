@@ -0,0 +1,227 @@
+package cli
+
+import (
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+)
+
+// Offline sends: algorand suggested-params snapshots the params of a network
+// on a connected machine, algorand send --offline builds and signs a group
+// from the snapshot without network access, and algorand submit broadcasts
+// the signed group, refusing it once its validity window has passed.
+
+// suggestedParamsJSON is the snapshot written by algorand suggested-params.
+type suggestedParamsJSON struct {
+	Network          string `json:"network"`
+	GenesisID        string `json:"genesis_id"`
+	GenesisHash      string `json:"genesis_hash"` // base64
+	ConsensusVersion string `json:"consensus_version"`
+	FirstValid       uint64 `json:"first_valid"`
+	LastValid        uint64 `json:"last_valid"`
+	FeePerByte       uint64 `json:"fee_per_byte"`
+	MinFee           uint64 `json:"min_fee"`
+	Fetched          string `json:"fetched"` // RFC 3339
+}
+
+// readSuggestedParams reads a snapshot written by algorand suggested-params.
+func readSuggestedParams(path string) (string, types.SuggestedParams, error) {
+	var snap suggestedParamsJSON
+	if err := readAuthJSON(path, &snap); err != nil {
+		return "", types.SuggestedParams{}, err
+	}
+	if _, err := parseAlgorandNetwork(snap.Network); err != nil || snap.Network == "" {
+		return "", types.SuggestedParams{}, fmt.Errorf("invalid network %q", snap.Network)
+	}
+	hash, err := base64.StdEncoding.DecodeString(snap.GenesisHash)
+	if err != nil || len(hash) != len(types.Digest{}) {
+		return "", types.SuggestedParams{}, errors.New("invalid genesis_hash")
+	}
+	if snap.LastValid <= snap.FirstValid || snap.MinFee == 0 {
+		return "", types.SuggestedParams{}, errors.New("invalid validity window or fees")
+	}
+	return snap.Network, types.SuggestedParams{
+		Fee:              types.MicroAlgos(snap.FeePerByte),
+		GenesisID:        snap.GenesisID,
+		GenesisHash:      hash,
+		FirstRoundValid:  types.Round(snap.FirstValid),
+		LastRoundValid:   types.Round(snap.LastValid),
+		ConsensusVersion: snap.ConsensusVersion,
+		MinFee:           snap.MinFee,
+	}, nil
+}
+
+// writeOfflineGroup writes a group signed by send --offline as a pending
+// record, ready for algorand submit.
+func writeOfflineGroup(path, operation, network string, g algorand.PendingGroup) int {
+	rec := newPendingRecord(operation, network, g)
+	rec.AlgodURL = ""
+	return writeAuthJSON(rec, path, "signed group")
+}
+
+// ---- algorand suggested-params ----
+func runAlgorandSuggestedParams(args []string) int {
+	fs := flag.NewFlagSet("algorand suggested-params", flag.ExitOnError)
+	networkFlag := fs.String("network", "mainnet", "network: mainnet, testnet, betanet, devnet")
+	algodURL := fs.String("algod-url", "", "set algod API endpoint (optional)")
+	algodToken := fs.String("algod-token", "", "set algod API token (optional); requires --algod-url")
+	out := fs.String("out", "", "write the snapshot JSON to this file (stdout if omitted)")
+	parseFlags(fs, args)
+	algodURLProvided := false
+	algodTokenProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "algod-url" {
+			algodURLProvided = true
+		}
+		if f.Name == "algod-token" {
+			algodTokenProvided = true
+		}
+	})
+
+	if algodTokenProvided && !algodURLProvided {
+		fmt.Fprintf(os.Stderr, "--algod-token requires --algod-url\n")
+		return 2
+	}
+	netw, err := parseAlgorandNetwork(*networkFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --network: %v\n", err)
+		return 2
+	}
+	if algodURLProvided {
+		if err := setAlgodEnv(strings.TrimSpace(*algodURL), strings.TrimSpace(*algodToken)); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to set ALGOD_URL: %v\n", err)
+			return 2
+		}
+	}
+
+	sp, err := algorand.GetSuggestedParams(netw)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to query suggested params: %v\n", err)
+		return 2
+	}
+	return writeAuthJSON(suggestedParamsJSON{
+		Network:          strings.ToLower(strings.TrimSpace(*networkFlag)),
+		GenesisID:        sp.GenesisID,
+		GenesisHash:      base64.StdEncoding.EncodeToString(sp.GenesisHash),
+		ConsensusVersion: sp.ConsensusVersion,
+		FirstValid:       uint64(sp.FirstRoundValid),
+		LastValid:        uint64(sp.LastRoundValid),
+		FeePerByte:       uint64(sp.Fee),
+		MinFee:           sp.MinFee,
+		Fetched:          time.Now().UTC().Format(time.RFC3339),
+	}, *out, "suggested params")
+}
+
+// ---- algorand submit ----
+func runAlgorandSubmit(args []string) int {
+	fs := flag.NewFlagSet("algorand submit", flag.ExitOnError)
+	in := fs.String("in", "", "signed group written by algorand send --offline")
+	networkFlag := fs.String("network", "", "network: mainnet, testnet, betanet, devnet (default: from the file)")
+	algodURL := fs.String("algod-url", "", "set algod API endpoint (optional)")
+	algodToken := fs.String("algod-token", "", "set algod API token (optional); requires --algod-url")
+	explorer := fs.String("explorer", "", "explorer links after sending: allo, pera, none or a URL template (env "+envExplorer+", default allo)")
+	jsonOut := fs.Bool("json", false, "print the result as JSON")
+	pendingDir := fs.String("pending-dir", "", "directory of pending transaction records (env "+envPendingDir+")")
+	parseFlags(fs, args)
+	networkSet := false
+	algodURLProvided := false
+	algodTokenProvided := false
+	explorerSet := false
+	pendingDirSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "network" {
+			networkSet = true
+		}
+		if f.Name == "algod-url" {
+			algodURLProvided = true
+		}
+		if f.Name == "algod-token" {
+			algodTokenProvided = true
+		}
+		if f.Name == "explorer" {
+			explorerSet = true
+		}
+		if f.Name == "pending-dir" {
+			pendingDirSet = true
+		}
+	})
+
+	if *in == "" {
+		fmt.Fprintf(os.Stderr, "--in is required\n")
+		return 2
+	}
+	if algodTokenProvided && !algodURLProvided {
+		fmt.Fprintf(os.Stderr, "--algod-token requires --algod-url\n")
+		return 2
+	}
+	explorerValue := resolveExplorer(*explorer, explorerSet)
+	if _, err := algorand.ExplorerURLs(explorerValue, algorand.MainNet, "", types.Digest{}); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --explorer: %v\n", err)
+		return 2
+	}
+	// Without a usable default directory, recordBeforeBroadcast warns instead.
+	recordDir, recordExplicit, _ := resolvePendingDir(*pendingDir, pendingDirSet)
+	rec, g, err := readPendingRecord(*in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --in: %v\n", err)
+		return 2
+	}
+	network := rec.Network
+	if networkSet {
+		if !strings.EqualFold(strings.TrimSpace(*networkFlag), network) {
+			fmt.Fprintf(os.Stderr, "--network %s does not match the network of %s (%s)\n",
+				*networkFlag, *in, network)
+			return 2
+		}
+	}
+	netw, err := parseAlgorandNetwork(network)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid network in %s: %v\n", *in, err)
+		return 2
+	}
+	if algodURLProvided {
+		if err := setAlgodEnv(strings.TrimSpace(*algodURL), strings.TrimSpace(*algodToken)); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to set ALGOD_URL: %v\n", err)
+			return 2
+		}
+	}
+
+	var recordPath string
+	err = algorand.SubmitGroup(g, netw, recordBeforeBroadcast(recordDir, recordExplicit, rec.Operation, network, &recordPath))
+	switch {
+	case errors.Is(err, algorand.ErrStaleGroup), errors.Is(err, algorand.ErrWrongNetwork):
+		fmt.Fprintf(os.Stderr, "submit refused: %v\n", err)
+		fmt.Fprintln(os.Stderr, "nothing sent; build and sign the group again from a fresh algorand suggested-params")
+		return 1
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "submit failed: %v\n", err)
+		if recordPath != "" {
+			pendingTxID := strings.TrimSuffix(filepath.Base(recordPath), pendingRecordExt)
+			fmt.Fprintf(os.Stderr, "the transaction may have been broadcast; it is recorded in %s\n", recordPath)
+			fmt.Fprintf(os.Stderr, "run 'falcon algorand status --txid %s' to find out whether funds moved\n",
+				pendingTxID)
+		}
+		return 2
+	}
+	if recordPath != "" {
+		if err := os.Remove(recordPath); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: failed to remove %s: %v\n", recordPath, err)
+		}
+	}
+
+	links, _ := algorand.ExplorerURLs(explorerValue, netw, g.TxIDs[0], g.GroupID)
+	if err := printSendResult(os.Stdout, g.TxIDs[0], g.GroupID, links, *jsonOut); err != nil {
+		fmt.Fprintf(os.Stderr, "transaction %s was sent, but printing the result failed: %v\n", g.TxIDs[0], err)
+		return 2
+	}
+	return 0
+}
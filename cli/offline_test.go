@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestRunAlgorandOffline snapshots the suggested params, signs a send from the
+// snapshot without a node and submits it, then refuses it once stale.
+func TestRunAlgorandOffline(t *testing.T) {
+	var round, sent atomic.Uint64
+	round.Store(10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v2/transactions/params":
+			_ = json.NewEncoder(w).Encode(models.TransactionParametersResponse{MinFee: 1000,
+				GenesisId: "testnet-v1.0", GenesisHash: make([]byte, 32), LastRound: round.Load()})
+		case r.URL.Path == "/v2/transactions" && r.Method == http.MethodPost:
+			sent.Add(1)
+			_ = json.NewEncoder(w).Encode(models.PostTransactionsResponse{Txid: "X"})
+		case r.URL.Path == "/v2/status" || strings.HasPrefix(r.URL.Path, "/v2/status/wait-for-block-after/"):
+			_ = json.NewEncoder(w).Encode(models.NodeStatus{LastRound: round.Load()})
+		case strings.HasPrefix(r.URL.Path, "/v2/transactions/pending/"):
+			_, _ = w.Write(msgpack.Encode(models.PendingTransactionInfoResponse{ConfirmedRound: round.Load()}))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("ALGOD_URL", "")
+	dir := t.TempDir()
+	spPath := filepath.Join(dir, "sp.json")
+	groupPath := filepath.Join(dir, "group.json")
+	pendingDir := filepath.Join(dir, "pending")
+
+	if code := runAlgorandSuggestedParams([]string{"--network", "testnet", "--algod-url", srv.URL,
+		"--out", spPath}); code != 0 {
+		t.Fatalf("suggested-params: exit %d", code)
+	}
+
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("offline send test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+	var to types.Address
+	send := []string{"--offline", "--suggested-params", spPath, "--out", groupPath, "--key", keyPath,
+		"--to", to.String(), "--amount", "1"}
+	t.Setenv("ALGOD_URL", "http://127.0.0.1:1") // unreachable: the send must not need it
+	var code int
+	out := captureStdout(t, func() { code = runAlgorandSend(send) })
+	if code != 0 || !strings.Contains(out, "submit it before round 1011") {
+		t.Fatalf("send --offline: exit %d, %q", code, out)
+	}
+
+	var stderr string
+	_, stderr = captureStdoutStderr(t, func() {
+		code = runAlgorandSubmit([]string{"--in", groupPath, "--network", "mainnet"})
+	})
+	if code != 2 || !strings.Contains(stderr, "does not match") {
+		t.Fatalf("expected a network mismatch, got %d %q", code, stderr)
+	}
+
+	round.Store(20)
+	submit := []string{"--in", groupPath, "--algod-url", srv.URL, "--pending-dir", pendingDir,
+		"--explorer", "none"}
+	out = captureStdout(t, func() { code = runAlgorandSubmit(submit) })
+	if code != 0 || !strings.Contains(out, "Transaction confirmed with id:") || sent.Load() != 1 {
+		t.Fatalf("submit: exit %d, %d sent, %q", code, sent.Load(), out)
+	}
+	if entries, _ := os.ReadDir(pendingDir); len(entries) != 0 {
+		t.Fatalf("expected the pending record to be removed, got %v", entries)
+	}
+
+	round.Store(1011)
+	_, stderr = captureStdoutStderr(t, func() { code = runAlgorandSubmit(submit) })
+	if code != 1 || !strings.Contains(stderr, "stale") || sent.Load() != 1 {
+		t.Fatalf("expected a stale group to be refused, got %d %q", code, stderr)
+	}
+}
+
+// TestRunAlgorandSend_OfflineUsage covers the flag errors of send --offline.
+func TestRunAlgorandSend_OfflineUsage(t *testing.T) {
+	for _, c := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--offline", "--out", "g.json"}, "--offline requires --suggested-params and --out"},
+		{[]string{"--suggested-params", "sp.json"}, "require --offline"},
+		{[]string{"--offline", "--suggested-params", "sp.json", "--out", "g.json", "--fee-strategy", "min"},
+			"cannot be combined"},
+		{[]string{"--offline", "--suggested-params", "missing.json", "--out", "g.json"}, "invalid --suggested-params"},
+	} {
+		args := append([]string{"--key", "dummy.json", "--to", "ALGOADDRESS", "--amount", "1"}, c.args...)
+		var code int
+		_, stderr := captureStdoutStderr(t, func() { code = runAlgorandSend(args) })
+		if code != 2 || !strings.Contains(stderr, c.want) {
+			t.Fatalf("%v: expected %q with exit 2, got code %d: %q", c.args, c.want, code, stderr)
+		}
+	}
+}
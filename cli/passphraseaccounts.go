@@ -0,0 +1,223 @@
+package cli
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/mnemonic"
+)
+
+// passphraseAccountJSON is an account of the passphrase_accounts of a key
+// file: the address and fingerprint of the keys one passphrase derives from
+// the file's mnemonic, under a hint chosen by the user. As with the hidden
+// wallets of hardware wallets, each passphrase (the "25th word") opens a
+// separate account, e.g. a decoy and a real one; the passphrase itself is
+// never stored.
+type passphraseAccountJSON struct {
+	Hint        string `json:"hint"`
+	Address     string `json:"address"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// Warnings printed wherever passphrase accounts are listed or recorded.
+const (
+	passphraseHintsWarning = "warning: passphrase hints are stored in clear text: anyone who reads this file learns " +
+		"how many passphrase accounts its mnemonic has and what their hints say; keep accounts you may have to " +
+		"deny out of files you may have to reveal"
+	passphraseTypoWarning = "warning: every passphrase opens a valid account: a mistyped passphrase opens an empty " +
+		"one, without error; check the address before receiving funds"
+)
+
+// mnemonicPublicKey derives the public key of a mnemonic and passphrase. The
+// seed and private key are wiped once the public key is known.
+func mnemonicPublicKey(phrase []string, passphrase string) (falcongo.PublicKey, error) {
+	seed, err := mnemonic.SeedFromMnemonic(phrase, passphrase)
+	if err != nil {
+		return falcongo.PublicKey{}, err
+	}
+	kp, err := falcongo.GenerateKeyPair(seed[:])
+	wipeBytes(seed[:])
+	wipeBytes(kp.PrivateKey[:])
+	if err != nil {
+		return falcongo.PublicKey{}, fmt.Errorf("failed to generate keypair: %w", err)
+	}
+	return kp.PublicKey, nil
+}
+
+// newPassphraseAccount derives the account a passphrase opens.
+func newPassphraseAccount(hint string, phrase []string, passphrase string) (passphraseAccountJSON, error) {
+	pk, err := mnemonicPublicKey(phrase, passphrase)
+	if err != nil {
+		return passphraseAccountJSON{}, err
+	}
+	addr, err := algorand.GetAddressFromPublicKey(pk)
+	if err != nil {
+		return passphraseAccountJSON{}, err
+	}
+	fp := falcongo.Fingerprint(pk)
+	return passphraseAccountJSON{Hint: hint, Address: string(addr), Fingerprint: hex.EncodeToString(fp[:])}, nil
+}
+
+// ---- keys add-passphrase-account ----
+func runKeysAddPassphraseAccount(args []string) int {
+	fs := flag.NewFlagSet("keys add-passphrase-account", flag.ExitOnError)
+	keyPath := fs.String("key", "", "key JSON file with a mnemonic, updated in place")
+	hint := fs.String("hint", "", "hint recorded for the passphrase, e.g. decoy or real (never the passphrase)")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "passphrase of the account (may be empty)")
+	promptPassphrase := fs.Bool("mnemonic-passphrase-prompt", false, "read the passphrase from stdin, with confirmation")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+	})
+
+	if *keyPath == "" || strings.TrimSpace(*hint) == "" {
+		fmt.Fprintf(os.Stderr, "--key and --hint are required\n")
+		return 2
+	}
+	if passphraseProvided == *promptPassphrase {
+		fmt.Fprintf(os.Stderr, "provide exactly one of --mnemonic-passphrase or --mnemonic-passphrase-prompt\n")
+		return 2
+	}
+	if _, _, ok := keySourceRef(*keyPath); ok {
+		fmt.Fprintf(os.Stderr, "--key must be a file: %s cannot be updated\n", *keyPath)
+		return 2
+	}
+	k, err := readKeyFileStrict(*keyPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
+		return 2
+	}
+	phrase := strings.Fields(k.Mnemonic)
+	if len(phrase) == 0 {
+		fmt.Fprintf(os.Stderr, "%s has no mnemonic: passphrase accounts need one\n", *keyPath)
+		return 2
+	}
+	pass := *mnemonicPassphrase
+	if *promptPassphrase {
+		if pass, err = promptMnemonicPassphrase(false); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 2
+		}
+	}
+
+	acct, err := newPassphraseAccount(strings.TrimSpace(*hint), phrase, pass)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to derive the account: %v\n", err)
+		return 2
+	}
+	// The file is re-read under its lock, so that concurrent invocations
+	// do not drop each other's accounts.
+	unlock, err := lockFile(*keyPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	defer unlock()
+	if k, err = readKeyFileStrict(*keyPath); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
+		return 2
+	}
+	for _, a := range k.PassphraseAccounts {
+		if a.Hint == acct.Hint {
+			fmt.Fprintf(os.Stderr, "hint %q is already recorded\n", acct.Hint)
+			return 2
+		}
+		if a.Fingerprint == acct.Fingerprint {
+			fmt.Fprintf(os.Stderr, "this passphrase is already recorded as %q\n", a.Hint)
+			return 2
+		}
+	}
+	k.PassphraseAccounts = append(k.PassphraseAccounts, acct)
+	data, err := json.MarshalIndent(k, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode key JSON: %v\n", err)
+		return 2
+	}
+	if err := writeFileAtomic(*keyPath, append(data, '\n'), 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *keyPath, err)
+		return 2
+	}
+	fmt.Printf("%s: %s (fingerprint %s, petname %s)\n", acct.Hint, acct.Address, acct.Fingerprint,
+		fingerprintPetname(acct.Fingerprint))
+	fmt.Fprintln(os.Stderr, passphraseHintsWarning)
+	fmt.Fprintln(os.Stderr, passphraseTypoWarning)
+	return 0
+}
+
+// listPassphraseAccounts prints the passphrase accounts recorded in the key
+// file at path, for info --list-passphrase-accounts. Given a passphrase, it
+// also shows which account that passphrase opens, and exits 1 if none.
+func listPassphraseAccounts(path string, passphrase *string) int {
+	b, err := readKeySource(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
+		return 2
+	}
+	if err := checkKeyFileVersion(b); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
+		return 2
+	}
+	var k keyPairJSON
+	if err := json.Unmarshal(b, &k); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --key: invalid JSON: %v\n", err)
+		return 2
+	}
+	if len(k.PassphraseAccounts) == 0 {
+		fmt.Fprintf(os.Stderr, "no passphrase accounts recorded in %s; add them with 'falcon keys add-passphrase-account'\n", path)
+		return 0
+	}
+
+	// The account of the file's own public key, and the one the given
+	// passphrase opens.
+	ownFingerprint := publicKeyFingerprint(strings.ToLower(strings.TrimSpace(k.PublicKey)))
+	var given passphraseAccountJSON
+	if passphrase != nil {
+		phrase := strings.Fields(k.Mnemonic)
+		if len(phrase) == 0 {
+			fmt.Fprintf(os.Stderr, "%s has no mnemonic to derive --mnemonic-passphrase from\n", path)
+			return 2
+		}
+		if given, err = newPassphraseAccount("", phrase, *passphrase); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to derive the account of --mnemonic-passphrase: %v\n", err)
+			return 2
+		}
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "HINT\tADDRESS\tFINGERPRINT")
+	found := false
+	for _, a := range k.PassphraseAccounts {
+		var marks []string
+		if a.Fingerprint == ownFingerprint {
+			marks = append(marks, "public_key of this file")
+		}
+		if passphrase != nil && a.Fingerprint == given.Fingerprint {
+			marks = append(marks, "opened by --mnemonic-passphrase")
+			found = true
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s", a.Hint, a.Address, a.Fingerprint)
+		if len(marks) > 0 {
+			fmt.Fprintf(tw, "\t(%s)", strings.Join(marks, ", "))
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+	fmt.Fprintln(os.Stderr, passphraseHintsWarning)
+	fmt.Fprintln(os.Stderr, passphraseTypoWarning)
+	if passphrase != nil && !found {
+		fmt.Fprintf(os.Stderr, "--mnemonic-passphrase opens none of these accounts but %s (fingerprint %s): "+
+			"check it for typos\n", given.Address, given.Fingerprint)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPassphraseAccounts(t *testing.T) {
+	wordStr := "legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth title"
+	path := filepath.Join(t.TempDir(), "backup.json")
+	if err := os.WriteFile(path, []byte(`{"mnemonic": "`+wordStr+`"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	decoy, err := newPassphraseAccount("decoy", strings.Fields(wordStr), "")
+	if err != nil {
+		t.Fatalf("newPassphraseAccount failed: %v", err)
+	}
+	hidden, err := newPassphraseAccount("real", strings.Fields(wordStr), "TREZOR")
+	if err != nil {
+		t.Fatalf("newPassphraseAccount failed: %v", err)
+	}
+	if decoy.Address == hidden.Address {
+		t.Fatalf("both passphrases open %s", decoy.Address)
+	}
+
+	var code int
+	out, stderr := captureStdoutStderr(t, func() {
+		code = runKeys([]string{"add-passphrase-account", "--key", path, "--hint", "decoy", "--mnemonic-passphrase", ""})
+	})
+	if code != 0 || !strings.Contains(out, decoy.Address) || !strings.Contains(stderr, "clear text") {
+		t.Fatalf("add decoy: exit %d, %q, %q", code, out, stderr)
+	}
+	withStdin(t, "TREZOR\nTREZOR\n", func() {
+		_, stderr = captureStdoutStderr(t, func() {
+			code = runKeys([]string{"add-passphrase-account", "--key", path, "--hint", "real", "--mnemonic-passphrase-prompt"})
+		})
+	})
+	if code != 0 {
+		t.Fatalf("add real: exit %d, %q", code, stderr)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var k keyPairJSON
+	if err := json.Unmarshal(b, &k); err != nil {
+		t.Fatal(err)
+	}
+	if len(k.PassphraseAccounts) != 2 || k.PassphraseAccounts[0] != decoy || k.PassphraseAccounts[1] != hidden ||
+		k.MnemonicPassphrase != "" || strings.Contains(string(b), "TREZOR") {
+		t.Fatalf("key file after adding accounts: %s", b)
+	}
+
+	for _, args := range [][]string{
+		{"--hint", "real", "--mnemonic-passphrase", "other"},
+		{"--hint", "again", "--mnemonic-passphrase", "TREZOR"},
+		{"--hint", "none"},
+	} {
+		_, stderr = captureStdoutStderr(t, func() {
+			code = runKeys(append([]string{"add-passphrase-account", "--key", path}, args...))
+		})
+		if code != 2 || stderr == "" {
+			t.Fatalf("add %q: exit %d, %q", args, code, stderr)
+		}
+	}
+
+	// The file has no passphrase, but listing needs none.
+	out, stderr = captureStdoutStderr(t, func() {
+		code = runInfo([]string{"--key", path, "--list-passphrase-accounts"})
+	})
+	if code != 0 || !strings.Contains(out, decoy.Address) || !strings.Contains(out, hidden.Address) ||
+		strings.Contains(out, "opened by") || !strings.Contains(stderr, "mistyped passphrase") {
+		t.Fatalf("list: exit %d, %q, %q", code, out, stderr)
+	}
+	out = captureStdout(t, func() {
+		code = runInfo([]string{"--key", path, "--list-passphrase-accounts", "--mnemonic-passphrase", "TREZOR"})
+	})
+	var marked string
+	for _, line := range strings.Split(out, "\n") {
+		if strings.Contains(line, "opened by --mnemonic-passphrase") {
+			marked = line
+		}
+	}
+	if code != 0 || !strings.HasPrefix(marked, "real ") {
+		t.Fatalf("list with the real passphrase: exit %d, %q", code, out)
+	}
+	_, stderr = captureStdoutStderr(t, func() {
+		code = runInfo([]string{"--key", path, "--list-passphrase-accounts", "--mnemonic-passphrase", "TREZ0R"})
+	})
+	if code != 1 || !strings.Contains(stderr, "opens none of these accounts") {
+		t.Fatalf("list with a mistyped passphrase: exit %d, %q", code, stderr)
+	}
+}
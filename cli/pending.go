@@ -0,0 +1,334 @@
+package cli
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+)
+
+// Pending transaction records: algorand send writes one before broadcasting
+// and removes it once the transaction is confirmed, so a send that timed out
+// or was interrupted leaves a record that algorand status can resume from.
+const (
+	envPendingDir    = "FALCON_PENDING_DIR"
+	pendingRecordExt = ".json"
+	// statusWaitRounds matches the wait of algorand send.
+	statusWaitRounds = 9
+	// Transaction IDs are base32 SHA-512/256 digests.
+	txIDSize = 32
+)
+
+var txIDEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// pendingRecordJSON is the on-disk record of a broadcast group. It holds the
+// signed transactions but no key material.
+type pendingRecordJSON struct {
+	Operation   string   `json:"operation"` // e.g. "algorand send"
+	Network     string   `json:"network"`
+	AlgodURL    string   `json:"algod_url,omitempty"`
+	TxIDs       []string `json:"txids"`
+	Group       string   `json:"group"`        // base64
+	SignedGroup string   `json:"signed_group"` // base64
+	FirstValid  uint64   `json:"first_valid"`
+	LastValid   uint64   `json:"last_valid"`
+	Created     string   `json:"created"` // RFC 3339
+}
+
+// resolvePendingDir returns --pending-dir, falling back to $FALCON_PENDING_DIR
+// and then to falcon/pending in the user configuration directory. explicit
+// reports whether the directory came from the flag or the environment.
+func resolvePendingDir(flagValue string, flagSet bool) (dir string, explicit bool, err error) {
+	if dir := flagOrEnv(flagValue, flagSet, envPendingDir); dir != "" {
+		return dir, true, nil
+	}
+	cfg, err := os.UserConfigDir()
+	if err != nil {
+		return "", false, fmt.Errorf("no directory for pending transaction records (set --pending-dir): %w", err)
+	}
+	return filepath.Join(cfg, "falcon", "pending"), false, nil
+}
+
+// recordBeforeBroadcast returns an OnBroadcast callback that records the group
+// in dir and stores the record's path in *path. When dir was configured
+// explicitly, a record that cannot be written aborts the broadcast; with the
+// default directory, or none, the group is sent unrecorded after a warning.
+func recordBeforeBroadcast(dir string, explicit bool, operation, network string, path *string) func(algorand.PendingGroup) error {
+	return func(g algorand.PendingGroup) error {
+		if dir == "" {
+			fmt.Fprintln(os.Stderr, "warning: sending without a pending record: "+
+				"no directory for pending transaction records (set --pending-dir)")
+			return nil
+		}
+		p, err := writePendingRecord(dir, operation, network, g)
+		if err != nil {
+			if explicit {
+				return fmt.Errorf("cannot record the transaction before broadcasting it "+
+					"(nothing sent; see --pending-dir): %w", err)
+			}
+			fmt.Fprintf(os.Stderr, "warning: sending without a pending record: %v\n", err)
+			return nil
+		}
+		*path = p
+		return nil
+	}
+}
+
+// pendingRecordPath names the record of a group after its last transaction,
+// the one whose confirmation is awaited.
+func pendingRecordPath(dir, txID string) string {
+	return filepath.Join(dir, txID+pendingRecordExt)
+}
+
+// newPendingRecord returns the record of g, sent through $ALGOD_URL.
+func newPendingRecord(operation, network string, g algorand.PendingGroup) pendingRecordJSON {
+	return pendingRecordJSON{
+		Operation:   operation,
+		Network:     network,
+		AlgodURL:    os.Getenv("ALGOD_URL"),
+		TxIDs:       g.TxIDs,
+		Group:       base64.StdEncoding.EncodeToString(g.GroupID[:]),
+		SignedGroup: base64.StdEncoding.EncodeToString(g.SignedGroup),
+		FirstValid:  g.FirstValid,
+		LastValid:   g.LastValid,
+		Created:     time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// writePendingRecord records g in dir and returns the record's path.
+func writePendingRecord(dir, operation, network string, g algorand.PendingGroup) (string, error) {
+	data, err := json.MarshalIndent(newPendingRecord(operation, network, g), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	path := pendingRecordPath(dir, g.TxIDs[len(g.TxIDs)-1])
+	if err := writeFileAtomic(path, append(data, '\n'), 0o600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// readPendingRecord reads a record written by writePendingRecord.
+func readPendingRecord(path string) (pendingRecordJSON, algorand.PendingGroup, error) {
+	var rec pendingRecordJSON
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return rec, algorand.PendingGroup{}, err
+	}
+	if err := json.Unmarshal(b, &rec); err != nil {
+		return rec, algorand.PendingGroup{}, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if len(rec.TxIDs) == 0 {
+		return rec, algorand.PendingGroup{}, errors.New("no txids in record")
+	}
+	g := algorand.PendingGroup{
+		TxIDs:      rec.TxIDs,
+		FirstValid: rec.FirstValid,
+		LastValid:  rec.LastValid,
+	}
+	gid, err := base64.StdEncoding.DecodeString(rec.Group)
+	if err != nil || len(gid) != len(g.GroupID) {
+		return rec, algorand.PendingGroup{}, errors.New("invalid group in record")
+	}
+	copy(g.GroupID[:], gid)
+	if g.SignedGroup, err = base64.StdEncoding.DecodeString(rec.SignedGroup); err != nil {
+		return rec, algorand.PendingGroup{}, fmt.Errorf("invalid signed_group in record: %w", err)
+	}
+	return rec, g, nil
+}
+
+// describePending formats the status of the group ending with txID for
+// algorand status.
+func describePending(txID string, st algorand.PendingStatus, g algorand.PendingGroup) string {
+	var s string
+	switch st.State {
+	case algorand.StateConfirmed:
+		s = fmt.Sprintf("confirmed in round %d", st.ConfirmedRound)
+	case algorand.StatePending:
+		s = "pending in the transaction pool"
+	case algorand.StateRejected:
+		s = "rejected: " + st.PoolError
+	case algorand.StateExpired:
+		s = fmt.Sprintf("expired after round %d without being committed; no funds moved", g.LastValid)
+	default:
+		s = "not seen by the node"
+	}
+	if !st.Final() && g.LastValid != 0 {
+		s += fmt.Sprintf(" (valid until round %d, now %d)", g.LastValid, st.LastRound)
+	}
+	if st.Rebroadcast {
+		s += "; broadcast again"
+	}
+	return txID + ": " + s
+}
+
+// ---- algorand status ----
+func runAlgorandStatus(args []string) int {
+	fs := flag.NewFlagSet("algorand status", flag.ExitOnError)
+	txID := fs.String("txid", "", "transaction ID to check (resumes from its pending record, if any)")
+	pending := fs.Bool("pending", false, "check every pending transaction record")
+	networkFlag := fs.String("network", "", "network: mainnet, testnet, betanet, devnet (default: from the record, else mainnet)")
+	algodURL := fs.String("algod-url", "", "set algod API endpoint (default: from the record)")
+	algodToken := fs.String("algod-token", "", "set algod API token (optional); requires --algod-url")
+	pendingDir := fs.String("pending-dir", "", "directory of pending transaction records (env "+envPendingDir+")")
+	wait := fs.Uint64("wait", statusWaitRounds, "rounds to wait for pending transactions (0: check once)")
+	noRebroadcast := fs.Bool("no-rebroadcast", false, "do not broadcast a recorded group again if the node has not seen it")
+	parseFlags(fs, args)
+	pendingDirSet := false
+	networkSet := false
+	algodURLProvided := false
+	algodTokenProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "pending-dir" {
+			pendingDirSet = true
+		}
+		if f.Name == "network" {
+			networkSet = true
+		}
+		if f.Name == "algod-url" {
+			algodURLProvided = true
+		}
+		if f.Name == "algod-token" {
+			algodTokenProvided = true
+		}
+	})
+
+	if (*txID == "") == !*pending {
+		fmt.Fprintln(os.Stderr, "provide exactly one of --txid or --pending")
+		return 2
+	}
+	*txID = strings.TrimSpace(*txID)
+	if *txID != "" {
+		if b, err := txIDEncoding.DecodeString(*txID); err != nil || len(b) != txIDSize {
+			fmt.Fprintf(os.Stderr, "invalid --txid %q\n", *txID)
+			return 2
+		}
+	}
+	if algodTokenProvided && !algodURLProvided {
+		fmt.Fprintf(os.Stderr, "--algod-token requires --algod-url\n")
+		return 2
+	}
+	if _, err := parseAlgorandNetwork(*networkFlag); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --network: %v\n", err)
+		return 2
+	}
+	dir, _, err := resolvePendingDir(*pendingDir, pendingDirSet)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	var paths []string
+	if *pending {
+		if paths, err = filepath.Glob(filepath.Join(dir, "*"+pendingRecordExt)); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to list %s: %v\n", dir, err)
+			return 2
+		}
+		sort.Strings(paths)
+		if len(paths) == 0 {
+			fmt.Fprintln(os.Stdout, "no pending transactions")
+			return 0
+		}
+	} else {
+		paths = []string{pendingRecordPath(dir, *txID)}
+	}
+
+	envURL, envToken := os.Getenv("ALGOD_URL"), os.Getenv("ALGOD_TOKEN")
+	if algodURLProvided {
+		envURL, envToken = strings.TrimSpace(*algodURL), strings.TrimSpace(*algodToken)
+	}
+	code := 0
+	for _, path := range paths {
+		rec, g, err := readPendingRecord(path)
+		if err == nil {
+			// Records are checked under their lock, held until all are
+			// checked; paths are sorted, so concurrent invocations take the
+			// locks in the same order.
+			unlock, err := lockFile(path)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 2
+			}
+			defer unlock()
+			// Another invocation may have resolved the record meanwhile.
+			rec, g, err = readPendingRecord(path)
+		}
+		hasRecord := err == nil
+		switch {
+		case hasRecord:
+		case *pending && errors.Is(err, os.ErrNotExist):
+			continue
+		case !*pending && errors.Is(err, os.ErrNotExist):
+			// No record: the transaction can only be found while the node
+			// remembers it.
+			rec = pendingRecordJSON{}
+			g = algorand.PendingGroup{TxIDs: []string{*txID}}
+		default:
+			fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", path, err)
+			return 2
+		}
+
+		network := rec.Network
+		if networkSet || network == "" {
+			network = *networkFlag
+		}
+		netw, err := parseAlgorandNetwork(network)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid network in %s: %v\n", path, err)
+			return 2
+		}
+		url, token := envURL, envToken
+		if !algodURLProvided && rec.AlgodURL != "" {
+			url = rec.AlgodURL
+		}
+		if err := setAlgodEnv(url, token); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to set ALGOD_URL: %v\n", err)
+			return 2
+		}
+
+		st, err := algorand.CheckPending(g, algorand.CheckOptions{
+			Network:     netw,
+			WaitRounds:  *wait,
+			Rebroadcast: !*noRebroadcast,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to check %s: %v\n", g.TxIDs[len(g.TxIDs)-1], err)
+			return 2
+		}
+		fmt.Fprintln(os.Stdout, describePending(g.TxIDs[len(g.TxIDs)-1], st, g))
+		if st.Final() && hasRecord {
+			if err := os.Remove(path); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to remove %s: %v\n", path, err)
+			}
+			// Invocations waiting on the lock find the record gone.
+			_ = os.Remove(path + lockFileExt)
+		}
+		if st.State != algorand.StateConfirmed {
+			code = 1
+		}
+	}
+	return code
+}
+
+// setAlgodEnv points GetAlgodClient at url (the network default if empty).
+func setAlgodEnv(url, token string) error {
+	if url == "" {
+		return os.Unsetenv("ALGOD_URL")
+	}
+	if err := os.Setenv("ALGOD_URL", url); err != nil {
+		return err
+	}
+	return os.Setenv("ALGOD_TOKEN", token)
+}
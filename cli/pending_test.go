@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+)
+
+// TestRunAlgorandStatus_Pending resumes a recorded send against a node that
+// has confirmed it, and removes the record.
+func TestRunAlgorandStatus_Pending(t *testing.T) {
+	const txID = "UD6JLKRAKFRDQDBPMUDQ3WM3WAFLA7TUQGSAI5AAA2WYPX3NFXFQ"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/status":
+			_ = json.NewEncoder(w).Encode(models.NodeStatus{LastRound: 15})
+		case "/v2/transactions/pending/" + txID:
+			_, _ = w.Write(msgpack.Encode(models.PendingTransactionInfoResponse{ConfirmedRound: 14}))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("ALGOD_URL", srv.URL)
+	t.Setenv("ALGOD_TOKEN", "")
+	dir := t.TempDir()
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runAlgorandStatus([]string{"--pending", "--pending-dir", dir})
+	})
+	if code != 0 || strings.TrimSpace(out) != "no pending transactions" {
+		t.Fatalf("expected no pending transactions, got %d %q", code, out)
+	}
+
+	path, err := writePendingRecord(dir, "algorand send", "testnet", algorand.PendingGroup{
+		TxIDs: []string{txID}, SignedGroup: []byte{1}, FirstValid: 10, LastValid: 20,
+	})
+	if err != nil {
+		t.Fatalf("writePendingRecord failed: %v", err)
+	}
+	if filepath.Base(path) != txID+".json" {
+		t.Fatalf("unexpected record path %s", path)
+	}
+	// The record keeps the endpoint it was sent through.
+	t.Setenv("ALGOD_URL", "")
+	out = captureStdout(t, func() {
+		code = runAlgorandStatus([]string{"--pending", "--pending-dir", dir})
+	})
+	if code != 0 || strings.TrimSpace(out) != txID+": confirmed in round 14" {
+		t.Fatalf("expected confirmed, got %d %q", code, out)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected the record to be removed, got %v", err)
+	}
+}
+
+// TestRunAlgorandStatus_Usage covers flag errors.
+func TestRunAlgorandStatus_Usage(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"--pending", "--txid", "X"},
+		{"--txid", "not-a-txid"},
+		{"--pending", "--algod-token", "t"},
+	} {
+		var code int
+		stderr := captureStderr(t, func() { code = runAlgorandStatus(args) })
+		if code != 2 || stderr == "" {
+			t.Fatalf("%v: expected usage error, got %d %q", args, code, stderr)
+		}
+	}
+}
+
+// TestRecordBeforeBroadcast checks that only an explicitly configured pending
+// directory makes a failed record abort the broadcast.
+func TestRecordBeforeBroadcast(t *testing.T) {
+	t.Setenv(envPendingDir, "")
+	t.Setenv("HOME", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	dir, explicit, err := resolvePendingDir("", false)
+	if err == nil || dir != "" || explicit {
+		t.Fatalf("expected no default directory, got %q %v %v", dir, explicit, err)
+	}
+
+	g := algorand.PendingGroup{TxIDs: []string{"TXID"}, SignedGroup: []byte{1}}
+	notDir := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(notDir, nil, 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	for _, tc := range []struct {
+		name     string
+		dir      string
+		explicit bool
+		wantErr  bool
+	}{
+		{"no default directory", "", false, false},
+		{"default directory unusable", notDir, false, false},
+		{"explicit directory unusable", notDir, true, true},
+	} {
+		var path string
+		var err error
+		stderr := captureStderr(t, func() {
+			err = recordBeforeBroadcast(tc.dir, tc.explicit, "algorand send", "testnet", &path)(g)
+		})
+		if (err != nil) != tc.wantErr || path != "" {
+			t.Errorf("%s: err = %v, path = %q", tc.name, err, path)
+		}
+		if !tc.wantErr && !strings.Contains(stderr, "sending without a pending record") {
+			t.Errorf("%s: expected a warning, got %q", tc.name, stderr)
+		}
+	}
+
+	var path string
+	recordDir := filepath.Join(t.TempDir(), "pending")
+	if err := recordBeforeBroadcast(recordDir, true, "algorand send", "testnet", &path)(g); err != nil {
+		t.Fatalf("recordBeforeBroadcast failed: %v", err)
+	}
+	if path != pendingRecordPath(recordDir, "TXID") {
+		t.Fatalf("unexpected record path %q", path)
+	}
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Long operations (vanity search, directory signing, bulk address derivation,
+// mnemonic recovery) report their progress on stderr as a bar with an ETA,
+// redrawn in place. Bars are only drawn when stderr is a terminal, and
+// --no-progress turns them off, e.g. when stderr is captured in logs.
+const noProgressFlag = "--no-progress"
+
+// progressDisabled is set by --no-progress.
+var progressDisabled atomic.Bool
+
+// progressInterval is how often a bar is redrawn.
+const progressInterval = 200 * time.Millisecond
+
+// progressBarWidth is the number of cells of a bar.
+const progressBarWidth = 30
+
+// progress reports the progress of an operation of total steps. A nil
+// *progress reports nothing, so callers need not check whether bars are
+// enabled. It is safe for concurrent use.
+type progress struct {
+	label   string
+	total   uint64
+	initial uint64 // steps done before the operation started, e.g. on resume
+	done    atomic.Uint64
+	start   time.Time
+	out     io.Writer
+	stop    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// startProgress starts a bar for an operation of total steps, initial of
+// which are already done, and returns nil if bars are disabled.
+func startProgress(label string, initial, total uint64) *progress {
+	if progressDisabled.Load() || !isTerminal(os.Stderr) {
+		return nil
+	}
+	p := &progress{label: label, total: total, initial: initial, start: time.Now(),
+		out: os.Stderr, stop: make(chan struct{})}
+	p.done.Store(initial)
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		t := time.NewTicker(progressInterval)
+		defer t.Stop()
+		for {
+			select {
+			case <-p.stop:
+				// Clear the bar so the next output starts on a clean line.
+				fmt.Fprint(p.out, "\r\x1b[K")
+				return
+			case <-t.C:
+				fmt.Fprintf(p.out, "\r%s\x1b[K", renderProgress(p.label, p.done.Load(), p.initial, p.total,
+					time.Since(p.start)))
+			}
+		}
+	}()
+	return p
+}
+
+// add records n more steps done.
+func (p *progress) add(n uint64) {
+	if p != nil {
+		p.done.Add(n)
+	}
+}
+
+// finish removes the bar. It must be called once the operation is over and
+// before anything else is written to stderr.
+func (p *progress) finish() {
+	if p == nil {
+		return
+	}
+	close(p.stop)
+	p.wg.Wait()
+}
+
+// renderProgress formats a bar line: done of total steps after elapsed, with
+// an ETA estimated from the rate since initial steps were done.
+func renderProgress(label string, done, initial, total uint64, elapsed time.Duration) string {
+	done = min(done, total)
+	frac := 1.0
+	if total > 0 {
+		frac = float64(done) / float64(total)
+	}
+	cells := int(frac * progressBarWidth)
+	line := fmt.Sprintf("%s [%s%s] %3.0f%% %d/%d", label,
+		strings.Repeat("#", cells), strings.Repeat(".", progressBarWidth-cells), 100*frac, done, total)
+	switch {
+	case done == total:
+		return line
+	case done <= initial || elapsed <= 0:
+		return line + " ETA --"
+	}
+	eta := time.Duration(float64(elapsed) / float64(done-initial) * float64(total-done))
+	return line + " ETA " + eta.Round(time.Second).String()
+}
+
+// isTerminal reports whether f is a character device, such as a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
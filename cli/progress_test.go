@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRenderProgress(t *testing.T) {
+	for _, tc := range []struct {
+		done, initial, total uint64
+		elapsed              time.Duration
+		want                 string
+	}{
+		{0, 0, 100, 0, "work [..............................]   0% 0/100 ETA --"},
+		{25, 0, 100, 10 * time.Second, "work [#######.......................]  25% 25/100 ETA 30s"},
+		{60, 50, 100, 5 * time.Second, "work [##################............]  60% 60/100 ETA 20s"},
+		{50, 50, 100, 5 * time.Second, "work [###############...............]  50% 50/100 ETA --"},
+		{100, 0, 100, time.Minute, "work [##############################] 100% 100/100"},
+		{0, 0, 0, 0, "work [##############################] 100% 0/0"},
+	} {
+		if got := renderProgress("work", tc.done, tc.initial, tc.total, tc.elapsed); got != tc.want {
+			t.Fatalf("renderProgress(%d, %d, %d, %v) =\n%q, want\n%q",
+				tc.done, tc.initial, tc.total, tc.elapsed, got, tc.want)
+		}
+	}
+}
+
+// TestStartProgress_Disabled checks that no bar is drawn when stderr is not a
+// terminal or with --no-progress, and that a nil bar can be used.
+func TestStartProgress_Disabled(t *testing.T) {
+	p := startProgress("work", 0, 10)
+	if p != nil {
+		t.Fatalf("startProgress returned a bar without a terminal")
+	}
+	p.add(1)
+	p.finish()
+
+	defer progressDisabled.Store(false)
+	var code int
+	captureStdout(t, func() { code = run([]string{"version", "--no-progress"}) })
+	if code != 0 {
+		t.Fatalf("version --no-progress exited with %d", code)
+	}
+	if !progressDisabled.Load() {
+		t.Fatalf("--no-progress did not disable progress bars")
+	}
+}
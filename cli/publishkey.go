@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// ---- algorand publish-key ----
+func runAlgorandPublishKey(args []string) int {
+	fs := flag.NewFlagSet("algorand publish-key", flag.ExitOnError)
+	keyPath := fs.String("key", "", "path to FALCON keypair JSON file")
+	networkFlag := fs.String("network", "mainnet", "network: mainnet, testnet, betanet, devnet")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	algodURL := fs.String("algod-url", "", "set algod API endpoint (optional)")
+	algodToken := fs.String("algod-token", "", "set algod API token (optional); requires --algod-url")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	algodURLProvided := false
+	algodTokenProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+		if f.Name == "algod-url" {
+			algodURLProvided = true
+		}
+		if f.Name == "algod-token" {
+			algodTokenProvided = true
+		}
+	})
+
+	if *keyPath == "" {
+		fmt.Fprintf(os.Stderr, "--key is required\n")
+		return 2
+	}
+	if algodTokenProvided && !algodURLProvided {
+		fmt.Fprintf(os.Stderr, "--algod-token requires --algod-url\n")
+		return 2
+	}
+	netw, err := parseAlgorandNetwork(*networkFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --network: %v\n", err)
+		return 2
+	}
+
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	kp, code := loadSigningKeyPair("--key", *keyPath, override, "publishing the key")
+	if code != 0 {
+		return code
+	}
+
+	if algodURLProvided {
+		if err := setAlgodEnv(strings.TrimSpace(*algodURL), strings.TrimSpace(*algodToken)); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to set ALGOD_URL: %v\n", err)
+			return 2
+		}
+	}
+
+	txIDs, err := algorand.PublishKey(kp, algorand.PublishKeyOptions{Network: netw})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "publish-key failed: %v\n", err)
+		return 2
+	}
+	recordKeyUse(kp.PublicKey[:], *keyPath, "algorand publish-key",
+		strings.ToLower(strings.TrimSpace(*networkFlag)), 1)
+
+	for _, txID := range txIDs {
+		fmt.Fprintf(os.Stdout, "Transaction confirmed with id: %s\n", txID)
+	}
+	hash := falcongo.Fingerprint(kp.PublicKey)
+	fmt.Fprintf(os.Stdout, "public key sha256: %s\n", hex.EncodeToString(hash[:]))
+	return 0
+}
+
+// ---- algorand fetch-key ----
+func runAlgorandFetchKey(args []string) int {
+	fs := flag.NewFlagSet("algorand fetch-key", flag.ExitOnError)
+	address := fs.String("address", "", "PQ address whose published public key to fetch")
+	out := fs.String("out", "", "write the public key JSON to file (stdout if omitted)")
+	networkFlag := fs.String("network", "mainnet", "network: mainnet, testnet, betanet, devnet")
+	indexerURL := fs.String("indexer-url", "", "set indexer API endpoint (optional)")
+	indexerToken := fs.String("indexer-token", "", "set indexer API token (optional); requires --indexer-url")
+	parseFlags(fs, args)
+	indexerURLProvided := false
+	indexerTokenProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "indexer-url" {
+			indexerURLProvided = true
+		}
+		if f.Name == "indexer-token" {
+			indexerTokenProvided = true
+		}
+	})
+
+	if *address == "" {
+		fmt.Fprintf(os.Stderr, "--address is required\n")
+		return 2
+	}
+	if _, err := types.DecodeAddress(*address); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --address: %v\n", err)
+		return 2
+	}
+	if indexerTokenProvided && !indexerURLProvided {
+		fmt.Fprintf(os.Stderr, "--indexer-token requires --indexer-url\n")
+		return 2
+	}
+	netw, err := parseAlgorandNetwork(*networkFlag)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --network: %v\n", err)
+		return 2
+	}
+
+	if indexerURLProvided {
+		if err := setIndexerEnv(strings.TrimSpace(*indexerURL), strings.TrimSpace(*indexerToken)); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to set INDEXER_URL: %v\n", err)
+			return 2
+		}
+	}
+
+	key, err := algorand.FetchPublishedKey(*address, netw)
+	if errors.Is(err, algorand.ErrNoPublishedKey) {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "fetch-key failed: %v\n", err)
+		return 2
+	}
+	if code := writeKeypairOutput(keyPairJSON{
+		PublicKey: strings.ToLower(hex.EncodeToString(key.PublicKey[:])),
+	}, *out); code != 0 {
+		return code
+	}
+	fmt.Fprintf(os.Stderr, "published in round %d by %s\n", key.Round, strings.Join(key.TxIDs, ", "))
+	fmt.Fprintf(os.Stderr, "public key sha256: %s (matches the committed hash)\n", hex.EncodeToString(key.Hash[:]))
+	fmt.Fprintf(os.Stderr, "verified: the public key derives %s\n", *address)
+	return 0
+}
+
+// setIndexerEnv points the indexer client at url, as setAlgodEnv does for
+// algod.
+func setIndexerEnv(url, token string) error {
+	if url == "" {
+		return os.Unsetenv("INDEXER_URL")
+	}
+	if err := os.Setenv("INDEXER_URL", url); err != nil {
+		return err
+	}
+	return os.Setenv("INDEXER_TOKEN", token)
+}
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/client/v2/common/models"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestRunAlgorandFetchKey fetches a key published in two notes from a fake
+// indexer, and exits 1 for an address with no published key.
+func TestRunAlgorandFetchKey(t *testing.T) {
+	t.Setenv("INDEXER_URL", "")
+	t.Setenv("INDEXER_TOKEN", "")
+	dir := t.TempDir()
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("fetch-key test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	addr, err := algorand.GetAddressFromPublicKey(kp.PublicKey)
+	if err != nil {
+		t.Fatalf("GetAddressFromPublicKey failed: %v", err)
+	}
+	hash := sha256.Sum256(kp.PublicKey[:])
+	var txns []models.Transaction
+	for i, chunk := range [][]byte{kp.PublicKey[:900], kp.PublicKey[900:]} {
+		note := append([]byte(algorand.PublishedKeyNotePrefix), hash[:]...)
+		note = append(note, byte(i), 2)
+		txns = append(txns, models.Transaction{Id: "TX" + string(rune('0'+i)), Sender: string(addr),
+			Group: []byte{1}, ConfirmedRound: 42, Note: append(note, chunk...)})
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := models.TransactionsResponse{}
+		if r.URL.Path == "/v2/accounts/"+string(addr)+"/transactions" {
+			resp.Transactions = txns
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	out := filepath.Join(dir, "pub.json")
+	var code int
+	stderr := captureStderr(t, func() {
+		code = runAlgorandFetchKey([]string{"--address", string(addr), "--out", out, "--indexer-url", srv.URL})
+	})
+	if code != 0 {
+		t.Fatalf("fetch-key exit %d: %s", code, stderr)
+	}
+	if !strings.Contains(stderr, "published in round 42 by TX0, TX1") ||
+		!strings.Contains(stderr, hex.EncodeToString(hash[:])) {
+		t.Fatalf("unexpected stderr: %s", stderr)
+	}
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read --out: %v", err)
+	}
+	var obj keyPairJSON
+	if err := json.Unmarshal(data, &obj); err != nil {
+		t.Fatalf("invalid key JSON: %v", err)
+	}
+	if obj.PublicKey != hex.EncodeToString(kp.PublicKey[:]) || obj.PrivateKey != "" {
+		t.Fatalf("unexpected key JSON: %s", data)
+	}
+
+	other, err := falcongo.GenerateKeyPair(deriveSeed([]byte("fetch-key other seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	otherAddr, err := algorand.GetAddressFromPublicKey(other.PublicKey)
+	if err != nil {
+		t.Fatalf("GetAddressFromPublicKey failed: %v", err)
+	}
+	captureStderr(t, func() {
+		code = runAlgorandFetchKey([]string{"--address", string(otherAddr), "--indexer-url", srv.URL})
+	})
+	if code != 1 {
+		t.Fatalf("fetch-key of an unpublished key: exit %d, want 1", code)
+	}
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// Read-only mode disables signing and broadcasting for the whole process, so
+// the same binary can be deployed where it must only verify and derive
+// addresses. It is requested with readOnlyFlag anywhere before a "--"
+// argument, or with envReadOnly.
+const (
+	readOnlyFlag = "--read-only"
+	envReadOnly  = "FALCON_READ_ONLY"
+)
+
+// enterReadOnlyMode applies --read-only and $FALCON_READ_ONLY, and returns
+// args without the flag. Once entered, the mode lasts for the process.
+func enterReadOnlyMode(args []string) ([]string, error) {
+	args, readOnly := extractGlobalBoolFlag(args, readOnlyFlag)
+	if v := strings.TrimSpace(os.Getenv(envReadOnly)); v != "" {
+		on, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid $%s %q (use true or false)", envReadOnly, v)
+		}
+		readOnly = readOnly || on
+	}
+	if readOnly {
+		falcongo.DisableSigning()
+		algorand.DisableBroadcast()
+	}
+	return args, nil
+}
+
+const helpReadOnly = `# falcon --read-only
+
+Run a command with signing and broadcasting disabled.
+
+Usage:
+  falcon <command> [flags] --read-only
+  FALCON_READ_ONLY=true falcon <command> [flags]
+
+The flag is accepted anywhere on the command line; FALCON_READ_ONLY takes
+true/false, 1/0. In read-only mode every FALCON signature fails, whatever the
+command (sign, csr create, attest add, auth respond, vote sign, revoke, algorand
+send/claim/opt-in), so does every Ed25519 signature (algorand delegate,
+migrate, hybrid send), algod clients refuse to post transactions, and algorand
+status does not broadcast recorded transactions again. Verification, address derivation, key
+inspection and algod reads work as usual.
+
+Set FALCON_READ_ONLY in the environment of a deployment that must only verify
+signatures or derive addresses: the mode cannot be turned off from the command
+line.
+`
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	sdkmnemonic "github.com/algorand/go-algorand-sdk/v2/mnemonic"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+func TestExtractGlobalBoolFlag(t *testing.T) {
+	for _, tc := range []struct {
+		args     []string
+		rest     []string
+		readOnly bool
+	}{
+		{[]string{"verify", "--key", "k.json"}, []string{"verify", "--key", "k.json"}, false},
+		{[]string{"--read-only", "verify"}, []string{"verify"}, true},
+		{[]string{"sign", "-read-only", "--key", "k.json"}, []string{"sign", "--key", "k.json"}, true},
+		{[]string{"sign", "--", "--read-only"}, []string{"sign", "--", "--read-only"}, false},
+	} {
+		rest, readOnly := extractGlobalBoolFlag(tc.args, readOnlyFlag)
+		if !slices.Equal(rest, tc.rest) || readOnly != tc.readOnly {
+			t.Fatalf("extractGlobalBoolFlag(%q) = %q, %v; want %q, %v",
+				tc.args, rest, readOnly, tc.rest, tc.readOnly)
+		}
+	}
+}
+
+// Read-only mode cannot be left, so TestRun_ReadOnly runs each case in a
+// child process that runs TestReadOnlyChild.
+const envReadOnlyChildArgs = "FALCON_TEST_READ_ONLY_ARGS"
+
+func TestReadOnlyChild(t *testing.T) {
+	args := os.Getenv(envReadOnlyChildArgs)
+	if args == "" {
+		t.Skip("run by TestRun_ReadOnly")
+	}
+	os.Exit(Run(strings.Split(args, "\n")))
+}
+
+func TestRun_ReadOnly(t *testing.T) {
+	dir := t.TempDir()
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("read-only")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, dir, "key.json", kp, true)
+	sig, err := kp.Sign([]byte("hello"))
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	sigHex := hex.EncodeToString(sig)
+	words, err := sdkmnemonic.FromPrivateKey(ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)))
+	if err != nil {
+		t.Fatalf("FromPrivateKey failed: %v", err)
+	}
+	delegationPath := filepath.Join(dir, "delegation.json")
+
+	for _, tc := range []struct {
+		name    string
+		args    []string
+		env     string
+		code    int
+		wantErr string
+	}{
+		{"sign with flag", []string{"sign", "--key", keyPath, "--msg", "hello", "--read-only"}, "", 2, "signing is disabled"},
+		{"sign with env", []string{"sign", "--key", keyPath, "--msg", "hello"}, "1", 2, "signing is disabled"},
+		{"delegate", []string{"algorand", "delegate", "--key", keyPath, "--from-ed25519-mnemonic", words,
+			"--out", delegationPath, "--read-only"}, "", 2, "signing is disabled"},
+		{"verify", []string{"verify", "--key", keyPath, "--msg", "hello", "--signature", sigHex, "--read-only"}, "", 0, ""},
+		{"invalid env", []string{"verify", "--key", keyPath, "--msg", "hello", "--signature", sigHex}, "maybe", 2, "FALCON_READ_ONLY"},
+	} {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestReadOnlyChild$")
+		cmd.Env = append(os.Environ(),
+			envReadOnlyChildArgs+"="+strings.Join(tc.args, "\n"), envReadOnly+"="+tc.env)
+		var stderr strings.Builder
+		cmd.Stderr = &stderr
+		err := cmd.Run()
+		code := 0
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			code = exitErr.ExitCode()
+		} else if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if code != tc.code || !strings.Contains(stderr.String(), tc.wantErr) {
+			t.Fatalf("%s: exit %d, stderr %q; want exit %d and %q",
+				tc.name, code, stderr.String(), tc.code, tc.wantErr)
+		}
+	}
+	if _, err := os.Stat(delegationPath); !os.IsNotExist(err) {
+		t.Fatalf("a delegation was written in read-only mode (%v)", err)
+	}
+}
@@ -0,0 +1,350 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/hashing"
+)
+
+// Receipts: with --receipts-dir, algorand send archives each confirmed group
+// as <txid>.msgpack, the exact bytes broadcast, beside a <txid>.json receipt,
+// and appends a row to index.csv, so auditors can check what was sent.
+const (
+	envReceiptsDir         = "FALCON_RECEIPTS_DIR"
+	receiptVersion         = 1
+	receiptsIndexName      = "index.csv"
+	receiptExt             = ".json"
+	receiptSignedExt       = ".msgpack"
+	receiptDigestAlgorithm = hashing.Default
+)
+
+// receiptsIndexHeader is the header of index.csv.
+var receiptsIndexHeader = []string{
+	"confirmed", "txid", "round", "network", "operation", "from", "to", "amount", "fee", "signed_group_digest",
+}
+
+// receiptJSON is the receipt of a confirmed group.
+type receiptJSON struct {
+	Version   int      `json:"version"`
+	Operation string   `json:"operation"` // e.g. "algorand send"
+	Network   string   `json:"network"`
+	TxID      string   `json:"txid"`
+	TxIDs     []string `json:"txids"`
+	Group     string   `json:"group"` // base64
+	Round     uint64   `json:"round"`
+	From      string   `json:"from"`
+	To        string   `json:"to"`
+	Amount    uint64   `json:"amount"`
+	Note      string   `json:"note,omitempty"`
+	// Fees are those of each transaction of the group, PQ and dummy, in
+	// group order.
+	Fees     []uint64 `json:"fees"`
+	FeeTotal uint64   `json:"fee_total"`
+	// SignedGroupFile, beside the receipt, holds the signed group as broadcast.
+	SignedGroupFile            string `json:"signed_group_file"`
+	SignedGroupDigest          string `json:"signed_group_digest"` // hex
+	SignedGroupDigestAlgorithm string `json:"signed_group_digest_algorithm"`
+	Confirmed                  string `json:"confirmed"` // RFC 3339, when send observed it
+}
+
+// resolveReceiptsDir returns --receipts-dir, falling back to
+// $FALCON_RECEIPTS_DIR; "" means receipts are not archived.
+func resolveReceiptsDir(flagValue string, flagSet bool) string {
+	return flagOrEnv(flagValue, flagSet, envReceiptsDir)
+}
+
+// newReceipt describes g, confirmed in round, from its signed transactions.
+func newReceipt(operation, network string, g algorand.PendingGroup, round uint64) (receiptJSON, error) {
+	txns, err := g.Txns()
+	if err != nil {
+		return receiptJSON{}, err
+	}
+	pay := txns[0].Txn
+	r := receiptJSON{
+		Version:                    receiptVersion,
+		Operation:                  operation,
+		Network:                    network,
+		TxID:                       g.TxIDs[0],
+		TxIDs:                      g.TxIDs,
+		Group:                      base64.StdEncoding.EncodeToString(g.GroupID[:]),
+		Round:                      round,
+		From:                       pay.Sender.String(),
+		To:                         pay.Receiver.String(),
+		Amount:                     uint64(pay.Amount),
+		Note:                       string(pay.Note),
+		SignedGroupFile:            g.TxIDs[0] + receiptSignedExt,
+		SignedGroupDigest:          hex.EncodeToString(receiptDigestAlgorithm.Sum(g.SignedGroup)),
+		SignedGroupDigestAlgorithm: receiptDigestAlgorithm.String(),
+		Confirmed:                  time.Now().UTC().Format(time.RFC3339),
+	}
+	for _, stxn := range txns {
+		r.Fees = append(r.Fees, uint64(stxn.Txn.Fee))
+		r.FeeTotal += uint64(stxn.Txn.Fee)
+	}
+	return r, nil
+}
+
+// archiveReceipt writes the signed group and receipt of g to dir and appends
+// it to the index. It returns the receipt's path.
+func archiveReceipt(dir, operation, network string, g algorand.PendingGroup, round uint64) (string, error) {
+	r, err := newReceipt(operation, network, g, round)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	if err := writeFileAtomic(filepath.Join(dir, r.SignedGroupFile), g.SignedGroup, 0o600); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, r.TxID+receiptExt)
+	if err := writeFileAtomic(path, append(data, '\n'), 0o600); err != nil {
+		return "", err
+	}
+	return path, appendReceiptIndex(filepath.Join(dir, receiptsIndexName), r)
+}
+
+// appendReceiptIndex appends the row of r to the index at path, writing the
+// header first if the index is new.
+func appendReceiptIndex(path string, r receiptJSON) error {
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+	if st, err := f.Stat(); err == nil && st.Size() == 0 {
+		_ = w.Write(receiptsIndexHeader)
+	}
+	_ = w.Write([]string{
+		r.Confirmed, r.TxID, strconv.FormatUint(r.Round, 10), r.Network, r.Operation, r.From, r.To,
+		strconv.FormatUint(r.Amount, 10), strconv.FormatUint(r.FeeTotal, 10), r.SignedGroupDigest,
+	})
+	w.Flush()
+	if err := w.Error(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readReceiptsIndex reads the rows of the index of dir, header excluded.
+func readReceiptsIndex(dir string) ([]map[string]string, error) {
+	b, err := os.ReadFile(filepath.Join(dir, receiptsIndexName))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	records, err := csv.NewReader(bytes.NewReader(b)).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", receiptsIndexName, err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	header := records[0]
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, rec := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, name := range header {
+			if i < len(rec) {
+				row[name] = rec[i]
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// checkReceipt reads the receipt of txID in dir and checks its archived
+// signed group against its digest and transaction IDs.
+func checkReceipt(dir, txID string) (receiptJSON, error) {
+	var r receiptJSON
+	if err := readAuthJSON(filepath.Join(dir, txID+receiptExt), &r); err != nil {
+		return r, err
+	}
+	if r.Version != receiptVersion {
+		return r, fmt.Errorf("unsupported receipt version %d", r.Version)
+	}
+	alg, err := hashing.Parse(r.SignedGroupDigestAlgorithm)
+	if err != nil {
+		return r, err
+	}
+	if filepath.Base(r.SignedGroupFile) != r.SignedGroupFile {
+		return r, fmt.Errorf("invalid signed_group_file %q", r.SignedGroupFile)
+	}
+	signed, err := os.ReadFile(filepath.Join(dir, r.SignedGroupFile))
+	if err != nil {
+		return r, err
+	}
+	if hex.EncodeToString(alg.Sum(signed)) != r.SignedGroupDigest {
+		return r, errMismatchedReceipt
+	}
+	txns, err := algorand.PendingGroup{SignedGroup: signed}.Txns()
+	if err != nil {
+		return r, err
+	}
+	if crypto.GetTxID(txns[0].Txn) != r.TxID {
+		return r, errMismatchedReceipt
+	}
+	return r, nil
+}
+
+// errMismatchedReceipt is returned by checkReceipt for an archived signed
+// group that is not the one of its receipt.
+var errMismatchedReceipt = errors.New("the archived signed group does not match the digest of its receipt")
+
+// resolveReceiptsDirOrFail is resolveReceiptsDir for receipts list and show,
+// which need a directory.
+func resolveReceiptsDirOrFail(flagValue string, flagSet bool) (string, int) {
+	dir := resolveReceiptsDir(flagValue, flagSet)
+	if dir == "" {
+		fmt.Fprintf(os.Stderr, "--receipts-dir is required (or set $%s)\n", envReceiptsDir)
+		return "", 2
+	}
+	return dir, 0
+}
+
+// ---- algorand receipts ----
+func runAlgorandReceipts(args []string) int {
+	return runSubcommand("algorand receipts", args)
+}
+
+// ---- algorand receipts list ----
+func runAlgorandReceiptsList(args []string) int {
+	fs := flag.NewFlagSet("algorand receipts list", flag.ExitOnError)
+	receiptsDir := fs.String("receipts-dir", "", "directory of receipts (env "+envReceiptsDir+")")
+	jsonOut := fs.Bool("json", false, "print the index as JSON")
+	parseFlags(fs, args)
+	receiptsDirSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "receipts-dir" {
+			receiptsDirSet = true
+		}
+	})
+
+	dir, code := resolveReceiptsDirOrFail(*receiptsDir, receiptsDirSet)
+	if code != 0 {
+		return code
+	}
+	rows, err := readReceiptsIndex(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read receipts: %v\n", err)
+		return 2
+	}
+	if *jsonOut {
+		if rows == nil {
+			rows = []map[string]string{}
+		}
+		return writeAuthJSON(rows, "", "receipts")
+	}
+	if len(rows) == 0 {
+		fmt.Fprintln(os.Stdout, "no receipts")
+		return 0
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CONFIRMED\tTXID\tROUND\tNETWORK\tAMOUNT\tFEE\tTO")
+	for _, r := range rows {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			r["confirmed"], r["txid"], r["round"], r["network"], r["amount"], r["fee"], r["to"])
+	}
+	if err := tw.Flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write receipts: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+// ---- algorand receipts show ----
+func runAlgorandReceiptsShow(args []string) int {
+	fs := flag.NewFlagSet("algorand receipts show", flag.ExitOnError)
+	txID := fs.String("txid", "", "transaction ID of the receipt")
+	receiptsDir := fs.String("receipts-dir", "", "directory of receipts (env "+envReceiptsDir+")")
+	jsonOut := fs.Bool("json", false, "print the receipt as JSON")
+	parseFlags(fs, args)
+	receiptsDirSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "receipts-dir" {
+			receiptsDirSet = true
+		}
+	})
+
+	*txID = strings.TrimSpace(*txID)
+	if b, err := txIDEncoding.DecodeString(*txID); err != nil || len(b) != txIDSize {
+		fmt.Fprintf(os.Stderr, "invalid --txid %q\n", *txID)
+		return 2
+	}
+	dir, code := resolveReceiptsDirOrFail(*receiptsDir, receiptsDirSet)
+	if code != 0 {
+		return code
+	}
+	r, err := checkReceipt(dir, *txID)
+	mismatch := errors.Is(err, errMismatchedReceipt)
+	if err != nil && !mismatch {
+		fmt.Fprintf(os.Stderr, "failed to read the receipt of %s: %v\n", *txID, err)
+		return 2
+	}
+	if *jsonOut {
+		if code := writeAuthJSON(r, "", "receipt"); code != 0 {
+			return code
+		}
+	} else if err := printReceipt(os.Stdout, dir, r); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write the receipt: %v\n", err)
+		return 2
+	}
+	if mismatch {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", *txID, err)
+		return 1
+	}
+	return 0
+}
+
+// printReceipt prints r, whose signed group was checked, as text.
+func printReceipt(w io.Writer, dir string, r receiptJSON) error {
+	fees := make([]string, len(r.Fees))
+	for i, fee := range r.Fees {
+		fees[i] = strconv.FormatUint(fee, 10)
+	}
+	_, err := fmt.Fprintf(w, "txid:         %s\n"+
+		"group:        %s\n"+
+		"operation:    %s\n"+
+		"network:      %s\n"+
+		"round:        %d\n"+
+		"confirmed:    %s\n"+
+		"from:         %s\n"+
+		"to:           %s\n"+
+		"amount:       %d microAlgos\n"+
+		"fee:          %d microAlgos (%s)\n"+
+		"signed group: %s (%s %s)\n",
+		r.TxID, r.Group, r.Operation, r.Network, r.Round, r.Confirmed, r.From, r.To, r.Amount,
+		r.FeeTotal, strings.Join(fees, " + "),
+		filepath.Join(dir, r.SignedGroupFile), r.SignedGroupDigestAlgorithm, r.SignedGroupDigest)
+	return err
+}
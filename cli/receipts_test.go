@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+)
+
+// TestAlgorandReceipts archives a confirmed group, lists and shows it, and
+// detects a tampered archive.
+func TestAlgorandReceipts(t *testing.T) {
+	dir := t.TempDir()
+	var pay, dummy types.Transaction
+	pay.Type = types.PaymentTx
+	pay.Sender = types.Address{1}
+	pay.Receiver = types.Address{2}
+	pay.Amount = 1500
+	pay.Fee = 3000
+	pay.Note = []byte("rent")
+	dummy.Fee = 1000
+	txID := crypto.GetTxID(pay)
+	g := algorand.PendingGroup{
+		TxIDs:       []string{txID},
+		GroupID:     types.Digest{9},
+		SignedGroup: append(msgpack.Encode(types.SignedTxn{Txn: pay}), msgpack.Encode(types.SignedTxn{Txn: dummy})...),
+	}
+	if _, err := archiveReceipt(dir, "algorand send", "testnet", g, 42); err != nil {
+		t.Fatalf("archiveReceipt failed: %v", err)
+	}
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runAlgorandReceiptsList([]string{"--receipts-dir", dir, "--json"})
+	})
+	var rows []map[string]string
+	if err := json.Unmarshal([]byte(out), &rows); err != nil || code != 0 || len(rows) != 1 ||
+		rows[0]["txid"] != txID || rows[0]["round"] != "42" || rows[0]["fee"] != "4000" {
+		t.Fatalf("receipts list: exit %d, %q", code, out)
+	}
+
+	t.Setenv(envReceiptsDir, dir)
+	out = captureStdout(t, func() { code = runAlgorandReceiptsShow([]string{"--txid", txID}) })
+	if code != 0 || !strings.Contains(out, "round:        42\n") ||
+		!strings.Contains(out, "fee:          4000 microAlgos (3000 + 1000)\n") {
+		t.Fatalf("receipts show: exit %d, %q", code, out)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, txID+receiptSignedExt), msgpack.Encode(types.SignedTxn{Txn: dummy}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	var stderr string
+	_, stderr = captureStdoutStderr(t, func() { code = runAlgorandReceiptsShow([]string{"--txid", txID}) })
+	if code != 1 || !strings.Contains(stderr, "does not match") {
+		t.Fatalf("tampered receipt: exit %d, %q", code, stderr)
+	}
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// ---- algorand recovery-address ----
+func runAlgorandRecoveryAddress(args []string) int {
+	fs := flag.NewFlagSet("algorand recovery-address", flag.ExitOnError)
+	primaryPath := fs.String("primary", "", "key JSON file of the primary key, which can always sign")
+	backupPath := fs.String("backup", "", "key JSON file of the backup key, which can sign after --after-round")
+	afterRound := fs.Uint64("after-round", 0, "round after which the backup key can sign")
+	out := fs.String("out", "", "write derived address to file (stdout if empty)")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key files omit it)")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+	})
+
+	if *primaryPath == "" || *backupPath == "" {
+		fmt.Fprintf(os.Stderr, "--primary and --backup are required\n")
+		return 2
+	}
+	if *afterRound == 0 {
+		fmt.Fprintf(os.Stderr, "--after-round is required and must be > 0\n")
+		return 2
+	}
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	var keys [2]falcongo.PublicKey
+	for i, path := range []string{*primaryPath, *backupPath} {
+		pub, _, _, err := loadKeypairFile(path, override)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", path, err)
+			return 2
+		}
+		if pub == nil {
+			fmt.Fprintf(os.Stderr, "public key not found in %s\n", path)
+			return 2
+		}
+		copy(keys[i][:], pub)
+	}
+	if bytes.Equal(keys[0][:], keys[1][:]) {
+		fmt.Fprintf(os.Stderr, "--primary and --backup must be different keys\n")
+		return 2
+	}
+
+	address, _, err := algorand.DeriveRecoveryAddress(keys[0], keys[1], *afterRound)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error deriving address: %v\n", err)
+		return 2
+	}
+
+	if *out == "" {
+		fmt.Fprintln(os.Stdout, address.String())
+		return 0
+	}
+	if err := writeFileAtomic(*out, []byte(address.String()), 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *out, err)
+		return 2
+	}
+	return 0
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+func TestRunAlgorandRecoveryAddress(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	var keys []falcongo.PublicKey
+	for _, name := range []string{"primary.json", "backup.json"} {
+		kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("recovery " + name)))
+		if err != nil {
+			t.Fatalf("GenerateKeyPair failed: %v", err)
+		}
+		paths = append(paths, writeKeypairJSON(t, dir, name, kp, false))
+		keys = append(keys, kp.PublicKey)
+	}
+	want, _, err := algorand.DeriveRecoveryAddress(keys[0], keys[1], 1000)
+	if err != nil {
+		t.Fatalf("DeriveRecoveryAddress failed: %v", err)
+	}
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runAlgorandRecoveryAddress([]string{
+			"--primary", paths[0], "--backup", paths[1], "--after-round", "1000"})
+	})
+	if code != 0 || strings.TrimSpace(out) != want.String() {
+		t.Fatalf("got exit %d, output %q; want 0, %s", code, out, want)
+	}
+
+	outPath := filepath.Join(dir, "address.txt")
+	code = runAlgorandRecoveryAddress([]string{
+		"--primary", paths[0], "--backup", paths[1], "--after-round", "1000", "--out", outPath})
+	if code != 0 {
+		t.Fatalf("expected exit 0 with --out, got %d", code)
+	}
+	if b, err := os.ReadFile(outPath); err != nil || string(b) != want.String() {
+		t.Fatalf("--out wrote %q, %v; want %s", b, err, want)
+	}
+
+	for name, args := range map[string][]string{
+		"no backup":   {"--primary", paths[0], "--after-round", "1000"},
+		"no round":    {"--primary", paths[0], "--backup", paths[1]},
+		"same keys":   {"--primary", paths[0], "--backup", paths[0], "--after-round", "1000"},
+		"missing key": {"--primary", paths[0], "--backup", filepath.Join(dir, "nope.json"), "--after-round", "1"},
+	} {
+		var code int
+		_, errOut := captureStdoutStderr(t, func() { code = runAlgorandRecoveryAddress(args) })
+		if code != 2 || errOut == "" {
+			t.Fatalf("%s: got exit %d, stderr %q; want 2 and an error", name, code, errOut)
+		}
+	}
+}
@@ -0,0 +1,361 @@
+package cli
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// revocationJSON is a statement, self-signed by a FALCON key, that the key is
+// compromised as of a time and must no longer be trusted.
+type revocationJSON struct {
+	Version   int                `json:"version"`
+	Algorithm string             `json:"algorithm"`
+	PublicKey string             `json:"public_key"`
+	RevokedAt string             `json:"revoked_at"` // RFC 3339
+	Reason    string             `json:"reason"`
+	Signature falcongo.Signature `json:"signature"`
+}
+
+const (
+	revocationVersion   = 1
+	revocationAlgorithm = "falcon-1024"
+	revocationDomain    = "falcon-revoke-v1"
+	// maxRevocationList bounds the size of a revocation list fetched over HTTP.
+	maxRevocationList = 16 << 20
+	// revocationStoreFileName holds, in accountConfigDir, the statements of
+	// the keys revoked on this machine.
+	revocationStoreFileName = "revoked.json"
+)
+
+// revocationFetchTimeout bounds the fetch of a revocation list URL.
+var revocationFetchTimeout = 30 * time.Second
+
+// revocationSigningBytes returns the byte string covered by the revocation
+// signature: the domain tag followed by length-prefixed algorithm, public
+// key, time and reason.
+func revocationSigningBytes(r revocationJSON, pub []byte) []byte {
+	var out []byte
+	for _, field := range [][]byte{
+		[]byte(revocationDomain), []byte(r.Algorithm), pub, []byte(r.RevokedAt), []byte(r.Reason),
+	} {
+		out = binary.BigEndian.AppendUint32(out, uint32(len(field)))
+		out = append(out, field...)
+	}
+	return out
+}
+
+// checkRevocation checks the form and self-signature of a revocation
+// statement and returns the revoked public key.
+func checkRevocation(r revocationJSON) (falcongo.PublicKey, error) {
+	var pk falcongo.PublicKey
+	if r.Version != revocationVersion || r.Algorithm != revocationAlgorithm {
+		return pk, fmt.Errorf("unsupported revocation version %d / algorithm %q", r.Version, r.Algorithm)
+	}
+	if _, err := time.Parse(time.RFC3339, r.RevokedAt); err != nil {
+		return pk, fmt.Errorf("invalid revoked_at: %w", err)
+	}
+	pub, err := parseHex(r.PublicKey)
+	if err != nil {
+		return pk, fmt.Errorf("invalid public_key hex: %w", err)
+	}
+	if len(pub) != len(pk) {
+		return pk, fmt.Errorf("invalid public_key length: %d", len(pub))
+	}
+	copy(pk[:], pub)
+	if err := r.Signature.Verify(revocationSigningBytes(r, pub), pk); err != nil {
+		return pk, fmt.Errorf("invalid revocation signature")
+	}
+	return pk, nil
+}
+
+// parseRevocations decodes a revocation statement or a JSON array of them.
+func parseRevocations(b []byte) ([]revocationJSON, error) {
+	var list []revocationJSON
+	if strings.HasPrefix(strings.TrimSpace(string(b)), "[") {
+		if err := json.Unmarshal(b, &list); err != nil {
+			return nil, fmt.Errorf("invalid revocation list JSON: %w", err)
+		}
+		return list, nil
+	}
+	var r revocationJSON
+	if err := json.Unmarshal(b, &r); err != nil {
+		return nil, fmt.Errorf("invalid revocation JSON: %w", err)
+	}
+	return append(list, r), nil
+}
+
+// loadRevocations reads the revocation statements of source: a directory of
+// *.json files, a file, or an http(s) URL, each holding a statement or an
+// array of them. Statements that do not verify are reported on stderr and
+// skipped. The valid ones are returned by public key.
+func loadRevocations(source string) (map[falcongo.PublicKey]revocationJSON, error) {
+	var docs [][]byte
+	var names []string
+	switch {
+	case strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://"):
+		client := http.Client{Timeout: revocationFetchTimeout}
+		resp, err := client.Get(source)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%s: %s", source, resp.Status)
+		}
+		b, err := io.ReadAll(io.LimitReader(resp.Body, maxRevocationList+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(b) > maxRevocationList {
+			return nil, fmt.Errorf("%s: revocation list larger than %d bytes", source, maxRevocationList)
+		}
+		docs, names = append(docs, b), append(names, source)
+	default:
+		info, err := os.Stat(source)
+		if err != nil {
+			return nil, err
+		}
+		paths := []string{source}
+		if info.IsDir() {
+			if paths, err = filepath.Glob(filepath.Join(source, "*.json")); err != nil {
+				return nil, err
+			}
+		}
+		for _, path := range paths {
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			docs, names = append(docs, b), append(names, path)
+		}
+	}
+
+	revoked := map[falcongo.PublicKey]revocationJSON{}
+	for i, b := range docs {
+		list, err := parseRevocations(b)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: skipping %s: %v\n", names[i], err)
+			continue
+		}
+		for _, r := range list {
+			pk, err := checkRevocation(r)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: skipping a revocation in %s: %v\n", names[i], err)
+				continue
+			}
+			// Keep the earliest time a key was declared compromised.
+			if prev, ok := revoked[pk]; !ok || r.RevokedAt < prev.RevokedAt {
+				revoked[pk] = r
+			}
+		}
+	}
+	return revoked, nil
+}
+
+// revocationStorePath returns the revocation store, revoked.json in
+// accountConfigDir. Unlike key statistics it cannot be turned off, and no
+// environment variable can move it.
+func revocationStorePath() (string, error) {
+	dir, err := accountConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("no directory for the revocation store: %w", err)
+	}
+	return filepath.Join(dir, revocationStoreFileName), nil
+}
+
+// readRevocationStore reads the revocation statements stored at path; a
+// missing file has none.
+func readRevocationStore(path string) ([]revocationJSON, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []revocationJSON
+	if err := json.Unmarshal(b, &list); err != nil {
+		return nil, fmt.Errorf("invalid JSON in %s: %w", path, err)
+	}
+	return list, nil
+}
+
+// storeRevocation adds r to the revocation store, so signing commands refuse
+// its key from then on.
+func storeRevocation(r revocationJSON) error {
+	path, err := revocationStorePath()
+	if err != nil {
+		return err
+	}
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	list, err := readRevocationStore(path)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(append(list, r), "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'), 0o600)
+}
+
+// refuseRevokedKey reports on stderr, and returns true, if the key pub was
+// revoked on this machine with falcon revoke, or if the revocation store
+// cannot be found or read. Stored statements are matched by public key alone: they
+// were checked when falcon revoke wrote them, and a doubtful one refuses the
+// key rather than letting it sign.
+func refuseRevokedKey(pub []byte) bool {
+	path, err := revocationStorePath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "refusing to sign: %v\n", err)
+		return true
+	}
+	list, err := readRevocationStore(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "refusing to sign: cannot read the revocation store: %v\n", err)
+		return true
+	}
+	pubHex := hex.EncodeToString(pub)
+	for _, r := range list {
+		if strings.EqualFold(r.PublicKey, pubHex) {
+			fmt.Fprintf(os.Stderr, "refusing to sign: the key was revoked as of %s (%s)\n", r.RevokedAt, r.Reason)
+			return true
+		}
+	}
+	return false
+}
+
+// ---- revoke ----
+func runRevoke(args []string) int {
+	fs := flag.NewFlagSet("revoke", flag.ExitOnError)
+	keyPath := fs.String("key", "", "path to keypair JSON file of the compromised key")
+	reason := fs.String("reason", "", "why the key is revoked, e.g. \"laptop stolen\"")
+	at := fs.String("at", "", "time the key is compromised as of, RFC 3339 (default: now)")
+	out := fs.String("out", "", "write the revocation statement to file (stdout if empty)")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+	})
+
+	if *keyPath == "" {
+		fmt.Fprintf(os.Stderr, "--key is required\n")
+		return 2
+	}
+	if strings.TrimSpace(*reason) == "" {
+		fmt.Fprintf(os.Stderr, "--reason is required\n")
+		return 2
+	}
+	revokedAt := time.Now().UTC()
+	if *at != "" {
+		t, err := time.Parse(time.RFC3339, *at)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --at: %v\n", err)
+			return 2
+		}
+		revokedAt = t.UTC()
+	}
+
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	pub, priv, _, err := loadKeypairFile(*keyPath, override)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
+		return 2
+	}
+	if pub == nil || priv == nil {
+		fmt.Fprintf(os.Stderr, "public and private key required in %s\n", *keyPath)
+		return 2
+	}
+
+	r := revocationJSON{
+		Version:   revocationVersion,
+		Algorithm: revocationAlgorithm,
+		PublicKey: strings.ToLower(hex.EncodeToString(pub)),
+		RevokedAt: revokedAt.Format(time.RFC3339),
+		Reason:    strings.TrimSpace(*reason),
+	}
+	var kp falcongo.KeyPair
+	copy(kp.PrivateKey[:], priv)
+	sig, err := kp.Sign(revocationSigningBytes(r, pub))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "signing failed: %v\n", err)
+		return 2
+	}
+	if r.Signature, err = falcongo.NewSignature(sig); err != nil {
+		fmt.Fprintf(os.Stderr, "signing failed: %v\n", err)
+		return 2
+	}
+
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode revocation JSON: %v\n", err)
+		return 2
+	}
+	if *out == "" {
+		if _, err := os.Stdout.Write(append(data, '\n')); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write revocation JSON: %v\n", err)
+			return 2
+		}
+	} else if err := writeFileAtomic(*out, append(data, '\n'), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *out, err)
+		return 2
+	}
+	if err := storeRevocation(r); err != nil {
+		fmt.Fprintf(os.Stderr, "the statement was written, but the key could not be marked revoked "+
+			"on this machine: %v\n", err)
+		return 2
+	}
+	recordKeyRevoked(pub, *keyPath, r.RevokedAt, r.Reason)
+	return 0
+}
+
+const helpRevoke = `# falcon revoke
+
+Declare a key compromised with a revocation statement self-signed by the key.
+
+The statement says "this key is compromised as of time T" and carries a
+reason. Publish it in a directory or at a URL that verifiers pass to
+'falcon verify --revocations', which then reports REVOKED for any signature of
+the key. The key is also recorded as revoked on this machine, in
+falcon/revoked.json in the default user configuration directory of the home
+directory the system records for the user (whatever $HOME or $XDG_CONFIG_HOME
+say), and the signing commands refuse to use it from then on, even with key
+statistics turned off. If that directory cannot be found, they refuse every
+key.
+
+Usage:
+  falcon revoke --key <file> --reason <text> [--at <time>] [--out <file>] [--mnemonic-passphrase <string>]
+
+Arguments:
+  --key <file>              keypair JSON of the compromised key (required, must include private key)
+  --reason <text>           why the key is revoked (required)
+  --at <time>               RFC 3339 time the key is compromised as of (default: now)
+  --out <file>              write the statement JSON (stdout if omitted)
+  --mnemonic-passphrase     optional mnemonic passphrase when the key file omits it
+
+Examples:
+  falcon revoke --key mykeys.json --reason "laptop stolen" --out revocations/mykey.json
+  falcon revoke --key mykeys.json --reason "leaked in CI logs" --at 2026-03-01T00:00:00Z
+`
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestRunRevoke checks the statement verifies, that verify reports the
+// revoked key from a directory or a URL, and that signing is refused.
+func TestRunRevoke(t *testing.T) {
+	dir := t.TempDir()
+	useTempUserConfigDir(t)
+	t.Setenv(envKeyStats, filepath.Join(dir, keyStatsFileName))
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("revoke test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+	pubPath := writeKeypairJSON(t, dir, "pub.json", kp, false)
+
+	var code int
+	sig := captureStdout(t, func() { code = runSign([]string{"--key", keyPath, "--msg", "hello"}) })
+	if code != 0 {
+		t.Fatalf("sign failed: %d", code)
+	}
+	sig = strings.TrimSpace(sig)
+
+	revDir := filepath.Join(dir, "revocations")
+	if err := os.Mkdir(revDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	revPath := filepath.Join(revDir, "key.json")
+	if code := runRevoke([]string{"--key", keyPath, "--reason", "laptop stolen",
+		"--at", "2026-03-01T01:00:00+01:00", "--out", revPath}); code != 0 {
+		t.Fatalf("revoke failed: %d", code)
+	}
+	var statement revocationJSON
+	readJSONFile(t, revPath, &statement)
+	if statement.RevokedAt != "2026-03-01T00:00:00Z" || statement.Reason != "laptop stolen" {
+		t.Fatalf("unexpected statement: %+v", statement)
+	}
+	if pk, err := checkRevocation(statement); err != nil || pk != kp.PublicKey {
+		t.Fatalf("checkRevocation: %v", err)
+	}
+	forged := statement
+	forged.RevokedAt = "2025-01-01T00:00:00Z"
+	if _, err := checkRevocation(forged); err == nil {
+		t.Fatalf("checkRevocation accepted a modified statement")
+	}
+
+	list, _ := json.Marshal([]revocationJSON{forged, statement})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(list)
+	}))
+	defer srv.Close()
+	for _, source := range []string{revDir, srv.URL} {
+		var code int
+		out, stderr := captureStdoutStderr(t, func() {
+			code = runVerify([]string{"--key", pubPath, "--msg", "hello", "--signature", sig, "--revocations", source})
+		})
+		if code != 1 || !strings.HasPrefix(out, "REVOKED\n") ||
+			!strings.Contains(out, "revoked: 2026-03-01T00:00:00Z (laptop stolen)") {
+			t.Fatalf("%s: got exit %d, stdout %q, stderr %q", source, code, out, stderr)
+		}
+	}
+
+	// An unrelated key is not affected.
+	other, err := falcongo.GenerateKeyPair(deriveSeed([]byte("other revoke test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	otherPath := writeKeypairJSON(t, dir, "other.json", other, true)
+	otherSig := captureStdout(t, func() { code = runSign([]string{"--key", otherPath, "--msg", "hello"}) })
+	out := captureStdout(t, func() {
+		code = runVerify([]string{"--key", otherPath, "--msg", "hello", "--signature", strings.TrimSpace(otherSig),
+			"--revocations", revDir})
+	})
+	if code != 0 || out != "VALID\n" {
+		t.Fatalf("unrelated key: got exit %d, stdout %q", code, out)
+	}
+
+	_, stderr := captureStdoutStderr(t, func() { code = runSign([]string{"--key", keyPath, "--msg", "again"}) })
+	if code != 2 || !strings.Contains(stderr, "refusing to sign: the key was revoked as of 2026-03-01T00:00:00Z") {
+		t.Fatalf("sign with a revoked key: got exit %d, stderr %q", code, stderr)
+	}
+
+	// The revocation store does not depend on key statistics.
+	t.Setenv(envKeyStats, keyStatsOff)
+	_, stderr = captureStdoutStderr(t, func() { code = runSign([]string{"--key", keyPath, "--msg", "again"}) })
+	if code != 2 || !strings.Contains(stderr, "the key was revoked") {
+		t.Fatalf("sign with statistics off: got exit %d, stderr %q", code, stderr)
+	}
+	// Another user configuration directory does not hide the store.
+	for _, name := range userConfigEnv {
+		t.Setenv(name, filepath.Join(dir, "elsewhere"))
+	}
+	_, stderr = captureStdoutStderr(t, func() { code = runSign([]string{"--key", keyPath, "--msg", "again"}) })
+	if code != 2 || !strings.Contains(stderr, "the key was revoked") {
+		t.Fatalf("sign with XDG_CONFIG_HOME moved: got exit %d, stderr %q", code, stderr)
+	}
+}
+
+// TestRefuseRevokedKey_UnreadableStore checks that signing is refused when the
+// revocation store cannot be found or read.
+func TestRefuseRevokedKey_UnreadableStore(t *testing.T) {
+	useTempUserConfigDir(t)
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("revocation store test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, t.TempDir(), "keys.json", kp, true)
+	var code int
+	if _, stderr := captureStdoutStderr(t, func() { code = runSign([]string{"--key", keyPath, "--msg", "hi"}) }); code != 0 {
+		t.Fatalf("sign without a store: got exit %d, stderr %q", code, stderr)
+	}
+
+	home, err := accountHomeDir()
+	if err != nil {
+		t.Fatalf("accountHomeDir: %v", err)
+	}
+	useAccountHomeDir(t, "", errors.New("no such user"))
+	_, stderr := captureStdoutStderr(t, func() { code = runSign([]string{"--key", keyPath, "--msg", "hi"}) })
+	if code != 2 || !strings.Contains(stderr, "no directory for the revocation store") {
+		t.Fatalf("sign without a home directory: got exit %d, stderr %q", code, stderr)
+	}
+	useAccountHomeDir(t, home, nil)
+
+	path, err := revocationStorePath()
+	if err != nil {
+		t.Fatalf("revocationStorePath: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	_, stderr = captureStdoutStderr(t, func() { code = runSign([]string{"--key", keyPath, "--msg", "hi"}) })
+	if code != 2 || !strings.Contains(stderr, "cannot read the revocation store") {
+		t.Fatalf("sign with a corrupt store: got exit %d, stderr %q", code, stderr)
+	}
+}
+
+func TestRunRevoke_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--reason", "x"}, "--key is required"},
+		{[]string{"--key", "k.json"}, "--reason is required"},
+		{[]string{"--key", "k.json", "--reason", "x", "--at", "yesterday"}, "invalid --at"},
+	} {
+		var code int
+		_, stderr := captureStdoutStderr(t, func() { code = runRevoke(tc.args) })
+		if code != 2 || !strings.Contains(stderr, tc.want) {
+			t.Fatalf("%q: got exit %d, stderr %q", tc.args, code, stderr)
+		}
+	}
+}
@@ -0,0 +1,401 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/mnemonic"
+	"github.com/algorandfoundation/falcon-signatures/totp"
+)
+
+// Second factors: keys enrolled with keys totp-enroll can sign on this machine
+// only after a TOTP code from the enrolled authenticator is given, on top of
+// the key file and its passphrase. Unlike key statistics, the enrollments
+// cannot be turned off or moved, and a file that cannot be found or read
+// refuses every key.
+const (
+	envTOTPCode          = "FALCON_TOTP_CODE"
+	secondFactorVersion  = 1
+	secondFactorFileName = "second-factor.json"
+	secondFactorTOTP     = "totp"
+	// totpSkew accepts the codes of one time step before and after the
+	// current one.
+	totpSkew = 1
+	// minTOTPSecret is the minimum secret size of RFC 4226 (128 bits).
+	minTOTPSecret = 16
+)
+
+// secondFactorJSON is the enrollment file.
+type secondFactorJSON struct {
+	Version int                            `json:"version"`
+	Keys    map[string]*totpEnrollmentJSON `json:"keys"` // by hex fingerprint
+}
+
+// totpEnrollmentJSON is the second factor of one key.
+type totpEnrollmentJSON struct {
+	Method   string `json:"method"` // "totp"
+	Secret   string `json:"secret"` // base32
+	Digits   int    `json:"digits"`
+	Period   int    `json:"period"`   // seconds
+	Enrolled string `json:"enrolled"` // RFC 3339
+	// LastStep is the time step of the last code accepted; a code is
+	// accepted only once.
+	LastStep uint64 `json:"last_step,omitempty"`
+}
+
+func (e *totpEnrollmentJSON) key() (totp.Key, error) {
+	secret, err := totp.DecodeSecret(e.Secret)
+	if err != nil {
+		return totp.Key{}, err
+	}
+	if e.Method != secondFactorTOTP || e.Digits < 6 || e.Digits > 8 || e.Period <= 0 {
+		return totp.Key{}, fmt.Errorf("unsupported second factor %q", e.Method)
+	}
+	return totp.Key{Secret: secret, Digits: e.Digits, Period: time.Duration(e.Period) * time.Second}, nil
+}
+
+// secondFactorPath returns the enrollment file, second-factor.json in
+// accountConfigDir, which no environment variable can move.
+func secondFactorPath() (string, error) {
+	dir, err := accountConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("no directory for second factor enrollments: %w", err)
+	}
+	return filepath.Join(dir, secondFactorFileName), nil
+}
+
+// readSecondFactors reads the enrollment file at path; a missing file has no
+// enrollments.
+func readSecondFactors(path string) (secondFactorJSON, error) {
+	sf := secondFactorJSON{Version: secondFactorVersion, Keys: map[string]*totpEnrollmentJSON{}}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return sf, nil
+	}
+	if err != nil {
+		return sf, err
+	}
+	if err := json.Unmarshal(b, &sf); err != nil {
+		return sf, fmt.Errorf("invalid JSON in %s: %w", path, err)
+	}
+	if sf.Version != secondFactorVersion {
+		return sf, fmt.Errorf("%s has version %d; this falcon supports %d", path, sf.Version, secondFactorVersion)
+	}
+	if sf.Keys == nil {
+		sf.Keys = map[string]*totpEnrollmentJSON{}
+	}
+	return sf, nil
+}
+
+func writeSecondFactors(path string, sf secondFactorJSON) error {
+	data, err := json.MarshalIndent(sf, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, append(data, '\n'), 0o600)
+}
+
+// approvedKeys holds the fingerprints approved by a code in this process, so
+// a key loaded twice by one command asks for one code.
+var approvedKeys struct {
+	sync.Mutex
+	fps map[string]bool
+}
+
+// readTOTPCode returns $FALCON_TOTP_CODE, else a code typed on stdin after a
+// prompt naming the key.
+func readTOTPCode(petname string) (string, error) {
+	if code := strings.TrimSpace(os.Getenv(envTOTPCode)); code != "" {
+		return code, nil
+	}
+	fmt.Fprintf(os.Stderr, "TOTP code for key %s: ", petname)
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		fmt.Fprintln(os.Stderr)
+		return "", fmt.Errorf("failed to read the code (set $%s when stdin is not a terminal): %w", envTOTPCode, err)
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// refuseWithoutSecondFactor reports, after printing why, whether pub is
+// enrolled for a second factor and no valid code was given for it.
+func refuseWithoutSecondFactor(pub []byte) bool {
+	var pk falcongo.PublicKey
+	if len(pub) != len(pk) {
+		return false
+	}
+	copy(pk[:], pub)
+	fp := falcongo.Fingerprint(pk)
+	id := hex.EncodeToString(fp[:])
+	approvedKeys.Lock()
+	defer approvedKeys.Unlock()
+	if approvedKeys.fps[id] {
+		return false
+	}
+	if err := approveSecondFactor(id, mnemonic.Petname(fp)); err != nil {
+		fmt.Fprintf(os.Stderr, "refusing to sign: %v\n", err)
+		return true
+	}
+	if approvedKeys.fps == nil {
+		approvedKeys.fps = map[string]bool{}
+	}
+	approvedKeys.fps[id] = true
+	return false
+}
+
+// approveSecondFactor checks a code for the key of fingerprint id, if it is
+// enrolled, and records its time step. The code is read before the file is
+// locked, so that a prompt does not hold up other falcon processes, and
+// checked against the file as re-read under the lock, so that concurrent
+// invocations cannot both use it.
+func approveSecondFactor(id, petname string) error {
+	path, err := secondFactorPath()
+	if err != nil {
+		// The key may be enrolled in the file that cannot be found.
+		return err
+	}
+	sf, err := readSecondFactors(path)
+	if err != nil {
+		return fmt.Errorf("cannot read second factor enrollments: %w", err)
+	}
+	if sf.Keys[id] == nil {
+		return nil
+	}
+	code, err := readTOTPCode(petname)
+	if err != nil {
+		return err
+	}
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if sf, err = readSecondFactors(path); err != nil {
+		return fmt.Errorf("cannot read second factor enrollments: %w", err)
+	}
+	e := sf.Keys[id]
+	if e == nil {
+		return fmt.Errorf("second factor of key %s was removed meanwhile", petname)
+	}
+	k, err := e.key()
+	if err != nil {
+		return fmt.Errorf("second factor of key %s: %w", petname, err)
+	}
+	step, err := k.Verify(code, time.Now(), totpSkew)
+	if err != nil {
+		return fmt.Errorf("key %s requires a second factor: %w", petname, err)
+	}
+	if step <= e.LastStep {
+		return fmt.Errorf("key %s requires a second factor: code already used; wait for the next one", petname)
+	}
+	e.LastStep = step
+	if err := writeSecondFactors(path, sf); err != nil {
+		return fmt.Errorf("cannot record the second factor code: %w", err)
+	}
+	return nil
+}
+
+// refuseUnapprovedKey reports, after printing why, whether signing with pub
+// must be refused: the key is revoked, or enrolled for a second factor whose
+// code was not given.
+func refuseUnapprovedKey(pub []byte) bool {
+	return refuseRevokedKey(pub) || refuseWithoutSecondFactor(pub)
+}
+
+// loadEnrollmentKey returns the hex fingerprint and petname of the public key
+// of --key.
+func loadEnrollmentKey(path string, override *string) (string, string, int) {
+	fp, ok, err := keyFileFingerprint(path, override)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
+		return "", "", 2
+	}
+	if !ok {
+		fmt.Fprintf(os.Stderr, "public key not found in %s\n", path)
+		return "", "", 2
+	}
+	return hex.EncodeToString(fp[:]), mnemonic.Petname(fp), 0
+}
+
+// ---- keys totp-enroll ----
+func runKeysTOTPEnroll(args []string) int {
+	fs := flag.NewFlagSet("keys totp-enroll", flag.ExitOnError)
+	keyPath := fs.String("key", "", "path to key JSON file (public key sufficient)")
+	secretFlag := fs.String("secret", "", "enroll this base32 secret (e.g. of a programmable hardware token) instead of a new one")
+	code := fs.String("code", "", "current code of the authenticator, to confirm without a prompt")
+	issuer := fs.String("issuer", "falcon", "issuer shown by the authenticator app")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+	})
+
+	if *keyPath == "" {
+		fmt.Fprintf(os.Stderr, "--key is required\n")
+		return 2
+	}
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	id, petname, rc := loadEnrollmentKey(*keyPath, override)
+	if rc != 0 {
+		return rc
+	}
+	path, err := secondFactorPath()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	sf, err := readSecondFactors(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read second factor enrollments: %v\n", err)
+		return 2
+	}
+	if sf.Keys[id] != nil {
+		fmt.Fprintf(os.Stderr, "key %s is already enrolled; remove it first with keys totp-remove\n", petname)
+		return 2
+	}
+
+	k := totp.Key{Digits: totp.DefaultDigits, Period: totp.DefaultPeriod}
+	if *secretFlag != "" {
+		if k.Secret, err = totp.DecodeSecret(*secretFlag); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --secret: %v\n", err)
+			return 2
+		}
+		if len(k.Secret) < minTOTPSecret {
+			fmt.Fprintf(os.Stderr, "invalid --secret: shorter than %d bytes\n", minTOTPSecret)
+			return 2
+		}
+	} else {
+		if k, err = totp.Generate(); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to generate secret: %v\n", err)
+			return 2
+		}
+		fmt.Fprintf(os.Stdout, "Add this key to an authenticator app (scan the URI as a QR code or type the secret):\n")
+		fmt.Fprintf(os.Stdout, "secret: %s\n", totp.EncodeSecret(k.Secret))
+		fmt.Fprintf(os.Stdout, "uri: %s\n", k.URI(*issuer, petname))
+	}
+
+	if *code == "" {
+		fmt.Fprint(os.Stderr, "type the code the authenticator shows to confirm: ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Fprintf(os.Stderr, "\nfailed to read confirmation: %v\n", err)
+			return 2
+		}
+		*code = line
+	}
+	step, err := k.Verify(*code, time.Now(), totpSkew)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v; the key is not enrolled\n", err)
+		return 1
+	}
+	unlock, err := lockFile(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	defer unlock()
+	if sf, err = readSecondFactors(path); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read second factor enrollments: %v\n", err)
+		return 2
+	}
+	if sf.Keys[id] != nil {
+		fmt.Fprintf(os.Stderr, "key %s was enrolled meanwhile; the new secret is not\n", petname)
+		return 2
+	}
+	sf.Keys[id] = &totpEnrollmentJSON{
+		Method:   secondFactorTOTP,
+		Secret:   totp.EncodeSecret(k.Secret),
+		Digits:   k.Digits,
+		Period:   int(k.Period / time.Second),
+		Enrolled: time.Now().UTC().Format(time.RFC3339),
+		LastStep: step,
+	}
+	if err := writeSecondFactors(path, sf); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", path, err)
+		return 2
+	}
+	fmt.Fprintf(os.Stdout, "key %s now requires a TOTP code to sign on this machine\n", petname)
+	return 0
+}
+
+// ---- keys totp-remove ----
+func runKeysTOTPRemove(args []string) int {
+	fs := flag.NewFlagSet("keys totp-remove", flag.ExitOnError)
+	keyPath := fs.String("key", "", "path to key JSON file (public key sufficient)")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+	})
+
+	if *keyPath == "" {
+		fmt.Fprintf(os.Stderr, "--key is required\n")
+		return 2
+	}
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	id, petname, rc := loadEnrollmentKey(*keyPath, override)
+	if rc != 0 {
+		return rc
+	}
+	path, err := secondFactorPath()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	sf, err := readSecondFactors(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read second factor enrollments: %v\n", err)
+		return 2
+	}
+	if sf.Keys[id] == nil {
+		fmt.Fprintf(os.Stderr, "key %s is not enrolled\n", petname)
+		return 2
+	}
+	// Removing the second factor takes a code, as signing does.
+	if err := approveSecondFactor(id, petname); err != nil {
+		fmt.Fprintf(os.Stderr, "%v; the second factor is kept\n", err)
+		return 1
+	}
+	unlock, err := lockFile(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	defer unlock()
+	if sf, err = readSecondFactors(path); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read second factor enrollments: %v\n", err)
+		return 2
+	}
+	delete(sf.Keys, id)
+	if err := writeSecondFactors(path, sf); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", path, err)
+		return 2
+	}
+	fmt.Fprintf(os.Stdout, "key %s no longer requires a TOTP code\n", petname)
+	return 0
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/totp"
+)
+
+// TestKeysTOTP enrolls a key, signs with and without a code, and removes the
+// second factor.
+func TestKeysTOTP(t *testing.T) {
+	dir := t.TempDir()
+	useTempUserConfigDir(t)
+	t.Setenv(envTOTPCode, "")
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("totp second factor test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+	k := totp.Key{Secret: []byte("12345678901234567890"), Digits: totp.DefaultDigits, Period: totp.DefaultPeriod}
+	secret := totp.EncodeSecret(k.Secret)
+	step := k.Counter(time.Now())
+	resetApprovals := func() {
+		approvedKeys.Lock()
+		approvedKeys.fps = nil
+		approvedKeys.Unlock()
+	}
+	sign := func() (int, string) {
+		t.Helper()
+		resetApprovals()
+		var code int
+		var stderr string
+		withStdin(t, "", func() {
+			_, stderr = captureStdoutStderr(t, func() {
+				code = runSign([]string{"--key", keyPath, "--msg", "hello"})
+			})
+		})
+		return code, stderr
+	}
+
+	var code int
+	captureStdoutStderr(t, func() {
+		code = runKeysTOTPEnroll([]string{"--key", keyPath, "--secret", secret, "--code", "000000x"})
+	})
+	if code != 1 {
+		t.Fatalf("enroll with a wrong code: exit %d", code)
+	}
+	out := captureStdout(t, func() {
+		code = runKeysTOTPEnroll([]string{"--key", keyPath, "--secret", secret, "--code", k.Code(step - 1)})
+	})
+	if code != 0 || !strings.Contains(out, "now requires a TOTP code") {
+		t.Fatalf("enroll: exit %d, %q", code, out)
+	}
+
+	if code, stderr := sign(); code != 2 || !strings.Contains(stderr, "refusing to sign") {
+		t.Fatalf("sign without a code: exit %d, %q", code, stderr)
+	}
+	// The variable that used to relocate the enrollments is ignored.
+	t.Setenv("FALCON_SECOND_FACTOR", filepath.Join(dir, "nonexistent.json"))
+	if code, stderr := sign(); code != 2 || !strings.Contains(stderr, "refusing to sign") {
+		t.Fatalf("sign with FALCON_SECOND_FACTOR set: exit %d, %q", code, stderr)
+	}
+	// Neither does another user configuration directory, and a home
+	// directory that cannot be found refuses the key.
+	for _, name := range userConfigEnv {
+		t.Setenv(name, filepath.Join(dir, "elsewhere"))
+	}
+	if code, stderr := sign(); code != 2 || !strings.Contains(stderr, "refusing to sign") {
+		t.Fatalf("sign with XDG_CONFIG_HOME moved: exit %d, %q", code, stderr)
+	}
+	home, err := accountHomeDir()
+	if err != nil {
+		t.Fatalf("accountHomeDir: %v", err)
+	}
+	useAccountHomeDir(t, "", errors.New("no such user"))
+	if code, stderr := sign(); code != 2 || !strings.Contains(stderr, "cannot find the home directory") {
+		t.Fatalf("sign without a home directory: exit %d, %q", code, stderr)
+	}
+	useAccountHomeDir(t, home, nil)
+	t.Setenv(envTOTPCode, k.Code(step))
+	if code, stderr := sign(); code != 0 {
+		t.Fatalf("sign with a code: exit %d, %q", code, stderr)
+	}
+	if code, stderr := sign(); code != 2 || !strings.Contains(stderr, "already used") {
+		t.Fatalf("sign with a used code: exit %d, %q", code, stderr)
+	}
+
+	t.Setenv(envTOTPCode, "")
+	withStdin(t, k.Code(step+1)+"\n", func() {
+		out, _ = captureStdoutStderr(t, func() { code = runKeysTOTPRemove([]string{"--key", keyPath}) })
+	})
+	if code != 0 || !strings.Contains(out, "no longer requires") {
+		t.Fatalf("remove: exit %d, %q", code, out)
+	}
+	if code, stderr := sign(); code != 0 {
+		t.Fatalf("sign after removal: exit %d, %q", code, stderr)
+	}
+}
@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// Send templates capture the recipient, amount, note pattern and network of a
+// recurring payment, so that 'algorand send --template <name>' need not be
+// retyped. They are JSON files named <name>.json in the template directory.
+const (
+	envTemplateDir = "FALCON_TEMPLATE_DIR"
+	templateExt    = ".json"
+)
+
+// sendTemplateJSON is a send template file.
+type sendTemplateJSON struct {
+	Name   string `json:"name"`
+	To     string `json:"to"`
+	Amount uint64 `json:"amount"` // microAlgos
+	// Note is expanded by expandNotePattern on every send.
+	Note    string `json:"note,omitempty"`
+	Network string `json:"network,omitempty"`
+}
+
+// templateNameRE matches the names of send templates, which are file names.
+var templateNameRE = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// notePlaceholderRE matches the placeholders of a note pattern.
+var notePlaceholderRE = regexp.MustCompile(`\{([a-z]+)\}`)
+
+// notePlaceholders are the placeholders of note patterns, formatted in UTC.
+var notePlaceholders = map[string]string{
+	"date":  "2006-01-02",
+	"month": "2006-01",
+	"year":  "2006",
+}
+
+// expandNotePattern replaces the {date}, {month} and {year} placeholders of
+// pattern with now in UTC. Other text, braces included, is kept.
+func expandNotePattern(pattern string, now time.Time) (string, error) {
+	var err error
+	note := notePlaceholderRE.ReplaceAllStringFunc(pattern, func(m string) string {
+		layout, ok := notePlaceholders[m[1:len(m)-1]]
+		if !ok {
+			err = fmt.Errorf("unknown placeholder %s in note pattern (use {date}, {month} or {year})", m)
+			return m
+		}
+		return now.UTC().Format(layout)
+	})
+	return note, err
+}
+
+// checkSendTemplate validates the fields of t.
+func checkSendTemplate(t sendTemplateJSON) error {
+	if !templateNameRE.MatchString(t.Name) {
+		return fmt.Errorf("invalid name %q (letters, digits, '.', '_' and '-')", t.Name)
+	}
+	if _, err := types.DecodeAddress(t.To); err != nil {
+		return fmt.Errorf("invalid to address: %w", err)
+	}
+	if t.Amount == 0 {
+		return errors.New("amount must be > 0")
+	}
+	if _, err := expandNotePattern(t.Note, time.Now()); err != nil {
+		return err
+	}
+	if t.Network != "" {
+		if _, err := parseAlgorandNetwork(t.Network); err != nil {
+			return fmt.Errorf("invalid network: %w", err)
+		}
+	}
+	return nil
+}
+
+// resolveTemplateDir returns --template-dir, falling back to
+// $FALCON_TEMPLATE_DIR and then to falcon/templates in the user configuration
+// directory.
+func resolveTemplateDir(flagValue string, flagSet bool) (string, error) {
+	if dir := flagOrEnv(flagValue, flagSet, envTemplateDir); dir != "" {
+		return dir, nil
+	}
+	cfg, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("no directory for send templates (set --template-dir): %w", err)
+	}
+	return filepath.Join(cfg, "falcon", "templates"), nil
+}
+
+// loadSendTemplate reads the template ref: a template file, or the name of a
+// template of dir.
+func loadSendTemplate(ref, dir string) (sendTemplateJSON, error) {
+	path := ref
+	if _, err := os.Stat(ref); err != nil {
+		if !templateNameRE.MatchString(strings.TrimSuffix(ref, templateExt)) {
+			return sendTemplateJSON{}, err
+		}
+		path = filepath.Join(dir, strings.TrimSuffix(ref, templateExt)+templateExt)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return sendTemplateJSON{}, err
+	}
+	var t sendTemplateJSON
+	if err := json.Unmarshal(b, &t); err != nil {
+		return sendTemplateJSON{}, fmt.Errorf("%s: invalid JSON: %w", path, err)
+	}
+	if err := checkSendTemplate(t); err != nil {
+		return sendTemplateJSON{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return t, nil
+}
+
+// ---- algorand template-create ----
+func runAlgorandTemplateCreate(args []string) int {
+	fs := flag.NewFlagSet("algorand template-create", flag.ExitOnError)
+	name := fs.String("name", "", "name of the template")
+	to := fs.String("to", "", "Algorand destination address")
+	amount := fs.Uint64("amount", 0, "amount to send in microAlgos")
+	note := fs.String("note", "", "note pattern; {date}, {month} and {year} are replaced when sending")
+	networkFlag := fs.String("network", "", "network: mainnet, testnet, betanet, devnet (default: that of send)")
+	templateDir := fs.String("template-dir", "", "directory of send templates (env "+envTemplateDir+")")
+	force := fs.Bool("force", false, "replace an existing template of the same name")
+	parseFlags(fs, args)
+	templateDirSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "template-dir" {
+			templateDirSet = true
+		}
+	})
+
+	t := sendTemplateJSON{
+		Name:    *name,
+		To:      strings.TrimSpace(*to),
+		Amount:  *amount,
+		Note:    *note,
+		Network: strings.ToLower(strings.TrimSpace(*networkFlag)),
+	}
+	if t.Name == "" {
+		fmt.Fprintf(os.Stderr, "--name is required\n")
+		return 2
+	}
+	if err := checkSendTemplate(t); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid template: %v\n", err)
+		return 2
+	}
+	dir, err := resolveTemplateDir(*templateDir, templateDirSet)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	path := filepath.Join(dir, t.Name+templateExt)
+	if _, err := os.Stat(path); err == nil && !*force {
+		fmt.Fprintf(os.Stderr, "template %s already exists in %s (use --force to replace it)\n", t.Name, dir)
+		return 2
+	}
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode template: %v\n", err)
+		return 2
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create %s: %v\n", dir, err)
+		return 2
+	}
+	if err := writeFileAtomic(path, append(data, '\n'), 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write template: %v\n", err)
+		return 2
+	}
+	fmt.Fprintf(os.Stdout, "Template %s written to %s\n", t.Name, path)
+	return 0
+}
+
+// ---- algorand template-list ----
+func runAlgorandTemplateList(args []string) int {
+	fs := flag.NewFlagSet("algorand template-list", flag.ExitOnError)
+	templateDir := fs.String("template-dir", "", "directory of send templates (env "+envTemplateDir+")")
+	jsonOut := fs.Bool("json", false, "print the templates as JSON")
+	parseFlags(fs, args)
+	templateDirSet := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "template-dir" {
+			templateDirSet = true
+		}
+	})
+
+	dir, err := resolveTemplateDir(*templateDir, templateDirSet)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+templateExt))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to list %s: %v\n", dir, err)
+		return 2
+	}
+	sort.Strings(paths)
+	templates := []sendTemplateJSON{}
+	for _, path := range paths {
+		t, err := loadSendTemplate(path, dir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: skipping %v\n", err)
+			continue
+		}
+		templates = append(templates, t)
+	}
+
+	if *jsonOut {
+		data, err := json.MarshalIndent(templates, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to encode templates: %v\n", err)
+			return 2
+		}
+		fmt.Fprintf(os.Stdout, "%s\n", data)
+		return 0
+	}
+	if len(templates) == 0 {
+		fmt.Fprintf(os.Stdout, "No send templates in %s\n", dir)
+		return 0
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tNETWORK\tTO\tAMOUNT\tNOTE")
+	for _, t := range templates {
+		network := t.Network
+		if network == "" {
+			network = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", t.Name, network, t.To, t.Amount, t.Note)
+	}
+	w.Flush()
+	return 0
+}
@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+func TestExpandNotePattern(t *testing.T) {
+	now := time.Date(2026, 3, 31, 23, 30, 0, 0, time.FixedZone("", -3600))
+	got, err := expandNotePattern("rent {month} ({date}, {year}) {not a placeholder}", now)
+	if err != nil || got != "rent 2026-04 (2026-04-01, 2026) {not a placeholder}" {
+		t.Fatalf("got %q, %v", got, err)
+	}
+	if _, err := expandNotePattern("rent {week}", now); err == nil {
+		t.Fatal("unknown placeholder accepted")
+	}
+}
+
+// TestRunAlgorandTemplate creates and lists templates, then sends with one,
+// stopped by a pre-hook that records the payment.
+func TestRunAlgorandTemplate(t *testing.T) {
+	t.Setenv(envTemplateDir, "")
+	t.Setenv(envPreHook, "")
+	t.Setenv(envPostHook, "")
+	dir := t.TempDir()
+	templates := filepath.Join(dir, "templates")
+	var to types.Address
+	to[0] = 1
+
+	create := []string{"--name", "monthly-rent", "--to", to.String(), "--amount", "1500000",
+		"--note", "rent {month}", "--network", "TestNet", "--template-dir", templates}
+	var code int
+	out := captureStdout(t, func() { code = runAlgorandTemplateCreate(create) })
+	if code != 0 || !strings.Contains(out, "monthly-rent.json") {
+		t.Fatalf("create: exit %d, %q", code, out)
+	}
+	stderr := captureStderr(t, func() { code = runAlgorandTemplateCreate(create) })
+	if code != 2 || !strings.Contains(stderr, "--force") {
+		t.Fatalf("create again: exit %d, %q", code, stderr)
+	}
+	captureStdout(t, func() { code = runAlgorandTemplateCreate(append(create, "--force")) })
+	if code != 0 {
+		t.Fatalf("create --force: exit %d", code)
+	}
+	for name, args := range map[string][]string{
+		"no name":     {"--to", to.String(), "--amount", "1"},
+		"bad name":    {"--name", "../rent", "--to", to.String(), "--amount", "1"},
+		"bad address": {"--name", "x", "--to", "NOPE", "--amount", "1"},
+		"no amount":   {"--name", "x", "--to", to.String()},
+		"bad note":    {"--name", "x", "--to", to.String(), "--amount", "1", "--note", "{week}"},
+		"bad network": {"--name", "x", "--to", to.String(), "--amount", "1", "--network", "moon"},
+	} {
+		stderr := captureStderr(t, func() { code = runAlgorandTemplateCreate(append(args, "--template-dir", templates)) })
+		if code != 2 || stderr == "" {
+			t.Fatalf("%s: exit %d, %q", name, code, stderr)
+		}
+	}
+
+	out = captureStdout(t, func() { code = runAlgorandTemplateList([]string{"--template-dir", templates}) })
+	if code != 0 || !strings.Contains(out, "monthly-rent  testnet  "+to.String()+"  1500000  rent {month}") {
+		t.Fatalf("list: exit %d, %q", code, out)
+	}
+	out = captureStdout(t, func() {
+		code = runAlgorandTemplateList([]string{"--json", "--template-dir", templates})
+	})
+	var listed []sendTemplateJSON
+	if err := json.Unmarshal([]byte(out), &listed); code != 0 || err != nil || len(listed) != 1 ||
+		listed[0].Network != "testnet" || listed[0].Amount != 1500000 {
+		t.Fatalf("list --json: exit %d, %q", code, out)
+	}
+
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("send template test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+	eventPath := filepath.Join(dir, "event.json")
+	hook := writeHookScript(t, dir, "pre.sh", "cat > "+eventPath+"\nexit 1")
+	stderr = captureStderr(t, func() {
+		code = runAlgorandSend([]string{"--key", keyPath, "--template", "monthly-rent", "--amount", "7",
+			"--template-dir", templates, "--pre-hook", hook})
+	})
+	if code != 2 || !strings.Contains(stderr, "send aborted") {
+		t.Fatalf("send: exit %d, %q", code, stderr)
+	}
+	b, err := os.ReadFile(eventPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ev hookEvent
+	if err := json.Unmarshal(b, &ev); err != nil {
+		t.Fatal(err)
+	}
+	wantNote := "rent " + time.Now().UTC().Format("2006-01")
+	if ev.To != to.String() || ev.Amount != 7 || ev.Network != "testnet" || ev.Note != wantNote {
+		t.Fatalf("send with template: event %+v", ev)
+	}
+
+	stderr = captureStderr(t, func() {
+		code = runAlgorandSend([]string{"--key", keyPath, "--template", "weekly", "--template-dir", templates})
+	})
+	if code != 2 || !strings.Contains(stderr, "--template") {
+		t.Fatalf("missing template: exit %d, %q", code, stderr)
+	}
+}
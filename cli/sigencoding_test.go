@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestSigEncoding signs in each encoding, to stdout and to a file, and
+// verifies the result with the detected and the explicit encoding.
+func TestSigEncoding(t *testing.T) {
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("sig encoding test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	dir := t.TempDir()
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+	raw, err := kp.Sign([]byte("hello"))
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	for _, enc := range []string{"hex", "base64", "base64url", "raw"} {
+		var code int
+		out := captureStdout(t, func() {
+			code = runSign([]string{"--key", keyPath, "--msg", "hello", "--sig-encoding", enc})
+		})
+		if code != 0 {
+			t.Fatalf("%s: sign exit %d", enc, code)
+		}
+		if enc == "base64" && out != base64.StdEncoding.EncodeToString(raw)+"\n" {
+			t.Fatalf("base64: got %q", out)
+		}
+		if enc == "base64url" && strings.ContainsAny(out, "+/=") {
+			t.Fatalf("base64url: got %q", out)
+		}
+		if enc == "raw" && out != string(raw) {
+			t.Fatalf("raw: got %d bytes, want the %d-byte signature", len(out), len(raw))
+		}
+
+		sigPath := filepath.Join(dir, enc+".sig")
+		if code = runSign([]string{"--key", keyPath, "--msg", "hello", "--sig-encoding", enc, "--out", sigPath}); code != 0 {
+			t.Fatalf("%s: sign --out exit %d", enc, code)
+		}
+		b, err := os.ReadFile(sigPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if enc != "raw" && string(b) != out {
+			t.Fatalf("%s: file %q differs from stdout %q", enc, b, out)
+		}
+		for _, verifyEnc := range []string{"auto", enc} {
+			out = captureStdout(t, func() {
+				code = runVerify([]string{"--key", keyPath, "--msg", "hello", "--sig", sigPath, "--sig-encoding", verifyEnc})
+			})
+			if code != 0 || !strings.Contains(out, "VALID") {
+				t.Fatalf("%s: verify --sig-encoding %s: exit %d, %q", enc, verifyEnc, code, out)
+			}
+		}
+		if enc != "raw" {
+			out = captureStdout(t, func() {
+				code = runVerify([]string{"--key", keyPath, "--msg", "hello", "--signature", strings.TrimSpace(string(b))})
+			})
+			if code != 0 {
+				t.Fatalf("%s: verify --signature exit %d, %q", enc, code, out)
+			}
+		}
+	}
+
+	for name, args := range map[string][]string{
+		"sign auto":           {"sign", "--key", keyPath, "--msg", "hello", "--sig-encoding", "auto"},
+		"verify unknown":      {"verify", "--key", keyPath, "--msg", "hello", "--signature", "00", "--sig-encoding", "pem"},
+		"verify raw text":     {"verify", "--key", keyPath, "--msg", "hello", "--signature", "00", "--sig-encoding", "raw"},
+		"verify wrong format": {"verify", "--key", keyPath, "--msg", "hello", "--signature", "not*base64", "--sig-encoding", "base64url"},
+	} {
+		var code int
+		stderr := captureStderr(t, func() {
+			if args[0] == "sign" {
+				code = runSign(args[1:])
+			} else {
+				code = runVerify(args[1:])
+			}
+		})
+		if code != 2 || stderr == "" {
+			t.Fatalf("%s: exit %d, %q", name, code, stderr)
+		}
+	}
+}
@@ -1,13 +1,19 @@
 package cli
 
 import (
+	"crypto/rand"
 	"encoding/hex"
 	"flag"
 	"fmt"
+	iofs "io/fs"
 	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
 
 	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/hashing"
 )
 
 // ---- sign ----
@@ -17,21 +23,81 @@ func runSign(args []string) int {
 	inFile := fs.String("in", "", "file containing message (alternative to --msg)")
 	msg := fs.String("msg", "", "inline message text (alternative to --in)")
 	hexIn := fs.Bool("hex", false, "treat message as hex-encoded bytes")
-	out := fs.String("out", "", "write signature bytes to file (stdout hex if empty)")
+	jsonCanon := fs.Bool("json-canonicalize", false, "sign the RFC 8785 canonical form of a JSON message")
+	outPath := fs.String("out", "", "write signature bytes to file (stdout hex if empty)")
 	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
-	_ = fs.Parse(args)
+	preHook := fs.String("pre-hook", "", "program run before signing; non-zero exit aborts (env "+envPreHook+")")
+	postHook := fs.String("post-hook", "", "program run before the signature is output; non-zero exit aborts (env "+envPostHook+")")
+	commit := fs.Bool("commit", false, "sign a random 32-byte commitment with the message and prepend it to the signature")
+	inDir := fs.String("in-dir", "", "sign every file under this directory (requires --out-dir)")
+	outDir := fs.String("out-dir", "", "write <file>.sig for each --in-dir file here")
+	workers := fs.Int("workers", runtime.NumCPU(), "number of parallel workers with --in-dir")
+	attestEnv := fs.Bool("attest-env", false, "sign a statement of the signing environment with the message and prepend it")
+	attesterCmd := fs.String("attester", "", "with --attest-env: program producing a TEE quote (env "+envAttester+")")
+	hashName := fs.String("hash", string(hashing.Default), "with --attest-env: hash of the environment statement")
+	sigEncoding := fs.String("sig-encoding", "", "signature encoding: hex, base64, base64url or raw (default: hex on stdout, raw in files)")
+	parseFlags(fs, args)
 	passphraseProvided := false
+	preHookSet := false
+	postHookSet := false
+	attesterSet := false
+	hashSet := false
 	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "attester" {
+			attesterSet = true
+		}
+		if f.Name == "hash" {
+			hashSet = true
+		}
 		if f.Name == "mnemonic-passphrase" {
 			passphraseProvided = true
 		}
+		if f.Name == "pre-hook" {
+			preHookSet = true
+		}
+		if f.Name == "post-hook" {
+			postHookSet = true
+		}
 	})
 
 	if *keyPath == "" {
 		fmt.Fprintf(os.Stderr, "--key is required\n")
 		return 2
 	}
-	if (*inFile == "" && *msg == "") || (*inFile != "" && *msg != "") {
+	if *jsonCanon && *hexIn {
+		fmt.Fprintf(os.Stderr, "cannot combine --json-canonicalize with --hex\n")
+		return 2
+	}
+	if hashSet && !*attestEnv {
+		fmt.Fprintf(os.Stderr, "--hash requires --attest-env\n")
+		return 2
+	}
+	hash, err := hashing.ParseSelectable(*hashName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --hash: %v\n", err)
+		return 2
+	}
+	if *sigEncoding != "" {
+		if err := checkSigEncoding(*sigEncoding, false); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 2
+		}
+	}
+	batch := *inDir != "" || *outDir != ""
+	if batch {
+		if *inDir == "" || *outDir == "" {
+			fmt.Fprintf(os.Stderr, "--in-dir and --out-dir must be used together\n")
+			return 2
+		}
+		if *inFile != "" || *msg != "" || *outPath != "" {
+			fmt.Fprintf(os.Stderr, "cannot combine --in-dir with --in, --msg or --out\n")
+			return 2
+		}
+		if *workers < 1 {
+			fmt.Fprintf(os.Stderr, "--workers must be >= 1\n")
+			return 2
+		}
+	} else if (*inFile == "" && *msg == "") || (*inFile != "" && *msg != "") {
 		fmt.Fprintf(os.Stderr, "provide exactly one of --in or --msg\n")
 		return 2
 	}
@@ -41,19 +107,46 @@ func runSign(args []string) int {
 	if passphraseProvided {
 		override = mnemonicPassphrase
 	}
-	_, priv, _, err := loadKeypairFile(*keyPath, override)
+	kp, meta, code := loadSigningKey("--key", *keyPath, override, "signing", true)
+	if code != 0 {
+		return code
+	}
+	policy, err := compileMessagePolicy(meta.MessagePolicy)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
 		return 2
 	}
-	if priv == nil {
-		fmt.Fprintf(os.Stderr, "private key not found in %s (required for signing)\n", *keyPath)
+	pub := kp.PublicKey[:]
+
+	var attester environmentAttester
+	if *attestEnv {
+		if cmd := flagOrEnv(*attesterCmd, attesterSet, envAttester); cmd != "" {
+			attester = programAttester{command: cmd}
+		}
+	} else if attesterSet {
+		fmt.Fprintf(os.Stderr, "--attester requires --attest-env\n")
 		return 2
 	}
-	// Construct keypair struct expected by Sign
-	var kp falcongo.KeyPair
-	copy(kp.PrivateKey[:], priv)
-	// Public key not needed for signing.
+
+	s := &messageSigner{
+		kp: kp,
+		event: hookEvent{
+			Operation: "sign",
+			KeyFile:   *keyPath,
+			PublicKey: strings.ToLower(hex.EncodeToString(pub)),
+		},
+		preHook:   flagOrEnv(*preHook, preHookSet, envPreHook),
+		postHook:  flagOrEnv(*postHook, postHookSet, envPostHook),
+		commit:    *commit,
+		attestEnv: *attestEnv,
+		attester:  attester,
+		hash:      hash,
+		pub:       pub,
+		policy:    policy,
+	}
+	if batch {
+		return signDir(s, *inDir, *outDir, *hexIn, *jsonCanon, *sigEncoding, *workers)
+	}
 
 	// Read message
 	var msgBytes []byte
@@ -84,25 +177,247 @@ func runSign(args []string) int {
 			msgBytes = []byte(*msg)
 		}
 	}
+	if *jsonCanon {
+		if msgBytes, err = falcongo.CanonicalizeJSON(msgBytes); err != nil {
+			fmt.Fprintf(os.Stderr, "cannot canonicalize message: %v\n", err)
+			return 2
+		}
+	}
 
-	sig, err := kp.Sign(msgBytes)
+	out, err := s.sign(msgBytes)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "signing failed: %v\n", err)
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 2
+	}
+
+	if *outPath == "" {
+		enc := *sigEncoding
+		if enc == "" {
+			enc = sigEncodingHex
+		}
+		data := encodeSignature(out, enc)
+		if enc != sigEncodingRaw {
+			data = append(data, '\n')
+		}
+		if _, err := os.Stdout.Write(data); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write signature: %v\n", err)
+			return 2
+		}
+	} else if err := writeFileAtomic(*outPath, signatureFileData(out, *sigEncoding), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write signature: %v\n", err)
 		return 2
 	}
+	recordKeyUse(pub, *keyPath, "sign", "", 1)
+	return 0
+}
 
-	if *out == "" {
-		fmt.Println(strings.ToLower(hex.EncodeToString([]byte(sig))))
-		return 0
+// signatureFileData returns the contents of a signature file in enc: raw by
+// default, or the text encoding followed by a newline.
+func signatureFileData(out []byte, enc string) []byte {
+	if enc == "" || enc == sigEncodingRaw {
+		return out
 	}
+	return append(encodeSignature(out, enc), '\n')
+}
 
-	if err := writeFileAtomic(*out, []byte(sig), 0o644); err != nil {
-		fmt.Fprintf(os.Stderr, "failed to write signature: %v\n", err)
+// signResult is the outcome of signing one file of a --in-dir batch.
+type signResult struct {
+	rel string
+	err error
+}
+
+// signDir signs every regular file under inDir with s and writes the output
+// to outDir/<relative path>.sig in sigEncoding, using workers goroutines.
+// With jsonCanon, each file is a JSON document whose canonical form is
+// signed. Failures are reported per file; the exit code is 2 if any file
+// failed.
+func signDir(s *messageSigner, inDir, outDir string, hexIn, jsonCanon bool, sigEncoding string, workers int) int {
+	absOut, err := filepath.Abs(outDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --out-dir: %v\n", err)
+		return 2
+	}
+	var files []string
+	err = filepath.WalkDir(inDir, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			// Do not sign our own output when --out-dir is inside --in-dir.
+			if abs, err := filepath.Abs(path); err == nil && abs == absOut {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			rel, err := filepath.Rel(inDir, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --in-dir: %v\n", err)
+		return 2
+	}
+	if len(files) == 0 {
+		fmt.Fprintf(os.Stderr, "no files found in %s\n", inDir)
+		return 2
+	}
+
+	signFile := func(rel string) error {
+		b, err := os.ReadFile(filepath.Join(inDir, rel))
+		if err != nil {
+			return err
+		}
+		if hexIn {
+			if b, err = parseHex(strings.TrimSpace(string(b))); err != nil {
+				return fmt.Errorf("invalid hex: %w", err)
+			}
+		}
+		if jsonCanon {
+			if b, err = falcongo.CanonicalizeJSON(b); err != nil {
+				return fmt.Errorf("cannot canonicalize: %w", err)
+			}
+		}
+		out, err := s.sign(b)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(outDir, rel+".sig")
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			return err
+		}
+		return writeFileAtomic(dst, signatureFileData(out, sigEncoding), 0o644)
+	}
+
+	results := make([]signResult, len(files))
+	jobs := make(chan int)
+	bar := startProgress("signing", 0, uint64(len(files)))
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = signResult{rel: files[i], err: signFile(files[i])}
+				bar.add(1)
+			}
+		}()
+	}
+	for i := range files {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	bar.finish()
+
+	failed := 0
+	for _, r := range results {
+		if r.err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "%s: %v\n", r.rel, r.err)
+			continue
+		}
+		fmt.Fprintf(os.Stdout, "signed %s\n", r.rel)
+	}
+	recordKeyUse(s.pub, s.event.KeyFile, "sign", "", len(files)-failed)
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d of %d files failed\n", failed, len(files))
 		return 2
 	}
 	return 0
 }
 
+// messageSigner signs messages with one loaded key, running the hooks around
+// each signature. It is safe for concurrent use.
+type messageSigner struct {
+	kp       falcongo.KeyPair
+	event    hookEvent // template; Stage, Message, Commitment and Signature are set per call
+	preHook  string
+	postHook string
+	commit   bool
+	// attestEnv prepends a signed environment statement, with a quote from
+	// attester if it is not nil; pub is the public key the quote is bound to
+	// and hash the algorithm the statement records.
+	attestEnv bool
+	attester  environmentAttester
+	hash      hashing.Algorithm
+	pub       []byte
+	// policy is the message_policy of the key, checked before the hooks run.
+	policy *messagePolicy
+}
+
+// sign signs msg and returns the output container: the signature, preceded by
+// the commitment in commitment mode, preceded by the environment statement
+// with --attest-env.
+func (s *messageSigner) sign(msg []byte) ([]byte, error) {
+	if err := s.policy.check(msg); err != nil {
+		return nil, fmt.Errorf("refusing to sign: %w", err)
+	}
+	event := s.event
+	event.Message = strings.ToLower(hex.EncodeToString(msg))
+	var commitment []byte
+	if s.commit {
+		commitment = make([]byte, commitmentSize)
+		if _, err := rand.Read(commitment); err != nil {
+			return nil, fmt.Errorf("failed to read entropy: %w", err)
+		}
+		event.Commitment = hex.EncodeToString(commitment)
+	}
+	event.Stage = "pre"
+	if err := runHook(s.preHook, event); err != nil {
+		return nil, fmt.Errorf("signing aborted: %w", err)
+	}
+
+	payload := msg
+	if commitment != nil {
+		payload = committedMessage(commitment, msg)
+	}
+	var statement []byte
+	if s.attestEnv {
+		var err error
+		if statement, err = newEnvStatement(s.attester, s.hash, s.pub, msg); err != nil {
+			return nil, fmt.Errorf("signing aborted: %w", err)
+		}
+		payload = attestedMessage(s.hash, statement, payload)
+	}
+	sig, err := s.kp.Sign(payload)
+	if err != nil {
+		return nil, fmt.Errorf("signing failed: %w", err)
+	}
+
+	event.Stage = "post"
+	event.Signature = strings.ToLower(hex.EncodeToString(sig))
+	if err := runHook(s.postHook, event); err != nil {
+		return nil, fmt.Errorf("signing aborted: %w", err)
+	}
+	// In commitment mode the output container is commitment || signature.
+	out := append(commitment, sig...)
+	if statement != nil {
+		out = envContainer(statement, out)
+	}
+	return out, nil
+}
+
+// Commitment mode: the signed payload is commitmentDomain || commitment ||
+// message, so two signatures over the same message differ and verifiers can
+// track commitments to detect replays.
+const (
+	commitmentSize   = 32
+	commitmentDomain = "falcon-commit-v1"
+)
+
+// committedMessage returns the payload signed in commitment mode.
+func committedMessage(commitment, msg []byte) []byte {
+	out := make([]byte, 0, len(commitmentDomain)+len(commitment)+len(msg))
+	out = append(out, commitmentDomain...)
+	out = append(out, commitment...)
+	return append(out, msg...)
+}
+
 const helpSign = `# falcon sign
 
 Sign a message using a FALCON-1024 private key.
@@ -111,11 +426,43 @@ Arguments:
   --key <file>        keypair JSON file (mnemonic-only files supported)
   --in <file> | --msg <string>
   --hex               treat message as hex-encoded (utf-8 if omitted)
+  --json-canonicalize sign the RFC 8785 (JCS) canonical form of a JSON message,
+                       so any serialization of the same data verifies
+                       (verify with 'falcon verify --json-canonicalize')
   --out <file>        write signature bytes (stdout hex if omitted)
+  --sig-encoding <hex|base64|base64url|raw>
+                       encoding of the signature on stdout, in --out or in the
+                       --out-dir files (default: hex on stdout, raw in files);
+                       base64url is unpadded; verify detects the encoding
+  --in-dir <dir>      sign every file under dir with one key load (instead of --in/--msg)
+  --out-dir <dir>     with --in-dir: write <relative path>.sig here
+  --workers <n>       with --in-dir: parallel workers (default: number of CPUs)
+  --commit            sign a random 32-byte commitment together with the message;
+                       the output is the commitment followed by the signature
+                       (verify with 'falcon verify --commit')
+  --attest-env        sign a statement of the signing environment (tool version,
+                       hostname hash, OS/arch, optional TEE quote) together with the
+                       message; the output is the length-prefixed statement followed
+                       by the usual output (verify with 'falcon verify --require-attestation')
+  --attester <program>
+                       with --attest-env: program that reads {"report_data": "<hex>"}
+                       on stdin and writes a quote to stdout (default: $FALCON_ATTESTER)
+  --hash <name>        with --attest-env: hash of the statement digest and quote report
+                       data, recorded in the statement: sha512-256 (default), sha3-256
+                       or blake2b-256; verify uses the recorded one
   --mnemonic-passphrase <string>
                        mnemonic passphrase when the key file omits it
+  --pre-hook <program> run before signing with the operation as JSON on stdin;
+                       a non-zero exit aborts (default: $FALCON_PRE_HOOK)
+  --post-hook <program>
+                       run after signing, before the signature is output;
+                       a non-zero exit aborts (default: $FALCON_POST_HOOK)
 
 Examples:
   falcon sign --key mykeys.json --msg "hello world"
   falcon sign --key mykeys.json --in message.bin --hex --out payload.sig
+  falcon sign --key mykeys.json --msg "attest v1" --commit --out attest.sig
+  falcon sign --key mykeys.json --in payload.json --sig-encoding base64
+  falcon sign --key mykeys.json --in payload.json --json-canonicalize --out payload.sig
+  falcon sign --key mykeys.json --in-dir ./artifacts --out-dir ./sigs
 `
@@ -9,7 +9,6 @@ import (
 	"strings"
 	"testing"
 
-	falconlib "github.com/algorand/falcon"
 	"github.com/algorandfoundation/falcon-signatures/falcongo"
 	"github.com/algorandfoundation/falcon-signatures/mnemonic"
 )
@@ -90,7 +89,7 @@ func TestRunSign_MsgStdout_DeterministicAndVerifiable(t *testing.T) {
 	if err != nil {
 		t.Fatalf("stdout not valid hex: %v", err)
 	}
-	if err := falcongo.Verify([]byte(msg), falconlib.CompressedSignature(sigBytes), kp.PublicKey); err != nil {
+	if err := falcongo.Verify([]byte(msg), falcongo.CompressedSignature(sigBytes), kp.PublicKey); err != nil {
 		t.Fatalf("signature did not verify: %v", err)
 	}
 }
@@ -127,7 +126,7 @@ func TestRunSign_InHexToOutFile_Verifiable(t *testing.T) {
 	if err != nil {
 		t.Fatalf("parse message hex: %v", err)
 	}
-	if err := falcongo.Verify(msgBytes, falconlib.CompressedSignature(sigBytes), kp.PublicKey); err != nil {
+	if err := falcongo.Verify(msgBytes, falcongo.CompressedSignature(sigBytes), kp.PublicKey); err != nil {
 		t.Fatalf("signature from file did not verify: %v", err)
 	}
 }
@@ -382,7 +381,7 @@ func TestRunSign_FromMnemonicOnly(t *testing.T) {
 	if err != nil {
 		t.Fatalf("stdout not valid hex: %v", err)
 	}
-	if err := falcongo.Verify([]byte(msg), falconlib.CompressedSignature(sigBytes), kp.PublicKey); err != nil {
+	if err := falcongo.Verify([]byte(msg), falcongo.CompressedSignature(sigBytes), kp.PublicKey); err != nil {
 		t.Fatalf("signature did not verify with mnemonic-derived key: %v", err)
 	}
 }
@@ -424,7 +423,164 @@ func TestRunSign_MnemonicPassphraseRequired(t *testing.T) {
 	if err != nil {
 		t.Fatalf("stdout not valid hex: %v", err)
 	}
-	if err := falcongo.Verify([]byte(msg), falconlib.CompressedSignature(sigBytes), kp.PublicKey); err != nil {
+	if err := falcongo.Verify([]byte(msg), falcongo.CompressedSignature(sigBytes), kp.PublicKey); err != nil {
 		t.Fatalf("signature did not verify with passphrase: %v", err)
 	}
 }
+
+// TestRunSign_InDir_SignsEveryFile checks batch signing writes a verifiable
+// <file>.sig for every file, including nested ones.
+func TestRunSign_InDir_SignsEveryFile(t *testing.T) {
+	seed := deriveSeed([]byte("unit test seed for sign in-dir"))
+	kp, err := falcongo.GenerateKeyPair(seed)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	dir := t.TempDir()
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+
+	inDir := filepath.Join(dir, "artifacts")
+	files := map[string]string{
+		"a.txt":       "first artifact",
+		"b.bin":       "second artifact",
+		"nested/c.gz": "third artifact",
+	}
+	for rel, content := range files {
+		p := filepath.Join(inDir, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatalf("write artifact: %v", err)
+		}
+	}
+	outDir := filepath.Join(dir, "sigs")
+
+	var code int
+	stdout := captureStdout(t, func() {
+		code = runSign([]string{"--key", keyPath, "--in-dir", inDir, "--out-dir", outDir, "--workers", "2"})
+	})
+	if code != 0 {
+		t.Fatalf("expected exit 0, got %d", code)
+	}
+	for rel, content := range files {
+		if !strings.Contains(stdout, "signed "+rel) {
+			t.Fatalf("missing report for %s: %q", rel, stdout)
+		}
+		sig, err := os.ReadFile(filepath.Join(outDir, rel+".sig"))
+		if err != nil {
+			t.Fatalf("read signature for %s: %v", rel, err)
+		}
+		if err := falcongo.Verify([]byte(content), falcongo.CompressedSignature(sig), kp.PublicKey); err != nil {
+			t.Fatalf("signature for %s did not verify: %v", rel, err)
+		}
+	}
+}
+
+// TestRunSign_InDir_ReportsPerFileErrors ensures one bad file does not stop
+// the batch and yields exit 2.
+func TestRunSign_InDir_ReportsPerFileErrors(t *testing.T) {
+	seed := deriveSeed([]byte("unit test seed for sign in-dir errors"))
+	kp, err := falcongo.GenerateKeyPair(seed)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	dir := t.TempDir()
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+
+	inDir := filepath.Join(dir, "in")
+	if err := os.MkdirAll(inDir, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(inDir, "good.hex"), []byte("deadbeef\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(inDir, "bad.hex"), []byte("zz"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	outDir := filepath.Join(dir, "out")
+
+	var code int
+	_, stderr := captureStdoutStderr(t, func() {
+		code = runSign([]string{"--key", keyPath, "--in-dir", inDir, "--out-dir", outDir, "--hex"})
+	})
+	if code != 2 {
+		t.Fatalf("expected exit 2, got %d", code)
+	}
+	if !strings.Contains(stderr, "bad.hex: invalid hex") || !strings.Contains(stderr, "1 of 2 files failed") {
+		t.Fatalf("unexpected stderr: %q", stderr)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "good.hex.sig")); err != nil {
+		t.Fatalf("expected good.hex.sig to be written: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "bad.hex.sig")); !os.IsNotExist(err) {
+		t.Fatalf("expected no bad.hex.sig, got err=%v", err)
+	}
+}
+
+// TestRunSign_InDir_FlagConflicts_Returns2 checks batch flag validation.
+func TestRunSign_InDir_FlagConflicts_Returns2(t *testing.T) {
+	cases := [][]string{
+		{"--key", "dummy", "--in-dir", "x"},
+		{"--key", "dummy", "--in-dir", "x", "--out-dir", "y", "--msg", "m"},
+		{"--key", "dummy", "--in-dir", "x", "--out-dir", "y", "--workers", "0"},
+	}
+	for _, args := range cases {
+		var code int
+		captureStderr(t, func() { code = runSign(args) })
+		if code != 2 {
+			t.Fatalf("%v: expected exit 2, got %d", args, code)
+		}
+	}
+}
+
+// TestRunSign_JSONCanonicalize checks that a JSON signature verifies against a
+// reformatted document.
+func TestRunSign_JSONCanonicalize(t *testing.T) {
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("sign json")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	dir := t.TempDir()
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+	docPath := filepath.Join(dir, "doc.json")
+	if err := os.WriteFile(docPath, []byte("{\n  \"to\": \"alice\",\n  \"amount\": 1.50\n}\n"), 0o644); err != nil {
+		t.Fatalf("write doc: %v", err)
+	}
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runSign([]string{"--key", keyPath, "--in", docPath, "--json-canonicalize"})
+	})
+	if code != 0 {
+		t.Fatalf("sign: expected exit 0, got %d", code)
+	}
+	sig := strings.TrimSpace(out)
+	sigBytes, _ := hex.DecodeString(sig)
+	if err := falcongo.Verify([]byte(`{"amount":1.5,"to":"alice"}`), sigBytes, kp.PublicKey); err != nil {
+		t.Fatalf("signature is not over the canonical form: %v", err)
+	}
+
+	for msg, want := range map[string]string{
+		`{"amount":15e-1, "to":"alice"}`: "VALID",
+		`{"amount":1.5,"to":"bob"}`:      "INVALID",
+	} {
+		out := captureStdout(t, func() {
+			runVerify([]string{"--key", keyPath, "--msg", msg, "--signature", sig, "--json-canonicalize"})
+		})
+		if strings.TrimSpace(out) != want {
+			t.Fatalf("verify %s: got %q, want %s", msg, out, want)
+		}
+	}
+
+	for _, args := range [][]string{
+		{"--key", keyPath, "--msg", `{"a":1,"a":2}`, "--json-canonicalize"},
+		{"--key", keyPath, "--msg", "00", "--hex", "--json-canonicalize"},
+	} {
+		var code int
+		captureStdoutStderr(t, func() { code = runSign(args) })
+		if code != 2 {
+			t.Fatalf("runSign(%q): expected exit 2, got %d", args, code)
+		}
+	}
+}
@@ -0,0 +1,916 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"mime"
+	"os"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/hashing"
+	"github.com/algorandfoundation/falcon-signatures/mnemonic"
+)
+
+// signRequestJSON is a signing request: a self-describing document stating
+// what a key is asked to sign, so that a person can review it before it is
+// signed instead of approving an opaque hash.
+type signRequestJSON struct {
+	Version int    `json:"version"`
+	Kind    string `json:"kind"`
+	// Hash is the algorithm of the message digest and of the request digest.
+	Hash        string                   `json:"hash"`
+	PublicKey   string                   `json:"public_key"`
+	Description string                   `json:"description,omitempty"`
+	Requester   string                   `json:"requester,omitempty"`
+	Created     string                   `json:"created"`
+	Expires     string                   `json:"expires,omitempty"`
+	Message     *signRequestMessageJSON  `json:"message,omitempty"`
+	Transaction *signRequestTxnJSON      `json:"transaction,omitempty"`
+	Approval    *signRequestApprovalJSON `json:"approval,omitempty"`
+}
+
+// signRequestMessageJSON is the message of a "message" request.
+type signRequestMessageJSON struct {
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+	Digest      string `json:"digest"` // hex, under the hash of the request
+	Data        string `json:"data"`   // base64
+}
+
+// signRequestTxnJSON is the transaction of an "algorand-transaction"
+// request, with its fields decoded for review.
+type signRequestTxnJSON struct {
+	TxID    string        `json:"txid"`
+	Fields  []reviewField `json:"fields"`
+	Msgpack string        `json:"msgpack"` // base64
+}
+
+// reviewField is one decoded transaction field. Warning, if set, says why
+// the field deserves attention.
+type reviewField struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Warning string `json:"warning,omitempty"`
+}
+
+// signRequestApprovalJSON records the approval of a request. Signature, if
+// set, is made by the approver key over the approval domain and Digest.
+type signRequestApprovalJSON struct {
+	Digest            string              `json:"digest"`
+	ApprovedAt        string              `json:"approved_at"`
+	Approver          string              `json:"approver,omitempty"`
+	ApproverPublicKey string              `json:"approver_public_key,omitempty"`
+	Signature         *falcongo.Signature `json:"signature,omitempty"`
+}
+
+const (
+	signRequestVersion      = 1
+	signRequestKindMessage  = "message"
+	signRequestKindTxn      = "algorand-transaction"
+	signRequestApprovalTag  = "falcon-sign-request-approval-v1"
+	signRequestPreviewBytes = 256
+)
+
+// signRequestDigest returns the hex digest, under the hash req records, of
+// the RFC 8785 canonical form of req without its approval: the value an
+// approval commits to.
+func signRequestDigest(req signRequestJSON) (string, error) {
+	hash, err := hashing.Parse(req.Hash)
+	if err != nil {
+		return "", err
+	}
+	req.Approval = nil
+	b, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	if b, err = falcongo.CanonicalizeJSON(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(b)), nil
+}
+
+// signRequestApprovalBytes returns the bytes an approver key signs: the
+// domain tag, the hash name, a zero byte and the hex digest.
+func signRequestApprovalBytes(hash, digest string) []byte {
+	out := append([]byte(signRequestApprovalTag), hash...)
+	out = append(out, 0)
+	return append(out, digest...)
+}
+
+// signRequestPayload is the checked content of a request.
+type signRequestPayload struct {
+	pub     falcongo.PublicKey
+	hash    hashing.Algorithm
+	message []byte
+	txn     types.Transaction
+	expires time.Time // zero if the request does not expire
+}
+
+// readSignRequest reads the request at path and checks that it is
+// consistent: it records a known hash, the digest and size of a message
+// match its data, and the decoded fields of a transaction match its msgpack.
+func readSignRequest(path string) (signRequestJSON, signRequestPayload, error) {
+	var req signRequestJSON
+	var p signRequestPayload
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return req, p, err
+	}
+	if err := json.Unmarshal(b, &req); err != nil {
+		return req, p, fmt.Errorf("invalid signing request JSON: %w", err)
+	}
+	if req.Version != signRequestVersion {
+		return req, p, fmt.Errorf("unsupported signing request version %d", req.Version)
+	}
+	pub, err := parseHex(req.PublicKey)
+	if err != nil || len(pub) != falcongo.PublicKeySize {
+		return req, p, fmt.Errorf("invalid public_key")
+	}
+	copy(p.pub[:], pub)
+	if p.hash, err = hashing.Parse(req.Hash); err != nil {
+		return req, p, fmt.Errorf("invalid hash: %w", err)
+	}
+	if req.Expires != "" {
+		if p.expires, err = time.Parse(time.RFC3339, req.Expires); err != nil {
+			return req, p, fmt.Errorf("invalid expires: %w", err)
+		}
+	}
+	switch req.Kind {
+	case signRequestKindMessage:
+		m := req.Message
+		if m == nil || req.Transaction != nil {
+			return req, p, fmt.Errorf("a %s request must have a message and no transaction", req.Kind)
+		}
+		if p.message, err = base64.StdEncoding.DecodeString(m.Data); err != nil {
+			return req, p, fmt.Errorf("invalid message data: %w", err)
+		}
+		if m.Size != len(p.message) || !strings.EqualFold(m.Digest, hex.EncodeToString(p.hash.Sum(p.message))) {
+			return req, p, fmt.Errorf("message size or digest does not match its data")
+		}
+		if _, _, err := mime.ParseMediaType(m.ContentType); err != nil {
+			return req, p, fmt.Errorf("invalid message content_type: %w", err)
+		}
+	case signRequestKindTxn:
+		t := req.Transaction
+		if t == nil || req.Message != nil {
+			return req, p, fmt.Errorf("a %s request must have a transaction and no message", req.Kind)
+		}
+		raw, err := base64.StdEncoding.DecodeString(t.Msgpack)
+		if err != nil {
+			return req, p, fmt.Errorf("invalid transaction msgpack: %w", err)
+		}
+		if p.txn, err = algorand.DecodeTxn(raw); err != nil {
+			return req, p, err
+		}
+		if !bytes.Equal(msgpack.Encode(p.txn), raw) {
+			return req, p, fmt.Errorf("transaction msgpack is not canonical")
+		}
+		fields, err := describeTxn(p.txn, p.pub)
+		if err != nil {
+			return req, p, err
+		}
+		if t.TxID != crypto.GetTxID(p.txn) || !slices.Equal(t.Fields, fields) {
+			return req, p, fmt.Errorf("transaction txid or fields do not match its msgpack")
+		}
+	default:
+		return req, p, fmt.Errorf("unknown signing request kind %q", req.Kind)
+	}
+	return req, p, nil
+}
+
+// ---- sign-request dispatcher ----
+func runSignRequest(args []string) int {
+	return runSubcommand("sign-request", args)
+}
+
+// ---- sign-request create ----
+func runSignRequestCreate(args []string) int {
+	fs := flag.NewFlagSet("sign-request create", flag.ExitOnError)
+	keyPath := fs.String("key", "", "path to the key file of the signer (public key suffices)")
+	msg := fs.String("msg", "", "inline message text")
+	inFile := fs.String("in", "", "file containing the message")
+	txnPath := fs.String("txn", "", "unsigned Algorand transaction, msgpack or base64")
+	contentType := fs.String("content-type", "", "media type of the message (default: detected text or binary)")
+	description := fs.String("description", "", "what the request is for, shown to the reviewer")
+	requester := fs.String("requester", "", "who asks for the signature, shown to the reviewer")
+	expiresIn := fs.Duration("expires-in", 0, "refuse to approve or execute the request after this long")
+	hashName := fs.String("hash", string(hashing.Default), "hash of the message and of the request")
+	out := fs.String("out", "", "write the request JSON to file (stdout if empty)")
+	parseFlags(fs, args)
+
+	if *keyPath == "" {
+		fmt.Fprintf(os.Stderr, "--key is required\n")
+		return 2
+	}
+	sources := 0
+	for _, s := range []string{*msg, *inFile, *txnPath} {
+		if s != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		fmt.Fprintf(os.Stderr, "provide exactly one of --msg, --in or --txn\n")
+		return 2
+	}
+	if *txnPath != "" && *contentType != "" {
+		fmt.Fprintf(os.Stderr, "--content-type applies to messages, not to --txn\n")
+		return 2
+	}
+	if *expiresIn < 0 {
+		fmt.Fprintf(os.Stderr, "--expires-in must not be negative\n")
+		return 2
+	}
+	hash, err := hashing.ParseSelectable(*hashName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --hash: %v\n", err)
+		return 2
+	}
+
+	pub, _, _, err := loadKeypairFile(*keyPath, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
+		return 2
+	}
+	if pub == nil {
+		fmt.Fprintf(os.Stderr, "public key not found in %s\n", *keyPath)
+		return 2
+	}
+	var pk falcongo.PublicKey
+	copy(pk[:], pub)
+
+	now := time.Now().UTC()
+	req := signRequestJSON{
+		Version:     signRequestVersion,
+		Hash:        string(hash),
+		PublicKey:   strings.ToLower(hex.EncodeToString(pub)),
+		Description: *description,
+		Requester:   *requester,
+		Created:     now.Format(time.RFC3339),
+	}
+	if *expiresIn > 0 {
+		req.Expires = now.Add(*expiresIn).Format(time.RFC3339)
+	}
+	if *txnPath != "" {
+		b, err := os.ReadFile(*txnPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read --txn: %v\n", err)
+			return 2
+		}
+		txn, err := algorand.DecodeTxn(b)
+		if err != nil {
+			if decoded, derr := base64.StdEncoding.DecodeString(strings.TrimSpace(string(b))); derr == nil {
+				txn, err = algorand.DecodeTxn(decoded)
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --txn: %v\n", err)
+			return 2
+		}
+		fields, err := describeTxn(txn, pk)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 2
+		}
+		req.Kind = signRequestKindTxn
+		req.Transaction = &signRequestTxnJSON{
+			TxID:    crypto.GetTxID(txn),
+			Fields:  fields,
+			Msgpack: base64.StdEncoding.EncodeToString(msgpack.Encode(txn)),
+		}
+	} else {
+		data := []byte(*msg)
+		if *inFile != "" {
+			if data, err = os.ReadFile(*inFile); err != nil {
+				fmt.Fprintf(os.Stderr, "failed to read --in: %v\n", err)
+				return 2
+			}
+		}
+		ct := *contentType
+		if ct == "" {
+			ct = "application/octet-stream"
+			if utf8.Valid(data) {
+				ct = "text/plain; charset=utf-8"
+			}
+		}
+		mediaType, _, err := mime.ParseMediaType(ct)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --content-type: %v\n", err)
+			return 2
+		}
+		if isJSONMediaType(mediaType) && !json.Valid(data) {
+			fmt.Fprintf(os.Stderr, "the message is not valid JSON, yet --content-type is %s\n", mediaType)
+			return 2
+		}
+		req.Kind = signRequestKindMessage
+		req.Message = &signRequestMessageJSON{
+			ContentType: ct,
+			Size:        len(data),
+			Digest:      hex.EncodeToString(hash.Sum(data)),
+			Data:        base64.StdEncoding.EncodeToString(data),
+		}
+	}
+
+	if code := writeSignRequest(req, *out); code != 0 {
+		return code
+	}
+	digest, err := signRequestDigest(req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to hash the request: %v\n", err)
+		return 2
+	}
+	fmt.Fprintf(os.Stderr, "review code: %s\n", digest[:8])
+	return 0
+}
+
+// writeSignRequest writes req as indented JSON to path, or to stdout if path
+// is empty.
+func writeSignRequest(req signRequestJSON, path string) int {
+	data, err := json.MarshalIndent(req, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode the request JSON: %v\n", err)
+		return 2
+	}
+	data = append(data, '\n')
+	if path == "" {
+		if _, err := os.Stdout.Write(data); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write the request JSON: %v\n", err)
+			return 2
+		}
+	} else if err := writeFileAtomic(path, data, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", path, err)
+		return 2
+	}
+	return 0
+}
+
+// isJSONMediaType reports whether mediaType is application/json or a
+// +json structured syntax type.
+func isJSONMediaType(mediaType string) bool {
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// ---- sign-request approve ----
+func runSignRequestApprove(args []string) int {
+	fs := flag.NewFlagSet("sign-request approve", flag.ExitOnError)
+	inFile := fs.String("in", "", "path to the signing request JSON")
+	out := fs.String("out", "", "write the approved request to file (default: update --in)")
+	approver := fs.String("approver", "", "name of the approver, recorded in the approval")
+	approverKey := fs.String("approver-key", "", "key file signing the approval")
+	confirm := fs.String("confirm", "", "full request digest, to approve without a prompt")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase of --approver-key (if used and key file omits it)")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+	})
+
+	if *inFile == "" || *approverKey == "" {
+		fmt.Fprintf(os.Stderr, "--in and --approver-key are required\n")
+		return 2
+	}
+	req, p, err := readSignRequest(*inFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --in: %v\n", err)
+		return 2
+	}
+	if req.Approval != nil {
+		fmt.Fprintf(os.Stderr, "the request is approved already\n")
+		return 2
+	}
+	if !p.expires.IsZero() && time.Now().After(p.expires) {
+		fmt.Fprintf(os.Stderr, "the request expired at %s; nothing approved\n", req.Expires)
+		return 1
+	}
+	digest, err := signRequestDigest(req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to hash the request: %v\n", err)
+		return 2
+	}
+
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	approverKP, code := loadSigningKeyPair("--approver-key", *approverKey, override, "signing an approval")
+	if code != 0 {
+		return code
+	}
+
+	printSignRequest(req, p, digest)
+	if *confirm != "" {
+		if !strings.EqualFold(strings.TrimSpace(*confirm), digest) {
+			fmt.Fprintf(os.Stderr, "--confirm does not match the request digest; nothing approved\n")
+			return 2
+		}
+	} else {
+		fmt.Fprintf(os.Stderr, "Type the review code %s to approve this request: ", digest[:8])
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Fprintf(os.Stderr, "\nfailed to read confirmation: %v\n", err)
+			return 2
+		}
+		if !strings.EqualFold(strings.TrimSpace(line), digest[:8]) {
+			fmt.Fprintf(os.Stderr, "confirmation does not match the review code; nothing approved\n")
+			return 1
+		}
+	}
+
+	sig, err := approverKP.Sign(signRequestApprovalBytes(req.Hash, digest))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "signing the approval failed: %v\n", err)
+		return 2
+	}
+	approvalSig, err := falcongo.NewSignature(sig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "signing the approval failed: %v\n", err)
+		return 2
+	}
+	req.Approval = &signRequestApprovalJSON{
+		Digest:            digest,
+		ApprovedAt:        time.Now().UTC().Format(time.RFC3339),
+		Approver:          *approver,
+		ApproverPublicKey: strings.ToLower(hex.EncodeToString(approverKP.PublicKey[:])),
+		Signature:         &approvalSig,
+	}
+	dst := *out
+	if dst == "" {
+		dst = *inFile
+	}
+	if code := writeSignRequest(req, dst); code != 0 {
+		return code
+	}
+	recordKeyUse(approverKP.PublicKey[:], *approverKey, "sign-request approve", "", 1)
+	fmt.Fprintf(os.Stdout, "approved %s\n", digest)
+	return 0
+}
+
+// printSignRequest renders req for review on stdout.
+func printSignRequest(req signRequestJSON, p signRequestPayload, digest string) {
+	fp := falcongo.Fingerprint(p.pub)
+	fmt.Printf("Signing request (%s)\n", req.Kind)
+	fmt.Printf("  key:         %s (%s)\n", hex.EncodeToString(fp[:]), mnemonic.Petname(fp))
+	if req.Description != "" {
+		fmt.Printf("  description: %s\n", visibleText(req.Description, false))
+	}
+	if req.Requester != "" {
+		fmt.Printf("  requester:   %s\n", visibleText(req.Requester, false))
+	}
+	fmt.Printf("  created:     %s\n", req.Created)
+	if req.Expires != "" {
+		fmt.Printf("  expires:     %s\n", req.Expires)
+	}
+	fmt.Printf("  digest:      %s\n", digest)
+
+	switch req.Kind {
+	case signRequestKindMessage:
+		m := req.Message
+		fmt.Printf("\nMessage: %d bytes, %s, %s %s\n", m.Size, m.ContentType, p.hash, m.Digest)
+		printMessagePreview(m.ContentType, p.message)
+	case signRequestKindTxn:
+		fmt.Printf("\nTransaction %s\n", req.Transaction.TxID)
+		for _, f := range req.Transaction.Fields {
+			fmt.Printf("  %s: %s\n", f.Name, f.Value)
+			if f.Warning != "" {
+				fmt.Printf("    WARNING: %s\n", f.Warning)
+			}
+		}
+	}
+	fmt.Println()
+}
+
+// printMessagePreview prints msg as text, as indented JSON, or as a hex
+// dump of its first bytes, according to contentType.
+func printMessagePreview(contentType string, msg []byte) {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	isText := strings.HasPrefix(mediaType, "text/") || params["charset"] != "" || isJSONMediaType(mediaType)
+	if isJSONMediaType(mediaType) {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, msg, "", "  "); err == nil {
+			msg = buf.Bytes()
+		} else {
+			fmt.Printf("  WARNING: the message is not valid JSON\n")
+		}
+	}
+	if isText && utf8.Valid(msg) {
+		text := visibleText(string(msg), true)
+		if text != string(msg) {
+			fmt.Printf("  WARNING: the text contains control or invisible formatting characters, shown as <U+XXXX>\n")
+		}
+		fmt.Printf("  %s\n", strings.ReplaceAll(text, "\n", "\n  "))
+		return
+	}
+	if isText {
+		fmt.Printf("  WARNING: the message is not valid UTF-8, yet its content type is %s\n", contentType)
+	}
+	shown := msg
+	if len(shown) > signRequestPreviewBytes {
+		shown = shown[:signRequestPreviewBytes]
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(hex.Dump(shown), "\n"), "\n") {
+		fmt.Printf("  %s\n", line)
+	}
+	if len(msg) > len(shown) {
+		fmt.Printf("  ... %d more bytes\n", len(msg)-len(shown))
+	}
+}
+
+// visibleText replaces control characters and invisible formatting
+// characters (bidirectional overrides, zero-width spaces) with <U+XXXX>, so
+// the reviewer sees every character that is signed. Newlines and tabs are
+// kept if multiline.
+func visibleText(s string, multiline bool) string {
+	var b strings.Builder
+	for _, r := range s {
+		if multiline && (r == '\n' || r == '\t') {
+			b.WriteRune(r)
+			continue
+		}
+		if unicode.IsControl(r) || unicode.Is(unicode.Cf, r) {
+			fmt.Fprintf(&b, "<U+%04X>", r)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// onCompletionNames names the application call actions.
+var onCompletionNames = map[types.OnCompletion]string{
+	types.NoOpOC:              "noop",
+	types.OptInOC:             "optin",
+	types.CloseOutOC:          "closeout",
+	types.ClearStateOC:        "clearstate",
+	types.UpdateApplicationOC: "update",
+	types.DeleteApplicationOC: "delete",
+}
+
+// describeTxn returns the fields of txn a reviewer needs to see, with
+// warnings on those that move control of the account or all of its funds.
+// pub is the key of the request, whose PQ account should be the sender.
+func describeTxn(txn types.Transaction, pub falcongo.PublicKey) ([]reviewField, error) {
+	pqAddr, err := algorand.GetAddressFromPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive the PQ address: %w", err)
+	}
+	var fields []reviewField
+	add := func(name, value, warning string) {
+		fields = append(fields, reviewField{Name: name, Value: value, Warning: warning})
+	}
+	addAddr := func(name string, a types.Address, warning string) {
+		if !a.IsZero() {
+			add(name, a.String(), warning)
+		}
+	}
+
+	add("type", string(txn.Type), "")
+	senderWarning := ""
+	if txn.Sender.String() != string(pqAddr) {
+		senderWarning = "not the PQ account of the key; signing works only if the sender is rekeyed to it"
+	}
+	add("sender", txn.Sender.String(), senderWarning)
+	feeWarning := ""
+	if txn.Fee > 1_000_000 {
+		feeWarning = "the fee exceeds 1 Algo"
+	}
+	add("fee", fmt.Sprintf("%d microAlgos", txn.Fee), feeWarning)
+	add("valid_rounds", fmt.Sprintf("%d-%d", txn.FirstValid, txn.LastValid), "")
+	add("genesis_id", txn.GenesisID, "")
+	add("genesis_hash", base64.StdEncoding.EncodeToString(txn.GenesisHash[:]), "")
+	if txn.Group != (types.Digest{}) {
+		add("group", base64.StdEncoding.EncodeToString(txn.Group[:]), "")
+	}
+	if len(txn.Note) > 0 {
+		if text := printableUTF8(txn.Note); text != "" {
+			add("note", visibleText(text, false), "")
+		} else {
+			add("note", "base64:"+base64.StdEncoding.EncodeToString(txn.Note), "")
+		}
+	}
+	if txn.Lease != ([32]byte{}) {
+		add("lease", base64.StdEncoding.EncodeToString(txn.Lease[:]), "")
+	}
+	addAddr("rekey_to", txn.RekeyTo, "rekeys the sender: this address takes control of the account")
+
+	switch txn.Type {
+	case types.PaymentTx:
+		add("receiver", txn.Receiver.String(), "")
+		add("amount", fmt.Sprintf("%d microAlgos", txn.Amount), "")
+		addAddr("close_remainder_to", txn.CloseRemainderTo,
+			"closes the account: its whole remaining balance goes to this address")
+	case types.AssetTransferTx:
+		add("asset_id", fmt.Sprintf("%d", txn.XferAsset), "")
+		add("asset_amount", fmt.Sprintf("%d", txn.AssetAmount), "")
+		add("asset_receiver", txn.AssetReceiver.String(), "")
+		addAddr("asset_sender", txn.AssetSender, "clawback: moves the assets of this account, not of the sender")
+		addAddr("asset_close_to", txn.AssetCloseTo,
+			"closes the holding: all units of the asset go to this address")
+	case types.AssetConfigTx:
+		ap := txn.AssetParams
+		switch {
+		case txn.ConfigAsset == 0:
+			add("asset_id", "new asset", "")
+			add("total", fmt.Sprintf("%d", ap.Total), "")
+			add("decimals", fmt.Sprintf("%d", ap.Decimals), "")
+			add("unit_name", visibleText(ap.UnitName, false), "")
+			add("asset_name", visibleText(ap.AssetName, false), "")
+			add("url", visibleText(ap.URL, false), "")
+		case ap == (types.AssetParams{}):
+			add("asset_id", fmt.Sprintf("%d", txn.ConfigAsset), "destroys the asset")
+		default:
+			add("asset_id", fmt.Sprintf("%d", txn.ConfigAsset), "")
+		}
+		addAddr("manager", ap.Manager, "")
+		addAddr("reserve", ap.Reserve, "")
+		addAddr("freeze", ap.Freeze, "")
+		addAddr("clawback", ap.Clawback, "")
+	case types.AssetFreezeTx:
+		add("freeze_asset", fmt.Sprintf("%d", txn.FreezeAsset), "")
+		add("freeze_account", txn.FreezeAccount.String(), "")
+		add("frozen", fmt.Sprintf("%t", txn.AssetFrozen), "")
+	case types.ApplicationCallTx:
+		add("app_id", fmt.Sprintf("%d", txn.ApplicationID), "")
+		action, ok := onCompletionNames[txn.OnCompletion]
+		if !ok {
+			action = fmt.Sprintf("%d", txn.OnCompletion)
+		}
+		actionWarning := ""
+		switch txn.OnCompletion {
+		case types.UpdateApplicationOC:
+			actionWarning = "replaces the programs of the application"
+		case types.DeleteApplicationOC:
+			actionWarning = "deletes the application"
+		}
+		add("on_completion", action, actionWarning)
+		for i, arg := range txn.ApplicationArgs {
+			add(fmt.Sprintf("app_arg[%d]", i), "base64:"+base64.StdEncoding.EncodeToString(arg), "")
+		}
+		for i, a := range txn.Accounts {
+			add(fmt.Sprintf("account[%d]", i), a.String(), "")
+		}
+		for i, id := range txn.ForeignApps {
+			add(fmt.Sprintf("foreign_app[%d]", i), fmt.Sprintf("%d", id), "")
+		}
+		for i, id := range txn.ForeignAssets {
+			add(fmt.Sprintf("foreign_asset[%d]", i), fmt.Sprintf("%d", id), "")
+		}
+	case types.KeyRegistrationTx:
+		switch {
+		case txn.Nonparticipation:
+			add("status", "nonparticipating", "marks the account nonparticipating for good")
+		case txn.VotePK == (types.VotePK{}):
+			add("status", "offline", "")
+		default:
+			add("status", "online", "")
+			add("vote_rounds", fmt.Sprintf("%d-%d", txn.VoteFirst, txn.VoteLast), "")
+			add("vote_key_dilution", fmt.Sprintf("%d", txn.VoteKeyDilution), "")
+		}
+	default:
+		add("fields", "not decoded", "the fields of this transaction type are not shown; review its msgpack")
+	}
+	return fields, nil
+}
+
+// ---- sign-request execute ----
+func runSignRequestExecute(args []string) int {
+	fs := flag.NewFlagSet("sign-request execute", flag.ExitOnError)
+	inFile := fs.String("in", "", "path to the approved signing request JSON")
+	keyPath := fs.String("key", "", "path to the keypair JSON of the request key")
+	approverPath := fs.String("approver-key", "", "key file of the approver whose signed approval is required")
+	out := fs.String("out", "", "write the signature or signed transaction to file (stdout if empty)")
+	sigEncoding := fs.String("sig-encoding", "", "message signature encoding: hex, base64, base64url or raw (default: hex on stdout, raw in files)")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+	})
+
+	if *inFile == "" || *keyPath == "" || *approverPath == "" {
+		fmt.Fprintf(os.Stderr, "--in, --key and --approver-key are required\n")
+		return 2
+	}
+	if *sigEncoding != "" {
+		if err := checkSigEncoding(*sigEncoding, false); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 2
+		}
+	}
+	req, p, err := readSignRequest(*inFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --in: %v\n", err)
+		return 2
+	}
+	if req.Kind == signRequestKindTxn && *sigEncoding != "" {
+		fmt.Fprintf(os.Stderr, "--sig-encoding applies to message requests\n")
+		return 2
+	}
+	approverPub, _, _, err := loadKeypairFile(*approverPath, nil)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --approver-key: %v\n", err)
+		return 2
+	}
+	if approverPub == nil {
+		fmt.Fprintf(os.Stderr, "public key not found in %s\n", *approverPath)
+		return 2
+	}
+	if reason := checkSignRequestApproval(req, p, approverPub); reason != "" {
+		fmt.Fprintf(os.Stderr, "%s; nothing signed\n", reason)
+		return 1
+	}
+
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	if req.Kind == signRequestKindTxn {
+		kp, code := loadSigningKeyPair("--key", *keyPath, override, "signing a transaction")
+		if code != 0 {
+			return code
+		}
+		if kp.PublicKey != p.pub {
+			fmt.Fprintf(os.Stderr, "--key is not the key of the request; nothing signed\n")
+			return 1
+		}
+		txid, stxn, err := algorand.SignTransaction(kp, p.txn)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "signing failed: %v\n", err)
+			return 2
+		}
+		if *out == "" {
+			fmt.Fprintln(os.Stdout, base64.StdEncoding.EncodeToString(stxn))
+		} else if err := writeFileAtomic(*out, stxn, 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *out, err)
+			return 2
+		} else {
+			fmt.Fprintf(os.Stdout, "signed %s\n", txid)
+		}
+		recordKeyUse(kp.PublicKey[:], *keyPath, "sign-request execute", "", 1)
+		return 0
+	}
+
+	kp, meta, code := loadSigningKey("--key", *keyPath, override, "signing", true)
+	if code != 0 {
+		return code
+	}
+	if kp.PublicKey != p.pub {
+		fmt.Fprintf(os.Stderr, "--key is not the key of the request; nothing signed\n")
+		return 1
+	}
+	policy, err := compileMessagePolicy(meta.MessagePolicy)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
+		return 2
+	}
+	pub := kp.PublicKey[:]
+	s := &messageSigner{
+		kp: kp,
+		event: hookEvent{
+			Operation: "sign-request execute",
+			KeyFile:   *keyPath,
+			PublicKey: req.PublicKey,
+		},
+		preHook:  flagOrEnv("", false, envPreHook),
+		postHook: flagOrEnv("", false, envPostHook),
+		pub:      pub,
+		policy:   policy,
+	}
+	sig, err := s.sign(p.message)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 2
+	}
+	if *out == "" {
+		enc := *sigEncoding
+		if enc == "" {
+			enc = sigEncodingHex
+		}
+		data := encodeSignature(sig, enc)
+		if enc != sigEncodingRaw {
+			data = append(data, '\n')
+		}
+		if _, err := os.Stdout.Write(data); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write signature: %v\n", err)
+			return 2
+		}
+	} else if err := writeFileAtomic(*out, signatureFileData(sig, *sigEncoding), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write signature: %v\n", err)
+		return 2
+	}
+	recordKeyUse(pub, *keyPath, "sign-request execute", "", 1)
+	return 0
+}
+
+// checkSignRequestApproval returns why req may not be executed, or "" if it
+// may: it must be approved, unchanged since, and not expired, and its
+// approval must be signed by approverPub (execute --approver-key). The
+// digest alone proves nothing, since anyone can compute it.
+func checkSignRequestApproval(req signRequestJSON, p signRequestPayload, approverPub []byte) string {
+	a := req.Approval
+	if a == nil {
+		return "the request is not approved"
+	}
+	digest, err := signRequestDigest(req)
+	if err != nil || !strings.EqualFold(a.Digest, digest) {
+		return "the request changed after it was approved"
+	}
+	if !p.expires.IsZero() && time.Now().After(p.expires) {
+		return fmt.Sprintf("the request expired at %s", req.Expires)
+	}
+	if a.Signature == nil {
+		return "the approval is not signed"
+	}
+	signer, err := parseHex(a.ApproverPublicKey)
+	if err != nil || len(signer) != falcongo.PublicKeySize {
+		return "the approval has an invalid approver_public_key"
+	}
+	if !bytes.Equal(signer, approverPub) {
+		return "the approval is not signed by --approver-key"
+	}
+	var pk falcongo.PublicKey
+	copy(pk[:], signer)
+	if err := a.Signature.Verify(signRequestApprovalBytes(req.Hash, a.Digest), pk); err != nil {
+		return "the approval signature is INVALID"
+	}
+	return ""
+}
+
+const helpSignRequest = `# falcon sign-request
+
+Review what a key signs before it signs it.
+
+A signing request is a JSON document stating what a key is asked to sign: a
+message with its content type, or an Algorand transaction with its fields
+decoded. approve renders it for a person to check and records the approval,
+signed with the approver's key; execute signs only a request approved by the
+key given as --approver-key, unchanged since, and unexpired.
+
+Usage:
+  falcon sign-request create --key <file> (--msg <text> | --in <file> | --txn <file>) [--content-type <type>] [--description <text>] [--requester <name>] [--expires-in <duration>] [--hash <name>] [--out <file>]
+  falcon sign-request approve --in <file> --approver-key <file> [--out <file>] [--approver <name>] [--confirm <digest>] [--mnemonic-passphrase <string>]
+  falcon sign-request execute --in <file> --key <file> --approver-key <file> [--out <file>] [--sig-encoding <enc>] [--mnemonic-passphrase <string>]
+
+Subcommands:
+  create     Describe a message or transaction to sign
+  approve    Show a signing request and record its approval
+  execute    Sign an approved signing request
+
+Arguments (create):
+  --key <file>              key file of the signer (public key suffices)
+  --msg <text> | --in <file>
+                            message to sign
+  --txn <file>              unsigned Algorand transaction, msgpack or base64,
+                            grouped with the dummies covering its logicsig
+  --content-type <type>     media type of the message (default: text/plain;
+                            charset=utf-8 for UTF-8, else application/octet-stream)
+  --description <text>      what the request is for
+  --requester <name>        who asks for the signature
+  --expires-in <duration>   refuse the request after this long, e.g. 24h
+  --hash <name>             sha512-256 (default), sha3-256 or blake2b-256
+  --out <file>              write the request JSON (stdout if omitted)
+
+Arguments (approve):
+  --in <file>               signing request JSON (required)
+  --approver-key <file>     key file signing the approval (required)
+  --out <file>              write the approved request (default: update --in)
+  --approver <name>         name recorded in the approval
+  --confirm <digest>        full request digest, to approve without the prompt
+  --mnemonic-passphrase     optional mnemonic passphrase of --approver-key
+
+Arguments (execute):
+  --in <file>               approved signing request JSON (required)
+  --key <file>              keypair JSON of the request key (required)
+  --approver-key <file>     key file of the approver; the approval must be
+                            signed by this key (required, public key suffices)
+  --out <file>              write the signature (raw) or the signed transaction
+                            (msgpack); otherwise print hex or base64 to stdout
+  --sig-encoding <enc>      message signature encoding: hex, base64, base64url or raw
+  --mnemonic-passphrase     optional mnemonic passphrase when the key file omits it
+
+Exit codes: 0 success; 1 not confirmed, not approved, changed since approval,
+expired, or not signed by --approver-key; 2 usage, parse or I/O errors.
+
+Examples:
+  falcon sign-request create --key signer.pub.json --in statement.json --content-type application/json --out req.json
+  falcon sign-request approve --in req.json --approver alice --approver-key alice.json
+  falcon sign-request execute --in req.json --key signer.json --approver-key alice.pub.json --out statement.sig
+`
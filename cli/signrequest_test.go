@@ -0,0 +1,377 @@
+package cli
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/encoding/msgpack"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/hashing"
+)
+
+// readSignRequestFile decodes the signing request at path.
+func readSignRequestFile(t *testing.T, path string) signRequestJSON {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read the request: %v", err)
+	}
+	var req signRequestJSON
+	if err := json.Unmarshal(data, &req); err != nil {
+		t.Fatalf("invalid request JSON: %v", err)
+	}
+	return req
+}
+
+// TestRunSignRequest_Message creates, approves with a signed approval, and
+// executes a message request, and checks the signature.
+func TestRunSignRequest_Message(t *testing.T) {
+	dir := t.TempDir()
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("sign-request signer seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	approverKP, err := falcongo.GenerateKeyPair(deriveSeed([]byte("sign-request approver seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	pubPath := writeKeypairJSON(t, dir, "signer.pub.json", kp, false)
+	keyPath := writeKeypairJSON(t, dir, "signer.json", kp, true)
+	approverPath := writeKeypairJSON(t, dir, "approver.json", approverKP, true)
+	approverPubPath := writeKeypairJSON(t, dir, "approver.pub.json", approverKP, false)
+	reqPath := filepath.Join(dir, "req.json")
+
+	msg := `{"release":"v1.2.0","ok":true}`
+	var code int
+	_, stderr := captureStdoutStderr(t, func() {
+		code = runSignRequestCreate([]string{"--key", pubPath, "--msg", msg, "--content-type", "application/json",
+			"--description", "release statement", "--expires-in", "1h", "--out", reqPath})
+	})
+	if code != 0 {
+		t.Fatalf("expected exit 0 from create, got %d: %s", code, stderr)
+	}
+	req := readSignRequestFile(t, reqPath)
+	digest, err := signRequestDigest(req)
+	if err != nil {
+		t.Fatalf("signRequestDigest failed: %v", err)
+	}
+	if req.Kind != signRequestKindMessage || req.Hash != string(hashing.Default) || req.Message.Size != len(msg) ||
+		req.Message.Digest != hex.EncodeToString(hashing.Default.Sum([]byte(msg))) || req.Expires == "" ||
+		!strings.Contains(stderr, "review code: "+digest[:8]) {
+		t.Fatalf("unexpected request %+v: %s", req, stderr)
+	}
+
+	_, stderr = captureStdoutStderr(t, func() {
+		code = runSignRequestExecute([]string{"--in", reqPath, "--key", keyPath, "--approver-key", approverPubPath})
+	})
+	if code != 1 || !strings.Contains(stderr, "the request is not approved; nothing signed") {
+		t.Fatalf("expected a refusal before approval, got exit %d: %s", code, stderr)
+	}
+
+	var stdout string
+	stdout, stderr = captureStdoutStderr(t, func() {
+		code = runSignRequestApprove([]string{"--in", reqPath, "--approver", "alice",
+			"--approver-key", approverPath, "--confirm", digest})
+	})
+	if code != 0 {
+		t.Fatalf("expected exit 0 from approve, got %d: %s", code, stderr)
+	}
+	for _, want := range []string{"Signing request (message)", "description: release statement",
+		"application/json", `"release": "v1.2.0"`, "approved " + digest} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("approve output lacks %q:\n%s", want, stdout)
+		}
+	}
+
+	sigPath := filepath.Join(dir, "msg.sig")
+	_, stderr = captureStdoutStderr(t, func() {
+		code = runSignRequestExecute([]string{"--in", reqPath, "--key", keyPath, "--approver-key", approverPubPath,
+			"--out", sigPath})
+	})
+	if code != 0 {
+		t.Fatalf("expected exit 0 from execute, got %d: %s", code, stderr)
+	}
+	sig, err := os.ReadFile(sigPath)
+	if err != nil {
+		t.Fatalf("failed to read the signature: %v", err)
+	}
+	if err := falcongo.Verify([]byte(msg), sig, kp.PublicKey); err != nil {
+		t.Fatalf("the signature does not verify: %v", err)
+	}
+
+	// The approval must be signed by --approver-key, and cover the request.
+	_, stderr = captureStdoutStderr(t, func() {
+		code = runSignRequestExecute([]string{"--in", reqPath, "--key", keyPath, "--approver-key", pubPath})
+	})
+	if code != 1 || !strings.Contains(stderr, "not signed by --approver-key") {
+		t.Fatalf("expected a refusal on --approver-key, got exit %d: %s", code, stderr)
+	}
+	// Anyone can compute the digest: an approval without a signature, or
+	// with a signature by another key, is refused.
+	for name, tc := range map[string]struct {
+		mutate func(*signRequestJSON)
+		want   string
+	}{
+		"changed":  {func(r *signRequestJSON) { r.Description = "something else" }, "changed after it was approved"},
+		"unsigned": {func(r *signRequestJSON) { r.Approval.Signature = nil }, "the approval is not signed"},
+		"forged": {func(r *signRequestJSON) { r.Approval.ApproverPublicKey = r.PublicKey },
+			"not signed by --approver-key"},
+	} {
+		approved := readSignRequestFile(t, reqPath)
+		tc.mutate(&approved)
+		data, _ := json.Marshal(approved)
+		tamperedPath := filepath.Join(dir, name+".json")
+		if err := os.WriteFile(tamperedPath, data, 0o644); err != nil {
+			t.Fatalf("failed to write the request: %v", err)
+		}
+		_, stderr = captureStdoutStderr(t, func() {
+			code = runSignRequestExecute([]string{"--in", tamperedPath, "--key", keyPath, "--approver-key", approverPubPath})
+		})
+		if code != 1 || !strings.Contains(stderr, tc.want) {
+			t.Fatalf("%s: expected exit 1 (%s), got %d: %s", name, tc.want, code, stderr)
+		}
+	}
+	_, stderr = captureStdoutStderr(t, func() {
+		code = runSignRequestExecute([]string{"--in", reqPath, "--key", approverPath, "--approver-key", approverPubPath})
+	})
+	if code != 1 || !strings.Contains(stderr, "--key is not the key of the request") {
+		t.Fatalf("expected a refusal of the other key, got exit %d: %s", code, stderr)
+	}
+}
+
+// TestRunSignRequest_Review checks the prompt of approve and the rendering
+// of invisible characters, and refuses expired and inconsistent requests.
+func TestRunSignRequest_Review(t *testing.T) {
+	dir := t.TempDir()
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("sign-request review seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	pubPath := writeKeypairJSON(t, dir, "signer.pub.json", kp, false)
+	approverPath := writeKeypairJSON(t, dir, "approver.json", kp, true)
+	reqPath := filepath.Join(dir, "req.json")
+	var code int
+	_, _ = captureStdoutStderr(t, func() {
+		code = runSignRequestCreate([]string{"--key", pubPath, "--msg", "pay alice\u202e001\u202c", "--out", reqPath})
+	})
+	if code != 0 {
+		t.Fatalf("expected exit 0 from create, got %d", code)
+	}
+	req := readSignRequestFile(t, reqPath)
+	if req.Message.ContentType != "text/plain; charset=utf-8" {
+		t.Fatalf("unexpected content type %q", req.Message.ContentType)
+	}
+
+	var stdout, stderr string
+	withStdin(t, "00000000\n", func() {
+		stdout, stderr = captureStdoutStderr(t, func() {
+			code = runSignRequestApprove([]string{"--in", reqPath, "--approver-key", approverPath})
+		})
+	})
+	if code != 1 || !strings.Contains(stderr, "nothing approved") {
+		t.Fatalf("expected exit 1 on a wrong review code, got %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "pay alice<U+202E>001<U+202C>") || !strings.Contains(stdout, "WARNING: the text contains") {
+		t.Fatalf("invisible characters not flagged:\n%s", stdout)
+	}
+	digest, err := signRequestDigest(req)
+	if err != nil {
+		t.Fatalf("signRequestDigest failed: %v", err)
+	}
+	withStdin(t, digest[:8]+"\n", func() {
+		_, stderr = captureStdoutStderr(t, func() {
+			code = runSignRequestApprove([]string{"--in", reqPath, "--approver-key", approverPath})
+		})
+	})
+	if code != 0 || readSignRequestFile(t, reqPath).Approval == nil {
+		t.Fatalf("expected the request approved, got exit %d: %s", code, stderr)
+	}
+
+	for name, mutate := range map[string]func(*signRequestJSON){
+		"expired":      func(r *signRequestJSON) { r.Expires = "2001-01-01T00:00:00Z" },
+		"inconsistent": func(r *signRequestJSON) { r.Message.Size++ },
+		"unknown hash": func(r *signRequestJSON) { r.Hash = "md5" },
+	} {
+		r := req
+		m := *req.Message
+		r.Message = &m
+		mutate(&r)
+		data, _ := json.Marshal(r)
+		path := filepath.Join(dir, name+".json")
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatalf("failed to write the request: %v", err)
+		}
+		want := 1
+		if name != "expired" {
+			want = 2
+		}
+		_, stderr = captureStdoutStderr(t, func() {
+			code = runSignRequestApprove([]string{"--in", path, "--approver-key", approverPath, "--confirm", digest})
+		})
+		if code != want {
+			t.Errorf("%s: expected exit %d, got %d: %s", name, want, code, stderr)
+		}
+	}
+}
+
+// TestRunSignRequest_Transaction decodes a payment for review, with its
+// warnings, and signs it with the PQ logicsig once approved.
+func TestRunSignRequest_Transaction(t *testing.T) {
+	dir := t.TempDir()
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("sign-request txn seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, dir, "signer.json", kp, true)
+	pubPath := writeKeypairJSON(t, dir, "signer.pub.json", kp, false)
+	pq, err := algorand.GetAddressFromPublicKey(kp.PublicKey)
+	if err != nil {
+		t.Fatalf("GetAddressFromPublicKey failed: %v", err)
+	}
+	sender, err := types.DecodeAddress(string(pq))
+	if err != nil {
+		t.Fatalf("DecodeAddress failed: %v", err)
+	}
+	txn := types.Transaction{
+		Type: types.PaymentTx,
+		Header: types.Header{Sender: sender, Fee: 1000, FirstValid: 10, LastValid: 1010,
+			GenesisID: "devnet-v1", GenesisHash: types.Digest{1}, Note: []byte("rent")},
+		PaymentTxnFields: types.PaymentTxnFields{Receiver: types.Address{2}, Amount: 5,
+			CloseRemainderTo: types.Address{3}},
+	}
+	txnPath := filepath.Join(dir, "pay.txn")
+	if err := os.WriteFile(txnPath, msgpack.Encode(types.SignedTxn{Txn: txn}), 0o644); err != nil {
+		t.Fatalf("failed to write the transaction: %v", err)
+	}
+	reqPath := filepath.Join(dir, "req.json")
+	var code int
+	_, stderr := captureStdoutStderr(t, func() {
+		code = runSignRequestCreate([]string{"--key", keyPath, "--txn", txnPath, "--out", reqPath})
+	})
+	if code != 0 {
+		t.Fatalf("expected exit 0 from create, got %d: %s", code, stderr)
+	}
+	req := readSignRequestFile(t, reqPath)
+	if req.Kind != signRequestKindTxn || req.Transaction.TxID != crypto.GetTxID(txn) {
+		t.Fatalf("unexpected request %+v", req)
+	}
+	digest, err := signRequestDigest(req)
+	if err != nil {
+		t.Fatalf("signRequestDigest failed: %v", err)
+	}
+	stdout, stderr := captureStdoutStderr(t, func() {
+		code = runSignRequestApprove([]string{"--in", reqPath, "--approver-key", keyPath, "--confirm", digest})
+	})
+	if code != 0 {
+		t.Fatalf("expected exit 0 from approve, got %d: %s", code, stderr)
+	}
+	for _, want := range []string{"sender: " + string(pq), "amount: 5 microAlgos", "note: rent",
+		"close_remainder_to: " + types.Address{3}.String(), "WARNING: closes the account"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("approve output lacks %q:\n%s", want, stdout)
+		}
+	}
+	if strings.Contains(stdout, "not the PQ account") {
+		t.Errorf("warned on the PQ sender:\n%s", stdout)
+	}
+
+	_, stderr = captureStdoutStderr(t, func() {
+		code = runSignRequestExecute([]string{"--in", reqPath, "--key", keyPath, "--approver-key", pubPath,
+			"--sig-encoding", "hex"})
+	})
+	if code != 2 {
+		t.Fatalf("expected exit 2 on --sig-encoding, got %d: %s", code, stderr)
+	}
+	outPath := filepath.Join(dir, "pay.stxn")
+	_, stderr = captureStdoutStderr(t, func() {
+		code = runSignRequestExecute([]string{"--in", reqPath, "--key", keyPath, "--approver-key", pubPath,
+			"--out", outPath})
+	})
+	if code != 0 {
+		t.Fatalf("expected exit 0 from execute, got %d: %s", code, stderr)
+	}
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("failed to read the signed transaction: %v", err)
+	}
+	var stxn types.SignedTxn
+	if err := msgpack.Decode(data, &stxn); err != nil {
+		t.Fatalf("invalid signed transaction: %v", err)
+	}
+	if crypto.GetTxID(stxn.Txn) != crypto.GetTxID(txn) || len(stxn.Lsig.Logic) == 0 || len(stxn.Lsig.Args) != 1 {
+		t.Fatalf("unexpected signed transaction %+v", stxn)
+	}
+}
+
+// TestDescribeTxn_Warnings flags the fields that hand over an account or its
+// assets.
+func TestDescribeTxn_Warnings(t *testing.T) {
+	var pk falcongo.PublicKey
+	copy(pk[:], []byte("describe"))
+	testCases := []struct {
+		name  string
+		txn   types.Transaction
+		field string
+	}{
+		{"rekey", types.Transaction{Type: types.PaymentTx, Header: types.Header{RekeyTo: types.Address{4}}}, "rekey_to"},
+		{"clawback", types.Transaction{Type: types.AssetTransferTx,
+			AssetTransferTxnFields: types.AssetTransferTxnFields{AssetSender: types.Address{5}}}, "asset_sender"},
+		{"destroy", types.Transaction{Type: types.AssetConfigTx,
+			AssetConfigTxnFields: types.AssetConfigTxnFields{ConfigAsset: 7}}, "asset_id"},
+		{"delete", types.Transaction{Type: types.ApplicationCallTx,
+			ApplicationFields: types.ApplicationFields{ApplicationCallTxnFields: types.ApplicationCallTxnFields{
+				ApplicationID: 8, OnCompletion: types.DeleteApplicationOC}}}, "on_completion"},
+		{"fee", types.Transaction{Type: types.PaymentTx, Header: types.Header{Fee: 2_000_000}}, "fee"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fields, err := describeTxn(tc.txn, pk)
+			if err != nil {
+				t.Fatalf("describeTxn failed: %v", err)
+			}
+			warned := map[string]bool{}
+			for _, f := range fields {
+				warned[f.Name] = f.Warning != ""
+			}
+			if !warned[tc.field] || !warned["sender"] {
+				t.Fatalf("expected warnings on %s and sender, got %+v", tc.field, fields)
+			}
+		})
+	}
+}
+
+// TestRunSignRequest_Usage covers the usage errors of the subcommands.
+func TestRunSignRequest_Usage(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		run  func([]string) int
+		args []string
+	}{
+		{"create without key", runSignRequestCreate, []string{"--msg", "hi"}},
+		{"create without message", runSignRequestCreate, []string{"--key", "k.json"}},
+		{"create with two messages", runSignRequestCreate, []string{"--key", "k.json", "--msg", "hi", "--in", "m"}},
+		{"create txn content type", runSignRequestCreate, []string{"--key", "k.json", "--txn", "t", "--content-type", "text/plain"}},
+		{"create negative expiry", runSignRequestCreate, []string{"--key", "k.json", "--msg", "hi", "--expires-in", "-1h"}},
+		{"create sha256", runSignRequestCreate, []string{"--key", "k.json", "--msg", "hi", "--hash", "sha256"}},
+		{"approve without in", runSignRequestApprove, []string{"--approver", "alice", "--approver-key", "a.json"}},
+		{"approve without approver key", runSignRequestApprove, []string{"--in", "r.json", "--mnemonic-passphrase", "x"}},
+		{"execute without key", runSignRequestExecute, []string{"--in", "r.json", "--approver-key", "a.json"}},
+		{"execute without approver key", runSignRequestExecute, []string{"--in", "r.json", "--key", "k.json"}},
+		{"execute encoding", runSignRequestExecute, []string{"--in", "r.json", "--key", "k.json", "--approver-key", "a.json",
+			"--sig-encoding", "pem"}},
+	} {
+		var code int
+		_, _ = captureStdoutStderr(t, func() { code = tc.run(tc.args) })
+		if code != 2 {
+			t.Errorf("%s: expected exit 2, got %d", tc.name, code)
+		}
+	}
+}
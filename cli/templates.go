@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+)
+
+// templateCheckJSON is a template check of algorand verify-templates --json.
+type templateCheckJSON struct {
+	Name   string `json:"name"`
+	SHA256 string `json:"sha256,omitempty"`
+	Want   string `json:"manifest_sha256,omitempty"`
+	OK     bool   `json:"ok"`
+}
+
+// verifyTemplatesJSON is the output of algorand verify-templates --json.
+type verifyTemplatesJSON struct {
+	ManifestSHA256 string              `json:"manifest_sha256"`
+	ExpectedOK     *bool               `json:"expected_ok,omitempty"`
+	Templates      []templateCheckJSON `json:"templates"`
+}
+
+// ---- algorand verify-templates ----
+func runAlgorandVerifyTemplates(args []string) int {
+	fs := flag.NewFlagSet("algorand verify-templates", flag.ExitOnError)
+	expect := fs.String("expect", "", "SHA-256 of the manifest published for the release, to compare with")
+	jsonOut := fs.Bool("json", false, "print the result as JSON")
+	parseFlags(fs, args)
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "unexpected argument: %s\n", fs.Arg(0))
+		return 2
+	}
+	want := strings.ToLower(strings.TrimSpace(*expect))
+	if want != "" && len(want) != 64 {
+		fmt.Fprintf(os.Stderr, "invalid --expect: want 64 hex digits\n")
+		return 2
+	}
+
+	checks, err := algorand.VerifyTemplateIntegrity()
+	if err != nil && !errors.Is(err, algorand.ErrTemplateMismatch) {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return 2
+	}
+	manifest := algorand.TemplateManifestSHA256()
+	expectedOK := want == "" || want == manifest
+
+	if *jsonOut {
+		out := verifyTemplatesJSON{ManifestSHA256: manifest}
+		if want != "" {
+			out.ExpectedOK = &expectedOK
+		}
+		for _, c := range checks {
+			out.Templates = append(out.Templates, templateCheckJSON{Name: c.Name, SHA256: c.Got, Want: c.Want, OK: c.OK()})
+		}
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return 2
+		}
+		fmt.Printf("%s\n", data)
+	} else {
+		for _, c := range checks {
+			switch {
+			case c.OK():
+				fmt.Printf("ok        %s  %s\n", c.Got, c.Name)
+			case c.Want == "":
+				fmt.Printf("UNLISTED  %s  %s\n", c.Got, c.Name)
+			case c.Got == "":
+				fmt.Printf("MISSING   %s  %s\n", c.Want, c.Name)
+			default:
+				fmt.Printf("MISMATCH  %s  %s (manifest: %s)\n", c.Got, c.Name, c.Want)
+			}
+		}
+		fmt.Printf("manifest sha256: %s\n", manifest)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v: this binary does not derive accounts as its release does\n", err)
+		return 1
+	}
+	if !expectedOK {
+		fmt.Fprintf(os.Stderr, "the manifest of this binary is not the expected one (%s)\n", want)
+		return 1
+	}
+	if want == "" {
+		fmt.Fprintf(os.Stderr, "compare the manifest sha256 with the one published for the release, or pass it with --expect\n")
+	}
+	return 0
+}
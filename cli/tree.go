@@ -0,0 +1,462 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/hashing"
+)
+
+// treeRootJSON is the signed root of a Merkle tree over the files of a
+// directory.
+type treeRootJSON struct {
+	Version   int                `json:"version"`
+	Algorithm string             `json:"algorithm"`
+	Hash      string             `json:"hash"`
+	Root      string             `json:"root"` // hex
+	Leaves    int                `json:"leaves"`
+	PublicKey string             `json:"public_key"`
+	Signature falcongo.Signature `json:"signature"`
+}
+
+// treeProofJSON proves that one file is a leaf of a signed tree, so the file
+// verifies without the other files or a manifest of them.
+type treeProofJSON struct {
+	Version int `json:"version"`
+	// Path is the slash-separated path of the file in the signed directory.
+	Path  string `json:"path"`
+	Index int    `json:"index"`
+	// Siblings are the hex hashes of the proof, from the leaf up.
+	Siblings []string     `json:"siblings"`
+	Root     treeRootJSON `json:"root"`
+}
+
+const (
+	treeVersion   = 1
+	treeAlgorithm = "falcon-1024"
+	treeDomain    = "falcon-tree-v1"
+	// treeProofSuffix is appended to the path of a file to name its proof.
+	treeProofSuffix = ".proof.json"
+)
+
+// treeSigningBytes returns the bytes the root signature covers: the domain
+// tag, the hash name, a zero byte, the leaf count (8 bytes, big-endian) and
+// the root.
+func treeSigningBytes(hash hashing.Algorithm, leaves int, root []byte) []byte {
+	out := append([]byte(treeDomain), hash...)
+	out = append(out, 0)
+	out = binary.BigEndian.AppendUint64(out, uint64(leaves))
+	return append(out, root...)
+}
+
+// treeLeaf returns the leaf of the file at path (slash-separated) with the
+// content digest digest: H(0x00 || 4-byte big-endian length of path || path ||
+// digest), so a proof also binds the path of the file.
+func treeLeaf(hash hashing.Algorithm, path string, digest []byte) []byte {
+	h := hash.New()
+	h.Write([]byte{0})
+	h.Write(binary.BigEndian.AppendUint32(nil, uint32(len(path))))
+	h.Write([]byte(path))
+	h.Write(digest)
+	return h.Sum(nil)
+}
+
+// treeNode returns the parent of left and right: H(0x01 || left || right).
+// The prefixes keep leaves and inner nodes apart.
+func treeNode(hash hashing.Algorithm, left, right []byte) []byte {
+	h := hash.New()
+	h.Write([]byte{1})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// treeLevels returns the levels of the tree over leaves, from the leaves to
+// the root. The last node of a level with an odd number of nodes moves up
+// unchanged, so no node is ever hashed with itself.
+func treeLevels(hash hashing.Algorithm, leaves [][]byte) [][][]byte {
+	levels := [][][]byte{leaves}
+	for level := leaves; len(level) > 1; {
+		next := make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i+1 < len(level); i += 2 {
+			next = append(next, treeNode(hash, level[i], level[i+1]))
+		}
+		if len(level)%2 == 1 {
+			next = append(next, level[len(level)-1])
+		}
+		levels = append(levels, next)
+		level = next
+	}
+	return levels
+}
+
+// treeProof returns the siblings of the leaf at index, from the leaf up.
+func treeProof(levels [][][]byte, index int) [][]byte {
+	var siblings [][]byte
+	for _, level := range levels[:len(levels)-1] {
+		if sibling := index ^ 1; sibling < len(level) {
+			siblings = append(siblings, level[sibling])
+		}
+		index /= 2
+	}
+	return siblings
+}
+
+// treeRootFromProof returns the root of a tree of leaves leaves in which
+// leaf is at index with the given siblings. The shape of the tree fixes
+// where each sibling goes, so a proof has exactly one valid sibling count.
+func treeRootFromProof(hash hashing.Algorithm, leaf []byte, index, leaves int, siblings [][]byte) ([]byte, error) {
+	if index < 0 || index >= leaves {
+		return nil, fmt.Errorf("index %d outside the %d leaves", index, leaves)
+	}
+	node := leaf
+	for n := leaves; n > 1; n = (n + 1) / 2 {
+		if index^1 < n {
+			if len(siblings) == 0 {
+				return nil, errors.New("too few siblings")
+			}
+			if index%2 == 1 {
+				node = treeNode(hash, siblings[0], node)
+			} else {
+				node = treeNode(hash, node, siblings[0])
+			}
+			siblings = siblings[1:]
+		}
+		index /= 2
+	}
+	if len(siblings) != 0 {
+		return nil, errors.New("too many siblings")
+	}
+	return node, nil
+}
+
+// hashFile returns the digest of the contents of the file at path, read as a
+// stream.
+func hashFile(hash hashing.Algorithm, path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := hash.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+// ---- tree dispatcher ----
+func runTree(args []string) int {
+	return runSubcommand("tree", args)
+}
+
+// ---- tree sign ----
+func runTreeSign(args []string) int {
+	fs := flag.NewFlagSet("tree sign", flag.ExitOnError)
+	keyPath := fs.String("key", "", "path to keypair JSON file")
+	dir := fs.String("dir", "", "directory whose files are signed")
+	proofDir := fs.String("proof-dir", "", "write <file>"+treeProofSuffix+" for each file here")
+	out := fs.String("out", "", "write the signed root JSON to file (stdout if empty)")
+	hashName := fs.String("hash", string(hashing.Default), "hash of the files and of the tree")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+	})
+
+	if *keyPath == "" || *dir == "" || *proofDir == "" {
+		fmt.Fprintf(os.Stderr, "--key, --dir and --proof-dir are required\n")
+		return 2
+	}
+	hash, err := hashing.ParseSelectable(*hashName)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --hash: %v\n", err)
+		return 2
+	}
+
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	kp, code := loadSigningKeyPair("--key", *keyPath, override, "signing the tree")
+	if code != 0 {
+		return code
+	}
+	pub := kp.PublicKey[:]
+
+	// Do not sign our own output when it is inside --dir.
+	absProofDir, err := filepath.Abs(*proofDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --proof-dir: %v\n", err)
+		return 2
+	}
+	absOut := ""
+	if *out != "" {
+		if absOut, err = filepath.Abs(*out); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --out: %v\n", err)
+			return 2
+		}
+	}
+	var files []string
+	err = filepath.WalkDir(*dir, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if abs == absProofDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() && abs != absOut {
+			rel, err := filepath.Rel(*dir, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --dir: %v\n", err)
+		return 2
+	}
+	if len(files) == 0 {
+		fmt.Fprintf(os.Stderr, "no files found in %s\n", *dir)
+		return 2
+	}
+	sort.Strings(files)
+
+	leaves := make([][]byte, len(files))
+	bar := startProgress("hashing", 0, uint64(len(files)))
+	for i, rel := range files {
+		digest, err := hashFile(hash, filepath.Join(*dir, filepath.FromSlash(rel)))
+		if err != nil {
+			bar.finish()
+			fmt.Fprintf(os.Stderr, "%s: %v\n", rel, err)
+			return 2
+		}
+		leaves[i] = treeLeaf(hash, rel, digest)
+		bar.add(1)
+	}
+	bar.finish()
+	levels := treeLevels(hash, leaves)
+	root := levels[len(levels)-1][0]
+
+	sig, err := kp.Sign(treeSigningBytes(hash, len(files), root))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "signing failed: %v\n", err)
+		return 2
+	}
+	signed := treeRootJSON{
+		Version:   treeVersion,
+		Algorithm: treeAlgorithm,
+		Hash:      string(hash),
+		Root:      hex.EncodeToString(root),
+		Leaves:    len(files),
+		PublicKey: strings.ToLower(hex.EncodeToString(pub)),
+	}
+	if signed.Signature, err = falcongo.NewSignature(sig); err != nil {
+		fmt.Fprintf(os.Stderr, "signing failed: %v\n", err)
+		return 2
+	}
+
+	for i, rel := range files {
+		proof := treeProofJSON{Version: treeVersion, Path: rel, Index: i, Siblings: []string{}, Root: signed}
+		for _, s := range treeProof(levels, i) {
+			proof.Siblings = append(proof.Siblings, hex.EncodeToString(s))
+		}
+		data, err := json.MarshalIndent(proof, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to encode the proof of %s: %v\n", rel, err)
+			return 2
+		}
+		dst := filepath.Join(*proofDir, filepath.FromSlash(rel)+treeProofSuffix)
+		if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write the proof of %s: %v\n", rel, err)
+			return 2
+		}
+		if err := writeFileAtomic(dst, append(data, '\n'), 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write the proof of %s: %v\n", rel, err)
+			return 2
+		}
+	}
+
+	data, err := json.MarshalIndent(signed, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode the root JSON: %v\n", err)
+		return 2
+	}
+	data = append(data, '\n')
+	if *out == "" {
+		if _, err := os.Stdout.Write(data); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write the root JSON: %v\n", err)
+			return 2
+		}
+	} else if err := writeFileAtomic(*out, data, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *out, err)
+		return 2
+	}
+	recordKeyUse(pub, *keyPath, "tree sign", "", 1)
+	fmt.Fprintf(os.Stderr, "signed %d files, root %s\n", len(files), signed.Root)
+	return 0
+}
+
+// ---- tree verify ----
+func runTreeVerify(args []string) int {
+	fs := flag.NewFlagSet("tree verify", flag.ExitOnError)
+	filePath := fs.String("file", "", "file to verify")
+	proofPath := fs.String("proof", "", "proof JSON of the file, written by tree sign")
+	keyPath := fs.String("key", "", "require the root signed by the public key in this file")
+	wantPath := fs.String("path", "", "require the file signed under this path in the directory")
+	parseFlags(fs, args)
+
+	if *filePath == "" || *proofPath == "" {
+		fmt.Fprintf(os.Stderr, "--file and --proof are required\n")
+		return 2
+	}
+	var want []byte
+	if *keyPath != "" {
+		pub, _, _, err := loadKeypairFile(*keyPath, nil)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
+			return 2
+		}
+		if pub == nil {
+			fmt.Fprintf(os.Stderr, "public key not found in %s\n", *keyPath)
+			return 2
+		}
+		want = pub
+	}
+	b, err := os.ReadFile(*proofPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --proof: %v\n", err)
+		return 2
+	}
+	var proof treeProofJSON
+	if err := json.Unmarshal(b, &proof); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid proof JSON: %v\n", err)
+		return 2
+	}
+	r := proof.Root
+	if proof.Version != treeVersion || r.Version != treeVersion || r.Algorithm != treeAlgorithm {
+		fmt.Fprintf(os.Stderr, "unsupported proof version %d / root version %d / algorithm %q\n",
+			proof.Version, r.Version, r.Algorithm)
+		return 2
+	}
+	hash, err := hashing.Parse(r.Hash)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid proof hash: %v\n", err)
+		return 2
+	}
+	root, err := parseHex(r.Root)
+	if err != nil || len(root) != hash.Size() {
+		fmt.Fprintf(os.Stderr, "invalid proof root\n")
+		return 2
+	}
+	siblings := make([][]byte, len(proof.Siblings))
+	for i, s := range proof.Siblings {
+		if siblings[i], err = parseHex(s); err != nil || len(siblings[i]) != hash.Size() {
+			fmt.Fprintf(os.Stderr, "invalid proof sibling %d\n", i)
+			return 2
+		}
+	}
+	pub, err := parseHex(r.PublicKey)
+	if err != nil || len(pub) != falcongo.PublicKeySize {
+		fmt.Fprintf(os.Stderr, "invalid proof public_key\n")
+		return 2
+	}
+	digest, err := hashFile(hash, *filePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --file: %v\n", err)
+		return 2
+	}
+
+	var pk falcongo.PublicKey
+	copy(pk[:], pub)
+	if err := r.Signature.Verify(treeSigningBytes(hash, r.Leaves, root), pk); err != nil {
+		fmt.Fprintln(os.Stdout, "INVALID: the root signature does not verify")
+		return 1
+	}
+	if want != nil && !bytes.Equal(want, pub) {
+		fmt.Fprintln(os.Stdout, "INVALID: the root is signed by a different key than --key")
+		return 1
+	}
+	got, err := treeRootFromProof(hash, treeLeaf(hash, proof.Path, digest), proof.Index, r.Leaves, siblings)
+	if err != nil {
+		fmt.Fprintf(os.Stdout, "INVALID: %v\n", err)
+		return 1
+	}
+	if !bytes.Equal(got, root) {
+		fmt.Fprintln(os.Stdout, "INVALID: the file is not the one signed under this path")
+		return 1
+	}
+	if *wantPath != "" && filepath.ToSlash(*wantPath) != proof.Path {
+		fmt.Fprintf(os.Stdout, "INVALID: the file is signed as %s, not %s\n", proof.Path, *wantPath)
+		return 1
+	}
+	fp := falcongo.Fingerprint(pk)
+	fmt.Fprintln(os.Stdout, "VALID")
+	fmt.Fprintf(os.Stdout, "path: %s\n", proof.Path)
+	fmt.Fprintf(os.Stdout, "root: %s\n", r.Root)
+	fmt.Fprintf(os.Stdout, "fingerprint: %s\n", hex.EncodeToString(fp[:]))
+	return 0
+}
+
+const helpTree = `# falcon tree
+
+Sign a directory tree once, and verify any file of it on its own.
+
+tree sign hashes every file under a directory into a Merkle tree and signs
+its root. Each file gets a proof holding the signed root and the log2(n)
+hashes linking the file to it, so a single file verifies without the others
+or a manifest of them.
+
+Usage:
+  falcon tree sign --key <file> --dir <dir> --proof-dir <dir> [--out <file>] [--hash <name>] [--mnemonic-passphrase <string>]
+  falcon tree verify --file <file> --proof <file> [--key <file>] [--path <path>]
+
+Subcommands:
+  sign      Sign the Merkle root of the files of a directory
+  verify    Check one file against its proof
+
+Arguments (sign):
+  --key <file>              keypair JSON (required, must include private key)
+  --dir <dir>               directory whose regular files are signed (required)
+  --proof-dir <dir>         write <path>` + treeProofSuffix + ` for each file here
+                            (required; skipped if inside --dir)
+  --out <file>              write the signed root JSON (stdout if omitted)
+  --hash <name>             sha512-256 (default), sha3-256 or blake2b-256
+  --mnemonic-passphrase     optional mnemonic passphrase when the key file omits it
+
+Arguments (verify):
+  --file <file>             file to verify (required)
+  --proof <file>            its proof (required)
+  --key <file>              require the root signed by this public key
+  --path <path>             require the file signed under this path
+
+Exit codes (verify): 0 VALID, 1 INVALID, 2 usage or parse errors.
+
+Examples:
+  falcon tree sign --key mykeys.json --dir dist --proof-dir proofs --out dist.root.json
+  falcon tree verify --file dist/lib/app.so --proof proofs/lib/app.so.proof.json --key release.pub.json
+`
@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/hashing"
+)
+
+// TestTreeProofs checks every proof of trees of 1 to 9 leaves, and that a
+// proof for another index or with a sibling too many fails.
+func TestTreeProofs(t *testing.T) {
+	hash := hashing.Default
+	for n := 1; n <= 9; n++ {
+		leaves := make([][]byte, n)
+		for i := range leaves {
+			leaves[i] = treeLeaf(hash, fmt.Sprintf("f%d", i), []byte{byte(i)})
+		}
+		levels := treeLevels(hash, leaves)
+		root := levels[len(levels)-1][0]
+		for i := range leaves {
+			siblings := treeProof(levels, i)
+			got, err := treeRootFromProof(hash, leaves[i], i, n, siblings)
+			if err != nil || !bytes.Equal(got, root) {
+				t.Fatalf("n=%d, leaf %d: root %x, %v; want %x", n, i, got, err, root)
+			}
+			if n > 1 {
+				other := (i + 1) % n
+				if got, err := treeRootFromProof(hash, leaves[i], other, n, siblings); err == nil && bytes.Equal(got, root) {
+					t.Fatalf("n=%d: leaf %d verifies at index %d", n, i, other)
+				}
+			}
+			if _, err := treeRootFromProof(hash, leaves[i], i, n, append(siblings, root)); err == nil {
+				t.Fatalf("n=%d, leaf %d: accepted an extra sibling", n, i)
+			}
+		}
+		if _, err := treeRootFromProof(hash, leaves[0], n, n, nil); err == nil {
+			t.Fatalf("n=%d: accepted an index outside the tree", n)
+		}
+	}
+}
+
+// TestRunTree_SignAndVerify signs a directory and verifies single files,
+// and detects changed files, moved files and other signers.
+func TestRunTree_SignAndVerify(t *testing.T) {
+	dir := t.TempDir()
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("tree test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	other, err := falcongo.GenerateKeyPair(deriveSeed([]byte("tree other seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+	pubPath := writeKeypairJSON(t, dir, "keys.pub.json", kp, false)
+	otherPath := writeKeypairJSON(t, dir, "other.pub.json", other, false)
+
+	tree := filepath.Join(dir, "dist")
+	for name, content := range map[string]string{
+		"a.txt":        "alpha",
+		"b.txt":        "beta",
+		"lib/app.so":   "binary",
+		"lib/z/c.json": "{}",
+		"lib/z/d":      "delta",
+	} {
+		path := filepath.Join(tree, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	// Proofs and root inside the tree are not signed themselves.
+	proofDir := filepath.Join(tree, "proofs")
+	rootPath := filepath.Join(tree, "root.json")
+
+	var code int
+	_, stderr := captureStdoutStderr(t, func() {
+		code = runTreeSign([]string{"--key", keyPath, "--dir", tree, "--proof-dir", proofDir, "--out", rootPath})
+	})
+	if code != 0 {
+		t.Fatalf("expected exit 0 from sign, got %d: %s", code, stderr)
+	}
+	if !strings.Contains(stderr, "signed 5 files") {
+		t.Fatalf("unexpected sign output: %s", stderr)
+	}
+	data, err := os.ReadFile(rootPath)
+	if err != nil {
+		t.Fatalf("failed to read the root: %v", err)
+	}
+	var root treeRootJSON
+	if err := json.Unmarshal(data, &root); err != nil || root.Leaves != 5 || root.Hash != string(hashing.Default) {
+		t.Fatalf("unexpected root %s (%v)", data, err)
+	}
+
+	file := filepath.Join(tree, "lib", "app.so")
+	proof := filepath.Join(proofDir, "lib", "app.so"+treeProofSuffix)
+	stdout, _ := captureStdoutStderr(t, func() {
+		code = runTreeVerify([]string{"--file", file, "--proof", proof, "--key", pubPath, "--path", "lib/app.so"})
+	})
+	if code != 0 || !strings.Contains(stdout, "VALID") || !strings.Contains(stdout, "path: lib/app.so") ||
+		!strings.Contains(stdout, "root: "+root.Root) {
+		t.Fatalf("expected VALID/0, got %d: %s", code, stdout)
+	}
+
+	for _, tc := range []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"other file", []string{"--file", filepath.Join(tree, "a.txt"), "--proof", proof},
+			"not the one signed under this path"},
+		{"other signer", []string{"--file", file, "--proof", proof, "--key", otherPath},
+			"signed by a different key"},
+		{"other path", []string{"--file", file, "--proof", proof, "--path", "a.txt"},
+			"signed as lib/app.so, not a.txt"},
+	} {
+		stdout, _ := captureStdoutStderr(t, func() { code = runTreeVerify(tc.args) })
+		if code != 1 || !strings.HasPrefix(stdout, "INVALID: ") || !strings.Contains(stdout, tc.want) {
+			t.Errorf("%s: expected INVALID/1 (%s), got %d: %s", tc.name, tc.want, code, stdout)
+		}
+	}
+
+	// A proof whose path or leaf count was changed no longer verifies.
+	var p treeProofJSON
+	data, err = os.ReadFile(proof)
+	if err != nil || json.Unmarshal(data, &p) != nil {
+		t.Fatalf("failed to read the proof: %v", err)
+	}
+	for name, mutate := range map[string]func(*treeProofJSON){
+		"path":   func(p *treeProofJSON) { p.Path = "lib/evil.so" },
+		"leaves": func(p *treeProofJSON) { p.Root.Leaves = 4 },
+	} {
+		q := p
+		mutate(&q)
+		b, _ := json.Marshal(q)
+		path := filepath.Join(dir, name+".proof.json")
+		if err := os.WriteFile(path, b, 0o644); err != nil {
+			t.Fatalf("write proof: %v", err)
+		}
+		stdout, _ := captureStdoutStderr(t, func() { code = runTreeVerify([]string{"--file", file, "--proof", path}) })
+		if code != 1 || !strings.HasPrefix(stdout, "INVALID") {
+			t.Errorf("%s: expected INVALID/1, got %d: %s", name, code, stdout)
+		}
+	}
+}
+
+// TestRunTree_Usage covers the usage errors of tree sign and tree verify.
+func TestRunTree_Usage(t *testing.T) {
+	dir := t.TempDir()
+	for _, tc := range []struct {
+		name string
+		run  func([]string) int
+		args []string
+	}{
+		{"sign without key", runTreeSign, []string{"--dir", dir, "--proof-dir", dir}},
+		{"sign without proof dir", runTreeSign, []string{"--key", "k.json", "--dir", dir}},
+		{"sign sha256", runTreeSign, []string{"--key", "k.json", "--dir", dir, "--proof-dir", dir, "--hash", "sha256"}},
+		{"verify without proof", runTreeVerify, []string{"--file", "f"}},
+		{"verify missing proof", runTreeVerify, []string{"--file", "f", "--proof", filepath.Join(dir, "none")}},
+	} {
+		var code int
+		_, _ = captureStdoutStderr(t, func() { code = tc.run(tc.args) })
+		if code != 2 {
+			t.Errorf("%s: expected exit 2, got %d", tc.name, code)
+		}
+	}
+}
@@ -34,14 +34,97 @@ func parseHex(s string) ([]byte, error) {
 	return dst[:n], nil
 }
 
-// loadKeypairFile reads key material and returns decoded keys,
-// optionally regenerating them from a mnemonic.
+// extractGlobalBoolFlag removes the boolean flag name ("--name", also
+// accepted as "-name") from args, up to a "--" argument, and reports whether
+// it was given. Flags that apply to every command are taken out this way
+// before the command parses its own.
+func extractGlobalBoolFlag(args []string, name string) (rest []string, found bool) {
+	for i, a := range args {
+		if a == "--" {
+			return append(rest, args[i:]...), found
+		}
+		if a == name || a == name[1:] {
+			found = true
+			continue
+		}
+		rest = append(rest, a)
+	}
+	return rest, found
+}
+
+// keyFileFormatVersion is the key file format this build reads and writes.
+// Bump it whenever key files gain a field that older builds must not ignore
+// (e.g. encrypted key material or usage policies), and write it as
+// min_reader_version only into files that use such a field.
+const keyFileFormatVersion = keyFileFormatPolicy
+
+// Key file formats: keyFileFormatPolicy added message_policy.
+const (
+	keyFileFormatBase   = 1
+	keyFileFormatPolicy = 2
+)
+
+// keyFileFormat returns the format needed to read k.
+func keyFileFormat(k keyPairJSON) int {
+	if k.MessagePolicy != nil {
+		return keyFileFormatPolicy
+	}
+	return keyFileFormatBase
+}
+
+// stampKeyFile records the writing build and the format needed to read k.
+func stampKeyFile(k *keyPairJSON) {
+	k.CreatedBy = "falcon " + buildVersion()
+	k.MinReaderVersion = keyFileFormat(*k)
+}
+
+// checkKeyFileVersion refuses key file contents that declare a newer format
+// than this build understands. Malformed JSON is left for the caller's
+// decoder to report.
+func checkKeyFileVersion(b []byte) error {
+	var hdr struct {
+		CreatedBy        string `json:"created_by"`
+		MinReaderVersion int    `json:"min_reader_version"`
+	}
+	if json.Unmarshal(b, &hdr) != nil || hdr.MinReaderVersion <= keyFileFormatVersion {
+		return nil
+	}
+	createdBy := ""
+	if hdr.CreatedBy != "" {
+		createdBy = fmt.Sprintf(" (written by %s)", hdr.CreatedBy)
+	}
+	return fmt.Errorf("key file requires format version %d but falcon %s reads up to "+
+		"version %d%s; upgrade falcon to use it", hdr.MinReaderVersion, buildVersion(),
+		keyFileFormatVersion, createdBy)
+}
+
+// publicKeyFromPrivate recomputes the encoded public key of an encoded
+// private key.
+func publicKeyFromPrivate(priv []byte) ([]byte, error) {
+	var sk falcongo.PrivateKey
+	if len(priv) != len(sk) {
+		return nil, falcongo.ErrInvalidPrivateKey
+	}
+	copy(sk[:], priv)
+	pk, err := falcongo.PublicKeyFromPrivate(sk)
+	if err != nil {
+		return nil, err
+	}
+	return pk[:], nil
+}
+
+// loadKeypairFile reads key material from a key file or key source (see
+// keySources) and returns decoded keys, optionally regenerating them from a
+// mnemonic.
 func loadKeypairFile(path string, overridePassphrase *string,
 ) (pub []byte, priv []byte, meta keyPairJSON, err error) {
-	b, err := os.ReadFile(path)
+	b, err := readKeySource(path)
 	if err != nil {
 		return nil, nil, keyPairJSON{}, err
 	}
+	if err := checkKeyFileVersion(b); err != nil {
+		return nil, nil, keyPairJSON{}, err
+	}
 	if err := json.Unmarshal(b, &meta); err != nil {
 		return nil, nil, keyPairJSON{}, fmt.Errorf("invalid JSON: %w", err)
 	}
@@ -71,7 +154,8 @@ func loadKeypairFile(path string, overridePassphrase *string,
 
 	mnemonicPass := meta.MnemonicPassphrase
 	if overrideProvided {
-		if mnemonicPass != "" && mnemonicPass != overrideValue {
+		if mnemonicPass != "" &&
+			!falcongo.SecretsEqual([]byte(mnemonicPass), []byte(overrideValue)) {
 			return nil, nil, keyPairJSON{},
 				fmt.Errorf("mnemonic passphrase mismatch between file and flag")
 		}
@@ -108,13 +192,13 @@ func loadKeypairFile(path string, overridePassphrase *string,
 
 		if privBytes == nil {
 			privBytes = derivedPriv
-		} else if !bytes.Equal(privBytes, derivedPriv) {
+		} else if !falcongo.SecretsEqual(privBytes, derivedPriv) {
 			return nil, nil, keyPairJSON{},
 				fmt.Errorf("mnemonic does not match private key material")
 		}
 		if pubBytes == nil {
 			pubBytes = derivedPub
-		} else if !bytes.Equal(pubBytes, derivedPub) {
+		} else if !falcongo.SecretsEqual(pubBytes, derivedPub) {
 			return nil, nil, keyPairJSON{},
 				fmt.Errorf("mnemonic does not match public key material")
 		}
@@ -126,6 +210,79 @@ func loadKeypairFile(path string, overridePassphrase *string,
 	return pubBytes, privBytes, meta, nil
 }
 
+// loadSigningKey loads the keypair of the key file given as flagName, which
+// must include the private key for purpose, and checks that it may sign. The
+// public key is derived from the private key, and a stored public_key that
+// differs is refused before any check, so that revocation, second factors
+// and what is signed in the key's name all concern the key that signs. Keys
+// with a message_policy are refused unless enforcesPolicy. It returns a
+// non-zero exit code after reporting an error.
+func loadSigningKey(flagName, path string, override *string, purpose string,
+	enforcesPolicy bool) (falcongo.KeyPair, keyPairJSON, int) {
+	var kp falcongo.KeyPair
+	pub, priv, meta, err := loadKeypairFile(path, override)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", flagName, err)
+		return kp, meta, 2
+	}
+	if priv == nil {
+		fmt.Fprintf(os.Stderr, "private key not found in %s (required for %s)\n", path, purpose)
+		return kp, meta, 2
+	}
+	derived, err := publicKeyFromPrivate(priv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read %s: %v\n", flagName, err)
+		return kp, meta, 2
+	}
+	if pub != nil && !bytes.Equal(pub, derived) {
+		fmt.Fprintf(os.Stderr, "public_key does not match private_key in %s (see 'falcon keys check')\n", path)
+		return kp, meta, 2
+	}
+	if (!enforcesPolicy && refuseRestrictedKey(meta)) || refuseUnapprovedKey(derived) {
+		return kp, meta, 2
+	}
+	copy(kp.PublicKey[:], derived)
+	copy(kp.PrivateKey[:], priv)
+	wipeBytes(priv)
+	return kp, meta, 0
+}
+
+// loadSigningKeyPair is loadSigningKey for commands that do not enforce
+// message policies.
+func loadSigningKeyPair(flagName, path string, override *string, purpose string) (falcongo.KeyPair, int) {
+	kp, _, code := loadSigningKey(flagName, path, override, purpose, false)
+	return kp, code
+}
+
+// keyFileFingerprint returns the fingerprint of the public key of the key
+// file or key source path, and whether it has one. A stored public_key is
+// hashed as it is decoded, without loading the keys; other files are loaded
+// to derive it from their mnemonic. It does not check the rest of the file.
+func keyFileFingerprint(path string, override *string) ([32]byte, bool, error) {
+	b, err := readKeySource(path)
+	if err != nil {
+		return [32]byte{}, false, err
+	}
+	if err := checkKeyFileVersion(b); err != nil {
+		return [32]byte{}, false, err
+	}
+	var meta struct {
+		PublicKey string `json:"public_key"`
+	}
+	if err := json.Unmarshal(b, &meta); err != nil {
+		return [32]byte{}, false, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if meta.PublicKey != "" {
+		fp, err := falcongo.FingerprintFromHex(meta.PublicKey)
+		return fp, err == nil, err
+	}
+	pub, _, _, err := loadKeypairFile(path, override)
+	if err != nil || len(pub) != falcongo.PublicKeySize {
+		return [32]byte{}, false, err
+	}
+	return falcongo.Fingerprint(falcongo.PublicKey(pub)), true, nil
+}
+
 func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
 	if path == "" {
 		return errors.New("empty path")
@@ -138,9 +295,15 @@ func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
 		return err
 	}
 	name := tf.Name()
+	renamed := false
 	defer func() {
 		tf.Close()
-		os.Remove(name)
+		if !renamed {
+			// The temp file may hold key material; overwrite it before removal.
+			if wipeFile(name) != nil {
+				os.Remove(name)
+			}
+		}
 	}()
 	if _, err := tf.Write(data); err != nil {
 		return err
@@ -160,6 +323,7 @@ func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
 	if err := os.Rename(name, path); err != nil {
 		return err
 	}
+	renamed = true
 	// Best-effort directory sync on POSIX
 	if df, err := os.Open(dir); err == nil {
 		_ = df.Sync()
@@ -167,3 +331,40 @@ func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
 	}
 	return nil
 }
+
+// wipeFile overwrites the contents of the regular file at path with zeros,
+// syncs it, and removes it. This is best effort: filesystems with
+// copy-on-write, journaling of data, or wear leveling may keep old copies.
+func wipeFile(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", path)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	zeros := make([]byte, 4096)
+	for remaining := info.Size(); remaining > 0; {
+		n := int64(len(zeros))
+		if remaining < n {
+			n = remaining
+		}
+		if _, err := f.Write(zeros[:n]); err != nil {
+			f.Close()
+			return err
+		}
+		remaining -= n
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/algorandfoundation/falcon-signatures/falcongo"
 	"github.com/algorandfoundation/falcon-signatures/mnemonic"
@@ -121,3 +122,189 @@ func deriveKeyPair(t *testing.T, words []string, pass string) falcongo.KeyPair {
 	}
 	return kp
 }
+
+// TestLoadKeypairFile_MnemonicKeyMismatch ensures the constant-time consistency
+// check still rejects key material that does not match the mnemonic.
+func TestLoadKeypairFile_MnemonicKeyMismatch(t *testing.T) {
+	dir := t.TempDir()
+	words := strings.Fields("legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth title")
+	expected := deriveKeyPair(t, words, "")
+
+	privHex := hex.EncodeToString(expected.PrivateKey[:])
+	// Flip only the last nibble so the mismatch sits at the very end.
+	last := privHex[len(privHex)-1]
+	flipped := byte('0')
+	if last == '0' {
+		flipped = '1'
+	}
+	tamperedPriv := privHex[:len(privHex)-1] + string(flipped)
+
+	testCases := []struct {
+		name string
+		obj  keyPairJSON
+		want string
+	}{
+		{
+			name: "private key",
+			obj:  keyPairJSON{PrivateKey: tamperedPriv, Mnemonic: strings.Join(words, " ")},
+			want: "mnemonic does not match private key material",
+		},
+		{
+			name: "public key",
+			obj:  keyPairJSON{PublicKey: strings.Repeat("00", len(expected.PublicKey)), Mnemonic: strings.Join(words, " ")},
+			want: "mnemonic does not match public key material",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := json.Marshal(tc.obj)
+			if err != nil {
+				t.Fatalf("marshal key json: %v", err)
+			}
+			path := writeTempFile(t, dir, strings.ReplaceAll(tc.name, " ", "-")+".json", b)
+			empty := ""
+			_, _, _, err = loadKeypairFile(path, &empty)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected %q error, got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+// TestLoadKeypairFile_MinReaderVersion refuses files from a newer format and
+// stamps files written by this build.
+func TestLoadKeypairFile_MinReaderVersion(t *testing.T) {
+	dir := t.TempDir()
+	newer := writeTempFile(t, dir, "newer.json", []byte(`{"created_by":"falcon v9.0.0",`+
+		`"min_reader_version":99,"public_key":"aa","encrypted_private_key":"bb"}`))
+	_, _, _, err := loadKeypairFile(newer, nil)
+	if err == nil || !strings.Contains(err.Error(), "format version 99") ||
+		!strings.Contains(err.Error(), "falcon v9.0.0") {
+		t.Fatalf("expected newer format error, got %v", err)
+	}
+	if _, err := readKeyFileStrict(newer); err == nil ||
+		!strings.Contains(err.Error(), "format version 99") {
+		t.Fatalf("expected newer format error from strict reader, got %v", err)
+	}
+
+	out := filepath.Join(dir, "created.json")
+	if code := runCreate([]string{"--no-mnemonic", "--out", out}); code != 0 {
+		t.Fatalf("create failed with %d", code)
+	}
+	_, _, meta, err := loadKeypairFile(out, nil)
+	if err != nil {
+		t.Fatalf("loadKeypairFile returned error: %v", err)
+	}
+	if meta.MinReaderVersion != keyFileFormatBase ||
+		meta.CreatedBy != "falcon "+buildVersion() {
+		t.Fatalf("unexpected version stamp: %q %d", meta.CreatedBy, meta.MinReaderVersion)
+	}
+}
+
+// TestKeyFileFingerprint fingerprints a stored public key without loading
+// the rest of the file, and derives it from a mnemonic otherwise.
+func TestKeyFileFingerprint(t *testing.T) {
+	dir := t.TempDir()
+	words := strings.Fields("legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth title")
+	kp := deriveKeyPair(t, words, "")
+	want := falcongo.Fingerprint(kp.PublicKey)
+
+	// The private key is not even hex: it is never decoded.
+	stored := writeTempFile(t, dir, "stored.json", legacyKeyJSON(hex.EncodeToString(kp.PublicKey[:]), "zz"))
+	mnemonicOnly, err := json.Marshal(keyPairJSON{Mnemonic: strings.Join(words, " ")})
+	if err != nil {
+		t.Fatalf("marshal mnemonic json: %v", err)
+	}
+	derived := writeTempFile(t, dir, "mnemonic.json", mnemonicOnly)
+	empty := ""
+	for _, path := range []string{stored, derived} {
+		fp, ok, err := keyFileFingerprint(path, &empty)
+		if err != nil || !ok || fp != want {
+			t.Fatalf("%s: keyFileFingerprint = %x, %v, %v; want %x", filepath.Base(path), fp, ok, err, want)
+		}
+	}
+
+	privateOnly := writeTempFile(t, dir, "private.json", legacyKeyJSON("", "bb"))
+	if _, ok, err := keyFileFingerprint(privateOnly, nil); err != nil || ok {
+		t.Fatalf("private-only file: got ok %v, err %v; want no public key", ok, err)
+	}
+	short := writeTempFile(t, dir, "short.json", legacyKeyJSON("aa", ""))
+	if _, _, err := keyFileFingerprint(short, nil); err == nil {
+		t.Fatalf("expected an error on a truncated public key")
+	}
+}
+
+// TestWipeFile overwrites and removes regular files and refuses symlinks.
+func TestWipeFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "secret")
+	if err := os.WriteFile(path, []byte("secret material"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(path, link); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	if err := wipeFile(link); err == nil {
+		t.Fatalf("expected wipeFile to refuse a symlink")
+	}
+	if err := wipeFile(path); err != nil {
+		t.Fatalf("wipeFile failed: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected file to be removed, stat err=%v", err)
+	}
+}
+
+// TestLoadSigningKey_MismatchedPublicKey checks that a key file whose
+// public_key is not that of its private key is refused before the second
+// factor of either key is asked for, by every command that signs with it.
+func TestLoadSigningKey_MismatchedPublicKey(t *testing.T) {
+	dir := t.TempDir()
+	useTempUserConfigDir(t)
+	t.Setenv(envTOTPCode, "")
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("mismatched public key seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	other, err := falcongo.GenerateKeyPair(deriveSeed([]byte("mismatched public key other seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	k, _ := (&totpEnrollmentJSON{Method: secondFactorTOTP, Secret: secret, Digits: 6, Period: 30}).key()
+	var code int
+	captureStdoutStderr(t, func() {
+		code = runKeysTOTPEnroll([]string{"--key", keyPath, "--secret", secret, "--code", k.Code(k.Counter(time.Now()))})
+	})
+	if code != 0 {
+		t.Fatalf("enroll: exit %d", code)
+	}
+
+	// The enrolled private key, presented under another public key.
+	swapped := writeKeypairJSON(t, dir, "swapped.json",
+		falcongo.KeyPair{PublicKey: other.PublicKey, PrivateKey: kp.PrivateKey}, true)
+	challenge := filepath.Join(dir, "challenge.json")
+	captureStdout(t, func() {
+		code = runAuthChallenge([]string{"--service", "example.com", "--out", challenge})
+	})
+	if code != 0 {
+		t.Fatalf("auth challenge: exit %d", code)
+	}
+	for name, run := range map[string]func() int{
+		"sign":          func() int { return runSign([]string{"--key", swapped, "--msg", "hi"}) },
+		"auth respond":  func() int { return runAuthRespond([]string{"--key", swapped, "--challenge", challenge}) },
+		"csr create":    func() int { return runCSRCreate([]string{"--key", swapped, "--subject", "CN=x"}) },
+		"algorand send": func() int { return runAlgorandSend([]string{"--key", swapped, "--to", "x", "--amount", "1"}) },
+	} {
+		var stderr string
+		withStdin(t, "", func() {
+			_, stderr = captureStdoutStderr(t, func() { code = run() })
+		})
+		if code != 2 || !strings.Contains(stderr, "public_key does not match private_key") ||
+			strings.Contains(stderr, "TOTP code") {
+			t.Errorf("%s: expected a mismatch error, got exit %d, stderr %q", name, code, stderr)
+		}
+	}
+}
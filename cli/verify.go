@@ -1,12 +1,14 @@
 package cli
 
 import (
+	"bufio"
+	"encoding/hex"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
 	"strings"
 
-	"github.com/algorand/falcon"
 	"github.com/algorandfoundation/falcon-signatures/falcongo"
 )
 
@@ -17,19 +19,50 @@ func runVerify(args []string) int {
 	inFile := fs.String("in", "", "file containing message (alternative to --msg)")
 	msg := fs.String("msg", "", "inline message text (alternative to --in)")
 	hexIn := fs.Bool("hex", false, "treat message as hex-encoded bytes")
+	jsonCanon := fs.Bool("json-canonicalize", false, "verify against the RFC 8785 canonical form of a JSON message")
 	sigFile := fs.String("sig", "", "file containing signature bytes (alternative to --signature)")
-	sigHex := fs.String("signature", "", "hex-encoded signature (alternative to --sig)")
+	sigHex := fs.String("signature", "", "hex or base64 signature (alternative to --sig)")
+	sigEncoding := fs.String("sig-encoding", sigEncodingAuto, "encoding of --sig or --signature: auto, hex, base64, base64url or raw")
 	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
-	_ = fs.Parse(args)
+	commit := fs.Bool("commit", false, "signature was made with 'sign --commit' (commitment followed by signature)")
+	commitmentsLog := fs.String("commitments-log", "", "file of seen commitments; reject replays and record new ones (requires --commit)")
+	requireCT := fs.Bool("require-ct", false, "accept only fixed-length CT signatures")
+	policyPath := fs.String("require-attestation", "", "policy JSON the signing environment statement (sign --attest-env) must satisfy")
+	revocations := fs.String("revocations", "", "directory, file or URL of revocation statements (falcon revoke); report REVOKED keys")
+	stream := fs.Bool("stream", false, "verify a binary signature stream from --in or stdin")
+	keyRegistry := fs.String("key-registry", "", "directory, file or URL of public keys to resolve --key-ref and stream key fingerprints")
+	keyRef := fs.String("key-ref", "", "fingerprint of the key in --key-registry (alternative to --key)")
+	parseFlags(fs, args)
 	passphraseProvided := false
+	var streamConflicts []string
 	fs.Visit(func(f *flag.Flag) {
 		if f.Name == "mnemonic-passphrase" {
 			passphraseProvided = true
 		}
+		if f.Name != "stream" && f.Name != "in" && f.Name != "revocations" && f.Name != "key-registry" {
+			streamConflicts = append(streamConflicts, "--"+f.Name)
+		}
 	})
 
-	if *keyPath == "" {
-		fmt.Fprintf(os.Stderr, "--key is required\n")
+	if *stream {
+		if len(streamConflicts) > 0 {
+			fmt.Fprintf(os.Stderr, "--stream accepts only --in, --revocations and --key-registry, not %s\n",
+				strings.Join(streamConflicts, ", "))
+			return 2
+		}
+		return runVerifyStream(*inFile, *revocations, *keyRegistry)
+	}
+
+	if *keyPath == "" && *keyRef == "" {
+		fmt.Fprintf(os.Stderr, "--key is required (or --key-ref with --key-registry)\n")
+		return 2
+	}
+	if *keyPath != "" && *keyRef != "" {
+		fmt.Fprintf(os.Stderr, "provide only one of --key or --key-ref\n")
+		return 2
+	}
+	if (*keyRef != "") != (*keyRegistry != "") {
+		fmt.Fprintf(os.Stderr, "--key-ref and --key-registry go together\n")
 		return 2
 	}
 	if (*inFile == "" && *msg == "") || (*inFile != "" && *msg != "") {
@@ -40,20 +73,83 @@ func runVerify(args []string) int {
 		fmt.Fprintf(os.Stderr, "provide exactly one of --sig or --signature\n")
 		return 2
 	}
-
-	var override *string
-	if passphraseProvided {
-		override = mnemonicPassphrase
+	if *jsonCanon && *hexIn {
+		fmt.Fprintf(os.Stderr, "cannot combine --json-canonicalize with --hex\n")
+		return 2
 	}
-	pub, _, _, err := loadKeypairFile(*keyPath, override)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
+	if err := checkSigEncoding(*sigEncoding, true); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
 		return 2
 	}
-	if pub == nil {
-		fmt.Fprintf(os.Stderr, "public key not found in %s\n", *keyPath)
+	if *sigHex != "" && *sigEncoding == sigEncodingRaw {
+		fmt.Fprintf(os.Stderr, "--sig-encoding raw applies to --sig files only\n")
 		return 2
 	}
+	if *commitmentsLog != "" && !*commit {
+		fmt.Fprintf(os.Stderr, "--commitments-log requires --commit\n")
+		return 2
+	}
+
+	var policy *envPolicyJSON
+	if *policyPath != "" {
+		p, err := readEnvPolicy(*policyPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read --require-attestation: %v\n", err)
+			return 2
+		}
+		policy = &p
+	}
+
+	var pub []byte
+	var meta keyPairJSON
+	var err error
+	if *keyRef != "" {
+		registry, err := loadKeyRegistry(*keyRegistry)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read --key-registry: %v\n", err)
+			return 2
+		}
+		pk, err := registry.Resolve(*keyRef)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --key-ref: %v\n", err)
+			return 2
+		}
+		pub = pk[:]
+	} else {
+		var override *string
+		if passphraseProvided {
+			override = mnemonicPassphrase
+		}
+		pub, _, meta, err = loadKeypairFile(*keyPath, override)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
+			return 2
+		}
+		if pub == nil {
+			fmt.Fprintf(os.Stderr, "public key not found in %s\n", *keyPath)
+			return 2
+		}
+	}
+	// A corrupted public key would report every signature as invalid.
+	if meta.KeyAttestation != nil {
+		if err := checkKeyAttestation(meta.KeyAttestation, pub, nil); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --key %s: %v\n", *keyPath, err)
+			return 2
+		}
+	}
+	var revocation *revocationJSON
+	if *revocations != "" {
+		revoked, err := loadRevocations(*revocations)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read --revocations: %v\n", err)
+			return 2
+		}
+		var pk falcongo.PublicKey
+		copy(pk[:], pub)
+		if r, ok := revoked[pk]; ok {
+			revocation = &r
+		}
+	}
 
 	// Message
 	var msgBytes []byte
@@ -84,6 +180,12 @@ func runVerify(args []string) int {
 			msgBytes = []byte(*msg)
 		}
 	}
+	if *jsonCanon {
+		if msgBytes, err = falcongo.CanonicalizeJSON(msgBytes); err != nil {
+			fmt.Fprintf(os.Stderr, "cannot canonicalize message: %v\n", err)
+			return 2
+		}
+	}
 
 	// Signature
 	var sigBytes []byte
@@ -93,41 +195,294 @@ func runVerify(args []string) int {
 			fmt.Fprintf(os.Stderr, "failed to read --sig: %v\n", err)
 			return 2
 		}
-		sigBytes = b
+		if sigBytes, err = decodeSignature(b, *sigEncoding, true); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --sig %s: %v\n", *sigEncoding, err)
+			return 2
+		}
 	} else {
-		b, err := parseHex(*sigHex)
+		b, err := decodeSignature([]byte(*sigHex), *sigEncoding, false)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "invalid --signature hex: %v\n", err)
+			fmt.Fprintf(os.Stderr, "invalid --signature %s: %v\n", signatureTextEncodings(*sigEncoding), err)
 			return 2
 		}
 		sigBytes = b
 	}
 
+	// The output container is [statement] [commitment] signature, and the
+	// signed payload wraps the message in the reverse order.
+	signedMsg := msgBytes
+	var statement []byte
+	var env envStatementJSON
+	if policy != nil {
+		statement, env, sigBytes, err = splitEnvContainer(sigBytes)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			fmt.Fprintln(os.Stdout, "INVALID")
+			return 1
+		}
+	}
+
+	var commitment []byte
+	if *commit {
+		if len(sigBytes) <= commitmentSize {
+			fmt.Fprintln(os.Stdout, "INVALID")
+			return 1
+		}
+		commitment, sigBytes = sigBytes[:commitmentSize], sigBytes[commitmentSize:]
+		msgBytes = committedMessage(commitment, msgBytes)
+	}
+	if statement != nil {
+		// splitEnvContainer checked the hash.
+		hash, _ := env.hash()
+		msgBytes = attestedMessage(hash, statement, msgBytes)
+	}
+
 	// Verify
 	var pk falcongo.KeyPair
 	copy(pk.PublicKey[:], pub)
-	err = falcongo.Verify(msgBytes, falcon.CompressedSignature(sigBytes), pk.PublicKey)
+	// The signature is bare or carries its form (falcongo.Signature); either
+	// way it must be in the required form.
+	form := falcongo.FormCompressed
+	if *requireCT {
+		form = falcongo.FormCT
+	}
+	var sig falcongo.Signature
+	if err = sig.UnmarshalBinary(sigBytes); err == nil {
+		err = falcongo.VerifyStrict(form, msgBytes, sig.Bytes(), pk.PublicKey)
+	}
 	if err != nil {
+		if errors.Is(err, falcongo.ErrSignatureForm) || errors.Is(err, falcongo.ErrInvalidSignatureEncoding) {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
 		fmt.Fprintln(os.Stdout, "INVALID")
 		return 1
 	}
+	if policy != nil {
+		if err := policy.check(env, pub, signedMsg); err != nil {
+			fmt.Fprintf(os.Stderr, "attestation policy: %v\n", err)
+			fmt.Fprintln(os.Stdout, "INVALID")
+			return 1
+		}
+	}
+	if revocation != nil {
+		// Signatures carry no time, so none made by a revoked key is trusted.
+		fmt.Fprintln(os.Stdout, "REVOKED")
+		fmt.Fprintf(os.Stdout, "revoked: %s (%s)\n", revocation.RevokedAt, revocation.Reason)
+		return 1
+	}
+	if commitment == nil {
+		fmt.Fprintln(os.Stdout, "VALID")
+		printEnvironment(statement, env)
+		return 0
+	}
+
+	commitmentHex := hex.EncodeToString(commitment)
+	if *commitmentsLog != "" {
+		seen, err := recordCommitment(*commitmentsLog, commitmentHex)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to update --commitments-log: %v\n", err)
+			return 2
+		}
+		if seen {
+			fmt.Fprintln(os.Stdout, "REPLAYED")
+			fmt.Fprintf(os.Stdout, "commitment: %s\n", commitmentHex)
+			return 1
+		}
+	}
 	fmt.Fprintln(os.Stdout, "VALID")
+	fmt.Fprintf(os.Stdout, "commitment: %s\n", commitmentHex)
+	printEnvironment(statement, env)
+	return 0
+}
+
+// runVerifyStream verifies the signature stream (see falcongo.VerifyStream)
+// read from inFile, or stdin if it is empty, resolving key fingerprints with
+// the keys of keyRegistry. It prints a line for each record that is not valid
+// and a summary, and returns 1 if any record is not valid.
+func runVerifyStream(inFile, revocations, keyRegistry string) int {
+	var revoked map[falcongo.PublicKey]revocationJSON
+	if revocations != "" {
+		var err error
+		if revoked, err = loadRevocations(revocations); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read --revocations: %v\n", err)
+			return 2
+		}
+	}
+	var registry *falcongo.KeyRegistry
+	if keyRegistry != "" {
+		var err error
+		if registry, err = loadKeyRegistry(keyRegistry); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read --key-registry: %v\n", err)
+			return 2
+		}
+	}
+	in := os.Stdin
+	if inFile != "" && inFile != "-" {
+		f, err := os.Open(inFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read --in: %v\n", err)
+			return 2
+		}
+		defer f.Close()
+		in = f
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+	var valid, invalid, revokedCount uint64
+	for res, err := range falcongo.VerifyStreamWithRegistry(in, registry) {
+		if err != nil {
+			out.Flush()
+			fmt.Fprintf(os.Stderr, "%v (after %d records)\n", err, valid+invalid+revokedCount)
+			return 2
+		}
+		switch r, isRevoked := revoked[res.PublicKey]; {
+		case res.Err != nil:
+			invalid++
+			fmt.Fprintf(out, "INVALID %d\n", res.Index)
+		case isRevoked:
+			revokedCount++
+			fmt.Fprintf(out, "REVOKED %d key %d: %s (%s)\n", res.Index, res.KeyRef, r.RevokedAt, r.Reason)
+		default:
+			valid++
+		}
+	}
+	fmt.Fprintf(out, "records: %d, valid: %d, invalid: %d, revoked: %d\n",
+		valid+invalid+revokedCount, valid, invalid, revokedCount)
+	if invalid+revokedCount > 0 {
+		return 1
+	}
 	return 0
 }
 
+// loadKeyRegistry reads the public keys of source, a directory of *.json key
+// files, a key file or list of them, or an http(s) URL serving one.
+func loadKeyRegistry(source string) (*falcongo.KeyRegistry, error) {
+	registry := new(falcongo.KeyRegistry)
+	n, err := registry.Load(source)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("no public keys in %s", source)
+	}
+	return registry, nil
+}
+
+// printEnvironment prints the verified environment statement, if any.
+func printEnvironment(statement []byte, env envStatementJSON) {
+	if statement != nil {
+		fmt.Fprintf(os.Stdout, "environment: %s\n", env.describe())
+	}
+}
+
+// recordCommitment reports whether commitment is already listed in the log
+// file (one hex commitment per line) and appends it when it is not.
+func recordCommitment(path, commitment string) (seen bool, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if strings.EqualFold(strings.TrimSpace(line), commitment) {
+			return true, nil
+		}
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return false, err
+	}
+	if _, err := fmt.Fprintln(f, commitment); err != nil {
+		f.Close()
+		return false, err
+	}
+	return false, f.Close()
+}
+
 const helpVerify = `# falcon verify
 
 Verify a FALCON-1024 signature.
 
 Arguments:
   --key <file>         keypair/public key JSON file
+  --key-ref <fingerprint> --key-registry <dir|file|url>
+                       instead of --key: the key with this fingerprint (64 hex
+                       digits, see 'falcon info') among the public keys of a
+                       directory of *.json key files, a key file or a JSON array
+                       of them, or an http(s) URL serving one
   --in <file>  | --msg <string>
-  --sig <file> | --signature <hex>
+  --sig <file> | --signature <hex|base64>
+  --sig-encoding <auto|hex|base64|base64url|raw>
+                       encoding of the signature (default: auto: hex, base64 or
+                       base64url text, otherwise raw bytes in a --sig file)
   --hex                treat message as hex-encoded (utf-8 if omitted)
+  --json-canonicalize  the message is JSON signed with 'sign --json-canonicalize';
+                       verify against its RFC 8785 canonical form
   --mnemonic-passphrase <string>
                        mnemonic passphrase when the key file omits it
+  --commit             the signature was made with 'sign --commit'; prints the
+                       commitment after VALID
+  --commitments-log <file>
+                       with --commit: reject a commitment already listed in the
+                       file (prints REPLAYED, exit 1), otherwise append it
+  --require-attestation <policy.json>
+                       the signature was made with 'sign --attest-env': verify the
+                       signed environment statement and check it against the policy
+                       (prints 'environment: ...' after VALID; INVALID if it fails)
+  --revocations <dir|file|url>
+                       revocation statements made with 'falcon revoke': a directory
+                       of *.json files, a file or an http(s) URL, each holding a
+                       statement or an array of them; a valid signature by a revoked
+                       key prints REVOKED and the revocation, exit 1
+  --require-ct         accept only fixed-length CT signatures (1538 bytes, header
+                       0xda) instead of compressed ones; a signature in another
+                       encoding is INVALID and the reason is printed to stderr
+  --stream             verify a binary signature stream read from --in or stdin
+                       instead of one signature (see 'falcon help verify-stream');
+                       prints 'INVALID <n>' or 'REVOKED <n> ...' for each record n
+                       that is not valid, then a summary; exit 1 if any is not valid.
+                       Only --in, --revocations and --key-registry may be combined
+                       with it
 
 Examples:
   falcon verify --key pubkey.json --in message.txt --sig signature.sig
   falcon verify --key pubkey.json --msg deadbeef --hex --signature abcd1234...
+  falcon verify --key pubkey.json --msg "attest v1" --sig attest.sig --commit --commitments-log seen.txt
+  falcon verify --key pubkey.json --in message.txt --sig signature.sig --revocations https://example.com/revoked.json
+  falcon verify --key-registry keys/ --key-ref 3f5a...c2 --in message.txt --sig signature.sig
+  produce-records | falcon verify --stream
+`
+
+const helpVerifyStream = `# falcon verify --stream
+
+Verify a binary stream of signatures, for log verification pipelines.
+
+Usage:
+  falcon verify --stream [--in <file>] [--revocations <dir|file|url>] [--key-registry <dir|file|url>]
+
+The stream (read from stdin without --in) starts with the 8 bytes
+"FALCONS\x01" and holds records, each a type byte and a body. Integers are
+big-endian:
+
+  0x01 key        the 1793-byte public key; it is referred to by the number
+                  of key records before it (0, 1, ...)
+  0x02 signature  uint32 key ref, uint32 message length, message (the signed
+                  bytes, typically a hash; at most 1 MiB), uint16 signature
+                  length, compressed signature
+  0x03 key fingerprint
+                  the 32-byte SHA-256 fingerprint of a public key of
+                  --key-registry, referred to like a key record; a fingerprint
+                  not in the registry ends the stream with exit code 2
+
+Each signature record that is not valid prints 'INVALID <n>', n counting
+signature records from 0; with --revocations, a valid signature by a revoked
+key prints 'REVOKED <n> key <ref>: <time> (<reason>)'. A summary line
+follows. The exit code is 0 if every record is valid, 1 otherwise, and 2 for
+a malformed stream. Records are verified in parallel. Go programs write
+streams with falcongo.NewStreamWriter.
+
+Examples:
+  falcon verify --stream --in records.bin
+  produce-records | falcon verify --stream --revocations revocations/
+  produce-records | falcon verify --stream --key-registry https://example.com/keys.json
 `
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
 	"os"
@@ -414,3 +415,270 @@ func TestRunVerify_MnemonicPassphraseRequired(t *testing.T) {
 		t.Fatalf("expected VALID, got %q", strings.TrimSpace(out))
 	}
 }
+
+// TestRunVerify_CommitMode checks commitment-mode signatures round-trip, are
+// distinguishable, and that replays are detected with a commitments log.
+func TestRunVerify_CommitMode(t *testing.T) {
+	dir := t.TempDir()
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("commit mode")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+
+	sign := func() string {
+		t.Helper()
+		var code int
+		out := captureStdout(t, func() {
+			code = runSign([]string{"--key", keyPath, "--msg", "attest", "--commit"})
+		})
+		if code != 0 {
+			t.Fatalf("expected sign exit 0, got %d", code)
+		}
+		return strings.TrimSpace(out)
+	}
+	sig1, sig2 := sign(), sign()
+	if sig1 == sig2 {
+		t.Fatalf("commitment-mode signatures over the same message must differ")
+	}
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runVerify([]string{"--key", keyPath, "--msg", "attest", "--signature", sig1})
+	})
+	if code != 1 {
+		t.Fatalf("expected plain verify of a commitment signature to fail, got %d %q", code, out)
+	}
+
+	log := filepath.Join(dir, "seen.txt")
+	verify := func(sig string) (int, string) {
+		var code int
+		out := captureStdout(t, func() {
+			code = runVerify([]string{"--key", keyPath, "--msg", "attest", "--signature", sig,
+				"--commit", "--commitments-log", log})
+		})
+		return code, out
+	}
+	code, out = verify(sig1)
+	if code != 0 || !strings.Contains(out, "VALID") ||
+		!strings.Contains(out, "commitment: "+sig1[:2*commitmentSize]) {
+		t.Fatalf("expected VALID with commitment, got %d %q", code, out)
+	}
+	if code, out = verify(sig2); code != 0 {
+		t.Fatalf("expected second commitment to be accepted, got %d %q", code, out)
+	}
+	if code, out = verify(sig1); code != 1 || !strings.Contains(out, "REPLAYED") {
+		t.Fatalf("expected REPLAYED for a reused commitment, got %d %q", code, out)
+	}
+
+	out = captureStdout(t, func() {
+		code = runVerify([]string{"--key", keyPath, "--msg", "other", "--signature", sig2, "--commit"})
+	})
+	if code != 1 || !strings.Contains(out, "INVALID") {
+		t.Fatalf("expected INVALID for a different message, got %d %q", code, out)
+	}
+}
+
+// TestRunVerify_RequireCT accepts CT signatures and rejects compressed ones
+// with the reason on stderr.
+func TestRunVerify_RequireCT(t *testing.T) {
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("unit test seed for verify ct")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	pubPath := writeKeypairJSON(t, t.TempDir(), "pub.json", kp, false)
+	msg := "hello ct"
+	sig, err := kp.Sign([]byte(msg))
+	if err != nil {
+		t.Fatalf("sign failed: %v", err)
+	}
+	ct, err := falcongo.GetFixedLengthSignature(sig)
+	if err != nil {
+		t.Fatalf("GetFixedLengthSignature failed: %v", err)
+	}
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runVerify([]string{"--key", pubPath, "--msg", msg,
+			"--signature", hex.EncodeToString(ct), "--require-ct"})
+	})
+	if code != 0 || strings.TrimSpace(out) != "VALID" {
+		t.Fatalf("expected VALID for CT signature, got %d %q", code, out)
+	}
+
+	out, stderr := captureStdoutStderr(t, func() {
+		code = runVerify([]string{"--key", pubPath, "--msg", msg,
+			"--signature", hex.EncodeToString(sig), "--require-ct"})
+	})
+	if code != 1 || strings.TrimSpace(out) != "INVALID" || !strings.Contains(stderr, "want ct") {
+		t.Fatalf("expected INVALID for compressed signature, got %d %q %q", code, out, stderr)
+	}
+
+	out = captureStdout(t, func() {
+		code = runVerify([]string{"--key", pubPath, "--msg", msg,
+			"--signature", hex.EncodeToString(ct)})
+	})
+	if code != 1 || strings.TrimSpace(out) != "INVALID" {
+		t.Fatalf("expected INVALID for CT signature without --require-ct, got %d %q", code, out)
+	}
+}
+
+func TestRunVerify_Stream(t *testing.T) {
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("unit test seed for verify stream")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	var buf bytes.Buffer
+	sw := falcongo.NewStreamWriter(&buf)
+	ref, err := sw.AddKey(kp.PublicKey)
+	if err != nil {
+		t.Fatalf("AddKey failed: %v", err)
+	}
+	for i, signed := range []string{"a", "tampered", "c"} {
+		sig, err := kp.Sign([]byte(signed))
+		if err != nil {
+			t.Fatalf("sign failed: %v", err)
+		}
+		if err := sw.WriteSignature(ref, []byte{"abc"[i]}, sig); err != nil {
+			t.Fatalf("WriteSignature failed: %v", err)
+		}
+	}
+	dir := t.TempDir()
+	streamPath := filepath.Join(dir, "records.bin")
+	if err := os.WriteFile(streamPath, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("write stream: %v", err)
+	}
+
+	var code int
+	out := captureStdout(t, func() { code = runVerify([]string{"--stream", "--in", streamPath}) })
+	want := "INVALID 1\nrecords: 3, valid: 2, invalid: 1, revoked: 0\n"
+	if code != 1 || out != want {
+		t.Fatalf("got %d %q, want 1 %q", code, out, want)
+	}
+
+	truncPath := filepath.Join(dir, "truncated.bin")
+	if err := os.WriteFile(truncPath, buf.Bytes()[:buf.Len()-1], 0o644); err != nil {
+		t.Fatalf("write stream: %v", err)
+	}
+	_, stderr := captureStdoutStderr(t, func() { code = runVerify([]string{"--stream", "--in", truncPath}) })
+	if code != 2 || !strings.Contains(stderr, "truncated") {
+		t.Fatalf("truncated stream: got %d %q", code, stderr)
+	}
+
+	stderr = captureStderr(t, func() { code = runVerify([]string{"--stream", "--in", streamPath, "--key", "k.json"}) })
+	if code != 2 || !strings.Contains(stderr, "--key") {
+		t.Fatalf("--stream with --key: got %d %q", code, stderr)
+	}
+}
+
+// TestRunVerify_TypedSignature accepts a signature with its version and form
+// bytes, and reports one in the wrong form.
+func TestRunVerify_TypedSignature(t *testing.T) {
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("unit test seed for typed signatures")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	pubPath := writeKeypairJSON(t, t.TempDir(), "pub.json", kp, false)
+	msg := "hello typed"
+	compressed, err := kp.Sign([]byte(msg))
+	if err != nil {
+		t.Fatalf("sign failed: %v", err)
+	}
+	sig, err := falcongo.NewSignature(compressed)
+	if err != nil {
+		t.Fatalf("NewSignature failed: %v", err)
+	}
+	text, err := sig.MarshalText()
+	if err != nil {
+		t.Fatalf("MarshalText failed: %v", err)
+	}
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runVerify([]string{"--key", pubPath, "--msg", msg, "--signature", string(text)})
+	})
+	if code != 0 || strings.TrimSpace(out) != "VALID" {
+		t.Fatalf("expected VALID, got %d %q", code, out)
+	}
+	out, stderr := captureStdoutStderr(t, func() {
+		code = runVerify([]string{"--key", pubPath, "--msg", msg, "--signature", string(text), "--require-ct"})
+	})
+	if code != 1 || strings.TrimSpace(out) != "INVALID" || !strings.Contains(stderr, "want ct") {
+		t.Fatalf("expected a form error, got %d %q %q", code, out, stderr)
+	}
+
+	out = captureStdout(t, func() { code = runInfo([]string{"--signature", string(text)}) })
+	if code != 0 || !strings.Contains(out, "encoding_version: 1") || !strings.Contains(out, "verifiable: yes") {
+		t.Fatalf("info of a typed signature: %d %q", code, out)
+	}
+}
+
+// TestRunVerify_KeyRegistry resolves the signer by fingerprint, for a
+// signature and in a stream.
+func TestRunVerify_KeyRegistry(t *testing.T) {
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("unit test seed for key registry")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	dir := t.TempDir()
+	keysDir := filepath.Join(dir, "keys")
+	if err := os.Mkdir(keysDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeKeypairJSON(t, keysDir, "signer.json", kp, false)
+	fp := falcongo.Fingerprint(kp.PublicKey)
+	ref := hex.EncodeToString(fp[:])
+	sig, err := kp.Sign([]byte("registry"))
+	if err != nil {
+		t.Fatalf("sign failed: %v", err)
+	}
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runVerify([]string{"--key-registry", keysDir, "--key-ref", ref, "--msg", "registry",
+			"--signature", hex.EncodeToString(sig)})
+	})
+	if code != 0 || strings.TrimSpace(out) != "VALID" {
+		t.Fatalf("expected VALID, got %d %q", code, out)
+	}
+
+	var buf bytes.Buffer
+	sw := falcongo.NewStreamWriter(&buf)
+	keyRef, err := sw.AddKeyFingerprint(fp)
+	if err != nil {
+		t.Fatalf("AddKeyFingerprint failed: %v", err)
+	}
+	if err := sw.WriteSignature(keyRef, []byte("registry"), sig); err != nil {
+		t.Fatalf("WriteSignature failed: %v", err)
+	}
+	streamPath := filepath.Join(dir, "records.bin")
+	if err := os.WriteFile(streamPath, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("write stream: %v", err)
+	}
+	out = captureStdout(t, func() {
+		code = runVerify([]string{"--stream", "--in", streamPath, "--key-registry", keysDir})
+	})
+	if code != 0 || out != "records: 1, valid: 1, invalid: 0, revoked: 0\n" {
+		t.Fatalf("stream: got %d %q", code, out)
+	}
+	_, stderr := captureStdoutStderr(t, func() { code = runVerify([]string{"--stream", "--in", streamPath}) })
+	if code != 2 || !strings.Contains(stderr, "unknown public key") {
+		t.Fatalf("stream without registry: got %d %q", code, stderr)
+	}
+
+	for name, args := range map[string][]string{
+		"unknown ref":      {"--key-registry", keysDir, "--key-ref", strings.Repeat("00", 32)},
+		"ref, no registry": {"--key-ref", ref},
+		"key and ref":      {"--key", "k.json", "--key-registry", keysDir, "--key-ref", ref},
+		"empty registry":   {"--key-registry", dir, "--key-ref", ref},
+		"missing registry": {"--key-registry", filepath.Join(dir, "nope"), "--key-ref", ref},
+		"registry, no ref": {"--key", "k.json", "--key-registry", keysDir},
+	} {
+		stderr := captureStderr(t, func() {
+			code = runVerify(append(args, "--msg", "registry", "--signature", hex.EncodeToString(sig)))
+		})
+		if code != 2 || stderr == "" {
+			t.Fatalf("%s: got %d %q", name, code, stderr)
+		}
+	}
+}
@@ -1,21 +1,41 @@
 package cli
 
 import (
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"runtime"
 	"runtime/debug"
+	"strings"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
 )
 
 // version holds the build version string injected at link-time.
 var version = "dev"
 
-// runVersion implements `falcon version` by printing the current build string.
+// runVersion implements `falcon version` by printing the current build string,
+// and with --verbose the crypto backend and platform.
 func runVersion(args []string) int {
-	if len(args) > 0 {
+	fs := flag.NewFlagSet("version", flag.ExitOnError)
+	verbose := fs.Bool("verbose", false, "also print the crypto backend, platform and build settings")
+	parseFlags(fs, args)
+	if fs.NArg() > 0 {
 		fmt.Fprintln(os.Stderr, "falcon version does not accept arguments")
 		return 2
 	}
 
+	fmt.Fprintln(os.Stdout, buildVersion())
+	if *verbose {
+		printCapabilities(os.Stdout)
+	}
+	return 0
+}
+
+// buildVersion returns the link-time version, falling back to the module
+// version recorded by go install, or "dev".
+func buildVersion() string {
 	builtVersion := version
 	if builtVersion == "" {
 		builtVersion = "dev"
@@ -27,9 +47,54 @@ func runVersion(args []string) int {
 			}
 		}
 	}
+	return builtVersion
+}
 
-	fmt.Fprintln(os.Stdout, builtVersion)
-	return 0
+// printCapabilities prints the report of falcongo.Capabilities and the
+// build settings that select the backend or tune the code.
+func printCapabilities(w io.Writer) {
+	c := falcongo.Capabilities()
+	signing := "available"
+	switch {
+	case !c.Signing:
+		signing = "unavailable (built without cgo or with the purego tag)"
+	case c.SigningDisabled:
+		signing = "disabled (read-only mode)"
+	}
+	features := "none"
+	if len(c.CPUFeatures) > 0 {
+		features = strings.Join(c.CPUFeatures, ", ")
+	}
+	fmt.Fprintf(w, "go: %s %s/%s\n", runtime.Version(), c.GOOS, c.GOARCH)
+	fmt.Fprintf(w, "cpus: %d\n", runtime.NumCPU())
+	fmt.Fprintf(w, "crypto backend: %s (verifier: %s)\n", c.Backend, c.Verifier)
+	fmt.Fprintf(w, "deterministic signing: %s\n", signing)
+	if c.FloatingPoint != "" {
+		fmt.Fprintf(w, "floating point: %s\n", c.FloatingPoint)
+	}
+	fmt.Fprintf(w, "cpu features in use: %s\n", features)
+	if settings := buildSettings(); len(settings) > 0 {
+		fmt.Fprintf(w, "build: %s\n", strings.Join(settings, " "))
+	}
+}
+
+// buildSettings returns the settings of the build that matter to the crypto
+// backend and to reproducing it, as key=value.
+func buildSettings() []string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return nil
+	}
+	var settings []string
+	for _, s := range info.Settings {
+		switch {
+		case s.Key == "CGO_ENABLED", s.Key == "-tags", s.Key == "-trimpath",
+			s.Key == "vcs.revision", s.Key == "vcs.modified",
+			strings.HasPrefix(s.Key, "GO") && s.Key != "GOOS" && s.Key != "GOARCH":
+			settings = append(settings, s.Key+"="+s.Value)
+		}
+	}
+	return settings
 }
 
 const helpVersion = `# falcon version
@@ -37,5 +102,12 @@ const helpVersion = `# falcon version
 Show the CLI build version. Local builds print "dev"; binaries installed via go install or release builds include their tagged version.
 
 Usage:
-  falcon version
+  falcon version [--verbose]
+
+Arguments:
+  --verbose   also print the Go version and platform, the crypto backend (cgo or
+              purego), whether deterministic signing is available, the CPU
+              features the FALCON code uses and the build settings (CGO_ENABLED,
+              build tags, GOAMD64 and the like, VCS revision); include it in bug
+              reports
 `
@@ -3,6 +3,8 @@ package cli
 import (
 	"strings"
 	"testing"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
 )
 
 // TestRunVersion_PrintsInjectedVersion confirms the injected build string is printed.
@@ -32,3 +34,23 @@ func TestRunVersion_WithArguments_Returns2(t *testing.T) {
 		t.Fatalf("unexpected stderr: %q", errOut)
 	}
 }
+
+// TestRunVersion_Verbose checks that --verbose reports the crypto backend.
+func TestRunVersion_Verbose(t *testing.T) {
+	var code int
+	out := captureStdout(t, func() { code = runVersion([]string{"--verbose"}) })
+	if code != 0 {
+		t.Fatalf("expected exit 0, got %d", code)
+	}
+	c := falcongo.Capabilities()
+	for _, want := range []string{
+		"crypto backend: " + c.Backend,
+		"deterministic signing: ",
+		"cpu features in use: none",
+		c.GOOS + "/" + c.GOARCH,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("missing %q in:\n%s", want, out)
+		}
+	}
+}
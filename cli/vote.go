@@ -0,0 +1,365 @@
+package cli
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// voteBallotJSON is a signed off-chain vote: the vote itself, the voter's
+// public key and the signature of voteSigningBytes(Vote).
+type voteBallotJSON struct {
+	Version   int         `json:"version"`
+	Algorithm string      `json:"algorithm"`
+	Vote      votePayload `json:"vote"`
+	PublicKey string      `json:"public_key"`
+	Signature string      `json:"signature"` // text of a falcongo.Signature
+}
+
+// votePayload is what a voter signs. The proposal ID is part of it, so a
+// ballot cannot be replayed for another proposal, and the voter fingerprint,
+// so it cannot be presented as another voter's.
+type votePayload struct {
+	ProposalID string `json:"proposal_id"`
+	Choice     string `json:"choice"`
+	Voter      string `json:"voter"`   // hex SHA-256 of the public key
+	CastAt     string `json:"cast_at"` // RFC 3339, UTC
+}
+
+const (
+	voteVersion   = 1
+	voteAlgorithm = "falcon-1024"
+	// Ballots sign voteDomain || 0x00 || the RFC 8785 canonical JSON of the
+	// vote, so that the signature cannot pass for that of another command
+	// and any serialization of the vote verifies.
+	voteDomain = "falcon-vote-v1"
+)
+
+// voteSigningBytes returns the bytes a voter signs for v.
+func voteSigningBytes(v votePayload) ([]byte, error) {
+	doc, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	canonical, err := falcongo.CanonicalizeJSON(doc)
+	if err != nil {
+		return nil, err
+	}
+	out := append([]byte(voteDomain), 0)
+	return append(out, canonical...), nil
+}
+
+// checkBallot verifies the signature of b and that its voter is the
+// fingerprint of its public key. It returns the lowercase fingerprint.
+func checkBallot(b voteBallotJSON) (string, error) {
+	if b.Version != voteVersion || b.Algorithm != voteAlgorithm {
+		return "", fmt.Errorf("unsupported ballot version %d / algorithm %q", b.Version, b.Algorithm)
+	}
+	pub, err := parseHex(b.PublicKey)
+	if err != nil || len(pub) != falcongo.PublicKeySize {
+		return "", fmt.Errorf("invalid public_key")
+	}
+	var pk falcongo.PublicKey
+	copy(pk[:], pub)
+	fp := falcongo.Fingerprint(pk)
+	fingerprint := hex.EncodeToString(fp[:])
+	if !strings.EqualFold(b.Vote.Voter, fingerprint) {
+		return "", fmt.Errorf("voter %s does not match the public key", b.Vote.Voter)
+	}
+	var sig falcongo.Signature
+	if err := sig.UnmarshalText([]byte(b.Signature)); err != nil {
+		return "", fmt.Errorf("invalid signature: %v", err)
+	}
+	signed, err := voteSigningBytes(b.Vote)
+	if err != nil {
+		return "", err
+	}
+	if err := sig.Verify(signed, pk); err != nil {
+		return "", fmt.Errorf("invalid signature")
+	}
+	return fingerprint, nil
+}
+
+// readVoterRegistry reads a file of voter fingerprints, one per line, with
+// blank lines and '#' comments ignored.
+func readVoterRegistry(path string) (map[string]bool, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	voters := map[string]bool{}
+	for i, line := range strings.Split(string(b), "\n") {
+		if c := strings.IndexByte(line, '#'); c >= 0 {
+			line = line[:c]
+		}
+		line = strings.ToLower(strings.TrimSpace(line))
+		if line == "" {
+			continue
+		}
+		if fp, err := hex.DecodeString(line); err != nil || len(fp) != 32 {
+			return nil, fmt.Errorf("line %d: invalid fingerprint %q: want 64 hex digits", i+1, line)
+		}
+		voters[line] = true
+	}
+	return voters, nil
+}
+
+// ---- vote dispatcher ----
+func runVote(args []string) int {
+	return runSubcommand("vote", args)
+}
+
+// ---- vote sign ----
+func runVoteSign(args []string) int {
+	fs := flag.NewFlagSet("vote sign", flag.ExitOnError)
+	keyPath := fs.String("key", "", "path to keypair JSON file")
+	proposalID := fs.String("proposal-id", "", "identifier of the proposal voted on")
+	choice := fs.String("choice", "", "the option voted for")
+	out := fs.String("out", "", "write ballot JSON to file (stdout if omitted)")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+	})
+
+	if *keyPath == "" {
+		fmt.Fprintf(os.Stderr, "--key is required\n")
+		return 2
+	}
+	if strings.TrimSpace(*proposalID) == "" || strings.TrimSpace(*choice) == "" {
+		fmt.Fprintf(os.Stderr, "--proposal-id and --choice are required\n")
+		return 2
+	}
+
+	var override *string
+	if passphraseProvided {
+		override = mnemonicPassphrase
+	}
+	kp, code := loadSigningKeyPair("--key", *keyPath, override, "voting")
+	if code != 0 {
+		return code
+	}
+	pub := kp.PublicKey[:]
+	fp := falcongo.Fingerprint(kp.PublicKey)
+
+	ballot := voteBallotJSON{
+		Version:   voteVersion,
+		Algorithm: voteAlgorithm,
+		Vote: votePayload{
+			ProposalID: strings.TrimSpace(*proposalID),
+			Choice:     strings.TrimSpace(*choice),
+			Voter:      hex.EncodeToString(fp[:]),
+			CastAt:     time.Now().UTC().Format(time.RFC3339),
+		},
+		PublicKey: hex.EncodeToString(pub),
+	}
+	signed, err := voteSigningBytes(ballot.Vote)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode vote: %v\n", err)
+		return 2
+	}
+	sig, err := kp.Sign(signed)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "signing failed: %v\n", err)
+		return 2
+	}
+	typed, err := falcongo.NewSignature(sig)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "signing failed: %v\n", err)
+		return 2
+	}
+	sigText, err := typed.MarshalText()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "signing failed: %v\n", err)
+		return 2
+	}
+	ballot.Signature = string(sigText)
+
+	if code := writeAuthJSON(ballot, *out, "ballot"); code != 0 {
+		return code
+	}
+	recordKeyUse(pub, *keyPath, "vote sign", "", 1)
+	return 0
+}
+
+// voteTally is the result of vote tally.
+type voteTally struct {
+	ProposalID string         `json:"proposal_id"`
+	Registered int            `json:"registered"`
+	Counted    int            `json:"counted"`
+	Rejected   int            `json:"rejected"`
+	Duplicates int            `json:"duplicates"`
+	Choices    map[string]int `json:"choices"`
+}
+
+// ---- vote tally ----
+func runVoteTally(args []string) int {
+	fs := flag.NewFlagSet("vote tally", flag.ExitOnError)
+	dir := fs.String("dir", "", "directory of ballot JSON files")
+	proposalID := fs.String("proposal-id", "", "proposal to tally")
+	registryPath := fs.String("registry", "", "file of voter fingerprints, one per line")
+	jsonOut := fs.Bool("json", false, "print the tally as JSON")
+	parseFlags(fs, args)
+
+	if *dir == "" || *registryPath == "" || strings.TrimSpace(*proposalID) == "" {
+		fmt.Fprintf(os.Stderr, "--dir, --proposal-id and --registry are required\n")
+		return 2
+	}
+	voters, err := readVoterRegistry(*registryPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --registry: %v\n", err)
+		return 2
+	}
+	paths, err := filepath.Glob(filepath.Join(*dir, "*.json"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to list %s: %v\n", *dir, err)
+		return 2
+	}
+	sort.Strings(paths)
+
+	tally := voteTally{
+		ProposalID: strings.TrimSpace(*proposalID),
+		Registered: len(voters),
+		Choices:    map[string]int{},
+	}
+	// Choices of the valid ballots of each voter, with the file that cast
+	// each.
+	cast := map[string]map[string]string{}
+	var order []string
+	for _, path := range paths {
+		name := filepath.Base(path)
+		var b voteBallotJSON
+		if err := readAuthJSON(path, &b); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+			tally.Rejected++
+			continue
+		}
+		voter, err := checkBallot(b)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+			tally.Rejected++
+			continue
+		}
+		if b.Vote.ProposalID != tally.ProposalID {
+			fmt.Fprintf(os.Stderr, "%s: ballot is for proposal %q\n", name, b.Vote.ProposalID)
+			tally.Rejected++
+			continue
+		}
+		if !voters[voter] {
+			fmt.Fprintf(os.Stderr, "%s: %s is not a registered voter\n", name, voter)
+			tally.Rejected++
+			continue
+		}
+		if cast[voter] == nil {
+			cast[voter] = map[string]string{}
+			order = append(order, voter)
+		} else {
+			tally.Duplicates++
+		}
+		if _, ok := cast[voter][b.Vote.Choice]; !ok {
+			cast[voter][b.Vote.Choice] = name
+		}
+	}
+	// A voter who voted twice for the same choice counts once; one who voted
+	// for different choices does not count.
+	for _, voter := range order {
+		choices := cast[voter]
+		if len(choices) > 1 {
+			var files []string
+			for _, f := range choices {
+				files = append(files, f)
+			}
+			sort.Strings(files)
+			fmt.Fprintf(os.Stderr, "%s: conflicting votes in %s; not counted\n", voter, strings.Join(files, ", "))
+			continue
+		}
+		for choice := range choices {
+			tally.Choices[choice]++
+			tally.Counted++
+		}
+	}
+
+	if *jsonOut {
+		data, err := json.MarshalIndent(tally, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to encode tally: %v\n", err)
+			return 2
+		}
+		fmt.Fprintf(os.Stdout, "%s\n", data)
+	} else {
+		names := make([]string, 0, len(tally.Choices))
+		for choice := range tally.Choices {
+			names = append(names, choice)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			if tally.Choices[names[i]] != tally.Choices[names[j]] {
+				return tally.Choices[names[i]] > tally.Choices[names[j]]
+			}
+			return names[i] < names[j]
+		})
+		for _, choice := range names {
+			fmt.Fprintf(os.Stdout, "%s: %d\n", choice, tally.Choices[choice])
+		}
+		fmt.Fprintf(os.Stdout, "Proposal %s: %d of %d registered voters counted, %d ballots rejected, %d duplicates\n",
+			tally.ProposalID, tally.Counted, tally.Registered, tally.Rejected, tally.Duplicates)
+	}
+	if tally.Rejected > 0 || tally.Duplicates > 0 {
+		return 1
+	}
+	return 0
+}
+
+const helpVote = `# falcon vote
+
+Cast and count FALCON-1024 signed off-chain votes.
+
+A ballot is a JSON document holding the vote (proposal ID, choice, voter
+fingerprint and time cast), the voter's public key and a typed signature. The
+voter signs the domain tag falcon-vote-v1, a zero byte and the RFC 8785
+canonical JSON of the vote, so a ballot cannot be replayed for another proposal
+or passed off as another kind of signature.
+
+Usage:
+  falcon vote sign --key <file> --proposal-id <id> --choice <choice> [--out <file>] [--mnemonic-passphrase <string>]
+  falcon vote tally --dir <dir> --proposal-id <id> --registry <file> [--json]
+
+Subcommands:
+  sign      Sign a vote and write the ballot
+  tally     Verify a directory of ballots and count the votes
+
+Arguments (sign):
+  --key <file>              keypair JSON (required, must include private key)
+  --proposal-id <id>        identifier of the proposal (required)
+  --choice <choice>         the option voted for (required)
+  --out <file>              write the ballot to file (stdout if omitted)
+  --mnemonic-passphrase     optional mnemonic passphrase when the key file omits it
+
+Arguments (tally):
+  --dir <dir>               directory of ballot *.json files (required)
+  --proposal-id <id>        proposal to tally (required)
+  --registry <file>         voter fingerprints (see 'falcon info'), one per
+                            line; '#' starts a comment (required)
+  --json                    print the tally as JSON
+
+Ballots with an invalid signature, for another proposal or from a voter not in
+the registry are rejected. Several ballots of one voter for the same choice
+count once; ballots of one voter for different choices do not count.
+
+Exit codes (tally): 0 all ballots counted, 1 ballots rejected or duplicated,
+2 usage or read errors.
+
+Examples:
+  falcon vote sign --key alice.json --proposal-id prop-42 --choice yes --out ballots/alice.json
+  falcon vote tally --dir ballots --proposal-id prop-42 --registry voters.txt
+`
@@ -0,0 +1,89 @@
+package cli
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestRunVote_Tally signs ballots and tallies them against a registry.
+func TestRunVote_Tally(t *testing.T) {
+	dir := t.TempDir()
+	ballots := filepath.Join(dir, "ballots")
+	if err := os.Mkdir(ballots, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	keys := attestKeys(t, dir, 4)
+	var registry []string
+	for _, key := range keys[:3] {
+		pub, _, _, err := loadKeypairFile(key, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fp := falcongo.Fingerprint(falcongo.PublicKey(pub))
+		registry = append(registry, strings.ToUpper(hex.EncodeToString(fp[:])))
+	}
+	registryPath := filepath.Join(dir, "voters.txt")
+	doc := "# voters\n" + strings.Join(registry, "\n") + "\n"
+	if err := os.WriteFile(registryPath, []byte(doc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sign := func(key, proposal, choice, name string) {
+		t.Helper()
+		if code := runVoteSign([]string{"--key", key, "--proposal-id", proposal, "--choice", choice,
+			"--out", filepath.Join(ballots, name)}); code != 0 {
+			t.Fatalf("vote sign %s: exit %d", name, code)
+		}
+	}
+	sign(keys[0], "prop-1", "yes", "a.json")
+	sign(keys[1], "prop-1", "no", "b.json")
+	sign(keys[2], "prop-1", "yes", "c.json")
+
+	tally := func(wantCode int) voteTally {
+		t.Helper()
+		var code int
+		var out string
+		captureStderr(t, func() {
+			out = captureStdout(t, func() {
+				code = runVoteTally([]string{"--dir", ballots, "--proposal-id", "prop-1",
+					"--registry", registryPath, "--json"})
+			})
+		})
+		var result voteTally
+		if err := json.Unmarshal([]byte(out), &result); err != nil || code != wantCode {
+			t.Fatalf("tally: exit %d (want %d), %q", code, wantCode, out)
+		}
+		return result
+	}
+	if r := tally(0); r.Counted != 3 || r.Choices["yes"] != 2 || r.Choices["no"] != 1 {
+		t.Fatalf("unexpected tally %+v", r)
+	}
+
+	// A second ballot for the same choice counts once; an unregistered voter,
+	// another proposal and a tampered choice are rejected.
+	sign(keys[0], "prop-1", "yes", "a2.json")
+	sign(keys[3], "prop-1", "no", "d.json")
+	sign(keys[1], "prop-2", "yes", "e.json")
+	b, err := os.ReadFile(filepath.Join(ballots, "c.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := strings.Replace(string(b), `"choice": "yes"`, `"choice": "no"`, 1)
+	if err := os.WriteFile(filepath.Join(ballots, "f.json"), []byte(tampered), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if r := tally(1); r.Counted != 3 || r.Choices["yes"] != 2 || r.Rejected != 3 || r.Duplicates != 1 {
+		t.Fatalf("unexpected tally %+v", r)
+	}
+
+	// Votes of one voter for different choices do not count.
+	sign(keys[1], "prop-1", "yes", "b2.json")
+	if r := tally(1); r.Counted != 2 || r.Choices["no"] != 0 || r.Duplicates != 2 {
+		t.Fatalf("unexpected tally %+v", r)
+	}
+}
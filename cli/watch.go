@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+	qrcode "github.com/skip2/go-qrcode"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// Formats of algorand watch-export.
+const (
+	watchFormatText = "text" // URI and QR code drawn with block characters
+	watchFormatURI  = "uri"
+	watchFormatPNG  = "png"
+	watchFormatJSON = "json"
+)
+
+// watchQRSize is the width in pixels of PNG QR codes, enough for phones to
+// scan them from a screen or a print.
+const watchQRSize = 512
+
+// watchExportJSON is the json format of algorand watch-export.
+type watchExportJSON struct {
+	Address string `json:"address"`
+	Label   string `json:"label,omitempty"`
+	URI     string `json:"uri"`
+}
+
+// ---- algorand watch-export ----
+func runAlgorandWatchExport(args []string) int {
+	fs := flag.NewFlagSet("algorand watch-export", flag.ExitOnError)
+	keyPath := fs.String("key", "", "path to keypair/public key JSON file")
+	addressFlag := fs.String("address", "", "Algorand address to export instead of --key")
+	label := fs.String("label", "", "name of the account in the wallet")
+	out := fs.String("out", "", "write the export to file (stdout if empty)")
+	format := fs.String("format", "", "text, uri, png or json (default: png for a .png --out, text otherwise)")
+	invert := fs.Bool("invert", false, "text: draw the QR code for a light terminal background")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase (if used and key file omits it)")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+	})
+
+	if (*keyPath == "") == (*addressFlag == "") {
+		fmt.Fprintf(os.Stderr, "exactly one of --key or --address is required\n")
+		return 2
+	}
+	if *format == "" {
+		*format = watchFormatText
+		if strings.EqualFold(filepath.Ext(*out), ".png") {
+			*format = watchFormatPNG
+		}
+	}
+	switch *format {
+	case watchFormatText, watchFormatURI, watchFormatJSON:
+	case watchFormatPNG:
+		if *out == "" {
+			fmt.Fprintf(os.Stderr, "--format png requires --out\n")
+			return 2
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "invalid --format %q (want text, uri, png or json)\n", *format)
+		return 2
+	}
+
+	var address types.Address
+	if *addressFlag != "" {
+		var err error
+		address, err = types.DecodeAddress(strings.TrimSpace(*addressFlag))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "invalid --address: %v\n", err)
+			return 2
+		}
+	} else {
+		var override *string
+		if passphraseProvided {
+			override = mnemonicPassphrase
+		}
+		pub, _, _, err := loadKeypairFile(*keyPath, override)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
+			return 2
+		}
+		if pub == nil {
+			fmt.Fprintf(os.Stderr, "public key not found in %s\n", *keyPath)
+			return 2
+		}
+		var pk falcongo.PublicKey
+		copy(pk[:], pub)
+		address, _, err = algorand.DerivePQAddress(pk)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error deriving address: %v\n", err)
+			return 2
+		}
+	}
+
+	data, err := watchExport(address, *label, *format, *invert)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to export: %v\n", err)
+		return 2
+	}
+	if *out == "" {
+		os.Stdout.Write(data)
+		return 0
+	}
+	if err := writeFileAtomic(*out, data, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write --out: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+// watchExport encodes the ARC-26 URI of address, with label, in format.
+func watchExport(address types.Address, label, format string, invert bool) ([]byte, error) {
+	uri := algorand.AddressURI(address, label)
+	switch format {
+	case watchFormatURI:
+		return []byte(uri + "\n"), nil
+	case watchFormatJSON:
+		data, err := json.MarshalIndent(watchExportJSON{Address: address.String(), Label: label, URI: uri}, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(data, '\n'), nil
+	}
+	qr, err := qrcode.New(uri, qrcode.Medium)
+	if err != nil {
+		return nil, err
+	}
+	if format == watchFormatPNG {
+		return qr.PNG(watchQRSize)
+	}
+	return []byte(uri + "\n" + qr.ToSmallString(invert)), nil
+}
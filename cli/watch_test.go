@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/types"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestRunAlgorandWatchExport exports the PQ account of a key file in each
+// format.
+func TestRunAlgorandWatchExport(t *testing.T) {
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("watch export test seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	dir := t.TempDir()
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, false)
+	addr, _, err := algorand.DerivePQAddress(kp.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri := "algorand://" + addr.String() + "?label=PQ%20savings"
+
+	var code int
+	out := captureStdout(t, func() {
+		code = runAlgorandWatchExport([]string{"--key", keyPath, "--label", "PQ savings", "--format", "uri"})
+	})
+	if code != 0 || out != uri+"\n" {
+		t.Fatalf("uri: exit %d, %q", code, out)
+	}
+	out = captureStdout(t, func() {
+		code = runAlgorandWatchExport([]string{"--key", keyPath, "--label", "PQ savings"})
+	})
+	if code != 0 || !strings.HasPrefix(out, uri+"\n") || !strings.Contains(out, "█") {
+		t.Fatalf("text: exit %d, %q", code, out)
+	}
+	out = captureStdout(t, func() {
+		code = runAlgorandWatchExport([]string{"--address", addr.String(), "--format", "json"})
+	})
+	var export watchExportJSON
+	if code != 0 || json.Unmarshal([]byte(out), &export) != nil || export.Address != addr.String() ||
+		export.URI != "algorand://"+addr.String() || export.Label != "" {
+		t.Fatalf("json: exit %d, %q", code, out)
+	}
+
+	pngPath := filepath.Join(dir, "watch.png")
+	if code = runAlgorandWatchExport([]string{"--key", keyPath, "--out", pngPath}); code != 0 {
+		t.Fatalf("png: exit %d", code)
+	}
+	b, err := os.ReadFile(pngPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil || img.Bounds().Dx() != watchQRSize {
+		t.Fatalf("png: %v, bounds %v", err, img.Bounds())
+	}
+
+	for name, args := range map[string][]string{
+		"no account":    {},
+		"both accounts": {"--key", keyPath, "--address", addr.String()},
+		"bad address":   {"--address", "NOTANADDRESS"},
+		"png to stdout": {"--key", keyPath, "--format", "png"},
+		"bad format":    {"--key", keyPath, "--format", "svg"},
+	} {
+		stderr := captureStderr(t, func() { code = runAlgorandWatchExport(args) })
+		if code != 2 || stderr == "" {
+			t.Fatalf("%s: exit %d, %q", name, code, stderr)
+		}
+	}
+}
+
+// TestWatchExportInvert checks that --invert redraws the text QR code.
+func TestWatchExportInvert(t *testing.T) {
+	var addr types.Address
+	addr[0] = 3
+	text, err := watchExport(addr, "a", watchFormatText, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inverted, err := watchExport(addr, "a", watchFormatText, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(text, inverted) || !bytes.HasPrefix(inverted, []byte(algorand.AddressURI(addr, "a")+"\n")) {
+		t.Fatalf("--invert did not change the QR code")
+	}
+}
@@ -0,0 +1,560 @@
+package cli
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/mlkem"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Wrapped key files (.fwk) hold a key file encrypted to the wrap key of a
+// recipient, so that keys move between custodians without a shared
+// passphrase. FALCON keys can only sign, so recipients publish a separate
+// key encapsulation key: X25519 and ML-KEM-768, and the wrapped key stays
+// secret unless both are broken. Layout:
+//
+//	magic "FWK" | version (1) | KEM (1) | recipient ID (32) |
+//	ephemeral X25519 public key (32) | ML-KEM-768 ciphertext (1088, hybrid only) |
+//	nonce (12) | AES-256-GCM ciphertext
+//
+// The header up to the nonce is authenticated as additional data. The AES
+// key is HKDF-SHA-256 over the ML-KEM and X25519 shared secrets, with the
+// header as info. The plaintext is the key file as read.
+const (
+	wrapMagic      = "FWK"
+	wrapVersion    = 1
+	wrapKEMX25519  = 1 // X25519 alone, for recipients without ML-KEM
+	wrapKEMHybrid  = 2 // X25519 and ML-KEM-768
+	wrapHKDFInfo   = "falcon key wrap v1"
+	wrapIDContext  = "falcon wrap recipient v1\n"
+	wrapHeaderSize = len(wrapMagic) + 1 + 1 + sha256.Size + 32
+)
+
+// wrapRecipientJSON is the recipient file written by import --new-recipient
+// and given to senders. With a FALCON key, the recipient signs its recipient
+// ID (SignBytes), which tells senders whose wrap key it is.
+type wrapRecipientJSON struct {
+	X25519          string `json:"x25519"`
+	MLKEM768        string `json:"mlkem768,omitempty"`
+	FalconPublicKey string `json:"falcon_public_key,omitempty"`
+	Signature       string `json:"signature,omitempty"`
+}
+
+// wrapSecretJSON is the secret file of a recipient, needed to unwrap.
+type wrapSecretJSON struct {
+	X25519       string `json:"x25519"`
+	MLKEM768Seed string `json:"mlkem768_seed,omitempty"`
+}
+
+// wrapRecipient holds the wrap public keys of a recipient; mlkem is nil for
+// X25519-only recipients.
+type wrapRecipient struct {
+	x25519 *ecdh.PublicKey
+	mlkem  *mlkem.EncapsulationKey768
+}
+
+// id returns the recipient ID, which names the wrap keys in wrapped files.
+func (r wrapRecipient) id() [sha256.Size]byte {
+	h := sha256.New()
+	h.Write([]byte(wrapIDContext))
+	h.Write(r.x25519.Bytes())
+	if r.mlkem != nil {
+		h.Write(r.mlkem.Bytes())
+	}
+	return [sha256.Size]byte(h.Sum(nil))
+}
+
+// wrapSecret holds the wrap private keys of a recipient.
+type wrapSecret struct {
+	x25519 *ecdh.PrivateKey
+	mlkem  *mlkem.DecapsulationKey768
+}
+
+func (s wrapSecret) recipient() wrapRecipient {
+	r := wrapRecipient{x25519: s.x25519.PublicKey()}
+	if s.mlkem != nil {
+		r.mlkem = s.mlkem.EncapsulationKey()
+	}
+	return r
+}
+
+// newWrapSecret generates hybrid wrap keys.
+func newWrapSecret() (wrapSecret, error) {
+	x, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return wrapSecret{}, err
+	}
+	dk, err := mlkem.GenerateKey768()
+	if err != nil {
+		return wrapSecret{}, err
+	}
+	return wrapSecret{x25519: x, mlkem: dk}, nil
+}
+
+// parseWrapRecipient decodes a recipient file. If it is signed, it returns
+// the FALCON public key that signed it, after checking the signature.
+func parseWrapRecipient(b []byte) (wrapRecipient, *falcongo.PublicKey, error) {
+	var rj wrapRecipientJSON
+	if err := json.Unmarshal(b, &rj); err != nil {
+		return wrapRecipient{}, nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	xb, err := parseHex(rj.X25519)
+	if err != nil {
+		return wrapRecipient{}, nil, fmt.Errorf("invalid x25519 hex: %w", err)
+	}
+	var r wrapRecipient
+	if r.x25519, err = ecdh.X25519().NewPublicKey(xb); err != nil {
+		return wrapRecipient{}, nil, fmt.Errorf("invalid x25519 key: %w", err)
+	}
+	if rj.MLKEM768 != "" {
+		kb, err := parseHex(rj.MLKEM768)
+		if err != nil {
+			return wrapRecipient{}, nil, fmt.Errorf("invalid mlkem768 hex: %w", err)
+		}
+		if r.mlkem, err = mlkem.NewEncapsulationKey768(kb); err != nil {
+			return wrapRecipient{}, nil, fmt.Errorf("invalid mlkem768 key: %w", err)
+		}
+	}
+	if rj.FalconPublicKey == "" && rj.Signature == "" {
+		return r, nil, nil
+	}
+	pb, err := parseHex(rj.FalconPublicKey)
+	var pk falcongo.PublicKey
+	if err != nil || len(pb) != len(pk) {
+		return wrapRecipient{}, nil, errors.New("invalid falcon_public_key")
+	}
+	copy(pk[:], pb)
+	sig, err := parseHex(rj.Signature)
+	if err != nil {
+		return wrapRecipient{}, nil, fmt.Errorf("invalid signature hex: %w", err)
+	}
+	id := r.id()
+	if err := falcongo.VerifyBytes(id[:], sig, pk); err != nil {
+		return wrapRecipient{}, nil, errors.New("the signature of falcon_public_key does not verify: the recipient file was modified")
+	}
+	return r, &pk, nil
+}
+
+// parseWrapSecret decodes a recipient secret file.
+func parseWrapSecret(b []byte) (wrapSecret, error) {
+	var sj wrapSecretJSON
+	if err := json.Unmarshal(b, &sj); err != nil {
+		return wrapSecret{}, fmt.Errorf("invalid JSON: %w", err)
+	}
+	xb, err := parseHex(sj.X25519)
+	if err != nil {
+		return wrapSecret{}, fmt.Errorf("invalid x25519 hex: %w", err)
+	}
+	defer wipeBytes(xb)
+	var s wrapSecret
+	if s.x25519, err = ecdh.X25519().NewPrivateKey(xb); err != nil {
+		return wrapSecret{}, fmt.Errorf("invalid x25519 key: %w", err)
+	}
+	if sj.MLKEM768Seed != "" {
+		seed, err := parseHex(sj.MLKEM768Seed)
+		if err != nil {
+			return wrapSecret{}, fmt.Errorf("invalid mlkem768_seed hex: %w", err)
+		}
+		defer wipeBytes(seed)
+		if s.mlkem, err = mlkem.NewDecapsulationKey768(seed); err != nil {
+			return wrapSecret{}, fmt.Errorf("invalid mlkem768_seed: %w", err)
+		}
+	}
+	return s, nil
+}
+
+// wrapKey derives the AES key of a wrapped file from the shared secrets.
+func wrapKey(header, mlkemShared, x25519Shared []byte) ([]byte, error) {
+	secret := append(bytes.Clone(mlkemShared), x25519Shared...)
+	defer wipeBytes(secret)
+	key := make([]byte, backupKeySize)
+	info := append([]byte(wrapHKDFInfo), header...)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, nil, info), key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// sealWrapped encrypts plain to r with a fresh ephemeral key and nonce.
+func sealWrapped(plain []byte, r wrapRecipient) ([]byte, error) {
+	eph, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	xShared, err := eph.ECDH(r.x25519)
+	if err != nil {
+		return nil, err
+	}
+	defer wipeBytes(xShared)
+	kem := byte(wrapKEMX25519)
+	if r.mlkem != nil {
+		kem = wrapKEMHybrid
+	}
+	id := r.id()
+	header := append([]byte(wrapMagic), wrapVersion, kem)
+	header = append(header, id[:]...)
+	header = append(header, eph.PublicKey().Bytes()...)
+	var mShared []byte
+	if r.mlkem != nil {
+		var ct []byte
+		mShared, ct = r.mlkem.Encapsulate()
+		defer wipeBytes(mShared)
+		header = append(header, ct...)
+	}
+	key, err := wrapKey(header, mShared, xShared)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newBackupAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	out := append(bytes.Clone(header), nonce...)
+	return aead.Seal(out, nonce, plain, header), nil
+}
+
+// openWrapped decrypts a wrapped file with the secret of its recipient.
+func openWrapped(b []byte, s wrapSecret) ([]byte, error) {
+	if len(b) < wrapHeaderSize || string(b[:len(wrapMagic)]) != wrapMagic {
+		return nil, errors.New("not a falcon wrapped key file")
+	}
+	if v := b[len(wrapMagic)]; v != wrapVersion {
+		return nil, fmt.Errorf("unsupported wrapped key version %d", v)
+	}
+	kem := b[len(wrapMagic)+1]
+	headerSize := wrapHeaderSize
+	switch kem {
+	case wrapKEMX25519:
+	case wrapKEMHybrid:
+		headerSize += mlkem.CiphertextSize768
+	default:
+		return nil, fmt.Errorf("unsupported wrapped key KEM %d", kem)
+	}
+	if len(b) < headerSize {
+		return nil, errors.New("truncated wrapped key file")
+	}
+	header := b[:headerSize]
+	id := s.recipient().id()
+	p := header[len(wrapMagic)+2:]
+	if !bytes.Equal(p[:sha256.Size], id[:]) {
+		return nil, fmt.Errorf("wrapped for recipient %x, not for this secret (%x)", p[:sha256.Size], id)
+	}
+	eph, err := ecdh.X25519().NewPublicKey(p[sha256.Size : sha256.Size+32])
+	if err != nil {
+		return nil, fmt.Errorf("invalid ephemeral key: %w", err)
+	}
+	xShared, err := s.x25519.ECDH(eph)
+	if err != nil {
+		return nil, err
+	}
+	defer wipeBytes(xShared)
+	var mShared []byte
+	if kem == wrapKEMHybrid {
+		if s.mlkem == nil {
+			return nil, errors.New("wrapped with ML-KEM-768 but the secret has no mlkem768_seed")
+		}
+		if mShared, err = s.mlkem.Decapsulate(header[wrapHeaderSize:]); err != nil {
+			return nil, err
+		}
+		defer wipeBytes(mShared)
+	}
+	key, err := wrapKey(header, mShared, xShared)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newBackupAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	rest := b[headerSize:]
+	if len(rest) < aead.NonceSize()+aead.Overhead() {
+		return nil, errors.New("truncated wrapped key file")
+	}
+	nonce, sealed := rest[:aead.NonceSize()], rest[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, sealed, header)
+	if err != nil {
+		return nil, errors.New("corrupted wrapped key file")
+	}
+	return plain, nil
+}
+
+// ---- export ----
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	keyPath := fs.String("key", "", "key JSON file to export")
+	wrapWith := fs.String("wrap-with", "", "recipient file (falcon import --new-recipient) to encrypt the key to")
+	out := fs.String("out", "", "write the wrapped key to file")
+	parseFlags(fs, args)
+
+	if *keyPath == "" || *wrapWith == "" || *out == "" {
+		fmt.Fprintln(os.Stderr, "--key, --wrap-with and --out are required")
+		return 2
+	}
+	rb, err := os.ReadFile(*wrapWith)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --wrap-with: %v\n", err)
+		return 2
+	}
+	r, signer, err := parseWrapRecipient(rb)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --wrap-with: %v\n", err)
+		return 2
+	}
+	plain, err := readKeySource(*keyPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --key: %v\n", err)
+		return 2
+	}
+	defer wipeBytes(plain)
+	var k keyPairJSON
+	if err := json.Unmarshal(plain, &k); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --key: invalid JSON: %v\n", err)
+		return 2
+	}
+	if k.PrivateKey == "" && k.Mnemonic == "" {
+		fmt.Fprintf(os.Stderr, "no private key or mnemonic in %s: nothing to wrap\n", *keyPath)
+		return 2
+	}
+
+	data, err := sealWrapped(plain, r)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to wrap the key: %v\n", err)
+		return 2
+	}
+	if err := writeFileAtomic(*out, data, 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *out, err)
+		return 2
+	}
+	id := r.id()
+	fmt.Printf("recipient: %x\n", id)
+	if signer != nil {
+		fp := falcongo.Fingerprint(*signer)
+		fmt.Printf("recipient_falcon_key: %x\n", fp)
+	} else {
+		fmt.Fprintln(os.Stderr, "warning: the recipient file is not signed by a FALCON key: "+
+			"confirm the recipient ID with its owner over another channel")
+	}
+	if r.mlkem == nil {
+		fmt.Fprintln(os.Stderr, "warning: the recipient has no ML-KEM-768 key: "+
+			"the key is wrapped with X25519 alone, which is not post-quantum")
+	}
+	return 0
+}
+
+// ---- import ----
+func runImport(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	unwrapWith := fs.String("unwrap-with", "", "recipient secret file to decrypt --in with")
+	in := fs.String("in", "", "wrapped key file (falcon export --wrap-with)")
+	out := fs.String("out", "", "write the key JSON, or the recipient file with --new-recipient (stdout if empty)")
+	newRecipient := fs.String("new-recipient", "", "generate wrap keys and write their secret file here")
+	keyPath := fs.String("key", "", "with --new-recipient: FALCON key signing the recipient file")
+	mnemonicPassphrase := fs.String("mnemonic-passphrase", "", "mnemonic passphrase of --key (if used and key file omits it)")
+	parseFlags(fs, args)
+	passphraseProvided := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "mnemonic-passphrase" {
+			passphraseProvided = true
+		}
+	})
+
+	if *newRecipient != "" {
+		if *unwrapWith != "" || *in != "" {
+			fmt.Fprintln(os.Stderr, "cannot combine --new-recipient with --unwrap-with or --in")
+			return 2
+		}
+		var override *string
+		if passphraseProvided {
+			override = mnemonicPassphrase
+		}
+		return newWrapRecipient(*newRecipient, *out, *keyPath, override)
+	}
+	if *unwrapWith == "" || *in == "" {
+		fmt.Fprintln(os.Stderr, "--unwrap-with and --in are required (or --new-recipient)")
+		return 2
+	}
+	if *keyPath != "" {
+		fmt.Fprintln(os.Stderr, "--key requires --new-recipient")
+		return 2
+	}
+	sb, err := os.ReadFile(*unwrapWith)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --unwrap-with: %v\n", err)
+		return 2
+	}
+	s, err := parseWrapSecret(sb)
+	wipeBytes(sb)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --unwrap-with: %v\n", err)
+		return 2
+	}
+	wb, err := os.ReadFile(*in)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read --in: %v\n", err)
+		return 2
+	}
+	plain, err := openWrapped(wb, s)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to unwrap --in: %v\n", err)
+		return 2
+	}
+	defer wipeBytes(plain)
+	if err := checkKeyFileVersion(plain); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to unwrap --in: %v\n", err)
+		return 2
+	}
+
+	if *out == "" {
+		if _, err := os.Stdout.Write(plain); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write key JSON: %v\n", err)
+			return 2
+		}
+		return 0
+	}
+	if err := writeFileAtomic(*out, plain, 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *out, err)
+		return 2
+	}
+	return 0
+}
+
+// newWrapRecipient generates wrap keys, writes their secret to secretPath
+// and the recipient file to out (stdout if empty), signed by the FALCON key
+// at keyPath if given.
+func newWrapRecipient(secretPath, out, keyPath string, passphrase *string) int {
+	if _, err := os.Lstat(secretPath); err == nil {
+		fmt.Fprintf(os.Stderr, "%s already exists; refusing to overwrite a recipient secret\n", secretPath)
+		return 2
+	}
+	var kp *falcongo.KeyPair
+	if keyPath != "" {
+		signer, code := loadSigningKeyPair("--key", keyPath, passphrase, "signing the recipient")
+		if code != 0 {
+			return code
+		}
+		kp = &signer
+	}
+
+	s, err := newWrapSecret()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to generate wrap keys: %v\n", err)
+		return 2
+	}
+	r := s.recipient()
+	id := r.id()
+	rj := wrapRecipientJSON{
+		X25519:   hex.EncodeToString(r.x25519.Bytes()),
+		MLKEM768: hex.EncodeToString(r.mlkem.Bytes()),
+	}
+	if kp != nil {
+		sig, err := kp.SignBytes(id[:])
+		wipeBytes(kp.PrivateKey[:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to sign the recipient file: %v\n", err)
+			return 2
+		}
+		rj.FalconPublicKey = hex.EncodeToString(kp.PublicKey[:])
+		rj.Signature = hex.EncodeToString(sig)
+	}
+
+	sj := wrapSecretJSON{
+		X25519:       hex.EncodeToString(s.x25519.Bytes()),
+		MLKEM768Seed: hex.EncodeToString(s.mlkem.Bytes()),
+	}
+	secret, err := json.MarshalIndent(sj, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode the recipient secret: %v\n", err)
+		return 2
+	}
+	defer wipeBytes(secret)
+	if err := writeFileAtomic(secretPath, append(secret, '\n'), 0o600); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", secretPath, err)
+		return 2
+	}
+	data, err := json.MarshalIndent(rj, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode the recipient file: %v\n", err)
+		return 2
+	}
+	data = append(data, '\n')
+	if out == "" {
+		if _, err := os.Stdout.Write(data); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write the recipient file: %v\n", err)
+			return 2
+		}
+	} else if err := writeFileAtomic(out, data, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", out, err)
+		return 2
+	}
+	fmt.Fprintf(os.Stderr, "recipient: %x\n", id)
+	return 0
+}
+
+const helpExport = `# falcon export
+
+Encrypt a key file to the wrap key of another custodian, who decrypts it with
+'falcon import --unwrap-with'. No passphrase is shared: the key is encrypted
+with AES-256-GCM under a key agreed with X25519 and ML-KEM-768 (hybrid, so it
+stays secret unless both are broken). FALCON keys can only sign, so the
+recipient first creates wrap keys with 'falcon import --new-recipient'.
+
+Usage:
+  falcon export --key <file> --wrap-with <recipient.json> --out <file>
+
+Options:
+  --key <file>              key JSON file to export, as is (required)
+  --wrap-with <file>        recipient file of the custodian receiving the key (required)
+  --out <file>              write the wrapped key (0600) to file (required)
+
+The recipient ID is printed, and, for a recipient file signed by a FALCON key,
+the fingerprint of that key (recipient_falcon_key): check it with the
+recipient before sending the file. Recipient files without an ML-KEM-768 key
+(X25519 alone) are accepted with a warning that the wrapping is not
+post-quantum.
+
+Examples:
+  falcon export --key mykeys.json --wrap-with custodian.json --out mykeys.fwk
+`
+
+const helpImport = `# falcon import
+
+Decrypt a key file wrapped with 'falcon export --wrap-with', or create the
+wrap keys to receive one.
+
+Usage:
+  falcon import --new-recipient <secret.json> [--out <recipient.json>] [--key <file>] [--mnemonic-passphrase <string>]
+  falcon import --unwrap-with <secret.json> --in <file> [--out <file>]
+
+Options:
+  --new-recipient <file>    generate X25519 and ML-KEM-768 wrap keys; write their secret (0600) to
+                              file, which must not exist, and the recipient file to --out
+  --key <file>              with --new-recipient: FALCON key signing the recipient file, so that
+                              senders see whose it is
+  --mnemonic-passphrase <string>
+                            mnemonic passphrase of --key when the key file omits it
+  --unwrap-with <file>      recipient secret file (required to unwrap)
+  --in <file>               wrapped key file (required to unwrap)
+  --out <file>              write the key JSON (0600), or the recipient file, to file (stdout if omitted)
+
+A wrapped file made for another recipient, or modified, is reported as an
+error (exit 2).
+
+Examples:
+  falcon import --new-recipient custodian-secret.json --key custodian.json --out custodian-recipient.json
+  falcon import --unwrap-with custodian-secret.json --in mykeys.fwk --out mykeys.json
+`
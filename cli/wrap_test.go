@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+// TestWrapKey wraps a key file to a signed recipient and unwraps it, and
+// checks that other recipients, modified files and forged recipient files
+// are refused.
+func TestWrapKey(t *testing.T) {
+	dir := t.TempDir()
+	kp, err := falcongo.GenerateKeyPair(deriveSeed([]byte("wrap test key")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	keyPath := writeKeypairJSON(t, dir, "keys.json", kp, true)
+	custodian, err := falcongo.GenerateKeyPair(deriveSeed([]byte("wrap test custodian")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	custodianPath := writeKeypairJSON(t, dir, "custodian.json", custodian, true)
+
+	secretPath := filepath.Join(dir, "secret.json")
+	recipientPath := filepath.Join(dir, "recipient.json")
+	var code int
+	_, stderr := captureStdoutStderr(t, func() {
+		code = runImport([]string{"--new-recipient", secretPath, "--key", custodianPath, "--out", recipientPath})
+	})
+	if code != 0 || !strings.Contains(stderr, "recipient: ") {
+		t.Fatalf("new recipient: exit %d, %q", code, stderr)
+	}
+	if code = runImport([]string{"--new-recipient", secretPath, "--out", filepath.Join(dir, "other.json")}); code != 2 {
+		t.Fatalf("new recipient over an existing secret: exit %d", code)
+	}
+
+	wrapped := filepath.Join(dir, "keys.fwk")
+	out, stderr := captureStdoutStderr(t, func() {
+		code = runExport([]string{"--key", keyPath, "--wrap-with", recipientPath, "--out", wrapped})
+	})
+	fp := falcongo.Fingerprint(custodian.PublicKey)
+	if code != 0 || !strings.Contains(out, "recipient_falcon_key: "+hex.EncodeToString(fp[:])) || stderr != "" {
+		t.Fatalf("export: exit %d, %q, %q", code, out, stderr)
+	}
+	want, err := os.ReadFile(keyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unwrapped := filepath.Join(dir, "unwrapped.json")
+	if code = runImport([]string{"--unwrap-with", secretPath, "--in", wrapped, "--out", unwrapped}); code != 0 {
+		t.Fatalf("import: exit %d", code)
+	}
+	got, err := os.ReadFile(unwrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Fatalf("unwrapped key file differs:\n%s\nwant:\n%s", got, want)
+	}
+
+	// A modified wrapped file and another recipient's secret.
+	b, err := os.ReadFile(wrapped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b[len(b)-1] ^= 1
+	tampered := filepath.Join(dir, "tampered.fwk")
+	if err := os.WriteFile(tampered, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	otherSecret := filepath.Join(dir, "other-secret.json")
+	captureStdout(t, func() {
+		code = runImport([]string{"--new-recipient", otherSecret})
+	})
+	if code != 0 {
+		t.Fatalf("new unsigned recipient: exit %d", code)
+	}
+	for name, args := range map[string][]string{
+		"tampered":        {"--unwrap-with", secretPath, "--in", tampered},
+		"other recipient": {"--unwrap-with", otherSecret, "--in", wrapped},
+	} {
+		stderr := captureStderr(t, func() {
+			code = runImport(args)
+		})
+		if code != 2 || !strings.Contains(stderr, "failed to unwrap") {
+			t.Fatalf("%s: exit %d, %q", name, code, stderr)
+		}
+	}
+
+	// A recipient file whose wrap key was swapped keeps a signature that no
+	// longer verifies.
+	var rj wrapRecipientJSON
+	readJSONFile(t, recipientPath, &rj)
+	forged := rj
+	forged.X25519 = strings.Repeat("09", 32)
+	forgedPath := filepath.Join(dir, "forged.json")
+	writeJSONValue(t, forgedPath, forged)
+	stderr = captureStderr(t, func() {
+		code = runExport([]string{"--key", keyPath, "--wrap-with", forgedPath, "--out", filepath.Join(dir, "forged.fwk")})
+	})
+	if code != 2 || !strings.Contains(stderr, "does not verify") {
+		t.Fatalf("forged recipient: exit %d, %q", code, stderr)
+	}
+
+	// An X25519-only recipient, as other tools may publish, is accepted with
+	// a warning.
+	s, err := newWrapSecret()
+	if err != nil {
+		t.Fatalf("newWrapSecret failed: %v", err)
+	}
+	s.mlkem = nil
+	xOnlyPath := filepath.Join(dir, "x25519.json")
+	writeJSONValue(t, xOnlyPath, wrapRecipientJSON{X25519: hex.EncodeToString(s.x25519.PublicKey().Bytes())})
+	xOnlySecret := filepath.Join(dir, "x25519-secret.json")
+	writeJSONValue(t, xOnlySecret, wrapSecretJSON{X25519: hex.EncodeToString(s.x25519.Bytes())})
+	stderr = captureStderr(t, func() {
+		captureStdout(t, func() {
+			code = runExport([]string{"--key", keyPath, "--wrap-with", xOnlyPath, "--out", wrapped})
+		})
+	})
+	if code != 0 || !strings.Contains(stderr, "not post-quantum") || !strings.Contains(stderr, "not signed") {
+		t.Fatalf("x25519 export: exit %d, %q", code, stderr)
+	}
+	out = captureStdout(t, func() {
+		code = runImport([]string{"--unwrap-with", xOnlySecret, "--in", wrapped})
+	})
+	if code != 0 || out != string(want) {
+		t.Fatalf("x25519 import: exit %d, %q", code, out)
+	}
+}
+
+func writeJSONValue(t *testing.T, path string, v any) {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, b, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
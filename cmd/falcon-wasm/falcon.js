@@ -0,0 +1,49 @@
+// JavaScript wrapper for falcon.wasm (built with `make wasm`).
+//
+// Requires Go's wasm_exec.js (copied to build/ by `make wasm`) to be loaded
+// first, so that the global `Go` class is defined.
+//
+//   import { loadFalcon } from "./falcon.js";
+//   const falcon = await loadFalcon("falcon.wasm");
+//   falcon.verify(publicKey, message, signature); // true or false
+//   falcon.fingerprint(publicKey);               // hex SHA-256 of the key
+//   falcon.address(publicKey);                   // Algorand PQ account address
+//
+// Byte arguments may be Uint8Arrays or hex strings. Malformed inputs throw.
+
+function toHex(value, name) {
+  if (typeof value === "string") {
+    return value;
+  }
+  if (value instanceof Uint8Array) {
+    return Array.from(value, (b) => b.toString(16).padStart(2, "0")).join("");
+  }
+  throw new TypeError(`${name} must be a Uint8Array or a hex string`);
+}
+
+function unwrap(out) {
+  if (out.error !== undefined) {
+    throw new Error(out.error);
+  }
+  return out.result;
+}
+
+export async function loadFalcon(wasmURL) {
+  const go = new Go();
+  const source = fetch(wasmURL);
+  const { instance } = await WebAssembly.instantiateStreaming(source, go.importObject);
+  go.run(instance); // runs until the page is closed
+  const api = globalThis.falconWasm;
+  return {
+    verify(publicKey, message, signature) {
+      return unwrap(api.verify(
+        toHex(publicKey, "publicKey"), toHex(message, "message"), toHex(signature, "signature")));
+    },
+    fingerprint(publicKey) {
+      return unwrap(api.fingerprint(toHex(publicKey, "publicKey")));
+    },
+    address(publicKey) {
+      return unwrap(api.address(toHex(publicKey, "publicKey")));
+    },
+  };
+}
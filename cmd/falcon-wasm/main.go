@@ -0,0 +1,109 @@
+//go:build js && wasm
+
+// Command falcon-wasm exposes FALCON signature verification, public key
+// fingerprints and PQ account address derivation to JavaScript, so web wallets
+// can validate PQ addresses client-side with the same code as the CLI.
+//
+// It registers a global "falconWasm" object whose functions take hex strings
+// and return {result} or {error}; falcon.js wraps them in a friendlier API.
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"syscall/js"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+)
+
+func main() {
+	js.Global().Set("falconWasm", js.ValueOf(map[string]any{
+		"verify":      js.FuncOf(verify),
+		"fingerprint": js.FuncOf(fingerprint),
+		"address":     js.FuncOf(address),
+	}))
+	select {} // keep the functions alive
+}
+
+// verify(publicKeyHex, messageHex, signatureHex) -> {result: bool}
+func verify(_ js.Value, args []js.Value) any {
+	if len(args) != 3 {
+		return failure(fmt.Errorf("verify expects publicKey, message and signature"))
+	}
+	pk, err := publicKeyArg(args[0])
+	if err != nil {
+		return failure(err)
+	}
+	msg, err := hexArg(args[1], "message")
+	if err != nil {
+		return failure(err)
+	}
+	sig, err := hexArg(args[2], "signature")
+	if err != nil {
+		return failure(err)
+	}
+	return success(falcongo.Verify(msg, sig, pk) == nil)
+}
+
+// fingerprint(publicKeyHex) -> {result: hex SHA-256 of the public key}
+func fingerprint(_ js.Value, args []js.Value) any {
+	if len(args) != 1 {
+		return failure(fmt.Errorf("fingerprint expects publicKey"))
+	}
+	pk, err := publicKeyArg(args[0])
+	if err != nil {
+		return failure(err)
+	}
+	fp := falcongo.Fingerprint(pk)
+	return success(hex.EncodeToString(fp[:]))
+}
+
+// address(publicKeyHex) -> {result: Algorand address of the PQ logicsig}
+func address(_ js.Value, args []js.Value) any {
+	if len(args) != 1 {
+		return failure(fmt.Errorf("address expects publicKey"))
+	}
+	pk, err := publicKeyArg(args[0])
+	if err != nil {
+		return failure(err)
+	}
+	addr, err := algorand.GetAddressFromPublicKey(pk)
+	if err != nil {
+		return failure(err)
+	}
+	return success(string(addr))
+}
+
+func hexArg(v js.Value, name string) ([]byte, error) {
+	if v.Type() != js.TypeString {
+		return nil, fmt.Errorf("%s must be a hex string", name)
+	}
+	b, err := hex.DecodeString(strings.TrimPrefix(v.String(), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s hex: %w", name, err)
+	}
+	return b, nil
+}
+
+func publicKeyArg(v js.Value) (falcongo.PublicKey, error) {
+	var pk falcongo.PublicKey
+	b, err := hexArg(v, "publicKey")
+	if err != nil {
+		return pk, err
+	}
+	if len(b) != len(pk) {
+		return pk, fmt.Errorf("publicKey must be %d bytes, got %d", len(pk), len(b))
+	}
+	copy(pk[:], b)
+	return pk, nil
+}
+
+func success(result any) any {
+	return map[string]any{"result": result}
+}
+
+func failure(err error) any {
+	return map[string]any{"error": err.Error()}
+}
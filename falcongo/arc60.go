@@ -0,0 +1,109 @@
+package falcongo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/algorandfoundation/falcon-signatures/hashing"
+)
+
+// ARC-60 (Algorand wallet arbitrary signing): a dApp asks the wallet to sign
+// a structured payload instead of a transaction. For the AUTH scope the data
+// is client data JSON (such as {"type":"arc60.create","challenge":...,
+// "origin":...}) and the authenticator data starts with the SHA-256 of the
+// domain asking, as in WebAuthn. The signed bytes are
+//
+//	SHA-256(canonical client data JSON) || SHA-256(authenticator data)
+//
+// which are checked not to start with an Algorand domain-separation prefix,
+// so they can never pass for a transaction, program or other signed object.
+
+// SignDataScope is the ARC-60 scope of a request.
+type SignDataScope int
+
+// ScopeAuth is the ARC-60 scope of authentication requests, the only one
+// defined.
+const ScopeAuth SignDataScope = 1
+
+// arc60AuthenticatorDataMinSize is the SHA-256 of the domain, a flags byte
+// and a 4-byte signature counter.
+const arc60AuthenticatorDataMinSize = 32 + 1 + 4
+
+// ErrInvalidSignData is returned for an ARC-60 request a wallet must refuse.
+var ErrInvalidSignData = errors.New("invalid ARC-60 sign data request")
+
+// arc60ForbiddenPrefixes are the domain-separation prefixes of Algorand that
+// ARC-60 payloads must not start with.
+var arc60ForbiddenPrefixes = []string{
+	PrefixTransaction, PrefixBytes, PrefixProgram, PrefixProgramData,
+	"TG", "BH", "B256", "BR", "CR", "GE", "KP", "MA", "OT1", "OT2", "PF", "PK",
+	"PL", "PS", "SD", "SpecialAddr", "STIB", "TE", "TL", "VO", "appID", "arc",
+	"aB", "aD", "aO", "aP", "aS", "AS", "spc", "spm", "spp", "sps", "spv",
+}
+
+// SignDataRequest is an ARC-60 request, decoded from its transport encoding.
+type SignDataRequest struct {
+	Scope SignDataScope
+	// Data is the client data JSON. It is canonicalized (RFC 8785) before it
+	// is hashed, so any serialization of the same data signs the same bytes.
+	Data []byte
+	// Domain is the origin the request comes from, e.g. "arc60.io".
+	Domain            string
+	AuthenticatorData []byte
+}
+
+// Payload returns the bytes signed for r, or an error wrapping
+// ErrInvalidSignData if a wallet must refuse r.
+func (r SignDataRequest) Payload() ([]byte, error) {
+	if r.Scope != ScopeAuth {
+		return nil, fmt.Errorf("%w: unsupported scope %d", ErrInvalidSignData, r.Scope)
+	}
+	data, err := CanonicalizeJSON(r.Data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: data is not JSON: %v", ErrInvalidSignData, err)
+	}
+	if hasForbiddenPrefix(data) {
+		return nil, fmt.Errorf("%w: data starts with an Algorand domain prefix", ErrInvalidSignData)
+	}
+	if len(r.AuthenticatorData) < arc60AuthenticatorDataMinSize {
+		return nil, fmt.Errorf("%w: authenticator data of %d bytes is too short",
+			ErrInvalidSignData, len(r.AuthenticatorData))
+	}
+	if !bytes.Equal(r.AuthenticatorData[:32], hashing.SHA256.Sum([]byte(r.Domain))) {
+		return nil, fmt.Errorf("%w: authenticator data is not for domain %q", ErrInvalidSignData, r.Domain)
+	}
+	payload := hashing.SHA256.Sum(data)
+	payload = append(payload, hashing.SHA256.Sum(r.AuthenticatorData)...)
+	if hasForbiddenPrefix(payload) {
+		return nil, fmt.Errorf("%w: payload starts with an Algorand domain prefix", ErrInvalidSignData)
+	}
+	return payload, nil
+}
+
+// SignData signs the payload of the ARC-60 request r.
+func (d *KeyPair) SignData(r SignDataRequest) (CompressedSignature, error) {
+	payload, err := r.Payload()
+	if err != nil {
+		return nil, err
+	}
+	return d.Sign(payload)
+}
+
+// VerifySignData verifies a signature made by SignData.
+func VerifySignData(r SignDataRequest, sig CompressedSignature, pk PublicKey) error {
+	payload, err := r.Payload()
+	if err != nil {
+		return err
+	}
+	return Verify(payload, sig, pk)
+}
+
+func hasForbiddenPrefix(b []byte) bool {
+	for _, p := range arc60ForbiddenPrefixes {
+		if bytes.HasPrefix(b, []byte(p)) {
+			return true
+		}
+	}
+	return false
+}
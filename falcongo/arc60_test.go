@@ -0,0 +1,71 @@
+//go:build cgo && !purego
+
+package falcongo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"testing"
+)
+
+// TestSignData signs an ARC-60 authentication request and checks the
+// requests a wallet must refuse.
+func TestSignData(t *testing.T) {
+	kp, err := GenerateKeyPair(testSeed([]byte("arc60 sign data")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	domainHash := sha256.Sum256([]byte("arc60.io"))
+	authData := append(domainHash[:], 0x41, 0, 0, 0, 1)
+	req := SignDataRequest{
+		Scope:             ScopeAuth,
+		Data:              []byte(`{"type":"arc60.create","origin":"https://arc60.io","challenge":"eSE6M6w7..."}`),
+		Domain:            "arc60.io",
+		AuthenticatorData: authData,
+	}
+
+	payload, err := req.Payload()
+	if err != nil {
+		t.Fatalf("Payload failed: %v", err)
+	}
+	dataHash := sha256.Sum256([]byte(`{"challenge":"eSE6M6w7...","origin":"https://arc60.io","type":"arc60.create"}`))
+	authHash := sha256.Sum256(authData)
+	if !bytes.Equal(payload, append(dataHash[:], authHash[:]...)) {
+		t.Fatalf("payload is not SHA-256(canonical data) || SHA-256(authenticator data)")
+	}
+
+	sig, err := kp.SignData(req)
+	if err != nil {
+		t.Fatalf("SignData failed: %v", err)
+	}
+	reformatted := req
+	reformatted.Data = []byte("{ \"challenge\": \"eSE6M6w7...\",\n \"type\": \"arc60.create\", \"origin\": \"https://arc60.io\" }")
+	if err := VerifySignData(reformatted, sig, kp.PublicKey); err != nil {
+		t.Fatalf("VerifySignData failed for the same data reformatted: %v", err)
+	}
+	other := req
+	other.Data = []byte(`{"type":"arc60.create","origin":"https://evil.example","challenge":"eSE6M6w7..."}`)
+	if err := VerifySignData(other, sig, kp.PublicKey); err == nil {
+		t.Fatalf("signature verified for other data")
+	}
+
+	for name, mutate := range map[string]func(r *SignDataRequest){
+		"scope":       func(r *SignDataRequest) { r.Scope = 2 },
+		"not json":    func(r *SignDataRequest) { r.Data = []byte("TX not json") },
+		"domain":      func(r *SignDataRequest) { r.Domain = "evil.example" },
+		"short authn": func(r *SignDataRequest) { r.AuthenticatorData = domainHash[:] },
+	} {
+		bad := req
+		mutate(&bad)
+		if _, err := kp.SignData(bad); !errors.Is(err, ErrInvalidSignData) {
+			t.Fatalf("%s: expected ErrInvalidSignData, got %v", name, err)
+		}
+		if err := VerifySignData(bad, sig, kp.PublicKey); !errors.Is(err, ErrInvalidSignData) {
+			t.Fatalf("%s: expected ErrInvalidSignData from verify, got %v", name, err)
+		}
+	}
+	if !hasForbiddenPrefix([]byte("ProgData...")) || hasForbiddenPrefix([]byte(`{"a":1}`)) {
+		t.Fatalf("unexpected forbidden prefix check")
+	}
+}
@@ -0,0 +1,46 @@
+package falcongo
+
+import "runtime"
+
+// Capability values of the two builds of the package.
+const (
+	// BackendCgo is the C implementation of github.com/algorand/falcon.
+	BackendCgo = "cgo"
+	// BackendPureGo is the pure-Go verifier alone, without cgo or with the
+	// purego build tag.
+	BackendPureGo = "purego"
+)
+
+// BuildCapabilities describes the FALCON implementation the package was
+// built with, for bug reports and diagnostics.
+type BuildCapabilities struct {
+	// Backend is BackendCgo or BackendPureGo.
+	Backend string `json:"backend"`
+	// Signing reports whether key generation and deterministic signing are
+	// available in this build; SigningDisabled whether DisableSigning has
+	// since turned signing off.
+	Signing         bool `json:"signing"`
+	SigningDisabled bool `json:"signing_disabled"`
+	// Verifier is the implementation of Verify: "c" or "go".
+	Verifier string `json:"verifier"`
+	// FloatingPoint is how signing computes in floating point: "emulated"
+	// (constant-time, integer instructions) for the C implementation, empty
+	// without signing.
+	FloatingPoint string `json:"floating_point,omitempty"`
+	// CPUFeatures lists the optional CPU features the implementation uses.
+	// It is empty: the C implementation is built with AVX2 and FMA off, and
+	// the Go one is portable.
+	CPUFeatures []string `json:"cpu_features"`
+	// GOOS and GOARCH are the platform of the build.
+	GOOS   string `json:"goos"`
+	GOARCH string `json:"goarch"`
+}
+
+// Capabilities returns the capabilities of the build.
+func Capabilities() BuildCapabilities {
+	c := buildCapabilities
+	c.SigningDisabled = SigningDisabled()
+	c.CPUFeatures = []string{}
+	c.GOOS, c.GOARCH = runtime.GOOS, runtime.GOARCH
+	return c
+}
@@ -0,0 +1,28 @@
+package falcongo
+
+import (
+	"runtime"
+	"testing"
+)
+
+// TestCapabilities checks the report against the build: signing works
+// exactly when it says so.
+func TestCapabilities(t *testing.T) {
+	c := Capabilities()
+	if c.GOOS != runtime.GOOS || c.GOARCH != runtime.GOARCH || c.CPUFeatures == nil {
+		t.Fatalf("unexpected platform report %+v", c)
+	}
+	_, err := GenerateKeyPair(make([]byte, 48))
+	switch c.Backend {
+	case BackendCgo:
+		if !c.Signing || c.Verifier != "c" || err != nil {
+			t.Fatalf("cgo build: %+v, GenerateKeyPair: %v", c, err)
+		}
+	case BackendPureGo:
+		if c.Signing || c.Verifier != "go" || err == nil {
+			t.Fatalf("pure-Go build: %+v, GenerateKeyPair: %v", c, err)
+		}
+	default:
+		t.Fatalf("unknown backend %q", c.Backend)
+	}
+}
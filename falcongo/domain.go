@@ -0,0 +1,89 @@
+package falcongo
+
+import (
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// Domain-separation prefixes that Algorand prepends to the bytes it signs or
+// hashes, so that a signature made for one purpose cannot be replayed for
+// another.
+const (
+	// PrefixTransaction prefixes an encoded transaction; the transaction ID is
+	// the SHA-512/256 digest of the prefixed bytes.
+	PrefixTransaction = "TX"
+	// PrefixBytes prefixes arbitrary bytes signed off-chain, as the SDK's
+	// SignBytes does.
+	PrefixBytes = "MX"
+	// PrefixProgram prefixes a TEAL program; signing the prefixed program
+	// delegates the signer's authority to it.
+	PrefixProgram = "Program"
+	// PrefixProgramData prefixes data signed for a program to check with
+	// ed25519verify, after the address of the program.
+	PrefixProgramData = "ProgData"
+)
+
+// SignWithPrefix signs prefix || data.
+func (d *KeyPair) SignWithPrefix(prefix string, data []byte) (CompressedSignature, error) {
+	return d.Sign(withPrefix(prefix, data))
+}
+
+// VerifyWithPrefix verifies a signature of prefix || data made by
+// SignWithPrefix.
+func VerifyWithPrefix(prefix string, data []byte, sig CompressedSignature, pk PublicKey) error {
+	return Verify(withPrefix(prefix, data), sig, pk)
+}
+
+// SignBytes signs arbitrary bytes behind PrefixBytes, so the signature can
+// never pass for a signature of a transaction or program.
+func (d *KeyPair) SignBytes(data []byte) (CompressedSignature, error) {
+	return d.SignWithPrefix(PrefixBytes, data)
+}
+
+// VerifyBytes verifies a signature made by SignBytes.
+func VerifyBytes(data []byte, sig CompressedSignature, pk PublicKey) error {
+	return VerifyWithPrefix(PrefixBytes, data, sig, pk)
+}
+
+// SignTransactionID signs the raw 32-byte ID of txn, which already commits to
+// PrefixTransaction. This is the signature that the PQ logicsig checks with
+// falcon_verify against txn TxID.
+func (d *KeyPair) SignTransactionID(txn types.Transaction) (CompressedSignature, error) {
+	return d.Sign(crypto.TransactionID(txn))
+}
+
+// VerifyTransactionID verifies a signature made by SignTransactionID.
+func VerifyTransactionID(txn types.Transaction, sig CompressedSignature, pk PublicKey) error {
+	return Verify(crypto.TransactionID(txn), sig, pk)
+}
+
+// SignProgram signs program behind PrefixProgram.
+func (d *KeyPair) SignProgram(program []byte) (CompressedSignature, error) {
+	return d.SignWithPrefix(PrefixProgram, program)
+}
+
+// VerifyProgram verifies a signature made by SignProgram.
+func VerifyProgram(program []byte, sig CompressedSignature, pk PublicKey) error {
+	return VerifyWithPrefix(PrefixProgram, program, sig, pk)
+}
+
+// SignProgramData signs data for program as the SDK's TealSign does:
+// PrefixProgramData || address of program || data.
+func (d *KeyPair) SignProgramData(program, data []byte) (CompressedSignature, error) {
+	return d.Sign(programData(program, data))
+}
+
+// VerifyProgramData verifies a signature made by SignProgramData.
+func VerifyProgramData(program, data []byte, sig CompressedSignature, pk PublicKey) error {
+	return Verify(programData(program, data), sig, pk)
+}
+
+func withPrefix(prefix string, data []byte) []byte {
+	msg := make([]byte, 0, len(prefix)+len(data))
+	return append(append(msg, prefix...), data...)
+}
+
+func programData(program, data []byte) []byte {
+	address := crypto.AddressFromProgram(program)
+	return withPrefix(PrefixProgramData, append(address[:], data...))
+}
@@ -0,0 +1,75 @@
+//go:build cgo && !purego
+
+package falcongo
+
+import (
+	"testing"
+
+	"github.com/algorand/go-algorand-sdk/v2/crypto"
+	"github.com/algorand/go-algorand-sdk/v2/types"
+)
+
+// TestDomainPrefixes checks that each helper signs the prefixed bytes Algorand
+// expects and that a signature made for one domain fails in the others.
+func TestDomainPrefixes(t *testing.T) {
+	kp, err := GenerateKeyPair(testSeed([]byte("domain prefixes")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	data := []byte("hello")
+	program := []byte{0x0c, 0x81, 0x01}
+	txn := types.Transaction{Type: types.PaymentTx, Header: types.Header{Fee: 1000, FirstValid: 1, LastValid: 10}}
+	programAddress := crypto.AddressFromProgram(program)
+
+	signatures := map[string]CompressedSignature{}
+	for name, sign := range map[string]func() (CompressedSignature, error){
+		"bytes":        func() (CompressedSignature, error) { return kp.SignBytes(data) },
+		"program":      func() (CompressedSignature, error) { return kp.SignProgram(program) },
+		"program data": func() (CompressedSignature, error) { return kp.SignProgramData(program, data) },
+		"transaction":  func() (CompressedSignature, error) { return kp.SignTransactionID(txn) },
+	} {
+		if signatures[name], err = sign(); err != nil {
+			t.Fatalf("%s failed: %v", name, err)
+		}
+	}
+
+	verifiers := map[string]func(CompressedSignature) error{
+		"bytes":   func(s CompressedSignature) error { return VerifyBytes(data, s, kp.PublicKey) },
+		"program": func(s CompressedSignature) error { return VerifyProgram(program, s, kp.PublicKey) },
+		"program data": func(s CompressedSignature) error {
+			return VerifyProgramData(program, data, s, kp.PublicKey)
+		},
+		"transaction": func(s CompressedSignature) error { return VerifyTransactionID(txn, s, kp.PublicKey) },
+	}
+	raw := map[string][]byte{
+		"bytes":        append([]byte("MX"), data...),
+		"program":      append([]byte("Program"), program...),
+		"program data": append(append([]byte("ProgData"), programAddress[:]...), data...),
+		"transaction":  crypto.TransactionID(txn),
+	}
+	for name, sig := range signatures {
+		if err := Verify(raw[name], sig, kp.PublicKey); err != nil {
+			t.Fatalf("%s: signature is not over the prefixed bytes: %v", name, err)
+		}
+		for other, verify := range verifiers {
+			err := verify(sig)
+			if other == name && err != nil {
+				t.Fatalf("%s: verification failed: %v", name, err)
+			}
+			if other != name && err == nil {
+				t.Fatalf("%s signature verified as %s", name, other)
+			}
+		}
+	}
+
+	sig, err := kp.SignWithPrefix("X", data)
+	if err != nil {
+		t.Fatalf("SignWithPrefix failed: %v", err)
+	}
+	if err := VerifyWithPrefix("X", data, sig, kp.PublicKey); err != nil {
+		t.Fatalf("VerifyWithPrefix failed: %v", err)
+	}
+	if err := Verify(data, sig, kp.PublicKey); err == nil {
+		t.Fatalf("prefixed signature verified without its prefix")
+	}
+}
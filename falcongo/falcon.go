@@ -1,3 +1,5 @@
+//go:build cgo && !purego
+
 package falcongo
 
 import (
@@ -9,18 +11,37 @@ import (
 
 type PublicKey = falcon.PublicKey
 type PrivateKey = falcon.PrivateKey
+type CompressedSignature = falcon.CompressedSignature
+
+// The sizes in sizes.go must match the C implementation; a mismatch indexes
+// out of range and fails to compile.
+var (
+	_ = [1]struct{}{}[PublicKeySize-falcon.PublicKeySize]
+	_ = [1]struct{}{}[PrivateKeySize-falcon.PrivateKeySize]
+	_ = [1]struct{}{}[MaxCompressedSignatureSize-falcon.SignatureMaxSize]
+	_ = [1]struct{}{}[CTSignatureSize-falcon.CTSignatureSize]
+)
 
-// KeyPair groups a Falcon-1024 public/private key.
-type KeyPair struct {
-	PublicKey  PublicKey
-	PrivateKey PrivateKey
+// buildCapabilities is the implementation of this build, see Capabilities:
+// github.com/algorand/falcon pins FALCON_FPEMU on and FALCON_AVX2 and
+// FALCON_FMA off in its config.h.
+var buildCapabilities = BuildCapabilities{
+	Backend:       BackendCgo,
+	Signing:       true,
+	Verifier:      "c",
+	FloatingPoint: "emulated",
 }
 
-// GenerateKeyPair generates a new Falcon keypair from a given seed.
-// If the seed is empty, a random 48-byte seed is generated.
+// GenerateKeyPair generates a new Falcon keypair from a given seed, which must
+// be exactly SeedSize bytes long (ErrInvalidSeedSize otherwise); derive seeds
+// of other lengths with SeedFromBytes. If the seed is nil, a random
+// SeedSize-byte seed is generated.
 func GenerateKeyPair(seed []byte) (KeyPair, error) {
-	if len(seed) == 0 {
-		randomSeed := [48]byte{}
+	if err := checkSeed(seed); err != nil {
+		return KeyPair{}, err
+	}
+	if seed == nil {
+		randomSeed := [SeedSize]byte{}
 		_, err := rand.Read(randomSeed[:])
 		if err != nil {
 			panic(fmt.Sprintf("crypto/rand should never fail: %s", err))
@@ -32,18 +53,39 @@ func GenerateKeyPair(seed []byte) (KeyPair, error) {
 }
 
 // Sign signs the provided bytes using the private key and returns a compressed signature.
-func (d *KeyPair) Sign(data []byte) (falcon.CompressedSignature, error) {
+func (d *KeyPair) Sign(data []byte) (CompressedSignature, error) {
+	if SigningDisabled() {
+		return nil, ErrSigningDisabled
+	}
 	signedData, err := (*falcon.PrivateKey)(&d.PrivateKey).SignCompressed(data)
-	return falcon.CompressedSignature(signedData), err
+	return CompressedSignature(signedData), err
 }
 
 // Verify verifies the signature of the provided data using the public key.
-func Verify(data []byte, sig falcon.CompressedSignature, pk falcon.PublicKey) error {
+// Malformed signatures are reported with a *SignatureParseError without
+// reaching the C implementation.
+func Verify(data []byte, sig CompressedSignature, pk PublicKey) error {
+	if err := CheckCompressedSignature(sig); err != nil {
+		return err
+	}
 	return pk.Verify(sig, data)
 }
 
+// verifyFixedLength verifies a deterministic CT signature.
+func verifyFixedLength(data []byte, sig []byte, pk PublicKey) error {
+	if err := checkCTSignature(sig); err != nil {
+		return err
+	}
+	var ct falcon.CTSignature
+	copy(ct[:], sig)
+	return pk.VerifyCTSignature(ct, data)
+}
+
 // GetFixedLengthSignature converts a compressed signature to its fixed-length form.
-func GetFixedLengthSignature(sig falcon.CompressedSignature) ([]byte, error) {
+func GetFixedLengthSignature(sig CompressedSignature) ([]byte, error) {
+	if err := CheckCompressedSignature(sig); err != nil {
+		return nil, err
+	}
 	ctSignature, err := sig.ConvertToCT()
 	return ctSignature[:], err
 }
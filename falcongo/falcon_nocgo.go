@@ -0,0 +1,57 @@
+//go:build !cgo || purego
+
+package falcongo
+
+import "errors"
+
+// Without cgo (e.g. GOOS=js GOARCH=wasm), or with the purego build tag, the C
+// Falcon implementation is not linked: keys and signatures keep their usual
+// sizes and verification uses the pure-Go verifier, but key generation and
+// signing are not supported.
+
+type PublicKey [PublicKeySize]byte
+type PrivateKey [PrivateKeySize]byte
+type CompressedSignature []byte
+
+// ErrCgoRequired is returned by the operations that need the C Falcon
+// implementation when the package is built without cgo or with purego.
+var ErrCgoRequired = errors.New("falcon key generation and signing require cgo (and no purego build tag)")
+
+// buildCapabilities is the implementation of this build, see Capabilities.
+var buildCapabilities = BuildCapabilities{
+	Backend:  BackendPureGo,
+	Verifier: "go",
+}
+
+// GenerateKeyPair is not supported in this build; it returns ErrCgoRequired.
+func GenerateKeyPair(seed []byte) (KeyPair, error) {
+	return KeyPair{}, ErrCgoRequired
+}
+
+// Sign is not supported in this build; it returns ErrCgoRequired.
+func (d *KeyPair) Sign(data []byte) (CompressedSignature, error) {
+	return nil, ErrCgoRequired
+}
+
+// Verify verifies the signature of the provided data using the public key.
+// Malformed signatures are reported with a *SignatureParseError.
+func Verify(data []byte, sig CompressedSignature, pk PublicKey) error {
+	if err := CheckCompressedSignature(sig); err != nil {
+		return err
+	}
+	return verifyCompressed(pk[:], sig, data)
+}
+
+// verifyFixedLength verifies a deterministic CT signature.
+func verifyFixedLength(data []byte, sig []byte, pk PublicKey) error {
+	if err := checkCTSignature(sig); err != nil {
+		return err
+	}
+	return verifyCT(pk[:], sig, data)
+}
+
+// GetFixedLengthSignature is not supported in this build; it returns
+// ErrCgoRequired.
+func GetFixedLengthSignature(sig CompressedSignature) ([]byte, error) {
+	return nil, ErrCgoRequired
+}
@@ -1,9 +1,15 @@
+//go:build cgo && !purego
+
 package falcongo
 
 import (
 	"bytes"
 	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -28,22 +34,58 @@ func TestGenerateFalconKeyPair_WithoutSeed(t *testing.T) {
 	}
 }
 
-// TestGenerateFalconKeyPair_WithEmptySeed ensures empty seeds are treated as random.
+// TestGenerateFalconKeyPair_WithEmptySeed ensures only a nil seed is treated
+// as random: an empty seed is as likely a failed read as a request for one.
 func TestGenerateFalconKeyPair_WithEmptySeed(t *testing.T) {
-	emptySeed := []byte{}
-	keypair1, err := GenerateKeyPair(emptySeed)
-	if err != nil {
-		t.Fatalf("Failed to generate keypair with empty seed: %v", err)
+	if _, err := GenerateKeyPair([]byte{}); !errors.Is(err, ErrInvalidSeedSize) {
+		t.Fatalf("GenerateKeyPair(empty seed) error = %v, want ErrInvalidSeedSize", err)
+	}
+}
+
+// TestGenerateFalconKeyPair_SeedSize checks that seeds shorter or longer than
+// SeedSize are refused rather than used as they are.
+func TestGenerateFalconKeyPair_SeedSize(t *testing.T) {
+	for _, n := range []int{1, SeedSize - 1, SeedSize + 1, 64} {
+		if _, err := GenerateKeyPair(make([]byte, n)); !errors.Is(err, ErrInvalidSeedSize) {
+			t.Errorf("GenerateKeyPair(%d-byte seed) error = %v, want ErrInvalidSeedSize", n, err)
+		}
 	}
+}
 
-	keypair2, err := GenerateKeyPair(emptySeed)
+// TestSeedFromBytes checks that SeedSize-byte material is kept as is and that
+// other lengths derive distinct seeds.
+func TestSeedFromBytes(t *testing.T) {
+	full := bytes.Repeat([]byte{7}, SeedSize)
+	seed, err := SeedFromBytes(full)
+	if err != nil || !bytes.Equal(seed[:], full) {
+		t.Fatalf("SeedFromBytes(%d bytes) = %x, %v; want the input", SeedSize, seed, err)
+	}
+	short, err := SeedFromBytes(full[:SeedSize-1])
+	if err != nil {
+		t.Fatalf("SeedFromBytes(%d bytes) failed: %v", SeedSize-1, err)
+	}
+	long, err := SeedFromBytes(append(full, 7))
 	if err != nil {
-		t.Fatalf("Failed to generate second keypair with empty seed: %v", err)
+		t.Fatalf("SeedFromBytes(%d bytes) failed: %v", SeedSize+1, err)
+	}
+	if short == seed || long == seed || short == long {
+		t.Fatalf("SeedFromBytes gives equal seeds for different inputs")
+	}
+	if again, _ := SeedFromBytes(full[:SeedSize-1]); again != short {
+		t.Fatalf("SeedFromBytes is not deterministic")
+	}
+	if _, err := SeedFromBytes(nil); !errors.Is(err, ErrInvalidSeedSize) {
+		t.Fatalf("SeedFromBytes(nil) error = %v, want ErrInvalidSeedSize", err)
 	}
+}
 
-	if bytes.Equal(keypair1.PublicKey[:], keypair2.PublicKey[:]) {
-		t.Error("Keypairs with empty seed should be different (random generation)")
+// testSeed derives a keygen seed from test seed material of any length.
+func testSeed(b []byte) []byte {
+	seed, err := SeedFromBytes(b)
+	if err != nil {
+		panic(err)
 	}
+	return seed[:]
 }
 
 // TestGenerateFalconKeyPair_WithSeed verifies deterministic key generation.
@@ -289,7 +331,7 @@ func TestGetFixedLengthSignature(t *testing.T) {
 const (
 	expectedPublicKeySize              = 1793
 	expectedPrivateKeySize             = 2305
-	expectedUncompressedSignatureSize  = 1690
+	expectedUncompressedSignatureSize  = 1538
 	expectedMaxCompressedSignatureSize = 1423
 )
 
@@ -403,14 +445,13 @@ func TestFalconUncompressedSignatureSize(t *testing.T) {
 		t.Fatalf("Failed to get fixed-length signature: %v", err)
 	}
 
-	// Test uncompressed signature size: should be 1,690 bytes
+	// Test uncompressed signature size: should be 1,538 bytes
 	actualUncompressedSize := len(uncompressedSignature)
 
 	if actualUncompressedSize == expectedUncompressedSignatureSize {
 		t.Logf("✓ Uncompressed signature size matches expected: %d bytes", actualUncompressedSize)
 	} else {
-		t.Logf("⚠ Uncompressed signature size: %d bytes (expected %d bytes)", actualUncompressedSize, expectedUncompressedSignatureSize)
-		// Log but don't fail - the actual implementation might have different size
+		t.Errorf("Uncompressed signature size: %d bytes (expected %d bytes)", actualUncompressedSize, expectedUncompressedSignatureSize)
 	}
 
 	// Test multiple signatures have consistent uncompressed size
@@ -443,7 +484,7 @@ func TestSizeConsistencyAcrossKeyPairs(t *testing.T) {
 	}{
 		{"Random seed 1", nil},
 		{"Random seed 2", nil},
-		{"Fixed seed", []byte("this is a 48 byte seed for testing purposes!!")},
+		{"Fixed seed", []byte("this is a 48 byte seed for testing purposes!!!!!")},
 		{"Zero seed", make([]byte, 48)},
 	}
 
@@ -487,3 +528,217 @@ func TestSizeConsistencyAcrossKeyPairs(t *testing.T) {
 		})
 	}
 }
+
+// BenchmarkGenerateKeyPair measures allocations of key generation.
+func BenchmarkGenerateKeyPair(b *testing.B) {
+	seed := make([]byte, 48)
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := GenerateKeyPair(seed); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkSign measures allocations of the slice-returning Sign path.
+func BenchmarkSign(b *testing.B) {
+	keypair, err := GenerateKeyPair(make([]byte, 48))
+	if err != nil {
+		b.Fatalf("Failed to generate keypair: %v", err)
+	}
+	message := []byte("benchmark message")
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := keypair.Sign(message); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkVerify measures verification of a compressed signature.
+func BenchmarkVerify(b *testing.B) {
+	keypair, err := GenerateKeyPair(make([]byte, 48))
+	if err != nil {
+		b.Fatalf("Failed to generate keypair: %v", err)
+	}
+	message := []byte("benchmark message")
+	sig, err := keypair.Sign(message)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		if err := Verify(message, sig, keypair.PublicKey); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkVerifyParallel measures verification throughput across GOMAXPROCS
+// goroutines; the binding holds no shared state, so it scales with cores.
+func BenchmarkVerifyParallel(b *testing.B) {
+	keypair, err := GenerateKeyPair(make([]byte, 48))
+	if err != nil {
+		b.Fatalf("Failed to generate keypair: %v", err)
+	}
+	message := []byte("benchmark message")
+	sig, err := keypair.Sign(message)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if err := Verify(message, sig, keypair.PublicKey); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// TestSecretsEqual covers equal, differing, and length-mismatched inputs.
+func TestSecretsEqual(t *testing.T) {
+	testCases := []struct {
+		name string
+		a, b []byte
+		want bool
+	}{
+		{"equal", []byte("secret"), []byte("secret"), true},
+		{"both empty", []byte{}, nil, true},
+		{"last byte differs", []byte("secret"), []byte("secreT"), false},
+		{"first byte differs", []byte("secret"), []byte("Secret"), false},
+		{"prefix", []byte("secret"), []byte("secre"), false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := SecretsEqual(tc.a, tc.b); got != tc.want {
+				t.Errorf("SecretsEqual(%q, %q) = %v, want %v", tc.a, tc.b, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	var pk PublicKey
+	pk[0] = 1
+	want := sha256.Sum256(pk[:])
+	if Fingerprint(pk) != want {
+		t.Fatalf("Fingerprint is not the SHA-256 of the public key")
+	}
+	var other PublicKey
+	if Fingerprint(pk) == Fingerprint(other) {
+		t.Fatalf("distinct public keys must have distinct fingerprints")
+	}
+}
+
+func TestFingerprintFromHex(t *testing.T) {
+	var pk PublicKey
+	for i := range pk {
+		pk[i] = byte(i)
+	}
+	want := Fingerprint(pk)
+	h := hex.EncodeToString(pk[:])
+	for _, s := range []string{h, strings.ToUpper(h), " 0x" + h + "\n"} {
+		got, err := FingerprintFromHex(s)
+		if err != nil || got != want {
+			t.Fatalf("FingerprintFromHex(%.8q...) = %x, %v; want %x", s, got, err, want)
+		}
+	}
+	if got, err := FingerprintReader(bytes.NewReader(pk[:])); err != nil || got != want {
+		t.Fatalf("FingerprintReader = %x, %v; want %x", got, err, want)
+	}
+	for name, s := range map[string]string{
+		"short":   h[:len(h)-2],
+		"long":    h + "00",
+		"odd":     h + "0",
+		"not hex": "zz" + h[2:],
+		"empty":   "",
+	} {
+		if _, err := FingerprintFromHex(s); err == nil {
+			t.Errorf("%s: FingerprintFromHex accepted an invalid key", name)
+		}
+	}
+}
+
+func TestDeriveSubkey(t *testing.T) {
+	master, err := GenerateKeyPair(testSeed([]byte("master seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	ci, err := DeriveSubkey(master, "ci")
+	if err != nil {
+		t.Fatalf("DeriveSubkey failed: %v", err)
+	}
+	again, err := DeriveSubkey(master, "ci")
+	if err != nil {
+		t.Fatalf("DeriveSubkey failed: %v", err)
+	}
+	if ci != again {
+		t.Fatalf("DeriveSubkey is not deterministic")
+	}
+	payments, err := DeriveSubkey(master, "payments")
+	if err != nil {
+		t.Fatalf("DeriveSubkey failed: %v", err)
+	}
+	if ci.PublicKey == payments.PublicKey || ci.PublicKey == master.PublicKey {
+		t.Fatalf("subkeys must differ per label and from the master")
+	}
+	other, err := GenerateKeyPair(testSeed([]byte("other master")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	otherCI, err := DeriveSubkey(other, "ci")
+	if err != nil {
+		t.Fatalf("DeriveSubkey failed: %v", err)
+	}
+	if otherCI.PublicKey == ci.PublicKey {
+		t.Fatalf("subkeys must differ per master")
+	}
+	if _, err := DeriveSubkey(master, ""); err == nil {
+		t.Fatalf("expected error for empty label")
+	}
+	sig, err := ci.Sign([]byte("msg"))
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if err := Verify([]byte("msg"), sig, ci.PublicKey); err != nil {
+		t.Fatalf("subkey signature does not verify: %v", err)
+	}
+}
+
+// TestSignCanonicalJSON checks that a signature of a JSON document verifies
+// against any serialization of the same data, and only those.
+func TestSignCanonicalJSON(t *testing.T) {
+	kp, err := GenerateKeyPair(testSeed([]byte("canonical json")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	sig, err := kp.SignCanonicalJSON([]byte(`{"amount": 1.50, "to": "alice"}`))
+	if err != nil {
+		t.Fatalf("SignCanonicalJSON failed: %v", err)
+	}
+	if err := VerifyCanonicalJSON([]byte("{\n  \"to\": \"alice\",\n  \"amount\": 15e-1\n}"), sig, kp.PublicKey); err != nil {
+		t.Fatalf("VerifyCanonicalJSON failed on a reformatted document: %v", err)
+	}
+	if err := Verify([]byte(`{"amount":1.5,"to":"alice"}`), sig, kp.PublicKey); err != nil {
+		t.Fatalf("signature is not over the canonical form: %v", err)
+	}
+	if err := VerifyCanonicalJSON([]byte(`{"amount": 1.5, "to": "bob"}`), sig, kp.PublicKey); err == nil {
+		t.Fatalf("VerifyCanonicalJSON accepted different data")
+	}
+	if _, err := kp.SignCanonicalJSON([]byte(`{"a":1,"a":1}`)); err == nil {
+		t.Fatalf("SignCanonicalJSON accepted duplicate member names")
+	}
+}
+
+func TestDisableSigning(t *testing.T) {
+	kp, err := GenerateKeyPair(nil)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	t.Cleanup(func() { signingDisabled.Store(false) })
+	DisableSigning()
+	if _, err := kp.Sign([]byte("message")); !errors.Is(err, ErrSigningDisabled) {
+		t.Fatalf("Sign err = %v, want ErrSigningDisabled", err)
+	}
+}
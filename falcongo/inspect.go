@@ -0,0 +1,113 @@
+package falcongo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Signature encodings, from the high nibble of the header byte.
+const (
+	EncodingCompressed = "compressed"
+	EncodingPadded     = "padded"
+	EncodingCT         = "ct"
+	EncodingUnknown    = "unknown"
+)
+
+// Sizes of the salted (standard Falcon) signature layout: header, 40-byte
+// nonce, then s2.
+const (
+	saltedNonceSize      = 40
+	saltedPaddedSize1024 = 1280
+)
+
+// SignatureInfo describes a FALCON signature as parsed from its header,
+// without a public key or message.
+type SignatureInfo struct {
+	Length   int
+	Header   byte
+	Encoding string // EncodingCompressed, EncodingPadded, EncodingCT or EncodingUnknown
+	LogN     int    // log2 of the degree; 10 for FALCON-1024
+	// Deterministic is set for the deterministic variant used by
+	// algorand/falcon, whose header has the high bit set and whose second
+	// byte is a salt version replacing the 40-byte nonce.
+	Deterministic bool
+	SaltVersion   byte   // deterministic signatures only
+	Nonce         []byte // salted signatures only
+	// Issues lists the reasons, if any, why Verify rejects this signature
+	// whatever the key and message.
+	Issues []string
+}
+
+// InspectSignature parses the header of sig. It helps debug signatures from
+// other implementations, which may use the salted variant or another
+// encoding than the deterministic compressed signatures Verify accepts.
+func InspectSignature(sig []byte) (SignatureInfo, error) {
+	if len(sig) == 0 {
+		return SignatureInfo{}, errors.New("empty signature")
+	}
+	info := SignatureInfo{
+		Length:        len(sig),
+		Header:        sig[0],
+		LogN:          int(sig[0] & 0x0F),
+		Deterministic: sig[0]&0x80 != 0,
+	}
+	switch sig[0] & 0x70 {
+	case 0x30:
+		info.Encoding = EncodingCompressed
+	case 0x50:
+		info.Encoding = EncodingCT
+	default:
+		info.Encoding = EncodingUnknown
+	}
+	issue := func(format string, args ...any) {
+		info.Issues = append(info.Issues, fmt.Sprintf(format, args...))
+	}
+
+	if info.LogN != falconLogN {
+		issue("logn %d: only FALCON-1024 (logn %d) is supported", info.LogN, falconLogN)
+	}
+	if info.Encoding == EncodingUnknown {
+		issue("unknown encoding in header 0x%02x", sig[0])
+	}
+
+	if info.Deterministic {
+		if len(sig) < 2 {
+			issue("truncated: no salt version byte")
+			return info, nil
+		}
+		info.SaltVersion = sig[1]
+		switch {
+		case info.Encoding == EncodingCT:
+			if len(sig) != CTSignatureSize {
+				issue("CT signature of %d bytes, expected %d", len(sig), CTSignatureSize)
+			}
+			issue("CT encoding: Verify expects the compressed encoding (header 0x%02x); "+
+				"VerifyStrict with FormCT accepts it", detSigCompressedHeader)
+		case info.Encoding == EncodingCompressed && info.LogN == falconLogN:
+			if len(sig) > MaxCompressedSignatureSize {
+				issue("%d bytes, longer than the %d-byte maximum", len(sig), MaxCompressedSignatureSize)
+			} else if err := CheckCompressedSignature(sig); err != nil {
+				var pe *SignatureParseError
+				if errors.As(err, &pe) {
+					issue("s2 does not decode as compressed coefficients: %s (byte %d)", pe.Reason, pe.Offset)
+				}
+			}
+		}
+		return info, nil
+	}
+
+	// Salted signatures: header, 40-byte nonce, s2. Compressed and padded
+	// share a header and differ only in length.
+	if len(sig) < 1+saltedNonceSize {
+		issue("truncated: shorter than the header and %d-byte nonce", saltedNonceSize)
+		return info, nil
+	}
+	info.Nonce = append([]byte(nil), sig[1:1+saltedNonceSize]...)
+	if info.Encoding == EncodingCompressed && info.LogN == falconLogN && len(sig) == saltedPaddedSize1024 {
+		info.Encoding = EncodingPadded
+	}
+	issue("salted signature with a %d-byte nonce: Verify expects the deterministic "+
+		"variant of algorand/falcon (header 0x%02x, salt version byte)",
+		saltedNonceSize, detSigCompressedHeader)
+	return info, nil
+}
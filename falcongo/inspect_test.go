@@ -0,0 +1,69 @@
+//go:build cgo && !purego
+
+package falcongo
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestInspectSignature covers deterministic, CT and foreign encodings.
+func TestInspectSignature(t *testing.T) {
+	kp, err := GenerateKeyPair(testSeed([]byte("inspect signature seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	sig, err := kp.Sign([]byte("inspect"))
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	info, err := InspectSignature(sig)
+	if err != nil {
+		t.Fatalf("InspectSignature failed: %v", err)
+	}
+	if info.Length != len(sig) || info.Header != 0xBA || info.Encoding != EncodingCompressed ||
+		info.LogN != 10 || !info.Deterministic || info.SaltVersion != 0 || info.Nonce != nil ||
+		len(info.Issues) != 0 {
+		t.Fatalf("unexpected info for a valid signature: %+v", info)
+	}
+
+	ct, err := GetFixedLengthSignature(sig)
+	if err != nil {
+		t.Fatalf("GetFixedLengthSignature failed: %v", err)
+	}
+	info, _ = InspectSignature(ct)
+	if info.Encoding != EncodingCT || info.Length != CTSignatureSize || len(info.Issues) != 1 ||
+		!strings.Contains(info.Issues[0], "CT encoding") {
+		t.Fatalf("unexpected info for a CT signature: %+v", info)
+	}
+
+	trailing := append(append([]byte{}, sig...), 0xFF)
+	info, _ = InspectSignature(trailing)
+	if len(info.Issues) != 1 || !strings.Contains(info.Issues[0], "does not decode") {
+		t.Fatalf("unexpected issues for trailing bytes: %+v", info.Issues)
+	}
+
+	// A salted FALCON-1024 compressed signature: header, 40-byte nonce, s2.
+	salted := append([]byte{0x3A}, make([]byte, 40)...)
+	salted[1] = 0x77
+	salted = append(salted, sig[2:]...)
+	info, _ = InspectSignature(salted)
+	if info.Deterministic || info.Encoding != EncodingCompressed || len(info.Nonce) != 40 ||
+		info.Nonce[0] != 0x77 || len(info.Issues) != 1 || !strings.Contains(info.Issues[0], "salted") {
+		t.Fatalf("unexpected info for a salted signature: %+v", info)
+	}
+	padded := append(salted[:41:41], make([]byte, saltedPaddedSize1024-41)...)
+	if info, _ = InspectSignature(padded); info.Encoding != EncodingPadded {
+		t.Fatalf("expected padded encoding, got %+v", info)
+	}
+
+	info, _ = InspectSignature([]byte{0xB9, 0})
+	if info.LogN != 9 || len(info.Issues) != 1 || !strings.Contains(info.Issues[0], "logn 9") {
+		t.Fatalf("unexpected info for FALCON-512 header: %+v", info)
+	}
+
+	if _, err := InspectSignature(nil); err == nil {
+		t.Fatal("expected error for empty signature")
+	}
+}
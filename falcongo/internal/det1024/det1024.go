@@ -0,0 +1,308 @@
+// Package det1024 is a pure-Go verifier for deterministic FALCON-1024
+// signatures in compressed and CT format, matching
+// falcon_det1024_verify_compressed and falcon_det1024_verify_ct of the C
+// implementation. It is shared by falcongo, where cgo is unavailable, and by
+// falcongo/verifyonly. It is not constant-time, which verification does not
+// need.
+package det1024
+
+import (
+	"crypto/sha3"
+	"errors"
+	"fmt"
+)
+
+// Encoded sizes, as in the C implementation.
+const (
+	PublicKeySize    = 1793
+	SignatureMaxSize = 1423
+	CTSignatureSize  = 1538
+)
+
+const (
+	// Q is the FALCON modulus, and N = 2^LogN the degree of the polynomials.
+	Q    = 12289
+	LogN = 10
+	N    = 1 << LogN
+	// l2Bound is the maximum squared norm of (s1, s2) for n = 1024.
+	l2Bound = 70265242
+	// CompressedHeader is the header of a deterministic compressed
+	// signature: compressed format, logn = 10, and the high bit marking the
+	// deterministic variant.
+	CompressedHeader = 0x3A | 0x80
+	// CTHeader is the header of a deterministic CT signature.
+	CTHeader = 0x5A | 0x80
+	// SaltedHeader is the header of a standard (salted) compressed
+	// signature, as produced by the reference implementation and liboqs.
+	SaltedHeader = 0x3A
+	// NonceSize is the size of the salt of a standard signature.
+	NonceSize = 40
+	// ctCoefficientBits is the width of each s2 coefficient in CT format.
+	ctCoefficientBits = 12
+)
+
+// ErrVerify is returned for signatures that do not verify.
+var ErrVerify = errors.New("falcon verify failed")
+
+// ErrPublicKey is returned for bytes that do not encode a FALCON-1024 public
+// key.
+var ErrPublicKey = errors.New("invalid falcon public key")
+
+// VerifyCompressed reports whether sig is a valid deterministic compressed
+// signature of msg under the encoded public key pk.
+func VerifyCompressed(pk []byte, sig []byte, msg []byte) error {
+	if len(pk) != PublicKeySize || pk[0] != LogN {
+		return ErrPublicKey
+	}
+	if len(sig) < 2 || len(sig) > SignatureMaxSize || sig[0] != CompressedHeader {
+		return ErrVerify
+	}
+	s2, ok := DecodeCompressed(sig[2:])
+	if !ok {
+		return ErrVerify
+	}
+	return verifyS2(pk, detSalt(sig[1]), s2, msg)
+}
+
+// VerifyCT reports whether sig is a valid deterministic CT signature of msg
+// under the encoded public key pk.
+func VerifyCT(pk []byte, sig []byte, msg []byte) error {
+	if len(pk) != PublicKeySize || pk[0] != LogN {
+		return ErrPublicKey
+	}
+	if len(sig) != CTSignatureSize || sig[0] != CTHeader {
+		return ErrVerify
+	}
+	s2, ok := decodeCT(sig[2:])
+	if !ok {
+		return ErrVerify
+	}
+	return verifyS2(pk, detSalt(sig[1]), s2, msg)
+}
+
+// VerifySalted reports whether sig is a valid standard compressed signature
+// (header, 40-byte salt, s2) of msg under the encoded public key pk. This is
+// the encoding of the reference implementation and liboqs, which the
+// deterministic variant fixes the salt of; the interop tests use it to check
+// their signatures.
+func VerifySalted(pk []byte, sig []byte, msg []byte) error {
+	if len(pk) != PublicKeySize || pk[0] != LogN {
+		return ErrPublicKey
+	}
+	if len(sig) < 1+NonceSize || len(sig) > SignatureMaxSize+NonceSize-1 || sig[0] != SaltedHeader {
+		return ErrVerify
+	}
+	s2, ok := DecodeCompressed(sig[1+NonceSize:])
+	if !ok {
+		return ErrVerify
+	}
+	return verifyS2(pk, [NonceSize]byte(sig[1:1+NonceSize]), s2, msg)
+}
+
+// Resalt converts a deterministic compressed signature to the standard
+// encoding by writing out its fixed salt, as falcon_det1024_resalt does.
+// Standard verifiers accept the result.
+func Resalt(sig []byte) ([]byte, error) {
+	if len(sig) < 2 || len(sig) > SignatureMaxSize || sig[0] != CompressedHeader {
+		return nil, ErrVerify
+	}
+	salt := detSalt(sig[1])
+	out := make([]byte, 0, len(sig)+NonceSize-1)
+	out = append(out, sig[0]&^0x80)
+	out = append(out, salt[:]...)
+	return append(out, sig[2:]...), nil
+}
+
+// verifyS2 checks the decoded s2 of a signature with the given salt against
+// msg and the encoded public key pk.
+func verifyS2(pk []byte, salt [NonceSize]byte, s2 [N]int16, msg []byte) error {
+	h, ok := DecodeModQ(pk[1:])
+	if !ok {
+		return ErrPublicKey
+	}
+	c := hashToPoint(msg, salt)
+
+	// s1 = c - s2*h mod (x^n + 1) mod q, with coefficients in [-q/2, q/2].
+	var prod [N]int64
+	for i, a := range s2 {
+		if a == 0 {
+			continue
+		}
+		for j, b := range h {
+			if k := i + j; k < N {
+				prod[k] += int64(a) * int64(b)
+			} else {
+				prod[k-N] -= int64(a) * int64(b)
+			}
+		}
+	}
+	var norm uint64
+	for k := range N {
+		w := (int64(c[k]) - prod[k]) % Q
+		if w < 0 {
+			w += Q
+		}
+		if w > Q/2 {
+			w -= Q
+		}
+		norm += uint64(w*w) + uint64(int64(s2[k])*int64(s2[k]))
+	}
+	if norm > l2Bound {
+		return ErrVerify
+	}
+	return nil
+}
+
+// DecodeModQ decodes n 14-bit big-endian coefficients, each below q. Unused
+// bits at the end must be zero.
+func DecodeModQ(in []byte) (x [N]uint16, ok bool) {
+	if len(in) != (N*14+7)/8 {
+		return x, false
+	}
+	var acc uint32
+	accLen := 0
+	u := 0
+	for _, b := range in {
+		acc = acc<<8 | uint32(b)
+		accLen += 8
+		if accLen >= 14 {
+			accLen -= 14
+			w := (acc >> accLen) & 0x3FFF
+			if w >= Q {
+				return x, false
+			}
+			x[u] = uint16(w)
+			u++
+		}
+	}
+	return x, acc&(1<<accLen-1) == 0
+}
+
+// DecodeCompressed decodes the compressed encoding of s2: per coefficient a
+// sign bit, the low 7 bits of the absolute value and the high bits in unary.
+// All of in must be consumed, with zero padding bits.
+func DecodeCompressed(in []byte) (x [N]int16, ok bool) {
+	x, err := ParseCompressed(in)
+	return x, err == nil
+}
+
+// DecodeError tells where and why the compressed encoding of s2 does not
+// decode. Offset counts bytes from the start of the encoding.
+type DecodeError struct {
+	Offset int
+	Reason string
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("%s at byte %d of s2", e.Reason, e.Offset)
+}
+
+// ParseCompressed is DecodeCompressed, reporting failures as a *DecodeError.
+func ParseCompressed(in []byte) (x [N]int16, err error) {
+	var acc uint32
+	var accLen uint
+	v := 0
+	for u := range N {
+		if v >= len(in) {
+			return x, &DecodeError{Offset: v, Reason: fmt.Sprintf("truncated after %d of %d coefficients", u, N)}
+		}
+		acc = acc<<8 | uint32(in[v])
+		v++
+		b := acc >> accLen
+		s := b & 128
+		m := b & 127
+		for {
+			if accLen == 0 {
+				if v >= len(in) {
+					return x, &DecodeError{Offset: v, Reason: fmt.Sprintf("truncated in coefficient %d of %d", u, N)}
+				}
+				acc = acc<<8 | uint32(in[v])
+				v++
+				accLen = 8
+			}
+			accLen--
+			if (acc>>accLen)&1 != 0 {
+				break
+			}
+			m += 128
+			if m > 2047 {
+				return x, &DecodeError{Offset: v - 1, Reason: fmt.Sprintf("coefficient %d out of range", u)}
+			}
+		}
+		// "-0" is forbidden.
+		if s != 0 && m == 0 {
+			return x, &DecodeError{Offset: v - 1, Reason: fmt.Sprintf("coefficient %d is negative zero", u)}
+		}
+		if s != 0 {
+			x[u] = -int16(m)
+		} else {
+			x[u] = int16(m)
+		}
+	}
+	if v != len(in) {
+		return x, &DecodeError{Offset: v, Reason: fmt.Sprintf("%d trailing bytes", len(in)-v)}
+	}
+	if acc&(1<<accLen-1) != 0 {
+		return x, &DecodeError{Offset: v - 1, Reason: "non-zero padding bits"}
+	}
+	return x, nil
+}
+
+// decodeCT decodes the CT encoding of s2: n big-endian two's complement
+// coefficients of ctCoefficientBits bits each, the most negative value being
+// forbidden.
+func decodeCT(in []byte) (x [N]int16, ok bool) {
+	if len(in) != N*ctCoefficientBits/8 {
+		return x, false
+	}
+	const (
+		mask = 1<<ctCoefficientBits - 1
+		sign = 1 << (ctCoefficientBits - 1)
+	)
+	var acc uint32
+	accLen := 0
+	u := 0
+	for _, b := range in {
+		acc = acc<<8 | uint32(b)
+		accLen += 8
+		for accLen >= ctCoefficientBits {
+			accLen -= ctCoefficientBits
+			w := int32((acc >> accLen) & mask)
+			if w == sign {
+				return x, false
+			}
+			if w&sign != 0 {
+				w -= 1 << ctCoefficientBits
+			}
+			x[u] = int16(w)
+			u++
+		}
+	}
+	return x, true
+}
+
+// detSalt is the fixed salt of the deterministic variant for a salt version.
+func detSalt(saltVersion byte) (salt [NonceSize]byte) {
+	salt[0] = saltVersion
+	salt[1] = LogN
+	copy(salt[2:], "FALCON_DET")
+	return salt
+}
+
+// hashToPoint hashes msg to a polynomial with coefficients modulo q using
+// SHAKE256 over the salt.
+func hashToPoint(msg []byte, salt [NonceSize]byte) (c [N]uint16) {
+	shake := sha3.NewSHAKE256()
+	_, _ = shake.Write(salt[:])
+	_, _ = shake.Write(msg)
+	var buf [2]byte
+	for u := 0; u < N; {
+		_, _ = shake.Read(buf[:])
+		w := uint32(buf[0])<<8 | uint32(buf[1])
+		if w < 5*Q {
+			c[u] = uint16(w % Q)
+			u++
+		}
+	}
+	return c
+}
@@ -0,0 +1,363 @@
+//go:build interop && cgo && !purego
+
+package falcongo
+
+// The interop tests cross-check this package against other FALCON-1024
+// implementations: `go test -tags interop ./falcongo`, or make test-interop.
+// Every implementation found is tested, and the tests skip when there is none:
+//
+//   - OpenSSL with oqs-provider (liboqs), when `openssl list` shows falcon1024;
+//     FALCON_INTEROP_OPENSSL names another openssl binary.
+//   - FALCON_INTEROP_CMD, a command wrapping any implementation (such as the
+//     reference one) that reads and writes the raw standard encodings:
+//
+//     CMD keygen <pk-file> <sk-file>
+//     CMD sign <sk-file> <msg-file> <sig-file>
+//     CMD verify <pk-file> <msg-file> <sig-file>   exit 0 if valid, 1 if not
+//
+// Signatures of this package are converted to the standard salted encoding
+// with det1024.Resalt, and standard signatures are checked with
+// det1024.VerifySalted.
+
+import (
+	"bytes"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo/internal/det1024"
+)
+
+const (
+	envInteropCmd     = "FALCON_INTEROP_CMD"
+	envInteropOpenSSL = "FALCON_INTEROP_OPENSSL"
+)
+
+// interopKey is a key pair generated by another implementation.
+type interopKey struct {
+	pk PublicKey
+	// sk is the raw private key, or nil if the implementation does not
+	// expose it.
+	sk []byte
+	// file is the private key in the implementation's own format.
+	file string
+}
+
+// interopImpl is another implementation. Signatures are in the standard
+// salted encoding.
+type interopImpl struct {
+	name   string
+	keygen func(t *testing.T) interopKey
+	sign   func(t *testing.T, k interopKey, msg []byte) []byte
+	verify func(t *testing.T, pk PublicKey, msg, sig []byte) bool
+}
+
+var interopMessages = [][]byte{
+	{0},
+	[]byte("falcon interop"),
+	bytes.Repeat([]byte{0x00, 0xff, 0x5a}, 1500),
+}
+
+// interopImpls returns the implementations found, skipping t if none is.
+func interopImpls(t *testing.T) []interopImpl {
+	t.Helper()
+	var impls []interopImpl
+	if impl, ok := opensslImpl(t); ok {
+		impls = append(impls, impl)
+	}
+	if cmd := os.Getenv(envInteropCmd); cmd != "" {
+		impls = append(impls, commandImpl(cmd))
+	}
+	if len(impls) == 0 {
+		t.Skipf("no other FALCON-1024 implementation: install OpenSSL with oqs-provider or set %s", envInteropCmd)
+	}
+	return impls
+}
+
+// otherMessage returns a message that differs from msg.
+func otherMessage(msg []byte) []byte {
+	return append(bytes.Clone(msg), 1)
+}
+
+// TestInterop_OurSignatures checks that other implementations accept the
+// signatures of this package, and only for the signed message.
+func TestInterop_OurSignatures(t *testing.T) {
+	impls := interopImpls(t)
+	kp, err := GenerateKeyPair(nil)
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	for _, impl := range impls {
+		t.Run(impl.name, func(t *testing.T) {
+			for i, msg := range interopMessages {
+				sig, err := kp.Sign(msg)
+				if err != nil {
+					t.Fatalf("Sign failed: %v", err)
+				}
+				salted, err := det1024.Resalt(sig)
+				if err != nil {
+					t.Fatalf("Resalt failed: %v", err)
+				}
+				if !impl.verify(t, kp.PublicKey, msg, salted) {
+					t.Errorf("message %d: %s rejects the signature %x", i, impl.name, salted)
+				}
+				if impl.verify(t, kp.PublicKey, otherMessage(msg), salted) {
+					t.Errorf("message %d: %s accepts the signature for another message", i, impl.name)
+				}
+			}
+		})
+	}
+}
+
+// TestInterop_TheirSignatures checks that the pure-Go verifier accepts the
+// signatures of other implementations, and only for the signed message.
+func TestInterop_TheirSignatures(t *testing.T) {
+	for _, impl := range interopImpls(t) {
+		t.Run(impl.name, func(t *testing.T) {
+			k := impl.keygen(t)
+			for i, msg := range interopMessages {
+				sig := impl.sign(t, k, msg)
+				if err := det1024.VerifySalted(k.pk[:], sig, msg); err != nil {
+					t.Errorf("message %d: the signature %x of %s does not verify: %v", i, sig, impl.name, err)
+				}
+				if det1024.VerifySalted(k.pk[:], sig, otherMessage(msg)) == nil {
+					t.Errorf("message %d: the signature of %s verifies for another message", i, impl.name)
+				}
+			}
+		})
+	}
+}
+
+// TestInterop_TheirKeys checks that this package decodes the private keys of
+// other implementations: it derives the same public key, and its signatures
+// verify under both.
+func TestInterop_TheirKeys(t *testing.T) {
+	for _, impl := range interopImpls(t) {
+		t.Run(impl.name, func(t *testing.T) {
+			k := impl.keygen(t)
+			if k.sk == nil {
+				t.Skipf("%s does not expose its private keys", impl.name)
+			}
+			if len(k.sk) != PrivateKeySize {
+				t.Fatalf("%s private key is %d bytes, want %d", impl.name, len(k.sk), PrivateKeySize)
+			}
+			kp := KeyPair{PublicKey: k.pk, PrivateKey: PrivateKey(k.sk)}
+			pk, err := PublicKeyFromPrivate(kp.PrivateKey)
+			if err != nil {
+				t.Fatalf("PublicKeyFromPrivate failed: %v", err)
+			}
+			if pk != k.pk {
+				t.Fatalf("PublicKeyFromPrivate differs from the public key of %s", impl.name)
+			}
+			for i, msg := range interopMessages {
+				sig, err := kp.Sign(msg)
+				if err != nil {
+					t.Fatalf("Sign failed: %v", err)
+				}
+				if err := Verify(msg, sig, k.pk); err != nil {
+					t.Errorf("message %d: Verify failed: %v", i, err)
+				}
+				salted, err := det1024.Resalt(sig)
+				if err != nil {
+					t.Fatalf("Resalt failed: %v", err)
+				}
+				if !impl.verify(t, k.pk, msg, salted) {
+					t.Errorf("message %d: %s rejects a signature made with its own key", i, impl.name)
+				}
+			}
+		})
+	}
+}
+
+// writeInteropFile writes data to a new file under dir.
+func writeInteropFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+// readInteropFile reads a file written by another implementation.
+func readInteropFile(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return data
+}
+
+// runInterop runs an external command; a non-zero exit is returned as an
+// *exec.ExitError, and any other failure fails t.
+func runInterop(t *testing.T, name string, args ...string) ([]byte, error) {
+	t.Helper()
+	out, err := exec.Command(name, args...).CombinedOutput()
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		t.Fatalf("failed to run %s: %v", name, err)
+	}
+	return out, err
+}
+
+// mustRunInterop runs an external command that must succeed.
+func mustRunInterop(t *testing.T, name string, args ...string) {
+	t.Helper()
+	if out, err := runInterop(t, name, args...); err != nil {
+		t.Fatalf("%s %s: %v\n%s", name, strings.Join(args, " "), err, out)
+	}
+}
+
+// commandImpl is the implementation behind FALCON_INTEROP_CMD.
+func commandImpl(cmd string) interopImpl {
+	return interopImpl{
+		name: filepath.Base(cmd),
+		keygen: func(t *testing.T) interopKey {
+			dir := t.TempDir()
+			pkPath, skPath := filepath.Join(dir, "pk"), filepath.Join(dir, "sk")
+			mustRunInterop(t, cmd, "keygen", pkPath, skPath)
+			pk := readInteropFile(t, pkPath)
+			if len(pk) != PublicKeySize {
+				t.Fatalf("%s keygen wrote a %d-byte public key", cmd, len(pk))
+			}
+			return interopKey{pk: PublicKey(pk), sk: readInteropFile(t, skPath), file: skPath}
+		},
+		sign: func(t *testing.T, k interopKey, msg []byte) []byte {
+			dir := t.TempDir()
+			sigPath := filepath.Join(dir, "sig")
+			mustRunInterop(t, cmd, "sign", k.file, writeInteropFile(t, dir, "msg", msg), sigPath)
+			return readInteropFile(t, sigPath)
+		},
+		verify: func(t *testing.T, pk PublicKey, msg, sig []byte) bool {
+			dir := t.TempDir()
+			out, err := runInterop(t, cmd, "verify", writeInteropFile(t, dir, "pk", pk[:]),
+				writeInteropFile(t, dir, "msg", msg), writeInteropFile(t, dir, "sig", sig))
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) && exitErr.ExitCode() != 1 {
+				t.Fatalf("%s verify: %v\n%s", cmd, err, out)
+			}
+			return err == nil
+		},
+	}
+}
+
+// subjectPublicKeyInfo and oneAsymmetricKey are the DER containers of OpenSSL
+// public and private keys (RFC 5280 and RFC 5958).
+type subjectPublicKeyInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	PublicKey asn1.BitString
+}
+
+type oneAsymmetricKey struct {
+	Version    int
+	Algorithm  pkix.AlgorithmIdentifier
+	PrivateKey []byte
+	Attributes asn1.RawValue `asn1:"optional,tag:0"`
+	PublicKey  asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+// opensslImpl is OpenSSL with oqs-provider, if available. It takes the
+// algorithm identifier of its keys from a generated one, so that the OIDs,
+// which oqs-provider has changed between releases, need not be known.
+func opensslImpl(t *testing.T) (interopImpl, bool) {
+	bin := os.Getenv(envInteropOpenSSL)
+	if bin == "" {
+		bin = "openssl"
+	}
+	if _, err := exec.LookPath(bin); err != nil {
+		return interopImpl{}, false
+	}
+	providers := []string{"-provider", "oqsprovider", "-provider", "default"}
+	run := func(t *testing.T, args ...string) ([]byte, error) {
+		t.Helper()
+		return runInterop(t, bin, append(append(args[:1:1], providers...), args[1:]...)...)
+	}
+	mustRun := func(t *testing.T, args ...string) {
+		t.Helper()
+		if out, err := run(t, args...); err != nil {
+			t.Fatalf("openssl %s: %v\n%s", strings.Join(args, " "), err, out)
+		}
+	}
+	if out, err := run(t, "list", "-signature-algorithms"); err != nil ||
+		!strings.Contains(strings.ToLower(string(out)), "falcon1024") {
+		return interopImpl{}, false
+	}
+
+	var algorithm *pkix.AlgorithmIdentifier
+	keygen := func(t *testing.T) interopKey {
+		dir := t.TempDir()
+		keyPath, pubPath, derPath := filepath.Join(dir, "key.pem"), filepath.Join(dir, "pub.der"), filepath.Join(dir, "key.der")
+		mustRun(t, "genpkey", "-algorithm", "falcon1024", "-out", keyPath)
+		mustRun(t, "pkey", "-in", keyPath, "-pubout", "-outform", "DER", "-out", pubPath)
+		mustRun(t, "pkey", "-in", keyPath, "-outform", "DER", "-out", derPath)
+
+		var spki subjectPublicKeyInfo
+		if rest, err := asn1.Unmarshal(readInteropFile(t, pubPath), &spki); err != nil || len(rest) > 0 {
+			t.Fatalf("failed to parse the OpenSSL public key: %v", err)
+		}
+		if len(spki.PublicKey.Bytes) != PublicKeySize {
+			t.Fatalf("the OpenSSL public key is %d bytes, want %d", len(spki.PublicKey.Bytes), PublicKeySize)
+		}
+		algorithm = &spki.Algorithm
+		k := interopKey{pk: PublicKey(spki.PublicKey.Bytes), file: keyPath}
+
+		// oqs-provider stores the private key followed by the public key,
+		// in some releases wrapped in another OCTET STRING.
+		var key oneAsymmetricKey
+		if _, err := asn1.Unmarshal(readInteropFile(t, derPath), &key); err != nil {
+			t.Fatalf("failed to parse the OpenSSL private key: %v", err)
+		}
+		raw := key.PrivateKey
+		var inner []byte
+		if rest, err := asn1.Unmarshal(raw, &inner); err == nil && len(rest) == 0 {
+			raw = inner
+		}
+		if len(raw) >= PrivateKeySize && raw[0] == 0x50+det1024.LogN {
+			k.sk = raw[:PrivateKeySize]
+		} else {
+			t.Logf("unrecognized OpenSSL private key encoding of %d bytes", len(raw))
+		}
+		return k
+	}
+	return interopImpl{
+		name:   "openssl",
+		keygen: keygen,
+		sign: func(t *testing.T, k interopKey, msg []byte) []byte {
+			dir := t.TempDir()
+			sigPath := filepath.Join(dir, "sig")
+			mustRun(t, "pkeyutl", "-sign", "-rawin", "-inkey", k.file,
+				"-in", writeInteropFile(t, dir, "msg", msg), "-out", sigPath)
+			return readInteropFile(t, sigPath)
+		},
+		verify: func(t *testing.T, pk PublicKey, msg, sig []byte) bool {
+			if algorithm == nil {
+				keygen(t)
+			}
+			der, err := asn1.Marshal(subjectPublicKeyInfo{
+				Algorithm: *algorithm,
+				PublicKey: asn1.BitString{Bytes: pk[:], BitLength: 8 * len(pk)},
+			})
+			if err != nil {
+				t.Fatalf("failed to encode the public key: %v", err)
+			}
+			dir := t.TempDir()
+			out, err := run(t, "pkeyutl", "-verify", "-rawin", "-pubin", "-keyform", "DER",
+				"-inkey", writeInteropFile(t, dir, "pub.der", der),
+				"-in", writeInteropFile(t, dir, "msg", msg), "-sigfile", writeInteropFile(t, dir, "sig", sig))
+			switch {
+			case err == nil && strings.Contains(string(out), "Verified Successfully"):
+				return true
+			case strings.Contains(string(out), "Verification Failure"):
+				return false
+			}
+			t.Fatalf("openssl pkeyutl -verify: %v\n%s", err, out)
+			return false
+		},
+	}, true
+}
@@ -0,0 +1,397 @@
+package falcongo
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// maxJSONDepth bounds the nesting of documents accepted by CanonicalizeJSON.
+const maxJSONDepth = 1000
+
+// CanonicalizeJSON returns the RFC 8785 (JCS) canonical form of the JSON
+// document doc: no insignificant whitespace, object members sorted by the
+// UTF-16 code units of their names, numbers in the shortest form that
+// ECMAScript prints, and strings with minimal escaping. Documents that
+// JCS does not accept are rejected: duplicate member names, numbers that do
+// not fit a float64, invalid UTF-8 and lone surrogates.
+func CanonicalizeJSON(doc []byte) ([]byte, error) {
+	p := jcsParser{data: doc}
+	p.skipSpace()
+	out, err := p.value(nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos != len(p.data) {
+		return nil, p.errorf("trailing data")
+	}
+	return out, nil
+}
+
+// SignCanonicalJSON signs the canonical form of the JSON document doc, so
+// that any serialization of the same data has the same signature.
+func (d *KeyPair) SignCanonicalJSON(doc []byte) (CompressedSignature, error) {
+	canonical, err := CanonicalizeJSON(doc)
+	if err != nil {
+		return nil, err
+	}
+	return d.Sign(canonical)
+}
+
+// VerifyCanonicalJSON verifies a signature made by SignCanonicalJSON over
+// any serialization of the same data.
+func VerifyCanonicalJSON(doc []byte, sig CompressedSignature, pk PublicKey) error {
+	canonical, err := CanonicalizeJSON(doc)
+	if err != nil {
+		return err
+	}
+	return Verify(canonical, sig, pk)
+}
+
+// jcsParser parses JSON and appends its canonical form as it goes.
+type jcsParser struct {
+	data []byte
+	pos  int
+}
+
+func (p *jcsParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("invalid JSON at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *jcsParser) skipSpace() {
+	for p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *jcsParser) value(out []byte, depth int) ([]byte, error) {
+	if depth > maxJSONDepth {
+		return nil, p.errorf("nested too deeply")
+	}
+	if p.pos >= len(p.data) {
+		return nil, p.errorf("unexpected end of input")
+	}
+	switch c := p.data[p.pos]; {
+	case c == '{':
+		return p.object(out, depth)
+	case c == '[':
+		return p.array(out, depth)
+	case c == '"':
+		s, err := p.string()
+		if err != nil {
+			return nil, err
+		}
+		return appendJCSString(out, s), nil
+	case c == '-' || ('0' <= c && c <= '9'):
+		return p.number(out)
+	default:
+		for _, lit := range []string{"true", "false", "null"} {
+			if bytes.HasPrefix(p.data[p.pos:], []byte(lit)) {
+				p.pos += len(lit)
+				return append(out, lit...), nil
+			}
+		}
+		return nil, p.errorf("unexpected character %q", c)
+	}
+}
+
+func (p *jcsParser) object(out []byte, depth int) ([]byte, error) {
+	type member struct {
+		name  string
+		key   []uint16
+		value []byte
+	}
+	var members []member
+	p.pos++ // {
+	p.skipSpace()
+	if p.pos < len(p.data) && p.data[p.pos] == '}' {
+		p.pos++
+		return append(out, "{}"...), nil
+	}
+	for {
+		if p.pos >= len(p.data) || p.data[p.pos] != '"' {
+			return nil, p.errorf("expected member name")
+		}
+		name, err := p.string()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.data) || p.data[p.pos] != ':' {
+			return nil, p.errorf("expected ':'")
+		}
+		p.pos++
+		p.skipSpace()
+		value, err := p.value(nil, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, member{name, utf16.Encode([]rune(name)), value})
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return nil, p.errorf("unexpected end of input")
+		}
+		if p.data[p.pos] == '}' {
+			p.pos++
+			break
+		}
+		if p.data[p.pos] != ',' {
+			return nil, p.errorf("expected ',' or '}'")
+		}
+		p.pos++
+		p.skipSpace()
+	}
+	slices.SortFunc(members, func(a, b member) int { return slices.Compare(a.key, b.key) })
+	out = append(out, '{')
+	for i, m := range members {
+		if i > 0 {
+			if m.name == members[i-1].name {
+				return nil, fmt.Errorf("invalid JSON: duplicate member name %q", m.name)
+			}
+			out = append(out, ',')
+		}
+		out = appendJCSString(out, m.name)
+		out = append(out, ':')
+		out = append(out, m.value...)
+	}
+	return append(out, '}'), nil
+}
+
+func (p *jcsParser) array(out []byte, depth int) ([]byte, error) {
+	p.pos++ // [
+	p.skipSpace()
+	out = append(out, '[')
+	if p.pos < len(p.data) && p.data[p.pos] == ']' {
+		p.pos++
+		return append(out, ']'), nil
+	}
+	for {
+		var err error
+		if out, err = p.value(out, depth+1); err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return nil, p.errorf("unexpected end of input")
+		}
+		if p.data[p.pos] == ']' {
+			p.pos++
+			return append(out, ']'), nil
+		}
+		if p.data[p.pos] != ',' {
+			return nil, p.errorf("expected ',' or ']'")
+		}
+		p.pos++
+		p.skipSpace()
+		out = append(out, ',')
+	}
+}
+
+// string parses a JSON string and returns its value.
+func (p *jcsParser) string() (string, error) {
+	p.pos++ // "
+	var sb strings.Builder
+	for {
+		if p.pos >= len(p.data) {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.data[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return sb.String(), nil
+		case c < 0x20:
+			return "", p.errorf("control character in string")
+		case c == '\\':
+			r, err := p.escape()
+			if err != nil {
+				return "", err
+			}
+			sb.WriteRune(r)
+		default:
+			r, size := utf8.DecodeRune(p.data[p.pos:])
+			if r == utf8.RuneError && size <= 1 {
+				return "", p.errorf("invalid UTF-8")
+			}
+			sb.Write(p.data[p.pos : p.pos+size])
+			p.pos += size
+		}
+	}
+}
+
+// escape parses the escape sequence at p.pos, combining surrogate pairs.
+func (p *jcsParser) escape() (rune, error) {
+	if p.pos+1 >= len(p.data) {
+		return 0, p.errorf("unterminated escape")
+	}
+	c := p.data[p.pos+1]
+	p.pos += 2
+	switch c {
+	case '"', '\\', '/':
+		return rune(c), nil
+	case 'b':
+		return '\b', nil
+	case 'f':
+		return '\f', nil
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case 'u':
+		r, err := p.hex4()
+		if err != nil {
+			return 0, err
+		}
+		if !utf16.IsSurrogate(r) {
+			return r, nil
+		}
+		if r < 0xdc00 && bytes.HasPrefix(p.data[p.pos:], []byte(`\u`)) {
+			p.pos += 2
+			low, err := p.hex4()
+			if err != nil {
+				return 0, err
+			}
+			if pair := utf16.DecodeRune(r, low); pair != utf8.RuneError {
+				return pair, nil
+			}
+		}
+		return 0, p.errorf("lone surrogate")
+	default:
+		return 0, p.errorf("invalid escape %q", c)
+	}
+}
+
+func (p *jcsParser) hex4() (rune, error) {
+	if p.pos+4 > len(p.data) {
+		return 0, p.errorf("truncated \\u escape")
+	}
+	v, err := strconv.ParseUint(string(p.data[p.pos:p.pos+4]), 16, 16)
+	if err != nil {
+		return 0, p.errorf("invalid \\u escape")
+	}
+	p.pos += 4
+	return rune(v), nil
+}
+
+// number parses a JSON number and appends its ECMAScript form.
+func (p *jcsParser) number(out []byte) ([]byte, error) {
+	start := p.pos
+	digits := func() int {
+		n := 0
+		for p.pos < len(p.data) && '0' <= p.data[p.pos] && p.data[p.pos] <= '9' {
+			p.pos++
+			n++
+		}
+		return n
+	}
+	if p.data[p.pos] == '-' {
+		p.pos++
+	}
+	intStart := p.pos
+	if n := digits(); n == 0 || (n > 1 && p.data[intStart] == '0') {
+		return nil, p.errorf("invalid number")
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == '.' {
+		p.pos++
+		if digits() == 0 {
+			return nil, p.errorf("invalid number")
+		}
+	}
+	if p.pos < len(p.data) && (p.data[p.pos] == 'e' || p.data[p.pos] == 'E') {
+		p.pos++
+		if p.pos < len(p.data) && (p.data[p.pos] == '+' || p.data[p.pos] == '-') {
+			p.pos++
+		}
+		if digits() == 0 {
+			return nil, p.errorf("invalid number")
+		}
+	}
+	f, err := strconv.ParseFloat(string(p.data[start:p.pos]), 64)
+	if err != nil {
+		return nil, p.errorf("number %s does not fit a float64", p.data[start:p.pos])
+	}
+	return appendJCSNumber(out, f), nil
+}
+
+// appendJCSNumber appends f as ECMAScript's Number.prototype.toString
+// prints it.
+func appendJCSNumber(out []byte, f float64) []byte {
+	if f == 0 {
+		return append(out, '0')
+	}
+	if math.Signbit(f) {
+		out = append(out, '-')
+		f = -f
+	}
+	// Shortest digits that round-trip, as d.ddde±x.
+	mantissa, exp, _ := strings.Cut(strconv.FormatFloat(f, 'e', -1, 64), "e")
+	digits := strings.Replace(mantissa, ".", "", 1)
+	e, _ := strconv.Atoi(exp)
+	k, n := len(digits), e+1 // digits·10^(n-k)
+	switch {
+	case k <= n && n <= 21:
+		out = append(out, digits...)
+		return append(out, strings.Repeat("0", n-k)...)
+	case 0 < n && n <= 21:
+		out = append(out, digits[:n]...)
+		out = append(out, '.')
+		return append(out, digits[n:]...)
+	case -6 < n && n <= 0:
+		out = append(out, "0."...)
+		out = append(out, strings.Repeat("0", -n)...)
+		return append(out, digits...)
+	}
+	out = append(out, digits[0])
+	if k > 1 {
+		out = append(out, '.')
+		out = append(out, digits[1:]...)
+	}
+	out = append(out, 'e')
+	if n-1 >= 0 {
+		out = append(out, '+')
+	}
+	return strconv.AppendInt(out, int64(n-1), 10)
+}
+
+// appendJCSString appends s as a JSON string, escaping only what JSON
+// requires.
+func appendJCSString(out []byte, s string) []byte {
+	const hexDigits = "0123456789abcdef"
+	out = append(out, '"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\\':
+			out = append(out, '\\', c)
+		case '\b':
+			out = append(out, `\b`...)
+		case '\f':
+			out = append(out, `\f`...)
+		case '\n':
+			out = append(out, `\n`...)
+		case '\r':
+			out = append(out, `\r`...)
+		case '\t':
+			out = append(out, `\t`...)
+		default:
+			if c < 0x20 {
+				out = append(out, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			} else {
+				out = append(out, c)
+			}
+		}
+	}
+	return append(out, '"')
+}
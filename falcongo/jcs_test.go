@@ -0,0 +1,102 @@
+package falcongo
+
+import (
+	"math"
+	"testing"
+)
+
+// TestCanonicalizeJSON checks the examples of RFC 8785.
+func TestCanonicalizeJSON(t *testing.T) {
+	for _, tc := range []struct{ name, in, want string }{
+		{
+			"rfc 8785 section 3.2.2",
+			`{
+  "numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+  "string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+  "literals": [null, true, false]
+}`,
+			`{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`,
+		},
+		{
+			"rfc 8785 section 3.2.3",
+			`{
+  "\u20ac": "Euro Sign",
+  "\r": "Carriage Return",
+  "\ufb33": "Hebrew Letter Dalet With Dagesh",
+  "1": "One",
+  "\ud83d\ude00": "Emoji: Grinning Face",
+  "\u0080": "Control",
+  "\u00f6": "Latin Small Letter O With Diaeresis"
+}`,
+			"{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"ö\":\"Latin Small Letter O With Diaeresis\"," +
+				"\"€\":\"Euro Sign\",\"😀\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}",
+		},
+		{"nested", ` { "b" : [ {}, [ ] , { "z":1,"a":-0 } ], "a":"\u2028" } `, "{\"a\":\"\u2028\",\"b\":[{},[],{\"a\":0,\"z\":1}]}"},
+		{"scalar", ` "x" `, `"x"`},
+	} {
+		got, err := CanonicalizeJSON([]byte(tc.in))
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if string(got) != tc.want {
+			t.Fatalf("%s:\n got %s\nwant %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCanonicalizeJSON_Rejects(t *testing.T) {
+	for _, in := range []string{
+		`{"a":1,"a":2}`,
+		`1e400`,
+		`"\ud800"`,
+		`"\udc00\ud800"`,
+		"\"\xff\"",
+		"\"\x01\"",
+		`01`,
+		`1.`,
+		`[1,]`,
+		`{"a" 1}`,
+		`{} {}`,
+		`nul`,
+		``,
+	} {
+		if got, err := CanonicalizeJSON([]byte(in)); err == nil {
+			t.Fatalf("CanonicalizeJSON(%q) = %s, want an error", in, got)
+		}
+	}
+}
+
+// TestAppendJCSNumber checks the number serialization samples of RFC 8785
+// appendix B.
+func TestAppendJCSNumber(t *testing.T) {
+	for bits, want := range map[uint64]string{
+		0x0000000000000000: "0",
+		0x8000000000000000: "0",
+		0x0000000000000001: "5e-324",
+		0x8000000000000001: "-5e-324",
+		0x7fefffffffffffff: "1.7976931348623157e+308",
+		0xffefffffffffffff: "-1.7976931348623157e+308",
+		0x4340000000000000: "9007199254740992",
+		0xc340000000000000: "-9007199254740992",
+		0x4430000000000000: "295147905179352830000",
+		0x44b52d02c7e14af5: "9.999999999999997e+22",
+		0x44b52d02c7e14af6: "1e+23",
+		0x44b52d02c7e14af7: "1.0000000000000001e+23",
+		0x444b1ae4d6e2ef4e: "999999999999999700000",
+		0x444b1ae4d6e2ef4f: "999999999999999900000",
+		0x444b1ae4d6e2ef50: "1e+21",
+		0x3eb0c6f7a0b5ed8c: "9.999999999999997e-7",
+		0x3eb0c6f7a0b5ed8d: "0.000001",
+		0x41b3de4355555553: "333333333.3333332",
+		0x41b3de4355555554: "333333333.33333325",
+		0x41b3de4355555555: "333333333.3333333",
+		0x41b3de4355555556: "333333333.3333334",
+		0x41b3de4355555557: "333333333.33333343",
+		0xbecbf647612f3696: "-0.0000033333333333333333",
+		0x43143ff3c1cb0959: "1424953923781206.2",
+	} {
+		if got := string(appendJCSNumber(nil, math.Float64frombits(bits))); got != want {
+			t.Errorf("%016x: got %s, want %s", bits, got, want)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package falcongo
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/hkdf"
+
+	"github.com/algorandfoundation/falcon-signatures/hashing"
+)
+
+// KeyPair groups a Falcon-1024 public/private key.
+type KeyPair struct {
+	PublicKey  PublicKey
+	PrivateKey PrivateKey
+}
+
+// SecretsEqual reports whether a and b hold the same bytes, taking time that
+// depends only on their lengths. Use it when comparing secret material such as
+// private keys or passphrases.
+func SecretsEqual(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
+
+// Fingerprint returns the SHA-256 digest of the public key, a short identifier
+// for a key that can be shown to users or used to refer to signers. It stays
+// SHA-256 whatever hashing.Default is, as fingerprints are recorded elsewhere.
+func Fingerprint(pk PublicKey) [32]byte {
+	return [32]byte(hashing.SHA256.Sum(pk[:]))
+}
+
+// FingerprintFromHex returns the Fingerprint of a public key in hex, as key
+// files hold it (surrounding space and a 0x prefix are accepted), without
+// decoding the key first. Tools that only display identities use it.
+func FingerprintFromHex(s string) ([32]byte, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "0x")
+	fp, err := FingerprintReader(hex.NewDecoder(strings.NewReader(s)))
+	if err != nil {
+		return [32]byte{}, fmt.Errorf("invalid public key hex: %w", err)
+	}
+	return fp, nil
+}
+
+// FingerprintReader returns the Fingerprint of the raw public key read from
+// r, hashing it as it is read. r must hold exactly PublicKeySize bytes.
+func FingerprintReader(r io.Reader) ([32]byte, error) {
+	h := hashing.SHA256.New()
+	var buf [256]byte
+	n, err := io.CopyBuffer(h, io.LimitReader(r, PublicKeySize+1), buf[:])
+	if err != nil {
+		return [32]byte{}, err
+	}
+	if n != PublicKeySize {
+		if n > PublicKeySize {
+			return [32]byte{}, fmt.Errorf("public key longer than %d bytes", PublicKeySize)
+		}
+		return [32]byte{}, fmt.Errorf("public key is %d bytes, want %d", n, PublicKeySize)
+	}
+	return [32]byte(h.Sum(nil)), nil
+}
+
+// ErrInvalidSeedSize reports a keygen seed that is not SeedSize bytes long,
+// such as a truncated or empty one.
+var ErrInvalidSeedSize = errors.New("invalid falcon seed size")
+
+// checkSeed rejects seeds GenerateKeyPair must not use: nil asks for a random
+// seed, any other seed must be SeedSize bytes, so that a truncated seed is an
+// error rather than a key nobody can recreate from the full one.
+func checkSeed(seed []byte) error {
+	if seed != nil && len(seed) != SeedSize {
+		return fmt.Errorf("%w: got %d bytes, want %d (derive other lengths with SeedFromBytes)",
+			ErrInvalidSeedSize, len(seed), SeedSize)
+	}
+	return nil
+}
+
+// seedSalt domain-separates SeedFromBytes from other uses of HKDF.
+const seedSalt = "falcon-seed-v1"
+
+// SeedFromBytes derives a keygen seed from seed material of any non-zero
+// length, for callers that do not hold exactly SeedSize bytes. SeedSize-byte
+// material is returned unchanged, so it gives the same keys as passing it to
+// GenerateKeyPair directly; other lengths are expanded or compressed with
+// HKDF-SHA512. Material should hold at least SeedSize bytes of entropy;
+// derive seeds from passphrases with a password hash instead.
+func SeedFromBytes(b []byte) ([SeedSize]byte, error) {
+	var seed [SeedSize]byte
+	if len(b) == 0 {
+		return seed, fmt.Errorf("%w: no seed material", ErrInvalidSeedSize)
+	}
+	if len(b) == SeedSize {
+		copy(seed[:], b)
+		return seed, nil
+	}
+	r := hkdf.New(sha512.New, b, []byte(seedSalt), nil)
+	if _, err := io.ReadFull(r, seed[:]); err != nil {
+		return [SeedSize]byte{}, err
+	}
+	return seed, nil
+}
+
+// subkeySalt domain-separates subkey derivation from other uses of the master
+// private key.
+const subkeySalt = "falcon-subkey-v1"
+
+// DeriveSubkey derives an independent keypair for a purpose such as "payments"
+// or "ci" from a master keypair. The keygen seed is HKDF-SHA512 over the master
+// private key with the label as info, so the same master and label always give
+// the same subkey, and subkeys reveal nothing about the master or each other.
+func DeriveSubkey(master KeyPair, label string) (KeyPair, error) {
+	if label == "" {
+		return KeyPair{}, errors.New("subkey label must not be empty")
+	}
+	seed := make([]byte, SeedSize)
+	r := hkdf.New(sha512.New, master.PrivateKey[:], []byte(subkeySalt), []byte(label))
+	if _, err := io.ReadFull(r, seed); err != nil {
+		return KeyPair{}, err
+	}
+	kp, err := GenerateKeyPair(seed)
+	// Best-effort wipe of intermediate seed.
+	for i := range seed {
+		seed[i] = 0
+	}
+	return kp, err
+}
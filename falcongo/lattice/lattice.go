@@ -0,0 +1,106 @@
+// Package lattice exposes the polynomial structure of FALCON-1024 public keys
+// for research tooling, such as checks that generated keys are distributed as
+// expected.
+//
+// A FALCON-1024 public key is the polynomial h = g/f mod (x^1024 + 1, q) with
+// q = 12289, encoded as a header byte followed by its 1024 coefficients in 14
+// bits each. Nothing here is needed to sign or verify; it is kept apart from
+// falcongo so that applications do not depend on the internal representation
+// of keys. Like falcongo/verifyonly, the package is pure Go.
+package lattice
+
+import (
+	"errors"
+	"math"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo/internal/det1024"
+)
+
+// Parameters of FALCON-1024.
+const (
+	// Q is the modulus of the coefficients.
+	Q = det1024.Q
+	// LogN is log2 of the degree of the polynomials.
+	LogN = det1024.LogN
+	// N is the number of coefficients of a public key.
+	N = det1024.N
+	// PublicKeyHeader is the first byte of an encoded public key: the
+	// public key format, 0, in the high nibble and LogN in the low one.
+	PublicKeyHeader = 0x0A
+	// ChiSquareBins is the number of bins of Summary.ChiSquare.
+	ChiSquareBins = 16
+)
+
+// ErrPublicKey is returned for bytes that do not encode a FALCON-1024 public
+// key.
+var ErrPublicKey = errors.New("invalid falcon-1024 public key")
+
+// ParsePublicKeyCoefficients decodes an encoded public key, as produced by
+// falcongo, into the N coefficients of h, each in [0, Q).
+func ParsePublicKeyCoefficients(pk []byte) ([]uint16, error) {
+	if len(pk) != det1024.PublicKeySize || pk[0] != PublicKeyHeader {
+		return nil, ErrPublicKey
+	}
+	h, ok := det1024.DecodeModQ(pk[1:])
+	if !ok {
+		return nil, ErrPublicKey
+	}
+	return h[:], nil
+}
+
+// Summary describes the distribution of the coefficients of a public key.
+// For keys generated as specified, the coefficients are close to uniform on
+// [0, Q): mean about (Q-1)/2, variance about (Q²-1)/12, and ChiSquare
+// following a chi-squared distribution with ChiSquareBins-1 degrees of
+// freedom.
+type Summary struct {
+	Min      uint16  `json:"min"`
+	Max      uint16  `json:"max"`
+	Mean     float64 `json:"mean"`
+	Variance float64 `json:"variance"`
+	// ChiSquare is Pearson's statistic of the counts of coefficients in
+	// ChiSquareBins bins of [0, Q) of (nearly) equal width, against the
+	// uniform distribution.
+	ChiSquare float64 `json:"chi_square"`
+}
+
+// Summarize returns the summary of coefficients in [0, Q).
+func Summarize(coefficients []uint16) Summary {
+	if len(coefficients) == 0 {
+		return Summary{}
+	}
+	s := Summary{Min: math.MaxUint16}
+	var sum float64
+	var bins [ChiSquareBins]int
+	for _, c := range coefficients {
+		s.Min = min(s.Min, c)
+		s.Max = max(s.Max, c)
+		sum += float64(c)
+		bins[binOf(c)]++
+	}
+	n := float64(len(coefficients))
+	s.Mean = sum / n
+	for _, c := range coefficients {
+		d := float64(c) - s.Mean
+		s.Variance += d * d
+	}
+	s.Variance /= n
+	for i, count := range bins {
+		// Bin i holds the values v with binOf(v) == i.
+		width := float64(binStart(i+1) - binStart(i))
+		expected := n * width / Q
+		d := float64(count) - expected
+		s.ChiSquare += d * d / expected
+	}
+	return s
+}
+
+// binOf returns the bin of Summary.ChiSquare holding c.
+func binOf(c uint16) int {
+	return int(c) * ChiSquareBins / Q
+}
+
+// binStart returns the least value of bin i, or Q for i = ChiSquareBins.
+func binStart(i int) int {
+	return (i*Q + ChiSquareBins - 1) / ChiSquareBins
+}
@@ -0,0 +1,91 @@
+package lattice
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"testing"
+)
+
+// katPublicKeys returns the public keys of the verification KAT shared with
+// falcongo.
+func katPublicKeys(t *testing.T) [][]byte {
+	t.Helper()
+	data, err := os.ReadFile("../testdata/verify_kat.json")
+	if err != nil {
+		t.Fatalf("failed to read verify KAT: %v", err)
+	}
+	var kats []struct {
+		PublicKey string `json:"public_key"`
+	}
+	if err := json.Unmarshal(data, &kats); err != nil {
+		t.Fatalf("failed to parse verify KAT: %v", err)
+	}
+	var keys [][]byte
+	for _, kat := range kats {
+		pk, err := hex.DecodeString(kat.PublicKey)
+		if err != nil {
+			t.Fatalf("malformed KAT public key: %v", err)
+		}
+		keys = append(keys, pk)
+	}
+	return keys
+}
+
+// TestParsePublicKeyCoefficients decodes the KAT keys and rejects malformed
+// encodings.
+func TestParsePublicKeyCoefficients(t *testing.T) {
+	for i, pk := range katPublicKeys(t) {
+		h, err := ParsePublicKeyCoefficients(pk)
+		if err != nil || len(h) != N {
+			t.Fatalf("key %d: got %d coefficients, %v", i, len(h), err)
+		}
+		// The first coefficient is the first 14 bits after the header.
+		if want := uint16(pk[1])<<6 | uint16(pk[2])>>2; h[0] != want {
+			t.Fatalf("key %d: h[0] = %d, want %d", i, h[0], want)
+		}
+		s := Summarize(h)
+		if s.Max >= Q || math.Abs(s.Mean-(Q-1)/2.0) > 600 ||
+			math.Abs(s.Variance/((Q*Q-1)/12.0)-1) > 0.2 || s.ChiSquare > 60 {
+			t.Fatalf("key %d: coefficients do not look uniform: %+v", i, s)
+		}
+
+		bad := append([]byte{}, pk...)
+		bad[0] = 0x09
+		if _, err := ParsePublicKeyCoefficients(bad); !errors.Is(err, ErrPublicKey) {
+			t.Fatalf("key %d: wrong header: got %v", i, err)
+		}
+		copy(bad, pk)
+		bad[1], bad[2] = 0xFF, bad[2]|0xFC // h[0] = 2^14 - 1 >= Q
+		if _, err := ParsePublicKeyCoefficients(bad); !errors.Is(err, ErrPublicKey) {
+			t.Fatalf("key %d: coefficient >= Q: got %v", i, err)
+		}
+		if _, err := ParsePublicKeyCoefficients(pk[:len(pk)-1]); !errors.Is(err, ErrPublicKey) {
+			t.Fatalf("key %d: truncated key: got %v", i, err)
+		}
+	}
+}
+
+// TestSummarize checks the summary of fixed coefficients.
+func TestSummarize(t *testing.T) {
+	s := Summarize([]uint16{0, Q - 1})
+	if s.Min != 0 || s.Max != Q-1 || s.Mean != (Q-1)/2.0 {
+		t.Fatalf("unexpected summary %+v", s)
+	}
+	// One coefficient per value is exactly uniform.
+	all := make([]uint16, Q)
+	for i := range all {
+		all[i] = uint16(i)
+	}
+	if s := Summarize(all); s.ChiSquare > 1e-9 {
+		t.Fatalf("uniform coefficients: chi-square %v", s.ChiSquare)
+	}
+	if s := Summarize(make([]uint16, N)); s.ChiSquare < 1000 {
+		t.Fatalf("zero coefficients: chi-square %v", s.ChiSquare)
+	}
+	if (Summarize(nil) != Summary{}) {
+		t.Fatalf("empty input: unexpected summary")
+	}
+}
@@ -0,0 +1,72 @@
+package falcongo
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo/internal/det1024"
+)
+
+// ErrMalformedSignature matches the errors of signatures that are not
+// structurally valid, whatever the key and message. Verify reports them
+// before any cryptographic check, and, with cgo, before the signature is
+// handed to the C implementation.
+var ErrMalformedSignature = errors.New("malformed signature")
+
+// SignatureParseError tells where and why a signature is malformed. It
+// matches ErrMalformedSignature, and also the verification failure of the
+// pure-Go verifier (verifyonly.ErrVerify), which such signatures got before.
+type SignatureParseError struct {
+	Offset int // byte of the signature at fault
+	Reason string
+}
+
+func (e *SignatureParseError) Error() string {
+	return fmt.Sprintf("%v: %s (byte %d)", ErrMalformedSignature, e.Reason, e.Offset)
+}
+
+func (e *SignatureParseError) Unwrap() []error {
+	return []error{ErrMalformedSignature, errVerify}
+}
+
+// CheckCompressedSignature checks the structure of a deterministic compressed
+// FALCON-1024 signature: its header, its length, and that s2 decodes as
+// compressed coefficients with no trailing bytes or padding bits. It returns
+// a *SignatureParseError, or nil if only Verify can tell whether the
+// signature is valid.
+func CheckCompressedSignature(sig []byte) error {
+	switch {
+	case len(sig) == 0:
+		return &SignatureParseError{Offset: 0, Reason: "empty signature"}
+	case sig[0] != detSigCompressedHeader:
+		return &SignatureParseError{Offset: 0, Reason: fmt.Sprintf("header 0x%02x, want 0x%02x (deterministic compressed FALCON-1024)",
+			sig[0], detSigCompressedHeader)}
+	case len(sig) < minCompressedSignatureSize:
+		return &SignatureParseError{Offset: len(sig), Reason: "truncated: no salt version byte"}
+	case len(sig) > MaxCompressedSignatureSize:
+		return &SignatureParseError{Offset: MaxCompressedSignatureSize,
+			Reason: fmt.Sprintf("%d bytes, longer than the %d-byte maximum", len(sig), MaxCompressedSignatureSize)}
+	}
+	if _, err := det1024.ParseCompressed(sig[minCompressedSignatureSize:]); err != nil {
+		var de *det1024.DecodeError
+		if !errors.As(err, &de) {
+			return err
+		}
+		return &SignatureParseError{Offset: minCompressedSignatureSize + de.Offset, Reason: "s2: " + de.Reason}
+	}
+	return nil
+}
+
+// checkCTSignature checks the header and length of a deterministic CT
+// signature.
+func checkCTSignature(sig []byte) error {
+	switch {
+	case len(sig) != CTSignatureSize:
+		return &SignatureParseError{Offset: min(len(sig), CTSignatureSize),
+			Reason: fmt.Sprintf("CT signature of %d bytes, want %d", len(sig), CTSignatureSize)}
+	case sig[0] != detSigCTHeader:
+		return &SignatureParseError{Offset: 0, Reason: fmt.Sprintf("header 0x%02x, want 0x%02x (deterministic CT FALCON-1024)",
+			sig[0], detSigCTHeader)}
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package falcongo
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestCheckCompressedSignature checks the KAT signatures pass and that
+// malformed ones are located.
+func TestCheckCompressedSignature(t *testing.T) {
+	kat := readVerifyKATs(t)[0]
+	sig, err := hex.DecodeString(kat.Signature)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sigCT, err := hex.DecodeString(kat.SignatureCT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckCompressedSignature(sig); err != nil {
+		t.Fatalf("KAT signature: %v", err)
+	}
+
+	with := func(f func(b []byte) []byte) []byte { return f(append([]byte(nil), sig...)) }
+	for _, tc := range []struct {
+		name   string
+		sig    []byte
+		offset int
+		reason string
+	}{
+		{"empty", nil, 0, "empty signature"},
+		{"CT", sigCT, 0, "header 0xda"},
+		{"salted header", with(func(b []byte) []byte { b[0] = 0x3a; return b }), 0, "header 0x3a"},
+		{"header only", sig[:1], 1, "no salt version byte"},
+		{"no s2", sig[:2], 2, "truncated after 0 of 1024 coefficients"},
+		{"truncated", sig[:len(sig)-1], len(sig) - 1, "truncated"},
+		{"trailing byte", with(func(b []byte) []byte { return append(b, 0) }), len(sig), "1 trailing bytes"},
+		{"oversized", make([]byte, MaxCompressedSignatureSize+1), 0, "header 0x00"},
+		{"oversized with header", append([]byte{detSigCompressedHeader}, make([]byte, MaxCompressedSignatureSize)...),
+			MaxCompressedSignatureSize, "longer than the 1423-byte maximum"},
+		// 0x80 0x80: sign bit set, low bits 0 and high part 0, i.e. -0.
+		{"negative zero", []byte{detSigCompressedHeader, 0, 0x80, 0x80}, 3, "coefficient 0 is negative zero"},
+		{"out of range", append([]byte{detSigCompressedHeader, 0, 0}, make([]byte, 3)...), 4, "coefficient 0 out of range"},
+	} {
+		err := CheckCompressedSignature(tc.sig)
+		var pe *SignatureParseError
+		if !errors.As(err, &pe) || pe.Offset != tc.offset || !strings.Contains(pe.Reason, tc.reason) {
+			t.Errorf("%s: got %v, want %q at byte %d", tc.name, err, tc.reason, tc.offset)
+			continue
+		}
+		if !errors.Is(err, ErrMalformedSignature) || !errors.Is(err, errVerify) {
+			t.Errorf("%s: %v does not match ErrMalformedSignature and the verification failure", tc.name, err)
+		}
+	}
+}
+
+// TestVerify_Malformed checks that Verify and VerifyStrict report malformed
+// signatures as such.
+func TestVerify_Malformed(t *testing.T) {
+	kat := readVerifyKATs(t)[0]
+	pkBytes, _ := hex.DecodeString(kat.PublicKey)
+	msg, _ := hex.DecodeString(kat.Message)
+	sig, _ := hex.DecodeString(kat.Signature)
+	sigCT, _ := hex.DecodeString(kat.SignatureCT)
+	var pk PublicKey
+	copy(pk[:], pkBytes)
+
+	if err := Verify(msg, append(sig, 0), pk); !errors.Is(err, ErrMalformedSignature) {
+		t.Errorf("Verify with a trailing byte: %v", err)
+	}
+	badCT := append([]byte(nil), sigCT...)
+	badCT[0] = detSigCompressedHeader
+	if err := verifyFixedLength(msg, badCT, pk); !errors.Is(err, ErrMalformedSignature) {
+		t.Errorf("CT verify with a compressed header: %v", err)
+	}
+	if err := verifyFixedLength(msg, sigCT[:100], pk); !errors.Is(err, ErrMalformedSignature) {
+		t.Errorf("CT verify of a truncated signature: %v", err)
+	}
+}
+
+// FuzzCheckCompressedSignature checks that CheckCompressedSignature never
+// panics, accepts only what the pure-Go decoder accepts, and that Verify
+// agrees with the pure-Go verifier (the C one with cgo) on whatever passes
+// it. The seed corpus is the KAT signatures and testdata/fuzz.
+func FuzzCheckCompressedSignature(f *testing.F) {
+	kats := readVerifyKATs(f)
+	for _, kat := range kats {
+		sig, err := hex.DecodeString(kat.Signature)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(sig)
+	}
+	pkBytes, _ := hex.DecodeString(kats[0].PublicKey)
+	msg, _ := hex.DecodeString(kats[0].Message)
+	var pk PublicKey
+	copy(pk[:], pkBytes)
+
+	f.Fuzz(func(t *testing.T, sig []byte) {
+		err := CheckCompressedSignature(sig)
+		if err != nil {
+			var pe *SignatureParseError
+			if !errors.As(err, &pe) || pe.Offset < 0 || pe.Offset > len(sig) {
+				t.Fatalf("unexpected error %#v for %d bytes", err, len(sig))
+			}
+			if verifyCompressed(pk[:], sig, msg) == nil {
+				t.Fatalf("rejected %x, which verifies", sig)
+			}
+			if err := Verify(msg, sig, pk); !errors.Is(err, ErrMalformedSignature) {
+				t.Fatalf("Verify of a malformed signature: %v", err)
+			}
+			return
+		}
+		if !IsValidSignatureLength(len(sig)) {
+			t.Fatalf("accepted %d bytes", len(sig))
+		}
+		want := verifyCompressed(pk[:], sig, msg) == nil
+		if got := Verify(msg, sig, pk) == nil; got != want {
+			t.Fatalf("Verify=%v, pure-Go verify=%v for %x", got, want, sig)
+		}
+	})
+}
@@ -0,0 +1,185 @@
+package falcongo
+
+import "errors"
+
+// This file recomputes a public key from a private key in pure Go, like
+// falcon_make_public of the C implementation: the private key encodes the
+// polynomials f and g, and the public key is h = g/f mod (x^n + 1, q).
+
+const (
+	// Header of an encoded FALCON-1024 private key.
+	privateKeyHeader = 0x50 | falconLogN
+	// fgBits is the width of each coefficient of f and g in a FALCON-1024
+	// private key.
+	fgBits = 5
+)
+
+// ErrInvalidPrivateKey is returned by PublicKeyFromPrivate for bytes that do
+// not encode a FALCON-1024 private key.
+var ErrInvalidPrivateKey = errors.New("invalid falcon private key")
+
+// PublicKeyFromPrivate recomputes the public key matching sk. It lets callers
+// holding only a private key (e.g. a key file without public_key) obtain the
+// public key, or check that a stored public key matches.
+func PublicKeyFromPrivate(sk PrivateKey) (PublicKey, error) {
+	if sk[0] != privateKeyHeader {
+		return PublicKey{}, ErrInvalidPrivateKey
+	}
+	coeffBytes := falconN * fgBits / 8
+	f, ok1 := decodeTrimmedI8(sk[1:1+coeffBytes], fgBits)
+	g, ok2 := decodeTrimmedI8(sk[1+coeffBytes:1+2*coeffBytes], fgBits)
+	if !ok1 || !ok2 {
+		return PublicKey{}, ErrInvalidPrivateKey
+	}
+
+	// h = g/f, computed pointwise in the negacyclic NTT domain.
+	var fv, gv [falconN]uint32
+	for i := range falconN {
+		fv[i] = uint32(int32(f[i]) + falconQ)
+		gv[i] = uint32(int32(g[i]) + falconQ)
+	}
+	psi := rootOfUnity2N()
+	nttNegacyclic(&fv, psi)
+	nttNegacyclic(&gv, psi)
+	var h [falconN]uint32
+	for i := range falconN {
+		if fv[i] == 0 {
+			return PublicKey{}, ErrInvalidPrivateKey
+		}
+		h[i] = mulModQ(gv[i], invModQ(fv[i]))
+	}
+	inttNegacyclic(&h, psi)
+
+	var pk PublicKey
+	pk[0] = falconLogN
+	encodeModQ(pk[1:], &h)
+	return pk, nil
+}
+
+// decodeTrimmedI8 decodes n big-endian two's complement coefficients of bits
+// bits each, the most negative value being forbidden. Unused bits at the end
+// must be zero.
+func decodeTrimmedI8(in []byte, bits uint) (x [falconN]int8, ok bool) {
+	mask := uint32(1)<<bits - 1
+	sign := uint32(1) << (bits - 1)
+	var acc uint32
+	var accLen uint
+	u := 0
+	for _, b := range in {
+		acc = acc<<8 | uint32(b)
+		accLen += 8
+		for accLen >= bits && u < falconN {
+			accLen -= bits
+			w := (acc >> accLen) & mask
+			if w == sign {
+				return x, false
+			}
+			v := int32(w)
+			if w&sign != 0 {
+				v -= int32(1) << bits
+			}
+			x[u] = int8(v)
+			u++
+		}
+	}
+	return x, u == falconN && acc&(1<<accLen-1) == 0
+}
+
+// encodeModQ is the inverse of det1024.DecodeModQ.
+func encodeModQ(out []byte, x *[falconN]uint32) {
+	var acc uint32
+	accLen := 0
+	v := 0
+	for _, c := range x {
+		acc = acc<<14 | c
+		accLen += 14
+		for accLen >= 8 {
+			accLen -= 8
+			out[v] = byte(acc >> accLen)
+			v++
+		}
+	}
+	if accLen > 0 {
+		out[v] = byte(acc << (8 - accLen))
+	}
+}
+
+// nttNegacyclic replaces a, with coefficients below q, by its evaluations
+// at the odd powers of psi, a primitive 2n-th root of unity: the roots of
+// x^n + 1.
+func nttNegacyclic(a *[falconN]uint32, psi uint32) {
+	p := uint32(1)
+	for j := range a {
+		a[j] = mulModQ(a[j], p)
+		p = mulModQ(p, psi)
+	}
+	nttCyclic(a, mulModQ(psi, psi))
+}
+
+// inttNegacyclic is the inverse of nttNegacyclic.
+func inttNegacyclic(a *[falconN]uint32, psi uint32) {
+	psiInv := invModQ(psi)
+	nttCyclic(a, mulModQ(psiInv, psiInv))
+	p := invModQ(falconN)
+	for j := range a {
+		a[j] = mulModQ(a[j], p)
+		p = mulModQ(p, psiInv)
+	}
+}
+
+// nttCyclic replaces a by A_k = sum_j a_j omega^(jk), for omega a primitive
+// n-th root of unity (iterative Cooley-Tukey).
+func nttCyclic(a *[falconN]uint32, omega uint32) {
+	for i, j := 1, 0; i < falconN; i++ {
+		bit := falconN >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			a[i], a[j] = a[j], a[i]
+		}
+	}
+	for length := 2; length <= falconN; length <<= 1 {
+		wLen := powModQ(omega, uint32(falconN/length))
+		half := length / 2
+		for i := 0; i < falconN; i += length {
+			w := uint32(1)
+			for k := range half {
+				u, v := a[i+k], mulModQ(a[i+k+half], w)
+				a[i+k] = (u + v) % falconQ
+				a[i+k+half] = (u + falconQ - v) % falconQ
+				w = mulModQ(w, wLen)
+			}
+		}
+	}
+}
+
+// rootOfUnity2N returns a primitive 2n-th root of unity modulo q.
+func rootOfUnity2N() uint32 {
+	for c := uint32(2); ; c++ {
+		w := powModQ(c, (falconQ-1)/(2*falconN))
+		if powModQ(w, falconN) == falconQ-1 {
+			return w
+		}
+	}
+}
+
+func mulModQ(a, b uint32) uint32 {
+	return a * b % falconQ
+}
+
+func invModQ(a uint32) uint32 {
+	return powModQ(a, falconQ-2)
+}
+
+func powModQ(a, e uint32) uint32 {
+	r := uint32(1)
+	for ; e > 0; e >>= 1 {
+		if e&1 != 0 {
+			r = mulModQ(r, a)
+		}
+		a = mulModQ(a, a)
+	}
+	return r
+}
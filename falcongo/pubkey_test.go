@@ -0,0 +1,61 @@
+//go:build cgo && !purego
+
+package falcongo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// TestPublicKeyFromPrivate checks the recomputed public key matches the one
+// generated by the C implementation.
+func TestPublicKeyFromPrivate(t *testing.T) {
+	for k := range 3 {
+		kp, err := GenerateKeyPair(testSeed([]byte(fmt.Sprintf("public from private %d", k))))
+		if err != nil {
+			t.Fatalf("GenerateKeyPair failed: %v", err)
+		}
+		pk, err := PublicKeyFromPrivate(kp.PrivateKey)
+		if err != nil {
+			t.Fatalf("PublicKeyFromPrivate failed: %v", err)
+		}
+		if pk != kp.PublicKey {
+			t.Fatalf("key %d: recomputed public key differs", k)
+		}
+	}
+}
+
+// TestPublicKeyFromPrivate_Invalid rejects malformed private keys.
+func TestPublicKeyFromPrivate_Invalid(t *testing.T) {
+	kp, err := GenerateKeyPair(testSeed([]byte("public from private invalid")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	header := kp.PrivateKey
+	header[0] = 0x59
+	forbidden := kp.PrivateKey
+	forbidden[1] = 0x80 // first coefficient of f is -16
+	for name, sk := range map[string]PrivateKey{
+		"zero":      {},
+		"header":    header,
+		"forbidden": forbidden,
+	} {
+		if _, err := PublicKeyFromPrivate(sk); !errors.Is(err, ErrInvalidPrivateKey) {
+			t.Errorf("%s: expected ErrInvalidPrivateKey, got %v", name, err)
+		}
+	}
+}
+
+// BenchmarkPublicKeyFromPrivate measures public key recomputation.
+func BenchmarkPublicKeyFromPrivate(b *testing.B) {
+	kp, err := GenerateKeyPair(testSeed([]byte("public from private bench")))
+	if err != nil {
+		b.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	for b.Loop() {
+		if _, err := PublicKeyFromPrivate(kp.PrivateKey); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package falcongo
+
+import (
+	"errors"
+	"sync/atomic"
+)
+
+// ErrSigningDisabled is returned by Sign once DisableSigning was called.
+var ErrSigningDisabled = errors.New("signing is disabled (read-only mode)")
+
+var signingDisabled atomic.Bool
+
+// DisableSigning makes every later Sign in the process fail with
+// ErrSigningDisabled, so a process that only verifies and derives addresses
+// cannot sign even through a bug. It cannot be undone.
+func DisableSigning() {
+	signingDisabled.Store(true)
+}
+
+// SigningDisabled reports whether DisableSigning was called.
+func SigningDisabled() bool {
+	return signingDisabled.Load()
+}
@@ -0,0 +1,175 @@
+package falcongo
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnknownKey is returned when a fingerprint is not in a KeyRegistry.
+var ErrUnknownKey = errors.New("unknown public key fingerprint")
+
+const (
+	// maxRegistryDocument bounds the size of a key document fetched by
+	// LoadURL.
+	maxRegistryDocument = 64 << 20
+	// registryFetchTimeout bounds the fetch of LoadURL.
+	registryFetchTimeout = 30 * time.Second
+)
+
+// KeyRegistry maps the fingerprints of public keys to the keys, so that
+// signatures can refer to their signer by its 32-byte fingerprint rather than
+// carry the PublicKeySize bytes of the key. It is safe for concurrent use;
+// the zero value is an empty registry.
+type KeyRegistry struct {
+	mu   sync.RWMutex
+	keys map[[32]byte]PublicKey
+}
+
+// Add adds pk to the registry and returns its fingerprint.
+func (r *KeyRegistry) Add(pk PublicKey) [32]byte {
+	fp := Fingerprint(pk)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.keys == nil {
+		r.keys = map[[32]byte]PublicKey{}
+	}
+	r.keys[fp] = pk
+	return fp
+}
+
+// Lookup returns the public key with fingerprint fp.
+func (r *KeyRegistry) Lookup(fp [32]byte) (PublicKey, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	pk, ok := r.keys[fp]
+	return pk, ok
+}
+
+// Resolve returns the public key whose fingerprint is ref, 64 hex digits.
+func (r *KeyRegistry) Resolve(ref string) (PublicKey, error) {
+	b, err := hex.DecodeString(strings.TrimSpace(ref))
+	if err != nil || len(b) != 32 {
+		return PublicKey{}, fmt.Errorf("invalid fingerprint %q: want 64 hex digits", ref)
+	}
+	pk, ok := r.Lookup([32]byte(b))
+	if !ok {
+		return PublicKey{}, fmt.Errorf("%w %x", ErrUnknownKey, b)
+	}
+	return pk, nil
+}
+
+// Len returns the number of keys in the registry.
+func (r *KeyRegistry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.keys)
+}
+
+// Load adds the keys of source, a directory, a file or an http(s) URL, and
+// returns the number of keys read. See LoadDocument for the formats.
+func (r *KeyRegistry) Load(source string) (int, error) {
+	if strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://") {
+		return r.LoadURL(source)
+	}
+	info, err := os.Stat(source)
+	if err != nil {
+		return 0, err
+	}
+	if info.IsDir() {
+		return r.LoadDir(source)
+	}
+	b, err := os.ReadFile(source)
+	if err != nil {
+		return 0, err
+	}
+	n, err := r.LoadDocument(b)
+	if err != nil {
+		return n, fmt.Errorf("%s: %w", source, err)
+	}
+	return n, nil
+}
+
+// LoadDir adds the keys of the *.json files of dir.
+func (r *KeyRegistry) LoadDir(dir string) (int, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, path := range paths {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return total, err
+		}
+		n, err := r.LoadDocument(b)
+		total += n
+		if err != nil {
+			return total, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return total, nil
+}
+
+// LoadURL adds the keys of the document served at url.
+func (r *KeyRegistry) LoadURL(url string) (int, error) {
+	client := http.Client{Timeout: registryFetchTimeout}
+	resp, err := client.Get(url)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxRegistryDocument+1))
+	if err != nil {
+		return 0, err
+	}
+	if len(b) > maxRegistryDocument {
+		return 0, fmt.Errorf("%s: key document larger than %d bytes", url, maxRegistryDocument)
+	}
+	n, err := r.LoadDocument(b)
+	if err != nil {
+		return n, fmt.Errorf("%s: %w", url, err)
+	}
+	return n, nil
+}
+
+// LoadDocument adds the keys of a JSON document: a key file of the falcon CLI
+// (an object with a hex public_key) or an array of them. Objects without a
+// public_key, such as mnemonic-only key files, are skipped.
+func (r *KeyRegistry) LoadDocument(doc []byte) (int, error) {
+	type keyFile struct {
+		PublicKey string `json:"public_key"`
+	}
+	var files []keyFile
+	if err := json.Unmarshal(doc, &files); err != nil {
+		var file keyFile
+		if err := json.Unmarshal(doc, &file); err != nil {
+			return 0, fmt.Errorf("invalid key document: %w", err)
+		}
+		files = []keyFile{file}
+	}
+	n := 0
+	for _, f := range files {
+		if f.PublicKey == "" {
+			continue
+		}
+		b, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(f.PublicKey), "0x"))
+		if err != nil || len(b) != PublicKeySize {
+			return n, fmt.Errorf("invalid public_key: want %d bytes of hex", PublicKeySize)
+		}
+		r.Add(PublicKey(b))
+		n++
+	}
+	return n, nil
+}
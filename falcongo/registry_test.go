@@ -0,0 +1,93 @@
+//go:build cgo && !purego
+
+package falcongo
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func TestKeyRegistry(t *testing.T) {
+	var keys [3]PublicKey
+	for i := range keys {
+		kp, err := GenerateKeyPair(testSeed([]byte{byte(i + 10)}))
+		if err != nil {
+			t.Fatalf("GenerateKeyPair failed: %v", err)
+		}
+		keys[i] = kp.PublicKey
+	}
+	keyFile := func(pk PublicKey) string {
+		return fmt.Sprintf(`{"public_key": %q}`, hex.EncodeToString(pk[:]))
+	}
+
+	dir := t.TempDir()
+	for name, doc := range map[string]string{
+		"a.json":        keyFile(keys[0]),
+		"list.json":     "[" + keyFile(keys[1]) + "," + keyFile(keys[0]) + "]",
+		"mnemonic.json": `{"mnemonic": "abandon ..."}`,
+		"notes.txt":     "not a key",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(doc), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var reg KeyRegistry
+	if n, err := reg.Load(dir); err != nil || n != 3 || reg.Len() != 2 {
+		t.Fatalf("Load(dir) = %d, %v; %d keys", n, err, reg.Len())
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/keys.json" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, keyFile(keys[2]))
+	}))
+	defer srv.Close()
+	if n, err := reg.Load(srv.URL + "/keys.json"); err != nil || n != 1 {
+		t.Fatalf("Load(url) = %d, %v", n, err)
+	}
+	if _, err := reg.Load(srv.URL + "/missing.json"); err == nil {
+		t.Fatal("Load succeeded for a missing URL")
+	}
+
+	// Lookups run concurrently with additions.
+	var wg sync.WaitGroup
+	for i := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fp := reg.Add(keys[i])
+			if pk, ok := reg.Lookup(fp); !ok || pk != keys[i] {
+				t.Errorf("Lookup of key %d failed", i)
+			}
+			pk, err := reg.Resolve(hex.EncodeToString(fp[:]))
+			if err != nil || pk != keys[i] {
+				t.Errorf("Resolve of key %d: %v", i, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if reg.Len() != 3 {
+		t.Fatalf("Len = %d, want 3", reg.Len())
+	}
+
+	var other KeyRegistry
+	fp := Fingerprint(keys[0])
+	if _, err := other.Resolve(hex.EncodeToString(fp[:])); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("Resolve in an empty registry: %v", err)
+	}
+	if _, err := reg.Resolve("abcd"); err == nil || errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("Resolve of a short fingerprint: %v", err)
+	}
+	if _, err := other.LoadDocument([]byte(`{"public_key": "00ff"}`)); err == nil {
+		t.Fatal("LoadDocument accepted a short public key")
+	}
+}
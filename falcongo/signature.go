@@ -0,0 +1,177 @@
+package falcongo
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SignatureEncodingVersion is the version byte of the encoding of Signature
+// by MarshalBinary and MarshalText.
+const SignatureEncodingVersion = 1
+
+// signatureEncodingHeaderSize is the version byte and the form byte.
+const signatureEncodingHeaderSize = 2
+
+// ErrInvalidSignatureEncoding is returned when bytes or text do not decode
+// to a Signature.
+var ErrInvalidSignatureEncoding = errors.New("invalid signature encoding")
+
+// Signature is a deterministic FALCON-1024 signature that knows its form, so
+// it cannot be verified as the wrong one. The zero Signature is empty; the
+// others come from NewSignature or the Unmarshal methods, which check the
+// header and length of the signature against its form.
+//
+// MarshalBinary encodes it as a version byte (SignatureEncodingVersion) and a
+// form byte followed by the signature, and MarshalText as the hex of that.
+// The Unmarshal methods also accept a bare compressed or CT signature, as
+// produced by Sign and GetFixedLengthSignature, and take its form from its
+// header: bare signatures start with 0xBA or 0xDA, never with a version byte.
+type Signature struct {
+	form SignatureForm
+	sig  []byte
+}
+
+// NewSignature returns the Signature of a bare compressed or CT signature,
+// in the form given by its header. It copies sig.
+func NewSignature(sig []byte) (Signature, error) {
+	if len(sig) == 0 {
+		return Signature{}, fmt.Errorf("%w: empty signature", ErrInvalidSignatureEncoding)
+	}
+	var form SignatureForm
+	switch sig[0] {
+	case detSigCompressedHeader:
+		form = FormCompressed
+	case detSigCTHeader:
+		form = FormCT
+	default:
+		return Signature{}, fmt.Errorf("%w: unknown header 0x%02x", ErrInvalidSignatureEncoding, sig[0])
+	}
+	return newSignature(form, sig)
+}
+
+// newSignature checks sig against form and copies it.
+func newSignature(form SignatureForm, sig []byte) (Signature, error) {
+	if err := checkForm(form, sig); err != nil {
+		return Signature{}, fmt.Errorf("%w: %w", ErrInvalidSignatureEncoding, err)
+	}
+	return Signature{form: form, sig: append([]byte(nil), sig...)}, nil
+}
+
+// Form returns the form of s.
+func (s Signature) Form() SignatureForm {
+	return s.form
+}
+
+// Bytes returns the bare signature, as Verify (compressed) or VerifyStrict
+// take it. The caller must not modify it.
+func (s Signature) Bytes() []byte {
+	return s.sig
+}
+
+// IsZero reports whether s is the empty Signature.
+func (s Signature) IsZero() bool {
+	return len(s.sig) == 0
+}
+
+// Verify verifies s over data with pk, in the form of s.
+func (s Signature) Verify(data []byte, pk PublicKey) error {
+	if s.IsZero() {
+		return fmt.Errorf("%w: empty signature", ErrInvalidSignatureEncoding)
+	}
+	return VerifyStrict(s.form, data, s.sig, pk)
+}
+
+// Compressed returns s as a CompressedSignature, for APIs that take one. It
+// fails for a CT signature, which has no compressed form.
+func (s Signature) Compressed() (CompressedSignature, error) {
+	if s.IsZero() || s.form != FormCompressed {
+		return nil, fmt.Errorf("%w: want %s, got %s", ErrSignatureForm, FormCompressed, s.form)
+	}
+	return CompressedSignature(s.sig), nil
+}
+
+// CT returns s in the CT form, converting a compressed signature with
+// GetFixedLengthSignature.
+func (s Signature) CT() (Signature, error) {
+	if s.form == FormCT && !s.IsZero() {
+		return s, nil
+	}
+	compressed, err := s.Compressed()
+	if err != nil {
+		return Signature{}, err
+	}
+	ct, err := GetFixedLengthSignature(compressed)
+	if err != nil {
+		return Signature{}, err
+	}
+	return newSignature(FormCT, ct)
+}
+
+// MarshalBinary encodes s with its version and form bytes.
+func (s Signature) MarshalBinary() ([]byte, error) {
+	if s.IsZero() {
+		return nil, fmt.Errorf("%w: empty signature", ErrInvalidSignatureEncoding)
+	}
+	out := make([]byte, 0, signatureEncodingHeaderSize+len(s.sig))
+	out = append(out, SignatureEncodingVersion, byte(s.form))
+	return append(out, s.sig...), nil
+}
+
+// UnmarshalBinary decodes a signature encoded by MarshalBinary, or a bare
+// one.
+func (s *Signature) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("%w: empty signature", ErrInvalidSignatureEncoding)
+	}
+	if data[0] != SignatureEncodingVersion {
+		if data[0] == detSigCompressedHeader || data[0] == detSigCTHeader {
+			sig, err := NewSignature(data)
+			if err != nil {
+				return err
+			}
+			*s = sig
+			return nil
+		}
+		return fmt.Errorf("%w: unsupported version %d", ErrInvalidSignatureEncoding, data[0])
+	}
+	if len(data) < signatureEncodingHeaderSize {
+		return fmt.Errorf("%w: truncated header", ErrInvalidSignatureEncoding)
+	}
+	sig, err := newSignature(SignatureForm(data[1]), data[signatureEncodingHeaderSize:])
+	if err != nil {
+		return err
+	}
+	*s = sig
+	return nil
+}
+
+// MarshalText encodes s as the lowercase hex of MarshalBinary.
+func (s Signature) MarshalText() ([]byte, error) {
+	b, err := s.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(hex.EncodeToString(b)), nil
+}
+
+// UnmarshalText decodes the hex of a signature encoded by MarshalBinary, or
+// of a bare one. A 0x prefix and surrounding space are ignored.
+func (s *Signature) UnmarshalText(text []byte) error {
+	t := strings.TrimSpace(string(text))
+	t = strings.TrimPrefix(strings.TrimPrefix(t, "0x"), "0X")
+	b, err := hex.DecodeString(t)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignatureEncoding, err)
+	}
+	return s.UnmarshalBinary(b)
+}
+
+// String returns the form and length of s, not its bytes.
+func (s Signature) String() string {
+	if s.IsZero() {
+		return "empty signature"
+	}
+	return fmt.Sprintf("%s signature of %d bytes", s.form, len(s.sig))
+}
@@ -0,0 +1,95 @@
+//go:build cgo && !purego
+
+package falcongo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// TestSignatureEncoding round-trips both forms through the binary, text and
+// JSON encodings, and checks bare signatures decode to the form of their
+// header.
+func TestSignatureEncoding(t *testing.T) {
+	kp, err := GenerateKeyPair(testSeed([]byte("signature encoding seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	msg := []byte("typed")
+	compressed, err := kp.Sign(msg)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	sig, err := NewSignature(compressed)
+	if err != nil {
+		t.Fatalf("NewSignature failed: %v", err)
+	}
+	ct, err := sig.CT()
+	if err != nil {
+		t.Fatalf("CT failed: %v", err)
+	}
+
+	for _, s := range []Signature{sig, ct} {
+		if err := s.Verify(msg, kp.PublicKey); err != nil {
+			t.Fatalf("%s: Verify failed: %v", s, err)
+		}
+		b, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatalf("%s: MarshalBinary failed: %v", s, err)
+		}
+		if b[0] != SignatureEncodingVersion || SignatureForm(b[1]) != s.Form() || !bytes.Equal(b[2:], s.Bytes()) {
+			t.Fatalf("%s: unexpected header %x", s, b[:2])
+		}
+		var fromBinary, fromBare, fromJSON Signature
+		if err := fromBinary.UnmarshalBinary(b); err != nil || fromBinary.Form() != s.Form() ||
+			!bytes.Equal(fromBinary.Bytes(), s.Bytes()) {
+			t.Fatalf("%s: binary round trip: %v", s, err)
+		}
+		if err := fromBare.UnmarshalBinary(s.Bytes()); err != nil || fromBare.Form() != s.Form() {
+			t.Fatalf("%s: bare signature: %v", s, err)
+		}
+		j, err := json.Marshal(struct{ Signature Signature }{s})
+		if err != nil {
+			t.Fatalf("%s: json.Marshal failed: %v", s, err)
+		}
+		var decoded struct{ Signature Signature }
+		if err := json.Unmarshal(j, &decoded); err != nil {
+			t.Fatalf("%s: json.Unmarshal failed: %v", s, err)
+		}
+		fromJSON = decoded.Signature
+		if fromJSON.Form() != s.Form() || !bytes.Equal(fromJSON.Bytes(), s.Bytes()) {
+			t.Fatalf("%s: JSON round trip gave %s", s, fromJSON)
+		}
+	}
+	if _, err := ct.Compressed(); !errors.Is(err, ErrSignatureForm) {
+		t.Fatalf("Compressed of a CT signature: got %v", err)
+	}
+	if err := sig.Verify([]byte("other"), kp.PublicKey); err == nil {
+		t.Fatal("signature verified over another message")
+	}
+
+	mislabeled := append([]byte{SignatureEncodingVersion, byte(FormCT)}, compressed...)
+	for name, b := range map[string][]byte{
+		"empty":          nil,
+		"truncated":      {SignatureEncodingVersion},
+		"mislabeled":     mislabeled,
+		"other version":  append([]byte{2, byte(FormCompressed)}, compressed...),
+		"unknown form":   append([]byte{SignatureEncodingVersion, 9}, compressed...),
+		"unknown header": {0x30, 0x01},
+		"short ct":       ct.Bytes()[:CTSignatureSize-1],
+	} {
+		var s Signature
+		if err := s.UnmarshalBinary(b); !errors.Is(err, ErrInvalidSignatureEncoding) {
+			t.Errorf("%s: expected ErrInvalidSignatureEncoding, got %v", name, err)
+		}
+	}
+	var s Signature
+	if err := s.UnmarshalText([]byte("zz")); !errors.Is(err, ErrInvalidSignatureEncoding) {
+		t.Fatalf("bad hex: got %v", err)
+	}
+	if _, err := (Signature{}).MarshalText(); err == nil {
+		t.Fatal("the empty signature was encoded")
+	}
+}
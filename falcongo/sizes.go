@@ -0,0 +1,35 @@
+package falcongo
+
+import "github.com/algorandfoundation/falcon-signatures/falcongo/internal/det1024"
+
+// Encoded sizes of FALCON-1024 keys, signatures and seeds, in bytes. They are
+// the same with and without cgo.
+const (
+	PublicKeySize  = det1024.PublicKeySize
+	PrivateKeySize = 2305
+	// MaxCompressedSignatureSize bounds deterministic compressed signatures,
+	// whose length varies with the signature.
+	MaxCompressedSignatureSize = det1024.SignatureMaxSize
+	// CTSignatureSize is the length of every deterministic fixed-length (CT)
+	// signature.
+	CTSignatureSize = det1024.CTSignatureSize
+	// SeedSize is the length of the keygen seeds that GenerateKeyPair draws,
+	// DeriveSubkey derives and mnemonics encode.
+	SeedSize = 48
+
+	// SignatureMaxSize is MaxCompressedSignatureSize.
+	//
+	// Deprecated: Use MaxCompressedSignatureSize.
+	SignatureMaxSize = MaxCompressedSignatureSize
+)
+
+// minCompressedSignatureSize is a header and a salt version byte.
+const minCompressedSignatureSize = 2
+
+// IsValidSignatureLength reports whether n bytes can hold a deterministic
+// signature, compressed or CT. It is a cheap check of untrusted lengths before
+// allocating or verifying; only Verify or VerifyStrict tell whether a signature
+// is valid.
+func IsValidSignatureLength(n int) bool {
+	return n == CTSignatureSize || (n >= minCompressedSignatureSize && n <= MaxCompressedSignatureSize)
+}
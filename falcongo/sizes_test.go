@@ -0,0 +1,42 @@
+package falcongo
+
+import "testing"
+
+// TestSizes pins the exported sizes, which are part of the wire format.
+func TestSizes(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		got, want int
+	}{
+		{"PublicKeySize", PublicKeySize, 1793},
+		{"PrivateKeySize", PrivateKeySize, 2305},
+		{"MaxCompressedSignatureSize", MaxCompressedSignatureSize, 1423},
+		{"CTSignatureSize", CTSignatureSize, 1538},
+		{"SeedSize", SeedSize, 48},
+		{"len(PublicKey)", len(PublicKey{}), PublicKeySize},
+		{"len(PrivateKey)", len(PrivateKey{}), PrivateKeySize},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("%s = %d, want %d", tc.name, tc.got, tc.want)
+		}
+	}
+}
+
+// TestIsValidSignatureLength checks the bounds of both signature forms.
+func TestIsValidSignatureLength(t *testing.T) {
+	for n, want := range map[int]bool{
+		-1:                             false,
+		0:                              false,
+		1:                              false,
+		2:                              true,
+		MaxCompressedSignatureSize:     true,
+		MaxCompressedSignatureSize + 1: false,
+		CTSignatureSize - 1:            false,
+		CTSignatureSize:                true,
+		CTSignatureSize + 1:            false,
+	} {
+		if got := IsValidSignatureLength(n); got != want {
+			t.Errorf("IsValidSignatureLength(%d) = %v, want %v", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package falcongo
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"runtime"
+	"sync"
+)
+
+// A signature stream is a compact binary encoding of many signatures to
+// verify, for pipelines that check large logs. It starts with StreamMagic and
+// holds records, each a type byte and a body:
+//
+//	key record (StreamRecordKey):
+//	  public key   PublicKeySize bytes; its reference is the number of key
+//	               records (of either type) before it
+//	key fingerprint record (StreamRecordKeyFingerprint):
+//	  fingerprint  32 bytes, the Fingerprint of a key of the KeyRegistry the
+//	               stream is verified with; referenced like a key record
+//	signature record (StreamRecordSignature):
+//	  key ref      uint32, a key defined earlier in the stream
+//	  msg length   uint32, at most MaxStreamMessageSize
+//	  msg          the signed bytes, typically the hash of a log entry
+//	  sig length   uint16
+//	  sig          compressed signature
+//
+// Integers are big-endian. The stream ends at the end of a record.
+const (
+	StreamMagic           = "FALCONS\x01"
+	StreamRecordKey       = 0x01
+	StreamRecordSignature = 0x02
+	// StreamRecordKeyFingerprint defines a key by its fingerprint instead of
+	// its PublicKeySize bytes.
+	StreamRecordKeyFingerprint = 0x03
+	// MaxStreamKeys bounds the keys of a stream, and so the memory used to
+	// hold them.
+	MaxStreamKeys = 1 << 16
+	// MaxStreamMessageSize bounds the message of a signature record.
+	MaxStreamMessageSize = 1 << 20
+)
+
+// ErrStreamFormat is returned for a malformed signature stream.
+var ErrStreamFormat = errors.New("malformed signature stream")
+
+// StreamResult is the outcome of a signature record of a stream.
+type StreamResult struct {
+	Index     uint64 // of the signature record, from 0; key records are not counted
+	KeyRef    uint32
+	PublicKey PublicKey
+	Err       error // nil if the signature is valid
+}
+
+// streamBatch is the number of signature records verified concurrently per
+// available CPU.
+const streamBatch = 64
+
+// VerifyStream verifies the signature records read from r and yields their
+// results in stream order. A malformed stream or a read error is yielded as
+// the error of a last, zero result. Signatures are verified in parallel in
+// batches, so r may be read ahead of the results.
+func VerifyStream(r io.Reader) iter.Seq2[StreamResult, error] {
+	return VerifyStreamWithRegistry(r, nil)
+}
+
+// VerifyStreamWithRegistry is VerifyStream for streams with key fingerprint
+// records, which are resolved with registry. A fingerprint that is not in
+// registry (or any, if registry is nil) is yielded as an error wrapping
+// ErrUnknownKey.
+func VerifyStreamWithRegistry(r io.Reader, registry *KeyRegistry) iter.Seq2[StreamResult, error] {
+	return func(yield func(StreamResult, error) bool) {
+		sr := streamReader{r: bufio.NewReaderSize(r, 1<<16), registry: registry}
+		workers := runtime.GOMAXPROCS(0)
+		batch := make([]streamRecord, 0, streamBatch*workers)
+		results := make([]StreamResult, cap(batch))
+		var index uint64
+		for {
+			batch = batch[:0]
+			var readErr error
+			for len(batch) < cap(batch) {
+				rec, err := sr.next()
+				if err != nil {
+					if err != io.EOF {
+						readErr = err
+					}
+					break
+				}
+				batch = append(batch, rec)
+			}
+
+			var wg sync.WaitGroup
+			for w := range min(workers, len(batch)) {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := w; i < len(batch); i += workers {
+						rec := batch[i]
+						results[i] = StreamResult{
+							Index:     index + uint64(i),
+							KeyRef:    rec.keyRef,
+							PublicKey: *rec.key,
+							Err:       Verify(rec.msg, rec.sig, *rec.key),
+						}
+					}
+				}()
+			}
+			wg.Wait()
+			for i := range batch {
+				if !yield(results[i], nil) {
+					return
+				}
+			}
+			index += uint64(len(batch))
+
+			if readErr != nil {
+				yield(StreamResult{}, readErr)
+				return
+			}
+			if len(batch) < cap(batch) {
+				return
+			}
+		}
+	}
+}
+
+// streamRecord is a signature record read from a stream.
+type streamRecord struct {
+	keyRef uint32
+	key    *PublicKey
+	msg    []byte
+	sig    CompressedSignature
+}
+
+// streamReader decodes the records of a signature stream.
+type streamReader struct {
+	r        *bufio.Reader
+	registry *KeyRegistry
+	header   bool
+	keys     []*PublicKey
+	offset   int64
+}
+
+// next returns the next signature record, reading the key records before it.
+// It returns io.EOF at the end of the stream.
+func (s *streamReader) next() (streamRecord, error) {
+	if !s.header {
+		magic := make([]byte, len(StreamMagic))
+		err := s.read(magic)
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return streamRecord{}, err
+		}
+		if err != nil || string(magic) != StreamMagic {
+			return streamRecord{}, fmt.Errorf("%w: bad magic", ErrStreamFormat)
+		}
+		s.header = true
+	}
+	for {
+		start := s.offset
+		var typ [1]byte
+		if err := s.read(typ[:]); err != nil {
+			if err == io.ErrUnexpectedEOF {
+				return streamRecord{}, io.EOF
+			}
+			return streamRecord{}, err
+		}
+		switch typ[0] {
+		case StreamRecordKey, StreamRecordKeyFingerprint:
+			if len(s.keys) == MaxStreamKeys {
+				return streamRecord{}, fmt.Errorf("%w: more than %d keys", ErrStreamFormat, MaxStreamKeys)
+			}
+			pk := new(PublicKey)
+			if typ[0] == StreamRecordKey {
+				if err := s.read(pk[:]); err != nil {
+					return streamRecord{}, s.truncated(start, err)
+				}
+				s.keys = append(s.keys, pk)
+				continue
+			}
+			var fp [32]byte
+			if err := s.read(fp[:]); err != nil {
+				return streamRecord{}, s.truncated(start, err)
+			}
+			var ok bool
+			if s.registry != nil {
+				*pk, ok = s.registry.Lookup(fp)
+			}
+			if !ok {
+				return streamRecord{}, fmt.Errorf("%w %x: key record at offset %d", ErrUnknownKey, fp, start)
+			}
+			s.keys = append(s.keys, pk)
+		case StreamRecordSignature:
+			var head [8]byte
+			if err := s.read(head[:]); err != nil {
+				return streamRecord{}, s.truncated(start, err)
+			}
+			rec := streamRecord{keyRef: binary.BigEndian.Uint32(head[:4])}
+			if rec.keyRef >= uint32(len(s.keys)) {
+				return streamRecord{}, fmt.Errorf("%w: record at offset %d refers to undefined key %d",
+					ErrStreamFormat, start, rec.keyRef)
+			}
+			rec.key = s.keys[rec.keyRef]
+			msgLen := binary.BigEndian.Uint32(head[4:])
+			if msgLen > MaxStreamMessageSize {
+				return streamRecord{}, fmt.Errorf("%w: record at offset %d has a %d-byte message",
+					ErrStreamFormat, start, msgLen)
+			}
+			rec.msg = make([]byte, msgLen)
+			if err := s.read(rec.msg); err != nil {
+				return streamRecord{}, s.truncated(start, err)
+			}
+			var sigLen [2]byte
+			if err := s.read(sigLen[:]); err != nil {
+				return streamRecord{}, s.truncated(start, err)
+			}
+			n := binary.BigEndian.Uint16(sigLen[:])
+			if !IsValidSignatureLength(int(n)) {
+				return streamRecord{}, fmt.Errorf("%w: record at offset %d has a %d-byte signature",
+					ErrStreamFormat, start, n)
+			}
+			rec.sig = make(CompressedSignature, n)
+			if err := s.read(rec.sig); err != nil {
+				return streamRecord{}, s.truncated(start, err)
+			}
+			return rec, nil
+		default:
+			return streamRecord{}, fmt.Errorf("%w: unknown record type 0x%02x at offset %d",
+				ErrStreamFormat, typ[0], start)
+		}
+	}
+}
+
+// read fills b, returning io.ErrUnexpectedEOF if the stream ends first.
+func (s *streamReader) read(b []byte) error {
+	n, err := io.ReadFull(s.r, b)
+	s.offset += int64(n)
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+func (s *streamReader) truncated(start int64, err error) error {
+	if err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("%w: record at offset %d is truncated", ErrStreamFormat, start)
+	}
+	return err
+}
+
+// StreamWriter encodes a signature stream.
+type StreamWriter struct {
+	w      io.Writer
+	header bool
+	keys   uint32
+}
+
+// NewStreamWriter returns a StreamWriter writing to w. Wrap w in a
+// bufio.Writer when writing many records.
+func NewStreamWriter(w io.Writer) *StreamWriter {
+	return &StreamWriter{w: w}
+}
+
+// AddKey writes a key record for pk and returns its reference.
+func (s *StreamWriter) AddKey(pk PublicKey) (uint32, error) {
+	if s.keys == MaxStreamKeys {
+		return 0, fmt.Errorf("a stream holds at most %d keys", MaxStreamKeys)
+	}
+	if err := s.write(append([]byte{StreamRecordKey}, pk[:]...)); err != nil {
+		return 0, err
+	}
+	s.keys++
+	return s.keys - 1, nil
+}
+
+// AddKeyFingerprint writes a key fingerprint record for the key with
+// fingerprint fp and returns its reference. The stream must be verified with
+// a KeyRegistry holding the key.
+func (s *StreamWriter) AddKeyFingerprint(fp [32]byte) (uint32, error) {
+	if s.keys == MaxStreamKeys {
+		return 0, fmt.Errorf("a stream holds at most %d keys", MaxStreamKeys)
+	}
+	if err := s.write(append([]byte{StreamRecordKeyFingerprint}, fp[:]...)); err != nil {
+		return 0, err
+	}
+	s.keys++
+	return s.keys - 1, nil
+}
+
+// WriteSignature writes a signature record of sig over msg by the key keyRef.
+func (s *StreamWriter) WriteSignature(keyRef uint32, msg []byte, sig CompressedSignature) error {
+	if keyRef >= s.keys {
+		return fmt.Errorf("undefined key %d", keyRef)
+	}
+	if len(msg) > MaxStreamMessageSize {
+		return fmt.Errorf("message of %d bytes is larger than %d", len(msg), MaxStreamMessageSize)
+	}
+	if !IsValidSignatureLength(len(sig)) {
+		return fmt.Errorf("invalid signature length %d", len(sig))
+	}
+	rec := make([]byte, 0, 1+8+len(msg)+2+len(sig))
+	rec = append(rec, StreamRecordSignature)
+	rec = binary.BigEndian.AppendUint32(rec, keyRef)
+	rec = binary.BigEndian.AppendUint32(rec, uint32(len(msg)))
+	rec = append(rec, msg...)
+	rec = binary.BigEndian.AppendUint16(rec, uint16(len(sig)))
+	rec = append(rec, sig...)
+	return s.write(rec)
+}
+
+func (s *StreamWriter) write(rec []byte) error {
+	if !s.header {
+		if _, err := io.WriteString(s.w, StreamMagic); err != nil {
+			return err
+		}
+		s.header = true
+	}
+	_, err := s.w.Write(rec)
+	return err
+}
@@ -0,0 +1,146 @@
+//go:build cgo && !purego
+
+package falcongo
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// writeTestStream writes a stream of n signature records alternating between
+// two keys; the records listed in bad have a signature over another message.
+func writeTestStream(t *testing.T, n int, bad map[int]bool) []byte {
+	t.Helper()
+	var keys [2]KeyPair
+	var buf bytes.Buffer
+	sw := NewStreamWriter(&buf)
+	for i := range keys {
+		var err error
+		if keys[i], err = GenerateKeyPair(testSeed([]byte{byte(i + 1)})); err != nil {
+			t.Fatalf("GenerateKeyPair failed: %v", err)
+		}
+		if ref, err := sw.AddKey(keys[i].PublicKey); err != nil || ref != uint32(i) {
+			t.Fatalf("AddKey = %d, %v", ref, err)
+		}
+	}
+	for i := range n {
+		msg := []byte{byte(i), byte(i >> 8)}
+		signed := msg
+		if bad[i] {
+			signed = []byte("other")
+		}
+		sig, err := keys[i%2].Sign(signed)
+		if err != nil {
+			t.Fatalf("Sign failed: %v", err)
+		}
+		if err := sw.WriteSignature(uint32(i%2), msg, sig); err != nil {
+			t.Fatalf("WriteSignature failed: %v", err)
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestVerifyStream(t *testing.T) {
+	bad := map[int]bool{3: true, 70: true}
+	stream := writeTestStream(t, 100, bad)
+	var n int
+	for res, err := range VerifyStream(bytes.NewReader(stream)) {
+		if err != nil {
+			t.Fatalf("VerifyStream failed: %v", err)
+		}
+		if res.Index != uint64(n) || res.KeyRef != uint32(n%2) {
+			t.Fatalf("result %d: index %d, key %d", n, res.Index, res.KeyRef)
+		}
+		if (res.Err != nil) != bad[n] {
+			t.Fatalf("record %d: err = %v", n, res.Err)
+		}
+		n++
+	}
+	if n != 100 {
+		t.Fatalf("got %d results, want 100", n)
+	}
+
+	// Stopping early is allowed.
+	for range VerifyStream(bytes.NewReader(stream)) {
+		break
+	}
+}
+
+func TestVerifyStream_Malformed(t *testing.T) {
+	stream := writeTestStream(t, 2, nil)
+	keyRecord := 1 + PublicKeySize
+	for _, tc := range []struct {
+		name  string
+		in    []byte
+		valid int
+	}{
+		{"empty", nil, 0},
+		{"bad magic", append([]byte("FALCONS\x02"), stream[8:]...), 0},
+		{"truncated", stream[:len(stream)-1], 1},
+		{"unknown record", append(bytes.Clone(stream), 0x7f), 2},
+		{"undefined key", append([]byte(StreamMagic), StreamRecordSignature, 0, 0, 0, 0), 0},
+		{"oversized message", append(bytes.Clone(stream[:8+2*keyRecord]),
+			StreamRecordSignature, 0, 0, 0, 0, 0xff, 0xff, 0xff, 0xff), 0},
+	} {
+		valid := 0
+		var got error
+		for res, err := range VerifyStream(bytes.NewReader(tc.in)) {
+			if err != nil {
+				got = err
+				break
+			}
+			if res.Err == nil {
+				valid++
+			}
+		}
+		if !errors.Is(got, ErrStreamFormat) || valid != tc.valid {
+			t.Fatalf("%s: err = %v after %d valid records; want ErrStreamFormat after %d",
+				tc.name, got, valid, tc.valid)
+		}
+	}
+}
+
+func TestVerifyStreamWithRegistry(t *testing.T) {
+	kp, err := GenerateKeyPair(testSeed([]byte{9}))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	var buf bytes.Buffer
+	sw := NewStreamWriter(&buf)
+	if ref, err := sw.AddKeyFingerprint(Fingerprint(kp.PublicKey)); err != nil || ref != 0 {
+		t.Fatalf("AddKeyFingerprint = %d, %v", ref, err)
+	}
+	sig, err := kp.Sign([]byte("entry"))
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if err := sw.WriteSignature(0, []byte("entry"), sig); err != nil {
+		t.Fatalf("WriteSignature failed: %v", err)
+	}
+	stream := buf.Bytes()
+	if len(stream) != len(StreamMagic)+1+32+1+8+5+2+len(sig) {
+		t.Fatalf("stream of %d bytes", len(stream))
+	}
+
+	var reg KeyRegistry
+	reg.Add(kp.PublicKey)
+	n := 0
+	for res, err := range VerifyStreamWithRegistry(bytes.NewReader(stream), &reg) {
+		if err != nil || res.Err != nil || res.PublicKey != kp.PublicKey {
+			t.Fatalf("record %d: %v, %v", n, err, res.Err)
+		}
+		n++
+	}
+	if n != 1 {
+		t.Fatalf("got %d results, want 1", n)
+	}
+
+	for _, registry := range []*KeyRegistry{nil, {}} {
+		for _, err := range VerifyStreamWithRegistry(bytes.NewReader(stream), registry) {
+			if !errors.Is(err, ErrUnknownKey) {
+				t.Fatalf("unknown key: err = %v", err)
+			}
+		}
+	}
+}
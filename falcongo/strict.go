@@ -0,0 +1,75 @@
+package falcongo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// SignatureForm is a signature encoding that VerifyStrict can require.
+type SignatureForm int
+
+const (
+	// FormCompressed is the variable-length compressed encoding produced by
+	// Sign and accepted by Verify.
+	FormCompressed SignatureForm = iota
+	// FormCT is the fixed-length CT encoding of CTSignatureSize bytes
+	// produced by GetFixedLengthSignature.
+	FormCT
+)
+
+// String returns the encoding name used by InspectSignature.
+func (f SignatureForm) String() string {
+	switch f {
+	case FormCompressed:
+		return EncodingCompressed
+	case FormCT:
+		return EncodingCT
+	default:
+		return fmt.Sprintf("SignatureForm(%d)", int(f))
+	}
+}
+
+// ErrSignatureForm is returned by VerifyStrict when the signature is not
+// encoded in the required form, before any cryptographic check.
+var ErrSignatureForm = errors.New("signature is not in the required form")
+
+// VerifyStrict is like Verify but accepts only signatures encoded in form,
+// and reports a signature in another form with ErrSignatureForm rather than
+// as a plain verification failure, so encoding mistakes are told apart from
+// bad signatures.
+func VerifyStrict(form SignatureForm, data []byte, sig []byte, pk PublicKey) error {
+	if err := checkForm(form, sig); err != nil {
+		return err
+	}
+	if form == FormCT {
+		return verifyFixedLength(data, sig, pk)
+	}
+	return Verify(data, CompressedSignature(sig), pk)
+}
+
+// checkForm checks the header and length of sig against form, reporting a
+// mismatch with ErrSignatureForm.
+func checkForm(form SignatureForm, sig []byte) error {
+	var header byte
+	var sizeOK bool
+	switch form {
+	case FormCompressed:
+		header, sizeOK = detSigCompressedHeader, len(sig) >= minCompressedSignatureSize && len(sig) <= MaxCompressedSignatureSize
+	case FormCT:
+		header, sizeOK = detSigCTHeader, len(sig) == CTSignatureSize
+	default:
+		return fmt.Errorf("unknown signature form %d", int(form))
+	}
+	if len(sig) == 0 || sig[0] != header {
+		got := "empty signature"
+		if len(sig) > 0 {
+			got = fmt.Sprintf("header 0x%02x", sig[0])
+		}
+		return fmt.Errorf("%w: want %s (header 0x%02x), got %s",
+			ErrSignatureForm, form, header, got)
+	}
+	if !sizeOK {
+		return fmt.Errorf("%w: %s signature of %d bytes", ErrSignatureForm, form, len(sig))
+	}
+	return nil
+}
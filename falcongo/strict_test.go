@@ -0,0 +1,55 @@
+//go:build cgo && !purego
+
+package falcongo
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestVerifyStrict checks each form accepts its own encoding and reports the
+// other one as a form error rather than a verification failure.
+func TestVerifyStrict(t *testing.T) {
+	kp, err := GenerateKeyPair(testSeed([]byte("verify strict seed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	msg := []byte("strict")
+	sig, err := kp.Sign(msg)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	ct, err := GetFixedLengthSignature(sig)
+	if err != nil {
+		t.Fatalf("GetFixedLengthSignature failed: %v", err)
+	}
+
+	if err := VerifyStrict(FormCompressed, msg, sig, kp.PublicKey); err != nil {
+		t.Fatalf("compressed signature rejected: %v", err)
+	}
+	if err := VerifyStrict(FormCT, msg, ct, kp.PublicKey); err != nil {
+		t.Fatalf("CT signature rejected: %v", err)
+	}
+
+	for name, tc := range map[string]struct {
+		form SignatureForm
+		sig  []byte
+	}{
+		"compressed as ct": {FormCT, sig},
+		"ct as compressed": {FormCompressed, ct},
+		"short ct":         {FormCT, ct[:len(ct)-1]},
+		"empty":            {FormCT, nil},
+	} {
+		if err := VerifyStrict(tc.form, msg, tc.sig, kp.PublicKey); !errors.Is(err, ErrSignatureForm) {
+			t.Errorf("%s: expected ErrSignatureForm, got %v", name, err)
+		}
+	}
+
+	err = VerifyStrict(FormCT, []byte("other"), ct, kp.PublicKey)
+	if err == nil || errors.Is(err, ErrSignatureForm) {
+		t.Fatalf("expected a verification failure for a wrong message, got %v", err)
+	}
+	if err := VerifyStrict(SignatureForm(7), msg, sig, kp.PublicKey); err == nil {
+		t.Fatalf("unknown form accepted")
+	}
+}
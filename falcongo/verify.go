@@ -0,0 +1,30 @@
+package falcongo
+
+import "github.com/algorandfoundation/falcon-signatures/falcongo/internal/det1024"
+
+// The pure-Go verifier lives in internal/det1024 so that falcongo/verifyonly
+// can use it without importing the C implementation. It lets verification
+// run where cgo is unavailable, such as WebAssembly.
+
+const (
+	falconQ    = det1024.Q
+	falconLogN = det1024.LogN
+	falconN    = det1024.N
+
+	detSigCompressedHeader = det1024.CompressedHeader
+	detSigCTHeader         = det1024.CTHeader
+)
+
+var errVerify = det1024.ErrVerify
+
+// verifyCompressed reports whether sig is a valid deterministic compressed
+// signature of msg under the encoded public key pk.
+func verifyCompressed(pk []byte, sig []byte, msg []byte) error {
+	return det1024.VerifyCompressed(pk, sig, msg)
+}
+
+// verifyCT reports whether sig is a valid deterministic CT signature of msg
+// under the encoded public key pk.
+func verifyCT(pk []byte, sig []byte, msg []byte) error {
+	return det1024.VerifyCT(pk, sig, msg)
+}
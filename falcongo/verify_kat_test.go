@@ -0,0 +1,81 @@
+package falcongo
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/algorandfoundation/falcon-signatures/falcongo/internal/det1024"
+)
+
+type verifyKAT struct {
+	PublicKey string `json:"public_key"`
+	Message   string `json:"message"`
+	Signature string `json:"signature"`
+	// SignatureCT is Signature converted to the fixed-length CT form.
+	SignatureCT string `json:"signature_ct"`
+}
+
+// readVerifyKATs reads testdata/verify_kat.json.
+func readVerifyKATs(tb testing.TB) []verifyKAT {
+	tb.Helper()
+	data, err := os.ReadFile("testdata/verify_kat.json")
+	if err != nil {
+		tb.Fatalf("failed to read verify KAT: %v", err)
+	}
+	var kats []verifyKAT
+	if err := json.Unmarshal(data, &kats); err != nil {
+		tb.Fatalf("failed to parse verify KAT: %v", err)
+	}
+	return kats
+}
+
+// TestVerify_KAT checks fixed signatures with both Verify and the pure-Go
+// verifier. It needs no cgo, so it also runs under GOOS=js GOARCH=wasm.
+func TestVerify_KAT(t *testing.T) {
+	for i, kat := range readVerifyKATs(t) {
+		pkBytes, err1 := hex.DecodeString(kat.PublicKey)
+		msg, err2 := hex.DecodeString(kat.Message)
+		sig, err3 := hex.DecodeString(kat.Signature)
+		sigCT, err4 := hex.DecodeString(kat.SignatureCT)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil || len(pkBytes) != PublicKeySize {
+			t.Fatalf("case %d: malformed KAT entry", i)
+		}
+		var pk PublicKey
+		copy(pk[:], pkBytes)
+
+		if err := Verify(msg, sig, pk); err != nil {
+			t.Fatalf("case %d: Verify failed: %v", i, err)
+		}
+		if err := verifyCompressed(pk[:], sig, msg); err != nil {
+			t.Fatalf("case %d: pure-Go verify failed: %v", i, err)
+		}
+		bad := append([]byte{}, msg...)
+		bad = append(bad, 0)
+		if verifyCompressed(pk[:], sig, bad) == nil {
+			t.Fatalf("case %d: pure-Go verify accepted a different message", i)
+		}
+		salted, err := det1024.Resalt(sig)
+		if err != nil || det1024.VerifySalted(pk[:], salted, msg) != nil || det1024.VerifySalted(pk[:], salted, bad) == nil {
+			t.Fatalf("case %d: the resalted signature does not check as a standard one: %v", i, err)
+		}
+
+		if err := VerifyStrict(FormCT, msg, sigCT, pk); err != nil {
+			t.Fatalf("case %d: VerifyStrict(FormCT) failed: %v", i, err)
+		}
+		if err := verifyCT(pk[:], sigCT, msg); err != nil {
+			t.Fatalf("case %d: pure-Go CT verify failed: %v", i, err)
+		}
+		if verifyCT(pk[:], sigCT, bad) == nil {
+			t.Fatalf("case %d: pure-Go CT verify accepted a different message", i)
+		}
+		if err := VerifyStrict(FormCT, msg, sig, pk); !errors.Is(err, ErrSignatureForm) {
+			t.Fatalf("case %d: VerifyStrict(FormCT) on a compressed signature: %v", i, err)
+		}
+		if err := VerifyStrict(FormCompressed, msg, sigCT, pk); !errors.Is(err, ErrSignatureForm) {
+			t.Fatalf("case %d: VerifyStrict(FormCompressed) on a CT signature: %v", i, err)
+		}
+	}
+}
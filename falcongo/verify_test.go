@@ -0,0 +1,148 @@
+//go:build cgo && !purego
+
+package falcongo
+
+import (
+	"fmt"
+	"testing"
+)
+
+// TestVerifyCompressed_MatchesC checks the pure-Go verifier accepts and
+// rejects exactly what the C implementation does.
+func TestVerifyCompressed_MatchesC(t *testing.T) {
+	for k := range 3 {
+		kp, err := GenerateKeyPair(testSeed([]byte(fmt.Sprintf("pure-go verify seed %d", k))))
+		if err != nil {
+			t.Fatalf("GenerateKeyPair failed: %v", err)
+		}
+		other, err := GenerateKeyPair(testSeed([]byte(fmt.Sprintf("pure-go verify other %d", k))))
+		if err != nil {
+			t.Fatalf("GenerateKeyPair failed: %v", err)
+		}
+		for m := range 5 {
+			msg := []byte(fmt.Sprintf("message %d/%d", k, m))
+			if m == 0 {
+				msg = nil
+			}
+			sig, err := kp.Sign(msg)
+			if err != nil {
+				t.Fatalf("Sign failed: %v", err)
+			}
+
+			check := func(name string, msg []byte, sig CompressedSignature, pk PublicKey) {
+				t.Helper()
+				want := Verify(msg, sig, pk) == nil
+				got := verifyCompressed(pk[:], sig, msg) == nil
+				if got != want {
+					t.Fatalf("%s: pure-Go verify=%v, C verify=%v", name, got, want)
+				}
+			}
+
+			check("valid", msg, sig, kp.PublicKey)
+			if verifyCompressed(kp.PublicKey[:], sig, msg) != nil {
+				t.Fatalf("valid signature rejected")
+			}
+			check("wrong message", append([]byte("x"), msg...), sig, kp.PublicKey)
+			check("wrong key", msg, sig, other.PublicKey)
+			check("truncated", msg, sig[:len(sig)-1], kp.PublicKey)
+			check("extended", msg, append(append(CompressedSignature{}, sig...), 0), kp.PublicKey)
+			check("salt version", msg, append(CompressedSignature{sig[0], 1}, sig[2:]...), kp.PublicKey)
+			for _, pos := range []int{0, 2, 3, len(sig) / 2, len(sig) - 1} {
+				for _, bit := range []byte{0x01, 0x80} {
+					bad := append(CompressedSignature{}, sig...)
+					bad[pos] ^= bit
+					check(fmt.Sprintf("flip %d/%#x", pos, bit), msg, bad, kp.PublicKey)
+				}
+			}
+		}
+	}
+}
+
+// TestVerifyCT_MatchesC checks the pure-Go CT verifier against the C one.
+func TestVerifyCT_MatchesC(t *testing.T) {
+	kp, err := GenerateKeyPair(testSeed([]byte("pure-go verify ct")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	msg := []byte("ct message")
+	sig, err := kp.Sign(msg)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	ct, err := GetFixedLengthSignature(sig)
+	if err != nil {
+		t.Fatalf("GetFixedLengthSignature failed: %v", err)
+	}
+
+	check := func(name string, msg []byte, sig []byte) {
+		t.Helper()
+		want := verifyFixedLength(msg, sig, kp.PublicKey) == nil
+		got := verifyCT(kp.PublicKey[:], sig, msg) == nil
+		if got != want {
+			t.Fatalf("%s: pure-Go verify=%v, C verify=%v", name, got, want)
+		}
+	}
+
+	check("valid", msg, ct)
+	if verifyCT(kp.PublicKey[:], ct, msg) != nil {
+		t.Fatalf("valid CT signature rejected")
+	}
+	check("wrong message", []byte("other"), ct)
+	check("truncated", msg, ct[:len(ct)-1])
+	// 0x800 in the first coefficient is the forbidden most negative value.
+	minValue := append([]byte{}, ct...)
+	minValue[2], minValue[3] = 0x80, minValue[3]&0x0F
+	check("most negative coefficient", msg, minValue)
+	for _, pos := range []int{0, 1, 2, len(ct) / 2, len(ct) - 1} {
+		for _, bit := range []byte{0x01, 0x80} {
+			bad := append([]byte{}, ct...)
+			bad[pos] ^= bit
+			check(fmt.Sprintf("flip %d/%#x", pos, bit), msg, bad)
+		}
+	}
+}
+
+// TestVerifyCompressed_RejectsMalformed covers inputs rejected before any
+// arithmetic.
+func TestVerifyCompressed_RejectsMalformed(t *testing.T) {
+	kp, err := GenerateKeyPair(testSeed([]byte("pure-go malformed")))
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	sig, err := kp.Sign([]byte("msg"))
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if verifyCompressed(kp.PublicKey[:10], sig, []byte("msg")) == nil {
+		t.Error("short public key accepted")
+	}
+	badPK := kp.PublicKey
+	badPK[0] = 0x09
+	if verifyCompressed(badPK[:], sig, []byte("msg")) == nil {
+		t.Error("public key with wrong header accepted")
+	}
+	if verifyCompressed(kp.PublicKey[:], sig[:1], []byte("msg")) == nil {
+		t.Error("one-byte signature accepted")
+	}
+	if verifyCompressed(kp.PublicKey[:], make([]byte, MaxCompressedSignatureSize+1), []byte("msg")) == nil {
+		t.Error("oversized signature accepted")
+	}
+}
+
+// BenchmarkVerifyCompressed measures the pure-Go verifier.
+func BenchmarkVerifyCompressed(b *testing.B) {
+	kp, err := GenerateKeyPair(testSeed([]byte("pure-go verify bench")))
+	if err != nil {
+		b.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	msg := []byte("benchmark message")
+	sig, err := kp.Sign(msg)
+	if err != nil {
+		b.Fatalf("Sign failed: %v", err)
+	}
+	for b.Loop() {
+		if err := verifyCompressed(kp.PublicKey[:], sig, msg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+// Package verifyonly verifies deterministic FALCON-1024 signatures in pure Go.
+//
+// It is for services that only verify: unlike falcongo, it never links the C
+// Falcon implementation, so it builds without cgo and does not pull key
+// generation or signing into the binary. Keys and signatures use the same
+// encodings as falcongo, and a signature accepted here is accepted by
+// falcongo.Verify and falcongo.VerifyStrict.
+package verifyonly
+
+import "github.com/algorandfoundation/falcon-signatures/falcongo/internal/det1024"
+
+// Encoded sizes, matching falcongo.
+const (
+	PublicKeySize              = det1024.PublicKeySize
+	MaxCompressedSignatureSize = det1024.SignatureMaxSize
+	CTSignatureSize            = det1024.CTSignatureSize
+)
+
+// PublicKey is an encoded FALCON-1024 public key.
+type PublicKey [PublicKeySize]byte
+
+// ErrVerify is returned for signatures that do not verify.
+var ErrVerify = det1024.ErrVerify
+
+// Verify verifies a deterministic compressed signature of data, as produced by
+// falcongo's KeyPair.Sign.
+func Verify(data []byte, sig []byte, pk PublicKey) error {
+	return det1024.VerifyCompressed(pk[:], sig, data)
+}
+
+// VerifyCT verifies a deterministic fixed-length (CT) signature of data, as
+// produced by falcongo.GetFixedLengthSignature.
+func VerifyCT(data []byte, sig []byte, pk PublicKey) error {
+	return det1024.VerifyCT(pk[:], sig, data)
+}
@@ -0,0 +1,51 @@
+package verifyonly
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+)
+
+// TestVerify_KAT checks the fixed signatures shared with falcongo.
+func TestVerify_KAT(t *testing.T) {
+	data, err := os.ReadFile("../testdata/verify_kat.json")
+	if err != nil {
+		t.Fatalf("failed to read verify KAT: %v", err)
+	}
+	var kats []struct {
+		PublicKey   string `json:"public_key"`
+		Message     string `json:"message"`
+		Signature   string `json:"signature"`
+		SignatureCT string `json:"signature_ct"`
+	}
+	if err := json.Unmarshal(data, &kats); err != nil {
+		t.Fatalf("failed to parse verify KAT: %v", err)
+	}
+	for i, kat := range kats {
+		pkBytes, err1 := hex.DecodeString(kat.PublicKey)
+		msg, err2 := hex.DecodeString(kat.Message)
+		sig, err3 := hex.DecodeString(kat.Signature)
+		sigCT, err4 := hex.DecodeString(kat.SignatureCT)
+		if err1 != nil || err2 != nil || err3 != nil || err4 != nil || len(pkBytes) != PublicKeySize {
+			t.Fatalf("case %d: malformed KAT entry", i)
+		}
+		var pk PublicKey
+		copy(pk[:], pkBytes)
+		bad := append(append([]byte{}, msg...), 0)
+
+		if err := Verify(msg, sig, pk); err != nil {
+			t.Fatalf("case %d: Verify failed: %v", i, err)
+		}
+		if err := Verify(bad, sig, pk); !errors.Is(err, ErrVerify) {
+			t.Fatalf("case %d: Verify of a different message: got %v, want ErrVerify", i, err)
+		}
+		if err := VerifyCT(msg, sigCT, pk); err != nil {
+			t.Fatalf("case %d: VerifyCT failed: %v", i, err)
+		}
+		if err := VerifyCT(msg, sig, pk); err == nil {
+			t.Fatalf("case %d: VerifyCT accepted a compressed signature", i)
+		}
+	}
+}
@@ -7,13 +7,15 @@ require github.com/algorand/falcon v0.1.0
 require (
 	filippo.io/edwards25519 v1.2.0
 	github.com/algorand/go-algorand-sdk/v2 v2.11.1
+	github.com/algorand/go-codec/codec v1.1.10
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
 	golang.org/x/crypto v0.53.0
+	golang.org/x/sys v0.46.0
 	golang.org/x/text v0.38.0
 )
 
 require (
 	github.com/algorand/avm-abi v0.2.0 // indirect
-	github.com/algorand/go-codec/codec v1.1.10 // indirect
 	github.com/google/go-cmp v0.7.0 // indirect
 	github.com/google/go-querystring v1.1.0 // indirect
 	github.com/stretchr/testify v1.10.0 // indirect
@@ -0,0 +1,120 @@
+// Package hashing names the hash functions used by the formats of this module,
+// so that a format can record the algorithm of its digests and a verifier can
+// recompute them with the recorded one. Adding an algorithm here makes it
+// available to every format that records one.
+//
+// New digests use Default, SHA-512/256. SHA-256 is kept for the formats that
+// predate algorithm agility and cannot change without breaking existing
+// identifiers: key fingerprints, published key notes, version 1 attestation
+// bundles and version 1 environment statements.
+package hashing
+
+import (
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// Algorithm is a hash function, named as recorded in metadata.
+type Algorithm string
+
+const (
+	SHA512_256 Algorithm = "sha512-256"
+	SHA3_256   Algorithm = "sha3-256"
+	BLAKE2b256 Algorithm = "blake2b-256"
+	// SHA256 is the algorithm of the formats that do not record one. It
+	// cannot be selected for new digests.
+	SHA256 Algorithm = "sha256"
+)
+
+// Default is the algorithm of new digests.
+const Default = SHA512_256
+
+// ErrUnknownAlgorithm is returned by Parse for a name it does not know.
+var ErrUnknownAlgorithm = errors.New("unknown hash algorithm")
+
+// Algorithms returns the algorithms that can be selected for new digests,
+// Default first.
+func Algorithms() []Algorithm {
+	return []Algorithm{SHA512_256, SHA3_256, BLAKE2b256}
+}
+
+// Parse returns the algorithm named name, as recorded in metadata; this
+// includes SHA256, which only verifiers of older formats need.
+func Parse(name string) (Algorithm, error) {
+	a := Algorithm(strings.ToLower(strings.TrimSpace(name)))
+	if a == SHA256 || a.selectable() {
+		return a, nil
+	}
+	return "", fmt.Errorf("%w %q (supported: %s)", ErrUnknownAlgorithm, name, names())
+}
+
+// ParseSelectable is Parse for the choice of the algorithm of new digests,
+// as given by a user: SHA256 is refused.
+func ParseSelectable(name string) (Algorithm, error) {
+	a, err := Parse(name)
+	if err == nil && !a.selectable() {
+		err = fmt.Errorf("%w %q for new digests (supported: %s)", ErrUnknownAlgorithm, name, names())
+	}
+	return a, err
+}
+
+func (a Algorithm) selectable() bool {
+	for _, s := range Algorithms() {
+		if a == s {
+			return true
+		}
+	}
+	return false
+}
+
+func names() string {
+	var s []string
+	for _, a := range Algorithms() {
+		s = append(s, string(a))
+	}
+	return strings.Join(s, ", ")
+}
+
+// String returns the name of a.
+func (a Algorithm) String() string {
+	return string(a)
+}
+
+// Size returns the size of the digests of a in bytes: 32 for all of them.
+func (a Algorithm) Size() int {
+	return a.New().Size()
+}
+
+// New returns a hash.Hash computing a. It panics if a is not an algorithm
+// returned by Parse, like crypto.Hash.New for an unavailable hash.
+func (a Algorithm) New() hash.Hash {
+	switch a {
+	case SHA512_256:
+		return sha512.New512_256()
+	case SHA3_256:
+		return sha3.New256()
+	case BLAKE2b256:
+		h, err := blake2b.New256(nil)
+		if err != nil {
+			panic(err) // only for a key longer than 64 bytes
+		}
+		return h
+	case SHA256:
+		return sha256.New()
+	}
+	panic(fmt.Sprintf("hashing: unknown algorithm %q", string(a)))
+}
+
+// Sum returns the digest of data under a.
+func (a Algorithm) Sum(data []byte) []byte {
+	h := a.New()
+	h.Write(data)
+	return h.Sum(nil)
+}
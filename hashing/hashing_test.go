@@ -0,0 +1,45 @@
+package hashing
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+// TestSum checks each algorithm against a known digest of "abc".
+func TestSum(t *testing.T) {
+	for a, want := range map[Algorithm]string{
+		SHA512_256: "53048e2681941ef99b2e29b76b4c7dabe4c2d0c634fc6d46e0e2f13107e7af23",
+		SHA3_256:   "3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532",
+		BLAKE2b256: "bddd813c634239723171ef3fee98579b94964e3bb1cb3e427262c8c068d52319",
+		SHA256:     "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+	} {
+		if got := hex.EncodeToString(a.Sum([]byte("abc"))); got != want {
+			t.Fatalf("%s: got %s, want %s", a, got, want)
+		}
+		if a.Size() != 32 {
+			t.Fatalf("%s: size %d", a, a.Size())
+		}
+	}
+}
+
+// TestParse checks that SHA-256 is recognized but not selectable.
+func TestParse(t *testing.T) {
+	for _, a := range Algorithms() {
+		if got, err := ParseSelectable(" " + string(a) + " "); err != nil || got != a {
+			t.Fatalf("ParseSelectable(%s) = %s, %v", a, got, err)
+		}
+	}
+	if a, err := Parse("SHA256"); err != nil || a != SHA256 {
+		t.Fatalf("Parse(SHA256) = %s, %v", a, err)
+	}
+	if _, err := ParseSelectable("sha256"); !errors.Is(err, ErrUnknownAlgorithm) {
+		t.Fatalf("expected sha256 to be refused for new digests, got %v", err)
+	}
+	if _, err := Parse("md5"); !errors.Is(err, ErrUnknownAlgorithm) {
+		t.Fatalf("expected md5 to be unknown, got %v", err)
+	}
+	if Algorithms()[0] != Default {
+		t.Fatalf("Default must come first")
+	}
+}
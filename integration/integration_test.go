@@ -4,10 +4,12 @@ package integration
 
 import (
 	"bytes"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/algorandfoundation/falcon-signatures/algorand"
@@ -149,3 +151,40 @@ func TestPQLogicSigDerivationWithPrecompile(t *testing.T) {
 		}
 	}
 }
+
+// TestRecoveryLogicSigMatchesTemplate tests that the program assembled by
+// DeriveRecoveryLogicSig is what algod compiles from RecoverylogicsigTMPL.teal.
+func TestRecoveryLogicSigMatchesTemplate(t *testing.T) {
+	t.Parallel()
+	tmpl, err := os.ReadFile(filepath.Join("..", "algorand", "teal", "RecoverylogicsigTMPL.teal"))
+	if err != nil {
+		t.Fatalf("failed to read recovery template: %v", err)
+	}
+	primary, err := falcongo.GenerateKeyPair(nil)
+	if err != nil {
+		t.Fatalf("failed to generate Falcon keypair: %v", err)
+	}
+	backup, err := falcongo.GenerateKeyPair(nil)
+	if err != nil {
+		t.Fatalf("failed to generate Falcon keypair: %v", err)
+	}
+	for _, afterRound := range []uint64{1, 127, 128, 50_000_000} {
+		lsig, err := algorand.DeriveRecoveryLogicSig(primary.PublicKey, backup.PublicKey, afterRound)
+		if err != nil {
+			t.Fatalf("DeriveRecoveryLogicSig failed: %v", err)
+		}
+		teal := strings.NewReplacer(
+			"TMPL_COUNTER", fmt.Sprintf("0x%02x", lsig.Lsig.Logic[4]),
+			"TMPL_PRIMARY_FALCON_PUBLIC_KEY", "0x"+hex.EncodeToString(primary.PublicKey[:]),
+			"TMPL_BACKUP_FALCON_PUBLIC_KEY", "0x"+hex.EncodeToString(backup.PublicKey[:]),
+			"TMPL_AFTER_ROUND", fmt.Sprint(afterRound),
+		).Replace(string(tmpl))
+		compiled, err := algorand.CompileLogicSig(teal)
+		if err != nil {
+			t.Fatalf("failed to compile recovery teal: %v", err)
+		}
+		if !bytes.Equal(compiled.Lsig.Logic, lsig.Lsig.Logic) {
+			t.Fatalf("after round %d: compiled bytes do not match assembled bytes", afterRound)
+		}
+	}
+}
@@ -187,3 +187,191 @@ func TestSeedFromMnemonicNormalization(t *testing.T) {
 		t.Fatalf("normalized seeds differ for equivalent passphrases:\n% x\n% x", seed1[:], seed2[:])
 	}
 }
+
+// TestEnumeratePartial_FindsOriginal checks enumeration yields the original
+// phrase and only checksum-valid candidates.
+func TestEnumeratePartial_FindsOriginal(t *testing.T) {
+	original := strings.Fields("legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth title")
+	pattern := append([]string(nil), original...)
+	pattern[3] = UnknownWord
+
+	total, err := PartialCandidateCount(pattern)
+	if err != nil {
+		t.Fatalf("PartialCandidateCount returned error: %v", err)
+	}
+	if total != 2048 {
+		t.Fatalf("expected 2048 combinations, got %d", total)
+	}
+
+	found := false
+	count := 0
+	err = EnumeratePartial(pattern, 0, func(_ uint64, phrase []string) bool {
+		count++
+		if _, err := MnemonicToEntropy(phrase); err != nil {
+			t.Fatalf("enumerated phrase fails checksum: %v", err)
+		}
+		if reflect.DeepEqual(phrase, original) {
+			found = true
+		}
+		return true
+	})
+	if err != nil {
+		t.Fatalf("EnumeratePartial returned error: %v", err)
+	}
+	if !found {
+		t.Fatalf("original phrase was not enumerated")
+	}
+	if count == 0 || count >= 2048 {
+		t.Fatalf("expected checksum filtering, got %d candidates", count)
+	}
+}
+
+// TestEnumeratePartial_Resume ensures enumeration restarts at the given index.
+func TestEnumeratePartial_Resume(t *testing.T) {
+	pattern := strings.Fields("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon ?")
+
+	var all []uint64
+	if err := EnumeratePartial(pattern, 0, func(i uint64, _ []string) bool {
+		all = append(all, i)
+		return true
+	}); err != nil {
+		t.Fatalf("EnumeratePartial returned error: %v", err)
+	}
+	if len(all) < 2 {
+		t.Fatalf("expected several candidates, got %d", len(all))
+	}
+
+	var resumed []uint64
+	if err := EnumeratePartial(pattern, all[1], func(i uint64, _ []string) bool {
+		resumed = append(resumed, i)
+		return true
+	}); err != nil {
+		t.Fatalf("EnumeratePartial returned error: %v", err)
+	}
+	if !reflect.DeepEqual(resumed, all[1:]) {
+		t.Fatalf("resume mismatch\nexpected: %v\n     got: %v", all[1:], resumed)
+	}
+}
+
+// TestEnumeratePartial_InvalidPatterns covers pattern validation errors.
+func TestEnumeratePartial_InvalidPatterns(t *testing.T) {
+	base := strings.Fields("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art")
+	tooMany := append([]string(nil), base...)
+	for i := 0; i <= MaxUnknownWords; i++ {
+		tooMany[i] = UnknownWord
+	}
+	badWord := append([]string(nil), base...)
+	badWord[0], badWord[1] = "notaword", UnknownWord
+
+	testCases := []struct {
+		name    string
+		pattern []string
+	}{
+		{"no unknown words", base},
+		{"too many unknown words", tooMany},
+		{"wrong length", base[:12]},
+		{"invalid word", badWord},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := EnumeratePartial(tc.pattern, 0, func(uint64, []string) bool { return true })
+			if err == nil {
+				t.Fatalf("expected error for %s", tc.name)
+			}
+		})
+	}
+}
+
+func TestEstimatePassphraseStrength(t *testing.T) {
+	for _, tc := range []struct {
+		passphrase string
+		maxScore   int
+		minScore   int
+		warning    string
+	}{
+		{"", 0, 0, "empty passphrase"},
+		{"x", 0, 0, "short passphrase"},
+		{"Password1", 0, 0, "common password"},
+		{"aaaaaaaaaaaa", 0, 0, "repeated characters"},
+		{"abcdefgh", 0, 0, "sequences like abc or 123"},
+		{"qwertyuiop", 0, 0, "keyboard patterns"},
+		{"1987", 0, 0, "years are easy to guess"},
+		{"abandon ability", 1, 1, "dictionary words are easy to guess"},
+		{"kX9#mQ2$vL7!pR4@", 4, 4, ""},
+	} {
+		st := EstimatePassphraseStrength(tc.passphrase)
+		if st.Score < tc.minScore || st.Score > tc.maxScore || st.Warning != tc.warning {
+			t.Errorf("%q: got score %d (%.1f bits) warning %q, want score %d-%d warning %q",
+				tc.passphrase, st.Score, st.Bits, st.Warning, tc.minScore, tc.maxScore, tc.warning)
+		}
+	}
+	// Appending to a passphrase never lowers its estimate.
+	if a, b := EstimatePassphraseStrength("Tr0ub4dor&3"), EstimatePassphraseStrength("Tr0ub4dor&3 1987"); b.Bits < a.Bits {
+		t.Fatalf("appending a year lowered the estimate: %.1f < %.1f", b.Bits, a.Bits)
+	}
+}
+
+// TestSearchEntropy checks the search returns the first matching entropy and
+// gives up after maxAttempts.
+func TestSearchEntropy(t *testing.T) {
+	r := bytes.NewReader(bytes.Repeat([]byte{0x5a}, 32*1000))
+	entropy, attempts, err := SearchEntropy(r, 1000, func(phrase []string) bool {
+		return false
+	})
+	if err != ErrSearchExhausted || entropy != nil || attempts != 1000 {
+		t.Fatalf("expected exhaustion, got %x, %d, %v", entropy, attempts, err)
+	}
+
+	// Entropies 0 and 1 in the last byte, then all 0xff, whose final word
+	// differs from theirs.
+	var stream []byte
+	for i := range 2 {
+		e := make([]byte, 32)
+		e[31] = byte(i)
+		stream = append(stream, e...)
+	}
+	want := bytes.Repeat([]byte{0xff}, 32)
+	stream = append(stream, want...)
+	phrase, err := EntropyToMnemonic(want)
+	if err != nil {
+		t.Fatalf("EntropyToMnemonic failed: %v", err)
+	}
+	match, err := FinalWordMatcher(phrase[len(phrase)-1])
+	if err != nil {
+		t.Fatalf("FinalWordMatcher failed: %v", err)
+	}
+	entropy, attempts, err = SearchEntropy(bytes.NewReader(stream), 10, match)
+	if err != nil || attempts != 3 || !bytes.Equal(entropy, want) {
+		t.Fatalf("got %x, %d, %v", entropy, attempts, err)
+	}
+
+	if _, _, err := SearchEntropy(bytes.NewReader(nil), 10, match); err == nil || err == ErrSearchExhausted {
+		t.Fatalf("expected a read error, got %v", err)
+	}
+	if _, err := FinalWordMatcher("notaword"); err == nil {
+		t.Fatalf("expected an error for a word outside the list")
+	}
+}
+
+// TestPetname checks that a petname is the first words of the mnemonic of the
+// fingerprint bits.
+func TestPetname(t *testing.T) {
+	var fp [32]byte
+	if got := Petname(fp); got != "abandon-abandon-abandon" {
+		t.Fatalf("Petname(zero) = %q", got)
+	}
+	for i := range fp {
+		fp[i] = byte(i*37 + 11)
+	}
+	phrase, err := EntropyToMnemonic(fp[:])
+	if err != nil {
+		t.Fatalf("EntropyToMnemonic failed: %v", err)
+	}
+	if got, want := Petname(fp), strings.Join(phrase[:PetnameWords], "-"); got != want {
+		t.Fatalf("Petname = %q, want %q", got, want)
+	}
+	fp[4] ^= 0x80
+	if Petname(fp) == strings.Join(phrase[:PetnameWords], "-") {
+		t.Fatalf("Petname ignores bit 33 of the fingerprint")
+	}
+}
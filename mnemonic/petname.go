@@ -0,0 +1,23 @@
+package mnemonic
+
+import "strings"
+
+// PetnameWords is the number of words of a petname.
+const PetnameWords = 3
+
+// Petname returns a human-memorable name for a key fingerprint: the words of
+// the BIP-39 English list indexed by the first 33 bits of fp, 11 bits per
+// word, joined by '-'. A petname lets users recognize a key at a glance; with
+// 33 bits, different keys can share one, so it does not replace comparing
+// fingerprints when that matters.
+func Petname(fp [32]byte) string {
+	acc := uint64(fp[0])<<32 | uint64(fp[1])<<24 | uint64(fp[2])<<16 | uint64(fp[3])<<8 | uint64(fp[4])
+	// acc holds 40 bits; keep the first bitsPerWord*PetnameWords.
+	acc >>= 40 - bitsPerWord*PetnameWords
+	out := make([]string, PetnameWords)
+	for i := PetnameWords - 1; i >= 0; i-- {
+		out[i] = words[acc&(1<<bitsPerWord-1)]
+		acc >>= bitsPerWord
+	}
+	return strings.Join(out, "-")
+}
@@ -0,0 +1,88 @@
+package mnemonic
+
+import "fmt"
+
+const (
+	// UnknownWord marks a missing word in a partial mnemonic.
+	UnknownWord = "?"
+	// MaxUnknownWords bounds how many missing words a partial mnemonic may have.
+	MaxUnknownWords = 3
+)
+
+// PartialCandidateCount returns the number of word combinations that
+// EnumeratePartial walks for the given partial mnemonic (2048^unknowns).
+func PartialCandidateCount(pattern []string) (uint64, error) {
+	unknown, err := unknownPositions(pattern)
+	if err != nil {
+		return 0, err
+	}
+	total := uint64(1)
+	for range unknown {
+		total *= uint64(len(words))
+	}
+	return total, nil
+}
+
+// EnumeratePartial fills the UnknownWord positions of a 24-word partial mnemonic
+// with every BIP-39 word combination, in a fixed order, and calls fn for each
+// combination that passes the checksum.
+//
+// Combinations are numbered from 0 to PartialCandidateCount-1; enumeration begins
+// at index start so an interrupted search can be resumed. fn receives the index
+// and the candidate phrase (reused between calls; copy it to retain it) and
+// returns false to stop the enumeration.
+func EnumeratePartial(pattern []string, start uint64,
+	fn func(index uint64, phrase []string) bool) error {
+	unknown, err := unknownPositions(pattern)
+	if err != nil {
+		return err
+	}
+	total, err := PartialCandidateCount(pattern)
+	if err != nil {
+		return err
+	}
+
+	phrase := make([]string, len(pattern))
+	copy(phrase, pattern)
+	for index := start; index < total; index++ {
+		rest := index
+		for i := len(unknown) - 1; i >= 0; i-- {
+			phrase[unknown[i]] = words[rest%uint64(len(words))]
+			rest /= uint64(len(words))
+		}
+		if _, err := MnemonicToEntropy(phrase); err != nil {
+			continue
+		}
+		if !fn(index, phrase) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// unknownPositions validates a partial mnemonic and returns the indexes of its
+// UnknownWord entries.
+func unknownPositions(pattern []string) ([]int, error) {
+	if len(pattern) != mnemonicWordSize {
+		return nil, fmt.Errorf("mnemonic: phrase must contain %d words",
+			mnemonicWordSize)
+	}
+	var unknown []int
+	for i, word := range pattern {
+		if word == UnknownWord {
+			unknown = append(unknown, i)
+			continue
+		}
+		if _, ok := wordToIndex[word]; !ok {
+			return nil, fmt.Errorf("mnemonic: word %q is not in the BIP-39 list", word)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil, fmt.Errorf("mnemonic: phrase has no unknown words (use %q)", UnknownWord)
+	}
+	if len(unknown) > MaxUnknownWords {
+		return nil, fmt.Errorf("mnemonic: at most %d unknown words supported (got %d)",
+			MaxUnknownWords, len(unknown))
+	}
+	return unknown, nil
+}
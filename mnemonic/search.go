@@ -0,0 +1,47 @@
+package mnemonic
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrSearchExhausted is returned by SearchEntropy when no mnemonic matched
+// within the allowed number of attempts.
+var ErrSearchExhausted = errors.New("mnemonic: no matching mnemonic within the allowed attempts")
+
+// SearchEntropy reads 32-byte entropy values from r until the mnemonic of one
+// satisfies match, and returns that entropy and the number of attempts made.
+// It gives up with ErrSearchExhausted after maxAttempts attempts.
+//
+// Every constraint on the mnemonic discards entropy: a fixed final word keeps
+// about 1 in 2048 phrases, so the result has about 11 bits less entropy than
+// a phrase drawn at random.
+func SearchEntropy(r io.Reader, maxAttempts uint64,
+	match func(phrase []string) bool) (entropy []byte, attempts uint64, err error) {
+	entropy = make([]byte, entropyLength)
+	for attempts < maxAttempts {
+		attempts++
+		if _, err := io.ReadFull(r, entropy); err != nil {
+			return nil, attempts, fmt.Errorf("mnemonic: failed to read entropy: %w", err)
+		}
+		phrase, err := EntropyToMnemonic(entropy)
+		if err != nil {
+			return nil, attempts, err
+		}
+		if match(phrase) {
+			return entropy, attempts, nil
+		}
+	}
+	zero(entropy)
+	return nil, attempts, ErrSearchExhausted
+}
+
+// FinalWordMatcher returns a SearchEntropy match function accepting phrases
+// that end with word, which must be in the BIP-39 list.
+func FinalWordMatcher(word string) (func(phrase []string) bool, error) {
+	if _, ok := wordToIndex[word]; !ok {
+		return nil, fmt.Errorf("mnemonic: word %q is not in the BIP-39 list", word)
+	}
+	return func(phrase []string) bool { return phrase[len(phrase)-1] == word }, nil
+}
@@ -0,0 +1,214 @@
+package mnemonic
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// PassphraseStrength is an estimate of how hard a mnemonic passphrase is to
+// guess.
+type PassphraseStrength struct {
+	// Bits is log2 of the estimated number of guesses.
+	Bits float64
+	// Score rates Bits from 0 (trivial) to 4 (strong).
+	Score int
+	// Warning names the main weakness of a passphrase scoring below 3.
+	Warning string
+}
+
+// Score thresholds, in bits, of EstimatePassphraseStrength. A passphrase
+// guards the keys of a leaked mnemonic against offline guessing, so the scale
+// is stricter than one for online passwords.
+var scoreBits = [...]float64{20, 35, 50, 65}
+
+// commonPassphrases are frequent passwords, most common first.
+var commonPassphrases = []string{
+	"password", "123456", "12345678", "qwerty", "abc123", "111111", "letmein",
+	"monkey", "dragon", "iloveyou", "admin", "welcome", "secret", "master",
+	"login", "princess", "sunshine", "football", "baseball", "shadow",
+	"passw0rd", "trustno1", "superman", "batman", "starwars", "hello",
+	"freedom", "whatever", "bitcoin", "crypto", "satoshi", "algorand",
+	"falcon", "trezor", "ledger", "wallet", "changeme", "test", "pass",
+}
+
+var keyboardRows = []string{
+	"`1234567890-=", "qwertyuiop[]\\", "asdfghjkl;'", "zxcvbnm,./",
+	"qwertzuiop", "asdfghjkl", "yxcvbnm", "azertyuiop", "qsdfghjklm", "wxcvbn",
+}
+
+// EstimatePassphraseStrength estimates the strength of a mnemonic passphrase
+// in the spirit of zxcvbn: the passphrase is split into the cheapest sequence
+// of patterns (common passwords, BIP-39 words, repeats, sequences, keyboard
+// runs, years, or single characters guessed from the character classes used)
+// and the guesses of the parts are multiplied. It is an upper bound for
+// passphrases a human picked, not a guarantee.
+func EstimatePassphraseStrength(passphrase string) PassphraseStrength {
+	s := []rune(passphrase)
+	if len(s) == 0 {
+		return PassphraseStrength{Warning: "empty passphrase"}
+	}
+	lower := []rune(strings.ToLower(passphrase))
+	if len(lower) != len(s) {
+		lower = s
+	}
+	charBits := math.Log2(float64(charPool(s)))
+
+	// best[i] is the cheapest cover of s[:i]; warn[i] the weakness it uses.
+	best := make([]float64, len(s)+1)
+	warn := make([]string, len(s)+1)
+	for j := 1; j <= len(s); j++ {
+		best[j] = best[j-1] + charBits
+		warn[j] = warn[j-1]
+		for _, m := range patternsEndingAt(s, lower, j) {
+			if c := best[m.start] + m.bits; c < best[j] {
+				best[j] = c
+				warn[j] = m.warning
+				if warn[m.start] != "" {
+					warn[j] = warn[m.start]
+				}
+			}
+		}
+	}
+
+	st := PassphraseStrength{Bits: best[len(s)], Warning: warn[len(s)]}
+	for st.Score < len(scoreBits) && st.Bits >= scoreBits[st.Score] {
+		st.Score++
+	}
+	switch {
+	case st.Score >= 3:
+		st.Warning = ""
+	case st.Warning == "" && st.Score < 2:
+		st.Warning = "short passphrase"
+	}
+	return st
+}
+
+type passphraseMatch struct {
+	start   int
+	bits    float64
+	warning string
+}
+
+// patternsEndingAt returns the patterns matching s[start:end] for any start.
+func patternsEndingAt(s, lower []rune, end int) []passphraseMatch {
+	var ms []passphraseMatch
+	for start := end - 1; start >= 0; start-- {
+		n := end - start
+		seg := string(lower[start:end])
+		caseBits := 0.0
+		if string(s[start:end]) != seg {
+			caseBits = 1
+		}
+		for rank, p := range commonPassphrases {
+			if seg == p {
+				ms = append(ms, passphraseMatch{start, math.Log2(float64(rank+2)) + caseBits,
+					"common password"})
+			}
+		}
+		if _, ok := wordToIndex[seg]; ok {
+			ms = append(ms, passphraseMatch{start, bitsPerWord + caseBits,
+				"dictionary words are easy to guess"})
+		}
+		if n < 3 {
+			continue
+		}
+		if isRepeat(lower[start:end]) {
+			ms = append(ms, passphraseMatch{start, math.Log2(float64(charPool(s[start:start+1]))) +
+				math.Log2(float64(n)), "repeated characters"})
+		}
+		if isSequence(lower[start:end]) {
+			ms = append(ms, passphraseMatch{start, math.Log2(26) + 1 + math.Log2(float64(n)),
+				"sequences like abc or 123"})
+		}
+		if isKeyboardRun(seg) {
+			ms = append(ms, passphraseMatch{start, math.Log2(float64(len(keyboardRows)*10)) + 1 +
+				math.Log2(float64(n)), "keyboard patterns"})
+		}
+		if n == 4 && (strings.HasPrefix(seg, "19") || strings.HasPrefix(seg, "20")) &&
+			isDigits(seg) {
+			ms = append(ms, passphraseMatch{start, math.Log2(200), "years are easy to guess"})
+		}
+	}
+	return ms
+}
+
+// charPool returns the size of the character classes used by s.
+func charPool(s []rune) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < 0x80:
+			symbol = true
+		default:
+			other = true
+		}
+	}
+	pool := 0
+	for _, c := range []struct {
+		used bool
+		size int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if c.used {
+			pool += c.size
+		}
+	}
+	return pool
+}
+
+func isRepeat(s []rune) bool {
+	for _, r := range s[1:] {
+		if r != s[0] {
+			return false
+		}
+	}
+	return true
+}
+
+// isSequence reports whether s steps by a constant +1 or -1 through letters or
+// digits, like "abcd" or "9876".
+func isSequence(s []rune) bool {
+	d := s[1] - s[0]
+	if d != 1 && d != -1 {
+		return false
+	}
+	for i := range s {
+		if !unicode.IsLetter(s[i]) && !unicode.IsDigit(s[i]) {
+			return false
+		}
+		if i > 0 && s[i]-s[i-1] != d {
+			return false
+		}
+	}
+	return true
+}
+
+// isKeyboardRun reports whether s is a run of adjacent keys on one keyboard
+// row, forwards or backwards.
+func isKeyboardRun(s string) bool {
+	rev := []rune(s)
+	for i, j := 0, len(rev)-1; i < j; i, j = i+1, j-1 {
+		rev[i], rev[j] = rev[j], rev[i]
+	}
+	for _, row := range keyboardRows {
+		if strings.Contains(row, s) || strings.Contains(row, string(rev)) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,151 @@
+// Package mobile exposes FALCON-1024 key generation from a mnemonic, signing,
+// verification, ARC-60 authentication requests and Algorand PQ address
+// derivation with signatures that
+// gomobile can bind (byte slices, strings, bools and errors only), so iOS and
+// Android wallets can embed the reference implementation, including the
+// logicsig patching used to derive addresses, instead of reimplementing it.
+//
+// Build the bindings with, for example:
+//
+//	gomobile bind -target=android github.com/algorandfoundation/falcon-signatures/mobile
+//	gomobile bind -target=ios github.com/algorandfoundation/falcon-signatures/mobile
+//
+// Errors surface as exceptions (Java/Kotlin) or NSError (Swift/Objective-C)
+// whose message is the error string.
+package mobile
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/mnemonic"
+)
+
+// KeyPair is a FALCON-1024 keypair in its raw encodings: a 1793-byte public
+// key and a 2305-byte private key.
+type KeyPair struct {
+	PublicKey  []byte
+	PrivateKey []byte
+}
+
+// KeyPairFromMnemonic derives the keypair of a 24-word BIP-39 mnemonic
+// (words separated by spaces) and optional passphrase, exactly as
+// `falcon create --from-mnemonic` does.
+func KeyPairFromMnemonic(phrase string, passphrase string) (*KeyPair, error) {
+	seed, err := mnemonic.SeedFromMnemonic(strings.Fields(phrase), passphrase)
+	if err != nil {
+		return nil, err
+	}
+	kp, err := falcongo.GenerateKeyPair(seed[:])
+	clear(seed[:])
+	if err != nil {
+		return nil, err
+	}
+	return &KeyPair{
+		PublicKey:  append([]byte(nil), kp.PublicKey[:]...),
+		PrivateKey: append([]byte(nil), kp.PrivateKey[:]...),
+	}, nil
+}
+
+// Sign signs message with the private key and returns the compressed
+// signature. Signing is deterministic.
+func Sign(privateKey []byte, message []byte) ([]byte, error) {
+	var kp falcongo.KeyPair
+	if len(privateKey) != len(kp.PrivateKey) {
+		return nil, fmt.Errorf("private key must be %d bytes, got %d",
+			len(kp.PrivateKey), len(privateKey))
+	}
+	copy(kp.PrivateKey[:], privateKey)
+	defer clear(kp.PrivateKey[:])
+	return kp.Sign(message)
+}
+
+// Verify reports whether signature is a valid compressed signature of message
+// under the public key. It returns an error only for a malformed public key.
+func Verify(publicKey []byte, message []byte, signature []byte) (bool, error) {
+	pk, err := publicKeyFromBytes(publicKey)
+	if err != nil {
+		return false, err
+	}
+	return falcongo.Verify(message, signature, pk) == nil, nil
+}
+
+// SignAuthData signs an ARC-60 authentication request from a dApp: the
+// client data JSON, the domain asking and the authenticator data, which must
+// start with the SHA-256 of the domain. It refuses requests a wallet must not
+// sign.
+func SignAuthData(privateKey []byte, data []byte, domain string, authenticatorData []byte) ([]byte, error) {
+	var kp falcongo.KeyPair
+	if len(privateKey) != len(kp.PrivateKey) {
+		return nil, fmt.Errorf("private key must be %d bytes, got %d",
+			len(kp.PrivateKey), len(privateKey))
+	}
+	copy(kp.PrivateKey[:], privateKey)
+	defer clear(kp.PrivateKey[:])
+	return kp.SignData(authDataRequest(data, domain, authenticatorData))
+}
+
+// VerifyAuthData reports whether signature is a valid SignAuthData signature
+// of the request under the public key. It returns an error for a malformed
+// public key or a request SignAuthData refuses.
+func VerifyAuthData(publicKey []byte, data []byte, domain string, authenticatorData []byte,
+	signature []byte) (bool, error) {
+	pk, err := publicKeyFromBytes(publicKey)
+	if err != nil {
+		return false, err
+	}
+	req := authDataRequest(data, domain, authenticatorData)
+	if _, err := req.Payload(); err != nil {
+		return false, err
+	}
+	return falcongo.VerifySignData(req, signature, pk) == nil, nil
+}
+
+func authDataRequest(data []byte, domain string, authenticatorData []byte) falcongo.SignDataRequest {
+	return falcongo.SignDataRequest{
+		Scope:             falcongo.ScopeAuth,
+		Data:              data,
+		Domain:            domain,
+		AuthenticatorData: authenticatorData,
+	}
+}
+
+// Address returns the Algorand address of the PQ account controlled by the
+// public key.
+func Address(publicKey []byte) (string, error) {
+	pk, err := publicKeyFromBytes(publicKey)
+	if err != nil {
+		return "", err
+	}
+	addr, err := algorand.GetAddressFromPublicKey(pk)
+	if err != nil {
+		return "", err
+	}
+	return string(addr), nil
+}
+
+// LogicSig returns the program bytes of the PQ logicsig of the public key, to
+// be attached, with a signature of the transaction ID as its only argument,
+// to transactions sent from Address(publicKey).
+func LogicSig(publicKey []byte) ([]byte, error) {
+	pk, err := publicKeyFromBytes(publicKey)
+	if err != nil {
+		return nil, err
+	}
+	lsig, err := algorand.DerivePQLogicSig(pk)
+	if err != nil {
+		return nil, err
+	}
+	return lsig.Lsig.Logic, nil
+}
+
+func publicKeyFromBytes(b []byte) (falcongo.PublicKey, error) {
+	var pk falcongo.PublicKey
+	if len(b) != len(pk) {
+		return pk, fmt.Errorf("public key must be %d bytes, got %d", len(pk), len(b))
+	}
+	copy(pk[:], b)
+	return pk, nil
+}
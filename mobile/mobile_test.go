@@ -0,0 +1,139 @@
+package mobile
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"strings"
+	"testing"
+
+	"github.com/algorandfoundation/falcon-signatures/algorand"
+	"github.com/algorandfoundation/falcon-signatures/falcongo"
+	"github.com/algorandfoundation/falcon-signatures/mnemonic"
+)
+
+func testMnemonic(t *testing.T) string {
+	t.Helper()
+	words, err := mnemonic.EntropyToMnemonic(bytes.Repeat([]byte{0x42}, 32))
+	if err != nil {
+		t.Fatalf("EntropyToMnemonic failed: %v", err)
+	}
+	return strings.Join(words, " ")
+}
+
+// TestKeyPairFromMnemonic_MatchesReference checks the binding derives the same
+// keypair as the mnemonic and falcongo packages.
+func TestKeyPairFromMnemonic_MatchesReference(t *testing.T) {
+	phrase := testMnemonic(t)
+	kp, err := KeyPairFromMnemonic(phrase, "TREZOR")
+	if err != nil {
+		t.Fatalf("KeyPairFromMnemonic failed: %v", err)
+	}
+	seed, err := mnemonic.SeedFromMnemonic(strings.Fields(phrase), "TREZOR")
+	if err != nil {
+		t.Fatalf("SeedFromMnemonic failed: %v", err)
+	}
+	want, err := falcongo.GenerateKeyPair(seed[:])
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	if !bytes.Equal(kp.PublicKey, want.PublicKey[:]) || !bytes.Equal(kp.PrivateKey, want.PrivateKey[:]) {
+		t.Fatal("keypair differs from reference derivation")
+	}
+
+	if _, err := KeyPairFromMnemonic("abandon abandon", ""); err == nil {
+		t.Fatal("expected error for invalid mnemonic")
+	}
+}
+
+// TestSignVerify_RoundTrip signs and verifies through the binding.
+func TestSignVerify_RoundTrip(t *testing.T) {
+	kp, err := KeyPairFromMnemonic(testMnemonic(t), "")
+	if err != nil {
+		t.Fatalf("KeyPairFromMnemonic failed: %v", err)
+	}
+	msg := []byte("mobile binding message")
+	sig, err := Sign(kp.PrivateKey, msg)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	ok, err := Verify(kp.PublicKey, msg, sig)
+	if err != nil || !ok {
+		t.Fatalf("expected valid signature, got ok=%v err=%v", ok, err)
+	}
+	ok, err = Verify(kp.PublicKey, []byte("other message"), sig)
+	if err != nil || ok {
+		t.Fatalf("expected invalid signature, got ok=%v err=%v", ok, err)
+	}
+
+	if _, err := Sign(kp.PrivateKey[:10], msg); err == nil {
+		t.Fatal("expected error for short private key")
+	}
+	if _, err := Verify(kp.PublicKey[:10], msg, sig); err == nil {
+		t.Fatal("expected error for short public key")
+	}
+}
+
+// TestSignAuthData_RoundTrip signs and verifies an ARC-60 request through the
+// binding.
+func TestSignAuthData_RoundTrip(t *testing.T) {
+	kp, err := KeyPairFromMnemonic(testMnemonic(t), "")
+	if err != nil {
+		t.Fatalf("KeyPairFromMnemonic failed: %v", err)
+	}
+	data := []byte(`{"type":"arc60.create","challenge":"abc","origin":"https://arc60.io"}`)
+	domainHash := sha256.Sum256([]byte("arc60.io"))
+	authData := append(domainHash[:], 0, 0, 0, 0, 0)
+	sig, err := SignAuthData(kp.PrivateKey, data, "arc60.io", authData)
+	if err != nil {
+		t.Fatalf("SignAuthData failed: %v", err)
+	}
+	ok, err := VerifyAuthData(kp.PublicKey, data, "arc60.io", authData, sig)
+	if err != nil || !ok {
+		t.Fatalf("expected valid signature, got ok=%v err=%v", ok, err)
+	}
+	ok, err = VerifyAuthData(kp.PublicKey, []byte(`{"type":"arc60.create"}`), "arc60.io", authData, sig)
+	if err != nil || ok {
+		t.Fatalf("expected invalid signature, got ok=%v err=%v", ok, err)
+	}
+	if _, err := SignAuthData(kp.PrivateKey, data, "evil.example", authData); err == nil {
+		t.Fatal("expected error for authenticator data of another domain")
+	}
+}
+
+// TestAddress_MatchesAlgorandPackage checks address and logicsig derivation.
+func TestAddress_MatchesAlgorandPackage(t *testing.T) {
+	kp, err := KeyPairFromMnemonic(testMnemonic(t), "")
+	if err != nil {
+		t.Fatalf("KeyPairFromMnemonic failed: %v", err)
+	}
+	var pk falcongo.PublicKey
+	copy(pk[:], kp.PublicKey)
+
+	addr, err := Address(kp.PublicKey)
+	if err != nil {
+		t.Fatalf("Address failed: %v", err)
+	}
+	want, err := algorand.GetAddressFromPublicKey(pk)
+	if err != nil {
+		t.Fatalf("GetAddressFromPublicKey failed: %v", err)
+	}
+	if addr != string(want) {
+		t.Fatalf("address mismatch: got %s want %s", addr, want)
+	}
+
+	logic, err := LogicSig(kp.PublicKey)
+	if err != nil {
+		t.Fatalf("LogicSig failed: %v", err)
+	}
+	lsig, err := algorand.DerivePQLogicSig(pk)
+	if err != nil {
+		t.Fatalf("DerivePQLogicSig failed: %v", err)
+	}
+	if !bytes.Equal(logic, lsig.Lsig.Logic) {
+		t.Fatal("logicsig program mismatch")
+	}
+
+	if _, err := Address(nil); err == nil {
+		t.Fatal("expected error for empty public key")
+	}
+}
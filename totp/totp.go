@@ -0,0 +1,123 @@
+// Package totp implements time-based one-time passwords (RFC 6238) with
+// HMAC-SHA-1, the variant authenticator apps generate by default, so that a
+// code from an enrolled app can approve the use of a key.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// SecretSize is the size of generated secrets, that of an HMAC-SHA-1 key
+	// as recommended by RFC 4226.
+	SecretSize = 20
+	// DefaultDigits and DefaultPeriod are those of authenticator apps.
+	DefaultDigits = 6
+	DefaultPeriod = 30 * time.Second
+)
+
+// ErrInvalidCode is returned by Verify for a code that matches no time step
+// of the accepted window.
+var ErrInvalidCode = errors.New("invalid TOTP code")
+
+// secretEncoding is the base32 encoding of secrets in otpauth URIs.
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Key is a TOTP secret and its parameters.
+type Key struct {
+	Secret []byte
+	Digits int
+	Period time.Duration
+}
+
+// Generate returns a Key with a random secret and the default parameters.
+func Generate() (Key, error) {
+	secret := make([]byte, SecretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return Key{}, err
+	}
+	return Key{Secret: secret, Digits: DefaultDigits, Period: DefaultPeriod}, nil
+}
+
+// EncodeSecret returns secret in unpadded base32, as typed into or scanned by
+// authenticator apps.
+func EncodeSecret(secret []byte) string {
+	return secretEncoding.EncodeToString(secret)
+}
+
+// DecodeSecret decodes a secret written by EncodeSecret, ignoring case and
+// spaces.
+func DecodeSecret(s string) ([]byte, error) {
+	s = strings.ToUpper(strings.ReplaceAll(s, " ", ""))
+	secret, err := secretEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TOTP secret: %w", err)
+	}
+	return secret, nil
+}
+
+// Counter returns the time step of t.
+func (k Key) Counter(t time.Time) uint64 {
+	return uint64(t.Unix()) / uint64(k.Period/time.Second)
+}
+
+// Code returns the code of the time step counter (HOTP, RFC 4226).
+func (k Key) Code(counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	mac := hmac.New(sha1.New, k.Secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	off := sum[len(sum)-1] & 0x0f
+	v := binary.BigEndian.Uint32(sum[off:]) & 0x7fffffff
+	mod := uint32(1)
+	for range k.Digits {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", k.Digits, v%mod)
+}
+
+// Verify checks code against the time step of t and the skew steps before and
+// after it, to allow for clock drift and typing time. It returns the matching
+// step, which the caller should record to refuse the code a second time.
+func (k Key) Verify(code string, t time.Time, skew uint64) (uint64, error) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != k.Digits {
+		return 0, ErrInvalidCode
+	}
+	now := k.Counter(t)
+	first := now - min(now, skew)
+	for c := first; c <= now+skew; c++ {
+		if subtle.ConstantTimeCompare([]byte(k.Code(c)), []byte(code)) == 1 {
+			return c, nil
+		}
+	}
+	return 0, ErrInvalidCode
+}
+
+// URI returns the otpauth URI of k that authenticator apps scan as a QR code.
+func (k Key) URI(issuer, account string) string {
+	v := url.Values{}
+	v.Set("secret", EncodeSecret(k.Secret))
+	v.Set("issuer", issuer)
+	v.Set("algorithm", "SHA1")
+	v.Set("digits", fmt.Sprint(k.Digits))
+	v.Set("period", fmt.Sprint(int(k.Period/time.Second)))
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: v.Encode(),
+	}
+	return u.String()
+}
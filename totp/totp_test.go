@@ -0,0 +1,68 @@
+package totp
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCode checks the SHA-1 test vectors of RFC 6238, appendix B.
+func TestCode(t *testing.T) {
+	k := Key{Secret: []byte("12345678901234567890"), Digits: 8, Period: DefaultPeriod}
+	for _, tc := range []struct {
+		unix int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	} {
+		if got := k.Code(k.Counter(time.Unix(tc.unix, 0))); got != tc.code {
+			t.Fatalf("T=%d: got %s, want %s", tc.unix, got, tc.code)
+		}
+	}
+}
+
+// TestVerify checks the skew window and code formatting.
+func TestVerify(t *testing.T) {
+	k, err := Generate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1_700_000_000, 0)
+	code := k.Code(k.Counter(now) - 1)
+	if c, err := k.Verify(code, now, 1); err != nil || c != k.Counter(now)-1 {
+		t.Fatalf("previous step: got %d, %v", c, err)
+	}
+	if _, err := k.Verify(code[:3]+" "+code[3:], now, 1); err != nil {
+		t.Fatalf("spaced code: %v", err)
+	}
+	if _, err := k.Verify(code, now, 0); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("expected ErrInvalidCode without skew, got %v", err)
+	}
+	if _, err := k.Verify("12345", now, 1); !errors.Is(err, ErrInvalidCode) {
+		t.Fatalf("expected ErrInvalidCode for a short code, got %v", err)
+	}
+}
+
+// TestSecretAndURI round-trips a secret and checks the otpauth URI.
+func TestSecretAndURI(t *testing.T) {
+	k := Key{Secret: []byte("12345678901234567890"), Digits: DefaultDigits, Period: DefaultPeriod}
+	enc := EncodeSecret(k.Secret)
+	if enc != "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ" {
+		t.Fatalf("unexpected secret encoding %s", enc)
+	}
+	dec, err := DecodeSecret(strings.ToLower(enc[:8]) + " " + enc[8:])
+	if err != nil || string(dec) != string(k.Secret) {
+		t.Fatalf("DecodeSecret: %q, %v", dec, err)
+	}
+	uri := k.URI("falcon", "brave-otter-lamp")
+	if !strings.HasPrefix(uri, "otpauth://totp/falcon:brave-otter-lamp?") ||
+		!strings.Contains(uri, "secret="+enc) || !strings.Contains(uri, "period=30") {
+		t.Fatalf("unexpected URI %s", uri)
+	}
+}